@@ -13,6 +13,7 @@ require (
 	github.com/go-kivik/couchdb v2.0.0+incompatible
 	github.com/go-kivik/kivik v2.0.0+incompatible
 	github.com/go-kivik/kiviktest v2.0.0+incompatible // indirect
+	github.com/go-redis/redis/v7 v7.4.0
 	github.com/go-sql-driver/mysql v1.5.0
 	github.com/golang/mock v1.4.0
 	github.com/golang/protobuf v1.3.3
@@ -20,7 +21,9 @@ require (
 	github.com/google/uuid v1.1.1
 	github.com/gopherjs/gopherjs v0.0.0-20200217142428-fce0ec30dd00 // indirect
 	github.com/gorilla/mux v1.7.3
+	github.com/klauspost/compress v1.10.0
 	github.com/kr/pretty v0.1.0 // indirect
+	github.com/lib/pq v1.8.0
 	github.com/minio/sha256-simd v0.1.1 // indirect
 	github.com/mitchellh/mapstructure v1.1.2
 	github.com/multiformats/go-multibase v0.0.1
@@ -38,9 +41,8 @@ require (
 	github.com/xeipuuv/gojsonschema v1.2.0
 	gitlab.com/flimzy/testy v0.2.1 // indirect
 	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
-	golang.org/x/net v0.0.0-20200625001655-4c5254603344 // indirect
-	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 	gopkg.in/yaml.v2 v2.2.8 // indirect
+	modernc.org/sqlite v1.11.2
 	nhooyr.io/websocket v1.8.3
 )
 
@@ -0,0 +1,48 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package aead provides AEAD content encryption primitives not available in Tink. It currently offers AES-CBC-HMAC-SHA2
+// (A128CBC-HS256 and A256CBC-HS512) as defined in https://tools.ietf.org/html/rfc7518#section-5.2.
+//
+// Its key templates can be set as the content encryption of the composite primitives, example:
+//
+//  package main
+//
+//  import (
+//      "github.com/google/tink/go/keyset"
+//
+//      "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/aead"
+//      "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/ecdhes"
+//  )
+//
+//  func main() {
+//      kt, err := ecdhes.NewECDHESKeyTemplate(ecdhes.WithContentEncryption(aead.AES256CBCHMACSHA512KeyTemplate()))
+//      if err != nil {
+//          // handle error
+//      }
+//
+//      recKH, err := keyset.NewHandle(kt)
+//      if err != nil {
+//          // handle error
+//      }
+//  }
+package aead
+
+import (
+	"fmt"
+
+	"github.com/google/tink/go/core/registry"
+)
+
+// TODO - find a better way to setup tink than init.
+// nolint: gochecknoinits
+func init() {
+	// TODO - avoid the tink registry singleton.
+	err := registry.RegisterKeyManager(newAESCBCHMACAEADKeyManager())
+	if err != nil {
+		panic(fmt.Sprintf("aead.init() failed: %v", err))
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package aead
+
+import (
+	"github.com/golang/protobuf/proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/aead/subtle"
+	cbchmacpb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/aes_cbc_hmac_aead_go_proto"
+)
+
+// AES128CBCHMACSHA256KeyTemplate is a KeyTemplate that generates an A128CBC-HS256 AEAD key as per
+// https://tools.ietf.org/html/rfc7518#section-5.2.3 with the following parameters:
+//   - Key size: 32 bytes (16 bytes MAC key and 16 bytes AES-128 key)
+//   - IV size: 16 bytes
+//   - Tag size: 16 bytes
+func AES128CBCHMACSHA256KeyTemplate() *tinkpb.KeyTemplate {
+	return createAESCBCHMACAEADKeyTemplate(subtle.AES128CBCHMACSHA256KeySize)
+}
+
+// AES256CBCHMACSHA512KeyTemplate is a KeyTemplate that generates an A256CBC-HS512 AEAD key as per
+// https://tools.ietf.org/html/rfc7518#section-5.2.5 with the following parameters:
+//   - Key size: 64 bytes (32 bytes MAC key and 32 bytes AES-256 key)
+//   - IV size: 16 bytes
+//   - Tag size: 32 bytes
+func AES256CBCHMACSHA512KeyTemplate() *tinkpb.KeyTemplate {
+	return createAESCBCHMACAEADKeyTemplate(subtle.AES256CBCHMACSHA512KeySize)
+}
+
+// createAESCBCHMACAEADKeyTemplate creates a new AES-CBC-HMAC-SHA2 AEAD key template with the given composite key
+// size in bytes.
+func createAESCBCHMACAEADKeyTemplate(keySize uint32) *tinkpb.KeyTemplate {
+	format := &cbchmacpb.AesCbcHmacAeadKeyFormat{
+		KeySize: keySize,
+	}
+
+	serializedFormat, err := proto.Marshal(format)
+	if err != nil {
+		panic("failed to marshal AesCbcHmacAeadKeyFormat proto")
+	}
+
+	return &tinkpb.KeyTemplate{
+		TypeUrl:          aesCBCHMACAEADTypeURL,
+		Value:            serializedFormat,
+		OutputPrefixType: tinkpb.OutputPrefixType_RAW,
+	}
+}
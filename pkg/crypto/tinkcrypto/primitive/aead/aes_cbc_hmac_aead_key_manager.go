@@ -0,0 +1,139 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package aead
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/core/registry"
+	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"github.com/google/tink/go/subtle/random"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/aead/subtle"
+	cbchmacpb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/aes_cbc_hmac_aead_go_proto"
+)
+
+const (
+	aesCBCHMACAEADKeyVersion = 0
+	aesCBCHMACAEADTypeURL    = "type.hyperledger.org/hyperledger.aries.crypto.tink.AesCbcHmacAeadKey"
+)
+
+// common errors
+var (
+	errInvalidAESCBCHMACAEADKey       = errors.New("aes_cbc_hmac_aead_key_manager: invalid key")
+	errInvalidAESCBCHMACAEADKeyFormat = errors.New("aes_cbc_hmac_aead_key_manager: invalid key format")
+)
+
+// aesCBCHMACAEADKeyManager is an implementation of KeyManager interface.
+// It generates new AesCbcHmacAeadKey keys and produces new instances of AESCBCHMAC subtle.
+type aesCBCHMACAEADKeyManager struct{}
+
+// Assert that aesCBCHMACAEADKeyManager implements the KeyManager interface.
+var _ registry.KeyManager = (*aesCBCHMACAEADKeyManager)(nil)
+
+// newAESCBCHMACAEADKeyManager creates a new aesCBCHMACAEADKeyManager.
+func newAESCBCHMACAEADKeyManager() *aesCBCHMACAEADKeyManager {
+	return new(aesCBCHMACAEADKeyManager)
+}
+
+// Primitive creates an AESCBCHMAC subtle for the given serialized AesCbcHmacAeadKey proto.
+func (km *aesCBCHMACAEADKeyManager) Primitive(serializedKey []byte) (interface{}, error) {
+	if len(serializedKey) == 0 {
+		return nil, errInvalidAESCBCHMACAEADKey
+	}
+
+	key := new(cbchmacpb.AesCbcHmacAeadKey)
+
+	err := proto.Unmarshal(serializedKey, key)
+	if err != nil {
+		return nil, errInvalidAESCBCHMACAEADKey
+	}
+
+	err = km.validateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := subtle.NewAESCBCHMAC(key.KeyValue)
+	if err != nil {
+		return nil, fmt.Errorf("aes_cbc_hmac_aead_key_manager: cannot create new primitive: %w", err)
+	}
+
+	return ret, nil
+}
+
+// NewKey creates a new key according to the specification of the given serialized AesCbcHmacAeadKeyFormat.
+func (km *aesCBCHMACAEADKeyManager) NewKey(serializedKeyFormat []byte) (proto.Message, error) {
+	if len(serializedKeyFormat) == 0 {
+		return nil, errInvalidAESCBCHMACAEADKeyFormat
+	}
+
+	keyFormat := new(cbchmacpb.AesCbcHmacAeadKeyFormat)
+
+	err := proto.Unmarshal(serializedKeyFormat, keyFormat)
+	if err != nil {
+		return nil, errInvalidAESCBCHMACAEADKeyFormat
+	}
+
+	err = subtle.ValidateKeySize(keyFormat.KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("aes_cbc_hmac_aead_key_manager: invalid key format: %w", err)
+	}
+
+	return &cbchmacpb.AesCbcHmacAeadKey{
+		Version:  aesCBCHMACAEADKeyVersion,
+		KeyValue: random.GetRandomBytes(keyFormat.KeySize),
+	}, nil
+}
+
+// NewKeyData creates a new KeyData according to the specification of the given serialized AesCbcHmacAeadKeyFormat.
+// It should be used solely by the key management API.
+func (km *aesCBCHMACAEADKeyManager) NewKeyData(serializedKeyFormat []byte) (*tinkpb.KeyData, error) {
+	key, err := km.NewKey(serializedKeyFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	serializedKey, err := proto.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tinkpb.KeyData{
+		TypeUrl:         aesCBCHMACAEADTypeURL,
+		Value:           serializedKey,
+		KeyMaterialType: tinkpb.KeyData_SYMMETRIC,
+	}, nil
+}
+
+// DoesSupport indicates if this key manager supports the given key type.
+func (km *aesCBCHMACAEADKeyManager) DoesSupport(typeURL string) bool {
+	return typeURL == aesCBCHMACAEADTypeURL
+}
+
+// TypeURL returns the key type of keys managed by this key manager.
+func (km *aesCBCHMACAEADKeyManager) TypeURL() string {
+	return aesCBCHMACAEADTypeURL
+}
+
+// validateKey validates the given AesCbcHmacAeadKey.
+func (km *aesCBCHMACAEADKeyManager) validateKey(key *cbchmacpb.AesCbcHmacAeadKey) error {
+	err := keyset.ValidateKeyVersion(key.Version, aesCBCHMACAEADKeyVersion)
+	if err != nil {
+		return fmt.Errorf("aes_cbc_hmac_aead_key_manager: %w", err)
+	}
+
+	err = subtle.ValidateKeySize(uint32(len(key.KeyValue)))
+	if err != nil {
+		return fmt.Errorf("aes_cbc_hmac_aead_key_manager: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package aead
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/core/registry"
+	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/aead/subtle"
+	cbchmacpb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/aes_cbc_hmac_aead_go_proto"
+)
+
+func TestAESCBCHMACAEADKeyTemplates(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    *tinkpb.KeyTemplate
+		keySize int
+	}{
+		{name: "A128CBC-HS256", tmpl: AES128CBCHMACSHA256KeyTemplate(), keySize: subtle.AES128CBCHMACSHA256KeySize},
+		{name: "A256CBC-HS512", tmpl: AES256CBCHMACSHA512KeyTemplate(), keySize: subtle.AES256CBCHMACSHA512KeySize},
+	}
+
+	for _, tc := range tests {
+		tt := tc
+		t.Run(tt.name, func(t *testing.T) {
+			kh, err := keyset.NewHandle(tt.tmpl)
+			require.NoError(t, err)
+
+			a, err := aead.New(kh)
+			require.NoError(t, err)
+
+			pt := []byte("secret message")
+			aad := []byte("aad message")
+
+			ct, err := a.Encrypt(pt, aad)
+			require.NoError(t, err)
+			// RAW output prefix: IV || CT (1 padded block) || TAG (half the key size)
+			require.Len(t, ct, subtle.AESCBCIVSize+16+tt.keySize/2)
+
+			dpt, err := a.Decrypt(ct, aad)
+			require.NoError(t, err)
+			require.Equal(t, pt, dpt)
+		})
+	}
+}
+
+func TestAESCBCHMACAEADKeyManager(t *testing.T) {
+	km, err := registry.GetKeyManager(aesCBCHMACAEADTypeURL)
+	require.NoError(t, err)
+	require.True(t, km.DoesSupport(aesCBCHMACAEADTypeURL))
+	require.Equal(t, aesCBCHMACAEADTypeURL, km.TypeURL())
+
+	t.Run("NewKeyData and Primitive", func(t *testing.T) {
+		keyData, err := km.NewKeyData(AES256CBCHMACSHA512KeyTemplate().Value)
+		require.NoError(t, err)
+		require.Equal(t, aesCBCHMACAEADTypeURL, keyData.TypeUrl)
+		require.Equal(t, tinkpb.KeyData_SYMMETRIC, keyData.KeyMaterialType)
+
+		p, err := km.Primitive(keyData.Value)
+		require.NoError(t, err)
+		require.IsType(t, &subtle.AESCBCHMAC{}, p)
+	})
+
+	t.Run("invalid key formats", func(t *testing.T) {
+		_, err := km.NewKey(nil)
+		require.EqualError(t, err, errInvalidAESCBCHMACAEADKeyFormat.Error())
+
+		_, err = km.NewKey([]byte("bad format"))
+		require.EqualError(t, err, errInvalidAESCBCHMACAEADKeyFormat.Error())
+
+		_, err = km.NewKeyData(nil)
+		require.EqualError(t, err, errInvalidAESCBCHMACAEADKeyFormat.Error())
+
+		format, err := proto.Marshal(&cbchmacpb.AesCbcHmacAeadKeyFormat{KeySize: 32 + 1})
+		require.NoError(t, err)
+
+		_, err = km.NewKey(format)
+		require.EqualError(t, err, "aes_cbc_hmac_aead_key_manager: invalid key format: aes_cbc_hmac_aead: invalid "+
+			"key size 33")
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		_, err := km.Primitive(nil)
+		require.EqualError(t, err, errInvalidAESCBCHMACAEADKey.Error())
+
+		_, err = km.Primitive([]byte("bad key"))
+		require.EqualError(t, err, errInvalidAESCBCHMACAEADKey.Error())
+
+		key, err := proto.Marshal(&cbchmacpb.AesCbcHmacAeadKey{Version: 1, KeyValue: make([]byte, 32)})
+		require.NoError(t, err)
+
+		_, err = km.Primitive(key)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "aes_cbc_hmac_aead_key_manager: ")
+
+		key, err = proto.Marshal(&cbchmacpb.AesCbcHmacAeadKey{KeyValue: make([]byte, 16)})
+		require.NoError(t, err)
+
+		_, err = km.Primitive(key)
+		require.EqualError(t, err, "aes_cbc_hmac_aead_key_manager: aes_cbc_hmac_aead: invalid key size 16")
+	})
+}
@@ -0,0 +1,190 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package subtle
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/google/tink/go/subtle/random"
+	"github.com/google/tink/go/tink"
+)
+
+const (
+	// AESCBCIVSize is the IV size in bytes of AES-CBC-HMAC-SHA2 content encryption.
+	AESCBCIVSize = aes.BlockSize
+
+	// AES128CBCHMACSHA256KeySize is the composite key size in bytes of A128CBC-HS256.
+	AES128CBCHMACSHA256KeySize = 32
+	// AES192CBCHMACSHA384KeySize is the composite key size in bytes of A192CBC-HS384.
+	AES192CBCHMACSHA384KeySize = 48
+	// AES256CBCHMACSHA512KeySize is the composite key size in bytes of A256CBC-HS512.
+	AES256CBCHMACSHA512KeySize = 64
+
+	// bitsPerByte is used to compute the AAD length in bits (AL) of the authentication tag input.
+	bitsPerByte = 8
+)
+
+var errDecryptionFailed = errors.New("aes_cbc_hmac_aead: decryption failed")
+
+// AESCBCHMAC is an implementation of AEAD interface for AES-CBC-HMAC-SHA2 content encryption as defined in
+// https://tools.ietf.org/html/rfc7518#section-5.2. The key is the composite MAC_KEY || ENC_KEY, each half of the key
+// size, and the authentication tag is the first half of HMAC(MAC_KEY, AAD || IV || CT || AL).
+type AESCBCHMAC struct {
+	encKey   []byte
+	macKey   []byte
+	hashFunc func() hash.Hash
+	tagSize  int
+}
+
+// Assert that AESCBCHMAC implements the AEAD interface.
+var _ tink.AEAD = (*AESCBCHMAC)(nil)
+
+// NewAESCBCHMAC returns an AESCBCHMAC instance for the composite key. The key size must be 32 (A128CBC-HS256),
+// 48 (A192CBC-HS384) or 64 (A256CBC-HS512) bytes.
+func NewAESCBCHMAC(key []byte) (*AESCBCHMAC, error) {
+	var hashFunc func() hash.Hash
+
+	switch len(key) {
+	case AES128CBCHMACSHA256KeySize:
+		hashFunc = sha256.New
+	case AES192CBCHMACSHA384KeySize:
+		hashFunc = sha512.New384
+	case AES256CBCHMACSHA512KeySize:
+		hashFunc = sha512.New
+	default:
+		return nil, fmt.Errorf("aes_cbc_hmac_aead: invalid key size %d", len(key))
+	}
+
+	keySize := len(key) / 2
+
+	return &AESCBCHMAC{
+		macKey:   key[:keySize],
+		encKey:   key[keySize:],
+		hashFunc: hashFunc,
+		tagSize:  keySize,
+	}, nil
+}
+
+// ValidateKeySize checks if the given composite key size is a valid AES-CBC-HMAC-SHA2 key size.
+func ValidateKeySize(sizeInBytes uint32) error {
+	switch sizeInBytes {
+	case AES128CBCHMACSHA256KeySize, AES192CBCHMACSHA384KeySize, AES256CBCHMACSHA512KeySize:
+		return nil
+	default:
+		return fmt.Errorf("aes_cbc_hmac_aead: invalid key size %d", sizeInBytes)
+	}
+}
+
+// TagSize returns the authentication tag size in bytes.
+func (a *AESCBCHMAC) TagSize() int {
+	return a.tagSize
+}
+
+// Encrypt encrypts plaintext with additionalData as additional authenticated data. The resulting ciphertext is
+// IV || CT || TAG.
+func (a *AESCBCHMAC) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	return a.encryptWithIV(random.GetRandomBytes(AESCBCIVSize), plaintext, additionalData)
+}
+
+func (a *AESCBCHMAC) encryptWithIV(iv, plaintext, additionalData []byte) ([]byte, error) {
+	block, err := aes.NewCipher(a.encKey)
+	if err != nil {
+		return nil, fmt.Errorf("aes_cbc_hmac_aead: %w", err)
+	}
+
+	ct := pad(plaintext)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ct, ct)
+
+	out := make([]byte, 0, len(iv)+len(ct)+a.tagSize)
+	out = append(out, iv...)
+	out = append(out, ct...)
+
+	return append(out, a.computeTag(iv, ct, additionalData)...), nil
+}
+
+// Decrypt decrypts ciphertext, formatted as IV || CT || TAG, with additionalData as additional authenticated data.
+func (a *AESCBCHMAC) Decrypt(ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < AESCBCIVSize+aes.BlockSize+a.tagSize {
+		return nil, errors.New("aes_cbc_hmac_aead: ciphertext too short")
+	}
+
+	iv := ciphertext[:AESCBCIVSize]
+	ct := ciphertext[AESCBCIVSize : len(ciphertext)-a.tagSize]
+	tag := ciphertext[len(ciphertext)-a.tagSize:]
+
+	if len(ct)%aes.BlockSize != 0 {
+		return nil, errDecryptionFailed
+	}
+
+	if !hmac.Equal(tag, a.computeTag(iv, ct, additionalData)) {
+		return nil, errDecryptionFailed
+	}
+
+	block, err := aes.NewCipher(a.encKey)
+	if err != nil {
+		return nil, fmt.Errorf("aes_cbc_hmac_aead: %w", err)
+	}
+
+	pt := make([]byte, len(ct))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(pt, ct)
+
+	return unpad(pt)
+}
+
+// computeTag returns the first tagSize bytes of HMAC(MAC_KEY, AAD || IV || CT || AL) where AL is the number of bits in
+// AAD expressed as a 64-bit big-endian integer.
+func (a *AESCBCHMAC) computeTag(iv, ct, additionalData []byte) []byte {
+	al := make([]byte, 8)
+	binary.BigEndian.PutUint64(al, uint64(len(additionalData))*bitsPerByte)
+
+	mac := hmac.New(a.hashFunc, a.macKey)
+	// hash.Hash writes never return an error.
+	_, _ = mac.Write(additionalData)
+	_, _ = mac.Write(iv)
+	_, _ = mac.Write(ct)
+	_, _ = mac.Write(al)
+
+	return mac.Sum(nil)[:a.tagSize]
+}
+
+// pad returns a copy of plaintext with PKCS #7 padding.
+func pad(plaintext []byte) []byte {
+	padLen := aes.BlockSize - len(plaintext)%aes.BlockSize
+
+	padded := make([]byte, len(plaintext), len(plaintext)+padLen)
+	copy(padded, plaintext)
+
+	for i := 0; i < padLen; i++ {
+		padded = append(padded, byte(padLen))
+	}
+
+	return padded
+}
+
+// unpad removes the PKCS #7 padding of pt.
+func unpad(pt []byte) ([]byte, error) {
+	padLen := int(pt[len(pt)-1])
+	if padLen == 0 || padLen > aes.BlockSize {
+		return nil, errDecryptionFailed
+	}
+
+	for _, b := range pt[len(pt)-padLen:] {
+		if int(b) != padLen {
+			return nil, errDecryptionFailed
+		}
+	}
+
+	return pt[:len(pt)-padLen], nil
+}
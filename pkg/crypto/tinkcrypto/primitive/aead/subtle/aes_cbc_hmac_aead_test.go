@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package subtle
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/google/tink/go/subtle/random"
+	"github.com/stretchr/testify/require"
+)
+
+// test vectors from https://tools.ietf.org/html/rfc7518#appendix-B
+func TestAESCBCHMACRFC7518Vectors(t *testing.T) {
+	plaintext := []byte("A cipher system must not be required to be secret, and it must be able to fall into the " +
+		"hands of the enemy without inconvenience")
+	aad := []byte("The second principle of Auguste Kerckhoffs")
+	iv := hexDecode(t, "1a f3 8c 2d c2 b9 6f fd d8 66 94 09 23 41 bc 04")
+
+	tests := []struct {
+		name string
+		key  string
+		ct   string
+		tag  string
+	}{
+		{
+			name: "A128CBC-HS256",
+			key: "00 01 02 03 04 05 06 07 08 09 0a 0b 0c 0d 0e 0f 10 11 12 13 14 15 16 17 18 19 1a 1b 1c 1d 1e " +
+				"1f",
+			ct: "c8 0e df a3 2d df 39 d5 ef 00 c0 b4 68 83 42 79 a2 e4 6a 1b 80 49 f7 92 f7 6b fe 54 b9 03 a9 c9 " +
+				"a9 4a c9 b4 7a d2 65 5c 5f 10 f9 ae f7 14 27 e2 fc 6f 9b 3f 39 9a 22 14 89 f1 63 62 c7 03 23 36 " +
+				"09 d4 5a c6 98 64 e3 32 1c f8 29 35 ac 40 96 c8 6e 13 33 14 c5 40 19 e8 ca 79 80 df a4 b9 cf 1b " +
+				"38 4c 48 6f 3a 54 c5 10 78 15 8e e5 d7 9d e5 9f bd 34 d8 48 b3 d6 95 50 a6 76 46 34 44 27 ad e5 " +
+				"4b 88 51 ff b5 98 f7 f8 00 74 b9 47 3c 82 e2 db",
+			tag: "65 2c 3f a3 6b 0a 7c 5b 32 19 fa b3 a3 0b c1 c4",
+		},
+		{
+			name: "A256CBC-HS512",
+			key: "00 01 02 03 04 05 06 07 08 09 0a 0b 0c 0d 0e 0f 10 11 12 13 14 15 16 17 18 19 1a 1b 1c 1d 1e " +
+				"1f 20 21 22 23 24 25 26 27 28 29 2a 2b 2c 2d 2e 2f 30 31 32 33 34 35 36 37 38 39 3a 3b 3c 3d " +
+				"3e 3f",
+			ct: "4a ff aa ad b7 8c 31 c5 da 4b 1b 59 0d 10 ff bd 3d d8 d5 d3 02 42 35 26 91 2d a0 37 ec bc c7 bd " +
+				"82 2c 30 1d d6 7c 37 3b cc b5 84 ad 3e 92 79 c2 e6 d1 2a 13 74 b7 7f 07 75 53 df 82 94 10 44 6b " +
+				"36 eb d9 70 66 29 6a e6 42 7e a7 5c 2e 08 46 a1 1a 09 cc f5 37 0d c8 0b fe cb ad 28 c7 3f 09 b3 " +
+				"a3 b7 5e 66 2a 25 94 41 0a e4 96 b2 e2 e6 60 9e 31 e6 e0 2c c8 37 f0 53 d2 1f 37 ff 4f 51 95 0b " +
+				"be 26 38 d0 9d d7 a4 93 09 30 80 6d 07 03 b1 f6",
+			tag: "4d d3 b4 c0 88 a7 f4 5c 21 68 39 64 5b 20 12 bf 2e 62 69 a8 c5 6a 81 6d bc 1b 26 77 61 95 5b c5",
+		},
+	}
+
+	for _, tc := range tests {
+		tt := tc
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewAESCBCHMAC(hexDecode(t, tt.key))
+			require.NoError(t, err)
+
+			expected := append(append(append([]byte{}, iv...), hexDecode(t, tt.ct)...), hexDecode(t, tt.tag)...)
+
+			ct, err := a.encryptWithIV(iv, plaintext, aad)
+			require.NoError(t, err)
+			require.Equal(t, expected, ct)
+
+			pt, err := a.Decrypt(ct, aad)
+			require.NoError(t, err)
+			require.Equal(t, plaintext, pt)
+		})
+	}
+}
+
+func TestAESCBCHMACEncryptDecrypt(t *testing.T) {
+	for _, keySize := range []int{
+		AES128CBCHMACSHA256KeySize, AES192CBCHMACSHA384KeySize, AES256CBCHMACSHA512KeySize,
+	} {
+		a, err := NewAESCBCHMAC(random.GetRandomBytes(uint32(keySize)))
+		require.NoError(t, err)
+		require.Equal(t, keySize/2, a.TagSize())
+
+		aad := []byte("aad")
+
+		// cover empty, partial block and full block plaintexts
+		for _, ptSize := range []int{0, 1, 15, 16, 17, 32} {
+			pt := random.GetRandomBytes(uint32(ptSize))
+
+			ct, err := a.Encrypt(pt, aad)
+			require.NoError(t, err)
+			require.Len(t, ct, AESCBCIVSize+(ptSize/16+1)*16+a.TagSize())
+
+			dpt, err := a.Decrypt(ct, aad)
+			require.NoError(t, err)
+			require.EqualValues(t, pt, dpt)
+
+			_, err = a.Decrypt(ct, []byte("other aad"))
+			require.EqualError(t, err, "aes_cbc_hmac_aead: decryption failed")
+
+			tampered := append([]byte{}, ct...)
+			tampered[AESCBCIVSize] ^= 1
+
+			_, err = a.Decrypt(tampered, aad)
+			require.EqualError(t, err, "aes_cbc_hmac_aead: decryption failed")
+		}
+
+		_, err = a.Decrypt([]byte("short"), aad)
+		require.EqualError(t, err, "aes_cbc_hmac_aead: ciphertext too short")
+	}
+
+	_, err := NewAESCBCHMAC(random.GetRandomBytes(16))
+	require.EqualError(t, err, "aes_cbc_hmac_aead: invalid key size 16")
+}
+
+func hexDecode(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	require.NoError(t, err)
+
+	return b
+}
@@ -25,6 +25,9 @@ type EncrypterHelper interface {
 	// GetIVSize provides the aead primitive nonce size
 	GetIVSize() int
 
+	// GetEncAlg provides the content encryption algorithm value set in the encrypted data (eg A256GCM)
+	GetEncAlg() string
+
 	// BuildEncData will build the []byte representing the ciphertext sent to the end user as a result of the Composite
 	// Encryption primitive execution
 	BuildEncData(eAlg string, recipientsWK []*RecipientWrappedKey, ct, singleRecipientAAD []byte) ([]byte, error)
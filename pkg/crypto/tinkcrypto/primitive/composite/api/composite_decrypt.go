@@ -14,6 +14,14 @@ type CompositeDecrypt interface {
 	// Decrypt operation: decrypts ciphertext representing a serialized EncryptedData (mainly extracted from a
 	// JWE message) for a given recipient. It extracts the underlying secure material then executes key unwrapping of
 	// the cek and the AEAD decrypt primitive.
-	// returns resulting plaintext extracted from the serialized object.
+	// returns resulting plaintext extracted from the serialized object. For JWE messages, additionalData must be the
+	// AAD computed from the protected header as received as per https://tools.ietf.org/html/rfc7516#section-5.2.
 	Decrypt(cipherText, additionalData []byte) ([]byte, error)
 }
+
+// KIDProvider is implemented by the composite decryption primitives of keys with a KID. The primitives of keysets
+// holding several keys, ie during a key rotation, use it to try the keys of the recipients of a message first.
+type KIDProvider interface {
+	// KID returns the key ID of the recipient key of the primitive, it is empty if the key has no KID.
+	KID() string
+}
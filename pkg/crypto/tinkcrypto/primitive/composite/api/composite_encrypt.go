@@ -14,6 +14,8 @@ package api
 // identity remains unknown to the recipient in a serialized EncryptedData envelope (used mainly to build JWE messages).
 type CompositeEncrypt interface {
 	// Encrypt operation: encrypts plaintext with aad represented as the list of recipient's corresponding public keys
-	// Returns resulting EncryptedData wrapping ciphertext and the recipients protected keys or error if failed.
+	// Returns resulting EncryptedData wrapping ciphertext and the recipients protected keys or error if failed. For
+	// JWE messages, aad must be the AAD computed from the serialized protected header as per
+	// https://tools.ietf.org/html/rfc7516#section-5.1 so that the header is authenticated along with the content.
 	Encrypt(plainText, aad []byte) ([]byte, error)
 }
@@ -0,0 +1,30 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package api
+
+import (
+	"io"
+)
+
+// CompositeStreamingEncrypt will encrypt a stream of plaintext for a list of recipients. The CEK is wrapped once for
+// each recipient in a stream header, the content is then encrypted in segments with a streaming AEAD primitive. It is
+// meant for large payloads that should not be loaded in memory, the resulting stream is not a JWE message.
+type CompositeStreamingEncrypt interface {
+	// EncryptWriter returns a WriteCloser encrypting the plaintext written to it with aad and writing the resulting
+	// stream to w. The stream header is written to w before EncryptWriter returns. Close must be called to encrypt the
+	// last segment.
+	EncryptWriter(w io.Writer, aad []byte) (io.WriteCloser, error)
+}
+
+// CompositeStreamingDecrypt will decrypt a stream created by CompositeStreamingEncrypt for the recipient caller of
+// this interface.
+type CompositeStreamingDecrypt interface {
+	// DecryptReader reads the stream header from r and unwraps the CEK of the recipient. It returns a Reader
+	// decrypting the remaining content of r with aad. Each segment is authenticated as it is read, a Read error means
+	// the stream was altered or truncated.
+	DecryptReader(r io.Reader, aad []byte) (io.Reader, error)
+}
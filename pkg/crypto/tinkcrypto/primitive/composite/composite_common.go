@@ -7,17 +7,28 @@ SPDX-License-Identifier: Apache-2.0
 package composite
 
 import (
+	"crypto/elliptic"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 
+	"github.com/google/tink/go/core/cryptofmt"
+	"github.com/google/tink/go/core/primitiveset"
+	hybrid "github.com/google/tink/go/hybrid/subtle"
 	commonpb "github.com/google/tink/go/proto/common_go_proto"
 
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/api"
 	compositepb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
 )
 
 // package composite provides the core crypto composite primitives such as ECDH-ES and ECDH-1PU to be used by JWE crypto
 
 // EncryptedData represents the Encryption's output data as a result of ECDHESEncrypt.Encrypt(pt, aad) call
-// The user of the primitive must unmarshal the result and build their own ECDH-ES compliant message (ie JWE msg)
+// The user of the primitive must unmarshal the result and build their own ECDH-ES compliant message (ie JWE msg):
+// the ephemeral public key generated for the key agreement with every recipient is set in the EPK of its
+// RecipientWrappedKey, EPKToJWK marshals it as the JWE 'epk' header and EPKFromJWK parses this header back for the
+// decryption.
 type EncryptedData struct {
 	EncAlg     string                 `json:"encalg,omitempty"`
 	Ciphertext []byte                 `json:"ciphertext,omitempty"`
@@ -29,14 +40,111 @@ type EncryptedData struct {
 	// use this field. It is added here to provide access to the updated AAD for single recipient encryption use by
 	// external users of this crypto primitive.
 	SingleRecipientAAD []byte `json:"singlerecipientaad,omitempty"`
+	// SKID is the KID of the sender key of ECDH-1PU encryptions. It tells the recipients which sender key to decrypt
+	// with but it isn't authenticated, the sender is authenticated by the decryption with its key.
+	SKID string `json:"skid,omitempty"`
 }
 
 // RecipientWrappedKey contains recipient key material required to unwrap CEK
 type RecipientWrappedKey struct {
-	KID          string    `json:"kid,omitempty"`
-	EncryptedCEK []byte    `json:"encryptedcek,omitempty"`
-	EPK          PublicKey `json:"epk,omitempty"`
-	Alg          string    `json:"alg,omitempty"`
+	KID          string `json:"kid,omitempty"`
+	EncryptedCEK []byte `json:"encryptedcek,omitempty"`
+	// EPK is the ephemeral public key of the key agreement with the recipient, its KID is empty as the recipient is
+	// identified by the KID of the wrapped key
+	EPK PublicKey `json:"epk,omitempty"`
+	Alg string    `json:"alg,omitempty"`
+}
+
+// SortRecipientsByKID returns the recipients wrapped keys in the order a local key identified by kid should try to
+// unwrap them: the recipients with the same KID first, then the recipients without a KID and finally the recipients
+// with another KID since the sender may identify the local key differently. The order is left unchanged when kid is
+// empty.
+func SortRecipientsByKID(recipients []*RecipientWrappedKey, kid string) []*RecipientWrappedKey {
+	if kid == "" {
+		return recipients
+	}
+
+	sorted := make([]*RecipientWrappedKey, 0, len(recipients))
+
+	for _, rec := range recipients {
+		if rec.KID == kid {
+			sorted = append(sorted, rec)
+		}
+	}
+
+	for _, rec := range recipients {
+		if rec.KID == "" {
+			sorted = append(sorted, rec)
+		}
+	}
+
+	for _, rec := range recipients {
+		if rec.KID != kid && rec.KID != "" {
+			sorted = append(sorted, rec)
+		}
+	}
+
+	return sorted
+}
+
+// SortEntriesByRecipientKIDs returns the entries of the primitive set of a keyset in the order they should try to
+// decrypt the serialized EncryptedData ciphertext: the entries whose primitive has the KID of one of the recipients
+// first, then the other entries for a trial decryption since the sender may identify the keys differently. The order
+// is left unchanged when ciphertext isn't a serialized EncryptedData. The primitives provide their KID with
+// api.KIDProvider.
+func SortEntriesByRecipientKIDs(entries []*primitiveset.Entry, ciphertext []byte) []*primitiveset.Entry {
+	if len(entries) < 2 {
+		return entries
+	}
+
+	encData := new(EncryptedData)
+
+	if err := json.Unmarshal(ciphertext, encData); err != nil {
+		return entries
+	}
+
+	kids := make(map[string]bool, len(encData.Recipients))
+
+	for _, rec := range encData.Recipients {
+		if rec != nil && rec.KID != "" {
+			kids[rec.KID] = true
+		}
+	}
+
+	sorted := make([]*primitiveset.Entry, 0, len(entries))
+
+	var others []*primitiveset.Entry
+
+	for _, entry := range entries {
+		if p, ok := entry.Primitive.(api.KIDProvider); ok && kids[p.KID()] {
+			sorted = append(sorted, entry)
+		} else {
+			others = append(others, entry)
+		}
+	}
+
+	return append(sorted, others...)
+}
+
+// NonRawEntries returns the non-raw entries of the primitive set of a keyset that should try to decrypt a ciphertext
+// prefixed with the output prefix prefix: the entries with this prefix first, then the other non-raw entries since the
+// prefix is the key ID of the primary key of the encryption keyset, which is only the key ID of the recipient key when
+// encrypting with its public keyset. It returns nil if prefix isn't a TINK or LEGACY output prefix.
+func NonRawEntries(ps *primitiveset.PrimitiveSet, prefix string) []*primitiveset.Entry {
+	if len(prefix) != cryptofmt.NonRawPrefixSize ||
+		(prefix[0] != cryptofmt.TinkStartByte && prefix[0] != cryptofmt.LegacyStartByte) {
+		return nil
+	}
+
+	entries := append([]*primitiveset.Entry{}, ps.Entries[prefix]...)
+
+	for p, prefixEntries := range ps.Entries {
+		if p != prefix && p != cryptofmt.RawPrefix {
+			entries = append(entries, prefixEntries...)
+		}
+	}
+
+	return entries
 }
 
 // PublicKey mainly to exchange EPK in RecipientWrappedKey
@@ -48,8 +156,95 @@ type PublicKey struct {
 	Type  string `json:"type,omitempty"`
 }
 
-// GetCurveType is a utility function that converts a string EC curve name into an EC curve proto type
+// registeredCurve is an EC curve added by RegisterCurve.
+type registeredCurve struct {
+	curveType commonpb.EllipticCurveType
+	curve     elliptic.Curve
+}
+
+// curveRegistry holds the curves added by RegisterCurve, indexed by their registered name, by the name of their
+// proto type and by the name of their params since the primitives refer to curves by either of them.
+// nolint:gochecknoglobals
+var curveRegistry = struct {
+	sync.RWMutex
+	curves map[string]registeredCurve
+}{curves: map[string]registeredCurve{}}
+
+// RegisterCurve adds the EC curve with the given name and proto type to the curves supported by GetCurveType,
+// GetCurve and the composite primitives, ie to support curves other than the NIST P curves. The proto type must not
+// be used by another curve, as the primitives identify the curves of their keys by their type. The name, the proto
+// type and the params name of the curve must not be the name of a supported curve.
+func RegisterCurve(name string, curveType commonpb.EllipticCurveType, curve elliptic.Curve) error {
+	if name == "" {
+		return errors.New("registerCurve: curve name is mandatory")
+	}
+
+	if curve == nil {
+		return fmt.Errorf("registerCurve: curve %s is nil", name)
+	}
+
+	if curveType == commonpb.EllipticCurveType_UNKNOWN_CURVE {
+		return fmt.Errorf("registerCurve: curve %s has an unknown curve type", name)
+	}
+
+	curveRegistry.Lock()
+	defer curveRegistry.Unlock()
+
+	for _, c := range curveRegistry.curves {
+		if c.curveType == curveType {
+			return fmt.Errorf("registerCurve: curve type %s is already registered", curveType)
+		}
+	}
+
+	names := []string{name, curveType.String(), curve.Params().Name}
+
+	for _, n := range names {
+		if _, err := builtInCurveType(n); err == nil {
+			return fmt.Errorf("registerCurve: curve %s is already supported", n)
+		}
+
+		if _, ok := curveRegistry.curves[n]; ok {
+			return fmt.Errorf("registerCurve: curve %s is already registered", n)
+		}
+	}
+
+	for _, n := range names {
+		curveRegistry.curves[n] = registeredCurve{curveType: curveType, curve: curve}
+	}
+
+	return nil
+}
+
+// registered returns the curve registered under the given name, if any.
+func registered(curve string) (registeredCurve, bool) {
+	curveRegistry.RLock()
+	defer curveRegistry.RUnlock()
+
+	c, ok := curveRegistry.curves[curve]
+
+	return c, ok
+}
+
+// GetCurve returns the EC curve with the given name, either a NIST P curve or a curve added by RegisterCurve.
+func GetCurve(curve string) (elliptic.Curve, error) {
+	if c, ok := registered(curve); ok {
+		return c.curve, nil
+	}
+
+	return hybrid.GetCurve(curve)
+}
+
+// GetCurveType is a utility function that converts a string EC curve name into an EC curve proto type. Curve25519 is
+// used by OKP keys for X25519 key agreement. Curves added by RegisterCurve are converted into their registered type.
 func GetCurveType(curve string) (commonpb.EllipticCurveType, error) {
+	if c, ok := registered(curve); ok {
+		return c.curveType, nil
+	}
+
+	return builtInCurveType(curve)
+}
+
+func builtInCurveType(curve string) (commonpb.EllipticCurveType, error) {
 	switch curve {
 	case "secp256r1", "NIST_P256", "P-256", "EllipticCurveType_NIST_P256":
 		return commonpb.EllipticCurveType_NIST_P256, nil
@@ -57,6 +252,8 @@ func GetCurveType(curve string) (commonpb.EllipticCurveType, error) {
 		return commonpb.EllipticCurveType_NIST_P384, nil
 	case "secp521r1", "NIST_P521", "P-521", "EllipticCurveType_NIST_P521":
 		return commonpb.EllipticCurveType_NIST_P521, nil
+	case "X25519", "Curve25519", "CURVE25519", "EllipticCurveType_CURVE25519":
+		return commonpb.EllipticCurveType_CURVE25519, nil
 	default:
 		return commonpb.EllipticCurveType_UNKNOWN_CURVE, fmt.Errorf("curve %s not supported", curve)
 	}
@@ -0,0 +1,167 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package composite
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/api"
+	compositepb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
+)
+
+// RecipientFailure is the reason a recipient wrapped key of a message failed to be decrypted by a key.
+type RecipientFailure string
+
+const (
+	// RecipientAlgMismatch is the failure of a recipient whose CEK is wrapped with another key wrapping algorithm than
+	// the one of the key, the recipient is skipped.
+	RecipientAlgMismatch RecipientFailure = "key wrapping algorithm mismatch"
+	// RecipientCurveUnsupported is the failure of a recipient whose EPK is on an unsupported curve or on another curve
+	// than the key.
+	RecipientCurveUnsupported RecipientFailure = "curve unsupported"
+	// RecipientKIDMismatch is the failure of a recipient with another KID than the key whose CEK unwrap failed, the
+	// recipient is likely not the key.
+	RecipientKIDMismatch RecipientFailure = "KID mismatch"
+	// RecipientUnwrapFailed is the failure of a recipient with the KID of the key (or without KID) whose CEK unwrap
+	// failed, ie a corrupted wrapped CEK or KDF inputs (algorithm ID, party info) other than the sender's.
+	RecipientUnwrapFailed RecipientFailure = "CEK unwrap failed"
+	// RecipientTagInvalid is the failure of a recipient whose CEK was unwrapped but the content decryption failed, ie
+	// a corrupted ciphertext or another AAD than the sender's.
+	RecipientTagInvalid RecipientFailure = "tag invalid"
+)
+
+// RecipientError records why a recipient wrapped key of a message failed to be decrypted by a key.
+type RecipientError struct {
+	// KID is the KID of the recipient wrapped key
+	KID    string
+	Reason RecipientFailure
+	// Err is the underlying error, it is nil for skipped recipients
+	Err error
+}
+
+// NewRecipientError returns the error of the recipient wrapped key rec whose CEK unwrap failed with err for the key
+// identified by kid on curve c, c being nil for X25519 keys. The failure is RecipientCurveUnsupported if the EPK of
+// rec isn't on the curve of the key, RecipientKIDMismatch if rec and the key have different KIDs and
+// RecipientUnwrapFailed otherwise.
+func NewRecipientError(rec *RecipientWrappedKey, kid string, c elliptic.Curve, err error) *RecipientError {
+	recErr := &RecipientError{KID: rec.KID, Err: err}
+
+	switch {
+	case !isOnKeyCurve(&rec.EPK, c):
+		recErr.Reason = RecipientCurveUnsupported
+	case rec.KID != "" && kid != "" && rec.KID != kid:
+		recErr.Reason = RecipientKIDMismatch
+	default:
+		recErr.Reason = RecipientUnwrapFailed
+	}
+
+	return recErr
+}
+
+// isOnKeyCurve returns true if epk is a key on the curve c of a recipient key, or an X25519 key when c is nil.
+func isOnKeyCurve(epk *PublicKey, c elliptic.Curve) bool {
+	if c == nil {
+		return epk.Type == compositepb.KeyType_OKP.String()
+	}
+
+	epkCurve, err := GetCurve(epk.Curve)
+
+	return err == nil && epkCurve == c
+}
+
+func (e *RecipientError) String() string {
+	if e.Err == nil {
+		return fmt.Sprintf("recipient '%s': %s", e.KID, e.Reason)
+	}
+
+	return fmt.Sprintf("recipient '%s': %s: %v", e.KID, e.Reason, e.Err)
+}
+
+// KeyDecryptError is returned by the composite decryption primitives when a key fails to decrypt a message, it
+// records the failure of every recipient wrapped key of the message tried by the key. Its message is the message of
+// the underlying error, Detail describes the failures.
+type KeyDecryptError struct {
+	// KID is the KID of the key
+	KID        string
+	Recipients []*RecipientError
+	err        error
+}
+
+// NewKeyDecryptError returns the error err of the key identified by kid which failed to decrypt the recipients
+// wrapped keys of a message with the given errors.
+func NewKeyDecryptError(kid string, recipients []*RecipientError, err error) *KeyDecryptError {
+	return &KeyDecryptError{KID: kid, Recipients: recipients, err: err}
+}
+
+// KeyDecryptErrorOf returns the error err returned by the decryption primitive p as a KeyDecryptError. Errors
+// returned before trying the recipients (ie a malformed ciphertext) are returned with the KID of p, if p is an
+// api.KIDProvider, and no recipients.
+func KeyDecryptErrorOf(p interface{}, err error) *KeyDecryptError {
+	var keyErr *KeyDecryptError
+	if errors.As(err, &keyErr) {
+		return keyErr
+	}
+
+	keyErr = &KeyDecryptError{err: err}
+
+	if kidProvider, ok := p.(api.KIDProvider); ok {
+		keyErr.KID = kidProvider.KID()
+	}
+
+	return keyErr
+}
+
+func (e *KeyDecryptError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *KeyDecryptError) Unwrap() error {
+	return e.err
+}
+
+// Detail describes the failure of every recipient tried by the key.
+func (e *KeyDecryptError) Detail() string {
+	details := make([]string, 0, len(e.Recipients))
+
+	for _, rec := range e.Recipients {
+		details = append(details, rec.String())
+	}
+
+	return fmt.Sprintf("key '%s': %s: [%s]", e.KID, e.err, strings.Join(details, ", "))
+}
+
+// DecryptError is returned by the composite decryption factories when no key of a keyset decrypts a message, it
+// holds the errors of the keys which tried to decrypt it. Its message is generic, Detail describes the failures.
+type DecryptError struct {
+	Keys []*KeyDecryptError
+	msg  string
+}
+
+// NewDecryptError returns a decryption error with the message msg and the errors of the keys which tried to decrypt
+// the message.
+func NewDecryptError(msg string, keys []*KeyDecryptError) *DecryptError {
+	return &DecryptError{Keys: keys, msg: msg}
+}
+
+func (e *DecryptError) Error() string {
+	return e.msg
+}
+
+// Detail describes the failure of every recipient tried by every key.
+func (e *DecryptError) Detail() string {
+	details := make([]string, 0, len(e.Keys))
+
+	for _, key := range e.Keys {
+		details = append(details, key.Detail())
+	}
+
+	return fmt.Sprintf("%s: [%s]", e.msg, strings.Join(details, "; "))
+}
@@ -0,0 +1,94 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package composite
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"math/big"
+)
+
+const (
+	// CompressedPointFormat is the EC point format of composite keys setting EPKs with compressed points (ie 'x' only,
+	// 'y' is implied). It matches the tink EcPointFormat_COMPRESSED proto value name.
+	CompressedPointFormat = "COMPRESSED"
+
+	// compressed points are prefixed with 0x02 for an even 'y' or 0x03 for an odd 'y'
+	compressedEvenY = 0x02
+	compressedOddY  = 0x03
+
+	bitsPerByte = 8
+)
+
+var errInvalidCompressedPoint = errors.New("invalid compressed EC point")
+
+// CompressPoint encodes the point (x, y) of curve c in compressed form as per SEC 1, section 2.3.3.
+func CompressPoint(c elliptic.Curve, x, y *big.Int) []byte {
+	byteLen := curveByteSize(c)
+	compressed := make([]byte, 1+byteLen)
+	compressed[0] = compressedEvenY | byte(y.Bit(0))
+
+	xBytes := x.Bytes()
+	copy(compressed[1+byteLen-len(xBytes):], xBytes)
+
+	return compressed
+}
+
+// DecompressPoint decodes the compressed point data of curve c as per SEC 1, section 2.3.4 and returns its (x, y)
+// coordinates.
+func DecompressPoint(c elliptic.Curve, data []byte) (*big.Int, *big.Int, error) {
+	params := c.Params()
+	byteLen := curveByteSize(c)
+
+	if len(data) != 1+byteLen || (data[0] != compressedEvenY && data[0] != compressedOddY) {
+		return nil, nil, errInvalidCompressedPoint
+	}
+
+	x := new(big.Int).SetBytes(data[1:])
+	if x.Cmp(params.P) >= 0 {
+		return nil, nil, errInvalidCompressedPoint
+	}
+
+	// y² = x³ - 3x + b
+	y := new(big.Int).Mul(x, x)
+	y.Mul(y, x)
+
+	threeX := new(big.Int).Lsh(x, 1)
+	threeX.Add(threeX, x)
+
+	y.Sub(y, threeX)
+	y.Add(y, params.B)
+	y.Mod(y, params.P)
+
+	if y.ModSqrt(y, params.P) == nil {
+		return nil, nil, errInvalidCompressedPoint
+	}
+
+	if byte(y.Bit(0)) != data[0]&1 {
+		y.Sub(params.P, y)
+	}
+
+	if !c.IsOnCurve(x, y) {
+		return nil, nil, errInvalidCompressedPoint
+	}
+
+	return x, y, nil
+}
+
+func curveByteSize(c elliptic.Curve) int {
+	return (c.Params().BitSize + bitsPerByte - 1) / bitsPerByte
+}
+
+// ECPoint returns the (x, y) coordinates of the EC public key pub of curve c. If pub's Y is not set, its X is
+// considered compressed and is decompressed first.
+func ECPoint(c elliptic.Curve, pub *PublicKey) (*big.Int, *big.Int, error) {
+	if len(pub.Y) == 0 {
+		return DecompressPoint(c, pub.X)
+	}
+
+	return new(big.Int).SetBytes(pub.X), new(big.Int).SetBytes(pub.Y), nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package composite
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressDecompressPoint(t *testing.T) {
+	for _, c := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		curve := c
+
+		t.Run("compress and decompress "+curve.Params().Name+" points", func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				pvt, err := ecdsa.GenerateKey(curve, rand.Reader)
+				require.NoError(t, err)
+
+				compressed := CompressPoint(curve, pvt.X, pvt.Y)
+				require.Len(t, compressed, 1+curveByteSize(curve))
+
+				x, y, err := DecompressPoint(curve, compressed)
+				require.NoError(t, err)
+				require.Equal(t, pvt.X, x)
+				require.Equal(t, pvt.Y, y)
+
+				x, y, err = ECPoint(curve, &PublicKey{X: compressed})
+				require.NoError(t, err)
+				require.Equal(t, pvt.X, x)
+				require.Equal(t, pvt.Y, y)
+
+				x, y, err = ECPoint(curve, &PublicKey{X: pvt.X.Bytes(), Y: pvt.Y.Bytes()})
+				require.NoError(t, err)
+				require.Equal(t, pvt.X, x)
+				require.Equal(t, pvt.Y, y)
+			}
+		})
+	}
+
+	t.Run("decompress invalid points", func(t *testing.T) {
+		curve := elliptic.P256()
+
+		pvt, err := ecdsa.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+
+		compressed := CompressPoint(curve, pvt.X, pvt.Y)
+
+		_, _, err = DecompressPoint(curve, compressed[1:])
+		require.EqualError(t, err, errInvalidCompressedPoint.Error())
+
+		badPrefix := append([]byte{0x04}, compressed[1:]...)
+
+		_, _, err = DecompressPoint(curve, badPrefix)
+		require.EqualError(t, err, errInvalidCompressedPoint.Error())
+
+		badX := append([]byte{compressedEvenY}, curve.Params().P.Bytes()...)
+
+		_, _, err = DecompressPoint(curve, badX)
+		require.EqualError(t, err, errInvalidCompressedPoint.Error())
+	})
+}
@@ -21,7 +21,9 @@ SPDX-License-Identifier: Apache-2.0
 //  import (
 //      "bytes"
 //
+//      "github.com/google/tink/go/aead"
 //      "github.com/google/tink/go/keyset"
+//      commonpb "github.com/google/tink/go/proto/common_go_proto"
 //
 //      "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
 //      "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/ecdh1pu"
@@ -30,7 +32,12 @@ SPDX-License-Identifier: Apache-2.0
 //
 //  func main() {
 //      // create recipient side keyset handle
-//      recKH, err := keyset.NewHandle(ecdh1pu.ECDH1PU256KWAES256GCMKeyTemplate())
+//      kt, err := ecdh1pu.NewECDH1PUKeyTemplate(commonpb.EllipticCurveType_NIST_P256, aead.AES256GCMKeyTemplate())
+//      if err != nil {
+//          //handle error
+//      }
+//
+//      recKH, err := keyset.NewHandle(kt)
 //      if err != nil {
 //          //handle error
 //      }
@@ -46,7 +53,7 @@ SPDX-License-Identifier: Apache-2.0
 //		// handle error...
 //
 //      // now create sender keyset handle
-//      sKH, err := keyset.NewHandle(ecdh1pu.ECDH1PU256KWAES256GCMKeyTemplate())
+//      sKH, err := keyset.NewHandle(kt)
 //      // handle error...
 //
 //      // add recipient public key to the sender key handle (assuming the recipient have shared it with the sender)
@@ -55,6 +62,10 @@ SPDX-License-Identifier: Apache-2.0
 //      // for more recipient keys pass in a list: []*composite.PublicKey{recECPubKey1, recECPubKey2, etc.})
 //      // at least 1 recipient is required.
 //
+//      // optionally bind the sender and recipients identities to the KDF with PartyUInfo (apu) and PartyVInfo (apv),
+//      // the recipients must set the same values on their key handles before decrypting:
+//      sKH, err = ecdh1pu.SetPartyInfo(sKH, apu, apv)
+//
 //      // extract sender public keyset handle to encrypt
 //      senderPubKH, err := sKH.Public()
 //      if err != nil {
@@ -86,6 +97,10 @@ SPDX-License-Identifier: Apache-2.0
 //      if err != nil {
 //          // handle error
 //      }
+//
+//      // alternatively, ecdh1pu.Encrypt and ecdh1pu.Decrypt fetch the primitives from the keyset handles:
+//      ct, err = ecdh1pu.Encrypt(sKH, []byte("secret message"), []byte("some aad"))
+//      pt, err = ecdh1pu.Decrypt(refRecKH, ct, []byte("some aad"))
 //  }
 package ecdh1pu
 
@@ -93,13 +108,15 @@ import (
 	"fmt"
 
 	"github.com/google/tink/go/core/registry"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
 )
 
 // TODO - find a better way to setup tink than init.
 // nolint: gochecknoinits
 func init() {
 	// TODO - avoid the tink registry singleton (if possible).
-	err := registry.RegisterKeyManager(newECDH1PUPrivateKeyManager())
+	err := composite.RegisterKeyWrappingKeyManager(newECDH1PUPrivateKeyManager())
 	if err != nil {
 		panic(fmt.Sprintf("ecdh1pu.init() failed: %v", err))
 	}
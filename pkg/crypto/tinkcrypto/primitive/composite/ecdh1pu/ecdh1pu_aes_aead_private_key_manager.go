@@ -16,9 +16,11 @@ import (
 	"github.com/google/tink/go/core/registry"
 	hybrid "github.com/google/tink/go/hybrid/subtle"
 	"github.com/google/tink/go/keyset"
+	tinkcommonpb "github.com/google/tink/go/proto/common_go_proto"
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/api"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/ecdh1pu/subtle"
 	commonpb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
 	ecdh1pupb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/ecdh1pu_aead_go_proto"
@@ -40,6 +42,12 @@ type ecdh1puAESPrivateKeyManager struct{}
 // Assert that ecdh1puAESPrivateKeyManager implements the PrivateKeyManager interface.
 var _ registry.PrivateKeyManager = (*ecdh1puAESPrivateKeyManager)(nil)
 
+// Assert that ecdh1puAESPrivateKeyManager lists its supported curves.
+var _ composite.CurvesSupporter = (*ecdh1puAESPrivateKeyManager)(nil)
+
+// Assert that ecdh1puAESPrivateKeyManager supports composite.ValidateKeyTemplate.
+var _ composite.KeyTemplateValidator = (*ecdh1puAESPrivateKeyManager)(nil)
+
 // newECDH1PUPrivateKeyManager creates a new ecdh1puAESPrivateKeyManager.
 func newECDH1PUPrivateKeyManager() *ecdh1puAESPrivateKeyManager {
 	return new(ecdh1puAESPrivateKeyManager)
@@ -78,7 +86,7 @@ func (km *ecdh1puAESPrivateKeyManager) Primitive(serializedKey []byte) (interfac
 			"execution")
 	}
 
-	crv, err := hybrid.GetCurve(key.PublicKey.Params.KwParams.Sender.CurveType.String())
+	crv, err := composite.GetCurve(key.PublicKey.Params.KwParams.Sender.CurveType.String())
 	if err != nil {
 		return nil, fmt.Errorf("ecdh1pu_aes_private_key_manager: GetCurve failed: %w", err)
 	}
@@ -91,7 +99,10 @@ func (km *ecdh1puAESPrivateKeyManager) Primitive(serializedKey []byte) (interfac
 		},
 	}
 
-	return subtle.NewECDH1PUAEADCompositeDecrypt(senderPubKey, recPvtKey, ptFormat, rEnc, commonpb.KeyType_EC), nil
+	kwParams := key.PublicKey.Params.KwParams
+
+	return subtle.NewECDH1PUAEADCompositeDecrypt(senderPubKey, recPvtKey, ptFormat, rEnc, commonpb.KeyType_EC,
+		kwParams.Apu, kwParams.Apv, key.PublicKey.KID), nil
 }
 
 // NewKey creates a new key according to the specification of ECDH1PUPrivateKey format.
@@ -182,6 +193,102 @@ func (km *ecdh1puAESPrivateKeyManager) TypeURL() string {
 	return ecdh1puAESPrivateKeyTypeURL
 }
 
+// SupportedCurves returns the curves of the keys managed by this key manager.
+func (km *ecdh1puAESPrivateKeyManager) SupportedCurves() []string {
+	return []string{elliptic.P256().Params().Name, elliptic.P384().Params().Name, elliptic.P521().Params().Name}
+}
+
+// ValidateKeyFormat parses the given serialized ECDH1PUKeyFormat and validates its curve, content encryption and point
+// format.
+func (km *ecdh1puAESPrivateKeyManager) ValidateKeyFormat(serializedKeyFormat []byte) error {
+	keyFormat := new(ecdh1pupb.Ecdh1PuAeadKeyFormat)
+
+	err := proto.Unmarshal(serializedKeyFormat, keyFormat)
+	if err != nil {
+		return fmt.Errorf("ecdh1pu_aes_private_key_manager: failed to unmarshal key format: %w", err)
+	}
+
+	params := keyFormat.Params
+	if params == nil || params.KwParams == nil || params.EncParams == nil || params.EncParams.AeadEnc == nil {
+		return errors.New("ecdh1pu_aes_private_key_manager: key format params are missing")
+	}
+
+	_, err = validateKeyFormat(params)
+	if err != nil {
+		return err
+	}
+
+	_, err = composite.NewRegisterCompositeAEADEncHelper(params.EncParams.AeadEnc)
+	if err != nil {
+		return fmt.Errorf("ecdh1pu_aes_private_key_manager: unsupported content encryption: %w", err)
+	}
+
+	switch params.EcPointFormat {
+	case tinkcommonpb.EcPointFormat_UNCOMPRESSED, tinkcommonpb.EcPointFormat_COMPRESSED:
+	default:
+		return fmt.Errorf("ecdh1pu_aes_private_key_manager: unsupported point format '%s'", params.EcPointFormat)
+	}
+
+	return nil
+}
+
+// SelfEncryptionPrimitives returns the ECDH1PUAEADCompositeEncrypt and ECDH1PUAEADCompositeDecrypt primitives of the
+// given serialized ECDH1PUPrivateKey with the key itself as the sender and the only recipient.
+func (km *ecdh1puAESPrivateKeyManager) SelfEncryptionPrimitives(serializedPrivKey []byte) (api.CompositeEncrypt,
+	api.CompositeDecrypt, error) {
+	privKey := new(ecdh1pupb.Ecdh1PuAeadPrivateKey)
+
+	err := proto.Unmarshal(serializedPrivKey, privKey)
+	if err != nil || privKey.PublicKey == nil || privKey.PublicKey.Params == nil ||
+		privKey.PublicKey.Params.KwParams == nil {
+		return nil, nil, errInvalidECDH1PUAESPrivateKey
+	}
+
+	selfKey := &commonpb.ECPublicKey{
+		KID:       privKey.PublicKey.KID,
+		CurveType: privKey.PublicKey.Params.KwParams.CurveType,
+		KeyType:   commonpb.KeyType_EC,
+		X:         privKey.PublicKey.X,
+		Y:         privKey.PublicKey.Y,
+	}
+
+	privKey.PublicKey.Params.KwParams.Recipients = []*commonpb.ECPublicKey{selfKey}
+	privKey.PublicKey.Params.KwParams.Sender = selfKey
+	privKey.PublicKey.KWD = privKey.KeyValue
+
+	serializedSelfKey, err := proto.Marshal(privKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ecdh1pu_aes_private_key_manager: Proto.Marshal failed: %w", err)
+	}
+
+	p, err := km.Primitive(serializedSelfKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d, ok := p.(api.CompositeDecrypt)
+	if !ok {
+		return nil, nil, errors.New("ecdh1pu_aes_private_key_manager: not a CompositeDecrypt primitive")
+	}
+
+	serializedPubKey, err := proto.Marshal(privKey.PublicKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ecdh1pu_aes_private_key_manager: Proto.Marshal failed: %w", err)
+	}
+
+	p, err = newECDH1PUPublicKeyManager().Primitive(serializedPubKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e, ok := p.(api.CompositeEncrypt)
+	if !ok {
+		return nil, nil, errors.New("ecdh1pu_aes_private_key_manager: not a CompositeEncrypt primitive")
+	}
+
+	return e, d, nil
+}
+
 // validateKey validates the given ECDH1PUPrivateKey and returns the KW curve.
 func (km *ecdh1puAESPrivateKeyManager) validateKey(key *ecdh1pupb.Ecdh1PuAeadPrivateKey) (elliptic.Curve, error) {
 	err := keyset.ValidateKeyVersion(key.Version, ecdh1puAESPrivateKeyVersion)
@@ -194,7 +301,7 @@ func (km *ecdh1puAESPrivateKeyManager) validateKey(key *ecdh1pupb.Ecdh1PuAeadPri
 
 // validateKeyFormat validates the given ECDHESKeyFormat and returns the KW Curve.
 func validateKeyFormat(params *ecdh1pupb.Ecdh1PuAeadParams) (elliptic.Curve, error) {
-	c, err := hybrid.GetCurve(params.KwParams.CurveType.String())
+	c, err := composite.GetCurve(params.KwParams.CurveType.String())
 	if err != nil {
 		return nil, fmt.Errorf("ecdh1pu_aes_private_key_manager: invalid key: %w", err)
 	}
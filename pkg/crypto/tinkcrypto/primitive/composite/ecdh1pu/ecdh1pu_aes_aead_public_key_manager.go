@@ -93,12 +93,14 @@ func (km *ecdh1puPublicKeyManager) Primitive(serializedKey []byte) (interface{},
 
 	ptFormat := ecdh1puPubKey.Params.EcPointFormat.String()
 
-	return subtle.NewECDH1PUAEADCompositeEncrypt(recipientsKeys, senderPrivKey, ptFormat, rEnc, compositepb.KeyType_EC),
-		nil
+	kwParams := ecdh1puPubKey.Params.KwParams
+
+	return subtle.NewECDH1PUAEADCompositeEncrypt(recipientsKeys, senderPrivKey, ptFormat, rEnc, compositepb.KeyType_EC,
+		kwParams.Apu, kwParams.Apv, ecdh1puPubKey.KID), nil
 }
 
 func buildPrivKeyFromProto(key *ecdh1pupb.Ecdh1PuAeadPublicKey) (*hybrid.ECPrivateKey, error) {
-	c, err := hybrid.GetCurve(key.Params.KwParams.CurveType.String())
+	c, err := composite.GetCurve(key.Params.KwParams.CurveType.String())
 	if err != nil {
 		return nil, err
 	}
@@ -160,7 +162,7 @@ func (km *ecdh1puPublicKeyManager) validateRecKey(key *compositepb.ECPublicKey)
 		return fmt.Errorf("ecdh1pu_aes_public_key_manager: GetKeyType error: %w", err)
 	}
 
-	_, err = hybrid.GetCurve(key.CurveType.String())
+	_, err = composite.GetCurve(key.CurveType.String())
 	if err != nil {
 		return fmt.Errorf("ecdh1pu_aes_public_key_manager: GetCurve error: %w", err)
 	}
@@ -0,0 +1,263 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ecdh1pu
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/core/cryptofmt"
+	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+	compositepb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
+	ecdh1pupb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/ecdh1pu_aead_go_proto"
+)
+
+// Encrypt encrypts plaintext with aad for the recipients of the encryption keyset handle kh and returns the
+// serialized composite.EncryptedData. kh must be a sender keyset handle updated with AddRecipientsKeys or be its
+// public keyset handle.
+func Encrypt(kh *keyset.Handle, plaintext, aad []byte) ([]byte, error) {
+	pubKey, err := primaryPublicKey(kh)
+	if err != nil {
+		return nil, fmt.Errorf("ecdh1pu: Encrypt: %w", err)
+	}
+
+	if len(pubKey.Params.KwParams.Recipients) == 0 {
+		return nil, errors.New("ecdh1pu: Encrypt: keyset handle has no recipients keys, it is not an encryption handle")
+	}
+
+	pubKH, err := kh.Public()
+	if err != nil {
+		// kh is already a public keyset handle
+		pubKH = kh
+	}
+
+	e, err := NewECDH1PUEncrypt(pubKH)
+	if err != nil {
+		return nil, fmt.Errorf("ecdh1pu: Encrypt: %w", err)
+	}
+
+	return e.Encrypt(plaintext, aad)
+}
+
+// Decrypt decrypts ciphertext, the serialized composite.EncryptedData returned by Encrypt, with aad using the
+// decryption keyset handle kh. kh must be a recipient private keyset handle updated with AddSenderKey.
+func Decrypt(kh *keyset.Handle, ciphertext, aad []byte) ([]byte, error) {
+	if _, err := decryptionPublicKey(kh); err != nil {
+		return nil, fmt.Errorf("ecdh1pu: Decrypt: %w", err)
+	}
+
+	d, err := NewECDH1PUDecrypt(kh)
+	if err != nil {
+		return nil, fmt.Errorf("ecdh1pu: Decrypt: %w", err)
+	}
+
+	return d.Decrypt(ciphertext, aad)
+}
+
+// DecryptOption configures DecryptWithSender.
+type DecryptOption func(opts *decryptOpts)
+
+type decryptOpts struct {
+	allowedSenders []*composite.PublicKey
+	// restrictSenders is set by AllowedSenders, an empty list of allowed senders allowing no sender
+	restrictSenders bool
+}
+
+// AllowedSenders restricts the senders of the messages decrypted by DecryptWithSender to the given keys. A sender is
+// allowed if one of the keys has the curve and the coordinates of its key, and the same KID when both keys have one.
+func AllowedSenders(senders []*composite.PublicKey) DecryptOption {
+	return func(opts *decryptOpts) {
+		opts.allowedSenders = senders
+		opts.restrictSenders = true
+	}
+}
+
+// DecryptWithSender decrypts ciphertext with aad using the decryption keyset handle kh like Decrypt, and returns the
+// plaintext along with the public key of the sender. The sender key is the key added to kh with AddSenderKey: the
+// ECDH-1PU key unwrapping only succeeds if the message was encrypted with its private key, which authenticates the
+// sender. The SKID of the message, when set, must be the KID of the sender key.
+//
+// With the AllowedSenders option, the message is not decrypted if its sender isn't allowed, and the matching allowed
+// key is returned as the sender.
+func DecryptWithSender(kh *keyset.Handle, ciphertext, aad []byte,
+	opts ...DecryptOption) ([]byte, *composite.PublicKey, error) {
+	decOpts := &decryptOpts{}
+
+	for _, opt := range opts {
+		opt(decOpts)
+	}
+
+	pubKey, err := decryptionPublicKey(kh)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ecdh1pu: DecryptWithSender: %w", err)
+	}
+
+	sender := senderPublicKey(pubKey.Params.KwParams.Sender)
+
+	skid, err := messageSenderKID(ciphertext)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ecdh1pu: DecryptWithSender: %w", err)
+	}
+
+	if skid != "" && sender.KID != "" && skid != sender.KID {
+		return nil, nil, fmt.Errorf("ecdh1pu: DecryptWithSender: message sender '%s' is not the sender key '%s'",
+			skid, sender.KID)
+	}
+
+	if decOpts.restrictSenders {
+		allowed := allowedSender(sender, skid, decOpts.allowedSenders)
+		if allowed == nil {
+			if skid == "" {
+				skid = sender.KID
+			}
+
+			return nil, nil, fmt.Errorf("ecdh1pu: DecryptWithSender: sender '%s' is not an allowed sender", skid)
+		}
+
+		sender = allowed
+	}
+
+	d, err := NewECDH1PUDecrypt(kh)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ecdh1pu: DecryptWithSender: %w", err)
+	}
+
+	pt, err := d.Decrypt(ciphertext, aad)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pt, sender, nil
+}
+
+// decryptionPublicKey returns the primary public key proto of the decryption keyset handle kh, it returns an error if
+// kh isn't a recipient private keyset handle updated with AddSenderKey.
+func decryptionPublicKey(kh *keyset.Handle) (*ecdh1pupb.Ecdh1PuAeadPublicKey, error) {
+	pubKey, err := primaryPublicKey(kh)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pubKey.Params.KwParams.Recipients) > 0 {
+		return nil, errors.New("keyset handle has recipients keys, it is an encryption handle")
+	}
+
+	if pubKey.Params.KwParams.Sender == nil {
+		return nil, errors.New("keyset handle has no sender key, it is not a decryption handle")
+	}
+
+	if _, err = kh.Public(); err != nil {
+		return nil, errors.New("keyset handle is not a private keyset handle")
+	}
+
+	return pubKey, nil
+}
+
+// senderPublicKey converts the sender key proto of a decryption key to a composite public key.
+func senderPublicKey(senderKey *compositepb.ECPublicKey) *composite.PublicKey {
+	return &composite.PublicKey{
+		KID:   senderKey.KID,
+		Type:  senderKey.KeyType.String(),
+		Curve: senderKey.CurveType.String(),
+		X:     senderKey.X,
+		Y:     senderKey.Y,
+	}
+}
+
+// messageSenderKID returns the SKID of ciphertext, the serialized composite.EncryptedData optionally prefixed with the
+// output prefix of the sender key.
+func messageSenderKID(ciphertext []byte) (string, error) {
+	if len(ciphertext) > cryptofmt.NonRawPrefixSize && ciphertext[0] != '{' {
+		ciphertext = ciphertext[cryptofmt.NonRawPrefixSize:]
+	}
+
+	encData := new(composite.EncryptedData)
+
+	err := json.Unmarshal(ciphertext, encData)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal ciphertext: %w", err)
+	}
+
+	return encData.SKID, nil
+}
+
+// allowedSender returns the key of allowed matching the sender key identified by skid in the message, or nil if the
+// sender isn't allowed.
+func allowedSender(sender *composite.PublicKey, skid string, allowed []*composite.PublicKey) *composite.PublicKey {
+	senderCurve, err := composite.GetCurveType(sender.Curve)
+	if err != nil {
+		return nil
+	}
+
+	for _, key := range allowed {
+		curve, err := composite.GetCurveType(key.Curve)
+		if err != nil || curve != senderCurve {
+			continue
+		}
+
+		if new(big.Int).SetBytes(key.X).Cmp(new(big.Int).SetBytes(sender.X)) != 0 ||
+			new(big.Int).SetBytes(key.Y).Cmp(new(big.Int).SetBytes(sender.Y)) != 0 {
+			continue
+		}
+
+		if key.KID != "" && (skid != "" && key.KID != skid || sender.KID != "" && key.KID != sender.KID) {
+			continue
+		}
+
+		return key
+	}
+
+	return nil
+}
+
+// primaryPublicKey returns the primary public key proto of the ECDH-1PU keyset handle kh, kh can be either a private
+// or a public keyset handle.
+func primaryPublicKey(kh *keyset.Handle) (*ecdh1pupb.Ecdh1PuAeadPublicKey, error) {
+	if kh == nil {
+		return nil, errors.New("keyset handle is nil")
+	}
+
+	pubKH, err := kh.Public()
+	if err != nil {
+		// kh is not a private keyset handle, it may already be an ECDH-1PU public keyset handle
+		pubKH = kh
+	}
+
+	memWriter := &keyset.MemReaderWriter{}
+
+	err = pubKH.WriteWithNoSecrets(memWriter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public keyset: %w", err)
+	}
+
+	var primaryKeyData *tinkpb.KeyData
+
+	for _, key := range memWriter.Keyset.Key {
+		if key.KeyId == memWriter.Keyset.PrimaryKeyId {
+			primaryKeyData = key.KeyData
+		}
+	}
+
+	if primaryKeyData == nil || primaryKeyData.TypeUrl != ecdh1puAESPublicKeyTypeURL {
+		return nil, fmt.Errorf("not an ECDH-1PU key: '%s'", primaryKeyData.GetTypeUrl())
+	}
+
+	pubKeyProto := new(ecdh1pupb.Ecdh1PuAeadPublicKey)
+
+	err = proto.Unmarshal(primaryKeyData.Value, pubKeyProto)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal key failed: %w", err)
+	}
+
+	return pubKeyProto, nil
+}
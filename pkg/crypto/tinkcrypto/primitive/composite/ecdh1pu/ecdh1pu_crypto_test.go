@@ -0,0 +1,208 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ecdh1pu
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/keyset"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/keyio"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	recPubKeys, recKHs := createRecipients(t, ECDH1PU256KWAES256GCMKeyTemplate(), 2)
+
+	senderKH, err := keyset.NewHandle(ECDH1PU256KWAES256GCMKeyTemplate())
+	require.NoError(t, err)
+
+	senderKey, err := keyio.ExtractPrimaryPublicKey(senderKH)
+	require.NoError(t, err)
+
+	encKH, err := AddRecipientsKeys(senderKH, recPubKeys)
+	require.NoError(t, err)
+
+	encPubKH, err := encKH.Public()
+	require.NoError(t, err)
+
+	var decKHs []*keyset.Handle
+
+	for _, recKH := range recKHs {
+		decKH, err := AddSenderKey(recKH, senderKey)
+		require.NoError(t, err)
+
+		decKHs = append(decKHs, decKH)
+	}
+
+	pt := []byte("secret message")
+	aad := []byte("aad message")
+
+	for _, kh := range []*keyset.Handle{encKH, encPubKH} {
+		ct, err := Encrypt(kh, pt, aad)
+		require.NoError(t, err)
+
+		for _, decKH := range decKHs {
+			dpt, err := Decrypt(decKH, ct, aad)
+			require.NoError(t, err)
+			require.Equal(t, pt, dpt)
+		}
+	}
+
+	ct, err := Encrypt(encKH, pt, aad)
+	require.NoError(t, err)
+
+	t.Run("test encrypt with a decryption keyset handle", func(t *testing.T) {
+		_, err := Encrypt(decKHs[0], pt, aad)
+		require.EqualError(t, err, "ecdh1pu: Encrypt: keyset handle has no recipients keys, it is not an "+
+			"encryption handle")
+	})
+
+	t.Run("test decrypt with an encryption keyset handle", func(t *testing.T) {
+		_, err := Decrypt(encKH, ct, aad)
+		require.EqualError(t, err, "ecdh1pu: Decrypt: keyset handle has recipients keys, it is an encryption handle")
+	})
+
+	t.Run("test decrypt without the sender key", func(t *testing.T) {
+		_, err := Decrypt(recKHs[0], ct, aad)
+		require.EqualError(t, err, "ecdh1pu: Decrypt: keyset handle has no sender key, it is not a decryption "+
+			"handle")
+	})
+
+	t.Run("test decrypt with a public keyset handle", func(t *testing.T) {
+		decPubKH, err := decKHs[0].Public()
+		require.NoError(t, err)
+
+		_, err = Decrypt(decPubKH, ct, aad)
+		require.EqualError(t, err, "ecdh1pu: Decrypt: keyset handle is not a private keyset handle")
+	})
+
+	t.Run("test decrypt with the wrong aad", func(t *testing.T) {
+		_, err := Decrypt(decKHs[0], ct, []byte("other aad"))
+		require.EqualError(t, err, "ecdh1pu_factory: decryption failed")
+	})
+
+	t.Run("test invalid keyset handles", func(t *testing.T) {
+		_, err := Encrypt(nil, pt, aad)
+		require.EqualError(t, err, "ecdh1pu: Encrypt: keyset handle is nil")
+
+		_, err = Decrypt(nil, ct, aad)
+		require.EqualError(t, err, "ecdh1pu: Decrypt: keyset handle is nil")
+
+		aeadKH, err := keyset.NewHandle(aead.AES256GCMKeyTemplate())
+		require.NoError(t, err)
+
+		_, err = Encrypt(aeadKH, pt, aad)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ecdh1pu: Encrypt: failed to read public keyset")
+
+		_, err = Decrypt(aeadKH, ct, aad)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ecdh1pu: Decrypt: failed to read public keyset")
+	})
+}
+
+func TestDecryptWithSender(t *testing.T) {
+	recPubKeys, recKHs := createRecipients(t, ECDH1PU256KWAES256GCMKeyTemplate(), 1)
+
+	senderKH, err := keyset.NewHandle(ECDH1PU256KWAES256GCMKeyTemplate())
+	require.NoError(t, err)
+
+	senderKey, err := keyio.ExtractPrimaryPublicKey(senderKH)
+	require.NoError(t, err)
+
+	otherSenderKey, _ := createRecipient(t, ECDH1PU256KWAES256GCMKeyTemplate())
+
+	encKH, err := AddRecipientsKeys(senderKH, append(recPubKeys, otherSenderKey))
+	require.NoError(t, err)
+
+	senderKey.KID = "sender-kid"
+
+	decKH, err := AddSenderKey(recKHs[0], senderKey)
+	require.NoError(t, err)
+
+	pt := []byte("secret message")
+	aad := []byte("aad message")
+
+	ct, err := Encrypt(encKH, pt, aad)
+	require.NoError(t, err)
+
+	// withSKID returns ct with the given sender KID, which isn't authenticated
+	withSKID := func(t *testing.T, skid string) []byte {
+		t.Helper()
+
+		encData := &composite.EncryptedData{}
+		require.NoError(t, json.Unmarshal(ct, encData))
+
+		encData.SKID = skid
+
+		ctWithSKID, err := json.Marshal(encData)
+		require.NoError(t, err)
+
+		return ctWithSKID
+	}
+
+	t.Run("test decrypt returns the sender key", func(t *testing.T) {
+		for _, msg := range [][]byte{ct, withSKID(t, "sender-kid")} {
+			dpt, sender, err := DecryptWithSender(decKH, msg, aad)
+			require.NoError(t, err)
+			require.Equal(t, pt, dpt)
+			require.Equal(t, "sender-kid", sender.KID)
+			require.Equal(t, senderKey.X, sender.X)
+			require.Equal(t, senderKey.Y, sender.Y)
+		}
+	})
+
+	t.Run("test decrypt with allowed senders", func(t *testing.T) {
+		allowedKey := &composite.PublicKey{
+			KID:   "sender-kid",
+			Type:  senderKey.Type,
+			Curve: "P-256",
+			X:     senderKey.X,
+			Y:     senderKey.Y,
+		}
+
+		dpt, sender, err := DecryptWithSender(decKH, ct, aad, AllowedSenders([]*composite.PublicKey{
+			otherSenderKey, allowedKey,
+		}))
+		require.NoError(t, err)
+		require.Equal(t, pt, dpt)
+		require.Same(t, allowedKey, sender)
+	})
+
+	t.Run("test decrypt from a sender which isn't allowed", func(t *testing.T) {
+		otherKIDKey := *senderKey
+		otherKIDKey.KID = "other-kid"
+
+		for _, allowed := range [][]*composite.PublicKey{nil, {otherSenderKey}, {&otherKIDKey}} {
+			_, _, err := DecryptWithSender(decKH, ct, aad, AllowedSenders(allowed))
+			require.EqualError(t, err, "ecdh1pu: DecryptWithSender: sender 'sender-kid' is not an allowed sender")
+		}
+	})
+
+	t.Run("test decrypt with another sender KID in the message", func(t *testing.T) {
+		_, _, err := DecryptWithSender(decKH, withSKID(t, "other-kid"), aad)
+		require.EqualError(t, err, "ecdh1pu: DecryptWithSender: message sender 'other-kid' is not the sender key "+
+			"'sender-kid'")
+	})
+
+	t.Run("test decrypt failures", func(t *testing.T) {
+		_, _, err := DecryptWithSender(encKH, ct, aad)
+		require.EqualError(t, err, "ecdh1pu: DecryptWithSender: keyset handle has recipients keys, it is an "+
+			"encryption handle")
+
+		_, _, err = DecryptWithSender(decKH, []byte("not a message"), aad)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ecdh1pu: DecryptWithSender: failed to unmarshal ciphertext")
+
+		_, _, err = DecryptWithSender(decKH, ct, []byte("other aad"))
+		require.EqualError(t, err, "ecdh1pu_factory: decryption failed")
+	})
+}
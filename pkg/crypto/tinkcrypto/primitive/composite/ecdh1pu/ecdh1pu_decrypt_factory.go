@@ -15,6 +15,7 @@ import (
 	"github.com/google/tink/go/core/registry"
 	"github.com/google/tink/go/keyset"
 
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/api"
 )
 
@@ -66,31 +67,37 @@ func newDecryptPrimitiveSet(ps *primitiveset.PrimitiveSet) (*decryptPrimitiveSet
 // additional authenticated data. It returns the corresponding plaintext if the
 // ciphertext is authenticated.
 func (a *decryptPrimitiveSet) Decrypt(ct, aad []byte) ([]byte, error) {
-	// try non-raw keys
+	var keyErrs []*composite.KeyDecryptError
+
+	// try non-raw keys, the recipients keys may have another prefix than the sender's primary key
 	prefixSize := cryptofmt.NonRawPrefixSize
 	if len(ct) > prefixSize {
 		prefix := ct[:prefixSize]
 		ctNoPrefix := ct[prefixSize:]
 
-		entries, err := a.ps.EntriesForPrefix(string(prefix))
-		if err == nil {
-			for i := 0; i < len(entries); i++ {
-				p, ok := (entries[i].Primitive).(api.CompositeDecrypt)
-				if !ok {
-					return nil, errors.New("ecdh1pu_factory: not a CompositeDecrypt primitive")
-				}
-
-				pt, e := p.Decrypt(ctNoPrefix, aad)
-				if e == nil {
-					return pt, nil
-				}
+		entries := composite.SortEntriesByRecipientKIDs(composite.NonRawEntries(a.ps, string(prefix)), ctNoPrefix)
+
+		for i := 0; i < len(entries); i++ {
+			p, ok := (entries[i].Primitive).(api.CompositeDecrypt)
+			if !ok {
+				return nil, errors.New("ecdh1pu_factory: not a CompositeDecrypt primitive")
 			}
+
+			pt, e := p.Decrypt(ctNoPrefix, aad)
+			if e == nil {
+				return pt, nil
+			}
+
+			keyErrs = append(keyErrs, composite.KeyDecryptErrorOf(p, e))
 		}
 	}
 
-	// try raw keys
+	// try raw keys, starting with the keys of the recipients since keysets may hold several keys, ie old keys kept
+	// after a key rotation to decrypt the messages still sent to them
 	entries, err := a.ps.RawEntries()
 	if err == nil {
+		entries = composite.SortEntriesByRecipientKIDs(entries, ct)
+
 		for i := 0; i < len(entries); i++ {
 			p, ok := (entries[i].Primitive).(api.CompositeDecrypt)
 			if !ok {
@@ -101,9 +108,11 @@ func (a *decryptPrimitiveSet) Decrypt(ct, aad []byte) ([]byte, error) {
 			if e == nil {
 				return pt, nil
 			}
+
+			keyErrs = append(keyErrs, composite.KeyDecryptErrorOf(p, e))
 		}
 	}
 
 	// nothing worked
-	return nil, errors.New("ecdh1pu_factory: decryption failed")
+	return nil, composite.NewDecryptError("ecdh1pu_factory: decryption failed", keyErrs)
 }
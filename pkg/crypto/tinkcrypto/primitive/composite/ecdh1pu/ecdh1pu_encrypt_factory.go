@@ -61,7 +61,8 @@ func newEncryptPrimitiveSet(ps *primitiveset.PrimitiveSet) (*encryptPrimitiveSet
 }
 
 // Encrypt encrypts the given plaintext using the recipient public key found in the enclosed primitive.
-// It returns the ciphertext being a serialized JWE []byte.
+// It returns the ciphertext being a serialized JWE []byte, prefixed with the output prefix of the primary key unless
+// it's a RAW key.
 func (a *encryptPrimitiveSet) Encrypt(pt, aad []byte) ([]byte, error) {
 	primary := a.ps.Primary
 
@@ -70,5 +71,14 @@ func (a *encryptPrimitiveSet) Encrypt(pt, aad []byte) ([]byte, error) {
 		return nil, errors.New("ecdh1pu_factory: not a CompositeEncrypt primitive")
 	}
 
-	return p.Encrypt(pt, aad)
+	ct, err := p.Encrypt(pt, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	if primary.Prefix == "" {
+		return ct, nil
+	}
+
+	return append([]byte(primary.Prefix), ct...), nil
 }
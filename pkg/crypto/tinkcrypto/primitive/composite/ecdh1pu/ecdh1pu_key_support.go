@@ -55,7 +55,30 @@ func AddSenderKey(recipientKH *keyset.Handle, senderKey *composite.PublicKey) (*
 	return addKeysToHandle(recipientKH, []*compositepb.ECPublicKey{senderKeyPb}, fnName)
 }
 
+// SetPartyInfo sets the PartyUInfo (apu) and PartyVInfo (apv) values consumed by the One-Step KDF to the primary key of
+// the keyset handle kh and returns an updated handle. Both the sender and the recipients handles must be updated with
+// the same values. kh must contain a keyset of private keys. It will return an error if it points to a public key.
+func SetPartyInfo(kh *keyset.Handle, apu, apv []byte) (*keyset.Handle, error) {
+	return updateKeyHandle(kh, "SetPartyInfo", func(key *ecdh1pupb.Ecdh1PuAeadPrivateKey) {
+		key.PublicKey.Params.KwParams.Apu = apu
+		key.PublicKey.Params.KwParams.Apv = apv
+	})
+}
+
 func addKeysToHandle(kh *keyset.Handle, keysPbs []*compositepb.ECPublicKey, fnName string) (*keyset.Handle, error) {
+	return updateKeyHandle(kh, fnName, func(key *ecdh1pupb.Ecdh1PuAeadPrivateKey) {
+		switch fnName {
+		case "AddRecipientsKeys":
+			key.PublicKey.Params.KwParams.Recipients = keysPbs
+			key.PublicKey.KWD = key.KeyValue // key wrap using sender key for recipients needs this
+		case "AddSenderKey":
+			key.PublicKey.Params.KwParams.Sender = keysPbs[0]
+		}
+	})
+}
+
+func updateKeyHandle(kh *keyset.Handle, fnName string,
+	update func(key *ecdh1pupb.Ecdh1PuAeadPrivateKey)) (*keyset.Handle, error) {
 	_, err := kh.Public()
 	if err != nil && strings.Contains(err.Error(), "keyset contains a non-private key") {
 		return nil, fmt.Errorf("%s: keyset.Handle points to a public key. It must point to a priviate key",
@@ -79,13 +102,7 @@ func addKeysToHandle(kh *keyset.Handle, keysPbs []*compositepb.ECPublicKey, fnNa
 
 	// finally set the corresponding key in the protobuf, update keyset in memWriter and read it to get an updated
 	// *keyset.Handle ready for crypto primitive execution
-	switch fnName {
-	case "AddRecipientsKeys":
-		ecdh1privKeyPb.PublicKey.Params.KwParams.Recipients = keysPbs
-		ecdh1privKeyPb.PublicKey.KWD = ecdh1privKeyPb.KeyValue // key wrap using sender key for recipients needs this
-	case "AddSenderKey":
-		ecdh1privKeyPb.PublicKey.Params.KwParams.Sender = keysPbs[0]
-	}
+	update(ecdh1privKeyPb)
 
 	ks.Key[idx].KeyData.Value, err = proto.Marshal(ecdh1privKeyPb)
 	if err != nil {
@@ -7,6 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package ecdh1pu
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/golang/protobuf/proto"
 	"github.com/google/tink/go/aead"
 	commonpb "github.com/google/tink/go/proto/common_go_proto"
@@ -23,8 +26,10 @@ import (
 //  - Content Encryption: AES256-GCM
 //  - KDF: One-Step KDF as per https://tools.ietf.org/html/draft-madden-jose-ecdh-1pu-03#section-2.2
 // Keys from this template represent a valid recipient (or sender) public/private key pairs and can be stored in the KMS
+//
+// Deprecated: use NewECDH1PUKeyTemplate, it returns an error instead of panicking.
 func ECDH1PU256KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
-	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P256)
+	return mustCreateKeyTemplate(commonpb.EllipticCurveType_NIST_P256, aead.AES256GCMKeyTemplate())
 }
 
 // ECDH1PU384KWAES256GCMKeyTemplate is a KeyTemplate that generates an ECDH-1PU P-384 key wrapping and AES256-GCM CEK.
@@ -33,8 +38,10 @@ func ECDH1PU256KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
 //  - Content Encryption: AES256-GCM
 //  - KDF: One-Step KDF as per https://tools.ietf.org/html/draft-madden-jose-ecdh-1pu-03#section-2.2
 // Keys from this template represent a valid recipient (or sender) public/private key pairs and can be stored in the KMS
+//
+// Deprecated: use NewECDH1PUKeyTemplate, it returns an error instead of panicking.
 func ECDH1PU384KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
-	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P384)
+	return mustCreateKeyTemplate(commonpb.EllipticCurveType_NIST_P384, aead.AES256GCMKeyTemplate())
 }
 
 // ECDH1PU521KWAES256GCMKeyTemplate is a KeyTemplate that generates an ECDH-1PU P-521 key wrapping and AES256-GCM CEK.
@@ -43,8 +50,73 @@ func ECDH1PU384KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
 //  - Content Encryption: AES256-GCM
 //  - KDF: One-Step KDF as per https://tools.ietf.org/html/draft-madden-jose-ecdh-1pu-03#section-2.2
 // Keys from this template represent a valid recipient (or sender) public/private key pairs and can be stored in the KMS
+//
+// Deprecated: use NewECDH1PUKeyTemplate, it returns an error instead of panicking.
 func ECDH1PU521KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
-	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P521)
+	return mustCreateKeyTemplate(commonpb.EllipticCurveType_NIST_P521, aead.AES256GCMKeyTemplate())
+}
+
+// ECDH1PU256KWChaChaKeyTemplate is a KeyTemplate that generates an ECDH-1PU P-256 key wrapping and ChaCha20Poly1305
+// CEK. It is used to represent a recipient key to execute the `CompositeDecrypt` primitive with the following
+// parameters:
+//  - Key Wrapping: ECDH-1PU over A256KW as per https://tools.ietf.org/html/draft-madden-jose-ecdh-1pu-03#section-2
+//  - Content Encryption: ChaCha20Poly1305
+//  - KDF: One-Step KDF as per https://tools.ietf.org/html/draft-madden-jose-ecdh-1pu-03#section-2.2
+// Keys from this template represent a valid recipient (or sender) public/private key pairs and can be stored in the KMS
+//
+// Deprecated: use NewECDH1PUKeyTemplate, it returns an error instead of panicking.
+func ECDH1PU256KWChaChaKeyTemplate() *tinkpb.KeyTemplate {
+	return mustCreateKeyTemplate(commonpb.EllipticCurveType_NIST_P256, aead.ChaCha20Poly1305KeyTemplate())
+}
+
+// ECDH1PU256KWXChaChaKeyTemplate is a KeyTemplate that generates an ECDH-1PU P-256 key wrapping and XChaCha20Poly1305
+// CEK. It is used to represent a recipient key to execute the `CompositeDecrypt` primitive with the following
+// parameters:
+//  - Key Wrapping: ECDH-1PU over A256KW as per https://tools.ietf.org/html/draft-madden-jose-ecdh-1pu-03#section-2
+//  - Content Encryption: XChaCha20Poly1305
+//  - KDF: One-Step KDF as per https://tools.ietf.org/html/draft-madden-jose-ecdh-1pu-03#section-2.2
+// Keys from this template represent a valid recipient (or sender) public/private key pairs and can be stored in the KMS
+//
+// Deprecated: use NewECDH1PUKeyTemplate, it returns an error instead of panicking.
+func ECDH1PU256KWXChaChaKeyTemplate() *tinkpb.KeyTemplate {
+	return mustCreateKeyTemplate(commonpb.EllipticCurveType_NIST_P256, aead.XChaCha20Poly1305KeyTemplate())
+}
+
+// NewECDH1PUKeyTemplate creates a new ECDH-1PU key template with the given key wrapping curve (NIST P-256, P-384 or
+// P-521) and content encryption AEAD key template, ie aead.AES128GCMKeyTemplate() or the AES-CBC-HMAC-SHA2 templates
+// of the tinkcrypto/primitive/aead package. The predefined ECDH1PU*KeyTemplate functions are shortcuts for the curves
+// and content encryptions they are named after. It returns an error if the curve or the content encryption key
+// template is not supported.
+func NewECDH1PUKeyTemplate(c commonpb.EllipticCurveType, encAEAD *tinkpb.KeyTemplate,
+	opts ...KeyTemplateOption) (*tinkpb.KeyTemplate, error) {
+	if encAEAD == nil {
+		return nil, errors.New("NewECDH1PUKeyTemplate: content encryption key template is nil")
+	}
+
+	kt, err := createKeyTemplate(c, encAEAD, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("NewECDH1PUKeyTemplate: %w", err)
+	}
+
+	err = newECDH1PUPrivateKeyManager().ValidateKeyFormat(kt.Value)
+	if err != nil {
+		return nil, fmt.Errorf("NewECDH1PUKeyTemplate: %w", err)
+	}
+
+	return kt, nil
+}
+
+// KeyTemplateOption configures the key wrapping parameters of the keys created from an ECDH-1PU key template.
+type KeyTemplateOption func(kwParams *ecdh1pupb.Ecdh1PuKwParams)
+
+// WithPartyInfo option sets the PartyUInfo (apu) and PartyVInfo (apv) values consumed by the One-Step KDF of the keys
+// created from the template, ie to bind the sender and recipients identities as per DIDComm v2. Senders and recipients
+// must use the same values, default is empty.
+func WithPartyInfo(apu, apv []byte) KeyTemplateOption {
+	return func(kwParams *ecdh1pupb.Ecdh1PuKwParams) {
+		kwParams.Apu = apu
+		kwParams.Apv = apv
+	}
 }
 
 func convertPublicKeyToProto(rRawPublicKey *composite.PublicKey) (*compositepb.ECPublicKey, error) {
@@ -68,19 +140,24 @@ func convertPublicKeyToProto(rRawPublicKey *composite.PublicKey) (*compositepb.E
 	}, nil
 }
 
-// TODO add chacha key templates as well https://github.com/hyperledger/aries-framework-go/issues/1637
+// createKeyTemplate creates a new ECDH1PU-AEAD key template with the given key wrapping curve and content encryption
+// AEAD key template.
+func createKeyTemplate(c commonpb.EllipticCurveType, encAEAD *tinkpb.KeyTemplate,
+	opts ...KeyTemplateOption) (*tinkpb.KeyTemplate, error) {
+	kwParams := &ecdh1pupb.Ecdh1PuKwParams{
+		CurveType: c,
+		KeyType:   compositepb.KeyType_EC,
+	}
+
+	for _, opt := range opts {
+		opt(kwParams)
+	}
 
-// createKeyTemplate creates a new ECDH1PU-AEAD key template with the given key
-// size in bytes.
-func createKeyTemplate(c commonpb.EllipticCurveType) *tinkpb.KeyTemplate {
 	format := &ecdh1pupb.Ecdh1PuAeadKeyFormat{
 		Params: &ecdh1pupb.Ecdh1PuAeadParams{
-			KwParams: &ecdh1pupb.Ecdh1PuKwParams{
-				CurveType: c,
-				KeyType:   compositepb.KeyType_EC,
-			},
+			KwParams: kwParams,
 			EncParams: &ecdh1pupb.Ecdh1PuAeadEncParams{
-				AeadEnc: aead.AES256GCMKeyTemplate(),
+				AeadEnc: encAEAD,
 			},
 			EcPointFormat: commonpb.EcPointFormat_UNCOMPRESSED,
 		},
@@ -88,12 +165,23 @@ func createKeyTemplate(c commonpb.EllipticCurveType) *tinkpb.KeyTemplate {
 
 	serializedFormat, err := proto.Marshal(format)
 	if err != nil {
-		panic("failed to marshal Ecdh1PuAeadKeyFormat proto")
+		return nil, fmt.Errorf("failed to marshal Ecdh1PuAeadKeyFormat proto: %w", err)
 	}
 
 	return &tinkpb.KeyTemplate{
 		TypeUrl:          ecdh1puAESPrivateKeyTypeURL,
 		Value:            serializedFormat,
 		OutputPrefixType: tinkpb.OutputPrefixType_RAW,
+	}, nil
+}
+
+// mustCreateKeyTemplate is similar to NewECDH1PUKeyTemplate without options. It is used by the predefined key
+// templates whose curve and content encryption are constants, it panics if the key template can't be created.
+func mustCreateKeyTemplate(c commonpb.EllipticCurveType, encAEAD *tinkpb.KeyTemplate) *tinkpb.KeyTemplate {
+	kt, err := NewECDH1PUKeyTemplate(c, encAEAD)
+	if err != nil {
+		panic(err)
 	}
+
+	return kt
 }
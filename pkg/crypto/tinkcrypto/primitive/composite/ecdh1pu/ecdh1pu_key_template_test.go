@@ -9,7 +9,9 @@ package ecdh1pu
 import (
 	"testing"
 
+	"github.com/google/tink/go/aead"
 	"github.com/google/tink/go/keyset"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 	"github.com/stretchr/testify/require"
 
@@ -19,31 +21,35 @@ import (
 
 func TestECDH1PUKeyTemplateSuccess(t *testing.T) {
 	var flagTests = []struct {
-		tcName    string
-		curveType string
-		tmplFunc  func() *tinkpb.KeyTemplate
+		tcName   string
+		tmplFunc func() *tinkpb.KeyTemplate
 	}{
 		{
-			tcName:    "create ECDH1PU 256 key templates test",
-			curveType: "P-256",
-			tmplFunc:  ECDH1PU256KWAES256GCMKeyTemplate,
+			tcName:   "create ECDH1PU 256 key templates test",
+			tmplFunc: ECDH1PU256KWAES256GCMKeyTemplate,
 		},
 		{
-			tcName:    "create ECDH1PU 384 key templates test",
-			curveType: "P-384",
-			tmplFunc:  ECDH1PU384KWAES256GCMKeyTemplate,
+			tcName:   "create ECDH1PU 384 key templates test",
+			tmplFunc: ECDH1PU384KWAES256GCMKeyTemplate,
 		},
 		{
-			tcName:    "create ECDH1PU 521 key templates test",
-			curveType: "P-521",
-			tmplFunc:  ECDH1PU521KWAES256GCMKeyTemplate,
+			tcName:   "create ECDH1PU 521 key templates test",
+			tmplFunc: ECDH1PU521KWAES256GCMKeyTemplate,
+		},
+		{
+			tcName:   "create ECDH1PU 256 ChaCha20Poly1305 key templates test",
+			tmplFunc: ECDH1PU256KWChaChaKeyTemplate,
+		},
+		{
+			tcName:   "create ECDH1PU 256 XChaCha20Poly1305 key templates test",
+			tmplFunc: ECDH1PU256KWXChaChaKeyTemplate,
 		},
 	}
 
 	for _, tt := range flagTests {
 		tc := tt
 		t.Run("Test "+tc.tcName, func(t *testing.T) {
-			recPubKeys, recKHs := createRecipients(t, tc.curveType, 10)
+			recPubKeys, recKHs := createRecipients(t, tc.tmplFunc(), 10)
 
 			kt := tc.tmplFunc()
 
@@ -90,7 +96,8 @@ func TestECDH1PUKeyTemplateSuccess(t *testing.T) {
 }
 
 // createRecipients and return their public key and keyset.Handle
-func createRecipients(t *testing.T, curveType string, nbOfRecipients int) ([]*composite.PublicKey, []*keyset.Handle) {
+func createRecipients(t *testing.T, tmpl *tinkpb.KeyTemplate,
+	nbOfRecipients int) ([]*composite.PublicKey, []*keyset.Handle) {
 	t.Helper()
 
 	var (
@@ -99,7 +106,7 @@ func createRecipients(t *testing.T, curveType string, nbOfRecipients int) ([]*co
 	)
 
 	for i := 0; i < nbOfRecipients; i++ {
-		ecPubKey, kh := createRecipient(t, curveType)
+		ecPubKey, kh := createRecipient(t, tmpl)
 
 		r = append(r, ecPubKey)
 		rKH = append(rKH, kh)
@@ -110,20 +117,9 @@ func createRecipients(t *testing.T, curveType string, nbOfRecipients int) ([]*co
 
 // createRecipient creates a new recipient keyset.Handle, extracts public key, marshals it and returns
 // both marshalled public key and original recipient keyset.Handle
-func createRecipient(t *testing.T, curveType string) (*composite.PublicKey, *keyset.Handle) {
+func createRecipient(t *testing.T, tmpl *tinkpb.KeyTemplate) (*composite.PublicKey, *keyset.Handle) {
 	t.Helper()
 
-	var tmpl *tinkpb.KeyTemplate
-
-	switch curveType {
-	case "P-256":
-		tmpl = ECDH1PU256KWAES256GCMKeyTemplate()
-	case "P-384":
-		tmpl = ECDH1PU384KWAES256GCMKeyTemplate()
-	case "P-521":
-		tmpl = ECDH1PU521KWAES256GCMKeyTemplate()
-	}
-
 	kh, err := keyset.NewHandle(tmpl)
 	require.NoError(t, err)
 
@@ -132,3 +128,154 @@ func createRecipient(t *testing.T, curveType string) (*composite.PublicKey, *key
 
 	return ecPubKey, kh
 }
+
+func TestECDH1PUKeyTemplateWithPartyInfo(t *testing.T) {
+	apu := []byte("Alice")
+	apv := []byte("Bob")
+
+	kt, err := NewECDH1PUKeyTemplate(commonpb.EllipticCurveType_NIST_P256, aead.AES256GCMKeyTemplate(),
+		WithPartyInfo(apu, apv))
+	require.NoError(t, err)
+
+	senderKH, err := keyset.NewHandle(kt)
+	require.NoError(t, err)
+
+	// a recipient created from the same template and a recipient configured after its key was created
+	recPubKey, recKH := createRecipient(t, kt)
+	otherRecPubKey, otherRecKH := createRecipient(t, ECDH1PU256KWAES256GCMKeyTemplate())
+
+	otherRecKH, err = SetPartyInfo(otherRecKH, apu, apv)
+	require.NoError(t, err)
+
+	senderKH, err = AddRecipientsKeys(senderKH, []*composite.PublicKey{recPubKey, otherRecPubKey})
+	require.NoError(t, err)
+
+	senderKey, err := keyio.ExtractPrimaryPublicKey(senderKH)
+	require.NoError(t, err)
+
+	pubKH, err := senderKH.Public()
+	require.NoError(t, err)
+
+	e, err := NewECDH1PUEncrypt(pubKH)
+	require.NoError(t, err)
+
+	pt := []byte("secret message")
+	aad := []byte("aad message")
+
+	ct, err := e.Encrypt(pt, aad)
+	require.NoError(t, err)
+
+	for _, kh := range []*keyset.Handle{recKH, otherRecKH} {
+		kh, err = AddSenderKey(kh, senderKey)
+		require.NoError(t, err)
+
+		d, err := NewECDH1PUDecrypt(kh)
+		require.NoError(t, err)
+
+		dpt, err := d.Decrypt(ct, aad)
+		require.NoError(t, err)
+		require.Equal(t, pt, dpt)
+	}
+
+	t.Run("test decrypt fails with different party info", func(t *testing.T) {
+		kh, err := SetPartyInfo(recKH, apu, []byte("Charlie"))
+		require.NoError(t, err)
+
+		kh, err = AddSenderKey(kh, senderKey)
+		require.NoError(t, err)
+
+		d, err := NewECDH1PUDecrypt(kh)
+		require.NoError(t, err)
+
+		_, err = d.Decrypt(ct, aad)
+		require.EqualError(t, err, "ecdh1pu_factory: decryption failed")
+	})
+
+	t.Run("test set party info on public key handle fails", func(t *testing.T) {
+		_, err := SetPartyInfo(pubKH, apu, apv)
+		require.EqualError(t, err, "SetPartyInfo: keyset.Handle points to a public key. It must point to a priviate key")
+	})
+}
+
+func TestValidateKeyTemplate(t *testing.T) {
+	ktWithPartyInfo, err := NewECDH1PUKeyTemplate(commonpb.EllipticCurveType_NIST_P256,
+		aead.XChaCha20Poly1305KeyTemplate(), WithPartyInfo([]byte("Alice"), []byte("Bob")))
+	require.NoError(t, err)
+
+	for _, kt := range []*tinkpb.KeyTemplate{
+		ECDH1PU256KWAES256GCMKeyTemplate(),
+		ECDH1PU384KWAES256GCMKeyTemplate(),
+		ECDH1PU521KWAES256GCMKeyTemplate(),
+		ECDH1PU256KWChaChaKeyTemplate(),
+		ECDH1PU256KWXChaChaKeyTemplate(),
+		ktWithPartyInfo,
+	} {
+		require.NoError(t, composite.ValidateKeyTemplate(kt))
+	}
+
+	kt := ECDH1PU256KWAES256GCMKeyTemplate()
+	kt.Value = []byte("bad key format")
+
+	err = composite.ValidateKeyTemplate(kt)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "validateKeyTemplate: invalid key format: ecdh1pu_aes_private_key_manager: "+
+		"failed to unmarshal key format")
+}
+
+func TestNewECDH1PUKeyTemplate(t *testing.T) {
+	t.Run("test content encryption AES128-GCM", func(t *testing.T) {
+		kt, err := NewECDH1PUKeyTemplate(commonpb.EllipticCurveType_NIST_P384, aead.AES128GCMKeyTemplate())
+		require.NoError(t, err)
+
+		recPubKeys, recKHs := createRecipients(t, kt, 2)
+
+		senderKH, err := keyset.NewHandle(kt)
+		require.NoError(t, err)
+
+		senderKH, err = AddRecipientsKeys(senderKH, recPubKeys)
+		require.NoError(t, err)
+
+		senderKey, err := keyio.ExtractPrimaryPublicKey(senderKH)
+		require.NoError(t, err)
+
+		pubKH, err := senderKH.Public()
+		require.NoError(t, err)
+
+		e, err := NewECDH1PUEncrypt(pubKH)
+		require.NoError(t, err)
+
+		pt := []byte("secret message")
+
+		ct, err := e.Encrypt(pt, nil)
+		require.NoError(t, err)
+
+		for _, recKH := range recKHs {
+			recKH, err = AddSenderKey(recKH, senderKey)
+			require.NoError(t, err)
+
+			d, err := NewECDH1PUDecrypt(recKH)
+			require.NoError(t, err)
+
+			dpt, err := d.Decrypt(ct, nil)
+			require.NoError(t, err)
+			require.Equal(t, pt, dpt)
+		}
+	})
+
+	t.Run("test predefined key templates", func(t *testing.T) {
+		kt, err := NewECDH1PUKeyTemplate(commonpb.EllipticCurveType_NIST_P256, aead.AES256GCMKeyTemplate())
+		require.NoError(t, err)
+		require.Equal(t, ECDH1PU256KWAES256GCMKeyTemplate(), kt)
+	})
+
+	t.Run("test invalid key templates", func(t *testing.T) {
+		_, err := NewECDH1PUKeyTemplate(commonpb.EllipticCurveType_NIST_P256, nil)
+		require.EqualError(t, err, "NewECDH1PUKeyTemplate: content encryption key template is nil")
+
+		_, err = NewECDH1PUKeyTemplate(commonpb.EllipticCurveType_UNKNOWN_CURVE, aead.AES256GCMKeyTemplate())
+		require.Error(t, err)
+
+		_, err = NewECDH1PUKeyTemplate(commonpb.EllipticCurveType_NIST_P256, aead.AES128CTRHMACSHA256KeyTemplate())
+		require.Error(t, err)
+	})
+}
@@ -8,12 +8,14 @@ package subtle
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	hybrid "github.com/google/tink/go/hybrid/subtle"
 
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
 	commonpb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 )
 
 // package subtle provides the core crypto primitives to be used by ECDH-1PU composite primitives. It is intended for
@@ -27,21 +29,35 @@ type ECDH1PUAEADCompositeDecrypt struct {
 	pointFormat  string
 	encHelper    composite.EncrypterHelper
 	keyType      commonpb.KeyType
+	apu          []byte
+	apv          []byte
+	// kid identifies the recipient key, recipients with the same KID are unwrapped first
+	kid string
 }
 
 // NewECDH1PUAEADCompositeDecrypt returns ECDH-ES composite decryption construct with Concat KDF/ECDH-1PU key unwrapping
-// and AEAD payload decryption.
+// and AEAD payload decryption. apu and apv are the optional PartyUInfo and PartyVInfo consumed by the KDF, they must
+// match the values used for encryption. kid is the optional key ID of the recipient key.
 func NewECDH1PUAEADCompositeDecrypt(senderPub *hybrid.ECPublicKey, recPvt *hybrid.ECPrivateKey, ptFormat string,
-	encHelper composite.EncrypterHelper, keyType commonpb.KeyType) *ECDH1PUAEADCompositeDecrypt {
+	encHelper composite.EncrypterHelper, keyType commonpb.KeyType, apu, apv []byte,
+	kid string) *ECDH1PUAEADCompositeDecrypt {
 	return &ECDH1PUAEADCompositeDecrypt{
 		senderPubKey: senderPub,
 		recPrivKey:   recPvt,
 		pointFormat:  ptFormat,
 		encHelper:    encHelper,
 		keyType:      keyType,
+		apu:          apu,
+		apv:          apv,
+		kid:          kid,
 	}
 }
 
+// KID returns the key ID of the recipient key, it is empty if the key has no KID.
+func (d *ECDH1PUAEADCompositeDecrypt) KID() string {
+	return d.kid
+}
+
 // Decrypt using composite ECDH-ES with a Concat KDF key unwrap and AEAD content decryption
 func (d *ECDH1PUAEADCompositeDecrypt) Decrypt(ciphertext, aad []byte) ([]byte, error) {
 	if d.recPrivKey == nil {
@@ -59,33 +75,46 @@ func (d *ECDH1PUAEADCompositeDecrypt) Decrypt(ciphertext, aad []byte) ([]byte, e
 		return nil, err
 	}
 
-	// TODO: add support for Chacha content encryption https://github.com/hyperledger/aries-framework-go/issues/1684
 	switch d.keyType {
 	case commonpb.KeyType_EC:
-		if encData.EncAlg != A256GCM {
+		if encData.EncAlg != d.encHelper.GetEncAlg() {
 			return nil, fmt.Errorf("invalid content encryption algorihm '%s' for Decrypt()", encData.EncAlg)
 		}
 	default:
 		return nil, fmt.Errorf("invalid key type '%s' for Decrypt()", d.keyType)
 	}
 
-	for _, rec := range encData.Recipients {
+	var (
+		unwrappedRec *composite.RecipientWrappedKey
+		recErrs      []*composite.RecipientError
+	)
+
+	for _, rec := range composite.SortRecipientsByKID(encData.Recipients, d.kid) {
 		recipientKW := &ECDH1PUConcatKDFRecipientKW{
 			senderPubKey:        d.senderPubKey,
 			recipientPrivateKey: d.recPrivKey,
+			apu:                 d.apu,
+			apv:                 d.apv,
 		}
 
 		// TODO: add support for 25519 key unwrapping https://github.com/hyperledger/aries-framework-go/issues/1637
 		cek, err = recipientKW.unwrapKey(rec, keySize)
 		if err == nil {
+			unwrappedRec = rec
+
 			break
 		}
+
+		recErrs = append(recErrs, composite.NewRecipientError(rec, d.kid, d.recPrivKey.PublicKey.Curve, err))
 	}
 
 	if cek == nil {
-		return nil, fmt.Errorf("ecdh-1pu decrypt: cek unwrap failed for all recipients keys")
+		return nil, composite.NewKeyDecryptError(d.kid, recErrs,
+			errors.New("ecdh-1pu decrypt: cek unwrap failed for all recipients keys"))
 	}
 
+	defer cryptoutil.Zeroize(cek)
+
 	aead, err := d.encHelper.GetAEAD(cek)
 	if err != nil {
 		return nil, err
@@ -93,5 +122,16 @@ func (d *ECDH1PUAEADCompositeDecrypt) Decrypt(ciphertext, aad []byte) ([]byte, e
 
 	finalCT := d.encHelper.BuildDecData(encData)
 
-	return aead.Decrypt(finalCT, aad)
+	pt, err := aead.Decrypt(finalCT, aad)
+	if err != nil {
+		recErrs = append(recErrs, &composite.RecipientError{
+			KID:    unwrappedRec.KID,
+			Reason: composite.RecipientTagInvalid,
+			Err:    err,
+		})
+
+		return nil, composite.NewKeyDecryptError(d.kid, recErrs, err)
+	}
+
+	return pt, nil
 }
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package subtle
 
 import (
+	"encoding/json"
 	"fmt"
 
 	hybrid "github.com/google/tink/go/hybrid/subtle"
@@ -15,6 +16,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/api"
 	commonpb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 )
 
 // A256GCM is the default content encryption algorithm value as per
@@ -29,20 +31,29 @@ type ECDH1PUAEADCompositeEncrypt struct {
 	pointFormat   string
 	encHelper     composite.EncrypterHelper
 	keyType       commonpb.KeyType
+	apu           []byte
+	apv           []byte
+	// senderKID identifies the sender key in the skid header of the messages
+	senderKID string
 }
 
 var _ api.CompositeEncrypt = (*ECDH1PUAEADCompositeEncrypt)(nil)
 
 // NewECDH1PUAEADCompositeEncrypt returns ECDH-ES encryption construct with Concat KDF key wrapping
-// and AEAD content encryption
+// and AEAD content encryption. apu and apv are the optional PartyUInfo and PartyVInfo consumed by the KDF. senderKID is
+// the optional key ID of the sender key, set as the SKID of the messages.
 func NewECDH1PUAEADCompositeEncrypt(recipientsKeys []*composite.PublicKey, senderPrivKey *hybrid.ECPrivateKey,
-	ptFormat string, encHelper composite.EncrypterHelper, keyType commonpb.KeyType) *ECDH1PUAEADCompositeEncrypt {
+	ptFormat string, encHelper composite.EncrypterHelper, keyType commonpb.KeyType,
+	apu, apv []byte, senderKID string) *ECDH1PUAEADCompositeEncrypt {
 	return &ECDH1PUAEADCompositeEncrypt{
 		senderPrivKey: senderPrivKey,
 		recPublicKeys: recipientsKeys,
 		pointFormat:   ptFormat,
 		encHelper:     encHelper,
 		keyType:       keyType,
+		apu:           apu,
+		apv:           apv,
+		senderKID:     senderKID,
 	}
 }
 
@@ -54,10 +65,9 @@ func (e *ECDH1PUAEADCompositeEncrypt) Encrypt(plaintext, aad []byte) ([]byte, er
 
 	var eAlg, kwAlg string
 
-	// TODO add chacha alg support too, https://github.com/hyperledger/aries-framework-go/issues/1684
 	switch e.keyType {
 	case commonpb.KeyType_EC:
-		eAlg = A256GCM
+		eAlg = e.encHelper.GetEncAlg()
 		kwAlg = A256KWAlg
 	default:
 		return nil, fmt.Errorf("ECDH1PUAEADCompositeEncrypt: bad key type: '%s'", e.keyType)
@@ -65,6 +75,7 @@ func (e *ECDH1PUAEADCompositeEncrypt) Encrypt(plaintext, aad []byte) ([]byte, er
 
 	keySize := e.encHelper.GetSymmetricKeySize()
 	cek := random.GetRandomBytes(uint32(keySize))
+	defer cryptoutil.Zeroize(cek)
 
 	var recipientsWK []*composite.RecipientWrappedKey
 
@@ -75,6 +86,9 @@ func (e *ECDH1PUAEADCompositeEncrypt) Encrypt(plaintext, aad []byte) ([]byte, er
 			senderPrivateKey:   e.senderPrivKey,
 			recipientPublicKey: rec,
 			cek:                cek,
+			pointFormat:        e.pointFormat,
+			apu:                e.apu,
+			apv:                e.apv,
 		}
 
 		// TODO: add support for 25519 key wrapping https://github.com/hyperledger/aries-framework-go/issues/1637
@@ -105,5 +119,24 @@ func (e *ECDH1PUAEADCompositeEncrypt) Encrypt(plaintext, aad []byte) ([]byte, er
 		return nil, err
 	}
 
-	return e.encHelper.BuildEncData(eAlg, recipientsWK, ct, singleRecipientAAD)
+	encData, err := e.encHelper.BuildEncData(eAlg, recipientsWK, ct, singleRecipientAAD)
+	if err != nil || e.senderKID == "" {
+		return encData, err
+	}
+
+	return withSenderKID(encData, e.senderKID)
+}
+
+// withSenderKID sets the SKID of the serialized composite.EncryptedData encData to senderKID.
+func withSenderKID(encData []byte, senderKID string) ([]byte, error) {
+	data := new(composite.EncryptedData)
+
+	err := json.Unmarshal(encData, data)
+	if err != nil {
+		return nil, fmt.Errorf("ECDH1PUAEADCompositeEncrypt: failed to set sender KID: %w", err)
+	}
+
+	data.SKID = senderKID
+
+	return json.Marshal(data)
 }
@@ -34,12 +34,13 @@ func TestEncryptDecrypt(t *testing.T) {
 		AEADValue:    aeadPrimitive,
 		TagSizeValue: subtleaead.AESGCMTagSize,
 		IVSizeValue:  subtleaead.AESGCMIVSize,
+		EncAlgValue:  A256GCM,
 	}
 
 	senderKey := recipientsPrivKeys[0]
 
 	cEnc := NewECDH1PUAEADCompositeEncrypt(recipientsPubKeys, senderKey, commonpb.EcPointFormat_UNCOMPRESSED.String(),
-		mEncHelper, compositepb.KeyType_EC)
+		mEncHelper, compositepb.KeyType_EC, nil, nil, "")
 
 	pt := []byte("secret message")
 	aad := []byte("aad message")
@@ -49,7 +50,116 @@ func TestEncryptDecrypt(t *testing.T) {
 
 	for _, privKey := range recipientsPrivKeys {
 		dEnc := NewECDH1PUAEADCompositeDecrypt(&senderKey.PublicKey, privKey,
-			commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper, compositepb.KeyType_EC)
+			commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper, compositepb.KeyType_EC, nil, nil, "")
+
+		dpt, err := dEnc.Decrypt(ct, aad)
+		require.NoError(t, err)
+		require.EqualValues(t, pt, dpt)
+	}
+}
+
+func TestEncryptDecryptWithSenderKID(t *testing.T) {
+	recipientsPrivKeys, recipientsPubKeys := buildRecipientsKeys(t, 2)
+
+	mEncHelper := &MockEncHelper{
+		KeySizeValue: 32,
+		AEADValue:    getAEADPrimitive(t, aead.AES256GCMKeyTemplate()),
+		TagSizeValue: subtleaead.AESGCMTagSize,
+		IVSizeValue:  subtleaead.AESGCMIVSize,
+		EncAlgValue:  A256GCM,
+	}
+
+	senderKey := recipientsPrivKeys[0]
+
+	cEnc := NewECDH1PUAEADCompositeEncrypt(recipientsPubKeys, senderKey, commonpb.EcPointFormat_UNCOMPRESSED.String(),
+		mEncHelper, compositepb.KeyType_EC, nil, nil, "sender-kid")
+
+	pt := []byte("secret message")
+	aad := []byte("aad message")
+
+	ct, err := cEnc.Encrypt(pt, aad)
+	require.NoError(t, err)
+
+	encData := &composite.EncryptedData{}
+	require.NoError(t, json.Unmarshal(ct, encData))
+	require.Equal(t, "sender-kid", encData.SKID)
+
+	dEnc := NewECDH1PUAEADCompositeDecrypt(&senderKey.PublicKey, recipientsPrivKeys[1],
+		commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper, compositepb.KeyType_EC, nil, nil, "")
+
+	dpt, err := dEnc.Decrypt(ct, aad)
+	require.NoError(t, err)
+	require.EqualValues(t, pt, dpt)
+}
+
+func TestEncryptDecryptZeroizesCEK(t *testing.T) {
+	recipientsPrivKeys, recipientsPubKeys := buildRecipientsKeys(t, 2)
+
+	mEncHelper := &MockEncHelper{
+		KeySizeValue: 32,
+		AEADValue:    getAEADPrimitive(t, aead.AES256GCMKeyTemplate()),
+		TagSizeValue: subtleaead.AESGCMTagSize,
+		IVSizeValue:  subtleaead.AESGCMIVSize,
+		EncAlgValue:  A256GCM,
+	}
+
+	senderKey := recipientsPrivKeys[0]
+
+	cEnc := NewECDH1PUAEADCompositeEncrypt(recipientsPubKeys, senderKey, commonpb.EcPointFormat_UNCOMPRESSED.String(),
+		mEncHelper, compositepb.KeyType_EC, nil, nil, "")
+
+	ct, err := cEnc.Encrypt([]byte("secret message"), []byte("aad message"))
+	require.NoError(t, err)
+
+	dEnc := NewECDH1PUAEADCompositeDecrypt(&senderKey.PublicKey, recipientsPrivKeys[1],
+		commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper, compositepb.KeyType_EC, nil, nil, "")
+
+	_, err = dEnc.Decrypt(ct, []byte("aad message"))
+	require.NoError(t, err)
+
+	// the CEKs generated by Encrypt and unwrapped by Decrypt are wiped once they return
+	require.Len(t, mEncHelper.CEKs, 2)
+
+	for _, cek := range mEncHelper.CEKs {
+		require.Equal(t, make([]byte, 32), cek)
+	}
+}
+
+func TestEncryptDecryptWithCompressedPoints(t *testing.T) {
+	recipientsPrivKeys, recipientsPubKeys := buildRecipientsKeys(t, 3)
+	aeadPrimitive := getAEADPrimitive(t, aead.AES256GCMKeyTemplate())
+
+	mEncHelper := &MockEncHelper{
+		KeySizeValue: 32,
+		AEADValue:    aeadPrimitive,
+		TagSizeValue: subtleaead.AESGCMTagSize,
+		IVSizeValue:  subtleaead.AESGCMIVSize,
+		EncAlgValue:  A256GCM,
+	}
+
+	senderKey := recipientsPrivKeys[0]
+
+	cEnc := NewECDH1PUAEADCompositeEncrypt(recipientsPubKeys, senderKey, commonpb.EcPointFormat_COMPRESSED.String(),
+		mEncHelper, compositepb.KeyType_EC, nil, nil, "")
+
+	pt := []byte("secret message")
+	aad := []byte("aad message")
+
+	ct, err := cEnc.Encrypt(pt, aad)
+	require.NoError(t, err)
+
+	encData := &composite.EncryptedData{}
+	err = json.Unmarshal(ct, encData)
+	require.NoError(t, err)
+
+	for _, rec := range encData.Recipients {
+		require.Len(t, rec.EPK.X, 33)
+		require.Empty(t, rec.EPK.Y)
+	}
+
+	for _, privKey := range recipientsPrivKeys {
+		dEnc := NewECDH1PUAEADCompositeDecrypt(&senderKey.PublicKey, privKey,
+			commonpb.EcPointFormat_COMPRESSED.String(), mEncHelper, compositepb.KeyType_EC, nil, nil, "")
 
 		dpt, err := dEnc.Decrypt(ct, aad)
 		require.NoError(t, err)
@@ -66,6 +176,7 @@ func TestEncryptDecryptNegativeTCs(t *testing.T) {
 		AEADValue:    aeadPrimitive,
 		TagSizeValue: subtleaead.AESGCMTagSize,
 		IVSizeValue:  subtleaead.AESGCMIVSize,
+		EncAlgValue:  A256GCM,
 	}
 
 	pt := []byte("secret message")
@@ -75,7 +186,7 @@ func TestEncryptDecryptNegativeTCs(t *testing.T) {
 
 	// test with empty recipients public keys
 	cEnc := NewECDH1PUAEADCompositeEncrypt(nil, senderKey, commonpb.EcPointFormat_UNCOMPRESSED.String(),
-		mEncHelper, compositepb.KeyType_EC)
+		mEncHelper, compositepb.KeyType_EC, nil, nil, "")
 
 	// Encrypt should fail with empty recipients public keys
 	_, err := cEnc.Encrypt(pt, aad)
@@ -85,7 +196,7 @@ func TestEncryptDecryptNegativeTCs(t *testing.T) {
 	mEncHelper.KeySizeValue = 100
 
 	cEnc = NewECDH1PUAEADCompositeEncrypt(recipientsPubKeys, senderKey, commonpb.EcPointFormat_UNCOMPRESSED.String(),
-		mEncHelper, compositepb.KeyType_EC)
+		mEncHelper, compositepb.KeyType_EC, nil, nil, "")
 
 	// Encrypt should fail with large AEAD key size value
 	_, err = cEnc.Encrypt(pt, aad)
@@ -106,7 +217,7 @@ func TestEncryptDecryptNegativeTCs(t *testing.T) {
 	mEncHelper.AEADErrValue = fmt.Errorf("error from GetAEAD")
 
 	cEnc = NewECDH1PUAEADCompositeEncrypt(recipientsPubKeys, senderKey, commonpb.EcPointFormat_UNCOMPRESSED.String(),
-		mEncHelper, compositepb.KeyType_EC)
+		mEncHelper, compositepb.KeyType_EC, nil, nil, "")
 
 	// Encrypt should fail with large AEAD key size value
 	_, err = cEnc.Encrypt(pt, aad)
@@ -116,7 +227,7 @@ func TestEncryptDecryptNegativeTCs(t *testing.T) {
 
 	// create a valid ciphertext to test Decrypt for all recipients
 	cEnc = NewECDH1PUAEADCompositeEncrypt(recipientsPubKeys, senderKey, commonpb.EcPointFormat_UNCOMPRESSED.String(),
-		mEncHelper, compositepb.KeyType_EC)
+		mEncHelper, compositepb.KeyType_EC, nil, nil, "")
 
 	// test with empty plaintext
 	ct, err := cEnc.Encrypt([]byte{}, aad)
@@ -137,7 +248,7 @@ func TestEncryptDecryptNegativeTCs(t *testing.T) {
 	for _, privKey := range recipientsPrivKeys {
 		// test with nil recipient private key
 		dEnc := NewECDH1PUAEADCompositeDecrypt(nil, nil, commonpb.EcPointFormat_UNCOMPRESSED.String(),
-			mEncHelper, compositepb.KeyType_EC)
+			mEncHelper, compositepb.KeyType_EC, nil, nil, "")
 
 		_, err = dEnc.Decrypt(ct, aad)
 		require.EqualError(t, err, "ECDH1PUAEADCompositeDecrypt: missing recipient private key for key"+
@@ -146,7 +257,7 @@ func TestEncryptDecryptNegativeTCs(t *testing.T) {
 		// test with large key size
 		mEncHelper.KeySizeValue = 100
 		dEnc = NewECDH1PUAEADCompositeDecrypt(&senderKey.PublicKey, privKey,
-			commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper, compositepb.KeyType_EC)
+			commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper, compositepb.KeyType_EC, nil, nil, "")
 
 		_, err = dEnc.Decrypt(ct, aad)
 		require.EqualError(t, err, "ecdh-1pu decrypt: cek unwrap failed for all recipients keys")
@@ -157,7 +268,7 @@ func TestEncryptDecryptNegativeTCs(t *testing.T) {
 		mEncHelper.AEADErrValue = fmt.Errorf("error from GetAEAD")
 
 		dEnc = NewECDH1PUAEADCompositeDecrypt(&senderKey.PublicKey, privKey,
-			commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper, compositepb.KeyType_EC)
+			commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper, compositepb.KeyType_EC, nil, nil, "")
 
 		_, err = dEnc.Decrypt(ct, aad)
 		require.EqualError(t, err, "error from GetAEAD")
@@ -166,7 +277,7 @@ func TestEncryptDecryptNegativeTCs(t *testing.T) {
 
 		// create a valid Decrypt message and test against ct
 		dEnc = NewECDH1PUAEADCompositeDecrypt(&senderKey.PublicKey, privKey,
-			commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper, compositepb.KeyType_EC)
+			commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper, compositepb.KeyType_EC, nil, nil, "")
 
 		// try decrypting empty ct
 		_, err = dEnc.Decrypt([]byte{}, aad)
@@ -201,6 +312,7 @@ func TestEncryptDecryptWithSingleRecipient(t *testing.T) {
 		AEADValue:    aeadPrimitive,
 		TagSizeValue: subtleaead.AESGCMTagSize,
 		IVSizeValue:  subtleaead.AESGCMIVSize,
+		EncAlgValue:  A256GCM,
 	}
 
 	pt := []byte("secret message")
@@ -210,7 +322,7 @@ func TestEncryptDecryptWithSingleRecipient(t *testing.T) {
 
 	// test with single recipient public key
 	cEnc := NewECDH1PUAEADCompositeEncrypt(recipientsPubKeys, senderKey, commonpb.EcPointFormat_UNCOMPRESSED.String(),
-		mEncHelper, compositepb.KeyType_EC)
+		mEncHelper, compositepb.KeyType_EC, nil, nil, "")
 
 	errMsg := "error merge recipient headers"
 	mEncHelper.MergeRecErr = fmt.Errorf(errMsg)
@@ -233,7 +345,7 @@ func TestEncryptDecryptWithSingleRecipient(t *testing.T) {
 
 	for _, privKey := range recipientsPrivKeys {
 		dEnc := NewECDH1PUAEADCompositeDecrypt(&senderKey.PublicKey, privKey,
-			commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper, compositepb.KeyType_EC)
+			commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper, compositepb.KeyType_EC, nil, nil, "")
 
 		dpt, err := dEnc.Decrypt(ct, encData.SingleRecipientAAD)
 		require.NoError(t, err)
@@ -293,8 +405,11 @@ type MockEncHelper struct {
 	AEADErrValue  error
 	TagSizeValue  int
 	IVSizeValue   int
+	EncAlgValue   string
 	MergeRecValue []byte
 	MergeRecErr   error
+	// CEKs records the symmetric keys of the AEAD primitives
+	CEKs [][]byte
 }
 
 // GetSymmetricKeySize gives the size of the Encryption key (CEK) in bytes
@@ -304,6 +419,8 @@ func (m *MockEncHelper) GetSymmetricKeySize() int {
 
 // GetAEAD returns the newly created AEAD primitive used for the content Encryption
 func (m *MockEncHelper) GetAEAD(symmetricKeyValue []byte) (tink.AEAD, error) {
+	m.CEKs = append(m.CEKs, symmetricKeyValue)
+
 	return m.AEADValue, m.AEADErrValue
 }
 
@@ -317,6 +434,11 @@ func (m *MockEncHelper) GetIVSize() int {
 	return m.IVSizeValue
 }
 
+// GetEncAlg provides the content encryption algorithm value set in the encrypted data
+func (m *MockEncHelper) GetEncAlg() string {
+	return m.EncAlgValue
+}
+
 // BuildEncData will build the []byte representing the ciphertext sent to the end user of the Composite primitive
 func (m *MockEncHelper) BuildEncData(eAlg string, recipientsWK []*composite.RecipientWrappedKey, ct,
 	singleRecipientAAD []byte) ([]byte, error) {
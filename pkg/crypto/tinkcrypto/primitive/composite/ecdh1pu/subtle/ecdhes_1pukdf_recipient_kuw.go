@@ -10,12 +10,12 @@ import (
 	"crypto/aes"
 	"crypto/ecdsa"
 	"fmt"
-	"math/big"
 
 	hybrid "github.com/google/tink/go/hybrid/subtle"
 	josecipher "github.com/square/go-jose/v3/cipher"
 
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 )
 
 // ECDH1PUConcatKDFRecipientKW represents concat KDF based ECDH-1PU (One-Pass Unified Model) KW (key wrapping)
@@ -23,6 +23,9 @@ import (
 type ECDH1PUConcatKDFRecipientKW struct {
 	senderPubKey        *hybrid.ECPublicKey
 	recipientPrivateKey *hybrid.ECPrivateKey
+	// apu and apv are the PartyUInfo and PartyVInfo consumed by the One-Step KDF, they must match the sender's
+	apu []byte
+	apv []byte
 }
 
 // unwrapKey will do ECDH-1PU key unwrapping
@@ -42,15 +45,20 @@ func (s *ECDH1PUConcatKDFRecipientKW) unwrapKey(recWK *composite.RecipientWrappe
 		D: s.recipientPrivateKey.D,
 	}
 
-	epkCurve, err := hybrid.GetCurve(recWK.EPK.Curve)
+	epkCurve, err := composite.GetCurve(recWK.EPK.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	epkX, epkY, err := composite.ECPoint(epkCurve, &recWK.EPK)
 	if err != nil {
 		return nil, err
 	}
 
 	epkPubKey := &ecdsa.PublicKey{
 		Curve: epkCurve,
-		X:     new(big.Int).SetBytes(recWK.EPK.X),
-		Y:     new(big.Int).SetBytes(recWK.EPK.Y),
+		X:     epkX,
+		Y:     epkY,
 	}
 
 	senderPubKey := &ecdsa.PublicKey{
@@ -59,11 +67,13 @@ func (s *ECDH1PUConcatKDFRecipientKW) unwrapKey(recWK *composite.RecipientWrappe
 		Y:     s.senderPubKey.Point.Y,
 	}
 
-	kek, err := deriveRecipient1Pu(recWK.Alg, epkPubKey, senderPubKey, recPrivKey, keySize)
+	kek, err := deriveRecipient1Pu(recWK.Alg, s.apu, s.apv, epkPubKey, senderPubKey, recPrivKey, keySize)
 	if err != nil {
 		return nil, err
 	}
 
+	defer cryptoutil.Zeroize(kek)
+
 	block, err := aes.NewCipher(kek)
 	if err != nil {
 		return nil, err
@@ -72,11 +82,22 @@ func (s *ECDH1PUConcatKDFRecipientKW) unwrapKey(recWK *composite.RecipientWrappe
 	return josecipher.KeyUnwrap(block, recWK.EncryptedCEK)
 }
 
-func deriveRecipient1Pu(kwAlg string, ephemeralPub, senderPubKey *ecdsa.PublicKey, recPrivKey *ecdsa.PrivateKey,
-	keySize int) ([]byte, error) {
-	// DeriveECDHES checks if keys are on the same curve
-	ze := josecipher.DeriveECDHES(kwAlg, []byte{}, []byte{}, recPrivKey, ephemeralPub, keySize)
-	zs := josecipher.DeriveECDHES(kwAlg, []byte{}, []byte{}, recPrivKey, senderPubKey, keySize)
+func deriveRecipient1Pu(kwAlg string, apu, apv []byte, ephemeralPub, senderPubKey *ecdsa.PublicKey,
+	recPrivKey *ecdsa.PrivateKey, keySize int) ([]byte, error) {
+	// ecdhSharedSecret checks if keys are on the same curve
+	ze, err := ecdhSharedSecret(recPrivKey, ephemeralPub)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cryptoutil.Zeroize(ze)
+
+	zs, err := ecdhSharedSecret(recPrivKey, senderPubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cryptoutil.Zeroize(zs)
 
-	return derive1Pu(kwAlg, ze, zs, keySize)
+	return derive1Pu(kwAlg, apu, apv, ze, zs, keySize)
 }
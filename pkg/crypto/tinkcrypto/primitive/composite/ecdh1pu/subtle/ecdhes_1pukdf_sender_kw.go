@@ -10,9 +10,10 @@ import (
 	"crypto"
 	"crypto/aes"
 	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"encoding/binary"
-	"math/big"
+	"errors"
 
 	hybrid "github.com/google/tink/go/hybrid/subtle"
 	josecipher "github.com/square/go-jose/v3/cipher"
@@ -31,6 +32,11 @@ type ECDH1PUConcatKDFSenderKW struct {
 	senderPrivateKey   *hybrid.ECPrivateKey
 	recipientPublicKey *composite.PublicKey
 	cek                []byte
+	// pointFormat sets the EPK with compressed points when equal to composite.CompressedPointFormat
+	pointFormat string
+	// apu and apv are the PartyUInfo and PartyVInfo consumed by the One-Step KDF, they are optional
+	apu []byte
+	apv []byte
 }
 
 // wrapKey will do ECDH-1PU key wrapping
@@ -38,15 +44,20 @@ func (s *ECDH1PUConcatKDFSenderKW) wrapKey(kwAlg string, keySize int) (*composit
 	// TODO: add support for 25519 key wrapping https://github.com/hyperledger/aries-framework-go/issues/1637
 	keyType := compositepb.KeyType_EC.String()
 
-	c, err := hybrid.GetCurve(s.recipientPublicKey.Curve)
+	c, err := composite.GetCurve(s.recipientPublicKey.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	recX, recY, err := composite.ECPoint(c, s.recipientPublicKey)
 	if err != nil {
 		return nil, err
 	}
 
 	recPubKey := &ecdsa.PublicKey{
 		Curve: c,
-		X:     new(big.Int).SetBytes(s.recipientPublicKey.X),
-		Y:     new(big.Int).SetBytes(s.recipientPublicKey.Y),
+		X:     recX,
+		Y:     recY,
 	}
 
 	ephemeralPriv, err := ecdsa.GenerateKey(recPubKey.Curve, rand.Reader)
@@ -63,11 +74,13 @@ func (s *ECDH1PUConcatKDFSenderKW) wrapKey(kwAlg string, keySize int) (*composit
 		D: s.senderPrivateKey.D,
 	}
 
-	kek, err := deriveSender1Pu(kwAlg, ephemeralPriv, senderPriveKey, recPubKey, keySize)
+	kek, err := deriveSender1Pu(kwAlg, s.apu, s.apv, ephemeralPriv, senderPriveKey, recPubKey, keySize)
 	if err != nil {
 		return nil, err
 	}
 
+	defer cryptoutil.Zeroize(kek)
+
 	block, err := aes.NewCipher(kek)
 	if err != nil {
 		return nil, err
@@ -78,32 +91,78 @@ func (s *ECDH1PUConcatKDFSenderKW) wrapKey(kwAlg string, keySize int) (*composit
 		return nil, err
 	}
 
+	epk := composite.PublicKey{
+		X:     ephemeralPriv.PublicKey.X.Bytes(),
+		Y:     ephemeralPriv.PublicKey.Y.Bytes(),
+		Curve: ephemeralPriv.PublicKey.Curve.Params().Name,
+		Type:  keyType,
+	}
+
+	if s.pointFormat == composite.CompressedPointFormat {
+		epk.X = composite.CompressPoint(c, ephemeralPriv.PublicKey.X, ephemeralPriv.PublicKey.Y)
+		epk.Y = nil
+	}
+
 	return &composite.RecipientWrappedKey{
 		KID:          s.recipientPublicKey.KID,
 		EncryptedCEK: wk,
-		EPK: composite.PublicKey{
-			X:     ephemeralPriv.PublicKey.X.Bytes(),
-			Y:     ephemeralPriv.PublicKey.Y.Bytes(),
-			Curve: ephemeralPriv.PublicKey.Curve.Params().Name,
-			Type:  keyType,
-		},
-		Alg: kwAlg,
+		EPK:          epk,
+		Alg:          kwAlg,
 	}, nil
 }
 
-func deriveSender1Pu(kwAlg string, ephemeralPriv, senderPrivKey *ecdsa.PrivateKey, recPubKey *ecdsa.PublicKey,
-	keySize int) ([]byte, error) {
-	ze := josecipher.DeriveECDHES(kwAlg, []byte{}, []byte{}, ephemeralPriv, recPubKey, keySize)
-	zs := josecipher.DeriveECDHES(kwAlg, []byte{}, []byte{}, senderPrivKey, recPubKey, keySize)
+func deriveSender1Pu(kwAlg string, apu, apv []byte, ephemeralPriv, senderPrivKey *ecdsa.PrivateKey,
+	recPubKey *ecdsa.PublicKey, keySize int) ([]byte, error) {
+	ze, err := ecdhSharedSecret(ephemeralPriv, recPubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cryptoutil.Zeroize(ze)
+
+	zs, err := ecdhSharedSecret(senderPrivKey, recPubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cryptoutil.Zeroize(zs)
+
+	return derive1Pu(kwAlg, apu, apv, ze, zs, keySize)
+}
+
+// ecdhSharedSecret returns the raw ECDH shared secret Z of privKey and pubKey: the x-coordinate of the shared point
+// padded to the size of the curve.
+func ecdhSharedSecret(privKey *ecdsa.PrivateKey, pubKey *ecdsa.PublicKey) ([]byte, error) {
+	if privKey.Curve != pubKey.Curve || !pubKey.Curve.IsOnCurve(pubKey.X, pubKey.Y) {
+		return nil, errors.New("ecdh-1pu: public key is not on the curve of the private key")
+	}
 
-	return derive1Pu(kwAlg, ze, zs, keySize)
+	x, _ := pubKey.Curve.ScalarMult(pubKey.X, pubKey.Y, privKey.D.Bytes())
+
+	xBytes := x.Bytes()
+	defer cryptoutil.Zeroize(xBytes)
+
+	z := make([]byte, curveSize(pubKey.Curve))
+	copy(z[len(z)-len(xBytes):], xBytes)
+
+	return z, nil
 }
 
-func derive1Pu(kwAlg string, ze, zs []byte, keySize int) ([]byte, error) {
-	z := append(ze, zs...)
+func curveSize(c elliptic.Curve) int {
+	bitsInByte := 8
+
+	return (c.Params().BitSize + bitsInByte - 1) / bitsInByte
+}
+
+// derive1Pu derives the key encryption key from the ephemeral and static shared secrets ze and zs with the One-Step
+// KDF as per https://tools.ietf.org/html/draft-madden-jose-ecdh-1pu-03#section-2.2, kwAlg being the AlgorithmID.
+func derive1Pu(kwAlg string, apu, apv, ze, zs []byte, keySize int) ([]byte, error) {
+	z := append(append([]byte{}, ze...), zs...)
+	defer cryptoutil.Zeroize(z)
+
 	algID := cryptoutil.LengthPrefix([]byte(kwAlg))
-	ptyUInfo := cryptoutil.LengthPrefix([]byte{})
-	ptyVInfo := cryptoutil.LengthPrefix([]byte{})
+	ptyUInfo := cryptoutil.LengthPrefix(apu)
+	ptyVInfo := cryptoutil.LengthPrefix(apv)
 
 	supPubLen := 4
 	supPubInfo := make([]byte, supPubLen)
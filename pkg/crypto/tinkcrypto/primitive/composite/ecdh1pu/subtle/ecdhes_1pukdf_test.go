@@ -0,0 +1,87 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package subtle
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestDerive1PuDraftVector runs the example of https://tools.ietf.org/html/draft-madden-jose-ecdh-1pu-03#appendix-A
+// where Alice sends a message to Bob with apu "Alice", apv "Bob" and direct key agreement using A256GCM.
+func TestDerive1PuDraftVector(t *testing.T) {
+	alice := privateKey(t, "WKn-ZIGevcwGIyyrzFoZNBdaq9_TsqzGl96oc0CWuis", "y77t-RvAHRKTsSGdIYUfweuOvwrvDD-Q3Hv5J0fSKbE",
+		"Hndv7ZZjs_ke8o9zXYo3iq-Yr8SewI5vrqd0pAvEPqg")
+	bob := privateKey(t, "weNJy2HscCSM6AEDTDg04biOvhFhyyWvOHQfeF_PxMQ", "e8lnCO-AlStT-NJVX-crhB7QRYhiix03illJOVAOyck",
+		"VEmDZpDXXK8p8N0Cndsxs924q6nS1RXFASRl6BfUqdw")
+	ephemeral := privateKey(t, "gI0GAILBdu7T53akrFmMyGcsF3n5dO7MmwNBHKW5SV0",
+		"SLW_xSffzlPWrHEVI30DHM_4egVwt3NQqeUD7nMFpps", "0_NxaRPUMQoAJt50Gz8YiTr8gRTwyEaCumd-MToTmIo")
+
+	ze, err := ecdhSharedSecret(ephemeral, &bob.PublicKey)
+	require.NoError(t, err)
+	require.Equal(t, "9e56d91d817135d372834283bf84269cfb316ea3da806a48f6daa7798cfe90c4", hex.EncodeToString(ze))
+
+	zs, err := ecdhSharedSecret(alice, &bob.PublicKey)
+	require.NoError(t, err)
+	require.Equal(t, "e3ca3474384c9f62b30bfd4c688b3e7d4110a1b4badc3cc54ef7b81241efd50d", hex.EncodeToString(zs))
+
+	expected := "6caf13723d14850ad4b42cd6dde935bffd2fff00a9ba70de05c203a5e1722ca7"
+	keySize := 32
+
+	key, err := deriveSender1Pu(A256GCM, []byte("Alice"), []byte("Bob"), ephemeral, alice, &bob.PublicKey, keySize)
+	require.NoError(t, err)
+	require.Equal(t, expected, hex.EncodeToString(key))
+
+	key, err = deriveRecipient1Pu(A256GCM, []byte("Alice"), []byte("Bob"), &ephemeral.PublicKey, &alice.PublicKey,
+		bob, keySize)
+	require.NoError(t, err)
+	require.Equal(t, expected, hex.EncodeToString(key))
+
+	t.Run("test different party info derive a different key", func(t *testing.T) {
+		key, err = deriveRecipient1Pu(A256GCM, []byte("Alice"), []byte("Charlie"), &ephemeral.PublicKey,
+			&alice.PublicKey, bob, keySize)
+		require.NoError(t, err)
+		require.NotEqual(t, expected, hex.EncodeToString(key))
+	})
+
+	t.Run("test keys on different curves fail", func(t *testing.T) {
+		other, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		_, err = deriveRecipient1Pu(A256GCM, nil, nil, &other.PublicKey, &alice.PublicKey, bob, keySize)
+		require.EqualError(t, err, "ecdh-1pu: public key is not on the curve of the private key")
+	})
+}
+
+func privateKey(t *testing.T, x, y, d string) *ecdsa.PrivateKey {
+	t.Helper()
+
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     decodeBigInt(t, x),
+			Y:     decodeBigInt(t, y),
+		},
+		D: decodeBigInt(t, d),
+	}
+}
+
+func decodeBigInt(t *testing.T, s string) *big.Int {
+	t.Helper()
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	require.NoError(t, err)
+
+	return new(big.Int).SetBytes(b)
+}
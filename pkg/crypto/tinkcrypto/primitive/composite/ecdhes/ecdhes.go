@@ -19,9 +19,8 @@ SPDX-License-Identifier: Apache-2.0
 //  package main
 //
 //  import (
-//      "bytes"
-//
 //      "github.com/google/tink/go/keyset"
+//      commonpb "github.com/google/tink/go/proto/common_go_proto"
 //
 //      "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
 //      "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/ecdhes"
@@ -29,27 +28,22 @@ SPDX-License-Identifier: Apache-2.0
 //
 //  func main() {
 //      // create recipient side keyset handle
-//      recKH, err := keyset.NewHandle(ecdhes.ECDHES256KWAES256GCMKeyTemplate())
+//      kt, err := ecdhes.NewECDHESKeyTemplate(ecdhes.WithCurve(commonpb.EllipticCurveType_NIST_P256))
 //      if err != nil {
 //          //handle error
 //      }
 //
-//      // extract recipient public keyset handle and key
-//      recPubKH, err := recKH.Public()
+//      recKH, err := keyset.NewHandle(kt)
 //      if err != nil {
 //          //handle error
 //      }
 //
-//      buf := new(bytes.Buffer)
-//      pubKeyWriter := ecdhes.NewWriter(buf)
-//      err = recPubKH.WriteWithNoSecrets(pubKeyWriter)
+//      // extract recipient public key
+//      ecPubKey, err := ecdhes.PublicKeyFromKeysetHandle(recKH)
 //      if err != nil {
 //          //handle error
 //      }
 //
-//      ecPubKey := new(composite.PublicKey)
-//      err := json.Unmarshal(buf.Bytes(), ecPubKey)
-//
 //      // now create sender keyset handle with recipient public key (ecPubKey)
 //      sKH, err := keyset.NewHandle(ECDHES256KWAES256GCMKeyTemplateWithRecipients(
 //     		[]composite.PublicKey{*ecPubKey}))
@@ -58,7 +52,11 @@ SPDX-License-Identifier: Apache-2.0
 //      }
 //
 //      // for more recipient keys pass in a list: []composite.PublicKey{*ecPubKey1, *ecPubKey2, *ecPubKey3, etc.})
-//      // at least 1 recipient is required.
+//      // at least 1 recipient is required. Recipients keys may be on different curves, the key of each recipient is
+//      // wrapped with an ephemeral key on the curve of the recipient key.
+//      // the Concat KDF AlgorithmID and party info (apu/apv) default to the key wrapping algorithm and empty values,
+//      // they can be set with the WithAlgorithmID and WithPartyInfo options of NewECDHESKeyTemplate. The sender
+//      // and the recipients keys must then be created with the same options.
 //
 //      // extract sender public keyset handle to encrypt
 //      senderPubKH, err := sKH.Public()
@@ -82,6 +80,22 @@ SPDX-License-Identifier: Apache-2.0
 //      if err != nil {
 //          // handle error
 //      }
+//
+//      // alternatively, ecdhes.Encrypt and ecdhes.Decrypt fetch the primitives from the keyset handles:
+//      ct, err = ecdhes.Encrypt(sKH, []byte("secret message"), []byte("some aad"))
+//      pt, err = ecdhes.Decrypt(refRecKH, ct, []byte("some aad"))
+//
+//      // large payloads are streamed with ecdhes.EncryptWriter and ecdhes.DecryptReader without loading them in memory,
+//      // the CEK is wrapped once and the content is encrypted in segments (the stream is not a JWE message):
+//      w, err := ecdhes.EncryptWriter(sKH, dst, []byte("some aad"))
+//      _, err = io.Copy(w, src)
+//      err = w.Close()
+//      r, err := ecdhes.DecryptReader(refRecKH, encryptedSrc, []byte("some aad"))
+//
+//      // to move the recipient to another curve, rotate its key: RotateKeysetHandle generates a fresh key on the
+//      // target curve with the same metadata (key material is not converted). Keep `recKH` to decrypt messages sent
+//      // to the old key until the new public key (rotated.PublicKey) is published.
+//      rotated, err := ecdhes.RotateKeysetHandle(recKH, commonpb.EllipticCurveType_NIST_P384)
 //  }
 package ecdhes
 
@@ -89,13 +103,15 @@ import (
 	"fmt"
 
 	"github.com/google/tink/go/core/registry"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
 )
 
 // TODO - find a better way to setup tink than init.
 // nolint: gochecknoinits
 func init() {
 	// TODO - avoid the tink registry singleton.
-	err := registry.RegisterKeyManager(newECDHESPrivateKeyManager())
+	err := composite.RegisterKeyWrappingKeyManager(newECDHESPrivateKeyManager())
 	if err != nil {
 		panic(fmt.Sprintf("ecdhes.init() failed: %v", err))
 	}
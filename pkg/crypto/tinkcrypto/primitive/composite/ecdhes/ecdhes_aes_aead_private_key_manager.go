@@ -15,9 +15,11 @@ import (
 	"github.com/google/tink/go/core/registry"
 	hybrid "github.com/google/tink/go/hybrid/subtle"
 	"github.com/google/tink/go/keyset"
+	tinkcommonpb "github.com/google/tink/go/proto/common_go_proto"
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/api"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/ecdhes/subtle"
 	commonpb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
 	ecdhespb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/ecdhes_aead_go_proto"
@@ -39,6 +41,12 @@ type ecdhesAESPrivateKeyManager struct{}
 // Assert that ecdhesAESPrivateKeyManager implements the PrivateKeyManager interface.
 var _ registry.PrivateKeyManager = (*ecdhesAESPrivateKeyManager)(nil)
 
+// Assert that ecdhesAESPrivateKeyManager lists its supported curves.
+var _ composite.CurvesSupporter = (*ecdhesAESPrivateKeyManager)(nil)
+
+// Assert that ecdhesAESPrivateKeyManager supports composite.ValidateKeyTemplate.
+var _ composite.KeyTemplateValidator = (*ecdhesAESPrivateKeyManager)(nil)
+
 // newECDHESPrivateKeyManager creates a new ecdhesAESPrivateKeyManager.
 func newECDHESPrivateKeyManager() *ecdhesAESPrivateKeyManager {
 	return new(ecdhesAESPrivateKeyManager)
@@ -62,17 +70,31 @@ func (km *ecdhesAESPrivateKeyManager) Primitive(serializedKey []byte) (interface
 		return nil, errInvalidECDHESAESPrivateKey
 	}
 
-	pvt := hybrid.GetECPrivateKey(curve, key.KeyValue)
-
 	rEnc, err := composite.NewRegisterCompositeAEADEncHelper(key.PublicKey.Params.EncParams.AeadEnc)
 	if err != nil {
 		return nil, fmt.Errorf("ecdhes_aes_private_key_manager: NewRegisterCompositeAEADEncHelper "+
 			"failed: %w", err)
 	}
 
+	kwAlg, err := kwAlgorithm(key.PublicKey.Params.KwParams.KwKeySize)
+	if err != nil {
+		return nil, errInvalidECDHESAESPrivateKey
+	}
+
+	kwParams := key.PublicKey.Params.KwParams
+	aadPrefix := key.PublicKey.Params.EncParams.AadPrefix
+
+	if isOKPKey(kwParams) {
+		return subtle.NewECDHESX25519AEADCompositeDecrypt(key.KeyValue, rEnc, kwAlg, key.PublicKey.KID,
+			kwParams.AlgId, kwParams.Apu, kwParams.Apv, aadPrefix), nil
+	}
+
+	pvt := hybrid.GetECPrivateKey(curve, key.KeyValue)
+
 	ptFormat := key.PublicKey.Params.EcPointFormat.String()
 
-	return subtle.NewECDHESAEADCompositeDecrypt(pvt, ptFormat, rEnc, commonpb.KeyType_EC), nil
+	return subtle.NewECDHESAEADCompositeDecrypt(pvt, ptFormat, rEnc, commonpb.KeyType_EC, kwAlg,
+		key.PublicKey.KID, kwParams.AlgId, kwParams.Apu, kwParams.Apv, aadPrefix), nil
 }
 
 // NewKey creates a new key according to the specification of ECDHESPrivateKey format.
@@ -93,6 +115,10 @@ func (km *ecdhesAESPrivateKeyManager) NewKey(serializedKeyFormat []byte) (proto.
 		return nil, errInvalidECDHESAESPrivateKeyFormat
 	}
 
+	if isOKPKey(keyFormat.Params.KwParams) {
+		return newX25519PrivateKey(keyFormat.Params)
+	}
+
 	keyFormat.Params.KwParams.KeyType = commonpb.KeyType_EC
 
 	pvt, err := hybrid.GenerateECDHKeyPair(curve)
@@ -163,6 +189,113 @@ func (km *ecdhesAESPrivateKeyManager) TypeURL() string {
 	return ecdhesAESPrivateKeyTypeURL
 }
 
+// SupportedCurves returns the curves of the keys managed by this key manager: the NIST P curves and X25519 for OKP
+// keys.
+func (km *ecdhesAESPrivateKeyManager) SupportedCurves() []string {
+	return []string{
+		elliptic.P256().Params().Name,
+		elliptic.P384().Params().Name,
+		elliptic.P521().Params().Name,
+		subtle.X25519Curve,
+	}
+}
+
+// ValidateKeyFormat parses the given serialized ECDHESKeyFormat and validates its curve, key wrapping key size,
+// content encryption and point format.
+func (km *ecdhesAESPrivateKeyManager) ValidateKeyFormat(serializedKeyFormat []byte) error {
+	keyFormat := new(ecdhespb.EcdhesAeadKeyFormat)
+
+	err := proto.Unmarshal(serializedKeyFormat, keyFormat)
+	if err != nil {
+		return fmt.Errorf("ecdhes_aes_private_key_manager: failed to unmarshal key format: %w", err)
+	}
+
+	params := keyFormat.Params
+	if params == nil || params.KwParams == nil || params.EncParams == nil || params.EncParams.AeadEnc == nil {
+		return errors.New("ecdhes_aes_private_key_manager: key format params are missing")
+	}
+
+	_, err = validateKeyFormat(params)
+	if err != nil {
+		return err
+	}
+
+	_, err = composite.NewRegisterCompositeAEADEncHelper(params.EncParams.AeadEnc)
+	if err != nil {
+		return fmt.Errorf("ecdhes_aes_private_key_manager: unsupported content encryption: %w", err)
+	}
+
+	switch params.EcPointFormat {
+	case tinkcommonpb.EcPointFormat_UNCOMPRESSED:
+	case tinkcommonpb.EcPointFormat_COMPRESSED:
+		if isOKPKey(params.KwParams) {
+			return errors.New("ecdhes_aes_private_key_manager: compressed point format is not supported by " +
+				"OKP keys")
+		}
+	default:
+		return fmt.Errorf("ecdhes_aes_private_key_manager: unsupported point format '%s'", params.EcPointFormat)
+	}
+
+	return nil
+}
+
+// SelfEncryptionPrimitives returns the ECDHESAEADCompositeEncrypt primitive of the public key of the given serialized
+// ECDHESPrivateKey, with the key itself as the only recipient, and the ECDHESAEADCompositeDecrypt primitive of the key.
+func (km *ecdhesAESPrivateKeyManager) SelfEncryptionPrimitives(serializedPrivKey []byte) (api.CompositeEncrypt,
+	api.CompositeDecrypt, error) {
+	p, err := km.Primitive(serializedPrivKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	d, ok := p.(api.CompositeDecrypt)
+	if !ok {
+		return nil, nil, errors.New("ecdhes_aes_private_key_manager: not a CompositeDecrypt primitive")
+	}
+
+	privKey := new(ecdhespb.EcdhesAeadPrivateKey)
+
+	err = proto.Unmarshal(serializedPrivKey, privKey)
+	if err != nil {
+		return nil, nil, errInvalidECDHESAESPrivateKey
+	}
+
+	pubKey, ok := proto.Clone(privKey.PublicKey).(*ecdhespb.EcdhesAeadPublicKey)
+	if !ok {
+		return nil, nil, errInvalidECDHESAESPrivateKey
+	}
+
+	keyType := commonpb.KeyType_EC
+	if isOKPKey(pubKey.Params.KwParams) {
+		keyType = commonpb.KeyType_OKP
+	}
+
+	pubKey.Params.KwParams.Recipients = []*commonpb.ECPublicKey{{
+		KID:       pubKey.KID,
+		CurveType: pubKey.Params.KwParams.CurveType,
+		KeyType:   keyType,
+		X:         pubKey.X,
+		Y:         pubKey.Y,
+	}}
+
+	serializedPubKey, err := proto.Marshal(pubKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ecdhes_aes_private_key_manager: Proto.Marshal failed: %w", err)
+	}
+
+	p, err = newECDHESPublicKeyManager().Primitive(serializedPubKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e, ok := p.(api.CompositeEncrypt)
+	if !ok {
+		return nil, nil, errors.New("ecdhes_aes_private_key_manager: not a CompositeEncrypt primitive")
+	}
+
+	return e, d, nil
+}
+
 // validateKey validates the given ECDHESPrivateKey and erturns the KW curve.
 func (km *ecdhesAESPrivateKeyManager) validateKey(key *ecdhespb.EcdhesAeadPrivateKey) (elliptic.Curve, error) {
 	err := keyset.ValidateKeyVersion(key.Version, ecdhesAESPrivateKeyVersion)
@@ -173,9 +306,24 @@ func (km *ecdhesAESPrivateKeyManager) validateKey(key *ecdhespb.EcdhesAeadPrivat
 	return validateKeyFormat(key.PublicKey.Params)
 }
 
-// validateKeyFormat validates the given ECDHESKeyFormat and returns the KW Curve.
+// validateKeyFormat validates the given ECDHESKeyFormat and returns the KW Curve. The curve is nil for OKP keys.
 func validateKeyFormat(params *ecdhespb.EcdhesAeadParams) (elliptic.Curve, error) {
-	c, err := hybrid.GetCurve(params.KwParams.CurveType.String())
+	var (
+		c   elliptic.Curve
+		err error
+	)
+
+	if isOKPKey(params.KwParams) {
+		err = validateOKPCurve(params.KwParams.CurveType)
+	} else {
+		c, err = composite.GetCurve(params.KwParams.CurveType.String())
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes_aes_private_key_manager: invalid key: %w", err)
+	}
+
+	_, err = kwAlgorithm(params.KwParams.KwKeySize)
 	if err != nil {
 		return nil, fmt.Errorf("ecdhes_aes_private_key_manager: invalid key: %w", err)
 	}
@@ -192,3 +340,18 @@ func validateKeyFormat(params *ecdhespb.EcdhesAeadParams) (elliptic.Curve, error
 
 	return c, nil
 }
+
+// kwAlgorithm returns the ECDH-ES key wrapping algorithm matching the given AES key wrapping key size in bytes. A zero
+// size is for keys created before the size was stored in their KW params, these keys use A256KW.
+func kwAlgorithm(kwKeySize uint32) (string, error) {
+	switch kwKeySize {
+	case 0, a256KWKeySize:
+		return subtle.A256KWAlg, nil
+	case a128KWKeySize:
+		return subtle.A128KWAlg, nil
+	case a192KWKeySize:
+		return subtle.A192KWAlg, nil
+	default:
+		return "", fmt.Errorf("unsupported key wrapping key size %d", kwKeySize)
+	}
+}
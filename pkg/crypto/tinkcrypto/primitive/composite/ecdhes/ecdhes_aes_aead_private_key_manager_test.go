@@ -22,6 +22,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/ecdhes/subtle"
+	compositepb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
 	ecdhespb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/ecdhes_aead_go_proto"
 )
 
@@ -313,3 +315,59 @@ func TestEcdhesPrivateKeyManager_NewKey(t *testing.T) {
 		})
 	}
 }
+
+func TestECDHESPrivateKeyManagerNewKeyWithBadKWKeySize(t *testing.T) {
+	km := newECDHESPrivateKeyManager()
+
+	privKeyProto := &ecdhespb.EcdhesAeadKeyFormat{
+		Params: &ecdhespb.EcdhesAeadParams{
+			KwParams: &ecdhespb.EcdhesKwParams{
+				CurveType: commonpb.EllipticCurveType_NIST_P256,
+				KeyType:   compositepb.KeyType_EC,
+				KwKeySize: 20,
+			},
+			EncParams: &ecdhespb.EcdhesAeadEncParams{
+				AeadEnc: aead.AES256GCMKeyTemplate(),
+			},
+			EcPointFormat: commonpb.EcPointFormat_UNCOMPRESSED,
+		},
+	}
+
+	sPrivKey, err := proto.Marshal(privKeyProto)
+	require.NoError(t, err)
+
+	p, err := km.NewKey(sPrivKey)
+	require.EqualError(t, err, errInvalidECDHESAESPrivateKeyFormat.Error())
+	require.Empty(t, p)
+}
+
+func TestKWAlgorithm(t *testing.T) {
+	for size, alg := range map[uint32]string{
+		0:             subtle.A256KWAlg,
+		a128KWKeySize: subtle.A128KWAlg,
+		a192KWKeySize: subtle.A192KWAlg,
+		a256KWKeySize: subtle.A256KWAlg,
+	} {
+		kwAlg, err := kwAlgorithm(size)
+		require.NoError(t, err)
+		require.Equal(t, alg, kwAlg)
+	}
+
+	_, err := kwAlgorithm(20)
+	require.EqualError(t, err, "unsupported key wrapping key size 20")
+}
+
+func TestECDHESPrivateKeyManagerSupportedCurves(t *testing.T) {
+	curves := composite.SupportedCurves()
+
+	for _, c := range newECDHESPrivateKeyManager().SupportedCurves() {
+		require.Contains(t, curves, c)
+
+		// every advertised curve can be used in a key template
+		curveType, err := composite.GetCurveType(c)
+		require.NoError(t, err)
+
+		_, err = NewECDHESKeyTemplate(WithCurve(curveType))
+		require.NoError(t, err)
+	}
+}
@@ -13,7 +13,6 @@ import (
 
 	"github.com/golang/protobuf/proto"
 	"github.com/google/tink/go/core/registry"
-	hybrid "github.com/google/tink/go/hybrid/subtle"
 	"github.com/google/tink/go/keyset"
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 
@@ -86,9 +85,22 @@ func (km *ecdhesPublicKeyManager) Primitive(serializedKey []byte) (interface{},
 			"failed: %w", err)
 	}
 
+	kwAlg, err := kwAlgorithm(ecdhesPubKey.Params.KwParams.KwKeySize)
+	if err != nil {
+		return nil, errInvalidECDHESAESPublicKey
+	}
+
 	ptFormat := ecdhesPubKey.Params.EcPointFormat.String()
 
-	return subtle.NewECDHESAEADCompositeEncrypt(recipientsKeys, ptFormat, rEnc, compositepb.KeyType_EC), nil
+	keyType := compositepb.KeyType_EC
+	if isOKPKey(ecdhesPubKey.Params.KwParams) {
+		keyType = compositepb.KeyType_OKP
+	}
+
+	kwParams := ecdhesPubKey.Params.KwParams
+
+	return subtle.NewECDHESAEADCompositeEncrypt(recipientsKeys, ptFormat, rEnc, keyType, kwAlg, kwParams.AlgId,
+		kwParams.Apu, kwParams.Apv, ecdhesPubKey.Params.EncParams.AadPrefix), nil
 }
 
 // DoesSupport indicates if this key manager supports the given key type.
@@ -133,7 +145,11 @@ func (km *ecdhesPublicKeyManager) validateRecKey(key *compositepb.ECPublicKey) e
 		return fmt.Errorf("ecdhes_aes_public_key_manager: GetKeyType error: %w", err)
 	}
 
-	_, err = hybrid.GetCurve(key.CurveType.String())
+	if key.KeyType == compositepb.KeyType_OKP {
+		err = validateOKPCurve(key.CurveType)
+	} else {
+		_, err = composite.GetCurve(key.CurveType.String())
+	}
 	if err != nil {
 		return fmt.Errorf("ecdhes_aes_public_key_manager: GetCurve error: %w", err)
 	}
@@ -0,0 +1,137 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ecdhes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/tink/go/keyset"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/api"
+)
+
+// Encrypt encrypts plaintext with aad for the recipients of the encryption keyset handle kh and returns the
+// serialized composite.EncryptedData. kh must be created from a key template with recipients (ie
+// ECDHES256KWAES256GCMKeyTemplateWithRecipients) or be its public keyset handle.
+func Encrypt(kh *keyset.Handle, plaintext, aad []byte) ([]byte, error) {
+	pubKH, err := encryptionKeysetHandle(kh)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes: Encrypt: %w", err)
+	}
+
+	e, err := NewECDHESEncrypt(pubKH)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes: Encrypt: %w", err)
+	}
+
+	return e.Encrypt(plaintext, aad)
+}
+
+// Decrypt decrypts ciphertext, the serialized composite.EncryptedData returned by Encrypt, with aad using the
+// decryption keyset handle kh. kh must be a private keyset handle created from a key template without recipients.
+func Decrypt(kh *keyset.Handle, ciphertext, aad []byte) ([]byte, error) {
+	err := validateDecryptionKeysetHandle(kh)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes: Decrypt: %w", err)
+	}
+
+	d, err := NewECDHESDecrypt(kh)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes: Decrypt: %w", err)
+	}
+
+	return d.Decrypt(ciphertext, aad)
+}
+
+// EncryptWriter returns a WriteCloser encrypting the plaintext written to it with aad for the recipients of the
+// encryption keyset handle kh, the resulting stream is written to w. Unlike Encrypt, the plaintext is not loaded in
+// memory: the CEK is wrapped once for all the recipients in a stream header, then the content is encrypted in
+// segments with AES256-GCM-HKDF streaming AEAD (the content encryption key template of kh is not used). Close must be
+// called to encrypt the last segment and its tag, it does not close w. The stream is not a JWE message, it can only
+// be decrypted with DecryptReader. kh requirements are the same as Encrypt.
+func EncryptWriter(kh *keyset.Handle, w io.Writer, aad []byte) (io.WriteCloser, error) {
+	pubKH, err := encryptionKeysetHandle(kh)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes: EncryptWriter: %w", err)
+	}
+
+	e, err := NewECDHESEncrypt(pubKH)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes: EncryptWriter: %w", err)
+	}
+
+	se, ok := e.(api.CompositeStreamingEncrypt)
+	if !ok {
+		return nil, errors.New("ecdhes: EncryptWriter: not a CompositeStreamingEncrypt primitive")
+	}
+
+	return se.EncryptWriter(w, aad)
+}
+
+// DecryptReader returns a Reader decrypting the stream read from r, created by EncryptWriter, with aad using the
+// decryption keyset handle kh. The stream header is read before DecryptReader returns, the content is decrypted and
+// authenticated segment by segment as it is read: a Read error means the stream was altered or truncated and the
+// plaintext read so far must be discarded. kh requirements are the same as Decrypt.
+func DecryptReader(kh *keyset.Handle, r io.Reader, aad []byte) (io.Reader, error) {
+	err := validateDecryptionKeysetHandle(kh)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes: DecryptReader: %w", err)
+	}
+
+	d, err := NewECDHESDecrypt(kh)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes: DecryptReader: %w", err)
+	}
+
+	sd, ok := d.(api.CompositeStreamingDecrypt)
+	if !ok {
+		return nil, errors.New("ecdhes: DecryptReader: not a CompositeStreamingDecrypt primitive")
+	}
+
+	return sd.DecryptReader(r, aad)
+}
+
+// encryptionKeysetHandle validates kh is an encryption keyset handle (ie with recipients keys) and returns its public
+// keyset handle.
+func encryptionKeysetHandle(kh *keyset.Handle) (*keyset.Handle, error) {
+	pubKey, err := primaryPublicKey(kh)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pubKey.Params.KwParams.Recipients) == 0 {
+		return nil, errors.New("keyset handle has no recipients keys, it is not an encryption handle")
+	}
+
+	pubKH, err := kh.Public()
+	if err != nil {
+		// kh is already a public keyset handle
+		pubKH = kh
+	}
+
+	return pubKH, nil
+}
+
+// validateDecryptionKeysetHandle validates kh is a private keyset handle without recipients keys.
+func validateDecryptionKeysetHandle(kh *keyset.Handle) error {
+	pubKey, err := primaryPublicKey(kh)
+	if err != nil {
+		return err
+	}
+
+	if len(pubKey.Params.KwParams.Recipients) > 0 {
+		return errors.New("keyset handle has recipients keys, it is an encryption handle")
+	}
+
+	if _, err = kh.Public(); err != nil {
+		return errors.New("keyset handle is not a private keyset handle")
+	}
+
+	return nil
+}
@@ -0,0 +1,221 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ecdhes
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"github.com/google/tink/go/subtle/random"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	var (
+		recKHs     []*keyset.Handle
+		recPubKeys []*composite.PublicKey
+	)
+
+	for i := 0; i < 2; i++ {
+		recKH, err := keyset.NewHandle(ECDHES256KWAES256GCMKeyTemplate())
+		require.NoError(t, err)
+
+		recPubKey, err := PublicKeyFromKeysetHandle(recKH)
+		require.NoError(t, err)
+
+		recKHs = append(recKHs, recKH)
+		recPubKeys = append(recPubKeys, recPubKey)
+	}
+
+	kt, err := ECDHES256KWAES256GCMKeyTemplateWithRecipients(recPubKeys)
+	require.NoError(t, err)
+
+	senderKH, err := keyset.NewHandle(kt)
+	require.NoError(t, err)
+
+	senderPubKH, err := senderKH.Public()
+	require.NoError(t, err)
+
+	pt := []byte("secret message")
+	aad := []byte("aad message")
+
+	for _, kh := range []*keyset.Handle{senderKH, senderPubKH} {
+		ct, err := Encrypt(kh, pt, aad)
+		require.NoError(t, err)
+
+		for _, recKH := range recKHs {
+			dpt, err := Decrypt(recKH, ct, aad)
+			require.NoError(t, err)
+			require.Equal(t, pt, dpt)
+		}
+	}
+
+	t.Run("test encrypt with a decryption keyset handle", func(t *testing.T) {
+		_, err := Encrypt(recKHs[0], pt, aad)
+		require.EqualError(t, err, "ecdhes: Encrypt: keyset handle has no recipients keys, it is not an encryption "+
+			"handle")
+	})
+
+	t.Run("test decrypt with an encryption keyset handle", func(t *testing.T) {
+		ct, err := Encrypt(senderKH, pt, aad)
+		require.NoError(t, err)
+
+		_, err = Decrypt(senderKH, ct, aad)
+		require.EqualError(t, err, "ecdhes: Decrypt: keyset handle has recipients keys, it is an encryption handle")
+
+		recPubKH, err := recKHs[0].Public()
+		require.NoError(t, err)
+
+		_, err = Decrypt(recPubKH, ct, aad)
+		require.EqualError(t, err, "ecdhes: Decrypt: keyset handle is not a private keyset handle")
+	})
+
+	t.Run("test decrypt with the wrong aad", func(t *testing.T) {
+		ct, err := Encrypt(senderKH, pt, aad)
+		require.NoError(t, err)
+
+		_, err = Decrypt(recKHs[0], ct, []byte("other aad"))
+		require.EqualError(t, err, "ecdhes_factory: decryption failed")
+	})
+
+	t.Run("test invalid keyset handles", func(t *testing.T) {
+		_, err := Encrypt(nil, pt, aad)
+		require.EqualError(t, err, "ecdhes: Encrypt: keyset handle is nil")
+
+		_, err = Decrypt(nil, pt, aad)
+		require.EqualError(t, err, "ecdhes: Decrypt: keyset handle is nil")
+
+		aeadKH, err := keyset.NewHandle(aead.AES256GCMKeyTemplate())
+		require.NoError(t, err)
+
+		_, err = Encrypt(aeadKH, pt, aad)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ecdhes: Encrypt: failed to read public keyset")
+
+		_, err = Decrypt(aeadKH, pt, aad)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ecdhes: Decrypt: failed to read public keyset")
+	})
+}
+
+func TestEncryptWriterDecryptReader(t *testing.T) {
+	// recipients keys on different curves
+	recKHs := make([]*keyset.Handle, 0, 2)
+	recPubKeys := make([]*composite.PublicKey, 0, 2)
+
+	for _, tmpl := range []*tinkpb.KeyTemplate{
+		ECDHES256KWAES256GCMKeyTemplate(), ECDHESX25519KWXChaChaKeyTemplate(),
+	} {
+		recKH, err := keyset.NewHandle(tmpl)
+		require.NoError(t, err)
+
+		recPubKey, err := PublicKeyFromKeysetHandle(recKH)
+		require.NoError(t, err)
+
+		recKHs = append(recKHs, recKH)
+		recPubKeys = append(recPubKeys, recPubKey)
+	}
+
+	kt, err := ECDHES256KWAES256GCMKeyTemplateWithRecipients(recPubKeys)
+	require.NoError(t, err)
+
+	senderKH, err := keyset.NewHandle(kt)
+	require.NoError(t, err)
+
+	// several segments with a partial last segment
+	pt := random.GetRandomBytes(3*composite.StreamSegmentSize + 100)
+	aad := []byte("aad message")
+
+	encryptStream := func(t *testing.T) []byte {
+		t.Helper()
+
+		ct := new(bytes.Buffer)
+
+		w, err := EncryptWriter(senderKH, ct, aad)
+		require.NoError(t, err)
+
+		// write in chunks smaller than a segment
+		for i := 0; i < len(pt); i += 1000 {
+			end := i + 1000
+			if end > len(pt) {
+				end = len(pt)
+			}
+
+			_, err = w.Write(pt[i:end])
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, w.Close())
+
+		return ct.Bytes()
+	}
+
+	ct := encryptStream(t)
+
+	for _, recKH := range recKHs {
+		r, err := DecryptReader(recKH, bytes.NewReader(ct), aad)
+		require.NoError(t, err)
+
+		dpt, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, pt, dpt)
+	}
+
+	t.Run("test decrypt stream with the wrong aad", func(t *testing.T) {
+		r, err := DecryptReader(recKHs[0], bytes.NewReader(ct), []byte("other aad"))
+		require.NoError(t, err)
+
+		_, err = ioutil.ReadAll(r)
+		require.Error(t, err)
+	})
+
+	t.Run("test decrypt truncated stream", func(t *testing.T) {
+		r, err := DecryptReader(recKHs[0], bytes.NewReader(ct[:len(ct)-composite.StreamSegmentSize]), aad)
+		require.NoError(t, err)
+
+		_, err = ioutil.ReadAll(r)
+		require.Error(t, err)
+	})
+
+	t.Run("test decrypt stream with a key that is not a recipient", func(t *testing.T) {
+		otherKH, err := keyset.NewHandle(ECDHES256KWAES256GCMKeyTemplate())
+		require.NoError(t, err)
+
+		_, err = DecryptReader(otherKH, bytes.NewReader(ct), aad)
+		require.EqualError(t, err, "ecdhes_factory: decryption failed")
+	})
+
+	t.Run("test decrypt a non stream ciphertext", func(t *testing.T) {
+		nonStreamCT, err := Encrypt(senderKH, pt, aad)
+		require.NoError(t, err)
+
+		_, err = DecryptReader(recKHs[0], bytes.NewReader(nonStreamCT), aad)
+		require.EqualError(t, err, "ecdhes_factory: readStreamHeader: invalid stream header length")
+	})
+
+	t.Run("test invalid keyset handles", func(t *testing.T) {
+		_, err := EncryptWriter(recKHs[0], new(bytes.Buffer), aad)
+		require.EqualError(t, err, "ecdhes: EncryptWriter: keyset handle has no recipients keys, it is not an "+
+			"encryption handle")
+
+		_, err = DecryptReader(senderKH, bytes.NewReader(ct), aad)
+		require.EqualError(t, err, "ecdhes: DecryptReader: keyset handle has recipients keys, it is an encryption "+
+			"handle")
+
+		_, err = EncryptWriter(nil, new(bytes.Buffer), aad)
+		require.EqualError(t, err, "ecdhes: EncryptWriter: keyset handle is nil")
+
+		_, err = DecryptReader(nil, bytes.NewReader(ct), aad)
+		require.EqualError(t, err, "ecdhes: DecryptReader: keyset handle is nil")
+	})
+}
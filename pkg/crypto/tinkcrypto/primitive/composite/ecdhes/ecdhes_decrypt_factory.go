@@ -7,14 +7,17 @@ SPDX-License-Identifier: Apache-2.0
 package ecdhes
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/google/tink/go/core/cryptofmt"
 	"github.com/google/tink/go/core/primitiveset"
 	"github.com/google/tink/go/core/registry"
 	"github.com/google/tink/go/keyset"
 
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/api"
 )
 
@@ -43,6 +46,9 @@ type decryptPrimitiveSet struct {
 // Asserts that primitiveSet implements the CompositeDecrypt interface.
 var _ api.CompositeDecrypt = (*decryptPrimitiveSet)(nil)
 
+// Asserts that primitiveSet implements the CompositeStreamingDecrypt interface.
+var _ api.CompositeStreamingDecrypt = (*decryptPrimitiveSet)(nil)
+
 func newDecryptPrimitiveSet(ps *primitiveset.PrimitiveSet) (*decryptPrimitiveSet, error) {
 	if _, ok := (ps.Primary.Primitive).(api.CompositeDecrypt); !ok {
 		return nil, errors.New("ecdhes_factory: not a CompositeDecrypt primitive")
@@ -66,31 +72,37 @@ func newDecryptPrimitiveSet(ps *primitiveset.PrimitiveSet) (*decryptPrimitiveSet
 // additional authenticated data. It returns the corresponding plaintext if the
 // ciphertext is authenticated.
 func (a *decryptPrimitiveSet) Decrypt(ct, aad []byte) ([]byte, error) {
-	// try non-raw keys
+	var keyErrs []*composite.KeyDecryptError
+
+	// try non-raw keys, the recipients keys may have another prefix than the sender's primary key
 	prefixSize := cryptofmt.NonRawPrefixSize
 	if len(ct) > prefixSize {
 		prefix := ct[:prefixSize]
 		ctNoPrefix := ct[prefixSize:]
 
-		entries, err := a.ps.EntriesForPrefix(string(prefix))
-		if err == nil {
-			for i := 0; i < len(entries); i++ {
-				p, ok := (entries[i].Primitive).(api.CompositeDecrypt)
-				if !ok {
-					return nil, errors.New("ecdhes_factory: not a CompositeDecrypt primitive")
-				}
-
-				pt, e := p.Decrypt(ctNoPrefix, aad)
-				if e == nil {
-					return pt, nil
-				}
+		entries := composite.SortEntriesByRecipientKIDs(composite.NonRawEntries(a.ps, string(prefix)), ctNoPrefix)
+
+		for i := 0; i < len(entries); i++ {
+			p, ok := (entries[i].Primitive).(api.CompositeDecrypt)
+			if !ok {
+				return nil, errors.New("ecdhes_factory: not a CompositeDecrypt primitive")
 			}
+
+			pt, e := p.Decrypt(ctNoPrefix, aad)
+			if e == nil {
+				return pt, nil
+			}
+
+			keyErrs = append(keyErrs, composite.KeyDecryptErrorOf(p, e))
 		}
 	}
 
-	// try raw keys
+	// try raw keys, starting with the keys of the recipients since keysets may hold several keys, ie old keys kept
+	// after a key rotation to decrypt the messages still sent to them
 	entries, err := a.ps.RawEntries()
 	if err == nil {
+		entries = composite.SortEntriesByRecipientKIDs(entries, ct)
+
 		for i := 0; i < len(entries); i++ {
 			p, ok := (entries[i].Primitive).(api.CompositeDecrypt)
 			if !ok {
@@ -101,9 +113,52 @@ func (a *decryptPrimitiveSet) Decrypt(ct, aad []byte) ([]byte, error) {
 			if e == nil {
 				return pt, nil
 			}
+
+			keyErrs = append(keyErrs, composite.KeyDecryptErrorOf(p, e))
+		}
+	}
+
+	// nothing worked
+	return nil, composite.NewDecryptError("ecdhes_factory: decryption failed", keyErrs)
+}
+
+// DecryptReader reads the stream header from r and returns a Reader decrypting the remaining content of r with the
+// first key of the enclosed primitive set that unwraps the CEK. Streams have no key prefix, the raw keys are tried
+// first then the keys with an output prefix.
+func (a *decryptPrimitiveSet) DecryptReader(r io.Reader, aad []byte) (io.Reader, error) {
+	_, header, err := composite.ReadStreamHeader(r)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes_factory: %w", err)
+	}
+
+	entries, err := a.ps.RawEntries()
+	if err != nil {
+		entries = nil
+	}
+
+	for prefix, prefixEntries := range a.ps.Entries {
+		if prefix != cryptofmt.RawPrefix {
+			entries = append(entries, prefixEntries...)
+		}
+	}
+
+	var keyErrs []*composite.KeyDecryptError
+
+	for i := 0; i < len(entries); i++ {
+		p, ok := (entries[i].Primitive).(api.CompositeStreamingDecrypt)
+		if !ok {
+			return nil, errors.New("ecdhes_factory: not a CompositeStreamingDecrypt primitive")
+		}
+
+		// each primitive reads the stream header again, the content of r is only read by the returned reader.
+		dr, e := p.DecryptReader(io.MultiReader(bytes.NewReader(header), r), aad)
+		if e == nil {
+			return dr, nil
 		}
+
+		keyErrs = append(keyErrs, composite.KeyDecryptErrorOf(p, e))
 	}
 
 	// nothing worked
-	return nil, errors.New("ecdhes_factory: decryption failed")
+	return nil, composite.NewDecryptError("ecdhes_factory: decryption failed", keyErrs)
 }
@@ -9,6 +9,7 @@ package ecdhes
 import (
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/google/tink/go/core/primitiveset"
 	"github.com/google/tink/go/core/registry"
@@ -41,6 +42,9 @@ type encryptPrimitiveSet struct {
 // Asserts that primitiveSet implements the CompositeEncrypt interface.
 var _ api.CompositeEncrypt = (*encryptPrimitiveSet)(nil)
 
+// Asserts that primitiveSet implements the CompositeStreamingEncrypt interface.
+var _ api.CompositeStreamingEncrypt = (*encryptPrimitiveSet)(nil)
+
 func newEncryptPrimitiveSet(ps *primitiveset.PrimitiveSet) (*encryptPrimitiveSet, error) {
 	if _, ok := (ps.Primary.Primitive).(api.CompositeEncrypt); !ok {
 		return nil, errors.New("ecdhes_factory: not a CompositeEncrypt primitive")
@@ -61,7 +65,8 @@ func newEncryptPrimitiveSet(ps *primitiveset.PrimitiveSet) (*encryptPrimitiveSet
 }
 
 // Encrypt encrypts the given plaintext using the recipient public key found in the enclosed primitive.
-// It returns the ciphertext being a serialized JWE []byte.
+// It returns the ciphertext being a serialized JWE []byte, prefixed with the output prefix of the primary key unless
+// it's a RAW key.
 func (a *encryptPrimitiveSet) Encrypt(pt, aad []byte) ([]byte, error) {
 	primary := a.ps.Primary
 
@@ -70,5 +75,25 @@ func (a *encryptPrimitiveSet) Encrypt(pt, aad []byte) ([]byte, error) {
 		return nil, errors.New("ecdhes_factory: not a CompositeEncrypt primitive")
 	}
 
-	return p.Encrypt(pt, aad)
+	ct, err := p.Encrypt(pt, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	if primary.Prefix == "" {
+		return ct, nil
+	}
+
+	return append([]byte(primary.Prefix), ct...), nil
+}
+
+// EncryptWriter returns a WriteCloser encrypting the plaintext written to it for the recipients of the primary key of
+// the enclosed primitive set. The resulting stream is written to w.
+func (a *encryptPrimitiveSet) EncryptWriter(w io.Writer, aad []byte) (io.WriteCloser, error) {
+	p, ok := (a.ps.Primary.Primitive).(api.CompositeStreamingEncrypt)
+	if !ok {
+		return nil, errors.New("ecdhes_factory: not a CompositeStreamingEncrypt primitive")
+	}
+
+	return p.EncryptWriter(w, aad)
 }
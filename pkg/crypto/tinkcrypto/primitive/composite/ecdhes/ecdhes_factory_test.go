@@ -9,6 +9,7 @@ package ecdhes
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -100,6 +101,89 @@ func TestECDHESFactory(t *testing.T) {
 	}
 }
 
+func TestECDHESDecryptWithRotatedKeys(t *testing.T) {
+	c := commonpb.EllipticCurveType_NIST_P256
+	ptFmt := commonpb.EcPointFormat_UNCOMPRESSED
+	encT := aead.AES256GCMKeyTemplate()
+
+	newKey := func(keyID uint32, kid string) *tinkpb.Keyset_Key {
+		privProto := generateECDHESAEADPrivateKey(t, c, ptFmt, encT)
+		privProto.PublicKey.KID = kid
+		privProto.PublicKey.Params.KwParams.Recipients[0].KID = kid
+
+		sPriv, err := proto.Marshal(privProto)
+		require.NoError(t, err)
+
+		return testutil.NewKey(
+			testutil.NewKeyData(ecdhesAESPrivateKeyTypeURL, sPriv, tinkpb.KeyData_ASYMMETRIC_PRIVATE),
+			tinkpb.KeyStatusType_ENABLED, keyID, tinkpb.OutputPrefixType_RAW)
+	}
+
+	oldKey := newKey(1, "old-kid")
+	otherKey := newKey(2, "other-kid")
+	newPrimaryKey := newKey(3, "new-kid")
+
+	// the keyset keeps the old key after the rotation to the new primary key
+	rotatedKH, err := testkeyset.NewHandle(testutil.NewKeyset(newPrimaryKey.KeyId,
+		[]*tinkpb.Keyset_Key{oldKey, otherKey, newPrimaryKey}))
+	require.NoError(t, err)
+
+	d, err := NewECDHESDecrypt(rotatedKH)
+	require.NoError(t, err)
+
+	// a message in flight is still encrypted for the old key
+	oldKH, err := testkeyset.NewHandle(testutil.NewKeyset(oldKey.KeyId, []*tinkpb.Keyset_Key{oldKey}))
+	require.NoError(t, err)
+
+	oldPubKH, err := oldKH.Public()
+	require.NoError(t, err)
+
+	e, err := NewECDHESEncrypt(oldPubKH)
+	require.NoError(t, err)
+
+	pt := []byte("secret message")
+	aad := []byte(base64.RawURLEncoding.EncodeToString([]byte(`{"enc":"A256GCM"}`)))
+
+	ct, err := e.Encrypt(pt, aad)
+	require.NoError(t, err)
+
+	encData := &composite.EncryptedData{}
+	require.NoError(t, json.Unmarshal(ct, encData))
+	require.Equal(t, "old-kid", encData.Recipients[0].KID)
+
+	dpt, err := d.Decrypt(ct, encData.SingleRecipientAAD)
+	require.NoError(t, err)
+	require.Equal(t, pt, dpt)
+
+	// keys are still found by trial decryption when the sender identifies them differently
+	encData.Recipients[0].KID = "unknown-kid"
+
+	ct, err = json.Marshal(encData)
+	require.NoError(t, err)
+
+	_, err = d.Decrypt(ct, encData.SingleRecipientAAD)
+	require.NoError(t, err)
+
+	// the recipients order the keys to try
+	ps, err := rotatedKH.Primitives()
+	require.NoError(t, err)
+
+	entries, err := ps.RawEntries()
+	require.NoError(t, err)
+
+	encData.Recipients[0].KID = "other-kid"
+
+	ct, err = json.Marshal(encData)
+	require.NoError(t, err)
+
+	sorted := composite.SortEntriesByRecipientKIDs(entries, ct)
+	require.Len(t, sorted, 3)
+	require.Equal(t, []uint32{otherKey.KeyId, oldKey.KeyId, newPrimaryKey.KeyId},
+		[]uint32{sorted[0].KeyID, sorted[1].KeyID, sorted[2].KeyID})
+
+	require.Equal(t, entries, composite.SortEntriesByRecipientKIDs(entries, []byte("not JSON")))
+}
+
 // ecdhesAEADPublicKey returns a EcdhesAeadPublicKey with specified parameters.
 func ecdhesAEADPublicKey(t *testing.T, c commonpb.EllipticCurveType, ptfmt commonpb.EcPointFormat,
 	encT *tinkpb.KeyTemplate, x, y []byte) *ecdhespb.EcdhesAeadPublicKey {
@@ -408,3 +492,102 @@ func TestDecryptPrimitiveSetFail(t *testing.T) {
 	_, err = decPrimitiveSet.Decrypt([]byte("12345plaintext"), []byte("aad"))
 	require.EqualError(t, err, "ecdhes_factory: decryption failed")
 }
+
+func TestECDHESFactoryDecryptError(t *testing.T) {
+	p256RecPubKey, p256RecKH := createRecipient(t, ECDHES256KWAES256GCMKeyTemplate())
+	x25519RecPubKey, _ := createRecipient(t, ECDHESX25519KWXChaChaKeyTemplate())
+
+	p256RecPubKey.KID = "p256-kid"
+	x25519RecPubKey.KID = "x25519-kid"
+
+	kt, err := ECDHES256KWAES256GCMKeyTemplateWithRecipients([]*composite.PublicKey{p256RecPubKey, x25519RecPubKey})
+	require.NoError(t, err)
+
+	kh, err := keyset.NewHandle(kt)
+	require.NoError(t, err)
+
+	pt := []byte("secret message")
+	aad := []byte("aad message")
+
+	ct, err := Encrypt(kh, pt, aad)
+	require.NoError(t, err)
+
+	decryptError := func(t *testing.T, recKH *keyset.Handle, ct []byte) *composite.DecryptError {
+		t.Helper()
+
+		_, err := Decrypt(recKH, ct, aad)
+		require.EqualError(t, err, "ecdhes_factory: decryption failed")
+
+		decErr := &composite.DecryptError{}
+		require.True(t, errors.As(err, &decErr))
+		require.Len(t, decErr.Keys, 1)
+
+		return decErr
+	}
+
+	t.Run("message not encrypted for the key", func(t *testing.T) {
+		otherRecPubKey, otherRecKH := createRecipient(t, ECDHES256KWAES256GCMKeyTemplate())
+
+		decErr := decryptError(t, otherRecKH, ct)
+
+		keyErr := decErr.Keys[0]
+		require.Equal(t, otherRecPubKey.KID, keyErr.KID)
+		require.EqualError(t, keyErr, "ecdh-es decrypt: cek unwrap failed for all recipients keys")
+		require.Len(t, keyErr.Recipients, 2)
+		require.Equal(t, "p256-kid", keyErr.Recipients[0].KID)
+		// the key has no KID, the failure can't be told apart from a corrupted wrapped CEK
+		require.Equal(t, composite.RecipientUnwrapFailed, keyErr.Recipients[0].Reason)
+		require.Error(t, keyErr.Recipients[0].Err)
+		require.Equal(t, "x25519-kid", keyErr.Recipients[1].KID)
+		require.Equal(t, composite.RecipientCurveUnsupported, keyErr.Recipients[1].Reason)
+
+		require.Contains(t, decErr.Detail(), "recipient 'p256-kid': CEK unwrap failed")
+		require.Contains(t, decErr.Detail(), "recipient 'x25519-kid': curve unsupported")
+	})
+
+	t.Run("key wrapping algorithm mismatch", func(t *testing.T) {
+		_, a128KWRecKH := createRecipient(t, ECDHES256KWA128KWAES256GCMKeyTemplate())
+
+		keyErr := decryptError(t, a128KWRecKH, ct).Keys[0]
+		require.Len(t, keyErr.Recipients, 2)
+
+		for _, rec := range keyErr.Recipients {
+			require.Equal(t, composite.RecipientAlgMismatch, rec.Reason)
+			require.NoError(t, rec.Err)
+		}
+	})
+
+	t.Run("corrupted ciphertext", func(t *testing.T) {
+		encData := &composite.EncryptedData{}
+		require.NoError(t, json.Unmarshal(ct, encData))
+
+		encData.Tag[0] ^= 0xff
+
+		corruptedCT, err := json.Marshal(encData)
+		require.NoError(t, err)
+
+		keyErr := decryptError(t, p256RecKH, corruptedCT).Keys[0]
+		require.Len(t, keyErr.Recipients, 1)
+		require.Equal(t, "p256-kid", keyErr.Recipients[0].KID)
+		require.Equal(t, composite.RecipientTagInvalid, keyErr.Recipients[0].Reason)
+	})
+
+	t.Run("corrupted wrapped CEK", func(t *testing.T) {
+		encData := &composite.EncryptedData{}
+		require.NoError(t, json.Unmarshal(ct, encData))
+
+		encData.Recipients[0].EncryptedCEK[0] ^= 0xff
+
+		corruptedCT, err := json.Marshal(encData)
+		require.NoError(t, err)
+
+		keyErr := decryptError(t, p256RecKH, corruptedCT).Keys[0]
+		require.Equal(t, composite.RecipientUnwrapFailed, keyErr.Recipients[0].Reason)
+	})
+
+	t.Run("malformed ciphertext", func(t *testing.T) {
+		keyErr := decryptError(t, p256RecKH, []byte("not JSON")).Keys[0]
+		require.Empty(t, keyErr.Recipients)
+		require.Error(t, keyErr.Unwrap())
+	})
+}
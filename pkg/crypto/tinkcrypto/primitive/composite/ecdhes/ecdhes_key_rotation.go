@@ -0,0 +1,80 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ecdhes
+
+import (
+	"fmt"
+
+	"github.com/google/tink/go/keyset"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+)
+
+// RotatedKeysetHandle is the result of RotateKeysetHandle.
+type RotatedKeysetHandle struct {
+	// KeysetHandle is the new private keyset handle on the target curve.
+	KeysetHandle *keyset.Handle
+	// PublicKey is the primary public key of KeysetHandle, it is the key to publish (eg in a DID document update).
+	PublicKey *composite.PublicKey
+	// OldKID is the KID of the primary key of the rotated keyset handle, it is empty if this key had no KID.
+	OldKID string
+}
+
+// RotateKeysetHandle creates a new ECDH-ES private keyset handle on the given curve to replace the primary key of kh,
+// eg to move recipients keys from NIST P-256 to NIST P-384.
+//
+// This is a key rotation, not a conversion: key material cannot be converted from a curve to another. A fresh key is
+// generated on the target curve, the old key is left unchanged and must be kept to decrypt messages sent to it until
+// the new public key is published. Only the metadata of the old key is carried to the new key: the key wrapping key
+// size, the content encryption key template and AAD prefix, the EC point format and the Concat KDF AlgorithmID and
+// party info. The recipients keys of a sender keyset handle are not carried.
+//
+// kh can be either a private or a public ECDH-ES keyset handle. The KID of the old key is returned so that callers can
+// map it to the new key.
+func RotateKeysetHandle(kh *keyset.Handle, curve commonpb.EllipticCurveType) (*RotatedKeysetHandle, error) {
+	oldPubKey, err := primaryPublicKey(kh)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes: RotateKeysetHandle: %w", err)
+	}
+
+	kwParams := oldPubKey.Params.KwParams
+
+	kwKeySize := kwParams.KwKeySize
+	if kwKeySize == 0 {
+		// keys created before the key wrapping key size was stored in their KW params use A256KW.
+		kwKeySize = a256KWKeySize
+	}
+
+	kt, err := NewECDHESKeyTemplate(
+		WithCurve(curve),
+		WithKWKeySize(kwKeySize),
+		WithContentEncryption(oldPubKey.Params.EncParams.AeadEnc),
+		WithPointFormat(oldPubKey.Params.EcPointFormat),
+		WithAlgorithmID(kwParams.AlgId),
+		WithPartyInfo(kwParams.Apu, kwParams.Apv),
+		WithAADPrefix(oldPubKey.Params.EncParams.AadPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes: RotateKeysetHandle: %w", err)
+	}
+
+	newKH, err := keyset.NewHandle(kt)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes: RotateKeysetHandle: failed to create new keyset handle: %w", err)
+	}
+
+	newPubKey, err := PublicKeyFromKeysetHandle(newKH)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes: RotateKeysetHandle: %w", err)
+	}
+
+	return &RotatedKeysetHandle{
+		KeysetHandle: newKH,
+		PublicKey:    newPubKey,
+		OldKID:       oldPubKey.KID,
+	}, nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ecdhes
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/keyset"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"github.com/google/tink/go/testkeyset"
+	"github.com/google/tink/go/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+	compositepb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
+)
+
+func TestRotateKeysetHandle(t *testing.T) {
+	t.Run("rotate a P-256 key to P-384 with its metadata", func(t *testing.T) {
+		oldKH, err := keyset.NewHandle(newECDHESKeyTemplate(t, WithKWKeySize(a128KWKeySize),
+			WithContentEncryption(aead.AES128GCMKeyTemplate()), WithCompressedPoints(),
+			WithAlgorithmID("custom-alg-id"), WithPartyInfo([]byte("apu"), []byte("apv"))))
+		require.NoError(t, err)
+
+		rotated, err := RotateKeysetHandle(oldKH, commonpb.EllipticCurveType_NIST_P384)
+		require.NoError(t, err)
+		require.Empty(t, rotated.OldKID)
+		require.Equal(t, commonpb.EllipticCurveType_NIST_P384.String(), rotated.PublicKey.Curve)
+		require.Equal(t, compositepb.KeyType_EC.String(), rotated.PublicKey.Type)
+
+		oldPubKey, err := primaryPublicKey(oldKH)
+		require.NoError(t, err)
+
+		newPubKey, err := primaryPublicKey(rotated.KeysetHandle)
+		require.NoError(t, err)
+
+		// a fresh key is generated, only the curve of the params changes
+		require.NotEqual(t, oldPubKey.X, newPubKey.X)
+
+		oldPubKey.Params.KwParams.CurveType = commonpb.EllipticCurveType_NIST_P384
+		require.True(t, proto.Equal(oldPubKey.Params, newPubKey.Params))
+
+		// the new key can decrypt messages sent to its public key
+		encryptDecrypt(t, rotated)
+	})
+
+	t.Run("rotate a key with a KID to X25519", func(t *testing.T) {
+		privKey := generateECDHESAEADPrivateKey(t, commonpb.EllipticCurveType_NIST_P256,
+			commonpb.EcPointFormat_UNCOMPRESSED, aead.AES256GCMKeyTemplate())
+		privKey.PublicKey.KID = "old-kid"
+
+		sPrivKey, err := proto.Marshal(privKey)
+		require.NoError(t, err)
+
+		key := testutil.NewKey(
+			testutil.NewKeyData(ecdhesAESPrivateKeyTypeURL, sPrivKey, tinkpb.KeyData_ASYMMETRIC_PRIVATE),
+			tinkpb.KeyStatusType_ENABLED, 8, tinkpb.OutputPrefixType_RAW)
+
+		oldKH, err := testkeyset.NewHandle(testutil.NewKeyset(key.KeyId, []*tinkpb.Keyset_Key{key}))
+		require.NoError(t, err)
+
+		oldPubKH, err := oldKH.Public()
+		require.NoError(t, err)
+
+		// a public keyset handle is enough to rotate a key
+		rotated, err := RotateKeysetHandle(oldPubKH, commonpb.EllipticCurveType_CURVE25519)
+		require.NoError(t, err)
+		require.Equal(t, "old-kid", rotated.OldKID)
+		require.Empty(t, rotated.PublicKey.KID)
+		require.Equal(t, commonpb.EllipticCurveType_CURVE25519.String(), rotated.PublicKey.Curve)
+		require.Equal(t, compositepb.KeyType_OKP.String(), rotated.PublicKey.Type)
+
+		newPubKey, err := primaryPublicKey(rotated.KeysetHandle)
+		require.NoError(t, err)
+		// the old key has no key wrapping key size, it uses A256KW. Its recipients are not carried.
+		require.EqualValues(t, a256KWKeySize, newPubKey.Params.KwParams.KwKeySize)
+		require.Empty(t, newPubKey.Params.KwParams.Recipients)
+
+		encryptDecrypt(t, rotated)
+	})
+
+	t.Run("failure cases", func(t *testing.T) {
+		_, err := RotateKeysetHandle(nil, commonpb.EllipticCurveType_NIST_P384)
+		require.EqualError(t, err, "ecdhes: RotateKeysetHandle: keyset handle is nil")
+
+		kh, err := keyset.NewHandle(aead.AES256GCMKeyTemplate())
+		require.NoError(t, err)
+
+		_, err = RotateKeysetHandle(kh, commonpb.EllipticCurveType_NIST_P384)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ecdhes: RotateKeysetHandle: failed to read public keyset")
+
+		kh, err = keyset.NewHandle(ECDHES256KWAES256GCMKeyTemplate())
+		require.NoError(t, err)
+
+		_, err = RotateKeysetHandle(kh, commonpb.EllipticCurveType_UNKNOWN_CURVE)
+		require.EqualError(t, err, "ecdhes: RotateKeysetHandle: NewECDHESKeyTemplate: unsupported curve "+
+			"'UNKNOWN_CURVE'")
+	})
+}
+
+// encryptDecrypt encrypts a message for the public key of rotated and another recipient using the same params and
+// decrypts it with the rotated keyset handle.
+func encryptDecrypt(t *testing.T, rotated *RotatedKeysetHandle) {
+	t.Helper()
+
+	newPubKey, err := primaryPublicKey(rotated.KeysetHandle)
+	require.NoError(t, err)
+
+	kwParams := newPubKey.Params.KwParams
+	opts := []Option{
+		WithCurve(kwParams.CurveType),
+		WithKWKeySize(kwParams.KwKeySize),
+		WithContentEncryption(newPubKey.Params.EncParams.AeadEnc),
+		WithAlgorithmID(kwParams.AlgId),
+		WithPartyInfo(kwParams.Apu, kwParams.Apv),
+	}
+
+	otherRecPubKey, _ := createRecipient(t, newECDHESKeyTemplate(t, opts...))
+
+	recKeys, err := createECDHESPublicKeys([]*composite.PublicKey{rotated.PublicKey, otherRecPubKey})
+	require.NoError(t, err)
+
+	senderKH, err := keyset.NewHandle(newECDHESKeyTemplate(t, append(opts, WithRecipients(recKeys))...))
+	require.NoError(t, err)
+
+	senderPubKH, err := senderKH.Public()
+	require.NoError(t, err)
+
+	pt := []byte("secret message")
+	aad := []byte("aad message")
+
+	ct, err := Encrypt(senderPubKH, pt, aad)
+	require.NoError(t, err)
+
+	dpt, err := Decrypt(rotated.KeysetHandle, ct, aad)
+	require.NoError(t, err)
+	require.Equal(t, pt, dpt)
+}
@@ -7,24 +7,40 @@ SPDX-License-Identifier: Apache-2.0
 package ecdhes
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/golang/protobuf/proto"
 	"github.com/google/tink/go/aead"
 	commonpb "github.com/google/tink/go/proto/common_go_proto"
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"golang.org/x/crypto/curve25519"
 
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
 	compositepb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
 	ecdhespb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/ecdhes_aead_go_proto"
 )
 
+// errNilRecipientKey is returned when creating a key template with a nil recipient key
+var errNilRecipientKey = errors.New("recipient public key is nil")
+
+// AES key wrapping key sizes in bytes
+const (
+	a128KWKeySize = 16
+	a192KWKeySize = 24
+	a256KWKeySize = 32
+)
+
 // ECDHES256KWAES256GCMKeyTemplate is a KeyTemplate that generates an ECDH-ES P-256 key wrapping and AES256-GCM CEK. It
 // is used to represent a recipient key to execute the CompositeDecrypt primitive with the following parameters:
 //  - Key Wrapping: ECDH-ES over A256KW as per https://tools.ietf.org/html/rfc7518#appendix-A.2
 //  - Content Encryption: AES256-GCM
 //  - KDF: Concat KDF as per https://tools.ietf.org/html/rfc7518#section-4.6
 // Keys from this template represent a valid recipient public/private key pairs and can be stored in the KMS
+//
+// Deprecated: use NewECDHESKeyTemplate, it returns an error instead of panicking.
 func ECDHES256KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
-	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P256, nil)
+	return mustCreateKeyTemplate(commonpb.EllipticCurveType_NIST_P256, a256KWKeySize, aead.AES256GCMKeyTemplate())
 }
 
 // ECDHES384KWAES256GCMKeyTemplate is a KeyTemplate that generates an ECDH-ES P-384 key wrapping and AES256-GCM CEK. It
@@ -33,8 +49,10 @@ func ECDHES256KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
 //  - Content Encryption: AES256-GCM
 //  - KDF: Concat KDF as per https://tools.ietf.org/html/rfc7518#section-4.6
 // Keys from this template represent a valid recipient public/private key pairs and can be stored in the KMS
+//
+// Deprecated: use NewECDHESKeyTemplate, it returns an error instead of panicking.
 func ECDHES384KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
-	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P384, nil)
+	return mustCreateKeyTemplate(commonpb.EllipticCurveType_NIST_P384, a256KWKeySize, aead.AES256GCMKeyTemplate())
 }
 
 // ECDHES521KWAES256GCMKeyTemplate is a KeyTemplate that generates an ECDH-ES P-521 key wrapping and AES256-GCM CEK. It
@@ -43,8 +61,10 @@ func ECDHES384KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
 //  - Content Encryption: AES256-GCM
 //  - KDF: Concat KDF as per https://tools.ietf.org/html/rfc7518#section-4.6
 // Keys from this template represent a valid recipient public/private key pairs and can be stored in the KMS
+//
+// Deprecated: use NewECDHESKeyTemplate, it returns an error instead of panicking.
 func ECDHES521KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
-	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P521, nil)
+	return mustCreateKeyTemplate(commonpb.EllipticCurveType_NIST_P521, a256KWKeySize, aead.AES256GCMKeyTemplate())
 }
 
 // ECDHES256KWAES256GCMKeyTemplateWithRecipients is similar to ECDHES256KWAES256GCMKeyTemplate but adding recipients
@@ -56,7 +76,14 @@ func ECDHES256KWAES256GCMKeyTemplateWithRecipients(recPublicKeys []*composite.Pu
 		return nil, err
 	}
 
-	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P256, ecdhesRecipientKeys), nil
+	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P256, a256KWKeySize, aead.AES256GCMKeyTemplate(),
+		ecdhesRecipientKeys)
+}
+
+// ECDHES256KWAES256GCMKeyTemplateForRecipient is similar to ECDHES256KWAES256GCMKeyTemplateWithRecipients
+// for encrypting a message targeted to the single recipient recPublicKey.
+func ECDHES256KWAES256GCMKeyTemplateForRecipient(recPublicKey *composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	return keyTemplateForRecipient(ECDHES256KWAES256GCMKeyTemplateWithRecipients, recPublicKey)
 }
 
 // ECDHES384KWAES256GCMKeyTemplateWithRecipients is similar to ECDHES384KWAES256GCMKeyTemplate but adding recipients
@@ -68,7 +95,14 @@ func ECDHES384KWAES256GCMKeyTemplateWithRecipients(recPublicKeys []*composite.Pu
 		return nil, err
 	}
 
-	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P384, ecdhesRecipientKeys), nil
+	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P384, a256KWKeySize, aead.AES256GCMKeyTemplate(),
+		ecdhesRecipientKeys)
+}
+
+// ECDHES384KWAES256GCMKeyTemplateForRecipient is similar to ECDHES384KWAES256GCMKeyTemplateWithRecipients
+// for encrypting a message targeted to the single recipient recPublicKey.
+func ECDHES384KWAES256GCMKeyTemplateForRecipient(recPublicKey *composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	return keyTemplateForRecipient(ECDHES384KWAES256GCMKeyTemplateWithRecipients, recPublicKey)
 }
 
 // ECDHES521KWAES256GCMKeyTemplateWithRecipients is similar to ECDHES521KWAES256GCMKeyTemplate but adding recipients
@@ -80,21 +114,307 @@ func ECDHES521KWAES256GCMKeyTemplateWithRecipients(recPublicKeys []*composite.Pu
 		return nil, err
 	}
 
-	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P521, ecdhesRecipientKeys), nil
+	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P521, a256KWKeySize, aead.AES256GCMKeyTemplate(),
+		ecdhesRecipientKeys)
+}
+
+// ECDHES521KWAES256GCMKeyTemplateForRecipient is similar to ECDHES521KWAES256GCMKeyTemplateWithRecipients
+// for encrypting a message targeted to the single recipient recPublicKey.
+func ECDHES521KWAES256GCMKeyTemplateForRecipient(recPublicKey *composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	return keyTemplateForRecipient(ECDHES521KWAES256GCMKeyTemplateWithRecipients, recPublicKey)
+}
+
+// ECDHES256KWAES128GCMKeyTemplate is a KeyTemplate that generates an ECDH-ES P-256 key wrapping and AES128-GCM CEK. It
+// is used to represent a recipient key to execute the CompositeDecrypt primitive with the following parameters:
+//  - Key Wrapping: ECDH-ES over A256KW as per https://tools.ietf.org/html/rfc7518#appendix-A.2
+//  - Content Encryption: AES128-GCM
+//  - KDF: Concat KDF as per https://tools.ietf.org/html/rfc7518#section-4.6
+// Keys from this template represent a valid recipient public/private key pairs and can be stored in the KMS
+//
+// Deprecated: use NewECDHESKeyTemplate, it returns an error instead of panicking.
+func ECDHES256KWAES128GCMKeyTemplate() *tinkpb.KeyTemplate {
+	return mustCreateKeyTemplate(commonpb.EllipticCurveType_NIST_P256, a256KWKeySize, aead.AES128GCMKeyTemplate())
+}
+
+// ECDHES384KWAES128GCMKeyTemplate is a KeyTemplate that generates an ECDH-ES P-384 key wrapping and AES128-GCM CEK. It
+// is used to represent a recipient key to execute the CompositeDecrypt primitive with the following parameters:
+//  - Key Wrapping: ECDH-ES over A384KW as per https://tools.ietf.org/html/rfc7518#appendix-A.2
+//  - Content Encryption: AES128-GCM
+//  - KDF: Concat KDF as per https://tools.ietf.org/html/rfc7518#section-4.6
+// Keys from this template represent a valid recipient public/private key pairs and can be stored in the KMS
+//
+// Deprecated: use NewECDHESKeyTemplate, it returns an error instead of panicking.
+func ECDHES384KWAES128GCMKeyTemplate() *tinkpb.KeyTemplate {
+	return mustCreateKeyTemplate(commonpb.EllipticCurveType_NIST_P384, a256KWKeySize, aead.AES128GCMKeyTemplate())
+}
+
+// ECDHES521KWAES128GCMKeyTemplate is a KeyTemplate that generates an ECDH-ES P-521 key wrapping and AES128-GCM CEK. It
+// is used to represent a recipient key to execute the CompositeDecrypt primitive with the following parameters:
+//  - Key Wrapping: ECDH-ES over A521KW as per https://tools.ietf.org/html/rfc7518#appendix-A.2
+//  - Content Encryption: AES128-GCM
+//  - KDF: Concat KDF as per https://tools.ietf.org/html/rfc7518#section-4.6
+// Keys from this template represent a valid recipient public/private key pairs and can be stored in the KMS
+//
+// Deprecated: use NewECDHESKeyTemplate, it returns an error instead of panicking.
+func ECDHES521KWAES128GCMKeyTemplate() *tinkpb.KeyTemplate {
+	return mustCreateKeyTemplate(commonpb.EllipticCurveType_NIST_P521, a256KWKeySize, aead.AES128GCMKeyTemplate())
+}
+
+// ECDHES256KWAES128GCMKeyTemplateWithRecipients is similar to ECDHES256KWAES128GCMKeyTemplate but adding recipients
+// keys to execute the CompositeEncrypt primitive for encrypting a message targeted to one ore more recipients.
+// Keys from this template offer valid CompositeEncrypt primitive execution only and should not be stored in the KMS
+func ECDHES256KWAES128GCMKeyTemplateWithRecipients(recPublicKeys []*composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	ecdhesRecipientKeys, err := createECDHESPublicKeys(recPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P256, a256KWKeySize, aead.AES128GCMKeyTemplate(),
+		ecdhesRecipientKeys)
+}
+
+// ECDHES256KWAES128GCMKeyTemplateForRecipient is similar to ECDHES256KWAES128GCMKeyTemplateWithRecipients
+// for encrypting a message targeted to the single recipient recPublicKey.
+func ECDHES256KWAES128GCMKeyTemplateForRecipient(recPublicKey *composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	return keyTemplateForRecipient(ECDHES256KWAES128GCMKeyTemplateWithRecipients, recPublicKey)
+}
+
+// ECDHES384KWAES128GCMKeyTemplateWithRecipients is similar to ECDHES384KWAES128GCMKeyTemplate but adding recipients
+// keys to execute the CompositeEncrypt primitive for encrypting a message targeted to one ore more recipients.
+// Keys from this template offer valid CompositeEncrypt primitive execution only and should not be stored in the KMS
+func ECDHES384KWAES128GCMKeyTemplateWithRecipients(recPublicKeys []*composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	ecdhesRecipientKeys, err := createECDHESPublicKeys(recPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P384, a256KWKeySize, aead.AES128GCMKeyTemplate(),
+		ecdhesRecipientKeys)
+}
+
+// ECDHES384KWAES128GCMKeyTemplateForRecipient is similar to ECDHES384KWAES128GCMKeyTemplateWithRecipients
+// for encrypting a message targeted to the single recipient recPublicKey.
+func ECDHES384KWAES128GCMKeyTemplateForRecipient(recPublicKey *composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	return keyTemplateForRecipient(ECDHES384KWAES128GCMKeyTemplateWithRecipients, recPublicKey)
+}
+
+// ECDHES521KWAES128GCMKeyTemplateWithRecipients is similar to ECDHES521KWAES128GCMKeyTemplate but adding recipients
+// keys to execute the CompositeEncrypt primitive for encrypting a message targeted to one ore more recipients.
+// Keys from this template offer valid CompositeEncrypt primitive execution only and should not be stored in the KMS
+func ECDHES521KWAES128GCMKeyTemplateWithRecipients(recPublicKeys []*composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	ecdhesRecipientKeys, err := createECDHESPublicKeys(recPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P521, a256KWKeySize, aead.AES128GCMKeyTemplate(),
+		ecdhesRecipientKeys)
+}
+
+// ECDHES521KWAES128GCMKeyTemplateForRecipient is similar to ECDHES521KWAES128GCMKeyTemplateWithRecipients
+// for encrypting a message targeted to the single recipient recPublicKey.
+func ECDHES521KWAES128GCMKeyTemplateForRecipient(recPublicKey *composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	return keyTemplateForRecipient(ECDHES521KWAES128GCMKeyTemplateWithRecipients, recPublicKey)
+}
+
+// ECDHES256KWChaChaKeyTemplate is a KeyTemplate that generates an ECDH-ES P-256 key wrapping and ChaCha20Poly1305
+// CEK. It is used to represent a recipient key to execute the CompositeDecrypt primitive with the following parameters:
+//  - Key Wrapping: ECDH-ES over A256KW as per https://tools.ietf.org/html/rfc7518#appendix-A.2
+//  - Content Encryption: ChaCha20Poly1305
+//  - KDF: Concat KDF as per https://tools.ietf.org/html/rfc7518#section-4.6
+// Keys from this template represent a valid recipient public/private key pairs and can be stored in the KMS
+//
+// Deprecated: use NewECDHESKeyTemplate, it returns an error instead of panicking.
+func ECDHES256KWChaChaKeyTemplate() *tinkpb.KeyTemplate {
+	return mustCreateKeyTemplate(commonpb.EllipticCurveType_NIST_P256, a256KWKeySize, aead.ChaCha20Poly1305KeyTemplate())
+}
+
+// ECDHES256KWXChaChaKeyTemplate is a KeyTemplate that generates an ECDH-ES P-256 key wrapping and XChaCha20Poly1305
+// CEK. It is used to represent a recipient key to execute the CompositeDecrypt primitive with the following parameters:
+//  - Key Wrapping: ECDH-ES over A256KW as per https://tools.ietf.org/html/rfc7518#appendix-A.2
+//  - Content Encryption: XChaCha20Poly1305
+//  - KDF: Concat KDF as per https://tools.ietf.org/html/rfc7518#section-4.6
+// Keys from this template represent a valid recipient public/private key pairs and can be stored in the KMS
+//
+// Deprecated: use NewECDHESKeyTemplate, it returns an error instead of panicking.
+func ECDHES256KWXChaChaKeyTemplate() *tinkpb.KeyTemplate {
+	return mustCreateKeyTemplate(commonpb.EllipticCurveType_NIST_P256, a256KWKeySize, aead.XChaCha20Poly1305KeyTemplate())
+}
+
+// ECDHES256KWChaChaKeyTemplateWithRecipients is similar to ECDHES256KWChaChaKeyTemplate but adding recipients
+// keys to execute the CompositeEncrypt primitive for encrypting a message targeted to one ore more recipients.
+// Keys from this template offer valid CompositeEncrypt primitive execution only and should not be stored in the KMS
+func ECDHES256KWChaChaKeyTemplateWithRecipients(recPublicKeys []*composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	ecdhesRecipientKeys, err := createECDHESPublicKeys(recPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P256, a256KWKeySize, aead.ChaCha20Poly1305KeyTemplate(),
+		ecdhesRecipientKeys)
+}
+
+// ECDHES256KWChaChaKeyTemplateForRecipient is similar to ECDHES256KWChaChaKeyTemplateWithRecipients
+// for encrypting a message targeted to the single recipient recPublicKey.
+func ECDHES256KWChaChaKeyTemplateForRecipient(recPublicKey *composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	return keyTemplateForRecipient(ECDHES256KWChaChaKeyTemplateWithRecipients, recPublicKey)
+}
+
+// ECDHES256KWXChaChaKeyTemplateWithRecipients is similar to ECDHES256KWXChaChaKeyTemplate but adding recipients
+// keys to execute the CompositeEncrypt primitive for encrypting a message targeted to one ore more recipients.
+// Keys from this template offer valid CompositeEncrypt primitive execution only and should not be stored in the KMS
+func ECDHES256KWXChaChaKeyTemplateWithRecipients(recPublicKeys []*composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	ecdhesRecipientKeys, err := createECDHESPublicKeys(recPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P256, a256KWKeySize,
+		aead.XChaCha20Poly1305KeyTemplate(), ecdhesRecipientKeys)
+}
+
+// ECDHES256KWXChaChaKeyTemplateForRecipient is similar to ECDHES256KWXChaChaKeyTemplateWithRecipients
+// for encrypting a message targeted to the single recipient recPublicKey.
+func ECDHES256KWXChaChaKeyTemplateForRecipient(recPublicKey *composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	return keyTemplateForRecipient(ECDHES256KWXChaChaKeyTemplateWithRecipients, recPublicKey)
+}
+
+// ECDHES256KWA128KWAES256GCMKeyTemplate is a KeyTemplate that generates an ECDH-ES P-256 key wrapping with a 128 bits
+// AES key wrap and AES256-GCM CEK. It is used to represent a recipient key to execute the CompositeDecrypt primitive
+// with the following parameters:
+//  - Key Wrapping: ECDH-ES over A128KW as per https://tools.ietf.org/html/rfc7518#section-4.6
+//  - Content Encryption: AES256-GCM
+//  - KDF: Concat KDF as per https://tools.ietf.org/html/rfc7518#section-4.6
+// Keys from this template represent a valid recipient public/private key pairs and can be stored in the KMS
+//
+// Deprecated: use NewECDHESKeyTemplate, it returns an error instead of panicking.
+func ECDHES256KWA128KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
+	return mustCreateKeyTemplate(commonpb.EllipticCurveType_NIST_P256, a128KWKeySize, aead.AES256GCMKeyTemplate())
+}
+
+// ECDHES256KWA192KWAES256GCMKeyTemplate is a KeyTemplate that generates an ECDH-ES P-256 key wrapping with a 192 bits
+// AES key wrap and AES256-GCM CEK. It is used to represent a recipient key to execute the CompositeDecrypt primitive
+// with the following parameters:
+//  - Key Wrapping: ECDH-ES over A192KW as per https://tools.ietf.org/html/rfc7518#section-4.6
+//  - Content Encryption: AES256-GCM
+//  - KDF: Concat KDF as per https://tools.ietf.org/html/rfc7518#section-4.6
+// Keys from this template represent a valid recipient public/private key pairs and can be stored in the KMS
+//
+// Deprecated: use NewECDHESKeyTemplate, it returns an error instead of panicking.
+func ECDHES256KWA192KWAES256GCMKeyTemplate() *tinkpb.KeyTemplate {
+	return mustCreateKeyTemplate(commonpb.EllipticCurveType_NIST_P256, a192KWKeySize, aead.AES256GCMKeyTemplate())
+}
+
+// ECDHES256KWA128KWAES256GCMKeyTemplateWithRecipients is similar to ECDHES256KWA128KWAES256GCMKeyTemplate but adding
+// recipients keys to execute the CompositeEncrypt primitive for encrypting a message targeted to one ore more
+// recipients.
+// Keys from this template offer valid CompositeEncrypt primitive execution only and should not be stored in the KMS
+func ECDHES256KWA128KWAES256GCMKeyTemplateWithRecipients(
+	recPublicKeys []*composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	ecdhesRecipientKeys, err := createECDHESPublicKeys(recPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P256, a128KWKeySize, aead.AES256GCMKeyTemplate(),
+		ecdhesRecipientKeys)
+}
+
+// ECDHES256KWA128KWAES256GCMKeyTemplateForRecipient is similar to ECDHES256KWA128KWAES256GCMKeyTemplateWithRecipients
+// for encrypting a message targeted to the single recipient recPublicKey.
+func ECDHES256KWA128KWAES256GCMKeyTemplateForRecipient(recPublicKey *composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	return keyTemplateForRecipient(ECDHES256KWA128KWAES256GCMKeyTemplateWithRecipients, recPublicKey)
+}
+
+// ECDHES256KWA192KWAES256GCMKeyTemplateWithRecipients is similar to ECDHES256KWA192KWAES256GCMKeyTemplate but adding
+// recipients keys to execute the CompositeEncrypt primitive for encrypting a message targeted to one ore more
+// recipients.
+// Keys from this template offer valid CompositeEncrypt primitive execution only and should not be stored in the KMS
+func ECDHES256KWA192KWAES256GCMKeyTemplateWithRecipients(
+	recPublicKeys []*composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	ecdhesRecipientKeys, err := createECDHESPublicKeys(recPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return createKeyTemplate(commonpb.EllipticCurveType_NIST_P256, a192KWKeySize, aead.AES256GCMKeyTemplate(),
+		ecdhesRecipientKeys)
+}
+
+// ECDHES256KWA192KWAES256GCMKeyTemplateForRecipient is similar to ECDHES256KWA192KWAES256GCMKeyTemplateWithRecipients
+// for encrypting a message targeted to the single recipient recPublicKey.
+func ECDHES256KWA192KWAES256GCMKeyTemplateForRecipient(recPublicKey *composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	return keyTemplateForRecipient(ECDHES256KWA192KWAES256GCMKeyTemplateWithRecipients, recPublicKey)
+}
+
+// ECDHESX25519KWXChaChaKeyTemplate is a KeyTemplate that generates an ECDH-ES X25519 key wrapping and
+// XChaCha20Poly1305 CEK. It is used to represent a recipient key to execute the CompositeDecrypt primitive with the
+// following parameters:
+//  - Key Wrapping: ECDH-ES over A256KW with X25519 key agreement as per https://tools.ietf.org/html/rfc8037#section-3.2
+//  - Content Encryption: XChaCha20Poly1305
+//  - KDF: Concat KDF as per https://tools.ietf.org/html/rfc7518#section-4.6
+// Keys from this template represent a valid recipient public/private key pairs and can be stored in the KMS
+//
+// Deprecated: use NewECDHESKeyTemplate, it returns an error instead of panicking.
+func ECDHESX25519KWXChaChaKeyTemplate() *tinkpb.KeyTemplate {
+	return mustCreateKeyTemplate(commonpb.EllipticCurveType_CURVE25519, a256KWKeySize, aead.XChaCha20Poly1305KeyTemplate())
+}
+
+// ECDHESX25519KWXChaChaKeyTemplateWithRecipients is similar to ECDHESX25519KWXChaChaKeyTemplate but adding recipients
+// keys to execute the CompositeEncrypt primitive for encrypting a message targeted to one ore more recipients.
+// Keys from this template offer valid CompositeEncrypt primitive execution only and should not be stored in the KMS
+func ECDHESX25519KWXChaChaKeyTemplateWithRecipients(recPublicKeys []*composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	ecdhesRecipientKeys, err := createECDHESPublicKeys(recPublicKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return createKeyTemplate(commonpb.EllipticCurveType_CURVE25519, a256KWKeySize,
+		aead.XChaCha20Poly1305KeyTemplate(), ecdhesRecipientKeys)
+}
+
+// ECDHESX25519KWXChaChaKeyTemplateForRecipient is similar to ECDHESX25519KWXChaChaKeyTemplateWithRecipients
+// for encrypting a message targeted to the single recipient recPublicKey.
+func ECDHESX25519KWXChaChaKeyTemplateForRecipient(recPublicKey *composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	return keyTemplateForRecipient(ECDHESX25519KWXChaChaKeyTemplateWithRecipients, recPublicKey)
+}
+
+// keyTemplateForRecipient creates the key template of the ...WithRecipients function withRecipients for the single
+// recipient recPublicKey.
+func keyTemplateForRecipient(withRecipients func([]*composite.PublicKey) (*tinkpb.KeyTemplate, error),
+	recPublicKey *composite.PublicKey) (*tinkpb.KeyTemplate, error) {
+	if recPublicKey == nil {
+		return nil, errNilRecipientKey
+	}
+
+	return withRecipients([]*composite.PublicKey{recPublicKey})
+}
+
+// KeyTemplateWithRecipientDIDs creates the key template of the ...WithRecipients function withRecipients for the
+// recipients identified by dids. Their encryption keys are resolved with resolver, it fails if a DID resolves to zero
+// usable keys.
+// eg: KeyTemplateWithRecipientDIDs(ECDHESX25519KWXChaChaKeyTemplateWithRecipients, resolver, dids)
+func KeyTemplateWithRecipientDIDs(withRecipients func([]*composite.PublicKey) (*tinkpb.KeyTemplate, error),
+	resolver composite.RecipientResolver, dids []string) (*tinkpb.KeyTemplate, error) {
+	recPublicKeys, err := composite.ResolveRecipients(resolver, dids)
+	if err != nil {
+		return nil, err
+	}
+
+	return withRecipients(recPublicKeys)
 }
 
 func createECDHESPublicKeys(recRawPublicKeys []*composite.PublicKey) ([]*compositepb.ECPublicKey, error) {
 	var recKeys []*compositepb.ECPublicKey
 
 	for _, key := range recRawPublicKeys {
-		curveType, err := composite.GetCurveType(key.Curve)
-		if err != nil {
-			return nil, err
+		if key == nil {
+			return nil, errNilRecipientKey
 		}
 
-		keyType, err := composite.GetKeyType(key.Type)
+		curveType, keyType, err := validateRecipientKey(key)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("invalid recipient key with KID '%s': %w", key.KID, err)
 		}
 
 		rKey := &compositepb.ECPublicKey{
@@ -112,33 +432,283 @@ func createECDHESPublicKeys(recRawPublicKeys []*composite.PublicKey) ([]*composi
 	return recKeys, nil
 }
 
-// TODO add chacha key templates as well https://github.com/hyperledger/aries-framework-go/issues/1637
+// validateRecipientKey validates the curve, key type and point of the recipient key and returns its curve and key
+// types.
+func validateRecipientKey(key *composite.PublicKey) (commonpb.EllipticCurveType, compositepb.KeyType, error) {
+	curveType, err := composite.GetCurveType(key.Curve)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	keyType, err := composite.GetKeyType(key.Type)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(key.X) == 0 {
+		return 0, 0, errors.New("empty X")
+	}
+
+	if keyType == compositepb.KeyType_OKP {
+		err = validateOKPCurve(curveType)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if len(key.X) != curve25519.PointSize {
+			return 0, 0, fmt.Errorf("invalid X25519 public key size %d", len(key.X))
+		}
+
+		return curveType, keyType, nil
+	}
+
+	c, err := composite.GetCurve(curveType.String())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// an empty Y is only valid for a compressed X
+	x, y, err := composite.ECPoint(c, key)
+	if err != nil {
+		return 0, 0, fmt.Errorf("empty Y with an invalid compressed X: %w", err)
+	}
+
+	if !c.IsOnCurve(x, y) {
+		return 0, 0, fmt.Errorf("point is not on curve %s", c.Params().Name)
+	}
+
+	return curveType, keyType, nil
+}
+
+// createKeyTemplate creates a new ECDHES-AEAD key template with the given key wrapping curve, AES key wrapping key size
+// in bytes, content encryption AEAD key template and recipients keys.
+func createKeyTemplate(c commonpb.EllipticCurveType, kwKeySize uint32, encAEAD *tinkpb.KeyTemplate,
+	r []*compositepb.ECPublicKey) (*tinkpb.KeyTemplate, error) {
+	return NewECDHESKeyTemplate(WithCurve(c), WithKWKeySize(kwKeySize), WithContentEncryption(encAEAD),
+		WithRecipients(r))
+}
+
+// mustCreateKeyTemplate is similar to createKeyTemplate without recipients keys. It is used by the predefined key
+// templates whose parameters are constants, it panics if these parameters are invalid.
+func mustCreateKeyTemplate(c commonpb.EllipticCurveType, kwKeySize uint32,
+	encAEAD *tinkpb.KeyTemplate) *tinkpb.KeyTemplate {
+	kt, err := createKeyTemplate(c, kwKeySize, encAEAD, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	return kt
+}
+
+// Option configures the ECDH-ES key template created by NewECDHESKeyTemplate.
+type Option func(opts *keyTemplateOpts)
+
+type keyTemplateOpts struct {
+	curve       commonpb.EllipticCurveType
+	kwKeySize   uint32
+	encAEAD     *tinkpb.KeyTemplate
+	pointFormat commonpb.EcPointFormat
+	recipients  []*compositepb.ECPublicKey
+	algID       string
+	apu         []byte
+	apv         []byte
+	aadPrefix   []byte
+	prefixType  tinkpb.OutputPrefixType
+}
+
+// WithCurve option sets the key wrapping curve of the key template. Default is NIST P-256.
+func WithCurve(curve commonpb.EllipticCurveType) Option {
+	return func(opts *keyTemplateOpts) {
+		opts.curve = curve
+	}
+}
+
+// WithKWKeySize option sets the AES key wrapping key size in bytes (16, 24 or 32). Default is 32 (A256KW).
+func WithKWKeySize(size uint32) Option {
+	return func(opts *keyTemplateOpts) {
+		opts.kwKeySize = size
+	}
+}
+
+// WithContentEncryption option sets the content encryption AEAD key template. Default is AES256-GCM. AES-CBC-HMAC-SHA2
+// templates are available in the tinkcrypto/primitive/aead package (eg aead.AES256CBCHMACSHA512KeyTemplate()).
+func WithContentEncryption(aeadTemplate *tinkpb.KeyTemplate) Option {
+	return func(opts *keyTemplateOpts) {
+		opts.encAEAD = aeadTemplate
+	}
+}
+
+// WithPointFormat option sets the EC point format of the key template. Default is uncompressed.
+func WithPointFormat(format commonpb.EcPointFormat) Option {
+	return func(opts *keyTemplateOpts) {
+		opts.pointFormat = format
+	}
+}
+
+// WithCompressedPoints option sets the compressed EC point format in the key template. Primitives of keys created from
+// this template set EPKs with compressed points (ie 'x' only, 'y' is implied) to reduce the recipients headers size.
+func WithCompressedPoints() Option {
+	return WithPointFormat(commonpb.EcPointFormat_COMPRESSED)
+}
+
+// WithRecipients option sets the recipients keys of the key template. Keys from a template with recipients offer
+// valid CompositeEncrypt primitive execution only and should not be stored in the KMS. Recipients keys may be on
+// curves other than the curve of the template since each recipient key is wrapped with an ephemeral key on its own
+// curve. NewECDHESKeyTemplate returns an error if a recipient key is invalid, ie its point is not on its curve.
+func WithRecipients(recipients []*compositepb.ECPublicKey) Option {
+	return func(opts *keyTemplateOpts) {
+		opts.recipients = recipients
+	}
+}
+
+// WithAlgorithmID option sets the AlgorithmID of the Concat KDF OtherInfo as per
+// https://tools.ietf.org/html/rfc7518#section-4.6.2. Default is the key wrapping algorithm (eg ECDH-ES+A256KW). The
+// sender and the recipients keys must be created with the same AlgorithmID.
+func WithAlgorithmID(algID string) Option {
+	return func(opts *keyTemplateOpts) {
+		opts.algID = algID
+	}
+}
+
+// WithPartyInfo option sets the PartyUInfo (apu) and PartyVInfo (apv) of the Concat KDF OtherInfo as per
+// https://tools.ietf.org/html/rfc7518#section-4.6.2. Default is empty party info. The sender and the recipients keys
+// must be created with the same party info.
+func WithPartyInfo(apu, apv []byte) Option {
+	return func(opts *keyTemplateOpts) {
+		opts.apu = apu
+		opts.apv = apv
+	}
+}
+
+// WithAADPrefix option sets a label prepended to the AAD of the content encryption and decryption of the keys created
+// from the template, ie "didcomm-anon", so that ciphertexts can't be replayed across protocols: a ciphertext encrypted
+// with a prefix fails to decrypt with another one. The label is prepended with its length, so a prefix can't be
+// extended by the start of the AAD. The prefix isn't part of the AAD passed to nor returned by the
+// primitives. The sender and the recipients keys must be created with the same prefix. Default is no prefix.
+func WithAADPrefix(prefix []byte) Option {
+	return func(opts *keyTemplateOpts) {
+		opts.aadPrefix = prefix
+	}
+}
+
+// WithOutputPrefixType option sets the output prefix type of the keys created from the template. Default is RAW which
+// is required to build JWE messages from the ciphertexts. Other types (ie TINK) prepend the key ID of the primary key
+// to the ciphertexts for Tink-only use, decryption strips this prefix. Streams are never prefixed.
+func WithOutputPrefixType(prefixType tinkpb.OutputPrefixType) Option {
+	return func(opts *keyTemplateOpts) {
+		opts.prefixType = prefixType
+	}
+}
+
+// NewECDHESKeyTemplate creates a new ECDHES-AEAD key template configured with the given options. Without options, it
+// creates the same key template as ECDHES256KWAES256GCMKeyTemplate. Curve25519 templates are set with the OKP key type.
+// It returns an error if the options are not compatible, ie an unsupported curve, an AES key wrapping key size
+// other than 16, 24 or 32 bytes (the size of the key derived by the Concat KDF must be the size of the key wrapping
+// key) or an unsupported content encryption key template.
+func NewECDHESKeyTemplate(opts ...Option) (*tinkpb.KeyTemplate, error) {
+	tmplOpts := &keyTemplateOpts{
+		curve:       commonpb.EllipticCurveType_NIST_P256,
+		kwKeySize:   a256KWKeySize,
+		encAEAD:     aead.AES256GCMKeyTemplate(),
+		pointFormat: commonpb.EcPointFormat_UNCOMPRESSED,
+		prefixType:  tinkpb.OutputPrefixType_RAW,
+	}
+
+	for _, opt := range opts {
+		opt(tmplOpts)
+	}
+
+	err := validateKeyTemplateOpts(tmplOpts)
+	if err != nil {
+		return nil, fmt.Errorf("NewECDHESKeyTemplate: %w", err)
+	}
+
+	keyType := compositepb.KeyType_EC
+	if tmplOpts.curve == commonpb.EllipticCurveType_CURVE25519 {
+		keyType = compositepb.KeyType_OKP
+	}
 
-// createKeyTemplate creates a new ECDHES-AEAD key template with the given key
-// size in bytes.
-func createKeyTemplate(c commonpb.EllipticCurveType, r []*compositepb.ECPublicKey) *tinkpb.KeyTemplate {
 	format := &ecdhespb.EcdhesAeadKeyFormat{
 		Params: &ecdhespb.EcdhesAeadParams{
 			KwParams: &ecdhespb.EcdhesKwParams{
-				CurveType:  c,
-				KeyType:    compositepb.KeyType_EC,
-				Recipients: r,
+				CurveType:  tmplOpts.curve,
+				KeyType:    keyType,
+				Recipients: tmplOpts.recipients,
+				KwKeySize:  tmplOpts.kwKeySize,
+				AlgId:      tmplOpts.algID,
+				Apu:        tmplOpts.apu,
+				Apv:        tmplOpts.apv,
 			},
 			EncParams: &ecdhespb.EcdhesAeadEncParams{
-				AeadEnc: aead.AES256GCMKeyTemplate(),
+				AeadEnc:   tmplOpts.encAEAD,
+				AadPrefix: tmplOpts.aadPrefix,
 			},
-			EcPointFormat: commonpb.EcPointFormat_UNCOMPRESSED,
+			EcPointFormat: tmplOpts.pointFormat,
 		},
 	}
 
 	serializedFormat, err := proto.Marshal(format)
 	if err != nil {
-		panic("failed to marshal EcdhesAeadKeyFormat proto")
+		return nil, fmt.Errorf("NewECDHESKeyTemplate: failed to marshal EcdhesAeadKeyFormat proto: %w", err)
 	}
 
 	return &tinkpb.KeyTemplate{
 		TypeUrl:          ecdhesAESPrivateKeyTypeURL,
 		Value:            serializedFormat,
-		OutputPrefixType: tinkpb.OutputPrefixType_RAW,
+		OutputPrefixType: tmplOpts.prefixType,
+	}, nil
+}
+
+// validateKeyTemplateOpts validates the curve, the key wrapping key size and the content encryption key template of
+// opts are supported and compatible.
+func validateKeyTemplateOpts(opts *keyTemplateOpts) error {
+	// besides Curve25519, the curves are the NIST P curves and the curves added by composite.RegisterCurve
+	if opts.curve != commonpb.EllipticCurveType_CURVE25519 {
+		if _, err := composite.GetCurve(opts.curve.String()); err != nil {
+			return fmt.Errorf("unsupported curve '%s'", opts.curve)
+		}
+	}
+
+	// the Concat KDF derives a key wrapping key of kwKeySize bytes, it must be a valid AES key wrapping key size.
+	_, err := kwAlgorithm(opts.kwKeySize)
+	if err != nil {
+		return err
+	}
+
+	if opts.encAEAD == nil {
+		return errors.New("content encryption key template is nil")
+	}
+
+	if opts.prefixType == tinkpb.OutputPrefixType_UNKNOWN_PREFIX {
+		return errors.New("unknown output prefix type")
+	}
+
+	_, err = composite.NewRegisterCompositeAEADEncHelper(opts.encAEAD)
+	if err != nil {
+		return fmt.Errorf("invalid content encryption key template: %w", err)
 	}
+
+	return validateRecipients(opts.recipients)
+}
+
+// validateRecipients validates the curve, key type and point of the recipients keys set by WithRecipients.
+func validateRecipients(recipients []*compositepb.ECPublicKey) error {
+	for _, r := range recipients {
+		if r == nil {
+			return errNilRecipientKey
+		}
+
+		_, _, err := validateRecipientKey(&composite.PublicKey{
+			KID:   r.KID,
+			X:     r.X,
+			Y:     r.Y,
+			Curve: r.CurveType.String(),
+			Type:  r.KeyType.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("invalid recipient key with KID '%s': %w", r.KID, err)
+		}
+	}
+
+	return nil
 }
@@ -7,44 +7,113 @@ SPDX-License-Identifier: Apache-2.0
 package ecdhes
 
 import (
+	"bytes"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"math/big"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/core/cryptofmt"
 	"github.com/google/tink/go/keyset"
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"github.com/google/tink/go/testkeyset"
 	"github.com/stretchr/testify/require"
 
+	cbchmacaead "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/aead"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/keyio"
+	compositepb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
+	ecdhespb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/ecdhes_aead_go_proto"
 )
 
 func TestECDHESKeyTemplateSuccess(t *testing.T) {
 	var flagTests = []struct {
-		tcName    string
-		curveType string
-		tmplFunc  func(recPublicKeys []*composite.PublicKey) (*tinkpb.KeyTemplate, error)
+		tcName   string
+		recTmpl  *tinkpb.KeyTemplate
+		tmplFunc func(recPublicKeys []*composite.PublicKey) (*tinkpb.KeyTemplate, error)
+		// recTmplFunc creates the key template of a single recipient
+		recTmplFunc func(recPublicKey *composite.PublicKey) (*tinkpb.KeyTemplate, error)
 	}{
 		{
-			tcName:    "create ECDHES 256 key templates test",
-			curveType: "P-256",
-			tmplFunc:  ECDHES256KWAES256GCMKeyTemplateWithRecipients,
+			tcName:      "create ECDHES 256 key templates test",
+			recTmpl:     ECDHES256KWAES256GCMKeyTemplate(),
+			tmplFunc:    ECDHES256KWAES256GCMKeyTemplateWithRecipients,
+			recTmplFunc: ECDHES256KWAES256GCMKeyTemplateForRecipient,
 		},
 		{
-			tcName:    "create ECDHES 384 key templates test",
-			curveType: "P-384",
-			tmplFunc:  ECDHES384KWAES256GCMKeyTemplateWithRecipients,
+			tcName:      "create ECDHES 384 key templates test",
+			recTmpl:     ECDHES384KWAES256GCMKeyTemplate(),
+			tmplFunc:    ECDHES384KWAES256GCMKeyTemplateWithRecipients,
+			recTmplFunc: ECDHES384KWAES256GCMKeyTemplateForRecipient,
 		},
 		{
-			tcName:    "create ECDHES 521 key templates test",
-			curveType: "P-521",
-			tmplFunc:  ECDHES521KWAES256GCMKeyTemplateWithRecipients,
+			tcName:      "create ECDHES 521 key templates test",
+			recTmpl:     ECDHES521KWAES256GCMKeyTemplate(),
+			tmplFunc:    ECDHES521KWAES256GCMKeyTemplateWithRecipients,
+			recTmplFunc: ECDHES521KWAES256GCMKeyTemplateForRecipient,
+		},
+		{
+			tcName:      "create ECDHES 256 AES128-GCM key templates test",
+			recTmpl:     ECDHES256KWAES128GCMKeyTemplate(),
+			tmplFunc:    ECDHES256KWAES128GCMKeyTemplateWithRecipients,
+			recTmplFunc: ECDHES256KWAES128GCMKeyTemplateForRecipient,
+		},
+		{
+			tcName:      "create ECDHES 384 AES128-GCM key templates test",
+			recTmpl:     ECDHES384KWAES128GCMKeyTemplate(),
+			tmplFunc:    ECDHES384KWAES128GCMKeyTemplateWithRecipients,
+			recTmplFunc: ECDHES384KWAES128GCMKeyTemplateForRecipient,
+		},
+		{
+			tcName:      "create ECDHES 521 AES128-GCM key templates test",
+			recTmpl:     ECDHES521KWAES128GCMKeyTemplate(),
+			tmplFunc:    ECDHES521KWAES128GCMKeyTemplateWithRecipients,
+			recTmplFunc: ECDHES521KWAES128GCMKeyTemplateForRecipient,
+		},
+		{
+			tcName:      "create ECDHES 256 A128KW key templates test",
+			recTmpl:     ECDHES256KWA128KWAES256GCMKeyTemplate(),
+			tmplFunc:    ECDHES256KWA128KWAES256GCMKeyTemplateWithRecipients,
+			recTmplFunc: ECDHES256KWA128KWAES256GCMKeyTemplateForRecipient,
+		},
+		{
+			tcName:      "create ECDHES 256 A192KW key templates test",
+			recTmpl:     ECDHES256KWA192KWAES256GCMKeyTemplate(),
+			tmplFunc:    ECDHES256KWA192KWAES256GCMKeyTemplateWithRecipients,
+			recTmplFunc: ECDHES256KWA192KWAES256GCMKeyTemplateForRecipient,
+		},
+		{
+			tcName:      "create ECDHES 256 ChaCha20Poly1305 key templates test",
+			recTmpl:     ECDHES256KWChaChaKeyTemplate(),
+			tmplFunc:    ECDHES256KWChaChaKeyTemplateWithRecipients,
+			recTmplFunc: ECDHES256KWChaChaKeyTemplateForRecipient,
+		},
+		{
+			tcName:      "create ECDHES 256 XChaCha20Poly1305 key templates test",
+			recTmpl:     ECDHES256KWXChaChaKeyTemplate(),
+			tmplFunc:    ECDHES256KWXChaChaKeyTemplateWithRecipients,
+			recTmplFunc: ECDHES256KWXChaChaKeyTemplateForRecipient,
+		},
+		{
+			tcName:      "create ECDHES X25519 XChaCha20Poly1305 key templates test",
+			recTmpl:     ECDHESX25519KWXChaChaKeyTemplate(),
+			tmplFunc:    ECDHESX25519KWXChaChaKeyTemplateWithRecipients,
+			recTmplFunc: ECDHESX25519KWXChaChaKeyTemplateForRecipient,
 		},
 	}
 
 	for _, tt := range flagTests {
 		tc := tt
 		t.Run("Test "+tc.tcName, func(t *testing.T) {
-			recPubKeys, recKHs := createRecipients(t, tc.curveType, 10)
+			recPubKeys, recKHs := createRecipients(t, tc.recTmpl, 10)
 
 			kt, err := tc.tmplFunc(recPubKeys)
 			require.NoError(t, err)
@@ -74,12 +143,44 @@ func TestECDHESKeyTemplateSuccess(t *testing.T) {
 				require.NoError(t, er)
 				require.Equal(t, pt, dpt)
 			}
+
+			_, err = tc.recTmplFunc(nil)
+			require.EqualError(t, err, "recipient public key is nil")
+
+			kt, err = tc.recTmplFunc(recPubKeys[0])
+			require.NoError(t, err)
+
+			kh, err = keyset.NewHandle(kt)
+			require.NoError(t, err)
+
+			pubKH, err = kh.Public()
+			require.NoError(t, err)
+
+			e, err = NewECDHESEncrypt(pubKH)
+			require.NoError(t, err)
+
+			// single recipient encryption requires a base64URL encoded JSON aad
+			singleRecAAD := []byte(base64.RawURLEncoding.EncodeToString([]byte(`{"enc":"A256GCM"}`)))
+
+			ct, err = e.Encrypt(pt, singleRecAAD)
+			require.NoError(t, err)
+
+			encData := &composite.EncryptedData{}
+			require.NoError(t, json.Unmarshal(ct, encData))
+
+			d, err := NewECDHESDecrypt(recKHs[0])
+			require.NoError(t, err)
+
+			dpt, err := d.Decrypt(ct, encData.SingleRecipientAAD)
+			require.NoError(t, err)
+			require.Equal(t, pt, dpt)
 		})
 	}
 }
 
 // createRecipients and return their public key and keyset.Handle
-func createRecipients(t *testing.T, curveType string, nbOfRecipients int) ([]*composite.PublicKey, []*keyset.Handle) {
+func createRecipients(t *testing.T, tmpl *tinkpb.KeyTemplate,
+	nbOfRecipients int) ([]*composite.PublicKey, []*keyset.Handle) {
 	t.Helper()
 
 	var (
@@ -88,7 +189,7 @@ func createRecipients(t *testing.T, curveType string, nbOfRecipients int) ([]*co
 	)
 
 	for i := 0; i < nbOfRecipients; i++ {
-		ecPubKey, kh := createRecipient(t, curveType)
+		ecPubKey, kh := createRecipient(t, tmpl)
 
 		r = append(r, ecPubKey)
 		rKH = append(rKH, kh)
@@ -99,20 +200,9 @@ func createRecipients(t *testing.T, curveType string, nbOfRecipients int) ([]*co
 
 // createRecipient creates a new recipient keyset.Handle, extracts public key, marshals it and returns
 // both marshalled public key and original recipient keyset.Handle
-func createRecipient(t *testing.T, curveType string) (*composite.PublicKey, *keyset.Handle) {
+func createRecipient(t *testing.T, tmpl *tinkpb.KeyTemplate) (*composite.PublicKey, *keyset.Handle) {
 	t.Helper()
 
-	var tmpl *tinkpb.KeyTemplate
-
-	switch curveType {
-	case "P-256":
-		tmpl = ECDHES256KWAES256GCMKeyTemplate()
-	case "P-384":
-		tmpl = ECDHES384KWAES256GCMKeyTemplate()
-	case "P-521":
-		tmpl = ECDHES521KWAES256GCMKeyTemplate()
-	}
-
 	kh, err := keyset.NewHandle(tmpl)
 	require.NoError(t, err)
 
@@ -148,7 +238,7 @@ func TestECDHESKeyTemplateFailures(t *testing.T) {
 			curve:    badCurve,
 			keyType:  "EC",
 			tmplFunc: ECDHES256KWAES256GCMKeyTemplateWithRecipients,
-			errMsg:   fmt.Sprintf("curve %s not supported", badCurve),
+			errMsg:   fmt.Sprintf("invalid recipient key with KID '': curve %s not supported", badCurve),
 		},
 		{
 			tcName: "ECDHES P256 Key Template creation with Bad keyType should fail",
@@ -164,7 +254,7 @@ func TestECDHESKeyTemplateFailures(t *testing.T) {
 			curve:    "P-256",
 			keyType:  badKeyType,
 			tmplFunc: ECDHES256KWAES256GCMKeyTemplateWithRecipients,
-			errMsg:   fmt.Sprintf("key type %s not supported", badKeyType),
+			errMsg:   fmt.Sprintf("invalid recipient key with KID '': key type %s not supported", badKeyType),
 		},
 		{
 			tcName: "ECDHES P384 Key Template creation with Bad Curve should fail",
@@ -180,7 +270,7 @@ func TestECDHESKeyTemplateFailures(t *testing.T) {
 			curve:    badCurve,
 			keyType:  "EC",
 			tmplFunc: ECDHES384KWAES256GCMKeyTemplateWithRecipients,
-			errMsg:   fmt.Sprintf("curve %s not supported", badCurve),
+			errMsg:   fmt.Sprintf("invalid recipient key with KID '': curve %s not supported", badCurve),
 		},
 
 		{
@@ -197,7 +287,7 @@ func TestECDHESKeyTemplateFailures(t *testing.T) {
 			curve:    "P-384",
 			keyType:  badKeyType,
 			tmplFunc: ECDHES384KWAES256GCMKeyTemplateWithRecipients,
-			errMsg:   fmt.Sprintf("key type %s not supported", badKeyType),
+			errMsg:   fmt.Sprintf("invalid recipient key with KID '': key type %s not supported", badKeyType),
 		},
 		{
 			tcName: "ECDHES P521 Key Template creation with Bad Curve should fail",
@@ -213,7 +303,7 @@ func TestECDHESKeyTemplateFailures(t *testing.T) {
 			curve:    badCurve,
 			keyType:  "EC",
 			tmplFunc: ECDHES521KWAES256GCMKeyTemplateWithRecipients,
-			errMsg:   fmt.Sprintf("curve %s not supported", badCurve),
+			errMsg:   fmt.Sprintf("invalid recipient key with KID '': curve %s not supported", badCurve),
 		},
 
 		{
@@ -230,10 +320,91 @@ func TestECDHESKeyTemplateFailures(t *testing.T) {
 			curve:    "P-521",
 			keyType:  badKeyType,
 			tmplFunc: ECDHES521KWAES256GCMKeyTemplateWithRecipients,
-			errMsg:   fmt.Sprintf("key type %s not supported", badKeyType),
+			errMsg:   fmt.Sprintf("invalid recipient key with KID '': key type %s not supported", badKeyType),
 		},
 	}
 
+	validPubKey, _ := createRecipient(t, ECDHES256KWAES256GCMKeyTemplate())
+
+	flagTests = append(flagTests, []struct {
+		tcName     string
+		recPubKeys []*composite.PublicKey
+		curve      string
+		keyType    string
+		tmplFunc   func(recPublicKeys []*composite.PublicKey) (*tinkpb.KeyTemplate, error)
+		errMsg     string
+	}{
+		{
+			tcName: "ECDHES P256 Key Template creation with empty X should fail",
+			recPubKeys: []*composite.PublicKey{
+				{
+					KID:   "bad-key",
+					Y:     validPubKey.Y,
+					Curve: validPubKey.Curve,
+					Type:  validPubKey.Type,
+				},
+			},
+			tmplFunc: ECDHES256KWAES256GCMKeyTemplateWithRecipients,
+			errMsg:   "invalid recipient key with KID 'bad-key': empty X",
+		},
+		{
+			tcName: "ECDHES P256 Key Template creation with empty Y should fail",
+			recPubKeys: []*composite.PublicKey{
+				{
+					KID:   "bad-key",
+					X:     validPubKey.X,
+					Curve: validPubKey.Curve,
+					Type:  validPubKey.Type,
+				},
+			},
+			tmplFunc: ECDHES256KWAES256GCMKeyTemplateWithRecipients,
+			errMsg: "invalid recipient key with KID 'bad-key': empty Y with an invalid compressed X: " +
+				"invalid compressed EC point",
+		},
+		{
+			tcName: "ECDHES P256 Key Template creation with a point not on the curve should fail",
+			recPubKeys: []*composite.PublicKey{
+				validPubKey,
+				{
+					KID:   "bad-key",
+					X:     validPubKey.Y,
+					Y:     validPubKey.X,
+					Curve: validPubKey.Curve,
+					Type:  validPubKey.Type,
+				},
+			},
+			tmplFunc: ECDHES256KWAES256GCMKeyTemplateWithRecipients,
+			errMsg:   "invalid recipient key with KID 'bad-key': point is not on curve P-256",
+		},
+		{
+			tcName: "ECDHES P384 Key Template creation with a P256 key should fail",
+			recPubKeys: []*composite.PublicKey{
+				{
+					KID:   "bad-key",
+					X:     validPubKey.X,
+					Y:     validPubKey.Y,
+					Curve: "P-384",
+					Type:  validPubKey.Type,
+				},
+			},
+			tmplFunc: ECDHES384KWAES256GCMKeyTemplateWithRecipients,
+			errMsg:   "invalid recipient key with KID 'bad-key': point is not on curve P-384",
+		},
+		{
+			tcName: "ECDHES X25519 Key Template creation with a bad OKP key size should fail",
+			recPubKeys: []*composite.PublicKey{
+				{
+					KID:   "bad-key",
+					X:     []byte("bad X25519 key"),
+					Curve: "X25519",
+					Type:  "OKP",
+				},
+			},
+			tmplFunc: ECDHESX25519KWXChaChaKeyTemplateWithRecipients,
+			errMsg:   "invalid recipient key with KID 'bad-key': invalid X25519 public key size 14",
+		},
+	}...)
+
 	for _, tt := range flagTests {
 		tc := tt
 		t.Run(tc.tcName, func(t *testing.T) {
@@ -242,3 +413,587 @@ func TestECDHESKeyTemplateFailures(t *testing.T) {
 		})
 	}
 }
+
+func TestNewECDHESKeyTemplate(t *testing.T) {
+	t.Run("default options create ECDHES256KWAES256GCM key template", func(t *testing.T) {
+		require.Equal(t, ECDHES256KWAES256GCMKeyTemplate(), newECDHESKeyTemplate(t))
+	})
+
+	t.Run("P-384 curve with AES128-GCM CEK, A128KW and compressed points", func(t *testing.T) {
+		opts := []Option{
+			WithCurve(commonpb.EllipticCurveType_NIST_P384),
+			WithContentEncryption(aead.AES128GCMKeyTemplate()),
+			WithCompressedPoints(),
+			WithKWKeySize(a128KWKeySize),
+		}
+
+		recPubKeys, recKHs := createRecipients(t, newECDHESKeyTemplate(t, opts...), 3)
+
+		recKeys, err := createECDHESPublicKeys(recPubKeys)
+		require.NoError(t, err)
+
+		kh, err := keyset.NewHandle(newECDHESKeyTemplate(t, append(opts, WithRecipients(recKeys))...))
+		require.NoError(t, err)
+
+		pubKH, err := kh.Public()
+		require.NoError(t, err)
+
+		e, err := NewECDHESEncrypt(pubKH)
+		require.NoError(t, err)
+
+		pt := []byte("secret message")
+		aad := []byte("aad message")
+
+		ct, err := e.Encrypt(pt, aad)
+		require.NoError(t, err)
+
+		encData := &composite.EncryptedData{}
+		err = json.Unmarshal(ct, encData)
+		require.NoError(t, err)
+
+		// P-384 compressed EPKs are 1 byte prefix + 48 bytes 'x'
+		for _, rec := range encData.Recipients {
+			require.Len(t, rec.EPK.X, 49)
+			require.Empty(t, rec.EPK.Y)
+		}
+
+		for _, recKH := range recKHs {
+			d, er := NewECDHESDecrypt(recKH)
+			require.NoError(t, er)
+
+			dpt, er := d.Decrypt(ct, aad)
+			require.NoError(t, er)
+			require.Equal(t, pt, dpt)
+		}
+	})
+
+	t.Run("custom Concat KDF OtherInfo", func(t *testing.T) {
+		opts := []Option{WithAlgorithmID("custom-alg-id"), WithPartyInfo([]byte("Alice"), []byte("Bob"))}
+
+		recPubKeys, recKHs := createRecipients(t, newECDHESKeyTemplate(t, opts...), 2)
+
+		recKeys, err := createECDHESPublicKeys(recPubKeys)
+		require.NoError(t, err)
+
+		kh, err := keyset.NewHandle(newECDHESKeyTemplate(t, append(opts, WithRecipients(recKeys))...))
+		require.NoError(t, err)
+
+		pt := []byte("secret message")
+		aad := []byte("aad message")
+
+		ct, err := Encrypt(kh, pt, aad)
+		require.NoError(t, err)
+
+		for _, recKH := range recKHs {
+			dpt, er := Decrypt(recKH, ct, aad)
+			require.NoError(t, er)
+			require.Equal(t, pt, dpt)
+		}
+
+		// a recipient key with other party info derives another key wrapping key
+		otherRecKH, err := keyset.NewHandle(newECDHESKeyTemplate(t, WithAlgorithmID("custom-alg-id"),
+			WithPartyInfo([]byte("Alice"), []byte("Carol"))))
+		require.NoError(t, err)
+
+		otherRecPubKey, err := PublicKeyFromKeysetHandle(otherRecKH)
+		require.NoError(t, err)
+
+		otherRecKeys, err := createECDHESPublicKeys([]*composite.PublicKey{recPubKeys[0], otherRecPubKey})
+		require.NoError(t, err)
+
+		kh, err = keyset.NewHandle(newECDHESKeyTemplate(t, append(opts, WithRecipients(otherRecKeys))...))
+		require.NoError(t, err)
+
+		ct, err = Encrypt(kh, pt, aad)
+		require.NoError(t, err)
+
+		dpt, err := Decrypt(recKHs[0], ct, aad)
+		require.NoError(t, err)
+		require.Equal(t, pt, dpt)
+
+		_, err = Decrypt(otherRecKH, ct, aad)
+		require.EqualError(t, err, "ecdhes_factory: decryption failed")
+	})
+
+	t.Run("AAD prefix", func(t *testing.T) {
+		opts := []Option{WithAADPrefix([]byte("didcomm-anon"))}
+
+		recPubKeys, recKHs := createRecipients(t, newECDHESKeyTemplate(t, opts...), 1)
+
+		// the same recipient key with another prefix
+		otherRecKH, err := changeAADPrefix(recKHs[0], []byte("didcomm-auth"))
+		require.NoError(t, err)
+
+		recKeys, err := createECDHESPublicKeys(recPubKeys)
+		require.NoError(t, err)
+
+		kh, err := keyset.NewHandle(newECDHESKeyTemplate(t, append(opts, WithRecipients(recKeys))...))
+		require.NoError(t, err)
+
+		pt := []byte("secret message")
+		// single recipient encryption requires a base64URL encoded JSON aad
+		aad := []byte(base64.RawURLEncoding.EncodeToString([]byte(`{"enc":"A256GCM"}`)))
+
+		ct, err := Encrypt(kh, pt, aad)
+		require.NoError(t, err)
+
+		// the single recipient AAD doesn't include the prefix
+		encData := new(composite.EncryptedData)
+		require.NoError(t, json.Unmarshal(ct, encData))
+		require.NotContains(t, string(encData.SingleRecipientAAD), "didcomm-anon")
+
+		dpt, err := Decrypt(recKHs[0], ct, encData.SingleRecipientAAD)
+		require.NoError(t, err)
+		require.Equal(t, pt, dpt)
+
+		_, err = Decrypt(otherRecKH, ct, encData.SingleRecipientAAD)
+		require.EqualError(t, err, "ecdhes_factory: decryption failed")
+
+		// nor can it be decrypted by a recipient key without prefix
+		noPrefixRecKH, err := changeAADPrefix(recKHs[0], nil)
+		require.NoError(t, err)
+
+		_, err = Decrypt(noPrefixRecKH, ct, encData.SingleRecipientAAD)
+		require.EqualError(t, err, "ecdhes_factory: decryption failed")
+	})
+
+	t.Run("AES-CBC-HMAC-SHA2 content encryption round trip", func(t *testing.T) {
+		tests := []struct {
+			encAlg string
+			tmpl   *tinkpb.KeyTemplate
+		}{
+			{encAlg: composite.A128CBCHS256, tmpl: cbchmacaead.AES128CBCHMACSHA256KeyTemplate()},
+			{encAlg: composite.A256CBCHS512, tmpl: cbchmacaead.AES256CBCHMACSHA512KeyTemplate()},
+		}
+
+		for _, tc := range tests {
+			opts := []Option{WithContentEncryption(tc.tmpl)}
+
+			recPubKeys, recKHs := createRecipients(t, newECDHESKeyTemplate(t, opts...), 2)
+
+			recKeys, err := createECDHESPublicKeys(recPubKeys)
+			require.NoError(t, err)
+
+			kh, err := keyset.NewHandle(newECDHESKeyTemplate(t, append(opts, WithRecipients(recKeys))...))
+			require.NoError(t, err)
+
+			pt := []byte("secret message")
+			aad := []byte("aad message")
+
+			ct, err := Encrypt(kh, pt, aad)
+			require.NoError(t, err)
+
+			encData := &composite.EncryptedData{}
+			err = json.Unmarshal(ct, encData)
+			require.NoError(t, err)
+			require.Equal(t, tc.encAlg, encData.EncAlg)
+
+			for _, recKH := range recKHs {
+				dpt, er := Decrypt(recKH, ct, aad)
+				require.NoError(t, er)
+				require.Equal(t, pt, dpt)
+			}
+
+			_, err = Decrypt(recKHs[0], ct, []byte("other aad"))
+			require.EqualError(t, err, "ecdhes_factory: decryption failed")
+		}
+	})
+
+	t.Run("registered curve round trip", func(t *testing.T) {
+		params := &elliptic.CurveParams{Name: "brainpoolP256t1", BitSize: 256}
+		params.P, _ = new(big.Int).SetString("A9FB57DBA1EEA9BC3E660A909D838D726E3BF623D52620282013481D1F6E5377", 16)
+		params.N, _ = new(big.Int).SetString("A9FB57DBA1EEA9BC3E660A909D838D718C397AA3B561A6F7901E0E82974856A7", 16)
+		params.B, _ = new(big.Int).SetString("662C61C430D84EA4FE66A7733D0B76B7BF93EBC4AF2F49256AE58101FEE92B04", 16)
+		params.Gx, _ = new(big.Int).SetString("A3E8EB3CC1CFE7B7732213B23A656149AFA142C47AAFBC2B79A191562E1305F4", 16)
+		params.Gy, _ = new(big.Int).SetString("2D996C823439C56D7F7B22E14644417E69BCB6DE39D027001DABE8F35B25C9BE", 16)
+
+		curveType := commonpb.EllipticCurveType(100)
+
+		_, err := NewECDHESKeyTemplate(WithCurve(curveType))
+		require.EqualError(t, err, "NewECDHESKeyTemplate: unsupported curve '100'")
+
+		require.NoError(t, composite.RegisterCurve(params.Name, curveType, params))
+
+		opts := []Option{WithCurve(curveType)}
+
+		recPubKeys, recKHs := createRecipients(t, newECDHESKeyTemplate(t, opts...), 2)
+
+		recKeys, err := createECDHESPublicKeys(recPubKeys)
+		require.NoError(t, err)
+
+		kh, err := keyset.NewHandle(newECDHESKeyTemplate(t, append(opts, WithRecipients(recKeys))...))
+		require.NoError(t, err)
+
+		pt := []byte("secret message")
+		aad := []byte("aad message")
+
+		ct, err := Encrypt(kh, pt, aad)
+		require.NoError(t, err)
+
+		for _, recKH := range recKHs {
+			dpt, er := Decrypt(recKH, ct, aad)
+			require.NoError(t, er)
+			require.Equal(t, pt, dpt)
+		}
+	})
+
+	t.Run("RAW output prefix is the default", func(t *testing.T) {
+		require.Equal(t, tinkpb.OutputPrefixType_RAW, newECDHESKeyTemplate(t).OutputPrefixType)
+
+		recPubKeys, recKHs := createRecipients(t, newECDHESKeyTemplate(t), 2)
+
+		kt, err := ECDHES256KWAES256GCMKeyTemplateWithRecipients(recPubKeys)
+		require.NoError(t, err)
+		require.Equal(t, tinkpb.OutputPrefixType_RAW, kt.OutputPrefixType)
+
+		kh, err := keyset.NewHandle(kt)
+		require.NoError(t, err)
+
+		pt := []byte("secret message")
+		aad := []byte("aad message")
+
+		ct, err := Encrypt(kh, pt, aad)
+		require.NoError(t, err)
+
+		// JWE consumers parse the serialized encrypted data without any key prefix
+		encData := &composite.EncryptedData{}
+		require.NoError(t, json.Unmarshal(ct, encData))
+
+		dpt, err := Decrypt(recKHs[0], ct, aad)
+		require.NoError(t, err)
+		require.Equal(t, pt, dpt)
+	})
+
+	t.Run("TINK output prefix round trip", func(t *testing.T) {
+		opts := []Option{WithOutputPrefixType(tinkpb.OutputPrefixType_TINK)}
+
+		recPubKeys, recKHs := createRecipients(t, newECDHESKeyTemplate(t, opts...), 2)
+
+		recKeys, err := createECDHESPublicKeys(recPubKeys)
+		require.NoError(t, err)
+
+		kt := newECDHESKeyTemplate(t, append(opts, WithRecipients(recKeys))...)
+		require.Equal(t, tinkpb.OutputPrefixType_TINK, kt.OutputPrefixType)
+
+		kh, err := keyset.NewHandle(kt)
+		require.NoError(t, err)
+
+		pt := []byte("secret message")
+		aad := []byte("aad message")
+
+		ct, err := Encrypt(kh, pt, aad)
+		require.NoError(t, err)
+
+		// the ciphertext is prefixed with the TINK start byte and the key ID of the primary key
+		require.Equal(t, byte(cryptofmt.TinkStartByte), ct[0])
+		require.Equal(t, testkeyset.KeysetMaterial(kh).PrimaryKeyId,
+			binary.BigEndian.Uint32(ct[1:cryptofmt.NonRawPrefixSize]))
+
+		encData := &composite.EncryptedData{}
+		require.NoError(t, json.Unmarshal(ct[cryptofmt.NonRawPrefixSize:], encData))
+
+		for _, recKH := range recKHs {
+			dpt, er := Decrypt(recKH, ct, aad)
+			require.NoError(t, er)
+			require.Equal(t, pt, dpt)
+		}
+
+		// a RAW ciphertext doesn't start with a TINK or LEGACY output prefix
+		ps, err := recKHs[0].Primitives()
+		require.NoError(t, err)
+		require.Len(t, composite.NonRawEntries(ps, string(ct[:cryptofmt.NonRawPrefixSize])), 1)
+		require.Nil(t, composite.NonRawEntries(ps, string(ct[cryptofmt.NonRawPrefixSize:][:cryptofmt.NonRawPrefixSize])))
+
+		// streams are never prefixed
+		stream := new(bytes.Buffer)
+
+		w, err := EncryptWriter(kh, stream, aad)
+		require.NoError(t, err)
+
+		_, err = w.Write(pt)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		r, err := DecryptReader(recKHs[0], stream, aad)
+		require.NoError(t, err)
+
+		dpt, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, pt, dpt)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			opts   []Option
+			errMsg string
+		}{
+			{
+				name:   "unsupported curve",
+				opts:   []Option{WithCurve(commonpb.EllipticCurveType_UNKNOWN_CURVE)},
+				errMsg: "NewECDHESKeyTemplate: unsupported curve 'UNKNOWN_CURVE'",
+			},
+			{
+				name:   "key wrapping key size not matching an AES key wrap algorithm",
+				opts:   []Option{WithCurve(commonpb.EllipticCurveType_NIST_P521), WithKWKeySize(20)},
+				errMsg: "NewECDHESKeyTemplate: unsupported key wrapping key size 20",
+			},
+			{
+				name:   "nil content encryption key template",
+				opts:   []Option{WithContentEncryption(nil)},
+				errMsg: "NewECDHESKeyTemplate: content encryption key template is nil",
+			},
+			{
+				name:   "unknown output prefix type",
+				opts:   []Option{WithOutputPrefixType(tinkpb.OutputPrefixType_UNKNOWN_PREFIX)},
+				errMsg: "NewECDHESKeyTemplate: unknown output prefix type",
+			},
+			{
+				name: "unsupported content encryption key template",
+				opts: []Option{WithContentEncryption(aead.AES256CTRHMACSHA256KeyTemplate())},
+				errMsg: "NewECDHESKeyTemplate: invalid content encryption key template: compositeAEADEncHelper: " +
+					"unsupported AEAD content encryption key type: type.googleapis.com/google.crypto.tink.AesCtrHmacAeadKey",
+			},
+			{
+				name:   "nil recipient key",
+				opts:   []Option{WithRecipients([]*compositepb.ECPublicKey{nil})},
+				errMsg: "NewECDHESKeyTemplate: recipient public key is nil",
+			},
+			{
+				name: "recipient key with empty X",
+				opts: []Option{WithRecipients([]*compositepb.ECPublicKey{{
+					KID:       "rec1",
+					CurveType: commonpb.EllipticCurveType_NIST_P256,
+					KeyType:   compositepb.KeyType_EC,
+				}})},
+				errMsg: "NewECDHESKeyTemplate: invalid recipient key with KID 'rec1': empty X",
+			},
+			{
+				name: "recipient key not on its curve",
+				opts: []Option{WithRecipients([]*compositepb.ECPublicKey{{
+					KID:       "rec2",
+					CurveType: commonpb.EllipticCurveType_NIST_P256,
+					KeyType:   compositepb.KeyType_EC,
+					X:         []byte{1},
+					Y:         []byte{1},
+				}})},
+				errMsg: "NewECDHESKeyTemplate: invalid recipient key with KID 'rec2': point is not on curve P-256",
+			},
+		}
+
+		for _, tc := range tests {
+			tt := tc
+			t.Run(tt.name, func(t *testing.T) {
+				kt, err := NewECDHESKeyTemplate(tt.opts...)
+				require.EqualError(t, err, tt.errMsg)
+				require.Nil(t, kt)
+			})
+		}
+	})
+}
+
+func newECDHESKeyTemplate(t *testing.T, opts ...Option) *tinkpb.KeyTemplate {
+	t.Helper()
+
+	kt, err := NewECDHESKeyTemplate(opts...)
+	require.NoError(t, err)
+
+	return kt
+}
+
+func TestECDHESKeyTemplateSnapshot(t *testing.T) {
+	// golden serialized templates, a change here means the template parameters (eg curve or CEK) have changed.
+	const (
+		ecdhes256KWAES256GCMHex = "0a4a747970652e68797065726c65646765722e6f72672f68797065726c65646765722e617269" +
+			"65732e63727970746f2e74696e6b2e45636468657341657341656164507269766174654b657912480a460a060802100120" +
+			"20123a0a380a30747970652e676f6f676c65617069732e636f6d2f676f6f676c652e63727970746f2e74696e6b2e416573" +
+			"47636d4b657912021020180118011803"
+		ecdhes384KWAES128GCMHex = "0a4a747970652e68797065726c65646765722e6f72672f68797065726c65646765722e617269" +
+			"65732e63727970746f2e74696e6b2e45636468657341657341656164507269766174654b657912480a460a060803100120" +
+			"20123a0a380a30747970652e676f6f676c65617069732e636f6d2f676f6f676c652e63727970746f2e74696e6b2e416573" +
+			"47636d4b657912021010180118011803"
+	)
+
+	for expected, kt := range map[string]*tinkpb.KeyTemplate{
+		ecdhes256KWAES256GCMHex: ECDHES256KWAES256GCMKeyTemplate(),
+		ecdhes384KWAES128GCMHex: ECDHES384KWAES128GCMKeyTemplate(),
+	} {
+		serializedKT, err := composite.SerializeKeyTemplate(kt)
+		require.NoError(t, err)
+		require.Equal(t, expected, hex.EncodeToString(serializedKT))
+
+		parsedKT, err := composite.ParseKeyTemplate(serializedKT)
+		require.NoError(t, err)
+		require.Equal(t, kt.TypeUrl, parsedKT.TypeUrl)
+		require.Equal(t, kt.Value, parsedKT.Value)
+		require.Equal(t, kt.OutputPrefixType, parsedKT.OutputPrefixType)
+
+		// the parsed template creates working keys
+		_, err = keyset.NewHandle(parsedKT)
+		require.NoError(t, err)
+	}
+}
+
+func TestValidateKeyTemplate(t *testing.T) {
+	for _, kt := range []*tinkpb.KeyTemplate{
+		ECDHES256KWAES256GCMKeyTemplate(),
+		ECDHES384KWAES128GCMKeyTemplate(),
+		ECDHES521KWAES256GCMKeyTemplate(),
+		ECDHES256KWA128KWAES256GCMKeyTemplate(),
+		ECDHES256KWChaChaKeyTemplate(),
+		ECDHESX25519KWXChaChaKeyTemplate(),
+		newECDHESKeyTemplate(t, WithCompressedPoints(),
+			WithContentEncryption(cbchmacaead.AES128CBCHMACSHA256KeyTemplate())),
+	} {
+		require.NoError(t, composite.ValidateKeyTemplate(kt))
+	}
+
+	t.Run("malformed key templates", func(t *testing.T) {
+		kt := ECDHES256KWAES256GCMKeyTemplate()
+		kt.Value = []byte("bad key format")
+
+		err := composite.ValidateKeyTemplate(kt)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "validateKeyTemplate: invalid key format: ecdhes_aes_private_key_manager: "+
+			"failed to unmarshal key format")
+
+		kt = newECDHESKeyTemplate(t, WithCurve(commonpb.EllipticCurveType_CURVE25519),
+			WithContentEncryption(aead.XChaCha20Poly1305KeyTemplate()), WithCompressedPoints())
+
+		err = composite.ValidateKeyTemplate(kt)
+		require.EqualError(t, err, "validateKeyTemplate: invalid key format: ecdhes_aes_private_key_manager: "+
+			"compressed point format is not supported by OKP keys")
+
+		kt = newECDHESKeyTemplate(t, WithPointFormat(commonpb.EcPointFormat_UNKNOWN_FORMAT))
+
+		err = composite.ValidateKeyTemplate(kt)
+		require.EqualError(t, err, "validateKeyTemplate: invalid key format: ecdhes_aes_private_key_manager: "+
+			"unsupported point format 'UNKNOWN_FORMAT'")
+
+		kt = ECDHES256KWAES256GCMKeyTemplate()
+		kt.Value = mustMarshalKeyFormat(t, &ecdhespb.EcdhesAeadKeyFormat{
+			Params: &ecdhespb.EcdhesAeadParams{
+				KwParams: &ecdhespb.EcdhesKwParams{
+					CurveType: commonpb.EllipticCurveType_NIST_P256,
+					KeyType:   compositepb.KeyType_EC,
+				},
+				EncParams: &ecdhespb.EcdhesAeadEncParams{
+					AeadEnc: aead.AES128CTRHMACSHA256KeyTemplate(),
+				},
+				EcPointFormat: commonpb.EcPointFormat_UNCOMPRESSED,
+			},
+		})
+
+		err = composite.ValidateKeyTemplate(kt)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "validateKeyTemplate: invalid key format: ecdhes_aes_private_key_manager: "+
+			"unsupported content encryption")
+
+		kt.Value = mustMarshalKeyFormat(t, &ecdhespb.EcdhesAeadKeyFormat{
+			Params: &ecdhespb.EcdhesAeadParams{EcPointFormat: commonpb.EcPointFormat_UNCOMPRESSED},
+		})
+
+		err = composite.ValidateKeyTemplate(kt)
+		require.EqualError(t, err, "validateKeyTemplate: invalid key format: ecdhes_aes_private_key_manager: "+
+			"key format params are missing")
+	})
+}
+
+func mustMarshalKeyFormat(t *testing.T, keyFormat *ecdhespb.EcdhesAeadKeyFormat) []byte {
+	t.Helper()
+
+	serializedKeyFormat, err := proto.Marshal(keyFormat)
+	require.NoError(t, err)
+
+	return serializedKeyFormat
+}
+
+// changeAADPrefix returns a copy of the private keyset handle kh with its key set with the given AAD prefix.
+func changeAADPrefix(kh *keyset.Handle, prefix []byte) (*keyset.Handle, error) {
+	ks := proto.Clone(testkeyset.KeysetMaterial(kh)).(*tinkpb.Keyset)
+
+	for _, key := range ks.Key {
+		privKey := new(ecdhespb.EcdhesAeadPrivateKey)
+
+		err := proto.Unmarshal(key.KeyData.Value, privKey)
+		if err != nil {
+			return nil, err
+		}
+
+		privKey.PublicKey.Params.EncParams.AadPrefix = prefix
+
+		key.KeyData.Value, err = proto.Marshal(privKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return testkeyset.NewHandle(ks)
+}
+
+// mockRecipientResolver resolves the recipient keys of DIDs from a map.
+type mockRecipientResolver map[string][]*composite.PublicKey
+
+func (m mockRecipientResolver) ResolveRecipientKeys(did string) ([]*composite.PublicKey, error) {
+	keys, ok := m[did]
+	if !ok {
+		return nil, fmt.Errorf("DID %s not found", did)
+	}
+
+	return keys, nil
+}
+
+func TestKeyTemplateWithRecipientDIDs(t *testing.T) {
+	recPubKeys, recKHs := createRecipients(t, ECDHESX25519KWXChaChaKeyTemplate(), 3)
+
+	resolver := mockRecipientResolver{
+		"did:example:alice": recPubKeys[:2],
+		"did:example:bob":   recPubKeys[2:],
+		"did:example:carol": nil,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		kt, err := KeyTemplateWithRecipientDIDs(ECDHESX25519KWXChaChaKeyTemplateWithRecipients, resolver,
+			[]string{"did:example:alice", "did:example:bob"})
+		require.NoError(t, err)
+
+		kh, err := keyset.NewHandle(kt)
+		require.NoError(t, err)
+
+		pt := []byte("secret message")
+		aad := []byte("aad value")
+
+		ct, err := Encrypt(kh, pt, aad)
+		require.NoError(t, err)
+
+		for _, recKH := range recKHs {
+			dpt, err := Decrypt(recKH, ct, aad)
+			require.NoError(t, err)
+			require.Equal(t, pt, dpt)
+		}
+	})
+
+	t.Run("DID without encryption key", func(t *testing.T) {
+		_, err := KeyTemplateWithRecipientDIDs(ECDHESX25519KWXChaChaKeyTemplateWithRecipients, resolver,
+			[]string{"did:example:alice", "did:example:carol"})
+		require.EqualError(t, err, "resolveRecipients: recipient did:example:carol has no encryption key")
+	})
+
+	t.Run("DID not resolved", func(t *testing.T) {
+		_, err := KeyTemplateWithRecipientDIDs(ECDHESX25519KWXChaChaKeyTemplateWithRecipients, resolver,
+			[]string{"did:example:dave"})
+		require.EqualError(t, err, "resolveRecipients: failed to resolve keys of recipient did:example:dave: "+
+			"DID did:example:dave not found")
+	})
+
+	t.Run("no DID", func(t *testing.T) {
+		_, err := KeyTemplateWithRecipientDIDs(ECDHESX25519KWXChaChaKeyTemplateWithRecipients, resolver, nil)
+		require.EqualError(t, err, "resolveRecipients: no recipient DID")
+	})
+
+	t.Run("nil resolver", func(t *testing.T) {
+		_, err := KeyTemplateWithRecipientDIDs(ECDHESX25519KWXChaChaKeyTemplateWithRecipients, nil,
+			[]string{"did:example:alice"})
+		require.EqualError(t, err, "resolveRecipients: recipient resolver is nil")
+	})
+}
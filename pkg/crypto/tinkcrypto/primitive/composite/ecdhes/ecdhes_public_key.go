@@ -0,0 +1,96 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ecdhes
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+	ecdhespb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/ecdhes_aead_go_proto"
+)
+
+// PublicKeyFromKeysetHandle returns the primary public key of the ECDH-ES keyset handle kh. kh can be either a
+// private keyset handle created from an ECDH-ES key template or its public keyset handle. The returned key can be
+// passed to the ...WithRecipients key templates to encrypt messages for the owner of kh.
+func PublicKeyFromKeysetHandle(kh *keyset.Handle) (*composite.PublicKey, error) {
+	pubKeyProto, err := primaryPublicKey(kh)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes: PublicKeyFromKeysetHandle: %w", err)
+	}
+
+	return &composite.PublicKey{
+		KID:   pubKeyProto.KID,
+		X:     pubKeyProto.X,
+		Y:     pubKeyProto.Y,
+		Curve: pubKeyProto.Params.KwParams.CurveType.String(),
+		Type:  pubKeyProto.Params.KwParams.KeyType.String(),
+	}, nil
+}
+
+// primaryPublicKey returns the primary public key proto of the ECDH-ES keyset handle kh, kh can be either a private or
+// a public keyset handle.
+func primaryPublicKey(kh *keyset.Handle) (*ecdhespb.EcdhesAeadPublicKey, error) {
+	if kh == nil {
+		return nil, errors.New("keyset handle is nil")
+	}
+
+	pubKH, err := kh.Public()
+	if err != nil {
+		// kh is not a private keyset handle, it may already be an ECDH-ES public keyset handle
+		pubKH = kh
+	}
+
+	ksWriter := &publicKeysetWriter{}
+
+	err = pubKH.WriteWithNoSecrets(ksWriter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public keyset: %w", err)
+	}
+
+	var primaryKeyData *tinkpb.KeyData
+
+	for _, key := range ksWriter.ks.Key {
+		if key.KeyId == ksWriter.ks.PrimaryKeyId {
+			primaryKeyData = key.KeyData
+		}
+	}
+
+	if primaryKeyData == nil || primaryKeyData.TypeUrl != ecdhesAESPublicKeyTypeURL {
+		return nil, fmt.Errorf("not an ECDH-ES key: '%s'", primaryKeyData.GetTypeUrl())
+	}
+
+	pubKeyProto := new(ecdhespb.EcdhesAeadPublicKey)
+
+	err = proto.Unmarshal(primaryKeyData.Value, pubKeyProto)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal key failed: %w", err)
+	}
+
+	return pubKeyProto, nil
+}
+
+// publicKeysetWriter is a keyset.Writer capturing the public keyset written to it.
+type publicKeysetWriter struct {
+	ks *tinkpb.Keyset
+}
+
+// Write captures the public keyset ks.
+func (w *publicKeysetWriter) Write(ks *tinkpb.Keyset) error {
+	w.ks = ks
+
+	return nil
+}
+
+// WriteEncrypted is not supported by publicKeysetWriter.
+func (w *publicKeysetWriter) WriteEncrypted(_ *tinkpb.EncryptedKeyset) error {
+	return errors.New("write encrypted keyset is not supported")
+}
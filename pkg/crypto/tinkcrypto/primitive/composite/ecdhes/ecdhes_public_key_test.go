@@ -0,0 +1,93 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ecdhes
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/keyset"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/ecdh1pu"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/keyio"
+)
+
+func TestPublicKeyFromKeysetHandle(t *testing.T) {
+	recKH, err := keyset.NewHandle(ECDHES256KWAES256GCMKeyTemplate())
+	require.NoError(t, err)
+
+	t.Run("extract public key from private keyset handle", func(t *testing.T) {
+		pubKey, err := PublicKeyFromKeysetHandle(recKH)
+		require.NoError(t, err)
+		require.Equal(t, "NIST_P256", pubKey.Curve)
+		require.Equal(t, "EC", pubKey.Type)
+		require.NotEmpty(t, pubKey.X)
+		require.NotEmpty(t, pubKey.Y)
+
+		expectedPubKey, err := keyio.ExtractPrimaryPublicKey(recKH)
+		require.NoError(t, err)
+		require.Equal(t, expectedPubKey, pubKey)
+
+		// the public key is a valid recipient key for encryption
+		kt, err := ECDHES256KWAES256GCMKeyTemplateWithRecipients([]*composite.PublicKey{pubKey})
+		require.NoError(t, err)
+
+		senderKH, err := keyset.NewHandle(kt)
+		require.NoError(t, err)
+
+		senderPubKH, err := senderKH.Public()
+		require.NoError(t, err)
+
+		e, err := NewECDHESEncrypt(senderPubKH)
+		require.NoError(t, err)
+
+		// single recipient encryption requires a base64URL encoded JSON aad
+		aad := []byte(base64.RawURLEncoding.EncodeToString([]byte(`{"enc":"A256GCM"}`)))
+
+		ct, err := e.Encrypt([]byte("secret message"), aad)
+		require.NoError(t, err)
+		require.NotEmpty(t, ct)
+	})
+
+	t.Run("extract public key from public keyset handle", func(t *testing.T) {
+		recPubKH, err := recKH.Public()
+		require.NoError(t, err)
+
+		pubKey, err := PublicKeyFromKeysetHandle(recPubKH)
+		require.NoError(t, err)
+
+		expectedPubKey, err := PublicKeyFromKeysetHandle(recKH)
+		require.NoError(t, err)
+		require.Equal(t, expectedPubKey, pubKey)
+	})
+
+	t.Run("fail with nil keyset handle", func(t *testing.T) {
+		_, err := PublicKeyFromKeysetHandle(nil)
+		require.EqualError(t, err, "ecdhes: PublicKeyFromKeysetHandle: keyset handle is nil")
+	})
+
+	t.Run("fail with symmetric keyset handle", func(t *testing.T) {
+		kh, err := keyset.NewHandle(aead.AES256GCMKeyTemplate())
+		require.NoError(t, err)
+
+		_, err = PublicKeyFromKeysetHandle(kh)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "ecdhes: PublicKeyFromKeysetHandle: failed to read public keyset")
+	})
+
+	t.Run("fail with ECDH-1PU keyset handle", func(t *testing.T) {
+		kh, err := keyset.NewHandle(ecdh1pu.ECDH1PU256KWAES256GCMKeyTemplate())
+		require.NoError(t, err)
+
+		_, err = PublicKeyFromKeysetHandle(kh)
+		require.EqualError(t, err, "ecdhes: PublicKeyFromKeysetHandle: not an ECDH-ES key: "+
+			"'type.hyperledger.org/hyperledger.aries.crypto.tink.Ecdh1puAesAeadPublicKey'")
+	})
+}
@@ -6,4 +6,56 @@ SPDX-License-Identifier: Apache-2.0
 
 package ecdhes
 
-// TODO implement
+import (
+	"crypto/rand"
+	"fmt"
+
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+	"golang.org/x/crypto/curve25519"
+
+	compositepb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
+	ecdhespb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/ecdhes_aead_go_proto"
+)
+
+// OKP keys are handled by the ECDH-ES AES private and public key managers, their KW params set the OKP key type with
+// the Curve25519 curve. The key managers then use X25519 key agreement instead of the NIST P curves ECDH.
+
+// isOKPKey returns true if the given KW params are for an OKP key.
+func isOKPKey(kwParams *ecdhespb.EcdhesKwParams) bool {
+	return kwParams.KeyType == compositepb.KeyType_OKP
+}
+
+// validateOKPCurve validates the curve of an OKP key, X25519 is the only supported key agreement.
+func validateOKPCurve(curveType commonpb.EllipticCurveType) error {
+	if curveType != commonpb.EllipticCurveType_CURVE25519 {
+		return fmt.Errorf("curve %s not supported for OKP keys", curveType)
+	}
+
+	return nil
+}
+
+// newX25519PrivateKey generates a new X25519 private key with the given params. The public key is stored in X, Y
+// remains empty.
+func newX25519PrivateKey(params *ecdhespb.EcdhesAeadParams) (*ecdhespb.EcdhesAeadPrivateKey, error) {
+	pvt := make([]byte, curve25519.ScalarSize)
+
+	_, err := rand.Read(pvt)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes_aes_private_key_manager: failed to generate X25519 key: %w", err)
+	}
+
+	pub, err := curve25519.X25519(pvt, curve25519.Basepoint)
+	if err != nil {
+		return nil, fmt.Errorf("ecdhes_aes_private_key_manager: failed to generate X25519 key: %w", err)
+	}
+
+	return &ecdhespb.EcdhesAeadPrivateKey{
+		Version:  ecdhesAESPrivateKeyVersion,
+		KeyValue: pvt,
+		PublicKey: &ecdhespb.EcdhesAeadPublicKey{
+			Version: ecdhesAESPrivateKeyVersion,
+			Params:  params,
+			X:       pub,
+		},
+	}, nil
+}
@@ -7,13 +7,18 @@ SPDX-License-Identifier: Apache-2.0
 package subtle
 
 import (
+	"crypto/elliptic"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 
 	hybrid "github.com/google/tink/go/hybrid/subtle"
 
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/api"
 	commonpb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 )
 
 // package subtle provides the core crypto primitives to be used by ECDH-ES composite primitives. It is intended for
@@ -26,30 +31,73 @@ type ECDHESAEADCompositeDecrypt struct {
 	pointFormat string
 	encHelper   composite.EncrypterHelper
 	keyType     commonpb.KeyType
+	kwAlg       string
+	// kid identifies the recipient key, recipients with the same KID are unwrapped first
+	kid string
+	// x25519PrivateKey is set instead of privateKey for OKP recipient keys
+	x25519PrivateKey []byte
+	// algID, apu and apv are the Concat KDF OtherInfo inputs, algID defaults to the recipient's Alg when empty
+	algID string
+	apu   []byte
+	apv   []byte
+	// aadPrefix is prepended to the AAD of the content decryption
+	aadPrefix []byte
 }
 
+var _ api.CompositeDecrypt = (*ECDHESAEADCompositeDecrypt)(nil)
+
+var _ api.KIDProvider = (*ECDHESAEADCompositeDecrypt)(nil)
+
+var _ api.CompositeStreamingDecrypt = (*ECDHESAEADCompositeDecrypt)(nil)
+
 // NewECDHESAEADCompositeDecrypt returns ECDH-ES composite decryption construct with Concat KDF/ECDH-ES key unwrapping
-// and AEAD payload decryption.
+// and AEAD payload decryption. kwAlg is the ECDH-ES key wrapping algorithm (eg A256KWAlg) of the recipient key and
+// kid its optional key ID. algID, apu and apv are the Concat KDF OtherInfo inputs and aadPrefix the prefix of the AAD
+// of the content decryption, they must match the ones used by the sender.
 func NewECDHESAEADCompositeDecrypt(pvt *hybrid.ECPrivateKey, ptFormat string, encHelper composite.EncrypterHelper,
-	keyType commonpb.KeyType) *ECDHESAEADCompositeDecrypt {
+	keyType commonpb.KeyType, kwAlg, kid, algID string, apu, apv, aadPrefix []byte) *ECDHESAEADCompositeDecrypt {
 	return &ECDHESAEADCompositeDecrypt{
 		privateKey:  pvt,
 		pointFormat: ptFormat,
 		encHelper:   encHelper,
 		keyType:     keyType,
+		kwAlg:       kwAlg,
+		kid:         kid,
+		algID:       algID,
+		apu:         apu,
+		apv:         apv,
+		aadPrefix:   aadPrefix,
 	}
 }
 
+// NewECDHESX25519AEADCompositeDecrypt returns ECDH-ES composite decryption construct with Concat KDF/X25519 key
+// unwrapping and AEAD payload decryption for OKP recipient keys.
+func NewECDHESX25519AEADCompositeDecrypt(pvt []byte, encHelper composite.EncrypterHelper,
+	kwAlg, kid, algID string, apu, apv, aadPrefix []byte) *ECDHESAEADCompositeDecrypt {
+	return &ECDHESAEADCompositeDecrypt{
+		x25519PrivateKey: pvt,
+		encHelper:        encHelper,
+		keyType:          commonpb.KeyType_OKP,
+		kwAlg:            kwAlg,
+		kid:              kid,
+		algID:            algID,
+		apu:              apu,
+		apv:              apv,
+		aadPrefix:        aadPrefix,
+	}
+}
+
+// KID returns the key ID of the recipient key, it is empty if the key has no KID.
+func (d *ECDHESAEADCompositeDecrypt) KID() string {
+	return d.kid
+}
+
 // Decrypt using composite ECDH-ES with a Concat KDF key unwrap and AEAD content decryption
 func (d *ECDHESAEADCompositeDecrypt) Decrypt(ciphertext, aad []byte) ([]byte, error) {
-	if d.privateKey == nil {
+	if d.privateKey == nil && len(d.x25519PrivateKey) == 0 {
 		return nil, fmt.Errorf("ECDHESAEADCompositeDecrypt: missing recipient private key for key unwrapping")
 	}
 
-	keySize := d.encHelper.GetSymmetricKeySize()
-
-	var cek []byte
-
 	encData := new(composite.EncryptedData)
 
 	err := json.Unmarshal(ciphertext, encData)
@@ -57,31 +105,21 @@ func (d *ECDHESAEADCompositeDecrypt) Decrypt(ciphertext, aad []byte) ([]byte, er
 		return nil, err
 	}
 
-	// TODO: add support for Chacha content encryption https://github.com/hyperledger/aries-framework-go/issues/1684
 	switch d.keyType {
-	case commonpb.KeyType_EC:
-		if encData.EncAlg != A256GCM {
+	case commonpb.KeyType_EC, commonpb.KeyType_OKP:
+		if encData.EncAlg != d.encHelper.GetEncAlg() {
 			return nil, fmt.Errorf("invalid content encryption algorihm '%s' for Decrypt()", encData.EncAlg)
 		}
 	default:
 		return nil, fmt.Errorf("invalid key type '%s' for Decrypt()", d.keyType)
 	}
 
-	for _, rec := range encData.Recipients {
-		recipientKW := &ECDHESConcatKDFRecipientKW{
-			recipientPrivateKey: d.privateKey,
-		}
-
-		// TODO: add support for 25519 key unwrapping https://github.com/hyperledger/aries-framework-go/issues/1637
-		cek, err = recipientKW.unwrapKey(rec, keySize)
-		if err == nil {
-			break
-		}
+	cek, rec, recErrs, err := d.unwrapCEK(encData.Recipients)
+	if err != nil {
+		return nil, err
 	}
 
-	if cek == nil {
-		return nil, fmt.Errorf("ecdh-es decrypt: cek unwrap failed for all recipients keys")
-	}
+	defer cryptoutil.Zeroize(cek)
 
 	aead, err := d.encHelper.GetAEAD(cek)
 	if err != nil {
@@ -90,5 +128,102 @@ func (d *ECDHESAEADCompositeDecrypt) Decrypt(ciphertext, aad []byte) ([]byte, er
 
 	finalCT := d.encHelper.BuildDecData(encData)
 
-	return aead.Decrypt(finalCT, aad)
+	pt, err := aead.Decrypt(finalCT, prefixAAD(d.aadPrefix, aad))
+	if err != nil {
+		recErrs = append(recErrs, &composite.RecipientError{
+			KID:    rec.KID,
+			Reason: composite.RecipientTagInvalid,
+			Err:    err,
+		})
+
+		return nil, composite.NewKeyDecryptError(d.kid, recErrs, err)
+	}
+
+	return pt, nil
+}
+
+// DecryptReader using composite ECDH-ES with a Concat KDF key unwrap and AES256-GCM-HKDF streaming content
+// decryption. It reads the stream header from r, the returned Reader decrypts the remaining content of r.
+func (d *ECDHESAEADCompositeDecrypt) DecryptReader(r io.Reader, aad []byte) (io.Reader, error) {
+	if d.privateKey == nil && len(d.x25519PrivateKey) == 0 {
+		return nil, fmt.Errorf("ECDHESAEADCompositeDecrypt: missing recipient private key for key unwrapping")
+	}
+
+	encData, _, err := composite.ReadStreamHeader(r)
+	if err != nil {
+		return nil, fmt.Errorf("ECDHESAEADCompositeDecrypt: %w", err)
+	}
+
+	switch d.keyType {
+	case commonpb.KeyType_EC, commonpb.KeyType_OKP:
+		if encData.EncAlg != composite.AES256GCMHKDFStream {
+			return nil, fmt.Errorf("invalid content encryption algorihm '%s' for DecryptReader()", encData.EncAlg)
+		}
+	default:
+		return nil, fmt.Errorf("invalid key type '%s' for DecryptReader()", d.keyType)
+	}
+
+	cek, _, _, err := d.unwrapCEK(encData.Recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	// the streaming AEAD derives the segment keys from its own copy of the CEK
+	defer cryptoutil.Zeroize(cek)
+
+	sa, err := composite.NewStreamingAEAD(cek)
+	if err != nil {
+		return nil, fmt.Errorf("ECDHESAEADCompositeDecrypt: %w", err)
+	}
+
+	return sa.NewDecryptingReader(r, prefixAAD(d.aadPrefix, aad))
+}
+
+// unwrapCEK unwraps the CEK from the first recipient wrapped key that can be unwrapped with the recipient private key.
+// It returns the CEK with its recipient and the errors of the recipients tried before, or a composite.KeyDecryptError
+// recording the failure of every recipient.
+func (d *ECDHESAEADCompositeDecrypt) unwrapCEK(recipients []*composite.RecipientWrappedKey) ([]byte,
+	*composite.RecipientWrappedKey, []*composite.RecipientError, error) {
+	kekSize, err := kwKeySize(d.kwAlg)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("ECDHESAEADCompositeDecrypt: %w", err)
+	}
+
+	var recErrs []*composite.RecipientError
+
+	for _, rec := range composite.SortRecipientsByKID(recipients, d.kid) {
+		// skip recipients wrapped with a different algorithm than the one of the recipient key
+		if rec.Alg != d.kwAlg {
+			recErrs = append(recErrs, &composite.RecipientError{KID: rec.KID, Reason: composite.RecipientAlgMismatch})
+
+			continue
+		}
+
+		recipientKW := &ECDHESConcatKDFRecipientKW{
+			recipientPrivateKey:       d.privateKey,
+			recipientX25519PrivateKey: d.x25519PrivateKey,
+			algID:                     d.algID,
+			apu:                       d.apu,
+			apv:                       d.apv,
+		}
+
+		cek, err := recipientKW.unwrapKey(rec, kekSize)
+		if err == nil {
+			return cek, rec, recErrs, nil
+		}
+
+		recErrs = append(recErrs, composite.NewRecipientError(rec, d.kid, d.curve(), err))
+	}
+
+	return nil, nil, nil, composite.NewKeyDecryptError(d.kid, recErrs,
+		errors.New("ecdh-es decrypt: cek unwrap failed for all recipients keys"))
+}
+
+// curve returns the curve of the recipient private key, it is nil for X25519 keys.
+func (d *ECDHESAEADCompositeDecrypt) curve() elliptic.Curve {
+	if d.privateKey == nil {
+		return nil
+	}
+
+	return d.privateKey.PublicKey.Curve
 }
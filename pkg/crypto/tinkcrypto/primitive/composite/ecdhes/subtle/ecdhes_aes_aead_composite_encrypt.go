@@ -8,12 +8,14 @@ package subtle
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/google/tink/go/subtle/random"
 
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/api"
 	commonpb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 )
 
 // A256GCM is the default content encryption algorithm value as per
@@ -27,19 +29,37 @@ type ECDHESAEADCompositeEncrypt struct {
 	pointFormat   string
 	encHelper     composite.EncrypterHelper
 	keyType       commonpb.KeyType
+	kwAlg         string
+	// algID, apu and apv are the Concat KDF OtherInfo inputs, algID defaults to kwAlg when empty
+	algID string
+	apu   []byte
+	apv   []byte
+	// aadPrefix is prepended to the AAD of the content encryption
+	aadPrefix []byte
 }
 
 var _ api.CompositeEncrypt = (*ECDHESAEADCompositeEncrypt)(nil)
 
+var _ api.CompositeStreamingEncrypt = (*ECDHESAEADCompositeEncrypt)(nil)
+
 // NewECDHESAEADCompositeEncrypt returns ECDH-ES encryption construct with Concat KDF key wrapping
-// and AEAD content encryption
+// and AEAD content encryption. kwAlg is the ECDH-ES key wrapping algorithm (eg A256KWAlg). algID, apu and apv are the
+// Concat KDF OtherInfo AlgorithmID, PartyUInfo and PartyVInfo as per https://tools.ietf.org/html/rfc7518#section-4.6.2,
+// algID defaults to kwAlg when empty. aadPrefix is prepended to the AAD of the content encryption, ie a label binding
+// the ciphertexts to a protocol, the recipients must decrypt with the same prefix.
 func NewECDHESAEADCompositeEncrypt(recipientsKeys []*composite.PublicKey, ptFormat string,
-	encHelper composite.EncrypterHelper, keyType commonpb.KeyType) *ECDHESAEADCompositeEncrypt {
+	encHelper composite.EncrypterHelper, keyType commonpb.KeyType, kwAlg, algID string,
+	apu, apv, aadPrefix []byte) *ECDHESAEADCompositeEncrypt {
 	return &ECDHESAEADCompositeEncrypt{
 		recPublicKeys: recipientsKeys,
 		pointFormat:   ptFormat,
 		encHelper:     encHelper,
 		keyType:       keyType,
+		kwAlg:         kwAlg,
+		algID:         algID,
+		apu:           apu,
+		apv:           apv,
+		aadPrefix:     aadPrefix,
 	}
 }
 
@@ -49,46 +69,33 @@ func (e *ECDHESAEADCompositeEncrypt) Encrypt(plaintext, aad []byte) ([]byte, err
 		return nil, fmt.Errorf("ECDHESAEADCompositeEncrypt: missing recipients public keys for key wrapping")
 	}
 
-	var eAlg, kwAlg string
+	var eAlg string
 
-	// TODO add chacha alg support too, https://github.com/hyperledger/aries-framework-go/issues/1684
 	switch e.keyType {
-	case commonpb.KeyType_EC:
-		eAlg = A256GCM
-		kwAlg = A256KWAlg
+	case commonpb.KeyType_EC, commonpb.KeyType_OKP:
+		eAlg = e.encHelper.GetEncAlg()
 	default:
 		return nil, fmt.Errorf("ECDHESAEADCompositeEncrypt: bad key type: '%s'", e.keyType)
 	}
 
 	keySize := e.encHelper.GetSymmetricKeySize()
 	cek := random.GetRandomBytes(uint32(keySize))
+	defer cryptoutil.Zeroize(cek)
 
-	var recipientsWK []*composite.RecipientWrappedKey
+	recipientsWK, err := e.wrapCEK(cek)
+	if err != nil {
+		return nil, err
+	}
 
 	var singleRecipientAAD []byte
 
-	for _, rec := range e.recPublicKeys {
-		senderKW := &ECDHESConcatKDFSenderKW{
-			recipientPublicKey: rec,
-			cek:                cek,
-		}
-
-		// TODO: add support for 25519 key wrapping https://github.com/hyperledger/aries-framework-go/issues/1637
-		kek, err := senderKW.wrapKey(kwAlg, keySize)
+	if len(recipientsWK) == 1 {
+		singleRecipientAAD, err = e.encHelper.MergeSingleRecipientHeaders(recipientsWK[0], aad)
 		if err != nil {
 			return nil, err
 		}
 
-		recipientsWK = append(recipientsWK, kek)
-
-		if len(e.recPublicKeys) == 1 {
-			singleRecipientAAD, err = e.encHelper.MergeSingleRecipientHeaders(kek, aad)
-			if err != nil {
-				return nil, err
-			}
-
-			aad = singleRecipientAAD
-		}
+		aad = singleRecipientAAD
 	}
 
 	aead, err := e.encHelper.GetAEAD(cek)
@@ -96,10 +103,91 @@ func (e *ECDHESAEADCompositeEncrypt) Encrypt(plaintext, aad []byte) ([]byte, err
 		return nil, err
 	}
 
-	ct, err := aead.Encrypt(plaintext, aad)
+	// the prefix isn't part of the single recipient AAD returned to the caller, it is only mixed in the encryption
+	ct, err := aead.Encrypt(plaintext, prefixAAD(e.aadPrefix, aad))
 	if err != nil {
 		return nil, err
 	}
 
 	return e.encHelper.BuildEncData(eAlg, recipientsWK, ct, singleRecipientAAD)
 }
+
+// EncryptWriter using composite ECDH-ES with a Concat KDF key wrap and AES256-GCM-HKDF streaming content encryption.
+// The CEK is wrapped once for all the recipients in the stream header written to w, the content encryption key
+// template of the primitive is not used for streams.
+func (e *ECDHESAEADCompositeEncrypt) EncryptWriter(w io.Writer, aad []byte) (io.WriteCloser, error) {
+	if len(e.recPublicKeys) == 0 {
+		return nil, fmt.Errorf("ECDHESAEADCompositeEncrypt: missing recipients public keys for key wrapping")
+	}
+
+	switch e.keyType {
+	case commonpb.KeyType_EC, commonpb.KeyType_OKP:
+	default:
+		return nil, fmt.Errorf("ECDHESAEADCompositeEncrypt: bad key type: '%s'", e.keyType)
+	}
+
+	cek := random.GetRandomBytes(composite.StreamCEKSize)
+	// the streaming AEAD derives the segment keys from its own copy of the CEK
+	defer cryptoutil.Zeroize(cek)
+
+	recipientsWK, err := e.wrapCEK(cek)
+	if err != nil {
+		return nil, err
+	}
+
+	sa, err := composite.NewStreamingAEAD(cek)
+	if err != nil {
+		return nil, fmt.Errorf("ECDHESAEADCompositeEncrypt: %w", err)
+	}
+
+	err = composite.WriteStreamHeader(w, &composite.EncryptedData{
+		EncAlg:     composite.AES256GCMHKDFStream,
+		Recipients: recipientsWK,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ECDHESAEADCompositeEncrypt: %w", err)
+	}
+
+	return sa.NewEncryptingWriter(w, prefixAAD(e.aadPrefix, aad))
+}
+
+// prefixAAD returns aad prepended with the length prefixed prefix, aad is returned as is when prefix is empty. The
+// length of prefix keeps the prefix and aad boundary unambiguous: different splits of the same bytes between prefix
+// and aad result in different AADs.
+func prefixAAD(prefix, aad []byte) []byte {
+	if len(prefix) == 0 {
+		return aad
+	}
+
+	return append(cryptoutil.LengthPrefix(prefix), aad...)
+}
+
+// wrapCEK wraps cek for each recipient public key.
+func (e *ECDHESAEADCompositeEncrypt) wrapCEK(cek []byte) ([]*composite.RecipientWrappedKey, error) {
+	kekSize, err := kwKeySize(e.kwAlg)
+	if err != nil {
+		return nil, fmt.Errorf("ECDHESAEADCompositeEncrypt: %w", err)
+	}
+
+	var recipientsWK []*composite.RecipientWrappedKey
+
+	for _, rec := range e.recPublicKeys {
+		senderKW := &ECDHESConcatKDFSenderKW{
+			recipientPublicKey: rec,
+			cek:                cek,
+			pointFormat:        e.pointFormat,
+			algID:              e.algID,
+			apu:                e.apu,
+			apv:                e.apv,
+		}
+
+		kek, err := senderKW.wrapKey(e.kwAlg, kekSize)
+		if err != nil {
+			return nil, err
+		}
+
+		recipientsWK = append(recipientsWK, kek)
+	}
+
+	return recipientsWK, nil
+}
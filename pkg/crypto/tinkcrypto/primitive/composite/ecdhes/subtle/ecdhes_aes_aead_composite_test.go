@@ -7,9 +7,12 @@ SPDX-License-Identifier: Apache-2.0
 package subtle
 
 import (
+	"crypto/elliptic"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/google/tink/go/aead"
@@ -34,10 +37,11 @@ func TestEncryptDecrypt(t *testing.T) {
 		AEADValue:    aeadPrimitive,
 		TagSizeValue: subtleaead.AESGCMTagSize,
 		IVSizeValue:  subtleaead.AESGCMIVSize,
+		EncAlgValue:  A256GCM,
 	}
 
 	cEnc := NewECDHESAEADCompositeEncrypt(recipientsPubKeys, commonpb.EcPointFormat_UNCOMPRESSED.String(),
-		mEncHelper, compositepb.KeyType_EC)
+		mEncHelper, compositepb.KeyType_EC, A256KWAlg, "", nil, nil, nil)
 
 	pt := []byte("secret message")
 	aad := []byte("aad message")
@@ -47,7 +51,7 @@ func TestEncryptDecrypt(t *testing.T) {
 
 	for _, privKey := range recipientsPrivKeys {
 		dEnc := NewECDHESAEADCompositeDecrypt(privKey, commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper,
-			compositepb.KeyType_EC)
+			compositepb.KeyType_EC, A256KWAlg, "", "", nil, nil, nil)
 
 		dpt, err := dEnc.Decrypt(ct, aad)
 		require.NoError(t, err)
@@ -55,6 +59,233 @@ func TestEncryptDecrypt(t *testing.T) {
 	}
 }
 
+func TestEncryptDecryptWithAADPrefix(t *testing.T) {
+	recipientsPrivKeys, recipientsPubKeys := buildRecipientsKeys(t, 2)
+	aeadPrimitive := getAEADPrimitive(t, aead.AES256GCMKeyTemplate())
+
+	mEncHelper := &MockEncHelper{
+		KeySizeValue: 32,
+		AEADValue:    aeadPrimitive,
+		TagSizeValue: subtleaead.AESGCMTagSize,
+		IVSizeValue:  subtleaead.AESGCMIVSize,
+		EncAlgValue:  A256GCM,
+	}
+
+	cEnc := NewECDHESAEADCompositeEncrypt(recipientsPubKeys, commonpb.EcPointFormat_UNCOMPRESSED.String(),
+		mEncHelper, compositepb.KeyType_EC, A256KWAlg, "", nil, nil, []byte("didcomm-a"))
+
+	pt := []byte("secret message")
+
+	ct, err := cEnc.Encrypt(pt, []byte("non-aad"))
+	require.NoError(t, err)
+
+	dEnc := NewECDHESAEADCompositeDecrypt(recipientsPrivKeys[0], commonpb.EcPointFormat_UNCOMPRESSED.String(),
+		mEncHelper, compositepb.KeyType_EC, A256KWAlg, "", "", nil, nil, []byte("didcomm-a"))
+
+	dpt, err := dEnc.Decrypt(ct, []byte("non-aad"))
+	require.NoError(t, err)
+	require.EqualValues(t, pt, dpt)
+
+	// another split of the same prefix and AAD bytes is a different AAD
+	otherDEnc := NewECDHESAEADCompositeDecrypt(recipientsPrivKeys[0], commonpb.EcPointFormat_UNCOMPRESSED.String(),
+		mEncHelper, compositepb.KeyType_EC, A256KWAlg, "", "", nil, nil, []byte("didcomm-anon"))
+
+	_, err = otherDEnc.Decrypt(ct, []byte("-aad"))
+	require.Error(t, err)
+
+	require.NotEqual(t, prefixAAD([]byte("didcomm-a"), []byte("non-aad")),
+		prefixAAD([]byte("didcomm-anon"), []byte("-aad")))
+	require.Equal(t, []byte("aad"), prefixAAD(nil, []byte("aad")))
+}
+
+func TestEncryptDecryptWithRegisteredCurve(t *testing.T) {
+	params := &elliptic.CurveParams{Name: "brainpoolP256t1", BitSize: 256}
+	params.P, _ = new(big.Int).SetString("A9FB57DBA1EEA9BC3E660A909D838D726E3BF623D52620282013481D1F6E5377", 16)
+	params.N, _ = new(big.Int).SetString("A9FB57DBA1EEA9BC3E660A909D838D718C397AA3B561A6F7901E0E82974856A7", 16)
+	params.B, _ = new(big.Int).SetString("662C61C430D84EA4FE66A7733D0B76B7BF93EBC4AF2F49256AE58101FEE92B04", 16)
+	params.Gx, _ = new(big.Int).SetString("A3E8EB3CC1CFE7B7732213B23A656149AFA142C47AAFBC2B79A191562E1305F4", 16)
+	params.Gy, _ = new(big.Int).SetString("2D996C823439C56D7F7B22E14644417E69BCB6DE39D027001DABE8F35B25C9BE", 16)
+
+	require.NoError(t, composite.RegisterCurve(params.Name, commonpb.EllipticCurveType(100), params))
+
+	var (
+		recipientsPrivKeys []*hybrid.ECPrivateKey
+		recipientsPubKeys  []*composite.PublicKey
+	)
+
+	// with multiple recipients the AAD isn't merged with the recipient headers
+	for i := 0; i < 2; i++ {
+		recipientPriv, err := hybrid.GenerateECDHKeyPair(params)
+		require.NoError(t, err)
+
+		recipientsPrivKeys = append(recipientsPrivKeys, recipientPriv)
+		recipientsPubKeys = append(recipientsPubKeys, &composite.PublicKey{
+			Type:  compositepb.KeyType_EC.String(),
+			Curve: params.Name,
+			X:     recipientPriv.PublicKey.Point.X.Bytes(),
+			Y:     recipientPriv.PublicKey.Point.Y.Bytes(),
+		})
+	}
+
+	mEncHelper := &MockEncHelper{
+		KeySizeValue: 32,
+		AEADValue:    getAEADPrimitive(t, aead.AES256GCMKeyTemplate()),
+		TagSizeValue: subtleaead.AESGCMTagSize,
+		IVSizeValue:  subtleaead.AESGCMIVSize,
+		EncAlgValue:  A256GCM,
+	}
+
+	cEnc := NewECDHESAEADCompositeEncrypt(recipientsPubKeys, commonpb.EcPointFormat_UNCOMPRESSED.String(),
+		mEncHelper, compositepb.KeyType_EC, A256KWAlg, "", nil, nil, nil)
+
+	pt := []byte("secret message")
+	aad := []byte("aad message")
+
+	ct, err := cEnc.Encrypt(pt, aad)
+	require.NoError(t, err)
+
+	for _, privKey := range recipientsPrivKeys {
+		dEnc := NewECDHESAEADCompositeDecrypt(privKey, commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper,
+			compositepb.KeyType_EC, A256KWAlg, "", "", nil, nil, nil)
+
+		dpt, err := dEnc.Decrypt(ct, aad)
+		require.NoError(t, err)
+		require.EqualValues(t, pt, dpt)
+	}
+}
+
+func TestEncryptDecryptZeroizesCEK(t *testing.T) {
+	recipientsPrivKeys, recipientsPubKeys := buildRecipientsKeys(t, 2)
+
+	mEncHelper := &MockEncHelper{
+		KeySizeValue: 32,
+		AEADValue:    getAEADPrimitive(t, aead.AES256GCMKeyTemplate()),
+		TagSizeValue: subtleaead.AESGCMTagSize,
+		IVSizeValue:  subtleaead.AESGCMIVSize,
+		EncAlgValue:  A256GCM,
+	}
+
+	cEnc := NewECDHESAEADCompositeEncrypt(recipientsPubKeys, commonpb.EcPointFormat_UNCOMPRESSED.String(),
+		mEncHelper, compositepb.KeyType_EC, A256KWAlg, "", nil, nil, nil)
+
+	ct, err := cEnc.Encrypt([]byte("secret message"), []byte("aad message"))
+	require.NoError(t, err)
+
+	dEnc := NewECDHESAEADCompositeDecrypt(recipientsPrivKeys[0], commonpb.EcPointFormat_UNCOMPRESSED.String(),
+		mEncHelper, compositepb.KeyType_EC, A256KWAlg, "", "", nil, nil, nil)
+
+	_, err = dEnc.Decrypt(ct, []byte("aad message"))
+	require.NoError(t, err)
+
+	// the CEKs generated by Encrypt and unwrapped by Decrypt are wiped once they return
+	require.Len(t, mEncHelper.CEKs, 2)
+
+	for _, cek := range mEncHelper.CEKs {
+		require.Equal(t, make([]byte, 32), cek)
+	}
+}
+
+func TestEncryptDecryptWithKIDs(t *testing.T) {
+	recipientsPrivKeys, recipientsPubKeys := buildRecipientsKeys(t, 3)
+	aeadPrimitive := getAEADPrimitive(t, aead.AES256GCMKeyTemplate())
+
+	mEncHelper := &MockEncHelper{
+		KeySizeValue: 32,
+		AEADValue:    aeadPrimitive,
+		TagSizeValue: subtleaead.AESGCMTagSize,
+		IVSizeValue:  subtleaead.AESGCMIVSize,
+		EncAlgValue:  A256GCM,
+	}
+
+	for i, pubKey := range recipientsPubKeys {
+		pubKey.KID = fmt.Sprintf("kid-%d", i)
+	}
+
+	cEnc := NewECDHESAEADCompositeEncrypt(recipientsPubKeys, commonpb.EcPointFormat_UNCOMPRESSED.String(),
+		mEncHelper, compositepb.KeyType_EC, A256KWAlg, "", nil, nil, nil)
+
+	pt := []byte("secret message")
+	aad := []byte("aad message")
+
+	ct, err := cEnc.Encrypt(pt, aad)
+	require.NoError(t, err)
+
+	encData := &composite.EncryptedData{}
+	require.NoError(t, json.Unmarshal(ct, encData))
+	require.Len(t, encData.Recipients, len(recipientsPubKeys))
+
+	for i, rec := range encData.Recipients {
+		require.Equal(t, recipientsPubKeys[i].KID, rec.KID)
+	}
+
+	for i, privKey := range recipientsPrivKeys {
+		// a recipient key identified differently by the sender can still unwrap its key
+		for _, kid := range []string{recipientsPubKeys[i].KID, "other-kid"} {
+			dEnc := NewECDHESAEADCompositeDecrypt(privKey, commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper,
+				compositepb.KeyType_EC, A256KWAlg, kid, "", nil, nil, nil)
+
+			dpt, err := dEnc.Decrypt(ct, aad)
+			require.NoError(t, err)
+			require.EqualValues(t, pt, dpt)
+		}
+	}
+}
+
+func TestDecryptRecipientErrors(t *testing.T) {
+	recipientsPrivKeys, recipientsPubKeys := buildRecipientsKeys(t, 3)
+	aeadPrimitive := getAEADPrimitive(t, aead.AES256GCMKeyTemplate())
+
+	mEncHelper := &MockEncHelper{
+		KeySizeValue: 32,
+		AEADValue:    aeadPrimitive,
+		TagSizeValue: subtleaead.AESGCMTagSize,
+		IVSizeValue:  subtleaead.AESGCMIVSize,
+		EncAlgValue:  A256GCM,
+	}
+
+	for i, pubKey := range recipientsPubKeys {
+		pubKey.KID = fmt.Sprintf("kid-%d", i)
+	}
+
+	// encrypt for the first two recipients only
+	cEnc := NewECDHESAEADCompositeEncrypt(recipientsPubKeys[:2], commonpb.EcPointFormat_UNCOMPRESSED.String(),
+		mEncHelper, compositepb.KeyType_EC, A256KWAlg, "", nil, nil, nil)
+
+	ct, err := cEnc.Encrypt([]byte("secret message"), []byte("aad message"))
+	require.NoError(t, err)
+
+	dEnc := NewECDHESAEADCompositeDecrypt(recipientsPrivKeys[2], commonpb.EcPointFormat_UNCOMPRESSED.String(),
+		mEncHelper, compositepb.KeyType_EC, A256KWAlg, "kid-2", "", nil, nil, nil)
+
+	_, err = dEnc.Decrypt(ct, []byte("aad message"))
+	require.EqualError(t, err, "ecdh-es decrypt: cek unwrap failed for all recipients keys")
+
+	keyErr := &composite.KeyDecryptError{}
+	require.True(t, errors.As(err, &keyErr))
+	require.Equal(t, "kid-2", keyErr.KID)
+	require.Len(t, keyErr.Recipients, 2)
+
+	for i, rec := range keyErr.Recipients {
+		require.Equal(t, fmt.Sprintf("kid-%d", i), rec.KID)
+		require.Equal(t, composite.RecipientKIDMismatch, rec.Reason)
+		require.Error(t, rec.Err)
+	}
+
+	require.Contains(t, keyErr.Detail(), "key 'kid-2': ecdh-es decrypt: cek unwrap failed for all recipients keys: [")
+	require.Contains(t, keyErr.Detail(), "recipient 'kid-0': KID mismatch")
+
+	// a recipient with the wrong AAD unwraps the CEK but fails to decrypt the content
+	dEnc = NewECDHESAEADCompositeDecrypt(recipientsPrivKeys[0], commonpb.EcPointFormat_UNCOMPRESSED.String(),
+		mEncHelper, compositepb.KeyType_EC, A256KWAlg, "kid-0", "", nil, nil, nil)
+
+	_, err = dEnc.Decrypt(ct, []byte("other aad"))
+	require.Error(t, err)
+	require.True(t, errors.As(err, &keyErr))
+	require.Len(t, keyErr.Recipients, 1)
+	require.Equal(t, "kid-0", keyErr.Recipients[0].KID)
+	require.Equal(t, composite.RecipientTagInvalid, keyErr.Recipients[0].Reason)
+}
+
 func TestEncryptDecryptNegativeTCs(t *testing.T) {
 	recipientsPrivKeys, recipientsPubKeys := buildRecipientsKeys(t, 10)
 	aeadPrimitive := getAEADPrimitive(t, aead.AES256GCMKeyTemplate())
@@ -64,6 +295,7 @@ func TestEncryptDecryptNegativeTCs(t *testing.T) {
 		AEADValue:    aeadPrimitive,
 		TagSizeValue: subtleaead.AESGCMTagSize,
 		IVSizeValue:  subtleaead.AESGCMIVSize,
+		EncAlgValue:  A256GCM,
 	}
 
 	pt := []byte("secret message")
@@ -71,7 +303,7 @@ func TestEncryptDecryptNegativeTCs(t *testing.T) {
 
 	// test with empty recipients public keys
 	cEnc := NewECDHESAEADCompositeEncrypt(nil, commonpb.EcPointFormat_UNCOMPRESSED.String(),
-		mEncHelper, compositepb.KeyType_EC)
+		mEncHelper, compositepb.KeyType_EC, A256KWAlg, "", nil, nil, nil)
 
 	// Encrypt should fail with empty recipients public keys
 	_, err := cEnc.Encrypt(pt, aad)
@@ -81,14 +313,21 @@ func TestEncryptDecryptNegativeTCs(t *testing.T) {
 	mEncHelper.KeySizeValue = 100
 
 	cEnc = NewECDHESAEADCompositeEncrypt(recipientsPubKeys, commonpb.EcPointFormat_UNCOMPRESSED.String(),
-		mEncHelper, compositepb.KeyType_EC)
+		mEncHelper, compositepb.KeyType_EC, A256KWAlg, "", nil, nil, nil)
 
 	// Encrypt should fail with large AEAD key size value
 	_, err = cEnc.Encrypt(pt, aad)
-	require.EqualError(t, err, "crypto/aes: invalid key size 100")
+	require.EqualError(t, err, "square/go-jose: key wrap input must be 8 byte blocks")
 
 	mEncHelper.KeySizeValue = 32
 
+	// Encrypt should fail with an unsupported key wrapping algorithm
+	cEnc = NewECDHESAEADCompositeEncrypt(recipientsPubKeys, commonpb.EcPointFormat_UNCOMPRESSED.String(),
+		mEncHelper, compositepb.KeyType_EC, "ECDH-ES+BadKW", "", nil, nil, nil)
+
+	_, err = cEnc.Encrypt(pt, aad)
+	require.EqualError(t, err, "ECDHESAEADCompositeEncrypt: unsupported key wrapping algorithm 'ECDH-ES+BadKW'")
+
 	// Encrypt should fail with bad key type
 	cEnc.keyType = compositepb.KeyType_UNKNOWN_KEY_TYPE
 
@@ -102,7 +341,7 @@ func TestEncryptDecryptNegativeTCs(t *testing.T) {
 	mEncHelper.AEADErrValue = fmt.Errorf("error from GetAEAD")
 
 	cEnc = NewECDHESAEADCompositeEncrypt(recipientsPubKeys, commonpb.EcPointFormat_UNCOMPRESSED.String(),
-		mEncHelper, compositepb.KeyType_EC)
+		mEncHelper, compositepb.KeyType_EC, A256KWAlg, "", nil, nil, nil)
 
 	// Encrypt should fail with large AEAD key size value
 	_, err = cEnc.Encrypt(pt, aad)
@@ -112,7 +351,7 @@ func TestEncryptDecryptNegativeTCs(t *testing.T) {
 
 	// create a valid ciphertext to test Decrypt for all recipients
 	cEnc = NewECDHESAEADCompositeEncrypt(recipientsPubKeys, commonpb.EcPointFormat_UNCOMPRESSED.String(),
-		mEncHelper, compositepb.KeyType_EC)
+		mEncHelper, compositepb.KeyType_EC, A256KWAlg, "", nil, nil, nil)
 
 	// test with empty plaintext
 	ct, err := cEnc.Encrypt([]byte{}, aad)
@@ -133,27 +372,31 @@ func TestEncryptDecryptNegativeTCs(t *testing.T) {
 	for _, privKey := range recipientsPrivKeys {
 		// test with nil recipient private key
 		dEnc := NewECDHESAEADCompositeDecrypt(nil, commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper,
-			compositepb.KeyType_EC)
+			compositepb.KeyType_EC, A256KWAlg, "", "", nil, nil, nil)
 
 		_, err = dEnc.Decrypt(ct, aad)
 		require.EqualError(t, err, "ECDHESAEADCompositeDecrypt: missing recipient private key for key"+
 			" unwrapping")
 
-		// test with large key size
-		mEncHelper.KeySizeValue = 100
+		// test with a key wrapping algorithm not matching the one of the recipients
 		dEnc = NewECDHESAEADCompositeDecrypt(privKey, commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper,
-			compositepb.KeyType_EC)
+			compositepb.KeyType_EC, A128KWAlg, "", "", nil, nil, nil)
 
 		_, err = dEnc.Decrypt(ct, aad)
 		require.EqualError(t, err, "ecdh-es decrypt: cek unwrap failed for all recipients keys")
 
-		mEncHelper.KeySizeValue = 32
+		// test with an unsupported key wrapping algorithm
+		dEnc = NewECDHESAEADCompositeDecrypt(privKey, commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper,
+			compositepb.KeyType_EC, "ECDH-ES+BadKW", "", "", nil, nil, nil)
+
+		_, err = dEnc.Decrypt(ct, aad)
+		require.EqualError(t, err, "ECDHESAEADCompositeDecrypt: unsupported key wrapping algorithm 'ECDH-ES+BadKW'")
 
 		// test with GetAEAD() returning error
 		mEncHelper.AEADErrValue = fmt.Errorf("error from GetAEAD")
 
 		dEnc = NewECDHESAEADCompositeDecrypt(privKey, commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper,
-			compositepb.KeyType_EC)
+			compositepb.KeyType_EC, A256KWAlg, "", "", nil, nil, nil)
 
 		_, err = dEnc.Decrypt(ct, aad)
 		require.EqualError(t, err, "error from GetAEAD")
@@ -162,7 +405,7 @@ func TestEncryptDecryptNegativeTCs(t *testing.T) {
 
 		// create a valid Decrypt message and test against ct
 		dEnc = NewECDHESAEADCompositeDecrypt(privKey, commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper,
-			compositepb.KeyType_EC)
+			compositepb.KeyType_EC, A256KWAlg, "", "", nil, nil, nil)
 
 		// try decrypting empty ct
 		_, err = dEnc.Decrypt([]byte{}, aad)
@@ -197,6 +440,7 @@ func TestEncryptDecryptWithSingleRecipient(t *testing.T) {
 		AEADValue:    aeadPrimitive,
 		TagSizeValue: subtleaead.AESGCMTagSize,
 		IVSizeValue:  subtleaead.AESGCMIVSize,
+		EncAlgValue:  A256GCM,
 	}
 
 	pt := []byte("secret message")
@@ -204,7 +448,7 @@ func TestEncryptDecryptWithSingleRecipient(t *testing.T) {
 
 	// test with single recipient public key
 	cEnc := NewECDHESAEADCompositeEncrypt(recipientsPubKeys, commonpb.EcPointFormat_UNCOMPRESSED.String(),
-		mEncHelper, compositepb.KeyType_EC)
+		mEncHelper, compositepb.KeyType_EC, A256KWAlg, "", nil, nil, nil)
 
 	errMsg := "error merge recipient headers"
 	mEncHelper.MergeRecErr = fmt.Errorf(errMsg)
@@ -227,7 +471,7 @@ func TestEncryptDecryptWithSingleRecipient(t *testing.T) {
 
 	for _, privKey := range recipientsPrivKeys {
 		dEnc := NewECDHESAEADCompositeDecrypt(privKey, commonpb.EcPointFormat_UNCOMPRESSED.String(), mEncHelper,
-			compositepb.KeyType_EC)
+			compositepb.KeyType_EC, A256KWAlg, "", "", nil, nil, nil)
 
 		dpt, err := dEnc.Decrypt(ct, encData.SingleRecipientAAD)
 		require.NoError(t, err)
@@ -287,8 +531,11 @@ type MockEncHelper struct {
 	AEADErrValue  error
 	TagSizeValue  int
 	IVSizeValue   int
+	EncAlgValue   string
 	MergeRecValue []byte
 	MergeRecErr   error
+	// CEKs records the symmetric keys of the AEAD primitives
+	CEKs [][]byte
 }
 
 // GetSymmetricKeySize gives the size of the Encryption key (CEK) in bytes
@@ -298,6 +545,8 @@ func (m *MockEncHelper) GetSymmetricKeySize() int {
 
 // GetAEAD returns the newly created AEAD primitive used for the content Encryption
 func (m *MockEncHelper) GetAEAD(symmetricKeyValue []byte) (tink.AEAD, error) {
+	m.CEKs = append(m.CEKs, symmetricKeyValue)
+
 	return m.AEADValue, m.AEADErrValue
 }
 
@@ -311,6 +560,11 @@ func (m *MockEncHelper) GetIVSize() int {
 	return m.IVSizeValue
 }
 
+// GetEncAlg provides the content encryption algorithm value set in the encrypted data
+func (m *MockEncHelper) GetEncAlg() string {
+	return m.EncAlgValue
+}
+
 // BuildEncData will build the []byte representing the ciphertext sent to the end user of the Composite primitive
 func (m *MockEncHelper) BuildEncData(eAlg string, recipientsWK []*composite.RecipientWrappedKey, ct,
 	singleRecipientAAD []byte) ([]byte, error) {
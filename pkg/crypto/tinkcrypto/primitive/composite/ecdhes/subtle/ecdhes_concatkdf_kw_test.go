@@ -7,12 +7,16 @@ SPDX-License-Identifier: Apache-2.0
 package subtle
 
 import (
+	"crypto/aes"
+	"encoding/base64"
 	"testing"
 
 	hybrid "github.com/google/tink/go/hybrid/subtle"
 	commonpb "github.com/google/tink/go/proto/common_go_proto"
 	"github.com/google/tink/go/subtle/random"
+	josecipher "github.com/square/go-jose/v3/cipher"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/curve25519"
 
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
 	compositepb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
@@ -54,4 +58,157 @@ func TestWrap(t *testing.T) {
 	// error test cases
 	_, err = recipientKW.unwrapKey(nil, keySize)
 	require.Error(t, err)
+
+	// a key wrapped for a recipient on another curve
+	otherCurve, err := hybrid.GetCurve(commonpb.EllipticCurveType_NIST_P384.String())
+	require.NoError(t, err)
+
+	otherRecPvt, err := hybrid.GenerateECDHKeyPair(otherCurve)
+	require.NoError(t, err)
+
+	senderKW.recipientPublicKey = &composite.PublicKey{
+		Type:  compositepb.KeyType_EC.String(),
+		Curve: otherRecPvt.PublicKey.Curve.Params().Name,
+		X:     otherRecPvt.PublicKey.Point.X.Bytes(),
+		Y:     otherRecPvt.PublicKey.Point.Y.Bytes(),
+	}
+
+	wrappedKey, err = senderKW.wrapKey(A256KWAlg, keySize)
+	require.NoError(t, err)
+	require.Equal(t, "P-384", wrappedKey.EPK.Curve)
+
+	_, err = recipientKW.unwrapKey(wrappedKey, keySize)
+	require.EqualError(t, err, "unwrapKey: EPK is not on the recipient key curve 'P-256'")
+}
+
+// TestUnwrapRFC7518AppendixC reproduces the ECDH-ES key agreement example of
+// https://tools.ietf.org/html/rfc7518#appendix-C with its custom Concat KDF OtherInfo: AlgorithmID "A128GCM",
+// PartyUInfo "Alice" and PartyVInfo "Bob". The CEK is wrapped with the derived key of the example, unwrapping it proves
+// the recipient derives the same key.
+func TestUnwrapRFC7518AppendixC(t *testing.T) {
+	curve, err := hybrid.GetCurve(commonpb.EllipticCurveType_NIST_P256.String())
+	require.NoError(t, err)
+
+	// Bob's (recipient) private key
+	bobD := b64Decode(t, "VEmDZpDXXK8p8N0Cndsxs924q6nS1RXFASRl6BfUqdw")
+	// Alice's ephemeral public key
+	epk := composite.PublicKey{
+		Type:  compositepb.KeyType_EC.String(),
+		Curve: curve.Params().Name,
+		X:     b64Decode(t, "gI0GAILBdu7T53akrFmMyGcsF3n5dO7MmwNBHKW5SV0"),
+		Y:     b64Decode(t, "SLW_xSffzlPWrHEVI30DHM_4egVwt3NQqeUD7nMFpps"),
+	}
+	// the derived key of the example
+	derivedKey := b64Decode(t, "VqqN6vgjbSBcIijNcacQGg")
+
+	block, err := aes.NewCipher(derivedKey)
+	require.NoError(t, err)
+
+	cek := random.GetRandomBytes(32)
+
+	wk, err := josecipher.KeyWrap(block, cek)
+	require.NoError(t, err)
+
+	recWK := &composite.RecipientWrappedKey{
+		EncryptedCEK: wk,
+		EPK:          epk,
+		Alg:          A128KWAlg,
+	}
+
+	recipientKW := &ECDHESConcatKDFRecipientKW{
+		recipientPrivateKey: hybrid.GetECPrivateKey(curve, bobD),
+		algID:               "A128GCM",
+		apu:                 []byte("Alice"),
+		apv:                 []byte("Bob"),
+	}
+
+	unwrappedCEK, err := recipientKW.unwrapKey(recWK, len(derivedKey))
+	require.NoError(t, err)
+	require.Equal(t, cek, unwrappedCEK)
+
+	// the default OtherInfo (AlgorithmID set to the key wrapping algorithm, no party info) derives another key
+	recipientKW.algID, recipientKW.apu, recipientKW.apv = "", nil, nil
+
+	_, err = recipientKW.unwrapKey(recWK, len(derivedKey))
+	require.Error(t, err)
+}
+
+func TestWrapWithConcatKDFOtherInfo(t *testing.T) {
+	keySize := 32
+
+	curve, err := hybrid.GetCurve(commonpb.EllipticCurveType_NIST_P256.String())
+	require.NoError(t, err)
+
+	recPvt, err := hybrid.GenerateECDHKeyPair(curve)
+	require.NoError(t, err)
+
+	recX25519Pvt := random.GetRandomBytes(curve25519.ScalarSize)
+
+	recX25519Pub, err := curve25519.X25519(recX25519Pvt, curve25519.Basepoint)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		recPubKey   *composite.PublicKey
+		recipientKW *ECDHESConcatKDFRecipientKW
+	}{
+		{
+			name: "EC recipient key",
+			recPubKey: &composite.PublicKey{
+				Type:  compositepb.KeyType_EC.String(),
+				Curve: recPvt.PublicKey.Curve.Params().Name,
+				X:     recPvt.PublicKey.Point.X.Bytes(),
+				Y:     recPvt.PublicKey.Point.Y.Bytes(),
+			},
+			recipientKW: &ECDHESConcatKDFRecipientKW{recipientPrivateKey: recPvt},
+		},
+		{
+			name: "OKP recipient key",
+			recPubKey: &composite.PublicKey{
+				Type:  compositepb.KeyType_OKP.String(),
+				Curve: X25519Curve,
+				X:     recX25519Pub,
+			},
+			recipientKW: &ECDHESConcatKDFRecipientKW{recipientX25519PrivateKey: recX25519Pvt},
+		},
+	}
+
+	for _, tc := range tests {
+		tt := tc
+		t.Run(tt.name, func(t *testing.T) {
+			senderKW := &ECDHESConcatKDFSenderKW{
+				recipientPublicKey: tt.recPubKey,
+				cek:                random.GetRandomBytes(uint32(keySize)),
+				algID:              "custom-alg-id",
+				apu:                []byte("sender info"),
+				apv:                []byte("recipient info"),
+			}
+
+			wrappedKey, err := senderKW.wrapKey(A256KWAlg, keySize)
+			require.NoError(t, err)
+			// the header alg is still the key wrapping algorithm
+			require.Equal(t, A256KWAlg, wrappedKey.Alg)
+
+			// a recipient with the default OtherInfo fails to unwrap
+			_, err = tt.recipientKW.unwrapKey(wrappedKey, keySize)
+			require.Error(t, err)
+
+			tt.recipientKW.algID = senderKW.algID
+			tt.recipientKW.apu = senderKW.apu
+			tt.recipientKW.apv = senderKW.apv
+
+			cek, err := tt.recipientKW.unwrapKey(wrappedKey, keySize)
+			require.NoError(t, err)
+			require.Equal(t, senderKW.cek, cek)
+		})
+	}
+}
+
+func b64Decode(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	require.NoError(t, err)
+
+	return b
 }
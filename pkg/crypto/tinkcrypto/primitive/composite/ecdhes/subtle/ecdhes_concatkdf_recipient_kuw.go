@@ -10,18 +10,24 @@ import (
 	"crypto/aes"
 	"crypto/ecdsa"
 	"fmt"
-	"math/big"
 
 	hybrid "github.com/google/tink/go/hybrid/subtle"
 	josecipher "github.com/square/go-jose/v3/cipher"
 
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 )
 
 // ECDHESConcatKDFRecipientKW represents concat KDF based ECDH-ES KW (key wrapping)
 // for ECDH-ES recipient's unwrapping of CEK
 type ECDHESConcatKDFRecipientKW struct {
 	recipientPrivateKey *hybrid.ECPrivateKey
+	// recipientX25519PrivateKey is set instead of recipientPrivateKey for OKP recipient keys
+	recipientX25519PrivateKey []byte
+	// algID, apu and apv are the Concat KDF OtherInfo inputs as per https://tools.ietf.org/html/rfc7518#section-4.6.2
+	algID string
+	apu   []byte
+	apv   []byte
 }
 
 // unwrapKey will do ECDH-ES key unwrapping
@@ -30,7 +36,9 @@ func (s *ECDHESConcatKDFRecipientKW) unwrapKey(recWK *composite.RecipientWrapped
 		return nil, fmt.Errorf("unwrapKey: RecipientWrappedKey is empty")
 	}
 
-	// TODO: add support for 25519 key wrapping https://github.com/hyperledger/aries-framework-go/issues/1637
+	if len(s.recipientX25519PrivateKey) > 0 {
+		return s.unwrapX25519Key(recWK, keySize)
+	}
 
 	recPrivKey := &ecdsa.PrivateKey{
 		PublicKey: ecdsa.PublicKey{
@@ -41,19 +49,29 @@ func (s *ECDHESConcatKDFRecipientKW) unwrapKey(recWK *composite.RecipientWrapped
 		D: s.recipientPrivateKey.D,
 	}
 
-	epkCurve, err := hybrid.GetCurve(recWK.EPK.Curve)
+	epkCurve, err := composite.GetCurve(recWK.EPK.Curve)
 	if err != nil {
 		return nil, err
 	}
 
+	epkX, epkY, err := composite.ECPoint(epkCurve, &recWK.EPK)
+	if err != nil {
+		return nil, err
+	}
+
+	// recipients of a message may have keys on different curves, DeriveECDHES panics if the EPK is for another one
+	if epkCurve != recPrivKey.Curve || !epkCurve.IsOnCurve(epkX, epkY) {
+		return nil, fmt.Errorf("unwrapKey: EPK is not on the recipient key curve '%s'", recPrivKey.Curve.Params().Name)
+	}
+
 	epkPubKey := &ecdsa.PublicKey{
 		Curve: epkCurve,
-		X:     new(big.Int).SetBytes(recWK.EPK.X),
-		Y:     new(big.Int).SetBytes(recWK.EPK.Y),
+		X:     epkX,
+		Y:     epkY,
 	}
 
-	// DeriveECDHES checks if keys are on the same curve
-	kek := josecipher.DeriveECDHES(recWK.Alg, []byte{}, []byte{}, recPrivKey, epkPubKey, keySize)
+	kek := josecipher.DeriveECDHES(concatKDFAlgID(s.algID, recWK.Alg), s.apu, s.apv, recPrivKey, epkPubKey, keySize)
+	defer cryptoutil.Zeroize(kek)
 
 	block, err := aes.NewCipher(kek)
 	if err != nil {
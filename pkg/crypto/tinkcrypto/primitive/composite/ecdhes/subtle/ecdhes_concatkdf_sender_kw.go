@@ -10,39 +10,83 @@ import (
 	"crypto/aes"
 	"crypto/ecdsa"
 	"crypto/rand"
-	"math/big"
+	"fmt"
 
-	hybrid "github.com/google/tink/go/hybrid/subtle"
 	josecipher "github.com/square/go-jose/v3/cipher"
 
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
 	compositepb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 )
 
-// A256KWAlg is the ECDH-ES key wrapping algorithm
-const A256KWAlg = "ECDH-ES+A256KW"
+const (
+	// A128KWAlg is the ECDH-ES key wrapping algorithm with a 128 bits AES key wrap
+	A128KWAlg = "ECDH-ES+A128KW"
+	// A192KWAlg is the ECDH-ES key wrapping algorithm with a 192 bits AES key wrap
+	A192KWAlg = "ECDH-ES+A192KW"
+	// A256KWAlg is the ECDH-ES key wrapping algorithm
+	A256KWAlg = "ECDH-ES+A256KW"
+)
+
+// kwKeySize returns the size in bytes of the AES key wrapping key (KEK) of the given ECDH-ES key wrapping algorithm.
+func kwKeySize(kwAlg string) (int, error) {
+	switch kwAlg {
+	case A128KWAlg:
+		return 16, nil
+	case A192KWAlg:
+		return 24, nil
+	case A256KWAlg:
+		return 32, nil
+	default:
+		return 0, fmt.Errorf("unsupported key wrapping algorithm '%s'", kwAlg)
+	}
+}
+
+// concatKDFAlgID returns the Concat KDF OtherInfo AlgorithmID, algID when set or the key wrapping algorithm kwAlg
+// otherwise.
+func concatKDFAlgID(algID, kwAlg string) string {
+	if algID != "" {
+		return algID
+	}
+
+	return kwAlg
+}
 
 // ECDHESConcatKDFSenderKW represents concat KDF based ECDH-ES KW (key wrapping)
 // for ECDH-ES sender
 type ECDHESConcatKDFSenderKW struct {
 	recipientPublicKey *composite.PublicKey
 	cek                []byte
+	// pointFormat sets the EPK with compressed points when equal to composite.CompressedPointFormat
+	pointFormat string
+	// algID, apu and apv are the Concat KDF OtherInfo inputs as per https://tools.ietf.org/html/rfc7518#section-4.6.2
+	algID string
+	apu   []byte
+	apv   []byte
 }
 
 // wrapKey will do ECDH-ES key wrapping
 func (s *ECDHESConcatKDFSenderKW) wrapKey(kwAlg string, keySize int) (*composite.RecipientWrappedKey, error) {
-	// TODO: add support for 25519 key wrapping https://github.com/hyperledger/aries-framework-go/issues/1637
+	if s.recipientPublicKey.Type == compositepb.KeyType_OKP.String() {
+		return s.wrapX25519Key(kwAlg, keySize)
+	}
+
 	keyType := compositepb.KeyType_EC.String()
 
-	c, err := hybrid.GetCurve(s.recipientPublicKey.Curve)
+	c, err := composite.GetCurve(s.recipientPublicKey.Curve)
+	if err != nil {
+		return nil, err
+	}
+
+	recX, recY, err := composite.ECPoint(c, s.recipientPublicKey)
 	if err != nil {
 		return nil, err
 	}
 
 	recPubKey := &ecdsa.PublicKey{
 		Curve: c,
-		X:     new(big.Int).SetBytes(s.recipientPublicKey.X),
-		Y:     new(big.Int).SetBytes(s.recipientPublicKey.Y),
+		X:     recX,
+		Y:     recY,
 	}
 
 	ephemeralPriv, err := ecdsa.GenerateKey(recPubKey.Curve, rand.Reader)
@@ -50,7 +94,8 @@ func (s *ECDHESConcatKDFSenderKW) wrapKey(kwAlg string, keySize int) (*composite
 		return nil, err
 	}
 
-	kek := josecipher.DeriveECDHES(kwAlg, []byte{}, []byte{}, ephemeralPriv, recPubKey, keySize)
+	kek := josecipher.DeriveECDHES(concatKDFAlgID(s.algID, kwAlg), s.apu, s.apv, ephemeralPriv, recPubKey, keySize)
+	defer cryptoutil.Zeroize(kek)
 
 	block, err := aes.NewCipher(kek)
 	if err != nil {
@@ -62,15 +107,22 @@ func (s *ECDHESConcatKDFSenderKW) wrapKey(kwAlg string, keySize int) (*composite
 		return nil, err
 	}
 
+	epk := composite.PublicKey{
+		X:     ephemeralPriv.PublicKey.X.Bytes(),
+		Y:     ephemeralPriv.PublicKey.Y.Bytes(),
+		Curve: ephemeralPriv.PublicKey.Curve.Params().Name,
+		Type:  keyType,
+	}
+
+	if s.pointFormat == composite.CompressedPointFormat {
+		epk.X = composite.CompressPoint(c, ephemeralPriv.PublicKey.X, ephemeralPriv.PublicKey.Y)
+		epk.Y = nil
+	}
+
 	return &composite.RecipientWrappedKey{
 		KID:          s.recipientPublicKey.KID,
 		EncryptedCEK: wk,
-		EPK: composite.PublicKey{
-			X:     ephemeralPriv.PublicKey.X.Bytes(),
-			Y:     ephemeralPriv.PublicKey.Y.Bytes(),
-			Curve: ephemeralPriv.PublicKey.Curve.Params().Name,
-			Type:  keyType,
-		},
-		Alg: kwAlg,
+		EPK:          epk,
+		Alg:          kwAlg,
 	}, nil
 }
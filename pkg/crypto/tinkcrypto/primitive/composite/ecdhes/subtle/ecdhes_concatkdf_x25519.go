@@ -0,0 +1,119 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package subtle
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+
+	josecipher "github.com/square/go-jose/v3/cipher"
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+	compositepb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+)
+
+// X25519Curve is the curve name of OKP keys used for X25519 key agreement as per
+// https://tools.ietf.org/html/rfc8037#section-3.2
+const X25519Curve = "X25519"
+
+// wrapX25519Key will do ECDH-ES key wrapping with X25519 key agreement for the OKP recipient key.
+func (s *ECDHESConcatKDFSenderKW) wrapX25519Key(kwAlg string, keySize int) (*composite.RecipientWrappedKey, error) {
+	ephemeralPriv := make([]byte, curve25519.ScalarSize)
+	defer cryptoutil.Zeroize(ephemeralPriv)
+
+	_, err := rand.Read(ephemeralPriv)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeralPub, err := curve25519.X25519(ephemeralPriv, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+
+	kek, err := deriveX25519ConcatKDF(concatKDFAlgID(s.algID, kwAlg), s.apu, s.apv, ephemeralPriv,
+		s.recipientPublicKey.X, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cryptoutil.Zeroize(kek)
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	wk, err := josecipher.KeyWrap(block, s.cek)
+	if err != nil {
+		return nil, err
+	}
+
+	return &composite.RecipientWrappedKey{
+		KID:          s.recipientPublicKey.KID,
+		EncryptedCEK: wk,
+		EPK: composite.PublicKey{
+			X:     ephemeralPub,
+			Curve: X25519Curve,
+			Type:  compositepb.KeyType_OKP.String(),
+		},
+		Alg: kwAlg,
+	}, nil
+}
+
+// unwrapX25519Key will do ECDH-ES key unwrapping with X25519 key agreement using the OKP recipient private key.
+func (s *ECDHESConcatKDFRecipientKW) unwrapX25519Key(recWK *composite.RecipientWrappedKey,
+	keySize int) ([]byte, error) {
+	if recWK.EPK.Type != compositepb.KeyType_OKP.String() {
+		return nil, fmt.Errorf("unwrapKey: invalid EPK key type '%s' for X25519 key unwrapping", recWK.EPK.Type)
+	}
+
+	kek, err := deriveX25519ConcatKDF(concatKDFAlgID(s.algID, recWK.Alg), s.apu, s.apv, s.recipientX25519PrivateKey,
+		recWK.EPK.X, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cryptoutil.Zeroize(kek)
+
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+
+	return josecipher.KeyUnwrap(block, recWK.EncryptedCEK)
+}
+
+// deriveX25519ConcatKDF derives the key wrapping key from the X25519 shared secret of priv and pub with the Concat KDF
+// the same way josecipher.DeriveECDHES does for NIST P curves.
+func deriveX25519ConcatKDF(algID string, apu, apv, priv, pub []byte, keySize int) ([]byte, error) {
+	z, err := curve25519.X25519(priv, pub)
+	if err != nil {
+		return nil, err
+	}
+
+	defer cryptoutil.Zeroize(z)
+
+	// suppPubInfo is the encoded length of the output size in bits
+	supPubInfo := make([]byte, 4)
+	binary.BigEndian.PutUint32(supPubInfo, uint32(keySize)*8)
+
+	reader := josecipher.NewConcatKDF(crypto.SHA256, z, cryptoutil.LengthPrefix([]byte(algID)),
+		cryptoutil.LengthPrefix(apu), cryptoutil.LengthPrefix(apv), supPubInfo, []byte{})
+
+	kek := make([]byte, keySize)
+
+	// Read on the KDF never fails
+	_, _ = reader.Read(kek)
+
+	return kek, nil
+}
@@ -7,6 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package composite
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"math/big"
 	"testing"
 
 	commonpb "github.com/google/tink/go/proto/common_go_proto"
@@ -94,6 +98,18 @@ func TestGetCurveType(t *testing.T) {
 			expectedType: commonpb.EllipticCurveType_NIST_P521,
 			isError:      false,
 		},
+		{
+			tcName:       "test get X25519 curve type",
+			curveName:    "X25519",
+			expectedType: commonpb.EllipticCurveType_CURVE25519,
+			isError:      false,
+		},
+		{
+			tcName:       "test get CURVE25519 curve type",
+			curveName:    "CURVE25519",
+			expectedType: commonpb.EllipticCurveType_CURVE25519,
+			isError:      false,
+		},
 		{
 			tcName:       "test unsupported curve type",
 			curveName:    "bad.curve",
@@ -120,6 +136,89 @@ func TestGetCurveType(t *testing.T) {
 	}
 }
 
+func TestRegisterCurve(t *testing.T) {
+	curve := brainpoolP256t1(t)
+	curveType := commonpb.EllipticCurveType(100)
+
+	_, err := GetCurveType("brainpoolP256t1")
+	require.EqualError(t, err, "curve brainpoolP256t1 not supported")
+
+	require.NoError(t, RegisterCurve("brainpoolP256t1", curveType, curve))
+
+	t.Run("registered curve is found by its name, type and params name", func(t *testing.T) {
+		for _, name := range []string{"brainpoolP256t1", curveType.String(), curve.Params().Name} {
+			c, err := GetCurveType(name)
+			require.NoError(t, err)
+			require.Equal(t, curveType, c)
+
+			ec, err := GetCurve(name)
+			require.NoError(t, err)
+			require.Equal(t, curve, ec)
+		}
+
+		ec, err := GetCurve("NIST_P256")
+		require.NoError(t, err)
+		require.Equal(t, elliptic.P256(), ec)
+	})
+
+	t.Run("registered curve keys are marshalled as JWKs", func(t *testing.T) {
+		pvt, err := ecdsa.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+
+		jwk, err := PublicKeyToJWK(&PublicKey{
+			Type:  compositepb.KeyType_EC.String(),
+			Curve: "brainpoolP256t1",
+			X:     pvt.X.Bytes(),
+			Y:     pvt.Y.Bytes(),
+		})
+		require.NoError(t, err)
+
+		pubKey, err := PublicKeyFromJWK(jwk)
+		require.NoError(t, err)
+		require.Equal(t, curve.Params().Name, pubKey.Curve)
+		require.Equal(t, 0, new(big.Int).SetBytes(pubKey.X).Cmp(pvt.X))
+	})
+
+	t.Run("invalid registrations", func(t *testing.T) {
+		require.EqualError(t, RegisterCurve("", commonpb.EllipticCurveType(101), curve),
+			"registerCurve: curve name is mandatory")
+		require.EqualError(t, RegisterCurve("other", commonpb.EllipticCurveType(101), nil),
+			"registerCurve: curve other is nil")
+		require.EqualError(t, RegisterCurve("other", commonpb.EllipticCurveType_UNKNOWN_CURVE, curve),
+			"registerCurve: curve other has an unknown curve type")
+		require.EqualError(t, RegisterCurve("brainpoolP256t1", commonpb.EllipticCurveType(101), elliptic.P384()),
+			"registerCurve: curve brainpoolP256t1 is already registered")
+		require.EqualError(t, RegisterCurve("other", curveType, elliptic.P384()),
+			"registerCurve: curve type 100 is already registered")
+		require.EqualError(t, RegisterCurve("P-256", commonpb.EllipticCurveType(101), curve),
+			"registerCurve: curve P-256 is already supported")
+		require.EqualError(t, RegisterCurve("other", commonpb.EllipticCurveType_NIST_P384, curve),
+			"registerCurve: curve NIST_P384 is already supported")
+		require.EqualError(t, RegisterCurve("other", commonpb.EllipticCurveType(101), elliptic.P521()),
+			"registerCurve: curve P-521 is already supported")
+
+		_, err := GetCurveType("other")
+		require.Error(t, err)
+	})
+}
+
+// brainpoolP256t1 returns the twisted brainpool curve of https://tools.ietf.org/html/rfc5639#section-3.4, its a = -3
+// coefficient fitting elliptic.CurveParams.
+func brainpoolP256t1(t *testing.T) elliptic.Curve {
+	t.Helper()
+
+	params := &elliptic.CurveParams{Name: "brainpoolP256t1", BitSize: 256}
+	params.P, _ = new(big.Int).SetString("A9FB57DBA1EEA9BC3E660A909D838D726E3BF623D52620282013481D1F6E5377", 16)
+	params.N, _ = new(big.Int).SetString("A9FB57DBA1EEA9BC3E660A909D838D718C397AA3B561A6F7901E0E82974856A7", 16)
+	params.B, _ = new(big.Int).SetString("662C61C430D84EA4FE66A7733D0B76B7BF93EBC4AF2F49256AE58101FEE92B04", 16)
+	params.Gx, _ = new(big.Int).SetString("A3E8EB3CC1CFE7B7732213B23A656149AFA142C47AAFBC2B79A191562E1305F4", 16)
+	params.Gy, _ = new(big.Int).SetString("2D996C823439C56D7F7B22E14644417E69BCB6DE39D027001DABE8F35B25C9BE", 16)
+
+	require.True(t, params.IsOnCurve(params.Gx, params.Gy))
+
+	return params
+}
+
 func TestGetKeyType(t *testing.T) {
 	tcs := []struct {
 		tcName       string
@@ -164,3 +263,23 @@ func TestGetKeyType(t *testing.T) {
 		})
 	}
 }
+
+func TestSortRecipientsByKID(t *testing.T) {
+	recipients := []*RecipientWrappedKey{
+		{KID: "kid-1"},
+		{},
+		{KID: "kid-2"},
+		{KID: "kid-3"},
+		{KID: "kid-2"},
+	}
+
+	require.Equal(t, recipients, SortRecipientsByKID(recipients, ""))
+
+	require.Equal(t, []*RecipientWrappedKey{
+		recipients[2], recipients[4], recipients[1], recipients[0], recipients[3],
+	}, SortRecipientsByKID(recipients, "kid-2"))
+
+	require.Equal(t, []*RecipientWrappedKey{
+		recipients[1], recipients[0], recipients[2], recipients[3], recipients[4],
+	}, SortRecipientsByKID(recipients, "kid-4"))
+}
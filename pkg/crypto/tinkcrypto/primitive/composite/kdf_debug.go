@@ -0,0 +1,49 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package composite
+
+import (
+	"crypto"
+	"encoding/binary"
+	"errors"
+
+	josecipher "github.com/square/go-jose/v3/cipher"
+
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
+)
+
+// DeriveKEK is for testing and debugging only, it must not be used to encrypt or decrypt messages.
+//
+// It returns the raw key wrapping key of keySize bytes derived from the ECDH shared secret z with the Concat KDF as
+// per https://tools.ietf.org/html/rfc7518#section-4.6.2, with algID as the AlgorithmID and apu and apv as the
+// PartyUInfo and PartyVInfo (they are length prefixed as the composite encryption does). z is the ECDH-ES shared
+// secret, or the concatenation Ze || Zs of the ephemeral and static shared secrets for ECDH-1PU which uses the same
+// KDF inputs as per https://tools.ietf.org/html/draft-madden-jose-ecdh-1pu-03#section-2.2. Comparing its output with a
+// partner's implementation tells whether an interop failure comes from the ECDH, the KDF inputs or the key wrap.
+func DeriveKEK(z []byte, algID string, apu, apv []byte, keySize int) ([]byte, error) {
+	if len(z) == 0 {
+		return nil, errors.New("deriveKEK: shared secret is empty")
+	}
+
+	if keySize <= 0 {
+		return nil, errors.New("deriveKEK: key size must be positive")
+	}
+
+	// suppPubInfo is the encoded length of the output size in bits
+	supPubInfo := make([]byte, 4)
+	binary.BigEndian.PutUint32(supPubInfo, uint32(keySize)*8)
+
+	reader := josecipher.NewConcatKDF(crypto.SHA256, z, cryptoutil.LengthPrefix([]byte(algID)),
+		cryptoutil.LengthPrefix(apu), cryptoutil.LengthPrefix(apv), supPubInfo, []byte{})
+
+	kek := make([]byte, keySize)
+
+	// Read on the KDF never fails
+	_, _ = reader.Read(kek)
+
+	return kek, nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package composite
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+
+	josecipher "github.com/square/go-jose/v3/cipher"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveKEK(t *testing.T) {
+	t.Run("RFC 7518 appendix C test vector", func(t *testing.T) {
+		z := []byte{
+			158, 86, 217, 29, 129, 113, 53, 211, 114, 131, 66, 131, 191, 132, 38, 156,
+			251, 49, 110, 163, 218, 128, 106, 72, 246, 218, 167, 121, 140, 254, 144, 196,
+		}
+
+		kek, err := DeriveKEK(z, "A128GCM", []byte("Alice"), []byte("Bob"), 16)
+		require.NoError(t, err)
+		require.Equal(t, "VqqN6vgjbSBcIijNcacQGg", base64.RawURLEncoding.EncodeToString(kek))
+	})
+
+	t.Run("matches the ECDH-ES key derivation", func(t *testing.T) {
+		recKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		epk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		x, _ := elliptic.P256().ScalarMult(recKey.X, recKey.Y, epk.D.Bytes())
+		z := padBytes(x.Bytes(), curveByteSize(elliptic.P256()))
+
+		kek, err := DeriveKEK(z, "ECDH-ES+A256KW", []byte("apu"), []byte("apv"), 32)
+		require.NoError(t, err)
+		require.Equal(t, josecipher.DeriveECDHES("ECDH-ES+A256KW", []byte("apu"), []byte("apv"), epk,
+			&recKey.PublicKey, 32), kek)
+	})
+
+	t.Run("empty shared secret", func(t *testing.T) {
+		_, err := DeriveKEK(nil, "ECDH-ES+A256KW", nil, nil, 32)
+		require.EqualError(t, err, "deriveKEK: shared secret is empty")
+	})
+
+	t.Run("invalid key size", func(t *testing.T) {
+		_, err := DeriveKEK([]byte("z"), "ECDH-ES+A256KW", nil, nil, 0)
+		require.EqualError(t, err, "deriveKEK: key size must be positive")
+	})
+}
@@ -0,0 +1,149 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package composite
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/core/registry"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/api"
+)
+
+// nolint:gochecknoglobals
+var (
+	validationPlaintext = []byte("composite key template validation")
+	validationAAD       = []byte("eyJlbmMiOiJ2YWxpZGF0aW9uIn0") // base64URL of {"enc":"validation"}
+)
+
+// KeyTemplateValidator is implemented by the ECDH private key managers to support ValidateKeyTemplate.
+type KeyTemplateValidator interface {
+	// ValidateKeyFormat parses serializedKeyFormat and checks its curve, content encryption and point format are
+	// supported and consistent.
+	ValidateKeyFormat(serializedKeyFormat []byte) error
+	// SelfEncryptionPrimitives returns the encryption and decryption primitives of the private key serializedPrivKey
+	// set as its own recipient (and as its own sender for ECDH-1PU keys).
+	SelfEncryptionPrimitives(serializedPrivKey []byte) (api.CompositeEncrypt, api.CompositeDecrypt, error)
+}
+
+// SerializeKeyTemplate serializes kt with deterministic proto marshaling. The result is byte-stable for a given
+// template and can be used to snapshot key templates (eg to detect a curve or content encryption change in tests).
+// Note: the golang/protobuf version used by this framework does not offer proto.MarshalOptions, deterministic
+// marshaling is set on a proto.Buffer instead.
+func SerializeKeyTemplate(kt *tinkpb.KeyTemplate) ([]byte, error) {
+	if kt == nil {
+		return nil, errors.New("serializeKeyTemplate: key template is nil")
+	}
+
+	buf := proto.NewBuffer(nil)
+	buf.SetDeterministic(true)
+
+	err := buf.Marshal(kt)
+	if err != nil {
+		return nil, fmt.Errorf("serializeKeyTemplate: failed to marshal key template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ParseKeyTemplate parses a key template serialized by SerializeKeyTemplate.
+func ParseKeyTemplate(serializedKT []byte) (*tinkpb.KeyTemplate, error) {
+	if len(serializedKT) == 0 {
+		return nil, errors.New("parseKeyTemplate: serialized key template is empty")
+	}
+
+	kt := new(tinkpb.KeyTemplate)
+
+	err := proto.Unmarshal(serializedKT, kt)
+	if err != nil {
+		return nil, fmt.Errorf("parseKeyTemplate: failed to unmarshal key template: %w", err)
+	}
+
+	return kt, nil
+}
+
+// ValidateKeyTemplate validates kt creates working composite keys without storing any key: its serialized key format
+// is parsed and checked by its key manager, then a throwaway key is generated and a test message encrypted for this
+// key is decrypted with it. The key manager of kt must be registered (ie by importing the ecdhes or ecdh1pu package)
+// and implement KeyTemplateValidator.
+func ValidateKeyTemplate(kt *tinkpb.KeyTemplate) error {
+	if kt == nil {
+		return errors.New("validateKeyTemplate: key template is nil")
+	}
+
+	if kt.TypeUrl == "" {
+		return errors.New("validateKeyTemplate: key template type URL is empty")
+	}
+
+	if len(kt.Value) == 0 {
+		return errors.New("validateKeyTemplate: key template value is empty")
+	}
+
+	km, err := registry.GetKeyManager(kt.TypeUrl)
+	if err != nil {
+		return fmt.Errorf("validateKeyTemplate: key type '%s' is not registered: %w", kt.TypeUrl, err)
+	}
+
+	v, ok := km.(KeyTemplateValidator)
+	if !ok {
+		return fmt.Errorf("validateKeyTemplate: key type '%s' is not a composite private key type", kt.TypeUrl)
+	}
+
+	err = v.ValidateKeyFormat(kt.Value)
+	if err != nil {
+		return fmt.Errorf("validateKeyTemplate: invalid key format: %w", err)
+	}
+
+	key, err := km.NewKey(kt.Value)
+	if err != nil {
+		return fmt.Errorf("validateKeyTemplate: failed to generate key: %w", err)
+	}
+
+	serializedKey, err := proto.Marshal(key)
+	if err != nil {
+		return fmt.Errorf("validateKeyTemplate: failed to marshal key: %w", err)
+	}
+
+	e, d, err := v.SelfEncryptionPrimitives(serializedKey)
+	if err != nil {
+		return fmt.Errorf("validateKeyTemplate: failed to create primitives: %w", err)
+	}
+
+	ct, err := e.Encrypt(validationPlaintext, validationAAD)
+	if err != nil {
+		return fmt.Errorf("validateKeyTemplate: encryption failed: %w", err)
+	}
+
+	// a single recipient message is authenticated with the protected headers merged with its recipient headers
+	encData := new(EncryptedData)
+
+	err = json.Unmarshal(ct, encData)
+	if err != nil {
+		return fmt.Errorf("validateKeyTemplate: failed to unmarshal encrypted data: %w", err)
+	}
+
+	aad := validationAAD
+	if len(encData.SingleRecipientAAD) > 0 {
+		aad = encData.SingleRecipientAAD
+	}
+
+	pt, err := d.Decrypt(ct, aad)
+	if err != nil {
+		return fmt.Errorf("validateKeyTemplate: decryption failed: %w", err)
+	}
+
+	if !bytes.Equal(validationPlaintext, pt) {
+		return errors.New("validateKeyTemplate: decrypted plaintext does not match the encrypted plaintext")
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package composite
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/tink/go/aead"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerializeParseKeyTemplate(t *testing.T) {
+	kt := aead.AES256GCMKeyTemplate()
+
+	serializedKT, err := SerializeKeyTemplate(kt)
+	require.NoError(t, err)
+
+	// serialization is stable
+	serializedKT2, err := SerializeKeyTemplate(aead.AES256GCMKeyTemplate())
+	require.NoError(t, err)
+	require.Equal(t, serializedKT, serializedKT2)
+
+	parsedKT, err := ParseKeyTemplate(serializedKT)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(kt, parsedKT))
+
+	t.Run("failure cases", func(t *testing.T) {
+		_, err = SerializeKeyTemplate(nil)
+		require.EqualError(t, err, "serializeKeyTemplate: key template is nil")
+
+		_, err = ParseKeyTemplate(nil)
+		require.EqualError(t, err, "parseKeyTemplate: serialized key template is empty")
+
+		_, err = ParseKeyTemplate([]byte("bad template"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parseKeyTemplate: failed to unmarshal key template")
+	})
+}
+
+func TestValidateKeyTemplate(t *testing.T) {
+	err := ValidateKeyTemplate(nil)
+	require.EqualError(t, err, "validateKeyTemplate: key template is nil")
+
+	err = ValidateKeyTemplate(&tinkpb.KeyTemplate{Value: []byte("format")})
+	require.EqualError(t, err, "validateKeyTemplate: key template type URL is empty")
+
+	err = ValidateKeyTemplate(&tinkpb.KeyTemplate{TypeUrl: "type.hyperledger.org/unknown"})
+	require.EqualError(t, err, "validateKeyTemplate: key template value is empty")
+
+	err = ValidateKeyTemplate(&tinkpb.KeyTemplate{TypeUrl: "type.hyperledger.org/unknown", Value: []byte("format")})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "validateKeyTemplate: key type 'type.hyperledger.org/unknown' is not registered")
+
+	kt := aead.AES256GCMKeyTemplate()
+
+	err = ValidateKeyTemplate(kt)
+	require.EqualError(t, err, fmt.Sprintf("validateKeyTemplate: key type '%s' is not a composite private key type",
+		kt.TypeUrl))
+}
@@ -13,7 +13,6 @@ import (
 	"io"
 
 	"github.com/golang/protobuf/proto"
-	hybrid "github.com/google/tink/go/hybrid/subtle"
 	"github.com/google/tink/go/keyset"
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 
@@ -134,8 +133,15 @@ func buildKey(c compositeKeyGetter) (*composite.PublicKey, error) {
 }
 
 func buildCompositeKey(kid, keyType, curve string, x, y []byte) (*composite.PublicKey, error) {
-	// validate curve
-	_, err := hybrid.GetCurve(curve)
+	var err error
+
+	// validate curve, OKP keys are set with Curve25519 which is not a NIST curve
+	if keyType == commonpb.KeyType_OKP.String() {
+		_, err = composite.GetCurveType(curve)
+	} else {
+		_, err = composite.GetCurve(curve)
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("undefined curve: %w", err)
 	}
@@ -170,7 +176,8 @@ func newECDHESKey(mKey []byte) (compositeKeyGetter, error) {
 	}
 
 	// validate key type
-	if pubKeyProto.Params.KwParams.KeyType != commonpb.KeyType_EC {
+	if pubKeyProto.Params.KwParams.KeyType != commonpb.KeyType_EC &&
+		pubKeyProto.Params.KwParams.KeyType != commonpb.KeyType_OKP {
 		return nil, fmt.Errorf("undefined key type: '%s'", pubKeyProto.Params.KwParams.KeyType)
 	}
 
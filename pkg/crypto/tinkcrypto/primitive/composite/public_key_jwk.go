@@ -0,0 +1,264 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package composite
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	commonpb "github.com/google/tink/go/proto/common_go_proto"
+
+	compositepb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
+)
+
+const (
+	// jwkUseEnc is the JWK 'use' value of the keys marshalled by PublicKeyToJWK
+	jwkUseEnc = "enc"
+	// x25519Crv is the JWK 'crv' value of X25519 OKP keys as per https://tools.ietf.org/html/rfc8037#section-2
+	x25519Crv = "X25519"
+	// x25519KeySize is the size in bytes of X25519 public keys
+	x25519KeySize = 32
+)
+
+// publicKeyJWK is the JSON Web Key of a composite public key.
+type publicKeyJWK struct {
+	KID string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y,omitempty"`
+}
+
+// PublicKeyToJWK marshals pubKey as a JWK with its KID set as the JWK 'kid' and an 'enc' use, ie to exchange
+// recipient keys with other agents. EC keys are marshalled with their 'x' and 'y' coordinates padded to the size of
+// their curve, keys with a compressed 'x' point are decompressed first. OKP keys are marshalled as X25519 keys with an
+// 'x' value only as per https://tools.ietf.org/html/rfc8037#section-2.
+func PublicKeyToJWK(pubKey *PublicKey) ([]byte, error) {
+	if pubKey == nil {
+		return nil, errors.New("publicKeyToJWK: public key is nil")
+	}
+
+	jwk := publicKeyJWK{
+		KID: pubKey.KID,
+		Use: jwkUseEnc,
+		Kty: pubKey.Type,
+	}
+
+	switch pubKey.Type {
+	case compositepb.KeyType_EC.String():
+		c, err := GetCurve(pubKey.Curve)
+		if err != nil {
+			return nil, fmt.Errorf("publicKeyToJWK: %w", err)
+		}
+
+		x, y, err := ECPoint(c, pubKey)
+		if err != nil {
+			return nil, fmt.Errorf("publicKeyToJWK: %w", err)
+		}
+
+		if !c.IsOnCurve(x, y) {
+			return nil, errors.New("publicKeyToJWK: point is not on curve")
+		}
+
+		byteLen := curveByteSize(c)
+
+		jwk.Crv = c.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(padBytes(x.Bytes(), byteLen))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(padBytes(y.Bytes(), byteLen))
+	case compositepb.KeyType_OKP.String():
+		if err := validateX25519Key(pubKey.Curve, pubKey.X); err != nil {
+			return nil, fmt.Errorf("publicKeyToJWK: %w", err)
+		}
+
+		jwk.Crv = x25519Crv
+		jwk.X = base64.RawURLEncoding.EncodeToString(pubKey.X)
+	default:
+		return nil, fmt.Errorf("publicKeyToJWK: key type %s not supported", pubKey.Type)
+	}
+
+	return json.Marshal(jwk)
+}
+
+// PublicKeyFromJWK unmarshals the EC or X25519 OKP public JWK jwkBytes as a composite public key, with the JWK 'kid'
+// set as its KID and the JWK 'crv' as its curve. JWKs with a use other than 'enc' are rejected, the private parts of
+// JWKs are ignored.
+func PublicKeyFromJWK(jwkBytes []byte) (*PublicKey, error) {
+	jwk := &publicKeyJWK{}
+
+	if err := json.Unmarshal(jwkBytes, jwk); err != nil {
+		return nil, fmt.Errorf("publicKeyFromJWK: %w", err)
+	}
+
+	if jwk.Use != "" && jwk.Use != jwkUseEnc {
+		return nil, fmt.Errorf("publicKeyFromJWK: key use %s not supported", jwk.Use)
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return nil, fmt.Errorf("publicKeyFromJWK: invalid 'x': %w", err)
+	}
+
+	pubKey := &PublicKey{
+		KID:   jwk.KID,
+		Type:  jwk.Kty,
+		Curve: jwk.Crv,
+		X:     x,
+	}
+
+	switch jwk.Kty {
+	case compositepb.KeyType_EC.String():
+		c, err := GetCurve(jwk.Crv)
+		if err != nil {
+			return nil, fmt.Errorf("publicKeyFromJWK: %w", err)
+		}
+
+		pubKey.Y, err = base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("publicKeyFromJWK: invalid 'y': %w", err)
+		}
+
+		byteLen := curveByteSize(c)
+
+		if len(pubKey.X) != byteLen || len(pubKey.Y) != byteLen {
+			return nil, fmt.Errorf("publicKeyFromJWK: 'x' and 'y' must be %d bytes long for curve %s", byteLen,
+				jwk.Crv)
+		}
+
+		if !c.IsOnCurve(new(big.Int).SetBytes(pubKey.X), new(big.Int).SetBytes(pubKey.Y)) {
+			return nil, errors.New("publicKeyFromJWK: point is not on curve")
+		}
+	case compositepb.KeyType_OKP.String():
+		if err := validateX25519Key(jwk.Crv, pubKey.X); err != nil {
+			return nil, fmt.Errorf("publicKeyFromJWK: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("publicKeyFromJWK: key type %s not supported", jwk.Kty)
+	}
+
+	return pubKey, nil
+}
+
+// validateX25519Key checks that x is an X25519 public key of an OKP key with the given curve.
+func validateX25519Key(curve string, x []byte) error {
+	curveType, err := GetCurveType(curve)
+	if err != nil || curveType != commonpb.EllipticCurveType_CURVE25519 {
+		return fmt.Errorf("curve %s not supported for OKP keys", curve)
+	}
+
+	if len(x) != x25519KeySize {
+		return fmt.Errorf("'x' must be %d bytes long for curve %s", x25519KeySize, curve)
+	}
+
+	return nil
+}
+
+// padBytes left pads b with zeros to size bytes.
+func padBytes(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+
+	padded := make([]byte, size)
+	copy(padded[size-len(b):], b)
+
+	return padded
+}
+
+// EPKToJWK marshals the ephemeral public key generated by the composite encryption for the recipient wrapped key rec
+// as the JWK of the JWE 'epk' header of the recipient, with the recipient KID set as the JWK 'kid' and an 'enc' use.
+// EC keys are marshalled with their 'x' and 'y' coordinates padded to the size of their curve, or with an 'x' value
+// only when the encryption compressed their point (see CompressedPointFormat). OKP keys are marshalled as X25519 keys
+// with an 'x' value only as per https://tools.ietf.org/html/rfc8037#section-2.
+func EPKToJWK(rec *RecipientWrappedKey) ([]byte, error) {
+	if rec == nil {
+		return nil, errors.New("epkToJWK: recipient wrapped key is nil")
+	}
+
+	jwk := publicKeyJWK{
+		KID: rec.KID,
+		Use: jwkUseEnc,
+		Kty: rec.EPK.Type,
+		Crv: rec.EPK.Curve,
+		X:   base64.RawURLEncoding.EncodeToString(rec.EPK.X),
+	}
+
+	if rec.EPK.Type != compositepb.KeyType_OKP.String() {
+		c, err := GetCurve(rec.EPK.Curve)
+		if err != nil {
+			return nil, err
+		}
+
+		jwk.Kty = compositepb.KeyType_EC.String()
+		jwk.Crv = c.Params().Name
+
+		// compressed EC points only have an 'x' value, 'y' is implied
+		if len(rec.EPK.Y) > 0 {
+			byteLen := curveByteSize(c)
+
+			jwk.X = base64.RawURLEncoding.EncodeToString(padBytes(rec.EPK.X, byteLen))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(padBytes(rec.EPK.Y, byteLen))
+		}
+	}
+
+	return json.Marshal(jwk)
+}
+
+// EPKFromJWK unmarshals the JWK epkJWK of the JWE 'epk' header of a recipient as a recipient wrapped key holding the
+// ephemeral public key for the composite decryption, with the JWK 'kid' set as the recipient KID. It accepts the JWKs
+// marshalled by EPKToJWK: EC keys, with or without a compressed 'x' point, and X25519 OKP keys. The wrapped CEK and
+// the key wrapping algorithm of the returned key are read from the other JWE headers by the caller.
+func EPKFromJWK(epkJWK []byte) (*RecipientWrappedKey, error) {
+	jwk := &publicKeyJWK{}
+
+	if err := json.Unmarshal(epkJWK, jwk); err != nil {
+		return nil, fmt.Errorf("epkFromJWK: %w", err)
+	}
+
+	if jwk.Kty != compositepb.KeyType_EC.String() || jwk.Y != "" {
+		epk, err := PublicKeyFromJWK(epkJWK)
+		if err != nil {
+			return nil, err
+		}
+
+		epk.KID = ""
+
+		return &RecipientWrappedKey{KID: jwk.KID, EPK: *epk}, nil
+	}
+
+	if jwk.Use != "" && jwk.Use != jwkUseEnc {
+		return nil, fmt.Errorf("epkFromJWK: key use %s not supported", jwk.Use)
+	}
+
+	c, err := GetCurve(jwk.Crv)
+	if err != nil {
+		return nil, fmt.Errorf("epkFromJWK: %w", err)
+	}
+
+	compressed, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return nil, fmt.Errorf("epkFromJWK: invalid 'x': %w", err)
+	}
+
+	x, y, err := DecompressPoint(c, compressed)
+	if err != nil {
+		return nil, fmt.Errorf("epkFromJWK: %w", err)
+	}
+
+	return &RecipientWrappedKey{
+		KID: jwk.KID,
+		EPK: PublicKey{
+			X:     x.Bytes(),
+			Y:     y.Bytes(),
+			Curve: jwk.Crv,
+			Type:  jwk.Kty,
+		},
+	}, nil
+}
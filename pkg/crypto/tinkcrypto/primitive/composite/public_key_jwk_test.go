@@ -0,0 +1,314 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package composite
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// verification method JWKs of the JsonWebKey2020 examples of https://www.w3.org/TR/did-core
+const (
+	didDocP256JWK = `{
+		"kid": "_TKzHv2jFIyvdTGF1Dsgwngfdg3SH6TpDv0Ta1aOEkw",
+		"kty": "EC",
+		"crv": "P-256",
+		"x": "38M1FDts7Oea7urmseiugGW7tWc3mLpJh6rKe7xINZ8",
+		"y": "nDQW6XZ7b_u2Sy9slofYLlG03sOEoug3I0aAPQ0exs4"
+	}`
+	didDocP384JWK = `{
+		"kid": "8wgRfY3sWmzoeAL-78-oALNvNj67ZlQxd1ss_NX1hZY",
+		"kty": "EC",
+		"crv": "P-384",
+		"x": "GnLl6mDti7a2VUIZP5w6pcRX8q5nvEIgB3Q_5RI2p9F_QVsaAlDN7IG68Jn0dS_F",
+		"y": "jq4QoAHKiIzezDp88s_cxSPXtuXYFliuCGndgU4Qp8l91xzD1spCmFIzQgVjqvcP"
+	}`
+	didDocX25519JWK = `{
+		"kid": "#z6LSj72tK8brWgZja8NLRwPigth2T9QRiG1uH9oKZuKjdh9p",
+		"kty": "OKP",
+		"crv": "X25519",
+		"x": "pE_mG098rdQjY3MKK2D5SUQ6ZOEW3a6Z6T7Z4SgnzCE"
+	}`
+)
+
+func TestPublicKeyJWKRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		jwk  string
+	}{
+		{name: "P-256 EC key", jwk: didDocP256JWK},
+		{name: "P-384 EC key", jwk: didDocP384JWK},
+		{name: "X25519 OKP key", jwk: didDocX25519JWK},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			pubKey, err := PublicKeyFromJWK([]byte(tc.jwk))
+			require.NoError(t, err)
+
+			jwkBytes, err := PublicKeyToJWK(pubKey)
+			require.NoError(t, err)
+
+			expected := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal([]byte(tc.jwk), &expected))
+
+			expected["use"] = "enc"
+
+			actual := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal(jwkBytes, &actual))
+			require.Equal(t, expected, actual)
+
+			roundTripKey, err := PublicKeyFromJWK(jwkBytes)
+			require.NoError(t, err)
+			require.Equal(t, pubKey, roundTripKey)
+		})
+	}
+}
+
+func TestPublicKeyToJWK(t *testing.T) {
+	pvt, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+
+	pubKey := &PublicKey{
+		KID:   "key-1",
+		Type:  "EC",
+		Curve: "NIST_P521",
+		X:     pvt.X.Bytes(),
+		Y:     pvt.Y.Bytes(),
+	}
+
+	t.Run("EC key with a proto curve name", func(t *testing.T) {
+		jwkBytes, err := PublicKeyToJWK(pubKey)
+		require.NoError(t, err)
+
+		jwk := &publicKeyJWK{}
+		require.NoError(t, json.Unmarshal(jwkBytes, jwk))
+		require.Equal(t, "key-1", jwk.KID)
+		require.Equal(t, "enc", jwk.Use)
+		require.Equal(t, "P-521", jwk.Crv)
+
+		// the coordinates are padded to the curve size
+		require.Len(t, jwk.X, 88)
+		require.Len(t, jwk.Y, 88)
+
+		jwkKey, err := PublicKeyFromJWK(jwkBytes)
+		require.NoError(t, err)
+		require.Equal(t, "P-521", jwkKey.Curve)
+
+		x, y, err := ECPoint(elliptic.P521(), jwkKey)
+		require.NoError(t, err)
+		require.Zero(t, x.Cmp(pvt.X))
+		require.Zero(t, y.Cmp(pvt.Y))
+	})
+
+	t.Run("EC key with a compressed point", func(t *testing.T) {
+		compressedKey := &PublicKey{
+			KID:   "key-1",
+			Type:  "EC",
+			Curve: "NIST_P521",
+			X:     CompressPoint(elliptic.P521(), pvt.X, pvt.Y),
+		}
+
+		jwkBytes, err := PublicKeyToJWK(compressedKey)
+		require.NoError(t, err)
+
+		expected, err := PublicKeyToJWK(pubKey)
+		require.NoError(t, err)
+		require.Equal(t, expected, jwkBytes)
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		_, err := PublicKeyToJWK(nil)
+		require.EqualError(t, err, "publicKeyToJWK: public key is nil")
+
+		_, err = PublicKeyToJWK(&PublicKey{Type: "RSA"})
+		require.EqualError(t, err, "publicKeyToJWK: key type RSA not supported")
+
+		_, err = PublicKeyToJWK(&PublicKey{Type: "EC", Curve: "secp256k1", X: pubKey.X, Y: pubKey.Y})
+		require.EqualError(t, err, "publicKeyToJWK: unsupported curve")
+
+		_, err = PublicKeyToJWK(&PublicKey{Type: "EC", Curve: "P-521", X: pubKey.X, Y: pubKey.X})
+		require.EqualError(t, err, "publicKeyToJWK: point is not on curve")
+
+		_, err = PublicKeyToJWK(&PublicKey{Type: "EC", Curve: "P-521", X: pubKey.X})
+		require.EqualError(t, err, "publicKeyToJWK: invalid compressed EC point")
+
+		_, err = PublicKeyToJWK(&PublicKey{Type: "OKP", Curve: "P-256", X: make([]byte, 32)})
+		require.EqualError(t, err, "publicKeyToJWK: curve P-256 not supported for OKP keys")
+
+		_, err = PublicKeyToJWK(&PublicKey{Type: "OKP", Curve: "X25519", X: make([]byte, 31)})
+		require.EqualError(t, err, "publicKeyToJWK: 'x' must be 32 bytes long for curve X25519")
+	})
+}
+
+func TestPublicKeyFromJWK(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		jwk    string
+		errMsg string
+	}{
+		{
+			name:   "invalid JSON",
+			jwk:    `{`,
+			errMsg: "publicKeyFromJWK: unexpected end of JSON input",
+		},
+		{
+			name:   "signing key",
+			jwk:    `{"kty": "OKP", "crv": "X25519", "use": "sig", "x": "pE_mG098rdQjY3MKK2D5SUQ6ZOEW3a6Z6T7Z4SgnzCE"}`,
+			errMsg: "publicKeyFromJWK: key use sig not supported",
+		},
+		{
+			name:   "unsupported key type",
+			jwk:    `{"kty": "RSA", "n": "AQAB", "e": "AQAB"}`,
+			errMsg: "publicKeyFromJWK: key type RSA not supported",
+		},
+		{
+			name:   "unsupported OKP curve",
+			jwk:    `{"kty": "OKP", "crv": "Ed25519", "x": "pE_mG098rdQjY3MKK2D5SUQ6ZOEW3a6Z6T7Z4SgnzCE"}`,
+			errMsg: "publicKeyFromJWK: curve Ed25519 not supported for OKP keys",
+		},
+		{
+			name:   "unsupported EC curve",
+			jwk:    `{"kty": "EC", "crv": "secp256k1", "x": "AQAB", "y": "AQAB"}`,
+			errMsg: "publicKeyFromJWK: unsupported curve",
+		},
+		{
+			name:   "invalid x",
+			jwk:    `{"kty": "EC", "crv": "P-256", "x": "!", "y": "AQAB"}`,
+			errMsg: "publicKeyFromJWK: invalid 'x': illegal base64 data at input byte 0",
+		},
+		{
+			name:   "invalid y",
+			jwk:    `{"kty": "EC", "crv": "P-256", "x": "AQAB", "y": "!"}`,
+			errMsg: "publicKeyFromJWK: invalid 'y': illegal base64 data at input byte 0",
+		},
+		{
+			name:   "short coordinates",
+			jwk:    `{"kty": "EC", "crv": "P-256", "x": "AQAB", "y": "AQAB"}`,
+			errMsg: "publicKeyFromJWK: 'x' and 'y' must be 32 bytes long for curve P-256",
+		},
+		{
+			name: "point not on curve",
+			jwk: `{"kty": "EC", "crv": "P-256", "x": "38M1FDts7Oea7urmseiugGW7tWc3mLpJh6rKe7xINZ8",
+				"y": "38M1FDts7Oea7urmseiugGW7tWc3mLpJh6rKe7xINZ8"}`,
+			errMsg: "publicKeyFromJWK: point is not on curve",
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := PublicKeyFromJWK([]byte(tc.jwk))
+			require.EqualError(t, err, tc.errMsg)
+		})
+	}
+}
+
+func TestEPKJWK(t *testing.T) {
+	t.Run("EC EPK round trip", func(t *testing.T) {
+		pvt, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		rec := &RecipientWrappedKey{
+			KID: "kid",
+			EPK: PublicKey{
+				X:     pvt.X.Bytes(),
+				Y:     pvt.Y.Bytes(),
+				Curve: "P-384",
+				Type:  "EC",
+			},
+		}
+
+		epkJWK, err := EPKToJWK(rec)
+		require.NoError(t, err)
+
+		jwk := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(epkJWK, &jwk))
+		require.Equal(t, "kid", jwk["kid"])
+		require.Equal(t, "EC", jwk["kty"])
+		require.Equal(t, "P-384", jwk["crv"])
+		require.Contains(t, jwk, "y")
+
+		parsed, err := EPKFromJWK(epkJWK)
+		require.NoError(t, err)
+		require.Equal(t, rec, parsed)
+	})
+
+	t.Run("compressed EC EPK round trip", func(t *testing.T) {
+		curve := elliptic.P256()
+
+		pvt, err := ecdsa.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+
+		rec := &RecipientWrappedKey{
+			KID: "kid",
+			EPK: PublicKey{
+				X:     CompressPoint(curve, pvt.X, pvt.Y),
+				Curve: curve.Params().Name,
+				Type:  "EC",
+			},
+		}
+
+		epkJWK, err := EPKToJWK(rec)
+		require.NoError(t, err)
+		require.NotContains(t, string(epkJWK), `"y"`)
+
+		parsed, err := EPKFromJWK(epkJWK)
+		require.NoError(t, err)
+		require.Equal(t, "kid", parsed.KID)
+		require.Equal(t, pvt.X.Bytes(), parsed.EPK.X)
+		require.Equal(t, pvt.Y.Bytes(), parsed.EPK.Y)
+	})
+
+	t.Run("X25519 EPK round trip", func(t *testing.T) {
+		pubKey, err := PublicKeyFromJWK([]byte(didDocX25519JWK))
+		require.NoError(t, err)
+
+		pubKey.KID = ""
+
+		rec := &RecipientWrappedKey{KID: "kid", EPK: *pubKey}
+
+		epkJWK, err := EPKToJWK(rec)
+		require.NoError(t, err)
+		require.NotContains(t, string(epkJWK), `"y"`)
+
+		parsed, err := EPKFromJWK(epkJWK)
+		require.NoError(t, err)
+		require.Equal(t, rec, parsed)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		_, err := EPKToJWK(nil)
+		require.EqualError(t, err, "epkToJWK: recipient wrapped key is nil")
+
+		_, err = EPKToJWK(&RecipientWrappedKey{EPK: PublicKey{Curve: "badCurveName"}})
+		require.EqualError(t, err, "unsupported curve")
+
+		_, err = EPKFromJWK([]byte(`{`))
+		require.EqualError(t, err, "epkFromJWK: unexpected end of JSON input")
+
+		_, err = EPKFromJWK([]byte(`{"kty": "EC", "crv": "P-256", "use": "sig", "x": "AQAB"}`))
+		require.EqualError(t, err, "epkFromJWK: key use sig not supported")
+
+		_, err = EPKFromJWK([]byte(`{"kty": "EC", "crv": "secp256k1", "x": "AQAB"}`))
+		require.EqualError(t, err, "epkFromJWK: unsupported curve")
+
+		_, err = EPKFromJWK([]byte(`{"kty": "EC", "crv": "P-256", "x": "!"}`))
+		require.EqualError(t, err, "epkFromJWK: invalid 'x': illegal base64 data at input byte 0")
+
+		_, err = EPKFromJWK([]byte(`{"kty": "EC", "crv": "P-256", "x": "AQAB"}`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "epkFromJWK: ")
+
+		_, err = EPKFromJWK([]byte(`{"kty": "EC", "crv": "P-256", "x": "AQAB", "y": "AQAB"}`))
+		require.EqualError(t, err, "publicKeyFromJWK: 'x' and 'y' must be 32 bytes long for curve P-256")
+	})
+}
@@ -0,0 +1,51 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package composite
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RecipientResolver resolves the encryption public keys of recipients identified by their DID, ie the keyAgreement
+// keys of their DID documents. It is optional: the ...WithRecipients key templates can be called with the recipients
+// keys directly.
+type RecipientResolver interface {
+	// ResolveRecipientKeys returns the encryption public keys of the recipient identified by did. Verification
+	// methods that aren't encryption keys are skipped.
+	ResolveRecipientKeys(did string) ([]*PublicKey, error)
+}
+
+// ResolveRecipients resolves the encryption public keys of all the recipients identified by dids with resolver, in
+// the order of dids. It fails if a DID resolves to zero usable keys since the recipient would not be able to decrypt
+// the message.
+func ResolveRecipients(resolver RecipientResolver, dids []string) ([]*PublicKey, error) {
+	if resolver == nil {
+		return nil, errors.New("resolveRecipients: recipient resolver is nil")
+	}
+
+	if len(dids) == 0 {
+		return nil, errors.New("resolveRecipients: no recipient DID")
+	}
+
+	var recPublicKeys []*PublicKey
+
+	for _, did := range dids {
+		keys, err := resolver.ResolveRecipientKeys(did)
+		if err != nil {
+			return nil, fmt.Errorf("resolveRecipients: failed to resolve keys of recipient %s: %w", did, err)
+		}
+
+		if len(keys) == 0 {
+			return nil, fmt.Errorf("resolveRecipients: recipient %s has no encryption key", did)
+		}
+
+		recPublicKeys = append(recPublicKeys, keys...)
+	}
+
+	return recPublicKeys, nil
+}
@@ -7,25 +7,26 @@ SPDX-License-Identifier: Apache-2.0
 package composite
 
 import (
-	"crypto/ecdsa"
-	"crypto/elliptic"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"math/big"
 
 	"github.com/golang/protobuf/proto"
 	aead "github.com/google/tink/go/aead/subtle"
 	"github.com/google/tink/go/core/registry"
-	hybrid "github.com/google/tink/go/hybrid/subtle"
 	gcmpb "github.com/google/tink/go/proto/aes_gcm_go_proto"
 	chachapb "github.com/google/tink/go/proto/chacha20_poly1305_go_proto"
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 	xchachapb "github.com/google/tink/go/proto/xchacha20_poly1305_go_proto"
 	"github.com/google/tink/go/tink"
-	"github.com/square/go-jose/v3"
 	"golang.org/x/crypto/chacha20poly1305"
 	"golang.org/x/crypto/poly1305"
+
+	// register the AES-CBC-HMAC-SHA2 key manager needed to create CBC-HMAC content encryption primitives.
+	_ "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/aead"
+	cbchmac "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/aead/subtle"
+	cbchmacpb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/aes_cbc_hmac_aead_go_proto"
+	"github.com/hyperledger/aries-framework-go/pkg/internal/cryptoutil"
 )
 
 const (
@@ -35,13 +36,39 @@ const (
 	ChaCha20Poly1305TypeURL = "type.googleapis.com/google.crypto.tink.ChaCha20Poly1305Key"
 	// XChaCha20Poly1305TypeURL for XChachaPoly1305 content encryption URL identifier
 	XChaCha20Poly1305TypeURL = "type.googleapis.com/google.crypto.tink.XChaCha20Poly1305Key"
+	// AESCBCHMACTypeURL for AES-CBC-HMAC-SHA2 content encryption URL identifier
+	AESCBCHMACTypeURL = "type.hyperledger.org/hyperledger.aries.crypto.tink.AesCbcHmacAeadKey"
+
+	// A128GCM is the AES128-GCM content encryption algorithm value as per
+	// the JWA specification: https://tools.ietf.org/html/rfc7518#section-5.1
+	A128GCM = "A128GCM"
+	// A256GCM is the AES256-GCM content encryption algorithm value as per
+	// the JWA specification: https://tools.ietf.org/html/rfc7518#section-5.1
+	A256GCM = "A256GCM"
+	// C20P is the ChaCha20Poly1305 content encryption algorithm value
+	C20P = "C20P"
+	// XC20P is the XChaCha20Poly1305 content encryption algorithm value
+	XC20P = "XC20P"
+	// A128CBCHS256 is the AES128-CBC-HMAC-SHA256 content encryption algorithm value as per
+	// the JWA specification: https://tools.ietf.org/html/rfc7518#section-5.2.3
+	A128CBCHS256 = "A128CBC-HS256"
+	// A192CBCHS384 is the AES192-CBC-HMAC-SHA384 content encryption algorithm value as per
+	// the JWA specification: https://tools.ietf.org/html/rfc7518#section-5.2.4
+	A192CBCHS384 = "A192CBC-HS384"
+	// A256CBCHS512 is the AES256-CBC-HMAC-SHA512 content encryption algorithm value as per
+	// the JWA specification: https://tools.ietf.org/html/rfc7518#section-5.2.5
+	A256CBCHS512 = "A256CBC-HS512"
 )
 
+// aes128KeySize is the key size in bytes of AES128-GCM content encryption
+const aes128KeySize = 16
+
 type marshalFunc func(interface{}) ([]byte, error)
 
 // RegisterCompositeAEADEncHelper registers a content encryption helper
 type RegisterCompositeAEADEncHelper struct {
 	encKeyURL        string
+	encAlg           string
 	keyData          []byte
 	symmetricKeySize int
 	tagSize          int
@@ -55,6 +82,7 @@ var _ EncrypterHelper = (*RegisterCompositeAEADEncHelper)(nil)
 func NewRegisterCompositeAEADEncHelper(k *tinkpb.KeyTemplate) (*RegisterCompositeAEADEncHelper, error) {
 	var (
 		keySize, tagSize, ivSize int
+		encAlg                   string
 		skf                      []byte
 		err                      error
 	)
@@ -71,19 +99,45 @@ func NewRegisterCompositeAEADEncHelper(k *tinkpb.KeyTemplate) (*RegisterComposit
 		keySize = int(gcmKeyFormat.KeySize)
 		tagSize = aead.AESGCMTagSize
 		ivSize = aead.AESGCMIVSize
+		encAlg = A256GCM
+
+		if keySize == aes128KeySize {
+			encAlg = A128GCM
+		}
 
 		skf, err = proto.Marshal(gcmKeyFormat)
 		if err != nil {
 			return nil, fmt.Errorf("compositeAEADEncHelper: failed to serialize key format, error: %w", err)
 		}
+	case AESCBCHMACTypeURL:
+		cbcHMACKeyFormat := new(cbchmacpb.AesCbcHmacAeadKeyFormat)
+
+		err = proto.Unmarshal(k.Value, cbcHMACKeyFormat)
+		if err != nil {
+			return nil, fmt.Errorf("compositeAEADEncHelper: failed to unmarshal cbcHMACKeyFormat: %w", err)
+		}
+
+		keySize = int(cbcHMACKeyFormat.KeySize)
+		// the tag is half of the HMAC output, its size is the MAC key size, ie half of the composite key size.
+		tagSize = keySize / 2
+		ivSize = cbchmac.AESCBCIVSize
+
+		encAlg, err = cbcHMACEncAlg(keySize)
+		if err != nil {
+			return nil, fmt.Errorf("compositeAEADEncHelper: %w", err)
+		}
+
+		skf = k.Value
 	case ChaCha20Poly1305TypeURL:
 		keySize = chacha20poly1305.KeySize
 		tagSize = poly1305.TagSize
 		ivSize = chacha20poly1305.NonceSize
+		encAlg = C20P
 	case XChaCha20Poly1305TypeURL:
 		keySize = chacha20poly1305.KeySize
 		tagSize = poly1305.TagSize
 		ivSize = chacha20poly1305.NonceSizeX
+		encAlg = XC20P
 	default:
 		return nil, fmt.Errorf("compositeAEADEncHelper: unsupported AEAD content encryption key type: %s",
 			k.TypeUrl)
@@ -107,6 +161,7 @@ func NewRegisterCompositeAEADEncHelper(k *tinkpb.KeyTemplate) (*RegisterComposit
 
 	return &RegisterCompositeAEADEncHelper{
 		encKeyURL:        k.TypeUrl,
+		encAlg:           encAlg,
 		keyData:          sk,
 		symmetricKeySize: keySize,
 		tagSize:          tagSize,
@@ -115,6 +170,20 @@ func NewRegisterCompositeAEADEncHelper(k *tinkpb.KeyTemplate) (*RegisterComposit
 	}, nil
 }
 
+// cbcHMACEncAlg returns the AES-CBC-HMAC-SHA2 content encryption algorithm value of the composite key size.
+func cbcHMACEncAlg(keySize int) (string, error) {
+	switch keySize {
+	case cbchmac.AES128CBCHMACSHA256KeySize:
+		return A128CBCHS256, nil
+	case cbchmac.AES192CBCHMACSHA384KeySize:
+		return A192CBCHS384, nil
+	case cbchmac.AES256CBCHMACSHA512KeySize:
+		return A256CBCHS512, nil
+	default:
+		return "", fmt.Errorf("invalid AES-CBC-HMAC-SHA2 key size: %d", keySize)
+	}
+}
+
 // GetSymmetricKeySize returns the symmetric key size
 func (r *RegisterCompositeAEADEncHelper) GetSymmetricKeySize() int {
 	return r.symmetricKeySize
@@ -130,6 +199,11 @@ func (r *RegisterCompositeAEADEncHelper) GetIVSize() int {
 	return r.ivSize
 }
 
+// GetEncAlg returns the content encryption algorithm value of the primitive
+func (r *RegisterCompositeAEADEncHelper) GetEncAlg() string {
+	return r.encAlg
+}
+
 // GetAEAD returns the AEAD primitive from the DEM
 func (r *RegisterCompositeAEADEncHelper) GetAEAD(symmetricKeyValue []byte) (tink.AEAD, error) {
 	if len(symmetricKeyValue) != r.GetSymmetricKeySize() {
@@ -141,6 +215,9 @@ func (r *RegisterCompositeAEADEncHelper) GetAEAD(symmetricKeyValue []byte) (tink
 		return nil, err
 	}
 
+	// the serialized key holds a copy of the symmetric key, the primitive is created with its own copy
+	defer cryptoutil.Zeroize(sk)
+
 	p, err := registry.Primitive(r.encKeyURL, sk)
 	if err != nil {
 		return nil, err
@@ -166,6 +243,20 @@ func (r *RegisterCompositeAEADEncHelper) getSerializedKey(symmetricKeyValue []by
 		if err != nil {
 			return nil, fmt.Errorf("registerCompositeAEADEncHelper: failed to serialize key, error: %w", err)
 		}
+	case AESCBCHMACTypeURL:
+		cbcHMACKey := new(cbchmacpb.AesCbcHmacAeadKey)
+
+		err = proto.Unmarshal(r.keyData, cbcHMACKey)
+		if err != nil {
+			return nil, fmt.Errorf("registerCompositeAEADEncHelper: failed to unmarshal cbcHMAC key: %w", err)
+		}
+
+		cbcHMACKey.KeyValue = symmetricKeyValue
+
+		sk, err = proto.Marshal(cbcHMACKey)
+		if err != nil {
+			return nil, fmt.Errorf("registerCompositeAEADEncHelper: failed to serialize key, error: %w", err)
+		}
 	case ChaCha20Poly1305TypeURL:
 		chachaKey := new(chachapb.ChaCha20Poly1305Key)
 
@@ -267,7 +358,7 @@ func (r *RegisterCompositeAEADEncHelper) MergeSingleRecipientHeaders(recipientWK
 
 	rawHeaders["alg"] = alg
 
-	mEPK, err := convertRecKeyToMarshalledJWK(recipientWK)
+	mEPK, err := EPKToJWK(recipientWK)
 	if err != nil {
 		return nil, err
 	}
@@ -282,27 +373,6 @@ func (r *RegisterCompositeAEADEncHelper) MergeSingleRecipientHeaders(recipientWK
 	return []byte(base64.RawURLEncoding.EncodeToString(mAAD)), nil
 }
 
-func convertRecKeyToMarshalledJWK(rec *RecipientWrappedKey) ([]byte, error) {
-	var c elliptic.Curve
-
-	c, err := hybrid.GetCurve(rec.EPK.Curve)
-	if err != nil {
-		return nil, err
-	}
-
-	recJWK := jose.JSONWebKey{
-		KeyID: rec.KID,
-		Use:   "enc",
-		Key: &ecdsa.PublicKey{
-			Curve: c,
-			X:     new(big.Int).SetBytes(rec.EPK.X),
-			Y:     new(big.Int).SetBytes(rec.EPK.Y),
-		},
-	}
-
-	return recJWK.MarshalJSON()
-}
-
 // BuildDecData will build the []byte representing the ciphertext coming from encData struct returned as a result of
 // Composite Encrypt() call to prepare the Composite Decryption primitive execution.
 func (r *RegisterCompositeAEADEncHelper) BuildDecData(encData *EncryptedData) []byte {
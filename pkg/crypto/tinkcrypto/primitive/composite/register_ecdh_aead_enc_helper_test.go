@@ -23,6 +23,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/chacha20poly1305"
 	"golang.org/x/crypto/poly1305"
+
+	cbchmacaead "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/aead"
+	cbchmac "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/aead/subtle"
 )
 
 var (
@@ -32,6 +35,9 @@ var (
 		aead.XChaCha20Poly1305KeyTemplate(): 32,
 		aead.AES256GCMKeyTemplate():         32,
 		aead.AES128GCMKeyTemplate():         16,
+
+		cbchmacaead.AES128CBCHMACSHA256KeyTemplate(): 32,
+		cbchmacaead.AES256CBCHMACSHA512KeyTemplate(): 64,
 	}
 )
 
@@ -46,12 +52,31 @@ func TestCipherGetters(t *testing.T) {
 		case AESGCMTypeURL:
 			require.EqualValues(t, subtleaead.AESGCMIVSize, rDem.GetIVSize())
 			require.EqualValues(t, subtleaead.AESGCMTagSize, rDem.GetTagSize())
+
+			if l == 16 {
+				require.Equal(t, A128GCM, rDem.GetEncAlg())
+			} else {
+				require.Equal(t, A256GCM, rDem.GetEncAlg())
+			}
 		case ChaCha20Poly1305TypeURL:
 			require.EqualValues(t, chacha20poly1305.NonceSize, rDem.GetIVSize())
 			require.EqualValues(t, poly1305.TagSize, rDem.GetTagSize())
+			require.Equal(t, C20P, rDem.GetEncAlg())
 		case XChaCha20Poly1305TypeURL:
 			require.EqualValues(t, chacha20poly1305.NonceSizeX, rDem.GetIVSize())
 			require.EqualValues(t, poly1305.TagSize, rDem.GetTagSize())
+			require.Equal(t, XC20P, rDem.GetEncAlg())
+		case AESCBCHMACTypeURL:
+			require.EqualValues(t, cbchmac.AESCBCIVSize, rDem.GetIVSize())
+			require.EqualValues(t, l/2, rDem.GetTagSize())
+
+			if l == 32 {
+				require.Equal(t, A128CBCHS256, rDem.GetEncAlg())
+			} else {
+				require.Equal(t, A256CBCHS512, rDem.GetEncAlg())
+			}
+		default:
+			require.Fail(t, "unexpected key URL", rDem.encKeyURL)
 		}
 	}
 }
@@ -63,6 +88,8 @@ func TestUnsupportedKeyTemplates(t *testing.T) {
 		{TypeUrl: "some url", Value: []byte{0}},
 		{TypeUrl: AESGCMTypeURL},
 		{TypeUrl: AESGCMTypeURL, Value: []byte("123")},
+		{TypeUrl: AESCBCHMACTypeURL, Value: []byte("123")},
+		{TypeUrl: AESCBCHMACTypeURL, Value: []byte{0x08, 0x10}}, // key size 16 is not a valid composite key size
 	}
 
 	for _, l := range uTemplates {
@@ -0,0 +1,100 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package composite
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	streamingaead "github.com/google/tink/go/streamingaead/subtle"
+)
+
+// Streaming composite encryption writes a stream header followed by a Tink AES-GCM-HKDF streaming AEAD ciphertext. The
+// stream header is a 4 bytes big endian length prefixed JSON EncryptedData holding the content encryption algorithm
+// and the recipients wrapped keys, its Ciphertext, IV and Tag are empty. The CEK wrapped for each recipient is the
+// main key of the streaming AEAD, the content is encrypted in segments of StreamSegmentSize bytes, each segment has
+// its own nonce and tag.
+const (
+	// AES256GCMHKDFStream is the content encryption algorithm value of the stream header. It is not a JWA value,
+	// streams are not JWE messages.
+	AES256GCMHKDFStream = "A256GCM-HKDF-STREAM"
+	// StreamCEKSize is the size in bytes of the CEK of a stream, ie the streaming AEAD main key size.
+	StreamCEKSize = 32
+	// StreamSegmentSize is the size in bytes of a ciphertext segment of a stream.
+	StreamSegmentSize = 4096
+
+	streamHKDFAlg             = "SHA256"
+	streamHeaderLenPrefixSize = 4
+	// maxStreamHeaderSize caps the stream header size to avoid allocating an arbitrary header length read from a
+	// corrupted or malicious stream.
+	maxStreamHeaderSize = 1 << 20
+)
+
+// NewStreamingAEAD returns the AES256-GCM-HKDF streaming AEAD primitive encrypting the content of a stream with cek.
+func NewStreamingAEAD(cek []byte) (*streamingaead.AESGCMHKDF, error) {
+	if len(cek) != StreamCEKSize {
+		return nil, fmt.Errorf("newStreamingAEAD: invalid CEK size %d", len(cek))
+	}
+
+	return streamingaead.NewAESGCMHKDF(cek, streamHKDFAlg, StreamCEKSize, StreamSegmentSize, 0)
+}
+
+// WriteStreamHeader writes the stream header holding encData to w.
+func WriteStreamHeader(w io.Writer, encData *EncryptedData) error {
+	header, err := json.Marshal(encData)
+	if err != nil {
+		return fmt.Errorf("writeStreamHeader: failed to marshal stream header: %w", err)
+	}
+
+	frame := make([]byte, streamHeaderLenPrefixSize+len(header))
+	binary.BigEndian.PutUint32(frame, uint32(len(header)))
+	copy(frame[streamHeaderLenPrefixSize:], header)
+
+	_, err = w.Write(frame)
+	if err != nil {
+		return fmt.Errorf("writeStreamHeader: %w", err)
+	}
+
+	return nil
+}
+
+// ReadStreamHeader reads the stream header written by WriteStreamHeader from r. It reads the header bytes only, the
+// streaming AEAD ciphertext remains in r. It returns the parsed header and its raw bytes (including the length prefix)
+// for callers trying several keys on the same stream.
+func ReadStreamHeader(r io.Reader) (*EncryptedData, []byte, error) {
+	lenPrefix := make([]byte, streamHeaderLenPrefixSize)
+
+	_, err := io.ReadFull(r, lenPrefix)
+	if err != nil {
+		return nil, nil, fmt.Errorf("readStreamHeader: failed to read stream header length: %w", err)
+	}
+
+	headerLen := binary.BigEndian.Uint32(lenPrefix)
+	if headerLen == 0 || headerLen > maxStreamHeaderSize {
+		return nil, nil, errors.New("readStreamHeader: invalid stream header length")
+	}
+
+	frame := make([]byte, streamHeaderLenPrefixSize+int(headerLen))
+	copy(frame, lenPrefix)
+
+	_, err = io.ReadFull(r, frame[streamHeaderLenPrefixSize:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("readStreamHeader: failed to read stream header: %w", err)
+	}
+
+	encData := new(EncryptedData)
+
+	err = json.Unmarshal(frame[streamHeaderLenPrefixSize:], encData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("readStreamHeader: failed to unmarshal stream header: %w", err)
+	}
+
+	return encData, frame, nil
+}
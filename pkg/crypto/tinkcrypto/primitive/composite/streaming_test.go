@@ -0,0 +1,81 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package composite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/tink/go/subtle/random"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamHeader(t *testing.T) {
+	encData := &EncryptedData{
+		EncAlg: AES256GCMHKDFStream,
+		Recipients: []*RecipientWrappedKey{
+			{KID: "kid1", EncryptedCEK: []byte("wrapped cek 1"), Alg: "ECDH-ES+A256KW"},
+			{KID: "kid2", EncryptedCEK: []byte("wrapped cek 2"), Alg: "ECDH-ES+A256KW"},
+		},
+	}
+
+	stream := new(bytes.Buffer)
+
+	err := WriteStreamHeader(stream, encData)
+	require.NoError(t, err)
+
+	headerSize := stream.Len()
+
+	stream.WriteString("stream content")
+
+	readEncData, header, err := ReadStreamHeader(stream)
+	require.NoError(t, err)
+	require.Equal(t, encData, readEncData)
+	require.Len(t, header, headerSize)
+
+	// the content remains in the stream
+	content, err := ioutil.ReadAll(stream)
+	require.NoError(t, err)
+	require.Equal(t, "stream content", string(content))
+
+	t.Run("failure cases", func(t *testing.T) {
+		_, _, err = ReadStreamHeader(bytes.NewReader([]byte{0, 0}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "readStreamHeader: failed to read stream header length")
+
+		lenPrefix := make([]byte, streamHeaderLenPrefixSize)
+
+		_, _, err = ReadStreamHeader(bytes.NewReader(lenPrefix))
+		require.EqualError(t, err, "readStreamHeader: invalid stream header length")
+
+		binary.BigEndian.PutUint32(lenPrefix, maxStreamHeaderSize+1)
+
+		_, _, err = ReadStreamHeader(bytes.NewReader(lenPrefix))
+		require.EqualError(t, err, "readStreamHeader: invalid stream header length")
+
+		binary.BigEndian.PutUint32(lenPrefix, 10)
+
+		_, _, err = ReadStreamHeader(bytes.NewReader(append(lenPrefix, []byte("short")...)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "readStreamHeader: failed to read stream header")
+
+		_, _, err = ReadStreamHeader(bytes.NewReader(append(lenPrefix, []byte("not json!!")...)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "readStreamHeader: failed to unmarshal stream header")
+	})
+}
+
+func TestNewStreamingAEAD(t *testing.T) {
+	sa, err := NewStreamingAEAD(random.GetRandomBytes(StreamCEKSize))
+	require.NoError(t, err)
+	require.NotNil(t, sa)
+
+	_, err = NewStreamingAEAD(random.GetRandomBytes(16))
+	require.EqualError(t, err, "newStreamingAEAD: invalid CEK size 16")
+}
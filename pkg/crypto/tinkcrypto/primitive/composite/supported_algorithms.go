@@ -0,0 +1,112 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package composite
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/google/tink/go/core/registry"
+)
+
+// CurvesSupporter is implemented by the ECDH key wrapping key managers to list the curves of the keys they manage.
+// Curves are named as in the JWK 'crv' parameter (eg "P-256" or "X25519").
+type CurvesSupporter interface {
+	SupportedCurves() []string
+}
+
+// contentEncryptionAlgs lists the content encryption algorithms supported by RegisterCompositeAEADEncHelper for each
+// AEAD key type URL.
+// nolint:gochecknoglobals
+var contentEncryptionAlgs = map[string][]string{
+	AESGCMTypeURL:            {A128GCM, A256GCM},
+	AESCBCHMACTypeURL:        {A128CBCHS256, A192CBCHS384, A256CBCHS512},
+	ChaCha20Poly1305TypeURL:  {C20P},
+	XChaCha20Poly1305TypeURL: {XC20P},
+}
+
+// nolint:gochecknoglobals
+var (
+	kwKeyManagersMu sync.RWMutex
+	kwKeyManagers   []string
+)
+
+// RegisterKeyWrappingKeyManager registers km in the tink registry. If km implements CurvesSupporter, its curves are
+// added to the curves returned by SupportedCurves.
+func RegisterKeyWrappingKeyManager(km registry.KeyManager) error {
+	err := registry.RegisterKeyManager(km)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := km.(CurvesSupporter); !ok {
+		return nil
+	}
+
+	kwKeyManagersMu.Lock()
+	defer kwKeyManagersMu.Unlock()
+
+	kwKeyManagers = append(kwKeyManagers, km.TypeURL())
+
+	return nil
+}
+
+// SupportedCurves returns the sorted list of curves supported by the registered ECDH key wrapping key managers.
+func SupportedCurves() []string {
+	kwKeyManagersMu.RLock()
+	defer kwKeyManagersMu.RUnlock()
+
+	curves := map[string]struct{}{}
+
+	for _, typeURL := range kwKeyManagers {
+		km, err := registry.GetKeyManager(typeURL)
+		if err != nil {
+			continue
+		}
+
+		cs, ok := km.(CurvesSupporter)
+		if !ok {
+			continue
+		}
+
+		for _, c := range cs.SupportedCurves() {
+			curves[c] = struct{}{}
+		}
+	}
+
+	return sortedKeys(curves)
+}
+
+// SupportedContentEncryption returns the sorted list of content encryption algorithms (JWE 'enc' values) whose AEAD
+// key manager is registered in the tink registry.
+func SupportedContentEncryption() []string {
+	algs := map[string]struct{}{}
+
+	for typeURL, encAlgs := range contentEncryptionAlgs {
+		if _, err := registry.GetKeyManager(typeURL); err != nil {
+			continue
+		}
+
+		for _, alg := range encAlgs {
+			algs[alg] = struct{}{}
+		}
+	}
+
+	return sortedKeys(algs)
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,75 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package composite
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	// register the tink AEAD key managers.
+	_ "github.com/google/tink/go/aead"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupportedCurves(t *testing.T) {
+	km := &mockKWKeyManager{
+		typeURL: "type.hyperledger.org/hyperledger.aries.crypto.tink.MockKWKey",
+		curves:  []string{"P-384", "mock-curve"},
+	}
+
+	require.NotContains(t, SupportedCurves(), "mock-curve")
+
+	err := RegisterKeyWrappingKeyManager(km)
+	require.NoError(t, err)
+
+	curves := SupportedCurves()
+	require.Contains(t, curves, "P-384")
+	require.Contains(t, curves, "mock-curve")
+	require.True(t, sort.StringsAreSorted(curves))
+
+	// registering the same key manager twice fails
+	err = RegisterKeyWrappingKeyManager(km)
+	require.Error(t, err)
+	require.Equal(t, curves, SupportedCurves())
+}
+
+func TestSupportedContentEncryption(t *testing.T) {
+	// the tink AEAD key managers and the AES-CBC-HMAC-SHA2 key manager are registered.
+	require.Equal(t, []string{A128CBCHS256, A128GCM, A192CBCHS384, A256CBCHS512, A256GCM, C20P, XC20P},
+		SupportedContentEncryption())
+}
+
+type mockKWKeyManager struct {
+	typeURL string
+	curves  []string
+}
+
+func (m *mockKWKeyManager) Primitive([]byte) (interface{}, error) {
+	return nil, nil
+}
+
+func (m *mockKWKeyManager) NewKey([]byte) (proto.Message, error) {
+	return nil, nil
+}
+
+func (m *mockKWKeyManager) NewKeyData([]byte) (*tinkpb.KeyData, error) {
+	return nil, nil
+}
+
+func (m *mockKWKeyManager) DoesSupport(typeURL string) bool {
+	return typeURL == m.typeURL
+}
+
+func (m *mockKWKeyManager) TypeURL() string {
+	return m.typeURL
+}
+
+func (m *mockKWKeyManager) SupportedCurves() []string {
+	return m.curves
+}
@@ -0,0 +1,139 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: proto/aes_cbc_hmac_aead.proto
+
+package aes_cbc_hmac_aead_go_proto
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type AesCbcHmacAeadKeyFormat struct {
+	// Size of the composite key (MAC_KEY || ENC_KEY) in bytes: 32 for A128CBC-HS256, 48 for A192CBC-HS384 and 64 for
+	// A256CBC-HS512.
+	KeySize              uint32   `protobuf:"varint,1,opt,name=key_size,json=keySize,proto3" json:"key_size,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AesCbcHmacAeadKeyFormat) Reset()         { *m = AesCbcHmacAeadKeyFormat{} }
+func (m *AesCbcHmacAeadKeyFormat) String() string { return proto.CompactTextString(m) }
+func (*AesCbcHmacAeadKeyFormat) ProtoMessage()    {}
+func (*AesCbcHmacAeadKeyFormat) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6aa7fe9228e3c289, []int{0}
+}
+
+func (m *AesCbcHmacAeadKeyFormat) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AesCbcHmacAeadKeyFormat.Unmarshal(m, b)
+}
+func (m *AesCbcHmacAeadKeyFormat) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AesCbcHmacAeadKeyFormat.Marshal(b, m, deterministic)
+}
+func (m *AesCbcHmacAeadKeyFormat) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AesCbcHmacAeadKeyFormat.Merge(m, src)
+}
+func (m *AesCbcHmacAeadKeyFormat) XXX_Size() int {
+	return xxx_messageInfo_AesCbcHmacAeadKeyFormat.Size(m)
+}
+func (m *AesCbcHmacAeadKeyFormat) XXX_DiscardUnknown() {
+	xxx_messageInfo_AesCbcHmacAeadKeyFormat.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AesCbcHmacAeadKeyFormat proto.InternalMessageInfo
+
+func (m *AesCbcHmacAeadKeyFormat) GetKeySize() uint32 {
+	if m != nil {
+		return m.KeySize
+	}
+	return 0
+}
+
+// key_type: type.hyperledger.org/hyperledger.aries.crypto.tink.AesCbcHmacAeadKey
+type AesCbcHmacAeadKey struct {
+	Version uint32 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	// The composite key value MAC_KEY || ENC_KEY.
+	KeyValue             []byte   `protobuf:"bytes,2,opt,name=key_value,json=keyValue,proto3" json:"key_value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *AesCbcHmacAeadKey) Reset()         { *m = AesCbcHmacAeadKey{} }
+func (m *AesCbcHmacAeadKey) String() string { return proto.CompactTextString(m) }
+func (*AesCbcHmacAeadKey) ProtoMessage()    {}
+func (*AesCbcHmacAeadKey) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6aa7fe9228e3c289, []int{1}
+}
+
+func (m *AesCbcHmacAeadKey) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AesCbcHmacAeadKey.Unmarshal(m, b)
+}
+func (m *AesCbcHmacAeadKey) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AesCbcHmacAeadKey.Marshal(b, m, deterministic)
+}
+func (m *AesCbcHmacAeadKey) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AesCbcHmacAeadKey.Merge(m, src)
+}
+func (m *AesCbcHmacAeadKey) XXX_Size() int {
+	return xxx_messageInfo_AesCbcHmacAeadKey.Size(m)
+}
+func (m *AesCbcHmacAeadKey) XXX_DiscardUnknown() {
+	xxx_messageInfo_AesCbcHmacAeadKey.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AesCbcHmacAeadKey proto.InternalMessageInfo
+
+func (m *AesCbcHmacAeadKey) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *AesCbcHmacAeadKey) GetKeyValue() []byte {
+	if m != nil {
+		return m.KeyValue
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*AesCbcHmacAeadKeyFormat)(nil), "google.crypto.tink.AesCbcHmacAeadKeyFormat")
+	proto.RegisterType((*AesCbcHmacAeadKey)(nil), "google.crypto.tink.AesCbcHmacAeadKey")
+}
+
+func init() { proto.RegisterFile("proto/aes_cbc_hmac_aead.proto", fileDescriptor_6aa7fe9228e3c289) }
+
+var fileDescriptor_6aa7fe9228e3c289 = []byte{
+	// 258 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x90, 0xc1, 0x4a, 0xc3, 0x40,
+	0x10, 0x86, 0x49, 0x0f, 0xad, 0x2e, 0x7a, 0x30, 0x17, 0x2b, 0x2a, 0x94, 0x9e, 0x7a, 0x69, 0xf6,
+	0xa0, 0x2f, 0xd0, 0x0a, 0xa2, 0x16, 0xa4, 0x54, 0xf1, 0xe0, 0x65, 0x99, 0x6c, 0xa6, 0x9b, 0x25,
+	0xd9, 0x4e, 0x98, 0x6c, 0x23, 0xdb, 0xb7, 0xf0, 0x15, 0x7c, 0x52, 0x49, 0x5a, 0x4f, 0xc5, 0xdb,
+	0x7e, 0x3b, 0xf3, 0xfd, 0xfc, 0x8c, 0xb8, 0xad, 0x98, 0x3c, 0x49, 0xc0, 0x5a, 0xe9, 0x54, 0xab,
+	0xdc, 0x81, 0x56, 0x80, 0x90, 0x25, 0xdd, 0x7f, 0x1c, 0x1b, 0x22, 0x53, 0x62, 0xa2, 0x39, 0x54,
+	0x9e, 0x12, 0x6f, 0x37, 0xc5, 0xf8, 0x5e, 0x5c, 0xce, 0xb0, 0x7e, 0x48, 0xf5, 0x93, 0x03, 0x3d,
+	0x43, 0xc8, 0x16, 0x18, 0x1e, 0x89, 0x1d, 0xf8, 0xf8, 0x4a, 0x9c, 0x14, 0x18, 0x54, 0x6d, 0x77,
+	0x38, 0x8c, 0x46, 0xd1, 0xe4, 0x7c, 0x35, 0x28, 0x30, 0xbc, 0xd9, 0x1d, 0x8e, 0x5f, 0xc4, 0xc5,
+	0x91, 0x15, 0x0f, 0xc5, 0xa0, 0x41, 0xae, 0x2d, 0x6d, 0xfe, 0xd6, 0x0f, 0x18, 0x5f, 0x8b, 0xd3,
+	0x36, 0xa9, 0x81, 0x72, 0x8b, 0xc3, 0xde, 0x28, 0x9a, 0x9c, 0xad, 0xda, 0xe8, 0x8f, 0x96, 0xe7,
+	0xdf, 0x91, 0xb8, 0xd1, 0xe4, 0x92, 0xe3, 0x72, 0xfb, 0xda, 0xcb, 0xe8, 0x73, 0x6d, 0xac, 0xcf,
+	0xb7, 0x69, 0xa2, 0xc9, 0xc9, 0x3c, 0x54, 0xc8, 0x25, 0x66, 0x06, 0x59, 0x02, 0x5b, 0xac, 0xa7,
+	0x6b, 0x06, 0x87, 0x5f, 0xc4, 0xc5, 0xd4, 0x90, 0xdc, 0xeb, 0xb2, 0xd5, 0x0f, 0xcf, 0x8a, 0xad,
+	0xb3, 0xde, 0x36, 0x28, 0xff, 0xb9, 0x8e, 0x32, 0xa4, 0xba, 0xd1, 0x4f, 0xaf, 0xff, 0xfe, 0xfc,
+	0xba, 0x58, 0xce, 0xd3, 0x7e, 0xc7, 0x77, 0xbf, 0x03, 0x00, 0xbc, 0xfe, 0xab, 0x13, 0x51, 0x01,
+	0x00, 0x00,
+}
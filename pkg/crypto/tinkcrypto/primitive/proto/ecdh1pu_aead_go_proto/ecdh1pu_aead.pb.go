@@ -6,9 +6,9 @@ package ecdh1pu_aead_go_proto
 import (
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
-	common_composite_go_proto "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
 	common_go_proto "github.com/google/tink/go/proto/common_go_proto"
 	tink_go_proto "github.com/google/tink/go/proto/tink_go_proto"
+	common_composite_go_proto "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
 	math "math"
 )
 
@@ -28,6 +28,8 @@ type Ecdh1PuKwParams struct {
 	KeyType              common_composite_go_proto.KeyType        `protobuf:"varint,2,opt,name=key_type,json=keyType,proto3,enum=google.crypto.tink.KeyType" json:"key_type,omitempty"`
 	Recipients           []*common_composite_go_proto.ECPublicKey `protobuf:"bytes,3,rep,name=recipients,proto3" json:"recipients,omitempty"`
 	Sender               *common_composite_go_proto.ECPublicKey   `protobuf:"bytes,4,opt,name=sender,proto3" json:"sender,omitempty"`
+	Apu                  []byte                                   `protobuf:"bytes,5,opt,name=apu,proto3" json:"apu,omitempty"`
+	Apv                  []byte                                   `protobuf:"bytes,6,opt,name=apv,proto3" json:"apv,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}                                 `json:"-"`
 	XXX_unrecognized     []byte                                   `json:"-"`
 	XXX_sizecache        int32                                    `json:"-"`
@@ -86,6 +88,20 @@ func (m *Ecdh1PuKwParams) GetSender() *common_composite_go_proto.ECPublicKey {
 	return nil
 }
 
+func (m *Ecdh1PuKwParams) GetApu() []byte {
+	if m != nil {
+		return m.Apu
+	}
+	return nil
+}
+
+func (m *Ecdh1PuKwParams) GetApv() []byte {
+	if m != nil {
+		return m.Apv
+	}
+	return nil
+}
+
 type Ecdh1PuAeadEncParams struct {
 	AeadEnc              *tink_go_proto.KeyTemplate `protobuf:"bytes,1,opt,name=aead_enc,json=aeadEnc,proto3" json:"aead_enc,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}                   `json:"-"`
@@ -365,42 +381,43 @@ func init() {
 func init() { proto.RegisterFile("proto/ecdh1pu_aead.proto", fileDescriptor_a77c865180c47e23) }
 
 var fileDescriptor_a77c865180c47e23 = []byte{
-	// 578 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x54, 0xdf, 0x6b, 0xd3, 0x50,
-	0x14, 0x26, 0xad, 0x76, 0xcb, 0xb1, 0x73, 0x33, 0x28, 0x84, 0x6d, 0x60, 0x8d, 0x0c, 0xfa, 0xb2,
-	0x14, 0x27, 0x28, 0x08, 0xa2, 0x6e, 0xad, 0xa3, 0x04, 0xa4, 0x84, 0xa9, 0xe0, 0x4b, 0xb8, 0xbd,
-	0x3d, 0x4b, 0x2f, 0xf9, 0x71, 0x2f, 0x37, 0xb7, 0xed, 0xf2, 0x27, 0x08, 0xbe, 0xfb, 0xee, 0x3f,
-	0xe0, 0xdf, 0xe5, 0x7f, 0x21, 0xb9, 0x49, 0x6b, 0xca, 0xea, 0x1c, 0xbe, 0x9d, 0xef, 0xe6, 0x3b,
-	0x5f, 0xce, 0xf7, 0x9d, 0xe4, 0x82, 0x2d, 0x24, 0x57, 0xbc, 0x87, 0x74, 0x32, 0x7d, 0x26, 0x66,
-	0x01, 0x41, 0x32, 0x71, 0xf5, 0x91, 0x65, 0x85, 0x9c, 0x87, 0x31, 0xba, 0x54, 0xe6, 0x42, 0x71,
-	0x57, 0xb1, 0x34, 0xda, 0xb7, 0x4a, 0x36, 0xe5, 0x49, 0xc2, 0xd3, 0x92, 0xb7, 0xbf, 0x57, 0x9e,
-	0x15, 0xcf, 0xab, 0x93, 0xc3, 0x3a, 0x2b, 0xa0, 0x3c, 0x11, 0x3c, 0x63, 0x0a, 0xcb, 0xa7, 0xce,
-	0xd7, 0x06, 0xec, 0x0e, 0xca, 0xd7, 0x79, 0x8b, 0x11, 0x91, 0x24, 0xc9, 0xac, 0x3e, 0x00, 0x9d,
-	0xc9, 0x39, 0x06, 0x2a, 0x17, 0x68, 0x1b, 0x1d, 0xa3, 0x7b, 0xff, 0xe4, 0xc8, 0xbd, 0x3e, 0x80,
-	0x3b, 0x88, 0x63, 0x26, 0x14, 0xa3, 0x67, 0x05, 0xfb, 0x22, 0x17, 0xe8, 0x9b, 0x74, 0x59, 0x5a,
-	0x2f, 0x60, 0x3b, 0xc2, 0xbc, 0xd4, 0x68, 0x68, 0x8d, 0x83, 0x4d, 0x1a, 0x1e, 0xe6, 0xba, 0x73,
-	0x2b, 0x2a, 0x0b, 0xeb, 0x0d, 0x80, 0x44, 0xca, 0x04, 0xc3, 0x54, 0x65, 0x76, 0xb3, 0xd3, 0xec,
-	0xde, 0x3b, 0x79, 0xbc, 0xf1, 0xed, 0x67, 0xa3, 0xd9, 0x38, 0x66, 0xd4, 0xc3, 0xdc, 0xaf, 0xb5,
-	0x58, 0x2f, 0xa1, 0x95, 0x61, 0x3a, 0x41, 0x69, 0xdf, 0xe9, 0x18, 0xb7, 0x69, 0xae, 0xe8, 0x8e,
-	0x0f, 0x0f, 0xab, 0x28, 0xde, 0x21, 0x99, 0x0c, 0x52, 0x5a, 0xe5, 0xf1, 0x0a, 0xb6, 0x8b, 0x4d,
-	0x04, 0x98, 0x52, 0x9d, 0xc6, 0x5f, 0x24, 0x0b, 0x27, 0x98, 0x88, 0x98, 0x28, 0xf4, 0xb7, 0x48,
-	0xa9, 0xe0, 0xfc, 0x32, 0xe0, 0x41, 0x4d, 0xb4, 0x52, 0x7c, 0x0b, 0x66, 0xb4, 0x08, 0x84, 0x06,
-	0x95, 0xe4, 0xd3, 0x8d, 0x53, 0xae, 0x6f, 0xc6, 0xdf, 0x8e, 0x96, 0x3b, 0x3a, 0x07, 0xc0, 0x94,
-	0x2e, 0x25, 0x1a, 0x5a, 0xa2, 0x7b, 0x83, 0xc4, 0x9a, 0x23, 0xdf, 0xc4, 0x95, 0xb9, 0x21, 0xec,
-	0x22, 0x0d, 0x04, 0x67, 0xa9, 0x0a, 0x2e, 0xb9, 0x4c, 0x88, 0xb2, 0x9b, 0x7a, 0x5b, 0x4f, 0x36,
-	0xab, 0x8d, 0x0a, 0xe6, 0x7b, 0x4d, 0xf4, 0x77, 0xb0, 0x0e, 0x9d, 0x9f, 0xc6, 0x5a, 0x80, 0xab,
-	0x80, 0x2d, 0x1b, 0xb6, 0xe6, 0x28, 0x33, 0xc6, 0x53, 0x6d, 0x76, 0xc7, 0x5f, 0x42, 0xeb, 0x35,
-	0xb4, 0xd6, 0x2c, 0x1c, 0xfd, 0xc3, 0x42, 0x35, 0x7f, 0xd5, 0x64, 0xed, 0x41, 0xd3, 0x1b, 0xf6,
-	0xf5, 0xc0, 0xa6, 0x5f, 0x94, 0x56, 0x1b, 0x8c, 0x2b, 0xbd, 0xf7, 0xb6, 0x6f, 0x5c, 0x15, 0x28,
-	0xb7, 0xef, 0x96, 0x28, 0xd7, 0xec, 0xcf, 0x7d, 0xbb, 0xa5, 0x71, 0x51, 0x3a, 0xdf, 0x0d, 0x78,
-	0x54, 0x57, 0x97, 0x6c, 0x4e, 0x14, 0xde, 0x3c, 0xf2, 0x39, 0x80, 0xd0, 0xce, 0x82, 0x08, 0xf3,
-	0x5b, 0x26, 0xff, 0xe7, 0x5b, 0x33, 0xc5, 0x2a, 0x95, 0x03, 0x30, 0x8b, 0x1f, 0x64, 0x4e, 0xe2,
-	0x19, 0x6a, 0x0b, 0x6d, 0xbf, 0xf8, 0x63, 0x3e, 0x15, 0xd8, 0xf9, 0xb8, 0x16, 0xa5, 0x87, 0x79,
-	0x99, 0x71, 0x2d, 0x30, 0xe3, 0x3f, 0x02, 0x3b, 0xfd, 0x66, 0xc0, 0x21, 0xe5, 0xc9, 0xa6, 0x26,
-	0x7d, 0x1f, 0x8c, 0x8c, 0x2f, 0x24, 0x64, 0x6a, 0x3a, 0x1b, 0xbb, 0x94, 0x27, 0xbd, 0x69, 0x2e,
-	0x50, 0xc6, 0x38, 0x09, 0x51, 0xf6, 0x88, 0x64, 0x98, 0x1d, 0x5f, 0x4a, 0x92, 0xe0, 0x82, 0xcb,
-	0xe8, 0x38, 0xe4, 0xbd, 0xb2, 0x5d, 0x5f, 0x36, 0x55, 0x29, 0x24, 0x4b, 0x98, 0x62, 0x73, 0xec,
-	0x5d, 0xbf, 0xc9, 0x82, 0x90, 0x07, 0xfa, 0xf4, 0x47, 0xa3, 0x75, 0x31, 0xfc, 0xe0, 0x8d, 0x4e,
-	0xc7, 0x2d, 0x8d, 0x9f, 0xff, 0x0e, 0x00, 0x00, 0xff, 0xff, 0x2a, 0xe7, 0x86, 0xd9, 0xf8, 0x04,
+	// 594 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x54, 0x5f, 0x6b, 0x13, 0x4f,
+	0x14, 0x65, 0x92, 0xdf, 0x2f, 0x6d, 0xae, 0xa9, 0xad, 0x8b, 0xc2, 0xd2, 0x16, 0x8c, 0x91, 0x42,
+	0x5e, 0x9a, 0x60, 0x05, 0x05, 0x41, 0xd4, 0x36, 0xb5, 0x94, 0x05, 0x09, 0x43, 0xb5, 0xe0, 0xcb,
+	0x32, 0x9d, 0xdc, 0xa6, 0xc3, 0xfe, 0x99, 0x61, 0x32, 0xd9, 0x76, 0xbf, 0x83, 0xef, 0xbe, 0xfb,
+	0x2e, 0x7e, 0x2e, 0xbf, 0x85, 0xcc, 0xec, 0x6e, 0xdd, 0xd0, 0x58, 0x8b, 0x6f, 0xf7, 0xde, 0xbd,
+	0xf7, 0xcc, 0x3d, 0xe7, 0xec, 0x0c, 0xf8, 0x4a, 0x4b, 0x23, 0x87, 0xc8, 0x27, 0x17, 0xcf, 0xd4,
+	0x3c, 0x64, 0xc8, 0x26, 0x03, 0x57, 0xf2, 0xbc, 0xa9, 0x94, 0xd3, 0x18, 0x07, 0x5c, 0xe7, 0xca,
+	0xc8, 0x81, 0x11, 0x69, 0xb4, 0xe9, 0x15, 0xdd, 0x5c, 0x26, 0x89, 0x4c, 0x8b, 0xbe, 0xcd, 0x8d,
+	0xa2, 0x66, 0xbf, 0x97, 0x95, 0xed, 0x7a, 0x57, 0xc8, 0x65, 0xa2, 0xe4, 0x4c, 0x18, 0x2c, 0xbe,
+	0xf6, 0xbe, 0x37, 0x60, 0xfd, 0xb0, 0x38, 0x2e, 0xb8, 0x1c, 0x33, 0xcd, 0x92, 0x99, 0x37, 0x02,
+	0xe0, 0x73, 0x9d, 0x61, 0x68, 0x72, 0x85, 0x3e, 0xe9, 0x92, 0xfe, 0xfd, 0xbd, 0x9d, 0xc1, 0xcd,
+	0x05, 0x06, 0x87, 0x71, 0x2c, 0x94, 0x11, 0xfc, 0xc0, 0x76, 0x9f, 0xe4, 0x0a, 0x69, 0x9b, 0x57,
+	0xa1, 0xf7, 0x02, 0x56, 0x23, 0xcc, 0x0b, 0x8c, 0x86, 0xc3, 0xd8, 0x5a, 0x86, 0x11, 0x60, 0xee,
+	0x26, 0x57, 0xa2, 0x22, 0xf0, 0xde, 0x00, 0x68, 0xe4, 0x42, 0x09, 0x4c, 0xcd, 0xcc, 0x6f, 0x76,
+	0x9b, 0xfd, 0x7b, 0x7b, 0x8f, 0x97, 0x9e, 0x7e, 0x30, 0x9e, 0x9f, 0xc5, 0x82, 0x07, 0x98, 0xd3,
+	0xda, 0x88, 0xf7, 0x12, 0x5a, 0x33, 0x4c, 0x27, 0xa8, 0xfd, 0xff, 0xba, 0xe4, 0x2e, 0xc3, 0x65,
+	0xbb, 0xb7, 0x01, 0x4d, 0xa6, 0xe6, 0xfe, 0xff, 0x5d, 0xd2, 0xef, 0x50, 0x1b, 0x16, 0x95, 0xcc,
+	0x6f, 0x55, 0x95, 0xac, 0x47, 0xe1, 0x61, 0x29, 0xd7, 0x3b, 0x64, 0x93, 0xc3, 0x94, 0x97, 0x9a,
+	0xbd, 0x82, 0x55, 0xeb, 0x56, 0x88, 0x29, 0xf7, 0xc9, 0x9f, 0x8f, 0xb5, 0x6c, 0x31, 0x51, 0x31,
+	0x33, 0x48, 0x57, 0x58, 0x81, 0xd0, 0xfb, 0x49, 0xe0, 0x41, 0x0d, 0xb4, 0x44, 0x7c, 0x0b, 0xed,
+	0xe8, 0x32, 0x54, 0x2e, 0x29, 0x21, 0x9f, 0x2e, 0x65, 0xb2, 0xe8, 0x1e, 0x5d, 0x8d, 0x2a, 0x1f,
+	0x8f, 0x00, 0x30, 0xe5, 0x15, 0x44, 0xc3, 0x41, 0xf4, 0x6f, 0x81, 0x58, 0x60, 0x44, 0xdb, 0x78,
+	0x4d, 0xee, 0x18, 0xd6, 0x91, 0x87, 0x4a, 0x8a, 0xd4, 0x84, 0xe7, 0x52, 0x27, 0xcc, 0xf8, 0x4d,
+	0xe7, 0xe8, 0x93, 0xe5, 0x68, 0x63, 0xdb, 0xf9, 0xde, 0x35, 0xd2, 0x35, 0xac, 0xa7, 0xbd, 0x1f,
+	0x64, 0x41, 0xc0, 0x6b, 0x13, 0x3c, 0x1f, 0x56, 0x32, 0xd4, 0x33, 0x21, 0x53, 0x47, 0x76, 0x8d,
+	0x56, 0xa9, 0xf7, 0x1a, 0x5a, 0x0b, 0x14, 0x76, 0xfe, 0x42, 0xa1, 0xdc, 0xbf, 0x1c, 0xb2, 0x1e,
+	0x06, 0xc7, 0x23, 0xb7, 0x70, 0x9b, 0xda, 0xd0, 0xeb, 0x00, 0xb9, 0x72, 0xff, 0x46, 0x87, 0x92,
+	0x2b, 0x9b, 0xe5, 0xa5, 0xe7, 0x24, 0x77, 0xdd, 0xa7, 0xa3, 0xca, 0xf1, 0xe0, 0x74, 0xd4, 0xfb,
+	0x4a, 0xe0, 0x51, 0x1d, 0x5d, 0x8b, 0x8c, 0x19, 0xbc, 0x7d, 0xe5, 0x23, 0x00, 0xe5, 0x98, 0x85,
+	0x11, 0xe6, 0x77, 0x54, 0xfe, 0xf7, 0xff, 0xd8, 0x56, 0xd7, 0xaa, 0x6c, 0x41, 0xdb, 0x5e, 0xa2,
+	0x8c, 0xc5, 0x73, 0x74, 0x14, 0x3a, 0xd4, 0xde, 0xaa, 0x4f, 0x36, 0xef, 0x7d, 0x5c, 0x90, 0x32,
+	0xc0, 0xbc, 0xd0, 0xb8, 0x26, 0x18, 0xf9, 0x07, 0xc1, 0xf6, 0xbf, 0x10, 0xd8, 0xe6, 0x32, 0x59,
+	0x36, 0xe4, 0xde, 0x8c, 0x31, 0xf9, 0xcc, 0xa6, 0xc2, 0x5c, 0xcc, 0xcf, 0x06, 0x5c, 0x26, 0xc3,
+	0x8b, 0x5c, 0xa1, 0x8e, 0x71, 0x32, 0x45, 0x3d, 0x64, 0x5a, 0xe0, 0x6c, 0xf7, 0x5c, 0xb3, 0x04,
+	0x2f, 0xa5, 0x8e, 0x76, 0xa7, 0x72, 0x58, 0x8c, 0xbb, 0x07, 0xa9, 0x0c, 0x95, 0x16, 0x89, 0x30,
+	0x22, 0xc3, 0xe1, 0xcd, 0xd7, 0x2e, 0x9c, 0xca, 0xd0, 0x55, 0xbf, 0x35, 0x5a, 0x27, 0xc7, 0x1f,
+	0x82, 0xf1, 0xfe, 0x59, 0xcb, 0xe5, 0xcf, 0x7f, 0x0d, 0x00, 0xa2, 0x19, 0x09, 0x40, 0x1c, 0x05,
 	0x00, 0x00,
 }
@@ -27,6 +27,10 @@ type EcdhesKwParams struct {
 	CurveType            common_go_proto.EllipticCurveType        `protobuf:"varint,1,opt,name=curve_type,json=curveType,proto3,enum=google.crypto.tink.EllipticCurveType" json:"curve_type,omitempty"`
 	KeyType              common_composite_go_proto.KeyType        `protobuf:"varint,2,opt,name=key_type,json=keyType,proto3,enum=google.crypto.tink.KeyType" json:"key_type,omitempty"`
 	Recipients           []*common_composite_go_proto.ECPublicKey `protobuf:"bytes,3,rep,name=recipients,proto3" json:"recipients,omitempty"`
+	KwKeySize            uint32                                   `protobuf:"varint,4,opt,name=kw_key_size,json=kwKeySize,proto3" json:"kw_key_size,omitempty"`
+	AlgId                string                                   `protobuf:"bytes,5,opt,name=alg_id,json=algId,proto3" json:"alg_id,omitempty"`
+	Apu                  []byte                                   `protobuf:"bytes,6,opt,name=apu,proto3" json:"apu,omitempty"`
+	Apv                  []byte                                   `protobuf:"bytes,7,opt,name=apv,proto3" json:"apv,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}                                 `json:"-"`
 	XXX_unrecognized     []byte                                   `json:"-"`
 	XXX_sizecache        int32                                    `json:"-"`
@@ -78,8 +82,37 @@ func (m *EcdhesKwParams) GetRecipients() []*common_composite_go_proto.ECPublicKe
 	return nil
 }
 
+func (m *EcdhesKwParams) GetKwKeySize() uint32 {
+	if m != nil {
+		return m.KwKeySize
+	}
+	return 0
+}
+
+func (m *EcdhesKwParams) GetAlgId() string {
+	if m != nil {
+		return m.AlgId
+	}
+	return ""
+}
+
+func (m *EcdhesKwParams) GetApu() []byte {
+	if m != nil {
+		return m.Apu
+	}
+	return nil
+}
+
+func (m *EcdhesKwParams) GetApv() []byte {
+	if m != nil {
+		return m.Apv
+	}
+	return nil
+}
+
 type EcdhesAeadEncParams struct {
 	AeadEnc              *tink_go_proto.KeyTemplate `protobuf:"bytes,1,opt,name=aead_enc,json=aeadEnc,proto3" json:"aead_enc,omitempty"`
+	AadPrefix            []byte                     `protobuf:"bytes,2,opt,name=aad_prefix,json=aadPrefix,proto3" json:"aad_prefix,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}                   `json:"-"`
 	XXX_unrecognized     []byte                     `json:"-"`
 	XXX_sizecache        int32                      `json:"-"`
@@ -117,6 +150,13 @@ func (m *EcdhesAeadEncParams) GetAeadEnc() *tink_go_proto.KeyTemplate {
 	return nil
 }
 
+func (m *EcdhesAeadEncParams) GetAadPrefix() []byte {
+	if m != nil {
+		return m.AadPrefix
+	}
+	return nil
+}
+
 type EcdhesAeadParams struct {
 	KwParams             *EcdhesKwParams               `protobuf:"bytes,1,opt,name=kw_params,json=kwParams,proto3" json:"kw_params,omitempty"`
 	EncParams            *EcdhesAeadEncParams          `protobuf:"bytes,2,opt,name=enc_params,json=encParams,proto3" json:"enc_params,omitempty"`
@@ -349,40 +389,45 @@ func init() {
 func init() { proto.RegisterFile("proto/ecdhes_aead.proto", fileDescriptor_59a984bc83da313d) }
 
 var fileDescriptor_59a984bc83da313d = []byte{
-	// 556 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x94, 0xcf, 0x6e, 0xd3, 0x40,
-	0x10, 0xc6, 0xb5, 0x0d, 0xb4, 0xf5, 0xd0, 0x96, 0xca, 0x20, 0x61, 0xb5, 0x95, 0x08, 0x16, 0x88,
-	0x5c, 0xea, 0x48, 0x45, 0xe2, 0x80, 0x90, 0x2a, 0xda, 0x26, 0x52, 0x64, 0x09, 0x05, 0x53, 0x71,
-	0xe0, 0x62, 0x36, 0x9b, 0xa9, 0xb3, 0xf2, 0x9f, 0x5d, 0xad, 0x37, 0x49, 0xfd, 0x0c, 0x9c, 0x39,
-	0x71, 0xe3, 0x85, 0x78, 0x0b, 0x9e, 0x03, 0x79, 0xed, 0xa4, 0x8e, 0x9a, 0x56, 0x70, 0x9b, 0x19,
-	0xcf, 0xfc, 0x76, 0xbe, 0x4f, 0xeb, 0x85, 0x67, 0x52, 0x09, 0x2d, 0xba, 0xc8, 0xc6, 0x13, 0xcc,
-	0x43, 0x8a, 0x74, 0xec, 0x99, 0x8a, 0x6d, 0x47, 0x42, 0x44, 0x09, 0x7a, 0x4c, 0x15, 0x52, 0x0b,
-	0x4f, 0xf3, 0x2c, 0x3e, 0xb0, 0xab, 0x66, 0x26, 0xd2, 0x54, 0x64, 0x55, 0xdf, 0xc1, 0x7e, 0x55,
-	0x2b, 0xbf, 0xd7, 0x95, 0xa3, 0x66, 0x57, 0xc8, 0x44, 0x2a, 0x45, 0xce, 0x35, 0x56, 0x5f, 0xdd,
-	0xdf, 0x04, 0xf6, 0x7a, 0xe6, 0x34, 0x7f, 0x3e, 0xa4, 0x8a, 0xa6, 0xb9, 0x7d, 0x01, 0xc0, 0xa6,
-	0x6a, 0x86, 0xa1, 0x2e, 0x24, 0x3a, 0xa4, 0x4d, 0x3a, 0x7b, 0x27, 0xaf, 0xbc, 0xdb, 0xe7, 0x7b,
-	0xbd, 0x24, 0xe1, 0x52, 0x73, 0x76, 0x5e, 0x76, 0x5f, 0x16, 0x12, 0x03, 0x8b, 0x2d, 0x42, 0xfb,
-	0x2d, 0x6c, 0xc7, 0x58, 0x54, 0x8c, 0x0d, 0xc3, 0x38, 0x5c, 0xc7, 0xf0, 0xb1, 0x30, 0x93, 0x5b,
-	0x71, 0x15, 0xd8, 0xa7, 0x00, 0x0a, 0x19, 0x97, 0x1c, 0x33, 0x9d, 0x3b, 0xad, 0x76, 0xab, 0xf3,
-	0xe8, 0xe4, 0xf9, 0xda, 0xd3, 0xcf, 0x87, 0xd3, 0x51, 0xc2, 0x99, 0x8f, 0x45, 0xd0, 0x18, 0x71,
-	0x3f, 0xc1, 0x93, 0x4a, 0xd0, 0x07, 0xa4, 0xe3, 0x5e, 0xc6, 0x6a, 0x55, 0xef, 0x60, 0xbb, 0xb4,
-	0x33, 0xc4, 0x8c, 0x19, 0x4d, 0x77, 0x50, 0xcb, 0x7d, 0x30, 0x95, 0x09, 0xd5, 0x18, 0x6c, 0xd1,
-	0x8a, 0xe0, 0xfe, 0x21, 0xb0, 0x7f, 0xc3, 0xac, 0x81, 0xa7, 0x60, 0xc5, 0xf3, 0x50, 0x9a, 0xa4,
-	0x26, 0xba, 0x6b, 0xf7, 0x5c, 0x71, 0x37, 0xd8, 0x8e, 0x17, 0x3e, 0xf7, 0x01, 0x30, 0x63, 0x0b,
-	0xc2, 0x86, 0x21, 0xbc, 0xbe, 0x9b, 0xb0, 0x22, 0x27, 0xb0, 0x70, 0xa9, 0x6c, 0x00, 0x8f, 0x91,
-	0x85, 0x52, 0xf0, 0x4c, 0x87, 0x57, 0x42, 0xa5, 0x54, 0x3b, 0x2d, 0x63, 0xf8, 0x8b, 0xf5, 0xb0,
-	0x61, 0xd9, 0xd9, 0x37, 0x8d, 0xc1, 0x2e, 0x36, 0x53, 0xf7, 0x27, 0x69, 0x9a, 0xb7, 0xf4, 0xd7,
-	0x76, 0x60, 0x6b, 0x86, 0x2a, 0xe7, 0x22, 0x33, 0x4a, 0x77, 0x83, 0x45, 0x6a, 0xbf, 0x87, 0xcd,
-	0x15, 0x01, 0x2f, 0xef, 0x17, 0x50, 0x6f, 0x5f, 0xcf, 0xd8, 0xfb, 0xd0, 0xf2, 0x07, 0x17, 0x66,
-	0x5d, 0x2b, 0x28, 0x43, 0x7b, 0x07, 0xc8, 0xb5, 0xf3, 0xa0, 0x4d, 0x3a, 0x3b, 0x01, 0xb9, 0x2e,
-	0xb3, 0xc2, 0x79, 0x58, 0x65, 0x85, 0xfb, 0x83, 0xc0, 0xd3, 0x06, 0x4a, 0xf1, 0x19, 0xd5, 0x78,
-	0xff, 0x7a, 0x7d, 0x00, 0x69, 0x54, 0x84, 0x31, 0x16, 0xff, 0xe6, 0xf1, 0xcd, 0xad, 0xb2, 0xe4,
-	0xd2, 0x80, 0x43, 0xb0, 0xca, 0xdb, 0x3c, 0xa3, 0xc9, 0x14, 0xcd, 0xba, 0x3b, 0x41, 0x79, 0xbd,
-	0xbf, 0x94, 0xb9, 0xfb, 0xb9, 0x69, 0x9a, 0x8f, 0x45, 0x65, 0x66, 0xc3, 0x1a, 0xf2, 0xff, 0xd6,
-	0x9c, 0x7d, 0x27, 0x70, 0xc4, 0x44, 0xba, 0x6e, 0xc6, 0xfc, 0xb9, 0x43, 0xf2, 0xf5, 0x5b, 0xc4,
-	0xf5, 0x64, 0x3a, 0xf2, 0x98, 0x48, 0xbb, 0x93, 0x42, 0xa2, 0x4a, 0x70, 0x1c, 0xa1, 0xea, 0x52,
-	0xc5, 0x31, 0x3f, 0xbe, 0x52, 0x34, 0xc5, 0xb9, 0x50, 0xf1, 0x71, 0x24, 0xba, 0xd5, 0xb8, 0x79,
-	0x16, 0xea, 0x50, 0x2a, 0x9e, 0x72, 0xcd, 0x67, 0xd8, 0xbd, 0xf5, 0xe4, 0x84, 0x91, 0x08, 0x4d,
-	0xf1, 0xd7, 0xc6, 0xe6, 0xe5, 0xe0, 0xa3, 0x3f, 0x3c, 0x1b, 0x6d, 0x9a, 0xfc, 0xcd, 0xdf, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0xae, 0x96, 0xb6, 0xb7, 0xa0, 0x04, 0x00, 0x00,
+	// 633 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0x4d, 0x6f, 0xd3, 0x4c,
+	0x10, 0xd6, 0x26, 0x6f, 0x93, 0x7a, 0x9b, 0xf6, 0xad, 0x0c, 0x08, 0xab, 0x2d, 0x10, 0x2c, 0x10,
+	0xb9, 0x34, 0x91, 0x8a, 0xc4, 0x01, 0x21, 0x55, 0xf4, 0x4b, 0x8a, 0x2c, 0xa1, 0x68, 0x5b, 0x71,
+	0xe0, 0x62, 0xb6, 0xeb, 0xa9, 0xbb, 0xf2, 0xc7, 0xae, 0xd6, 0x8e, 0x53, 0xf7, 0x2f, 0x70, 0xe6,
+	0xc4, 0x8d, 0x23, 0x3f, 0x8c, 0xdf, 0x81, 0x76, 0xed, 0xb4, 0x8e, 0x9a, 0x56, 0x70, 0x9b, 0x19,
+	0xcf, 0x3c, 0x33, 0xcf, 0x33, 0xe3, 0xc5, 0x4f, 0xa5, 0x12, 0xb9, 0x18, 0x01, 0x0b, 0x2e, 0x21,
+	0xf3, 0x29, 0xd0, 0x60, 0x68, 0x22, 0xb6, 0x1d, 0x0a, 0x11, 0xc6, 0x30, 0x64, 0xaa, 0x94, 0xb9,
+	0x18, 0xe6, 0x3c, 0x8d, 0xb6, 0xec, 0x2a, 0x99, 0x89, 0x24, 0x11, 0x69, 0x95, 0xb7, 0xb5, 0x59,
+	0xc5, 0xf4, 0xf7, 0x3a, 0xb2, 0xd3, 0xcc, 0xf2, 0x99, 0x48, 0xa4, 0xc8, 0x78, 0x0e, 0xd5, 0x57,
+	0xf7, 0x57, 0x0b, 0x6f, 0x1c, 0x9b, 0x6e, 0xde, 0x6c, 0x42, 0x15, 0x4d, 0x32, 0xfb, 0x08, 0x63,
+	0x36, 0x55, 0x05, 0xf8, 0x79, 0x29, 0xc1, 0x41, 0x7d, 0x34, 0xd8, 0xd8, 0x7b, 0x3d, 0xbc, 0xdb,
+	0x7f, 0x78, 0x1c, 0xc7, 0x5c, 0xe6, 0x9c, 0x1d, 0xea, 0xec, 0xb3, 0x52, 0x02, 0xb1, 0xd8, 0xdc,
+	0xb4, 0xdf, 0xe1, 0xd5, 0x08, 0xca, 0x0a, 0xa3, 0x65, 0x30, 0xb6, 0x97, 0x61, 0x78, 0x50, 0x9a,
+	0xca, 0x6e, 0x54, 0x19, 0xf6, 0x3e, 0xc6, 0x0a, 0x18, 0x97, 0x1c, 0xd2, 0x3c, 0x73, 0xda, 0xfd,
+	0xf6, 0x60, 0x6d, 0xef, 0xc5, 0xd2, 0xee, 0x87, 0x93, 0xe9, 0x79, 0xcc, 0x99, 0x07, 0x25, 0x69,
+	0x94, 0xd8, 0xcf, 0xf1, 0x5a, 0x34, 0xf3, 0x75, 0xef, 0x8c, 0x5f, 0x83, 0xf3, 0x5f, 0x1f, 0x0d,
+	0xd6, 0x89, 0x15, 0xcd, 0x3c, 0x28, 0x4f, 0xf9, 0x35, 0xd8, 0x4f, 0x70, 0x87, 0xc6, 0xa1, 0xcf,
+	0x03, 0x67, 0xa5, 0x8f, 0x06, 0x16, 0x59, 0xa1, 0x71, 0x38, 0x0e, 0xec, 0x4d, 0xdc, 0xa6, 0x72,
+	0xea, 0x74, 0xfa, 0x68, 0xd0, 0x23, 0xda, 0xac, 0x22, 0x85, 0xd3, 0x9d, 0x47, 0x0a, 0x57, 0xe2,
+	0x47, 0x95, 0x56, 0x1f, 0x81, 0x06, 0xc7, 0x29, 0xab, 0x05, 0x7b, 0x8f, 0x57, 0xf5, 0xa6, 0x7c,
+	0x48, 0x99, 0x91, 0xeb, 0x9e, 0x81, 0x35, 0x55, 0x48, 0x64, 0x4c, 0x73, 0x20, 0x5d, 0x5a, 0x21,
+	0xd8, 0xcf, 0x30, 0xa6, 0x34, 0xf0, 0xa5, 0x82, 0x0b, 0x7e, 0x65, 0x84, 0xea, 0x11, 0x8b, 0xd2,
+	0x60, 0x62, 0x02, 0xee, 0x6f, 0x84, 0x37, 0x6f, 0x5b, 0xd6, 0xfd, 0xf6, 0xb1, 0x15, 0xcd, 0x7c,
+	0x69, 0x9c, 0xba, 0xa1, 0xbb, 0x54, 0xa1, 0x85, 0xbd, 0x92, 0xd5, 0x68, 0xbe, 0xe1, 0x13, 0x8c,
+	0x21, 0x65, 0x73, 0x84, 0x96, 0x41, 0x78, 0x73, 0x3f, 0xc2, 0x02, 0x5b, 0x62, 0xc1, 0x0d, 0xf1,
+	0x31, 0xfe, 0x1f, 0x98, 0x2f, 0x05, 0x4f, 0x73, 0xff, 0x42, 0xa8, 0x84, 0xe6, 0x4e, 0xdb, 0xac,
+	0xfa, 0xe5, 0x72, 0xb0, 0x89, 0xce, 0x3c, 0x31, 0x89, 0x64, 0x1d, 0x9a, 0xae, 0xfb, 0x03, 0x35,
+	0xb5, 0xbd, 0xd9, 0xac, 0xed, 0xe0, 0x6e, 0x01, 0x2a, 0xe3, 0x22, 0x35, 0x4c, 0xd7, 0xc9, 0xdc,
+	0xb5, 0x3f, 0xe0, 0xce, 0x02, 0x81, 0x57, 0x0f, 0x13, 0xa8, 0xa7, 0xaf, 0x6b, 0xf4, 0x72, 0xbd,
+	0xf1, 0x91, 0x19, 0xd7, 0x22, 0xda, 0xb4, 0x7b, 0x18, 0x5d, 0x99, 0x6b, 0xe9, 0x11, 0x74, 0xa5,
+	0xbd, 0xd2, 0x1c, 0x48, 0x8f, 0xa0, 0xd2, 0xfd, 0x8e, 0xf0, 0xe3, 0x06, 0x94, 0xe2, 0x05, 0xcd,
+	0xe1, 0xe1, 0xf1, 0x4e, 0x30, 0x96, 0x86, 0x85, 0x3e, 0xc5, 0xbf, 0xd3, 0xf8, 0xf6, 0x9e, 0x2d,
+	0x79, 0x23, 0xc0, 0x36, 0xb6, 0xf4, 0x2d, 0x17, 0x34, 0x9e, 0x82, 0x19, 0xb7, 0x47, 0xf4, 0x8f,
+	0xf5, 0x59, 0xfb, 0xee, 0x69, 0x53, 0x34, 0x0f, 0xca, 0x4a, 0xcc, 0x86, 0x34, 0xe8, 0xdf, 0xa5,
+	0x39, 0xf8, 0x86, 0xf0, 0x0e, 0x13, 0xc9, 0xb2, 0x1a, 0xf3, 0x66, 0x4c, 0xd0, 0x97, 0xaf, 0x21,
+	0xcf, 0x2f, 0xa7, 0xe7, 0x43, 0x26, 0x92, 0xd1, 0x65, 0x29, 0x41, 0xc5, 0x10, 0x84, 0xa0, 0x46,
+	0x54, 0x71, 0xc8, 0x76, 0x2f, 0x14, 0x4d, 0x60, 0x26, 0x54, 0xb4, 0x1b, 0x8a, 0x51, 0x55, 0x6e,
+	0x1e, 0xa4, 0xda, 0x94, 0x8a, 0x27, 0x3c, 0xe7, 0x05, 0x8c, 0xee, 0x3c, 0x76, 0x7e, 0x28, 0x7c,
+	0x13, 0xfc, 0xd9, 0xea, 0x9c, 0x8d, 0x3f, 0x79, 0x93, 0x83, 0xf3, 0x8e, 0xf1, 0xdf, 0xfe, 0x19,
+	0x00, 0x4c, 0x74, 0x01, 0x8d, 0x1a, 0x05, 0x00, 0x00,
 }
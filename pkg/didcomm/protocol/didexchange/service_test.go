@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package didexchange
 
 import (
+	"bytes"
 	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/json"
@@ -682,18 +683,79 @@ func (m *mockStore) Put(k string, v []byte) error {
 	return m.put(k, v)
 }
 
+// PutBatch stores the key/value pairs
+func (m *mockStore) PutBatch(kvs []storage.KeyValue) error {
+	for _, kv := range kvs {
+		if err := m.put(kv.Key, kv.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PutIfMatch stores the key and the record if the current record matches
+func (m *mockStore) PutIfMatch(k string, expected, newValue []byte) (bool, error) {
+	current, err := m.get(k)
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return false, err
+	}
+
+	if !bytes.Equal(current, expected) {
+		return false, nil
+	}
+
+	return true, m.put(k, newValue)
+}
+
 // Get fetches the record based on key
 func (m *mockStore) Get(k string) ([]byte, error) {
 	return m.get(k)
 }
 
+// GetBulk fetches the records based on keys
+func (m *mockStore) GetBulk(keys ...string) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+
+	for i, k := range keys {
+		v, err := m.get(k)
+		if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+			return nil, err
+		}
+
+		values[i] = v
+	}
+
+	return values, nil
+}
+
+// Has checks whether a record exists for the key
+func (m *mockStore) Has(k string) (bool, error) {
+	_, err := m.get(k)
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
 // Delete the record based on key
 func (m *mockStore) Delete(k string) error {
 	return m.delete(k)
 }
 
 // Search returns storage iterator
-func (m *mockStore) Iterator(start, limit string) storage.StoreIterator {
+func (m *mockStore) Iterator(start, limit string, opts ...storage.IteratorOption) storage.StoreIterator {
+	return nil
+}
+
+// Count returns the number of records in the range
+func (m *mockStore) Count(start, limit string) (int, error) {
+	return 0, nil
+}
+
+// ForEach calls fn with the records in the range
+func (m *mockStore) ForEach(start, limit string, fn func(key, value []byte) (bool, error)) error {
 	return nil
 }
 
@@ -1265,11 +1265,35 @@ func (s *stubStore) Put(k string, v []byte) error {
 	return nil
 }
 
+func (s *stubStore) PutBatch(kvs []storage.KeyValue) error {
+	panic("implement me")
+}
+
 func (s *stubStore) Get(k string) ([]byte, error) {
 	panic("implement me")
 }
 
-func (s *stubStore) Iterator(start, limit string) storage.StoreIterator {
+func (s *stubStore) GetBulk(keys ...string) ([][]byte, error) {
+	panic("implement me")
+}
+
+func (s *stubStore) PutIfMatch(k string, expected, newValue []byte) (bool, error) {
+	panic("implement me")
+}
+
+func (s *stubStore) Has(k string) (bool, error) {
+	panic("implement me")
+}
+
+func (s *stubStore) Iterator(start, limit string, opts ...storage.IteratorOption) storage.StoreIterator {
+	panic("implement me")
+}
+
+func (s *stubStore) Count(start, limit string) (int, error) {
+	panic("implement me")
+}
+
+func (s *stubStore) ForEach(start, limit string, fn func(key, value []byte) (bool, error)) error {
 	panic("implement me")
 }
 
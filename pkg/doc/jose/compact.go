@@ -0,0 +1,84 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jose
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+)
+
+var errSingleRecipientAADMissing = errors.New(errCompactSerializationCommonText +
+	"encrypted data has no single recipient AAD")
+
+// CompactSerializeEncryptedData serializes the result serializedEncData of a composite encryption primitive into a
+// compact JWE as defined in https://tools.ietf.org/html/rfc7516#section-7.1. The message must be encrypted for exactly
+// one recipient with the base64url encoded protected header as AAD, ie the output of ProtectedHeaderAAD: the protected
+// header of the JWE is then the SingleRecipientAAD of the encrypted data, which merges the recipient headers (alg,
+// kid and epk) in the encrypted header. The SKID of ECDH-1PU messages is not serialized since a compact JWE has no
+// unprotected header.
+func CompactSerializeEncryptedData(serializedEncData []byte) (string, error) {
+	encData := new(composite.EncryptedData)
+
+	err := json.Unmarshal(serializedEncData, encData)
+	if err != nil {
+		return "", fmt.Errorf("compact serialize encrypted data: %w", err)
+	}
+
+	if len(encData.Recipients) != 1 {
+		return "", errNotOnlyOneRecipient
+	}
+
+	if len(encData.SingleRecipientAAD) == 0 {
+		return "", errSingleRecipientAADMissing
+	}
+
+	b64EncryptedKey := base64.RawURLEncoding.EncodeToString(encData.Recipients[0].EncryptedCEK)
+
+	b64IV := base64.RawURLEncoding.EncodeToString(encData.IV)
+
+	b64Ciphertext := base64.RawURLEncoding.EncodeToString(encData.Ciphertext)
+
+	b64Tag := base64.RawURLEncoding.EncodeToString(encData.Tag)
+
+	return fmt.Sprintf("%s.%s.%s.%s.%s", encData.SingleRecipientAAD, b64EncryptedKey, b64IV, b64Ciphertext, b64Tag),
+		nil
+}
+
+// DeserializeCompactEncryptedData parses the compact JWE serializedJWE back into the serialized encrypted data and
+// the AAD (the protected header as received) to decrypt with a composite decryption primitive.
+func DeserializeCompactEncryptedData(serializedJWE string) ([]byte, []byte, error) {
+	jwe, err := deserializeCompact(serializedJWE)
+	if err != nil {
+		return nil, nil, fmt.Errorf("deserialize compact encrypted data: %w", err)
+	}
+
+	encAlg, ok := jwe.ProtectedHeaders.Encryption()
+	if !ok {
+		return nil, nil, fmt.Errorf("deserialize compact encrypted data: missing encryption algorithm 'enc' header")
+	}
+
+	encData, err := buildEncryptedData(encAlg, jwe)
+	if err != nil {
+		return nil, nil, fmt.Errorf("deserialize compact encrypted data: %w", err)
+	}
+
+	return encData, []byte(jwe.OrigProtectedHders), nil
+}
+
+// ProtectedHeaderAAD returns the base64url encoded JSON of protectedHeaders, the AAD a single recipient message must
+// be encrypted with by a composite encryption primitive to be serialized by CompactSerializeEncryptedData.
+func ProtectedHeaderAAD(protectedHeaders Headers) ([]byte, error) {
+	if protectedHeaders == nil {
+		return nil, errProtectedHeaderMissing
+	}
+
+	return computeAuthData(protectedHeaders, nil)
+}
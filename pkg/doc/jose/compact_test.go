@@ -0,0 +1,137 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package jose
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/tink/go/keyset"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/ecdhes"
+)
+
+func TestCompactEncryptedDataRoundTrip(t *testing.T) {
+	pt := []byte("some msg")
+
+	aad, err := ProtectedHeaderAAD(Headers{HeaderEncryption: A256GCM})
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		recECKeys, recKHs := createRecipients(t, 1)
+		recECKeys[0].KID = "kid-1"
+
+		serializedJWE, err := CompactSerializeEncryptedData(compositeEncrypt(t, recECKeys, pt, aad))
+		require.NoError(t, err)
+		require.Len(t, strings.Split(serializedJWE, "."), compactJWERequiredNumOfParts)
+
+		// the compact JWE is parsed and decrypted by the JWE decrypter too
+		jwe, err := Deserialize(serializedJWE)
+		require.NoError(t, err)
+		require.Equal(t, "kid-1", jwe.ProtectedHeaders["kid"])
+
+		msg, err := NewJWEDecrypt(recKHs[0]).Decrypt(jwe)
+		require.NoError(t, err)
+		require.EqualValues(t, pt, msg)
+
+		encData, authData, err := DeserializeCompactEncryptedData(serializedJWE)
+		require.NoError(t, err)
+		require.Equal(t, strings.Split(serializedJWE, ".")[0], string(authData))
+
+		decrypter, err := ecdhes.NewECDHESDecrypt(recKHs[0])
+		require.NoError(t, err)
+
+		msg, err = decrypter.Decrypt(encData, authData)
+		require.NoError(t, err)
+		require.EqualValues(t, pt, msg)
+
+		// the protected header is authenticated
+		_, err = decrypter.Decrypt(encData, aad)
+		require.EqualError(t, err, "ecdhes_factory: decryption failed")
+	})
+
+	t.Run("more than one recipient", func(t *testing.T) {
+		recECKeys, _ := createRecipients(t, 2)
+
+		serializedJWE, err := CompactSerializeEncryptedData(compositeEncrypt(t, recECKeys, pt, aad))
+		require.EqualError(t, err, errNotOnlyOneRecipient.Error())
+		require.Empty(t, serializedJWE)
+	})
+
+	t.Run("missing single recipient AAD", func(t *testing.T) {
+		encData, err := json.Marshal(&composite.EncryptedData{
+			Recipients: []*composite.RecipientWrappedKey{{EncryptedCEK: []byte("cek")}},
+		})
+		require.NoError(t, err)
+
+		serializedJWE, err := CompactSerializeEncryptedData(encData)
+		require.EqualError(t, err, errSingleRecipientAADMissing.Error())
+		require.Empty(t, serializedJWE)
+	})
+
+	t.Run("invalid encrypted data", func(t *testing.T) {
+		serializedJWE, err := CompactSerializeEncryptedData([]byte("{"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "compact serialize encrypted data")
+		require.Empty(t, serializedJWE)
+	})
+
+	t.Run("missing protected header", func(t *testing.T) {
+		headerAAD, err := ProtectedHeaderAAD(nil)
+		require.EqualError(t, err, errProtectedHeaderMissing.Error())
+		require.Empty(t, headerAAD)
+	})
+}
+
+func TestDeserializeCompactEncryptedDataFailures(t *testing.T) {
+	t.Run("wrong number of parts", func(t *testing.T) {
+		_, _, err := DeserializeCompactEncryptedData("a.b.c")
+		require.EqualError(t, err, "deserialize compact encrypted data: "+errWrongNumberOfCompactJWEParts.Error())
+	})
+
+	t.Run("missing enc header", func(t *testing.T) {
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"ECDH-ES+A256KW"}`))
+
+		_, _, err := DeserializeCompactEncryptedData(header + ".YQ.YQ.YQ.YQ")
+		require.EqualError(t, err,
+			"deserialize compact encrypted data: missing encryption algorithm 'enc' header")
+	})
+
+	t.Run("missing epk header", func(t *testing.T) {
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"enc":"A256GCM"}`))
+
+		_, _, err := DeserializeCompactEncryptedData(header + ".YQ.YQ.YQ.YQ")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "deserialize compact encrypted data: JSON value is not a map")
+	})
+}
+
+// compositeEncrypt encrypts pt and aad for recipients with the ECDH-ES composite primitive.
+func compositeEncrypt(t *testing.T, recipients []*composite.PublicKey, pt, aad []byte) []byte {
+	t.Helper()
+
+	kt, err := ecdhes.ECDHES256KWAES256GCMKeyTemplateWithRecipients(recipients)
+	require.NoError(t, err)
+
+	kh, err := keyset.NewHandle(kt)
+	require.NoError(t, err)
+
+	pubKH, err := kh.Public()
+	require.NoError(t, err)
+
+	encrypter, err := ecdhes.NewECDHESEncrypt(pubKH)
+	require.NoError(t, err)
+
+	encData, err := encrypter.Encrypt(pt, aad)
+	require.NoError(t, err)
+
+	return encData
+}
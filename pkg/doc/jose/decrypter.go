@@ -7,7 +7,6 @@ SPDX-License-Identifier: Apache-2.0
 package jose
 
 import (
-	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
 
@@ -74,15 +73,11 @@ func (jd *JWEDecrypt) Decrypt(jwe *JSONWebEncryption) ([]byte, error) {
 		return nil, fmt.Errorf("jwedecrypt: failed to build encryptedData for Decrypt(): %w", err)
 	}
 
-	authData, err := computeAuthData(protectedHeaders, []byte(jwe.AAD))
+	authData, err := jwe.AuthData()
 	if err != nil {
 		return nil, err
 	}
 
-	if len(jwe.Recipients) == 1 {
-		authData = []byte(jwe.OrigProtectedHders)
-	}
-
 	return decPrimitive.Decrypt(encryptedData, authData)
 }
 
@@ -95,7 +90,7 @@ func buildEncryptedData(encAlg string, jwe *JSONWebEncryption) ([]byte, error) {
 			return nil, err
 		}
 
-		rec, err := convertMarshalledJWKToRecKey(rHeaders.EPK)
+		rec, err := composite.EPKFromJWK(rHeaders.EPK)
 		if err != nil {
 			return nil, err
 		}
@@ -109,7 +104,7 @@ func buildEncryptedData(encAlg string, jwe *JSONWebEncryption) ([]byte, error) {
 		}
 	} else { // full serialization
 		for _, recJWE := range jwe.Recipients {
-			rec, err := convertMarshalledJWKToRecKey(recJWE.Header.EPK)
+			rec, err := composite.EPKFromJWK(recJWE.Header.EPK)
 			if err != nil {
 				return nil, err
 			}
@@ -177,30 +172,3 @@ func extractRecipientHeaders(headers map[string]interface{}) (*RecipientHeaders,
 
 	return recHeaders, nil
 }
-
-func convertMarshalledJWKToRecKey(marshalledJWK []byte) (*composite.RecipientWrappedKey, error) {
-	jwk := &JWK{}
-
-	err := jwk.UnmarshalJSON(marshalledJWK)
-	if err != nil {
-		return nil, err
-	}
-
-	epk := composite.PublicKey{
-		Curve: jwk.Crv,
-		Type:  jwk.Kty,
-	}
-
-	switch key := jwk.Key.(type) {
-	case *ecdsa.PublicKey:
-		epk.X = key.X.Bytes()
-		epk.Y = key.Y.Bytes()
-	default:
-		return nil, fmt.Errorf("unsupported recipient key type")
-	}
-
-	return &composite.RecipientWrappedKey{
-		KID: jwk.KeyID,
-		EPK: epk,
-	}, nil
-}
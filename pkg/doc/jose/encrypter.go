@@ -7,17 +7,12 @@ SPDX-License-Identifier: Apache-2.0
 package jose
 
 import (
-	"crypto/ecdsa"
-	"crypto/elliptic"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"math/big"
 
-	hybrid "github.com/google/tink/go/hybrid/subtle"
 	"github.com/google/tink/go/keyset"
 	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
-	"github.com/square/go-jose/v3"
 
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite/api"
@@ -193,7 +188,7 @@ func (je *JWEEncrypt) buildRecipients(encData *composite.EncryptedData) ([]*Reci
 }
 
 func buildRecipientHeaders(rec *composite.RecipientWrappedKey) (*RecipientHeaders, error) {
-	mRecJWK, err := convertRecKeyToMarshalledJWK(rec)
+	mRecJWK, err := composite.EPKToJWK(rec)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert recipient key to marshalled JWK: %w", err)
 	}
@@ -205,31 +200,6 @@ func buildRecipientHeaders(rec *composite.RecipientWrappedKey) (*RecipientHeader
 	}, nil
 }
 
-func convertRecKeyToMarshalledJWK(rec *composite.RecipientWrappedKey) ([]byte, error) {
-	var c elliptic.Curve
-
-	c, err := hybrid.GetCurve(rec.EPK.Curve)
-	if err != nil {
-		return nil, err
-	}
-
-	recJWK := JWK{
-		JSONWebKey: jose.JSONWebKey{
-			KeyID: rec.KID,
-			Use:   HeaderEncryption,
-			Key: &ecdsa.PublicKey{
-				Curve: c,
-				X:     new(big.Int).SetBytes(rec.EPK.X),
-				Y:     new(big.Int).SetBytes(rec.EPK.Y),
-			},
-		},
-		Kty: "EC", // TODO add support for X25519 content encryption, issue #1684
-		Crv: rec.EPK.Curve,
-	}
-
-	return recJWK.MarshalJSON()
-}
-
 // Get the additional authenticated data from a JWE object.
 func computeAuthData(protectedHeaders map[string]interface{}, aad []byte) ([]byte, error) {
 	var protected string
@@ -11,13 +11,16 @@ import (
 	"crypto/ecdsa"
 	"crypto/rand"
 	"crypto/rsa"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/google/tink/go/aead"
 	"github.com/google/tink/go/keyset"
+	tinkpb "github.com/google/tink/go/proto/tink_go_proto"
 	"github.com/google/tink/go/subtle"
 	"github.com/square/go-jose/v3"
 	"github.com/stretchr/testify/require"
@@ -108,8 +111,8 @@ func TestJWEEncryptRoundTrip(t *testing.T) {
 		}
 
 		_, err = jweDecrypter.Decrypt(badJWE)
-		require.EqualError(t, err, "jwedecrypt: failed to build encryptedData for Decrypt(): unable to read "+
-			"JWK: invalid character 's' looking for beginning of value")
+		require.EqualError(t, err, "jwedecrypt: failed to build encryptedData for Decrypt(): "+
+			"epkFromJWK: invalid character 's' looking for beginning of value")
 
 		// decrypt JWE with unsupported recipient key
 		var privKey *rsa.PrivateKey
@@ -143,8 +146,8 @@ func TestJWEEncryptRoundTrip(t *testing.T) {
 		}
 
 		_, err = jweDecrypter.Decrypt(badJWE)
-		require.EqualError(t, err, "jwedecrypt: failed to build encryptedData for Decrypt(): unsupported "+
-			"recipient key type")
+		require.EqualError(t, err, "jwedecrypt: failed to build encryptedData for Decrypt(): "+
+			"publicKeyFromJWK: key type RSA not supported")
 
 		badJWE.Recipients = recipients
 		// finally create Decrypt with bad keyset.Handle and try to Decrypt with invalid Handle
@@ -203,6 +206,176 @@ func TestJWEEncryptRoundTripWithSingleRecipient(t *testing.T) {
 	require.EqualValues(t, pt, msg)
 }
 
+func TestJWEDecryptWithTamperedProtectedHeader(t *testing.T) {
+	pt := []byte("some msg")
+
+	t.Run("full serialization", func(t *testing.T) {
+		recECKeys, recKHs := createRecipients(t, 2)
+
+		jweEncrypter, err := NewJWEEncrypt(A256GCM, recECKeys)
+		require.NoError(t, err)
+
+		jwe, err := jweEncrypter.EncryptWithAuthData(pt, []byte("aad value"))
+		require.NoError(t, err)
+
+		serializedJWE, err := jwe.FullSerialize(json.Marshal)
+		require.NoError(t, err)
+
+		localJWE, err := Deserialize(serializedJWE)
+		require.NoError(t, err)
+
+		authData, err := localJWE.AuthData()
+		require.NoError(t, err)
+		require.Equal(t, localJWE.OrigProtectedHders+"."+base64.RawURLEncoding.EncodeToString([]byte("aad value")),
+			string(authData))
+
+		msg, err := NewJWEDecrypt(recKHs[0]).Decrypt(localJWE)
+		require.NoError(t, err)
+		require.EqualValues(t, pt, msg)
+
+		// the tampered header holds the same headers, it must not be authenticated once parsed again
+		tamperedJWE := tamperProtectedHeader(t, serializedJWE, localJWE.OrigProtectedHders, func(header []byte) []byte {
+			return append([]byte(" "), header...)
+		})
+
+		_, err = NewJWEDecrypt(recKHs[0]).Decrypt(tamperedJWE)
+		require.EqualError(t, err, "ecdhes_factory: decryption failed")
+	})
+
+	t.Run("compact serialization", func(t *testing.T) {
+		recECKeys, recKHs := createRecipients(t, 1)
+		recECKeys[0].KID = "kid-1"
+
+		jweEncrypter, err := NewJWEEncrypt(A256GCM, recECKeys)
+		require.NoError(t, err)
+
+		jwe, err := jweEncrypter.Encrypt(pt)
+		require.NoError(t, err)
+
+		serializedJWE, err := jwe.CompactSerialize(json.Marshal)
+		require.NoError(t, err)
+
+		localJWE, err := Deserialize(serializedJWE)
+		require.NoError(t, err)
+
+		msg, err := NewJWEDecrypt(recKHs[0]).Decrypt(localJWE)
+		require.NoError(t, err)
+		require.EqualValues(t, pt, msg)
+
+		// flipping a byte of the kid header only changes the order in which the recipient keys are tried
+		tamperedJWE := tamperProtectedHeader(t, serializedJWE, localJWE.OrigProtectedHders, func(header []byte) []byte {
+			return bytes.ReplaceAll(header, []byte(`"kid":"kid-1"`), []byte(`"kid":"kid-2"`))
+		})
+		require.Equal(t, "kid-2", tamperedJWE.ProtectedHeaders["kid"])
+
+		_, err = NewJWEDecrypt(recKHs[0]).Decrypt(tamperedJWE)
+		require.EqualError(t, err, "ecdhes_factory: decryption failed")
+	})
+}
+
+// tamperProtectedHeader replaces the protected header of serializedJWE by the result of tamper and deserializes it.
+func tamperProtectedHeader(t *testing.T, serializedJWE, b64Header string,
+	tamper func(header []byte) []byte) *JSONWebEncryption {
+	t.Helper()
+
+	header, err := base64.RawURLEncoding.DecodeString(b64Header)
+	require.NoError(t, err)
+
+	tamperedHeader := base64.RawURLEncoding.EncodeToString(tamper(header))
+	require.NotEqual(t, b64Header, tamperedHeader)
+
+	jwe, err := Deserialize(strings.Replace(serializedJWE, b64Header, tamperedHeader, 1))
+	require.NoError(t, err)
+
+	return jwe
+}
+
+func TestJWEEncryptRoundTripWithRecipientsKIDs(t *testing.T) {
+	recECKeys, recKHs := createRecipients(t, 2)
+
+	recECKeys[0].KID = "did:example:alice#key-1"
+	recECKeys[1].KID = "did:example:bob#key-1"
+
+	jweEncrypter, err := NewJWEEncrypt(A256GCM, recECKeys)
+	require.NoError(t, err)
+
+	pt := []byte("some msg")
+	jwe, err := jweEncrypter.Encrypt(pt)
+	require.NoError(t, err)
+
+	serializedJWE, err := jwe.FullSerialize(json.Marshal)
+	require.NoError(t, err)
+
+	localJWE, err := Deserialize(serializedJWE)
+	require.NoError(t, err)
+	require.Len(t, localJWE.Recipients, 2)
+
+	for i, recipient := range localJWE.Recipients {
+		require.Equal(t, recECKeys[i].KID, recipient.Header.KID)
+	}
+
+	for _, recKH := range recKHs {
+		msg, err := NewJWEDecrypt(recKH).Decrypt(localJWE)
+		require.NoError(t, err)
+		require.EqualValues(t, pt, msg)
+	}
+}
+
+func TestJWEEncryptRoundTripWithMixedCurves(t *testing.T) {
+	var (
+		recECKeys []*composite.PublicKey
+		recKHs    []*keyset.Handle
+	)
+
+	for _, kt := range []*tinkpb.KeyTemplate{
+		ecdhes.ECDHES256KWAES256GCMKeyTemplate(),
+		ecdhes.ECDHES384KWAES256GCMKeyTemplate(),
+		ecdhes.ECDHES521KWAES256GCMKeyTemplate(),
+		ecdhes.ECDHES256KWAES256GCMKeyTemplate(),
+	} {
+		mrKey, kh := createAndMarshalRecipient(t, kt)
+		ecPubKey := new(composite.PublicKey)
+		require.NoError(t, json.Unmarshal(mrKey, ecPubKey))
+
+		recECKeys = append(recECKeys, ecPubKey)
+		recKHs = append(recKHs, kh)
+	}
+
+	jweEncrypter, err := NewJWEEncrypt(A256GCM, recECKeys)
+	require.NoError(t, err)
+
+	pt := []byte("some msg")
+	jwe, err := jweEncrypter.EncryptWithAuthData(pt, []byte("aad value"))
+	require.NoError(t, err)
+	require.Len(t, jwe.Recipients, len(recECKeys))
+
+	serializedJWE, err := jwe.FullSerialize(json.Marshal)
+	require.NoError(t, err)
+
+	localJWE, err := Deserialize(serializedJWE)
+	require.NoError(t, err)
+
+	for i, recipient := range localJWE.Recipients {
+		epk := &JWK{}
+		require.NoError(t, epk.UnmarshalJSON(recipient.Header.EPK))
+
+		recCurve, err := composite.GetCurveType(recECKeys[i].Curve)
+		require.NoError(t, err)
+
+		epkCurve, err := composite.GetCurveType(epk.Crv)
+		require.NoError(t, err)
+
+		// each recipient is wrapped with an ephemeral key on the curve of its own key
+		require.Equal(t, recCurve, epkCurve)
+	}
+
+	for _, recKH := range recKHs {
+		msg, err := NewJWEDecrypt(recKH).Decrypt(localJWE)
+		require.NoError(t, err)
+		require.EqualValues(t, pt, msg)
+	}
+}
+
 func TestInteropWithGoJoseEncryptAndLocalJoseDecryptUsingCompactSerialize(t *testing.T) {
 	recECKeys, recKHs := createRecipients(t, 1)
 	gjRecipients := convertToGoJoseRecipients(t, recECKeys)
@@ -383,7 +556,7 @@ func createRecipients(t *testing.T, numberOfRecipients int) ([]*composite.Public
 	)
 
 	for i := 0; i < numberOfRecipients; i++ {
-		mrKey, kh := createAndMarshalRecipient(t)
+		mrKey, kh := createAndMarshalRecipient(t, ecdhes.ECDHES256KWAES256GCMKeyTemplate())
 		ecPubKey := new(composite.PublicKey)
 		err := json.Unmarshal(mrKey, ecPubKey)
 		require.NoError(t, err)
@@ -397,10 +570,10 @@ func createRecipients(t *testing.T, numberOfRecipients int) ([]*composite.Public
 
 // createAndMarshalRecipient creates a new recipient keyset.Handle, extracts public key, marshals it and returns
 // both marshalled public key and original recipient keyset.Handle
-func createAndMarshalRecipient(t *testing.T) ([]byte, *keyset.Handle) {
+func createAndMarshalRecipient(t *testing.T, kt *tinkpb.KeyTemplate) ([]byte, *keyset.Handle) {
 	t.Helper()
 
-	kh, err := keyset.NewHandle(ecdhes.ECDHES256KWAES256GCMKeyTemplate())
+	kh, err := keyset.NewHandle(kt)
 	require.NoError(t, err)
 
 	pubKH, err := kh.Public()
@@ -416,17 +589,6 @@ func createAndMarshalRecipient(t *testing.T) ([]byte, *keyset.Handle) {
 	return buf.Bytes(), kh
 }
 
-func TestFailConvertRecKeyToMarshalledJWK(t *testing.T) {
-	recKey := &composite.RecipientWrappedKey{
-		EPK: composite.PublicKey{
-			Curve: "badCurveName",
-		},
-	}
-
-	_, err := convertRecKeyToMarshalledJWK(recKey)
-	require.EqualError(t, err, "unsupported curve")
-}
-
 func TestEmptyComputeAuthData(t *testing.T) {
 	protecteHeaders := new(map[string]interface{})
 	aad := []byte("")
@@ -147,6 +147,25 @@ func (e *JSONWebEncryption) prepareHeaders(marshal marshalFunc) (string, json.Ra
 	return b64ProtectedHeaders, unprotectedHeaders, nil
 }
 
+// AuthData returns the additional authenticated data of the content encryption of the JWE, binding its protected
+// header as per https://tools.ietf.org/html/rfc7516#section-5.1 step 14: the base64url encoded protected header,
+// followed by a '.' and the base64url encoded AAD when the JWE has one. The protected header of a deserialized JWE
+// is used as received, so that any change of its serialization fails the decryption.
+func (e *JSONWebEncryption) AuthData() ([]byte, error) {
+	if e.OrigProtectedHders == "" {
+		return computeAuthData(e.ProtectedHeaders, []byte(e.AAD))
+	}
+
+	authData := []byte(e.OrigProtectedHders)
+
+	if e.AAD != "" {
+		authData = append(authData, '.')
+		authData = append(authData, base64.RawURLEncoding.EncodeToString([]byte(e.AAD))...)
+	}
+
+	return authData, nil
+}
+
 func (e *JSONWebEncryption) prepareRecipients(marshal marshalFunc) (json.RawMessage, string, []byte, error) {
 	var recipientsJSON json.RawMessage
 
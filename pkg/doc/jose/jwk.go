@@ -21,6 +21,8 @@ import (
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/square/go-jose/v3"
 	"golang.org/x/crypto/ed25519"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
 )
 
 const (
@@ -29,6 +31,7 @@ const (
 	secp256k1Kty  = "EC"
 	secp256k1Size = 32
 	bitsPerByte   = 8
+	ecKty         = "EC"
 )
 
 // JWK (JSON Web Key) is a JSON data structure that represents a cryptographic key.
@@ -108,6 +111,13 @@ func (j *JWK) UnmarshalJSON(jwkBytes []byte) error {
 			return fmt.Errorf("unable to read JWK: %w", err)
 		}
 
+		*j = *jwk
+	} else if isCompressedEC(&key) {
+		jwk, err := unmarshalCompressedEC(&key)
+		if err != nil {
+			return fmt.Errorf("unable to read JWK: %w", err)
+		}
+
 		*j = *jwk
 	} else {
 		var joseJWK jose.JSONWebKey
@@ -211,6 +221,44 @@ func unmarshalSecp256k1(jwk *jsonWebKey) (*JWK, error) {
 	}, nil
 }
 
+// isCompressedEC returns true if the EC public key jwk has a compressed 'x' point with an implied 'y' value.
+func isCompressedEC(jwk *jsonWebKey) bool {
+	return strings.EqualFold(jwk.Kty, ecKty) && jwk.X != nil && jwk.Y == nil && jwk.D == nil
+}
+
+func unmarshalCompressedEC(jwk *jsonWebKey) (*JWK, error) {
+	var curve elliptic.Curve
+
+	switch jwk.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, ErrInvalidKey
+	}
+
+	x, y, err := composite.DecompressPoint(curve, jwk.X.data)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	return &JWK{
+		JSONWebKey: jose.JSONWebKey{
+			Key: &ecdsa.PublicKey{
+				Curve: curve,
+				X:     x,
+				Y:     y,
+			},
+			KeyID:     jwk.Kid,
+			Algorithm: jwk.Alg,
+			Use:       jwk.Use,
+		},
+	}, nil
+}
+
 func marshalSecp256k1(jwk *JWK) ([]byte, error) {
 	var raw jsonWebKey
 
@@ -7,15 +7,20 @@ SPDX-License-Identifier: Apache-2.0
 package jose
 
 import (
+	"crypto/ecdsa"
 	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
+	"encoding/base64"
+	"fmt"
 	"testing"
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/square/go-jose/v3"
 	"github.com/square/go-jose/v3/json"
 	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
 )
 
 func TestHeaders_GetJWK(t *testing.T) {
@@ -297,6 +302,45 @@ func TestDecodePublicKey(t *testing.T) {
 	})
 }
 
+func TestDecodeCompressedECPublicKey(t *testing.T) {
+	for _, c := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		curve := c
+
+		t.Run("decode compressed "+curve.Params().Name+" public key", func(t *testing.T) {
+			pvt, err := ecdsa.GenerateKey(curve, rand.Reader)
+			require.NoError(t, err)
+
+			jwkJSON := fmt.Sprintf(`{"kty":"EC","crv":"%s","kid":"kid","use":"enc","x":"%s"}`, curve.Params().Name,
+				base64.RawURLEncoding.EncodeToString(composite.CompressPoint(curve, pvt.X, pvt.Y)))
+
+			var jwk JWK
+
+			err = jwk.UnmarshalJSON([]byte(jwkJSON))
+			require.NoError(t, err)
+			require.Equal(t, "kid", jwk.KeyID)
+			require.Equal(t, "enc", jwk.Use)
+			require.Equal(t, "EC", jwk.Kty)
+			require.Equal(t, curve.Params().Name, jwk.Crv)
+			require.Equal(t, &pvt.PublicKey, jwk.Key)
+		})
+	}
+
+	t.Run("decode compressed public key failures", func(t *testing.T) {
+		pvt, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		x := base64.RawURLEncoding.EncodeToString(composite.CompressPoint(elliptic.P256(), pvt.X, pvt.Y))
+
+		var jwk JWK
+
+		err = jwk.UnmarshalJSON([]byte(fmt.Sprintf(`{"kty":"EC","crv":"P-224","x":"%s"}`, x)))
+		require.EqualError(t, err, "unable to read JWK: invalid JWK")
+
+		err = jwk.UnmarshalJSON([]byte(fmt.Sprintf(`{"kty":"EC","crv":"P-384","x":"%s"}`, x)))
+		require.EqualError(t, err, "unable to read JWK: invalid JWK")
+	})
+}
+
 func TestByteBufferUnmarshalFailure(t *testing.T) {
 	bb := &byteBuffer{}
 	err := bb.UnmarshalJSON([]byte("{"))
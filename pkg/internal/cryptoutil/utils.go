@@ -204,6 +204,15 @@ func LengthPrefix(array []byte) []byte {
 	return arrInfo
 }
 
+// Zeroize overwrites b with zeros, ie to wipe derived keys and shared secrets from memory once they are used. Only b
+// is wiped: copies of its content made by the code it was passed to, such as the expanded key of an AES cipher,
+// aren't.
+func Zeroize(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
 // Curve25519KeySize number of bytes in a Curve25519 public or private key
 const Curve25519KeySize = 32
 
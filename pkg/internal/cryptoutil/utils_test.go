@@ -274,3 +274,12 @@ func TestKeyConversion(t *testing.T) {
 		require.EqualError(t, err, "error converting public key")
 	})
 }
+
+func TestZeroize(t *testing.T) {
+	key := []byte{1, 2, 3, 4, 5}
+
+	Zeroize(key[1:])
+	require.Equal(t, []byte{1, 0, 0, 0, 0}, key)
+
+	require.NotPanics(t, func() { Zeroize(nil) })
+}
@@ -99,6 +99,21 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// Count mocks base method
+func (m *MockStore) Count(arg0, arg1 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count
+func (mr *MockStoreMockRecorder) Count(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockStore)(nil).Count), arg0, arg1)
+}
+
 // Delete mocks base method
 func (m *MockStore) Delete(arg0 string) error {
 	m.ctrl.T.Helper()
@@ -113,6 +128,20 @@ func (mr *MockStoreMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockStore)(nil).Delete), arg0)
 }
 
+// ForEach mocks base method
+func (m *MockStore) ForEach(arg0, arg1 string, arg2 func([]byte, []byte) (bool, error)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForEach", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForEach indicates an expected call of ForEach
+func (mr *MockStoreMockRecorder) ForEach(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForEach", reflect.TypeOf((*MockStore)(nil).ForEach), arg0, arg1, arg2)
+}
+
 // Get mocks base method
 func (m *MockStore) Get(arg0 string) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -128,18 +157,57 @@ func (mr *MockStoreMockRecorder) Get(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStore)(nil).Get), arg0)
 }
 
+// GetBulk mocks base method
+func (m *MockStore) GetBulk(arg0 ...string) ([][]byte, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range arg0 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetBulk", varargs...)
+	ret0, _ := ret[0].([][]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBulk indicates an expected call of GetBulk
+func (mr *MockStoreMockRecorder) GetBulk(arg0 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBulk", reflect.TypeOf((*MockStore)(nil).GetBulk), arg0...)
+}
+
+// Has mocks base method
+func (m *MockStore) Has(arg0 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Has", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Has indicates an expected call of Has
+func (mr *MockStoreMockRecorder) Has(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Has", reflect.TypeOf((*MockStore)(nil).Has), arg0)
+}
+
 // Iterator mocks base method
-func (m *MockStore) Iterator(arg0, arg1 string) storage.StoreIterator {
+func (m *MockStore) Iterator(arg0, arg1 string, arg2 ...storage.IteratorOption) storage.StoreIterator {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Iterator", arg0, arg1)
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Iterator", varargs...)
 	ret0, _ := ret[0].(storage.StoreIterator)
 	return ret0
 }
 
 // Iterator indicates an expected call of Iterator
-func (mr *MockStoreMockRecorder) Iterator(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockStoreMockRecorder) Iterator(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Iterator", reflect.TypeOf((*MockStore)(nil).Iterator), arg0, arg1)
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Iterator", reflect.TypeOf((*MockStore)(nil).Iterator), varargs...)
 }
 
 // Put mocks base method
@@ -155,3 +223,32 @@ func (mr *MockStoreMockRecorder) Put(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockStore)(nil).Put), arg0, arg1)
 }
+
+// PutBatch mocks base method
+func (m *MockStore) PutBatch(arg0 []storage.KeyValue) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutBatch", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PutBatch indicates an expected call of PutBatch
+func (mr *MockStoreMockRecorder) PutBatch(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutBatch", reflect.TypeOf((*MockStore)(nil).PutBatch), arg0)
+}
+
+// PutIfMatch mocks base method
+func (m *MockStore) PutIfMatch(arg0 string, arg1, arg2 []byte) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PutIfMatch", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PutIfMatch indicates an expected call of PutIfMatch
+func (mr *MockStoreMockRecorder) PutIfMatch(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PutIfMatch", reflect.TypeOf((*MockStore)(nil).PutIfMatch), arg0, arg1, arg2)
+}
@@ -187,20 +187,20 @@ func getKeyTemplate(keyType kms.KeyType) (*tinkpb.KeyTemplate, error) {
 	case kms.HMACSHA256Tag256Type:
 		return mac.HMACSHA256Tag256KeyTemplate(), nil
 	case kms.ECDHES256AES256GCMType:
-		return ecdhes.ECDHES256KWAES256GCMKeyTemplate(), nil
+		return ecdhes.NewECDHESKeyTemplate(ecdhes.WithCurve(commonpb.EllipticCurveType_NIST_P256))
 	case kms.ECDHES384AES256GCMType:
-		return ecdhes.ECDHES384KWAES256GCMKeyTemplate(), nil
+		return ecdhes.NewECDHESKeyTemplate(ecdhes.WithCurve(commonpb.EllipticCurveType_NIST_P384))
 	case kms.ECDHES521AES256GCMType:
-		return ecdhes.ECDHES521KWAES256GCMKeyTemplate(), nil
+		return ecdhes.NewECDHESKeyTemplate(ecdhes.WithCurve(commonpb.EllipticCurveType_NIST_P521))
 	case kms.ECDH1PU256AES256GCMType:
 		// Keys created by ECDH1PU templates should be used only to be persisted in the KMS. To execute primitives,
 		// one must add the sender public key (on the recipient side using ecdh1pu.AddSenderKey()) or the recipient(s)
 		// public key(s) (on the sender side using ecdh1pu.AddRecipientsKeys())
-		return ecdh1pu.ECDH1PU256KWAES256GCMKeyTemplate(), nil
+		return ecdh1pu.NewECDH1PUKeyTemplate(commonpb.EllipticCurveType_NIST_P256, aead.AES256GCMKeyTemplate())
 	case kms.ECDH1PU384AES256GCMType:
-		return ecdh1pu.ECDH1PU384KWAES256GCMKeyTemplate(), nil
+		return ecdh1pu.NewECDH1PUKeyTemplate(commonpb.EllipticCurveType_NIST_P384, aead.AES256GCMKeyTemplate())
 	case kms.ECDH1PU521AES256GCMType:
-		return ecdh1pu.ECDH1PU521KWAES256GCMKeyTemplate(), nil
+		return ecdh1pu.NewECDH1PUKeyTemplate(commonpb.EllipticCurveType_NIST_P521, aead.AES256GCMKeyTemplate())
 	default:
 		return nil, fmt.Errorf("key type unrecognized")
 	}
@@ -6,8 +6,10 @@ SPDX-License-Identifier: Apache-2.0
 package storage
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
@@ -85,6 +87,50 @@ func (s *MockStore) Put(k string, v []byte) error {
 	return s.ErrPut
 }
 
+// PutIfMatch stores the key and the record only if the current record equals expected
+func (s *MockStore) PutIfMatch(k string, expected, newValue []byte) (bool, error) {
+	if k == "" {
+		return false, errors.New("key is mandatory")
+	}
+
+	if s.ErrPut != nil {
+		return false, s.ErrPut
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	current, exists := s.Store[k]
+	if exists != (expected != nil) || !bytes.Equal(current, expected) {
+		return false, nil
+	}
+
+	s.Store[k] = newValue
+
+	return true, nil
+}
+
+// PutBatch stores all the given key/value pairs
+func (s *MockStore) PutBatch(kvs []storage.KeyValue) error {
+	for _, kv := range kvs {
+		if kv.Key == "" {
+			return errors.New("key is mandatory")
+		}
+	}
+
+	if s.ErrPut != nil {
+		return s.ErrPut
+	}
+
+	s.lock.Lock()
+	for _, kv := range kvs {
+		s.Store[kv.Key] = kv.Value
+	}
+	s.lock.Unlock()
+
+	return nil
+}
+
 // Get fetches the record based on key
 func (s *MockStore) Get(k string) ([]byte, error) {
 	if s.ErrGet != nil {
@@ -102,8 +148,27 @@ func (s *MockStore) Get(k string) ([]byte, error) {
 	return val, s.ErrGet
 }
 
+// GetBulk fetches the records of the given keys, with a nil entry for every key not found
+func (s *MockStore) GetBulk(keys ...string) ([][]byte, error) {
+	return storage.GetBulk(s.Get, keys...)
+}
+
+// Has checks whether a record with key k exists
+func (s *MockStore) Has(k string) (bool, error) {
+	if s.ErrGet != nil {
+		return false, s.ErrGet
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	_, ok := s.Store[k]
+
+	return ok, nil
+}
+
 // Iterator returns an iterator for the underlying mockstore
-func (s *MockStore) Iterator(start, limit string) storage.StoreIterator {
+func (s *MockStore) Iterator(start, limit string, opts ...storage.IteratorOption) storage.StoreIterator {
 	if s.ErrItr != nil {
 		return NewMockIteratorWithError(s.ErrItr)
 	}
@@ -119,7 +184,51 @@ func (s *MockStore) Iterator(start, limit string) storage.StoreIterator {
 		}
 	}
 
-	return NewMockIterator(batch)
+	options := storage.GetIteratorOptions(opts...)
+
+	sort.Slice(batch, func(i, j int) bool {
+		if options.Reverse {
+			return batch[i][0] > batch[j][0]
+		}
+
+		return batch[i][0] < batch[j][0]
+	})
+
+	total := len(batch)
+
+	if options.Limit > 0 && len(batch) > options.Limit {
+		batch = batch[:options.Limit]
+	}
+
+	itr := NewMockIterator(batch)
+	itr.total = total
+
+	return itr
+}
+
+// Count returns the number of records whose key starts with start
+func (s *MockStore) Count(start, limit string) (int, error) {
+	if s.ErrItr != nil {
+		return 0, s.ErrItr
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	count := 0
+
+	for k := range s.Store {
+		if strings.HasPrefix(k, start) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// ForEach calls fn with every key/value pair within the key range until fn returns stop or an error
+func (s *MockStore) ForEach(start, limit string, fn func(key, value []byte) (bool, error)) error {
+	return storage.ForEach(s.Iterator(start, limit), fn)
 }
 
 // Delete will delete record with k key
@@ -137,7 +246,7 @@ func NewMockIterator(batch [][]string) *MockIterator {
 		return &MockIterator{}
 	}
 
-	return &MockIterator{items: batch}
+	return &MockIterator{items: batch, total: len(batch)}
 }
 
 // NewMockIteratorWithError returns new mock iterator with error
@@ -151,6 +260,7 @@ type MockIterator struct {
 	currentItem  []string
 	items        [][]string
 	err          error
+	total        int
 }
 
 func (s *MockIterator) isExhausted() bool {
@@ -170,6 +280,20 @@ func (s *MockIterator) Next() bool {
 	return true
 }
 
+// Seek moves pointer to the first item whose key is greater than or equal to key.
+// It returns false if there is no such item.
+func (s *MockIterator) Seek(key string) bool {
+	for s.currentIndex = 0; s.currentIndex < len(s.items); s.currentIndex++ {
+		if s.items[s.currentIndex][0] >= key {
+			return s.Next()
+		}
+	}
+
+	s.currentItem = nil
+
+	return false
+}
+
 // Release releases associated resources.
 func (s *MockIterator) Release() {
 	s.currentIndex = 0
@@ -177,6 +301,16 @@ func (s *MockIterator) Release() {
 	s.currentItem = make([]string, 0)
 }
 
+// TotalCount returns the number of items of the iterator before its limit is applied, or -1 and the error of an
+// iterator created with NewMockIteratorWithError.
+func (s *MockIterator) TotalCount() (int, error) {
+	if s.err != nil {
+		return -1, s.err
+	}
+
+	return s.total, nil
+}
+
 // Error returns error in iterator.
 func (s *MockIterator) Error() error {
 	return s.err
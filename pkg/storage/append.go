@@ -0,0 +1,165 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+)
+
+// ErrReadOnly is returned when overwriting or deleting the records of an append-only store
+var ErrReadOnly = errors.New("store is read only")
+
+const (
+	// seqKeyFormat formats the sequence numbers with leading zeros so that the keys sort in sequence order
+	seqKeyFormat = "%020d"
+	// maxAppendAttempts caps the number of sequence numbers tried by Append when other appenders take them first
+	maxAppendAttempts = 100
+)
+
+// AppendStore is an append-only log of records ordered by sequence number, ie for audit trails. The records are
+// stored under their SeqKey in the underlying store, which is exposed read only: Put, PutBatch, PutIfMatch and Delete
+// return ErrReadOnly so that appended records are never overwritten nor deleted.
+type AppendStore interface {
+	Store
+
+	// Append appends the record v to the log and returns its sequence number. Sequence numbers start at 1 and
+	// increase by one with every appended record.
+	Append(v []byte) (uint64, error)
+
+	// Scan returns an iterator over the records with a sequence number greater than or equal to fromSeq, in
+	// increasing sequence order. The keys of the iterator are the SeqKey of the records.
+	Scan(fromSeq uint64) StoreIterator
+}
+
+// SeqKey returns the key of the record with the given sequence number in an AppendStore.
+func SeqKey(seq uint64) string {
+	return fmt.Sprintf(seqKeyFormat, seq)
+}
+
+// ParseSeqKey returns the sequence number of the record with the given key in an AppendStore.
+func ParseSeqKey(k string) (uint64, error) {
+	seq, err := strconv.ParseUint(k, 10, 64)
+	if err != nil || SeqKey(seq) != k {
+		return 0, fmt.Errorf("invalid sequence key %q", k)
+	}
+
+	return seq, nil
+}
+
+// NewAppendStore returns an append-only log of the records of s. s should only hold the records of the log, the
+// records being appended through AppendStores only. A sequence number is allocated by storing the record with
+// PutIfMatch under the key of the sequence number following the last record of s, the next sequence number being
+// tried when another appender stored it first: several AppendStores, in the same process or not, can append to the
+// same store as long as its PutIfMatch is atomic, and every record is visible as soon as it gets its sequence number,
+// the records never appearing out of sequence order.
+func NewAppendStore(s Store) (AppendStore, error) {
+	if s == nil {
+		return nil, errors.New("store is mandatory")
+	}
+
+	a := &appendStore{Store: s}
+
+	lastSeq, err := a.readLastSeq()
+	if err != nil {
+		return nil, err
+	}
+
+	a.lastSeq = lastSeq
+
+	return a, nil
+}
+
+type appendStore struct {
+	Store
+	mu      sync.Mutex
+	lastSeq uint64
+}
+
+// Append stores v under the next free sequence number.
+func (a *appendStore) Append(v []byte) (uint64, error) {
+	if v == nil {
+		return 0, errors.New("record is mandatory")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for attempt := 0; attempt < maxAppendAttempts; attempt++ {
+		seq := a.lastSeq + 1
+
+		stored, err := a.Store.PutIfMatch(SeqKey(seq), nil, v)
+		if err != nil {
+			return 0, fmt.Errorf("failed to append record %d: %w", seq, err)
+		}
+
+		if stored {
+			a.lastSeq = seq
+
+			return seq, nil
+		}
+
+		// another appender took the sequence number, the log is read again to skip the records appended meanwhile
+		lastSeq, err := a.readLastSeq()
+		if err != nil {
+			return 0, err
+		}
+
+		if lastSeq < seq {
+			lastSeq = seq
+		}
+
+		a.lastSeq = lastSeq
+	}
+
+	return 0, fmt.Errorf("failed to append record after %d attempts", maxAppendAttempts)
+}
+
+// Scan iterates over the records from fromSeq on.
+func (a *appendStore) Scan(fromSeq uint64) StoreIterator {
+	// the key of the last sequence number, never allocated, ends the range
+	return a.Store.Iterator(SeqKey(fromSeq), SeqKey(math.MaxUint64))
+}
+
+// readLastSeq returns the sequence number of the last record of the log, zero when the log is empty.
+func (a *appendStore) readLastSeq() (uint64, error) {
+	itr := a.Store.Iterator(SeqKey(0), SeqKey(math.MaxUint64), WithReverse(), WithLimit(1))
+	defer itr.Release()
+
+	if !itr.Next() {
+		if err := itr.Error(); err != nil {
+			return 0, fmt.Errorf("failed to read the last record: %w", err)
+		}
+
+		return 0, nil
+	}
+
+	return ParseSeqKey(string(itr.Key()))
+}
+
+// Put returns ErrReadOnly, records are appended with Append.
+func (a *appendStore) Put(string, []byte) error {
+	return ErrReadOnly
+}
+
+// PutBatch returns ErrReadOnly, records are appended with Append.
+func (a *appendStore) PutBatch([]KeyValue) error {
+	return ErrReadOnly
+}
+
+// PutIfMatch returns ErrReadOnly, records are appended with Append.
+func (a *appendStore) PutIfMatch(string, []byte, []byte) (bool, error) {
+	return false, ErrReadOnly
+}
+
+// Delete returns ErrReadOnly, appended records are never deleted.
+func (a *appendStore) Delete(string) error {
+	return ErrReadOnly
+}
@@ -0,0 +1,135 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestNewAppendStore(t *testing.T) {
+	t.Run("test append and scan", func(t *testing.T) {
+		s, err := mem.NewProvider().OpenStore("audit")
+		require.NoError(t, err)
+
+		log, err := storage.NewAppendStore(s)
+		require.NoError(t, err)
+
+		for i := 1; i <= 12; i++ {
+			seq, err := log.Append([]byte(fmt.Sprintf("event%d", i)))
+			require.NoError(t, err)
+			require.Equal(t, uint64(i), seq)
+		}
+
+		v, err := log.Get(storage.SeqKey(10))
+		require.NoError(t, err)
+		require.Equal(t, []byte("event10"), v)
+
+		itr := log.Scan(9)
+
+		var seqs []uint64
+
+		for itr.Next() {
+			seq, err := storage.ParseSeqKey(string(itr.Key()))
+			require.NoError(t, err)
+			require.Equal(t, []byte(fmt.Sprintf("event%d", seq)), itr.Value())
+
+			seqs = append(seqs, seq)
+		}
+
+		require.NoError(t, itr.Error())
+		itr.Release()
+
+		require.Equal(t, []uint64{9, 10, 11, 12}, seqs)
+
+		// the log carries on from its last record when it is opened again
+		log, err = storage.NewAppendStore(s)
+		require.NoError(t, err)
+
+		seq, err := log.Append([]byte("event13"))
+		require.NoError(t, err)
+		require.Equal(t, uint64(13), seq)
+
+		_, err = log.Append(nil)
+		require.EqualError(t, err, "record is mandatory")
+	})
+
+	t.Run("test records are read only", func(t *testing.T) {
+		s, err := mem.NewProvider().OpenStore("audit")
+		require.NoError(t, err)
+
+		log, err := storage.NewAppendStore(s)
+		require.NoError(t, err)
+
+		_, err = log.Append([]byte("event1"))
+		require.NoError(t, err)
+
+		require.Equal(t, storage.ErrReadOnly, log.Put(storage.SeqKey(1), []byte("forged")))
+		require.Equal(t, storage.ErrReadOnly, log.PutBatch([]storage.KeyValue{{Key: "k", Value: []byte("v")}}))
+		require.Equal(t, storage.ErrReadOnly, log.Delete(storage.SeqKey(1)))
+
+		_, err = log.PutIfMatch(storage.SeqKey(1), []byte("event1"), []byte("forged"))
+		require.Equal(t, storage.ErrReadOnly, err)
+
+		v, err := log.Get(storage.SeqKey(1))
+		require.NoError(t, err)
+		require.Equal(t, []byte("event1"), v)
+	})
+
+	t.Run("test concurrent appenders of the same store", func(t *testing.T) {
+		s, err := mem.NewProvider().OpenStore("audit")
+		require.NoError(t, err)
+
+		const appenders, records = 4, 25
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < appenders; i++ {
+			log, err := storage.NewAppendStore(s)
+			require.NoError(t, err)
+
+			wg.Add(1)
+
+			go func(log storage.AppendStore) {
+				defer wg.Done()
+
+				for j := 0; j < records; j++ {
+					_, err := log.Append([]byte("event"))
+					require.NoError(t, err)
+				}
+			}(log)
+		}
+
+		wg.Wait()
+
+		count, err := s.Count(storage.SeqKey(0), storage.SeqKey(appenders*records+1))
+		require.NoError(t, err)
+		require.Equal(t, appenders*records, count)
+	})
+
+	t.Run("test invalid arguments", func(t *testing.T) {
+		_, err := storage.NewAppendStore(nil)
+		require.EqualError(t, err, "store is mandatory")
+
+		s, err := mem.NewProvider().OpenStore("audit")
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put(storage.SeqKey(1)+"x", []byte("v")))
+
+		_, err = storage.NewAppendStore(s)
+		require.EqualError(t, err, `invalid sequence key "00000000000000000001x"`)
+
+		_, err = storage.ParseSeqKey("1")
+		require.EqualError(t, err, `invalid sequence key "1"`)
+	})
+}
@@ -0,0 +1,618 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultCacheSize is the default maximum number of records cached by each store of a caching provider
+const defaultCacheSize = 1000
+
+// CacheConfig configures the caches of the stores of a caching provider.
+type CacheConfig struct {
+	// Size is the maximum number of records cached by each store, the least recently used records being evicted
+	// first. Default is 1000.
+	Size int
+	// TTL is how long records are cached, records stay cached until evicted or invalidated when TTL isn't positive.
+	TTL time.Duration
+	// WarmOnIterate makes the iterators of the stores cache the records they return, iterators bypass the caches
+	// otherwise.
+	WarmOnIterate bool
+}
+
+// CacheStats are the counters of the caches of a caching provider.
+type CacheStats struct {
+	// Hits is the number of records read from the caches
+	Hits uint64
+	// Misses is the number of records read from the stores of the wrapped provider
+	Misses uint64
+}
+
+// CacheReporter is implemented by caching providers to report the counters of their caches, ie to export them as
+// metrics. Providers can be checked for this capability with a type assertion.
+type CacheReporter interface {
+	// CacheStats returns the counters of the caches of the provider since it was created
+	CacheStats() CacheStats
+}
+
+// NewCachingProvider returns a provider caching the records read from the stores of p in memory, ie for records read
+// far more often than they are written. Every store keeps the last records read by Get, GetContext, GetBulk and Has in
+// an LRU cache, the records of a key being invalidated when the key is written or deleted through the store.
+// Records written to the stores of p by other means, ie by other instances sharing the backend of p, are read from
+// the caches until evicted, a TTL bounds how stale they can be. Records stored with an expiry can be read from the
+// caches after they expire, for up to the TTL.
+// Iterators, counts and ForEach bypass the caches unless CacheConfig.WarmOnIterate is set, in which case the records
+// returned by the iterators are cached too.
+// The caching stores are Transactional, returning ErrTransactionsNotSupported when the stores of p aren't and
+// invalidating the records of a transaction once it ends, ContextStore, falling back to the operations without
+// context, RangeDeleter, purging their cache, and ExpiringStore only when the stores of p are. The provider is a
+// CacheReporter, and a Pinger only when p is.
+func NewCachingProvider(p Provider, cfg CacheConfig) (Provider, error) {
+	if p == nil {
+		return nil, errors.New("provider is mandatory")
+	}
+
+	if cfg.Size < 0 {
+		return nil, errors.New("cache size can't be negative")
+	}
+
+	if cfg.Size == 0 {
+		cfg.Size = defaultCacheSize
+	}
+
+	provider := &cachingProvider{Provider: p, cfg: cfg, caches: map[string]*lruCache{}}
+
+	if pinger, ok := p.(Pinger); ok {
+		return &cachingPinger{cachingProvider: provider, pinger: pinger}, nil
+	}
+
+	return provider, nil
+}
+
+type cachingProvider struct {
+	Provider
+	cfg    CacheConfig
+	hits   uint64
+	misses uint64
+	// caches are shared by the stores opened with the same name so that writes through either invalidate the
+	// records read by the other
+	caches map[string]*lruCache
+	lock   sync.Mutex
+}
+
+// OpenStore opens the store of p and caches its records.
+func (p *cachingProvider) OpenStore(name string) (Store, error) {
+	s, err := p.Provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	p.lock.Lock()
+
+	cache, ok := p.caches[name]
+	if !ok {
+		cache = newLRUCache(p.cfg.Size, p.cfg.TTL)
+		p.caches[name] = cache
+	}
+
+	p.lock.Unlock()
+
+	store := &cachingStore{store: s, cache: cache, provider: p}
+
+	rangeDeleter, isRangeDeleter := s.(RangeDeleter)
+	expiringStore, isExpiringStore := s.(ExpiringStore)
+
+	switch {
+	case isRangeDeleter && isExpiringStore:
+		return &cachingExpiringRangeDeleter{
+			cachingStore:         store,
+			cachingRangeDeleter:  &cachingRangeDeleter{cachingStore: store, rangeDeleter: rangeDeleter},
+			cachingExpiringStore: &cachingExpiringStore{cachingStore: store, expiringStore: expiringStore},
+		}, nil
+	case isRangeDeleter:
+		return &cachingRangeDeleter{cachingStore: store, rangeDeleter: rangeDeleter}, nil
+	case isExpiringStore:
+		return &cachingExpiringStore{cachingStore: store, expiringStore: expiringStore}, nil
+	default:
+		return store, nil
+	}
+}
+
+// CloseStore closes the store of p with the given name and drops its cache.
+func (p *cachingProvider) CloseStore(name string) error {
+	p.lock.Lock()
+	delete(p.caches, name)
+	p.lock.Unlock()
+
+	return p.Provider.CloseStore(name)
+}
+
+// Close closes p and drops the caches of its stores.
+func (p *cachingProvider) Close() error {
+	p.lock.Lock()
+	p.caches = map[string]*lruCache{}
+	p.lock.Unlock()
+
+	return p.Provider.Close()
+}
+
+// CacheStats returns the counters of the caches of the provider since it was created
+func (p *cachingProvider) CacheStats() CacheStats {
+	return CacheStats{Hits: atomic.LoadUint64(&p.hits), Misses: atomic.LoadUint64(&p.misses)}
+}
+
+func (p *cachingProvider) countLookup(hit bool) {
+	if hit {
+		atomic.AddUint64(&p.hits, 1)
+	} else {
+		atomic.AddUint64(&p.misses, 1)
+	}
+}
+
+type cachingPinger struct {
+	*cachingProvider
+	pinger Pinger
+}
+
+// Ping pings the backend of the caching provider.
+func (p *cachingPinger) Ping(ctx context.Context) error {
+	return p.pinger.Ping(ctx)
+}
+
+type cachingStore struct {
+	store    Store
+	cache    *lruCache
+	provider *cachingProvider
+}
+
+// lookup returns the cached record of key k and counts the lookup.
+func (s *cachingStore) lookup(k string) ([]byte, bool) {
+	v, ok := s.cache.get(k)
+	s.provider.countLookup(ok)
+
+	return v, ok
+}
+
+// Put stores the key and the record, and invalidates the cached record of the key
+func (s *cachingStore) Put(k string, v []byte) error {
+	defer s.cache.remove(k)
+
+	return s.store.Put(k, v)
+}
+
+// PutBatch stores all the given key/value pairs, and invalidates the cached records of their keys
+func (s *cachingStore) PutBatch(kvs []KeyValue) error {
+	defer func() {
+		for _, kv := range kvs {
+			s.cache.remove(kv.Key)
+		}
+	}()
+
+	return s.store.PutBatch(kvs)
+}
+
+// PutIfMatch stores the record only if the current record of key k equals expected, the current record being read
+// from the wrapped store
+func (s *cachingStore) PutIfMatch(k string, expected, newValue []byte) (bool, error) {
+	defer s.cache.remove(k)
+
+	return s.store.PutIfMatch(k, expected, newValue)
+}
+
+// Get fetches the record based on key, from the cache when the record is cached. The cache keeps its own copy of
+// the record, the returned record can be modified by the caller.
+func (s *cachingStore) Get(k string) ([]byte, error) {
+	if v, ok := s.lookup(k); ok {
+		return v, nil
+	}
+
+	generation := s.cache.generation()
+
+	v, err := s.store.Get(k)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.add(k, v, generation)
+
+	return v, nil
+}
+
+// GetBulk fetches the records of all the given keys, the records that aren't cached being fetched at once. As with
+// Get, the returned records can be modified by the caller.
+func (s *cachingStore) GetBulk(keys ...string) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+
+	var (
+		missingKeys    []string
+		missingIndexes []int
+	)
+
+	for i, k := range keys {
+		v, ok := s.lookup(k)
+		if !ok {
+			missingKeys = append(missingKeys, k)
+			missingIndexes = append(missingIndexes, i)
+
+			continue
+		}
+
+		values[i] = v
+	}
+
+	if len(missingKeys) == 0 {
+		return values, nil
+	}
+
+	generation := s.cache.generation()
+
+	missingValues, err := s.store.GetBulk(missingKeys...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, v := range missingValues {
+		values[missingIndexes[i]] = v
+
+		if v != nil {
+			s.cache.add(missingKeys[i], v, generation)
+		}
+	}
+
+	return values, nil
+}
+
+// Has checks whether a record with key k exists, records that are cached exist
+func (s *cachingStore) Has(k string) (bool, error) {
+	if _, ok := s.lookup(k); ok {
+		return true, nil
+	}
+
+	return s.store.Has(k)
+}
+
+// Iterator returns an iterator over the range of the wrapped store, caching the records it returns when
+// CacheConfig.WarmOnIterate is set.
+func (s *cachingStore) Iterator(startKey, endKey string, opts ...IteratorOption) StoreIterator {
+	itr := s.store.Iterator(startKey, endKey, opts...)
+
+	if !s.provider.cfg.WarmOnIterate {
+		return itr
+	}
+
+	return &cachingIterator{StoreIterator: itr, cache: s.cache, generation: s.cache.generation()}
+}
+
+// Count returns the number of records within the key range of the wrapped store
+func (s *cachingStore) Count(startKey, endKey string) (int, error) {
+	return s.store.Count(startKey, endKey)
+}
+
+// ForEach calls fn with every key/value pair within the key range, caching the records when
+// CacheConfig.WarmOnIterate is set.
+func (s *cachingStore) ForEach(startKey, endKey string, fn func(key, value []byte) (bool, error)) error {
+	if !s.provider.cfg.WarmOnIterate {
+		return s.store.ForEach(startKey, endKey, fn)
+	}
+
+	return ForEach(s.Iterator(startKey, endKey), fn)
+}
+
+// Delete will delete a record with k key, and invalidates the cached record of the key
+func (s *cachingStore) Delete(k string) error {
+	defer s.cache.remove(k)
+
+	return s.store.Delete(k)
+}
+
+// Begin starts a new transaction invalidating the cached records of its keys when it ends, stores wrapping a store
+// that can't execute atomic transactions return ErrTransactionsNotSupported.
+func (s *cachingStore) Begin(opts ...TxOption) (Transaction, error) {
+	txStore, ok := s.store.(Transactional)
+	if !ok {
+		return nil, ErrTransactionsNotSupported
+	}
+
+	tx, err := txStore.Begin(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cachingTransaction{Transaction: tx, cache: s.cache, keys: map[string]struct{}{}}, nil
+}
+
+// PutContext stores the key and the record, the operation isn't started when ctx is already done if the wrapped
+// store isn't a ContextStore.
+func (s *cachingStore) PutContext(ctx context.Context, k string, v []byte) error {
+	ctxStore, ok := s.store.(ContextStore)
+	if !ok {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		return s.Put(k, v)
+	}
+
+	defer s.cache.remove(k)
+
+	return ctxStore.PutContext(ctx, k, v)
+}
+
+// GetContext fetches the record based on key, the operation isn't started when ctx is already done if the wrapped
+// store isn't a ContextStore.
+func (s *cachingStore) GetContext(ctx context.Context, k string) ([]byte, error) {
+	ctxStore, ok := s.store.(ContextStore)
+	if !ok {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		return s.Get(k)
+	}
+
+	if v, found := s.lookup(k); found {
+		return v, nil
+	}
+
+	generation := s.cache.generation()
+
+	v, err := ctxStore.GetContext(ctx, k)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.add(k, v, generation)
+
+	return v, nil
+}
+
+// DeleteContext deletes the record of key k, the operation isn't started when ctx is already done if the wrapped
+// store isn't a ContextStore.
+func (s *cachingStore) DeleteContext(ctx context.Context, k string) error {
+	ctxStore, ok := s.store.(ContextStore)
+	if !ok {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		return s.Delete(k)
+	}
+
+	defer s.cache.remove(k)
+
+	return ctxStore.DeleteContext(ctx, k)
+}
+
+type cachingRangeDeleter struct {
+	*cachingStore
+	rangeDeleter RangeDeleter
+}
+
+// DeleteRange deletes the records within the key range and purges the cache of the store
+func (s *cachingRangeDeleter) DeleteRange(startKey, endKey string) (int, error) {
+	defer s.cache.purge()
+
+	return s.rangeDeleter.DeleteRange(startKey, endKey)
+}
+
+type cachingExpiringStore struct {
+	*cachingStore
+	expiringStore ExpiringStore
+}
+
+// PutWithExpiry stores the key and the record until ttl elapses, and invalidates the cached record of the key
+func (s *cachingExpiringStore) PutWithExpiry(k string, v []byte, ttl time.Duration) error {
+	defer s.cache.remove(k)
+
+	return s.expiringStore.PutWithExpiry(k, v, ttl)
+}
+
+// cachingExpiringRangeDeleter is both a cachingRangeDeleter and a cachingExpiringStore, the methods of the store
+// being promoted from its own cachingStore.
+type cachingExpiringRangeDeleter struct {
+	*cachingStore
+	*cachingRangeDeleter
+	*cachingExpiringStore
+}
+
+// cachingTransaction invalidates the cached records of the keys it writes when it's committed or rolled back, the
+// records read by the store while the transaction is pending being invalidated too.
+type cachingTransaction struct {
+	Transaction
+	cache *lruCache
+	keys  map[string]struct{}
+}
+
+// Put stores the key and the record within the transaction
+func (t *cachingTransaction) Put(k string, v []byte) error {
+	t.keys[k] = struct{}{}
+
+	return t.Transaction.Put(k, v)
+}
+
+// Delete deletes the record of key k within the transaction
+func (t *cachingTransaction) Delete(k string) error {
+	t.keys[k] = struct{}{}
+
+	return t.Transaction.Delete(k)
+}
+
+// Commit makes all the operations of the transaction permanent
+func (t *cachingTransaction) Commit() error {
+	defer t.invalidate()
+
+	return t.Transaction.Commit()
+}
+
+// Rollback discards all the operations of the transaction
+func (t *cachingTransaction) Rollback() error {
+	defer t.invalidate()
+
+	return t.Transaction.Rollback()
+}
+
+func (t *cachingTransaction) invalidate() {
+	for k := range t.keys {
+		t.cache.remove(k)
+	}
+}
+
+// cachingIterator caches the records it returns, as long as no record of the store was invalidated since it was
+// created.
+type cachingIterator struct {
+	StoreIterator
+	cache      *lruCache
+	generation uint64
+}
+
+// Next moves the iterator to the next key/value pair and caches it.
+func (i *cachingIterator) Next() bool {
+	if !i.StoreIterator.Next() {
+		return false
+	}
+
+	i.cache.add(string(i.Key()), i.Value(), i.generation)
+
+	return true
+}
+
+// Seek moves the iterator to the first key/value pair of its range at or after key and caches it.
+func (i *cachingIterator) Seek(key string) bool {
+	if !i.StoreIterator.Seek(key) {
+		return false
+	}
+
+	i.cache.add(string(i.Key()), i.Value(), i.generation)
+
+	return true
+}
+
+// lruCache is an LRU cache of records safe for concurrent use. Its generation changes whenever a record is invalidated,
+// records read before an invalidation aren't added so that the records written meanwhile aren't cached with a stale
+// value.
+type lruCache struct {
+	size  int
+	ttl   time.Duration
+	gen   uint64
+	items map[string]*list.Element
+	// order holds the entries from the most to the least recently used
+	order *list.List
+	lock  sync.Mutex
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+func newLRUCache(size int, ttl time.Duration) *lruCache {
+	return &lruCache{size: size, ttl: ttl, items: map[string]*list.Element{}, order: list.New()}
+}
+
+// get returns a copy of the cached record of key k, expired records being removed.
+func (c *lruCache) get(k string) ([]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.items[k]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.items, k)
+
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+
+	return copyBytes(entry.value), true
+}
+
+// generation returns the current generation of the cache, to be given to add.
+func (c *lruCache) generation() uint64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.gen
+}
+
+// add caches a copy of the record of key k read at the given generation, unless a record was invalidated since.
+func (c *lruCache) add(k string, v []byte, generation uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if generation != c.gen {
+		return
+	}
+
+	entry := &lruEntry{key: k, value: copyBytes(v)}
+
+	if c.ttl > 0 {
+		entry.expiresAt = time.Now().Add(c.ttl)
+	}
+
+	if elem, ok := c.items[k]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+
+		return
+	}
+
+	c.items[k] = c.order.PushFront(entry)
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// remove invalidates the cached record of key k.
+func (c *lruCache) remove(k string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.gen++
+
+	if elem, ok := c.items[k]; ok {
+		c.order.Remove(elem)
+		delete(c.items, k)
+	}
+}
+
+// purge invalidates all the cached records.
+func (c *lruCache) purge() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.gen++
+	c.items = map[string]*list.Element{}
+	c.order.Init()
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+
+	c := make([]byte, len(b))
+	copy(c, b)
+
+	return c
+}
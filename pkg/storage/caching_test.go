@@ -0,0 +1,360 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestNewCachingProvider(t *testing.T) {
+	t.Run("test invalid config", func(t *testing.T) {
+		_, err := storage.NewCachingProvider(nil, storage.CacheConfig{})
+		require.EqualError(t, err, "provider is mandatory")
+
+		_, err = storage.NewCachingProvider(mem.NewProvider(), storage.CacheConfig{Size: -1})
+		require.EqualError(t, err, "cache size can't be negative")
+	})
+
+	t.Run("test records are read from the cache", func(t *testing.T) {
+		obs := &recordingObserver{}
+		prov, store := openCachingStore(t, obs, storage.CacheConfig{})
+
+		require.NoError(t, store.Put("k1", []byte("v1")))
+
+		for i := 0; i < 3; i++ {
+			v, err := store.Get("k1")
+			require.NoError(t, err)
+			require.Equal(t, []byte("v1"), v)
+
+			// the cached record can't be modified by the callers
+			v[0] = 'x'
+		}
+
+		found, err := store.Has("k1")
+		require.NoError(t, err)
+		require.True(t, found)
+
+		_, err = store.Get("k2")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		require.Equal(t, storage.CacheStats{Hits: 3, Misses: 2}, prov.(storage.CacheReporter).CacheStats())
+		require.Equal(t, []string{storage.OpPut, storage.OpGet, storage.OpGet}, operationNames(obs))
+	})
+
+	t.Run("test cached records are copied", func(t *testing.T) {
+		_, store := openCachingStore(t, &recordingObserver{}, storage.CacheConfig{})
+
+		require.NoError(t, store.PutBatch([]storage.KeyValue{
+			{Key: "k1", Value: []byte("v1")},
+			{Key: "k2", Value: []byte("v2")},
+		}))
+
+		// the records read from the wrapped store are cached as copies
+		v, err := store.Get("k1")
+		require.NoError(t, err)
+
+		v[0] = 'x'
+
+		values, err := store.GetBulk("k1", "k2")
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("v1"), []byte("v2")}, values)
+
+		values[1][0] = 'x'
+
+		// the cached records are returned as copies
+		values, err = store.GetBulk("k1", "k2")
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("v1"), []byte("v2")}, values)
+
+		values[0][0] = 'x'
+
+		requireRecord(t, store, "k1", "v1")
+		requireRecord(t, store, "k2", "v2")
+	})
+
+	t.Run("test records are invalidated when written", func(t *testing.T) {
+		obs := &recordingObserver{}
+		_, store := openCachingStore(t, obs, storage.CacheConfig{})
+
+		require.NoError(t, store.Put("k1", []byte("v1")))
+		requireRecord(t, store, "k1", "v1")
+
+		require.NoError(t, store.Put("k1", []byte("v2")))
+		requireRecord(t, store, "k1", "v2")
+
+		require.NoError(t, store.PutBatch([]storage.KeyValue{{Key: "k1", Value: []byte("v3")}}))
+		requireRecord(t, store, "k1", "v3")
+
+		stored, err := store.PutIfMatch("k1", []byte("v3"), []byte("v4"))
+		require.NoError(t, err)
+		require.True(t, stored)
+		requireRecord(t, store, "k1", "v4")
+
+		require.NoError(t, store.(storage.ExpiringStore).PutWithExpiry("k1", []byte("v5"), time.Hour))
+		requireRecord(t, store, "k1", "v5")
+
+		ctxStore := store.(storage.ContextStore)
+
+		require.NoError(t, ctxStore.PutContext(context.Background(), "k1", []byte("v6")))
+
+		v, err := ctxStore.GetContext(context.Background(), "k1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v6"), v)
+
+		require.NoError(t, ctxStore.DeleteContext(context.Background(), "k1"))
+
+		_, err = store.Get("k1")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		require.NoError(t, store.Put("k1", []byte("v7")))
+		requireRecord(t, store, "k1", "v7")
+
+		require.NoError(t, store.Delete("k1"))
+
+		_, err = store.Get("k1")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		// instrumented stores aren't RangeDeleter
+		_, ok := store.(storage.RangeDeleter)
+		require.False(t, ok)
+
+		prov, err := storage.NewCachingProvider(mem.NewProvider(), storage.CacheConfig{})
+		require.NoError(t, err)
+
+		store, err = prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("k1", []byte("v8")))
+		requireRecord(t, store, "k1", "v8")
+
+		deleted, err := store.(storage.RangeDeleter).DeleteRange("k", "k"+storage.EndKeySuffix)
+		require.NoError(t, err)
+		require.Equal(t, 1, deleted)
+
+		_, err = store.Get("k1")
+		require.Equal(t, storage.ErrDataNotFound, err)
+	})
+
+	t.Run("test stores opened with the same name share their cache", func(t *testing.T) {
+		prov, err := storage.NewCachingProvider(mem.NewProvider(), storage.CacheConfig{})
+		require.NoError(t, err)
+
+		store1, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		store2, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, store1.Put("k1", []byte("v1")))
+		requireRecord(t, store2, "k1", "v1")
+
+		require.NoError(t, store1.Put("k1", []byte("v2")))
+		requireRecord(t, store2, "k1", "v2")
+
+		require.NoError(t, prov.CloseStore("test"))
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("test least recently used records are evicted", func(t *testing.T) {
+		obs := &recordingObserver{}
+		prov, store := openCachingStore(t, obs, storage.CacheConfig{Size: 2})
+
+		for _, k := range []string{"k1", "k2", "k3"} {
+			require.NoError(t, store.Put(k, []byte("v")))
+		}
+
+		values, err := store.GetBulk("k1", "k2", "k4")
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("v"), []byte("v"), nil}, values)
+
+		// k1 is used more recently than k2
+		requireRecord(t, store, "k1", "v")
+		requireRecord(t, store, "k3", "v")
+
+		values, err = store.GetBulk("k1", "k3")
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("v"), []byte("v")}, values)
+
+		requireRecord(t, store, "k2", "v")
+
+		require.Equal(t, storage.CacheStats{Hits: 3, Misses: 5}, prov.(storage.CacheReporter).CacheStats())
+	})
+
+	t.Run("test cached records expire", func(t *testing.T) {
+		obs := &recordingObserver{}
+		prov, store := openCachingStore(t, obs, storage.CacheConfig{TTL: 10 * time.Millisecond})
+
+		require.NoError(t, store.Put("k1", []byte("v1")))
+		requireRecord(t, store, "k1", "v1")
+		requireRecord(t, store, "k1", "v1")
+
+		time.Sleep(20 * time.Millisecond)
+
+		requireRecord(t, store, "k1", "v1")
+
+		require.Equal(t, storage.CacheStats{Hits: 1, Misses: 2}, prov.(storage.CacheReporter).CacheStats())
+	})
+
+	t.Run("test iterators bypass the cache unless warming it", func(t *testing.T) {
+		obs := &recordingObserver{}
+		prov, store := openCachingStore(t, obs, storage.CacheConfig{})
+
+		require.NoError(t, store.Put("k1", []byte("v1")))
+
+		verifyIteratorKeys(t, store.Iterator("k", "k"+storage.EndKeySuffix), "k1")
+		requireRecord(t, store, "k1", "v1")
+
+		require.Equal(t, storage.CacheStats{Misses: 1}, prov.(storage.CacheReporter).CacheStats())
+
+		obs = &recordingObserver{}
+		prov, store = openCachingStore(t, obs, storage.CacheConfig{WarmOnIterate: true})
+
+		require.NoError(t, store.Put("k1", []byte("v1")))
+		require.NoError(t, store.Put("k2", []byte("v2")))
+
+		verifyIteratorKeys(t, store.Iterator("k", "k"+storage.EndKeySuffix), "k1")
+
+		itr := store.Iterator("k", "k"+storage.EndKeySuffix)
+		require.True(t, itr.Seek("k2"))
+		itr.Release()
+
+		requireRecord(t, store, "k1", "v1")
+		requireRecord(t, store, "k2", "v2")
+
+		count, err := store.Count("k", "k"+storage.EndKeySuffix)
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+
+		require.Equal(t, storage.CacheStats{Hits: 2}, prov.(storage.CacheReporter).CacheStats())
+		require.NotContains(t, operationNames(obs), storage.OpGet)
+	})
+
+	t.Run("test records of transactions are invalidated", func(t *testing.T) {
+		prov, err := storage.NewCachingProvider(&txProvider{Provider: mem.NewProvider()}, storage.CacheConfig{})
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("k1", []byte("v1")))
+		requireRecord(t, store, "k1", "v1")
+
+		tx, err := store.(storage.Transactional).Begin()
+		require.NoError(t, err)
+		require.NoError(t, tx.Put("k1", []byte("v2")))
+
+		requireRecord(t, store, "k1", "v1")
+
+		require.NoError(t, tx.Commit())
+
+		requireRecord(t, store, "k1", "v2")
+	})
+
+	t.Run("test optional capabilities", func(t *testing.T) {
+		prov, err := storage.NewCachingProvider(mem.NewProvider(), storage.CacheConfig{})
+		require.NoError(t, err)
+
+		_, ok := prov.(storage.Pinger)
+		require.False(t, ok)
+
+		_, ok = prov.(storage.CacheReporter)
+		require.True(t, ok)
+
+		prov, err = storage.NewCachingProvider(&pingingProvider{Provider: mem.NewProvider()}, storage.CacheConfig{})
+		require.NoError(t, err)
+
+		require.Equal(t, storage.HealthStatusUp, storage.Health(context.Background(), prov).Status)
+
+		_, ok = prov.(storage.CacheReporter)
+		require.True(t, ok)
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		_, err = store.(storage.Transactional).Begin()
+		require.Equal(t, storage.ErrTransactionsNotSupported, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.Equal(t, context.Canceled, store.(storage.ContextStore).PutContext(ctx, "k1", []byte("v1")))
+	})
+}
+
+// txProvider opens txStore stores.
+type txProvider struct {
+	storage.Provider
+}
+
+func (p *txProvider) OpenStore(name string) (storage.Store, error) {
+	s, err := p.Provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &txStore{Store: s}, nil
+}
+
+type pingingProvider struct {
+	storage.Provider
+}
+
+func (p *pingingProvider) Ping(context.Context) error {
+	return nil
+}
+
+// openCachingStore opens a store of a caching provider over an instrumented mem provider reporting to obs, obs being
+// reset before the store is returned.
+func openCachingStore(t *testing.T, obs *recordingObserver, cfg storage.CacheConfig) (storage.Provider, storage.Store) {
+	t.Helper()
+
+	prov, err := storage.NewCachingProvider(storage.NewInstrumentedProvider(mem.NewProvider(), obs), cfg)
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	obs.operations = nil
+
+	return prov, store
+}
+
+func requireRecord(t *testing.T, store storage.Store, k, expected string) {
+	t.Helper()
+
+	v, err := store.Get(k)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(v))
+}
+
+func operationNames(obs *recordingObserver) []string {
+	var names []string
+
+	for _, op := range obs.operations {
+		names = append(names, op.op)
+	}
+
+	return names
+}
+
+func verifyIteratorKeys(t *testing.T, itr storage.StoreIterator, keys ...string) {
+	t.Helper()
+
+	defer itr.Release()
+
+	for _, k := range keys {
+		require.True(t, itr.Next())
+		require.Equal(t, k, string(itr.Key()))
+	}
+}
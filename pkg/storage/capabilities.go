@@ -0,0 +1,72 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage
+
+// StoreCapabilities reports the optional interfaces implemented by a store, as returned by Capabilities.
+type StoreCapabilities struct {
+	// SupportsBatch is true for every store since PutBatch and GetBulk are part of Store
+	SupportsBatch bool
+	// SupportsTransactions is true if the store implements Transactional
+	SupportsTransactions bool
+	// SupportsRangeDelete is true if the store implements RangeDeleter
+	SupportsRangeDelete bool
+	// SupportsFullIteration is true if the store implements FullIterator
+	SupportsFullIteration bool
+	// SupportsJSONFields is true if the store implements JSONStore
+	SupportsJSONFields bool
+	// SupportsContext is true if the store implements ContextStore
+	SupportsContext bool
+	// SupportsPrimaryReads is true if the store implements PrimaryReader
+	SupportsPrimaryReads bool
+	// SupportsTTL is true if the store implements ExpiringStore
+	SupportsTTL bool
+	// SupportsSoftDelete is true if the store implements SoftDeleteStore
+	SupportsSoftDelete bool
+	// SupportsTags is true if the store implements TaggedStore
+	SupportsTags bool
+	// SupportsSizeEstimation is true if the store implements SizeEstimator
+	SupportsSizeEstimation bool
+	// SupportsAppend is true if the store implements AppendStore
+	SupportsAppend bool
+}
+
+// Capabilities returns the optional interfaces implemented by s, so that callers can branch on the capabilities of a
+// store in one place instead of type asserting it. It only checks the methods of s: the stores wrapping other stores,
+// ie namespaced stores, may implement an interface whose methods fail when the wrapped store doesn't implement it, like
+// Begin returning ErrTransactionsNotSupported. No capability is reported for a nil store.
+func Capabilities(s Store) StoreCapabilities {
+	if s == nil {
+		return StoreCapabilities{}
+	}
+
+	_, transactional := s.(Transactional)
+	_, rangeDeleter := s.(RangeDeleter)
+	_, fullIterator := s.(FullIterator)
+	_, jsonStore := s.(JSONStore)
+	_, contextStore := s.(ContextStore)
+	_, primaryReader := s.(PrimaryReader)
+	_, expiringStore := s.(ExpiringStore)
+	_, softDeleteStore := s.(SoftDeleteStore)
+	_, taggedStore := s.(TaggedStore)
+	_, sizeEstimator := s.(SizeEstimator)
+	_, appendStore := s.(AppendStore)
+
+	return StoreCapabilities{
+		SupportsBatch:          true,
+		SupportsTransactions:   transactional,
+		SupportsRangeDelete:    rangeDeleter,
+		SupportsFullIteration:  fullIterator,
+		SupportsJSONFields:     jsonStore,
+		SupportsContext:        contextStore,
+		SupportsPrimaryReads:   primaryReader,
+		SupportsTTL:            expiringStore,
+		SupportsSoftDelete:     softDeleteStore,
+		SupportsTags:           taggedStore,
+		SupportsSizeEstimation: sizeEstimator,
+		SupportsAppend:         appendStore,
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+// plainStore only implements the methods of storage.Store
+type plainStore struct {
+	storage.Store
+}
+
+func TestCapabilities(t *testing.T) {
+	store, err := mem.NewProvider().OpenStore("test")
+	require.NoError(t, err)
+
+	t.Run("test mem store", func(t *testing.T) {
+		require.Equal(t, storage.StoreCapabilities{
+			SupportsBatch:          true,
+			SupportsTransactions:   true,
+			SupportsRangeDelete:    true,
+			SupportsFullIteration:  true,
+			SupportsTTL:            true,
+			SupportsTags:           true,
+			SupportsSizeEstimation: true,
+		}, storage.Capabilities(store))
+	})
+
+	t.Run("test append store", func(t *testing.T) {
+		appendStore, err := storage.NewAppendStore(store)
+		require.NoError(t, err)
+
+		capabilities := storage.Capabilities(appendStore)
+		require.True(t, capabilities.SupportsBatch)
+		require.True(t, capabilities.SupportsAppend)
+	})
+
+	t.Run("test store without optional interfaces", func(t *testing.T) {
+		require.Equal(t, storage.StoreCapabilities{SupportsBatch: true}, storage.Capabilities(&plainStore{store}))
+	})
+
+	t.Run("test nil store", func(t *testing.T) {
+		require.Equal(t, storage.StoreCapabilities{}, storage.Capabilities(nil))
+	})
+}
@@ -0,0 +1,371 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package compressed provides a storage.Provider compressing the values of the stores of another provider.
+//
+// Compressed values are stored with a header made of a magic prefix and the ID of their compression algorithm so
+// that values stored before the stores were wrapped, which don't start with the magic prefix, are still returned as
+// is. Values smaller than the threshold of the provider, or that compression doesn't make smaller, are stored
+// uncompressed to avoid expanding tiny records.
+package compressed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	// defaultThreshold is the size from which values are compressed by default
+	defaultThreshold = 1024
+	// uncompressedID is the algorithm ID of uncompressed values starting with the magic prefix
+	uncompressedID byte = 0
+)
+
+// magic prefixes the header of the values encoded by the stores, it isn't valid UTF-8 so that it can't be the
+// beginning of a legacy JSON value.
+func magic() []byte {
+	return []byte{0xff, 0xc0, 0x5a}
+}
+
+// Provider is a storage.Provider compressing the values of the stores of the provider it wraps.
+type Provider struct {
+	storage.Provider
+	compressor Compressor
+	threshold  int
+	// decompressors decode values compressed by any of the built-in algorithms or by the configured compressor
+	decompressors map[byte]Compressor
+}
+
+// Option configures the compressed provider
+type Option func(opts *Provider)
+
+// WithCompressor option sets the algorithm compressing the values, default is gzip. Values compressed by the
+// built-in algorithms can always be decompressed, values compressed by a custom algorithm can be decompressed only
+// as long as the provider is configured with it.
+func WithCompressor(c Compressor) Option {
+	return func(opts *Provider) {
+		opts.compressor = c
+	}
+}
+
+// WithThreshold option sets the size in bytes from which values are compressed, smaller values are stored
+// uncompressed. Default is 1024.
+func WithThreshold(n int) Option {
+	return func(opts *Provider) {
+		opts.threshold = n
+	}
+}
+
+// NewProvider instantiates a Provider compressing the values of the stores of p.
+func NewProvider(p storage.Provider, opts ...Option) (*Provider, error) {
+	if p == nil {
+		return nil, errors.New("provider is mandatory")
+	}
+
+	gzipCompressor, err := NewGzipCompressor(gzip.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	zstdCompressor := NewZstdCompressor()
+
+	prov := &Provider{
+		Provider:   p,
+		compressor: gzipCompressor,
+		threshold:  defaultThreshold,
+		decompressors: map[byte]Compressor{
+			GzipID: gzipCompressor,
+			ZstdID: zstdCompressor,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(prov)
+	}
+
+	if prov.compressor == nil {
+		return nil, errors.New("compressor is mandatory")
+	}
+
+	id := prov.compressor.ID()
+
+	if id == uncompressedID || (id <= maxReservedID && id != GzipID && id != ZstdID) {
+		return nil, fmt.Errorf("compressor ID %d is reserved", id)
+	}
+
+	prov.decompressors[id] = prov.compressor
+
+	return prov, nil
+}
+
+// OpenStore opens the store of the wrapped provider with the given name, compressing its values.
+func (p *Provider) OpenStore(name string) (storage.Store, error) {
+	store, err := p.Provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &compressedStore{store: store, provider: p}, nil
+}
+
+// encode compresses the value if it's worth it and adds the header when needed.
+func (p *Provider) encode(v []byte) ([]byte, error) {
+	if len(v) >= p.threshold {
+		compressed, err := p.compressor.Compress(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compress value: %w", err)
+		}
+
+		if len(compressed)+len(magic())+1 < len(v) {
+			return withHeader(p.compressor.ID(), compressed), nil
+		}
+	}
+
+	// uncompressed values looking like encoded values get a header too
+	if bytes.HasPrefix(v, magic()) {
+		return withHeader(uncompressedID, v), nil
+	}
+
+	return v, nil
+}
+
+// decode decompresses the value if it has a header, values without a header are returned as is.
+func (p *Provider) decode(v []byte) ([]byte, error) {
+	if !bytes.HasPrefix(v, magic()) || len(v) == len(magic()) {
+		return v, nil
+	}
+
+	id := v[len(magic())]
+	data := v[len(magic())+1:]
+
+	if id == uncompressedID {
+		return data, nil
+	}
+
+	decompressor, ok := p.decompressors[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown compression algorithm %d", id)
+	}
+
+	decompressed, err := decompressor.Decompress(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress value: %w", err)
+	}
+
+	return decompressed, nil
+}
+
+func withHeader(id byte, data []byte) []byte {
+	encoded := make([]byte, 0, len(magic())+1+len(data))
+	encoded = append(encoded, magic()...)
+	encoded = append(encoded, id)
+
+	return append(encoded, data...)
+}
+
+type compressedStore struct {
+	store    storage.Store
+	provider *Provider
+}
+
+// Put compresses the value and stores it with the key
+func (s *compressedStore) Put(k string, v []byte) error {
+	encoded, err := s.provider.encode(v)
+	if err != nil {
+		return err
+	}
+
+	return s.store.Put(k, encoded)
+}
+
+// PutBatch compresses the values of all the given key/value pairs and stores them
+func (s *compressedStore) PutBatch(kvs []storage.KeyValue) error {
+	encodedKVs := make([]storage.KeyValue, len(kvs))
+
+	for i, kv := range kvs {
+		encoded, err := s.provider.encode(kv.Value)
+		if err != nil {
+			return err
+		}
+
+		encodedKVs[i] = storage.KeyValue{Key: kv.Key, Value: encoded}
+	}
+
+	return s.store.PutBatch(encodedKVs)
+}
+
+// PutIfMatch stores the compressed value only if the current decompressed value equals expected. The current value
+// may have been stored uncompressed, so the current stored value is the one matched by the wrapped store.
+func (s *compressedStore) PutIfMatch(k string, expected, newValue []byte) (bool, error) {
+	if k == "" {
+		return false, storage.ErrKeyRequired
+	}
+
+	var current []byte
+
+	if expected != nil {
+		var err error
+
+		current, err = s.store.Get(k)
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return false, nil
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		decoded, err := s.provider.decode(current)
+		if err != nil {
+			return false, err
+		}
+
+		if !bytes.Equal(decoded, expected) {
+			return false, nil
+		}
+	}
+
+	encoded, err := s.provider.encode(newValue)
+	if err != nil {
+		return false, err
+	}
+
+	return s.store.PutIfMatch(k, current, encoded)
+}
+
+// Get fetches the value of the key and decompresses it
+func (s *compressedStore) Get(k string) ([]byte, error) {
+	v, err := s.store.Get(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.provider.decode(v)
+}
+
+// GetBulk fetches the values of the given keys and decompresses them, with a nil entry for every key not found
+func (s *compressedStore) GetBulk(keys ...string) ([][]byte, error) {
+	values, err := s.store.GetBulk(keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+
+		values[i], err = s.provider.decode(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// Has checks whether a record with key k exists
+func (s *compressedStore) Has(k string) (bool, error) {
+	return s.store.Has(k)
+}
+
+// Iterator returns an iterator over the range, decompressing the values.
+func (s *compressedStore) Iterator(startKey, endKey string, opts ...storage.IteratorOption) storage.StoreIterator {
+	return &compressedIterator{StoreIterator: s.store.Iterator(startKey, endKey, opts...), provider: s.provider}
+}
+
+// Count returns the number of records within the key range
+func (s *compressedStore) Count(startKey, endKey string) (int, error) {
+	return s.store.Count(startKey, endKey)
+}
+
+// ForEach calls fn with every key and decompressed value within the key range
+func (s *compressedStore) ForEach(startKey, endKey string, fn func(key, value []byte) (bool, error)) error {
+	return storage.ForEach(s.Iterator(startKey, endKey), fn)
+}
+
+// Delete will delete record with k key
+func (s *compressedStore) Delete(k string) error {
+	return s.store.Delete(k)
+}
+
+// Begin starts a new transaction compressing the values it stores, stores wrapping a store that can't execute
+// atomic transactions return storage.ErrTransactionsNotSupported.
+func (s *compressedStore) Begin(opts ...storage.TxOption) (storage.Transaction, error) {
+	txStore, ok := s.store.(storage.Transactional)
+	if !ok {
+		return nil, storage.ErrTransactionsNotSupported
+	}
+
+	tx, err := txStore.Begin(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &compressedTransaction{Transaction: tx, provider: s.provider}, nil
+}
+
+type compressedTransaction struct {
+	storage.Transaction
+	provider *Provider
+}
+
+// Put compresses the value and stores it with the key within the transaction
+func (t *compressedTransaction) Put(k string, v []byte) error {
+	encoded, err := t.provider.encode(v)
+	if err != nil {
+		return err
+	}
+
+	return t.Transaction.Put(k, encoded)
+}
+
+// Get fetches the value of the key within the transaction and decompresses it
+func (t *compressedTransaction) Get(k string) ([]byte, error) {
+	v, err := t.Transaction.Get(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.provider.decode(v)
+}
+
+type compressedIterator struct {
+	storage.StoreIterator
+	provider *Provider
+	err      error
+}
+
+// Value returns the decompressed value of the current key-value pair, or nil when it can't be decompressed in which
+// case Error returns the reason.
+func (i *compressedIterator) Value() []byte {
+	v := i.StoreIterator.Value()
+	if v == nil {
+		return nil
+	}
+
+	decoded, err := i.provider.decode(v)
+	if err != nil {
+		i.err = err
+
+		return nil
+	}
+
+	return decoded
+}
+
+// Error returns the error of the wrapped iterator or the last decompression error.
+func (i *compressedIterator) Error() error {
+	if err := i.StoreIterator.Error(); err != nil {
+		return err
+	}
+
+	return i.err
+}
@@ -0,0 +1,257 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package compressed
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+type halvingCompressor struct {
+	id byte
+}
+
+func (c *halvingCompressor) ID() byte {
+	return c.id
+}
+
+func (c *halvingCompressor) Compress(data []byte) ([]byte, error) {
+	if bytes.HasPrefix(data, []byte("fail")) {
+		return nil, errors.New("compress error")
+	}
+
+	// a poor but recognizable compression: the first half of the data
+	return data[:len(data)/2], nil
+}
+
+func (c *halvingCompressor) Decompress(data []byte) ([]byte, error) {
+	return append(data, data...), nil
+}
+
+func TestCompressedStore(t *testing.T) {
+	memProvider := mem.NewProvider()
+
+	rawStore, err := memProvider.OpenStore("test")
+	require.NoError(t, err)
+
+	large := []byte(strings.Repeat(`{"@context":"https://www.w3.org/2018/credentials/v1"}`, 100))
+
+	for _, c := range []Compressor{nil, NewZstdCompressor()} {
+		var opts []Option
+		if c != nil {
+			opts = append(opts, WithCompressor(c))
+		}
+
+		prov, err := NewProvider(memProvider, opts...)
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		t.Run("test large values are compressed", func(t *testing.T) {
+			require.NoError(t, store.Put("abc_1", large))
+			require.NoError(t, store.PutBatch([]storage.KeyValue{
+				{Key: "abc_2", Value: []byte("small")},
+				{Key: "abc_3", Value: large},
+			}))
+
+			raw, err := rawStore.Get("abc_1")
+			require.NoError(t, err)
+			require.True(t, bytes.HasPrefix(raw, magic()))
+			require.Less(t, len(raw), len(large)/10)
+
+			raw, err = rawStore.Get("abc_2")
+			require.NoError(t, err)
+			require.Equal(t, []byte("small"), raw)
+
+			v, err := store.Get("abc_1")
+			require.NoError(t, err)
+			require.Equal(t, large, v)
+
+			values, err := store.GetBulk("abc_3", "abc_4", "abc_2")
+			require.NoError(t, err)
+			require.Equal(t, [][]byte{large, nil, []byte("small")}, values)
+
+			itr := store.Iterator("abc_", "abc_"+storage.EndKeySuffix)
+
+			var iterated [][]byte
+
+			for itr.Next() {
+				iterated = append(iterated, itr.Value())
+			}
+
+			require.NoError(t, itr.Error())
+			require.Equal(t, [][]byte{large, []byte("small"), large}, iterated)
+			itr.Release()
+
+			found, err := store.Has("abc_1")
+			require.NoError(t, err)
+			require.True(t, found)
+
+			count, err := store.Count("abc_", "abc_"+storage.EndKeySuffix)
+			require.NoError(t, err)
+			require.Equal(t, 3, count)
+
+			for _, k := range []string{"abc_1", "abc_2", "abc_3"} {
+				require.NoError(t, store.Delete(k))
+			}
+		})
+	}
+
+	prov, err := NewProvider(memProvider)
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	t.Run("test legacy uncompressed values", func(t *testing.T) {
+		require.NoError(t, rawStore.Put("legacy", large))
+
+		v, err := store.Get("legacy")
+		require.NoError(t, err)
+		require.Equal(t, large, v)
+
+		stored, err := store.PutIfMatch("legacy", large, []byte("new"))
+		require.NoError(t, err)
+		require.True(t, stored)
+
+		v, err = store.Get("legacy")
+		require.NoError(t, err)
+		require.Equal(t, []byte("new"), v)
+	})
+
+	t.Run("test uncompressed values looking like compressed values", func(t *testing.T) {
+		value := append(magic(), GzipID, 'x')
+
+		require.NoError(t, store.Put("magic", value))
+
+		v, err := store.Get("magic")
+		require.NoError(t, err)
+		require.Equal(t, value, v)
+	})
+
+	t.Run("test put if match compares decompressed values", func(t *testing.T) {
+		stored, err := store.PutIfMatch("key1", nil, large)
+		require.NoError(t, err)
+		require.True(t, stored)
+
+		stored, err = store.PutIfMatch("key1", []byte("other"), []byte("value2"))
+		require.NoError(t, err)
+		require.False(t, stored)
+
+		stored, err = store.PutIfMatch("key1", large, []byte("value2"))
+		require.NoError(t, err)
+		require.True(t, stored)
+
+		stored, err = store.PutIfMatch("key2", large, []byte("value2"))
+		require.NoError(t, err)
+		require.False(t, stored)
+
+		_, err = store.PutIfMatch("", nil, large)
+		require.Equal(t, storage.ErrKeyRequired, err)
+	})
+
+	t.Run("test values that can't be decompressed", func(t *testing.T) {
+		require.NoError(t, rawStore.Put("corrupted", append(magic(), GzipID, 'x')))
+		require.NoError(t, rawStore.Put("unknown", append(magic(), 200, 'x')))
+
+		_, err := store.Get("corrupted")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decompress value")
+
+		_, err = store.GetBulk("unknown")
+		require.EqualError(t, err, "unknown compression algorithm 200")
+
+		_, err = store.PutIfMatch("unknown", []byte("x"), []byte("y"))
+		require.EqualError(t, err, "unknown compression algorithm 200")
+
+		itr := store.Iterator("corrupted", "corrupted"+storage.EndKeySuffix)
+		require.True(t, itr.Next())
+		require.Nil(t, itr.Value())
+		require.Error(t, itr.Error())
+		itr.Release()
+	})
+
+	t.Run("test transactions", func(t *testing.T) {
+		txStore, ok := store.(storage.Transactional)
+		require.True(t, ok)
+
+		_, err := txStore.Begin()
+		require.Equal(t, storage.ErrTransactionsNotSupported, err)
+	})
+}
+
+func TestCustomCompressor(t *testing.T) {
+	memProvider := mem.NewProvider()
+
+	prov, err := NewProvider(memProvider, WithCompressor(&halvingCompressor{id: 100}), WithThreshold(4))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	rawStore, err := memProvider.OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key", []byte("abcdabcdabcdabcd")))
+
+	raw, err := rawStore.Get("key")
+	require.NoError(t, err)
+	require.Equal(t, append(magic(), append([]byte{100}, []byte("abcdabcd")...)...), raw)
+
+	v, err := store.Get("key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("abcdabcdabcdabcd"), v)
+
+	// compression doesn't make it smaller than the header
+	require.NoError(t, store.Put("key", []byte("abcd")))
+
+	raw, err = rawStore.Get("key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("abcd"), raw)
+
+	// values compressed with the built-in algorithms can still be decompressed
+	gzipProv, err := NewProvider(memProvider, WithThreshold(0))
+	require.NoError(t, err)
+
+	gzipStore, err := gzipProv.OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, gzipStore.Put("gzip", bytes.Repeat([]byte("abcd"), 100)))
+
+	v, err = store.Get("gzip")
+	require.NoError(t, err)
+	require.Equal(t, bytes.Repeat([]byte("abcd"), 100), v)
+
+	err = store.PutBatch([]storage.KeyValue{{Key: "fail", Value: []byte("failure")}})
+	require.EqualError(t, err, "failed to compress value: compress error")
+}
+
+func TestNewProvider(t *testing.T) {
+	_, err := NewProvider(nil)
+	require.EqualError(t, err, "provider is mandatory")
+
+	_, err = NewProvider(mem.NewProvider(), WithCompressor(nil))
+	require.EqualError(t, err, "compressor is mandatory")
+
+	_, err = NewProvider(mem.NewProvider(), WithCompressor(&halvingCompressor{id: 3}))
+	require.EqualError(t, err, "compressor ID 3 is reserved")
+
+	_, err = NewProvider(mem.NewProvider(), WithCompressor(&halvingCompressor{id: uncompressedID}))
+	require.EqualError(t, err, "compressor ID 0 is reserved")
+
+	_, err = NewGzipCompressor(42)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid gzip compressor")
+}
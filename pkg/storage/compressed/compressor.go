@@ -0,0 +1,135 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package compressed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// IDs of the compression algorithms provided by this package, IDs up to 15 are reserved
+const (
+	GzipID byte = 1
+	ZstdID byte = 2
+	// maxReservedID is the highest ID reserved for the algorithms of this package
+	maxReservedID byte = 15
+)
+
+// Compressor compresses the values of the stores.
+type Compressor interface {
+	// ID identifies the algorithm in the header of the values it compressed, it must not change once values have
+	// been stored. IDs up to 15 are reserved for the algorithms of this package.
+	ID() byte
+
+	// Compress compresses data
+	Compress(data []byte) ([]byte, error)
+
+	// Decompress decompresses data compressed by Compress
+	Decompress(data []byte) ([]byte, error)
+}
+
+type gzipCompressor struct {
+	level int
+}
+
+// NewGzipCompressor returns a gzip Compressor using the given compression level, ie gzip.DefaultCompression.
+func NewGzipCompressor(level int) (Compressor, error) {
+	// validates the level
+	if _, err := gzip.NewWriterLevel(ioutil.Discard, level); err != nil {
+		return nil, fmt.Errorf("invalid gzip compressor: %w", err)
+	}
+
+	return &gzipCompressor{level: level}, nil
+}
+
+func (c *gzipCompressor) ID() byte {
+	return GzipID
+}
+
+func (c *gzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w, err := gzip.NewWriterLevel(&buf, c.level)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (c *gzipCompressor) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	decompressed, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return decompressed, r.Close()
+}
+
+type zstdCompressor struct {
+	once    sync.Once
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+	err     error
+}
+
+// NewZstdCompressor returns a zstd Compressor, it compresses faster than gzip with a similar ratio.
+func NewZstdCompressor() Compressor {
+	return &zstdCompressor{}
+}
+
+// init creates the encoder and the decoder on first use since they start goroutines.
+func (c *zstdCompressor) init() error {
+	c.once.Do(func() {
+		c.encoder, c.err = zstd.NewWriter(nil)
+		if c.err != nil {
+			return
+		}
+
+		c.decoder, c.err = zstd.NewReader(nil)
+	})
+
+	return c.err
+}
+
+func (c *zstdCompressor) ID() byte {
+	return ZstdID
+}
+
+func (c *zstdCompressor) Compress(data []byte) ([]byte, error) {
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+
+	return c.encoder.EncodeAll(data, nil), nil
+}
+
+func (c *zstdCompressor) Decompress(data []byte) ([]byte, error) {
+	if err := c.init(); err != nil {
+		return nil, err
+	}
+
+	return c.decoder.DecodeAll(data, nil)
+}
@@ -8,12 +8,14 @@ SPDX-License-Identifier: Apache-2.0
 package couchdbstore
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"strconv"
 	"strings"
 	"sync"
@@ -42,10 +44,10 @@ const (
 // Option configures the couchdb provider
 type Option func(opts *Provider)
 
-// WithDBPrefix option is for adding prefix to db name
+// WithDBPrefix option is for adding prefix to db name, it is trimmed with storage.TrimDBPrefix
 func WithDBPrefix(dbPrefix string) Option {
 	return func(opts *Provider) {
-		opts.dbPrefix = dbPrefix
+		opts.dbPrefix = storage.TrimDBPrefix(dbPrefix)
 	}
 }
 
@@ -57,7 +59,7 @@ func NewProvider(hostURL string, opts ...Option) (*Provider, error) {
 
 	client, err := kivik.New("couch", hostURL)
 	if err != nil {
-		return nil, err
+		return nil, storage.RedactDSNError(err, hostURL)
 	}
 
 	p := &Provider{hostURL: hostURL, couchDBClient: client, dbs: map[string]*CouchDBStore{}}
@@ -87,14 +89,14 @@ func (p *Provider) OpenStore(name string) (storage.Store, error) {
 	err := p.couchDBClient.CreateDB(context.Background(), name)
 	if err != nil {
 		if err.Error() != "Precondition Failed: The database could not be created, the file already exists." {
-			return nil, fmt.Errorf("failed to create db: %w", err)
+			return nil, fmt.Errorf("failed to create db: %w", storage.RedactDSNError(err, p.hostURL))
 		}
 	}
 
 	db := p.couchDBClient.DB(context.Background(), name)
 
 	if db.Err() != nil {
-		return nil, db.Err()
+		return nil, storage.RedactDSNError(db.Err(), p.hostURL)
 	}
 
 	store := &CouchDBStore{db: db}
@@ -123,24 +125,30 @@ func (p *Provider) CloseStore(name string) error {
 	return store.db.Close(context.Background())
 }
 
-// Close closes the provider.
+// Close closes the provider. Every store and the client are closed even if closing another one fails, the errors are
+// wrapped in a storage.MultiError.
 func (p *Provider) Close() error {
 	p.Lock()
 	defer p.Unlock()
 
-	for _, store := range p.dbs {
-		err := store.db.Close(context.Background())
-		if err != nil {
-			return fmt.Errorf(failToCloseProviderErrMsg+": %w", err)
+	var errs storage.MultiError
+
+	for name, store := range p.dbs {
+		if err := store.db.Close(context.Background()); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close store %s: %w", name, err))
 		}
 	}
 
 	if err := p.couchDBClient.Close(context.Background()); err != nil {
-		return err
+		errs = append(errs, fmt.Errorf("failed to close client: %w", err))
 	}
 
 	p.dbs = make(map[string]*CouchDBStore)
 
+	if len(errs) > 0 {
+		return fmt.Errorf(failToCloseProviderErrMsg+": %w", errs)
+	}
+
 	return nil
 }
 
@@ -151,7 +159,11 @@ type CouchDBStore struct {
 
 // Put stores the given key-value pair in the store.
 func (c *CouchDBStore) Put(k string, v []byte) error {
-	if k == "" || v == nil {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	if v == nil {
 		return errors.New("key and value are mandatory")
 	}
 
@@ -182,6 +194,83 @@ func (c *CouchDBStore) Put(k string, v []byte) error {
 	return nil
 }
 
+// PutIfMatch stores the given key-value pair only if the value currently stored equals expected, as returned by
+// Get. The document revision read for the comparison is used for the update, so a concurrent update of the document
+// is detected by CouchDB as a conflict.
+func (c *CouchDBStore) PutIfMatch(k string, expected, newValue []byte) (bool, error) {
+	if k == "" {
+		return false, storage.ErrKeyRequired
+	}
+
+	if newValue == nil {
+		return false, errors.New("key and value are mandatory")
+	}
+
+	valueToPut := newValue
+	if !isJSON(newValue) {
+		valueToPut = wrapTextAsCouchDBAttachment(newValue)
+	}
+
+	rawDoc := make(map[string]interface{})
+
+	err := c.db.Get(context.Background(), k).ScanDoc(&rawDoc)
+	if err != nil && kivik.StatusCode(err) != http.StatusNotFound {
+		return false, err
+	}
+
+	if (err == nil) != (expected != nil) {
+		return false, nil
+	}
+
+	if expected != nil {
+		current, getErr := c.getStoredValueFromRawDoc(rawDoc, k)
+		if getErr != nil {
+			return false, getErr
+		}
+
+		if !bytes.Equal(current, expected) {
+			return false, nil
+		}
+
+		valueToPut, err = c.addRevID(valueToPut, rawDoc["_rev"].(string))
+		if err != nil {
+			return false, err
+		}
+	}
+
+	_, err = c.db.Put(context.Background(), k, valueToPut)
+	if err != nil {
+		if kivik.StatusCode(err) == http.StatusConflict {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to store data: %w", err)
+	}
+
+	return true, nil
+}
+
+// PutBatch stores all the given key-value pairs in the store.
+func (c *CouchDBStore) PutBatch(kvs []storage.KeyValue) error {
+	for _, kv := range kvs {
+		if kv.Key == "" {
+			return storage.ErrKeyRequired
+		}
+
+		if kv.Value == nil {
+			return errors.New("key and value are mandatory")
+		}
+	}
+
+	for _, kv := range kvs {
+		if err := c.Put(kv.Key, kv.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func isJSON(textToCheck []byte) bool {
 	var js map[string]interface{}
 	return json.Unmarshal(textToCheck, &js) == nil
@@ -197,7 +286,7 @@ func wrapTextAsCouchDBAttachment(textToWrap []byte) []byte {
 // Get retrieves the value in the store associated with the given key.
 func (c *CouchDBStore) Get(k string) ([]byte, error) {
 	if k == "" {
-		return nil, errors.New("key is mandatory")
+		return nil, storage.ErrKeyRequired
 	}
 
 	rawDoc := make(map[string]interface{})
@@ -216,6 +305,29 @@ func (c *CouchDBStore) Get(k string) ([]byte, error) {
 	return c.getStoredValueFromRawDoc(rawDoc, k)
 }
 
+// GetBulk fetches the records of the given keys, with a nil entry for every key not found
+func (c *CouchDBStore) GetBulk(keys ...string) ([][]byte, error) {
+	return storage.GetBulk(c.Get, keys...)
+}
+
+// Has checks whether a document with key k exists, fetching only its metadata.
+func (c *CouchDBStore) Has(k string) (bool, error) {
+	if k == "" {
+		return false, storage.ErrKeyRequired
+	}
+
+	_, _, err := c.db.GetMeta(context.Background(), k)
+	if err != nil {
+		if kivik.StatusCode(err) == http.StatusNotFound {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
 func (c *CouchDBStore) addRevID(valueToPut []byte, revID string) ([]byte, error) {
 	var m map[string]interface{}
 	if err := json.Unmarshal(valueToPut, &m); err != nil {
@@ -253,7 +365,7 @@ func (c *CouchDBStore) getRevID(k string) (string, error) {
 // Delete will delete record with k key
 func (c *CouchDBStore) Delete(k string) error {
 	if k == "" {
-		return errors.New("key is mandatory")
+		return storage.ErrKeyRequired
 	}
 
 	revID, err := c.getRevID(k)
@@ -270,29 +382,174 @@ func (c *CouchDBStore) Delete(k string) error {
 }
 
 // Iterator returns iterator for the latest snapshot of the underlying db.
-func (c *CouchDBStore) Iterator(startKey, endKey string) storage.StoreIterator {
-	resultRows, err := c.db.AllDocs(context.TODO(), kivik.Options{
-		"startkey":      startKey,
-		"endkey":        strings.ReplaceAll(endKey, storage.EndKeySuffix, kivik.EndKeySuffix),
-		"inclusive_end": "false", // endkey should be exclusive to be consistent with goleveldb
-		"include_docs":  "true",
-	})
+func (c *CouchDBStore) Iterator(startKey, endKey string, opts ...storage.IteratorOption) storage.StoreIterator {
+	options := storage.GetIteratorOptions(opts...)
+
+	itr := &couchDBResultsIterator{
+		store:     c,
+		startKey:  startKey,
+		endKey:    strings.ReplaceAll(endKey, storage.EndKeySuffix, kivik.EndKeySuffix),
+		reverse:   options.Reverse,
+		limited:   options.Limit > 0,
+		remaining: options.Limit,
+	}
+
+	itr.query(nil)
+
+	return itr
+}
+
+// Count returns the number of records within the [startKey, endKey) range, the whole db being counted when both keys
+// are empty.
+func (c *CouchDBStore) Count(startKey, endKey string) (int, error) {
+	queryOpts := kivik.Options{}
+
+	if startKey != "" || endKey != "" {
+		queryOpts = kivik.Options{
+			"startkey":      startKey,
+			"endkey":        strings.ReplaceAll(endKey, storage.EndKeySuffix, kivik.EndKeySuffix),
+			"inclusive_end": "false",
+		}
+	}
+
+	resultRows, err := c.db.AllDocs(context.TODO(), queryOpts)
 	if err != nil {
-		return &couchDBResultsIterator{store: c, resultRows: &kivik.Rows{},
-			err: fmt.Errorf("failed to query docs: %w", err)}
+		return 0, fmt.Errorf("failed to query docs: %w", err)
+	}
+
+	count := 0
+
+	// the rows are closed once they're exhausted
+	for resultRows.Next() {
+		count++
 	}
 
-	return &couchDBResultsIterator{store: c, resultRows: resultRows}
+	if err = resultRows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to count docs: %w", err)
+	}
+
+	return count, nil
+}
+
+// ForEach calls fn with every key/value pair within the key range until fn returns stop or an error
+func (c *CouchDBStore) ForEach(startKey, endKey string, fn func(key, value []byte) (bool, error)) error {
+	return storage.ForEach(c.Iterator(startKey, endKey), fn)
 }
 
 type couchDBResultsIterator struct {
 	store      *CouchDBStore
 	resultRows *kivik.Rows
-	err        error
+	skipKey    string
+	// the range and the options of the iterator are kept to query the docs again when seeking a key
+	startKey string
+	endKey   string
+	reverse  bool
+	limited  bool
+	// remaining is the number of rows left before reaching the limit of the iterator, zero when unlimited
+	remaining int
+	done      bool
+	err       error
+}
+
+// query queries the docs of the range, only the docs at or after seekKey in the iteration order when it's given.
+func (i *couchDBResultsIterator) query(seekKey *string) {
+	startKey, endKey := i.startKey, i.endKey
+
+	if seekKey != nil && !i.reverse && *seekKey > startKey {
+		startKey = *seekKey
+	}
+
+	queryOpts := kivik.Options{
+		"startkey":      startKey,
+		"endkey":        endKey,
+		"inclusive_end": "false", // endkey should be exclusive to be consistent with goleveldb
+		"include_docs":  "true",
+	}
+
+	i.skipKey = ""
+
+	if i.reverse {
+		// a descending query walks from startkey down to endkey, so the bounds are swapped. The startkey bound is
+		// always inclusive: the end of the range is skipped by the iterator instead.
+		i.skipKey = endKey
+
+		// the seeked key is part of the range
+		if seekKey != nil && *seekKey < endKey {
+			endKey = *seekKey
+			i.skipKey = ""
+		}
+
+		queryOpts["descending"] = "true"
+		queryOpts["startkey"] = endKey
+		queryOpts["endkey"] = startKey
+		queryOpts["inclusive_end"] = "true"
+	}
+
+	if i.limited {
+		limit := i.remaining
+
+		// one more row may be needed when the end of the range is skipped
+		if i.skipKey != "" {
+			limit++
+		}
+
+		queryOpts["limit"] = limit
+	}
+
+	resultRows, err := i.store.db.AllDocs(context.TODO(), queryOpts)
+	if err != nil {
+		i.resultRows = &kivik.Rows{}
+		i.err = fmt.Errorf("failed to query docs: %w", err)
+
+		return
+	}
+
+	i.resultRows = resultRows
 }
 
 func (i *couchDBResultsIterator) Next() bool {
-	return i.resultRows.Next()
+	if i.done || !i.resultRows.Next() {
+		return false
+	}
+
+	if i.skipKey != "" && string(i.Key()) == i.skipKey && !i.resultRows.Next() {
+		return false
+	}
+
+	if i.remaining > 0 {
+		i.remaining--
+		i.done = i.remaining == 0
+	}
+
+	return true
+}
+
+// Seek moves the iterator to the first key-value pair of the range at or after key in the iteration order, the docs
+// being queried again from key.
+func (i *couchDBResultsIterator) Seek(key string) bool {
+	if i.done || i.err != nil {
+		return false
+	}
+
+	i.Release()
+
+	// CouchDB rejects inverted ranges
+	if (!i.reverse && key >= i.endKey) || (i.reverse && key < i.startKey) {
+		i.done = true
+
+		return false
+	}
+
+	i.query(&key)
+
+	return i.err == nil && i.Next()
+}
+
+// TotalCount is not supported since CouchDB only reports the total number of docs of the db, counting the docs of
+// the range requires to fetch all their IDs. It returns -1 and storage.ErrTotalCountNotSupported, Count fetches the IDs
+// to count the records of a range.
+func (i *couchDBResultsIterator) TotalCount() (int, error) {
+	return -1, storage.ErrTotalCountNotSupported
 }
 
 func (i *couchDBResultsIterator) Release() {
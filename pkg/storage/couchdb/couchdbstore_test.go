@@ -10,6 +10,7 @@ package couchdbstore
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -281,9 +282,110 @@ func TestCouchDBStore(t *testing.T) {
 
 		itr = store.Iterator("abc_", "mno_123")
 		verifyItr(t, itr, 5, "")
+
+		itr = store.Iterator("abc_", "abc_"+storage.EndKeySuffix, storage.WithReverse())
+		verifyItrKeys(t, itr, "abc_126", "abc_125", "abc_124", "abc_123")
+
+		// the end key remains excluded from the range when iterating in reverse
+		itr = store.Iterator("abc_", "mno_123", storage.WithReverse())
+		verifyItrKeys(t, itr, "jkl_123", "abc_126", "abc_125", "abc_124", "abc_123")
+
+		itr = store.Iterator("abc_", "abc_"+storage.EndKeySuffix, storage.WithLimit(2))
+		verifyItrKeys(t, itr, "abc_123", "abc_124")
+
+		itr = store.Iterator("abc_", "mno_123", storage.WithReverse(), storage.WithLimit(2))
+		verifyItrKeys(t, itr, "jkl_123", "abc_126")
+
+		// a zero limit means unlimited
+		itr = store.Iterator("abc_", "abc_"+storage.EndKeySuffix, storage.WithLimit(0))
+		verifyItr(t, itr, 4, "abc_")
+
+		itr = store.Iterator("abc_", "mno_123")
+		require.True(t, itr.Seek("abc_1255"))
+		require.Equal(t, "abc_126", string(itr.Key()))
+		verifyItrKeys(t, itr, "jkl_123")
+
+		itr = store.Iterator("abc_", "mno_123")
+		require.False(t, itr.Seek("mno_123"))
+		itr.Release()
+
+		itr = store.Iterator("abc_", "mno_123", storage.WithReverse())
+		require.True(t, itr.Seek("abc_1255"))
+		require.Equal(t, "abc_125", string(itr.Key()))
+		verifyItrKeys(t, itr, "abc_124", "abc_123")
+
+		itr = store.Iterator("abc_", "mno_123", storage.WithLimit(2))
+		require.True(t, itr.Seek("abc_125"))
+		verifyItrKeys(t, itr, "abc_126")
+
+		total, err := store.Iterator("abc_", "mno_123").TotalCount()
+		require.True(t, errors.Is(err, storage.ErrTotalCountNotSupported))
+		require.Equal(t, -1, total)
+
+		count, err := store.Count("abc_", "abc_"+storage.EndKeySuffix)
+		require.NoError(t, err)
+		require.Equal(t, 4, count)
+
+		count, err = store.Count("abc_", "mno_123")
+		require.NoError(t, err)
+		require.Equal(t, 5, count)
+
+		count, err = store.Count("", "")
+		require.NoError(t, err)
+		require.Equal(t, len(keys), count)
 	})
 }
 
+func TestCouchDBStorePutIfMatch(t *testing.T) {
+	prov, err := NewProvider(couchDBURL)
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("test-cas")
+	require.NoError(t, err)
+
+	// a fresh key for every run since the records persist in the DB
+	key := fmt.Sprintf("cas-%d", time.Now().UnixNano())
+
+	ok, err := store.PutIfMatch(key, nil, []byte("value1"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// create-only fails once the key exists
+	ok, err = store.PutIfMatch(key, nil, []byte("value2"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = store.PutIfMatch(key, []byte("other"), []byte("value2"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	v, err := store.Get(key)
+	require.NoError(t, err)
+	require.Equal(t, []byte("value1"), v)
+
+	ok, err = store.PutIfMatch(key, []byte("value1"), []byte("value2"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	v, err = store.Get(key)
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), v)
+
+	// unchanged value
+	ok, err = store.PutIfMatch(key, []byte("value2"), []byte("value2"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = store.PutIfMatch(key+"-missing", []byte("value1"), []byte("value2"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = store.PutIfMatch("", nil, []byte("value1"))
+	require.Error(t, err)
+
+	require.NoError(t, prov.Close())
+}
+
 func verifyItr(t *testing.T, itr storage.StoreIterator, count int, prefix string) {
 	var vals []string
 
@@ -342,3 +444,15 @@ func TestCouchDBStoreDelete(t *testing.T) {
 	require.EqualError(t, err, storage.ErrDataNotFound.Error())
 	require.Empty(t, doc)
 }
+
+func verifyItrKeys(t *testing.T, itr storage.StoreIterator, keys ...string) {
+	var actual []string
+
+	for itr.Next() {
+		actual = append(actual, string(itr.Key()))
+	}
+
+	require.Equal(t, keys, actual)
+
+	itr.Release()
+}
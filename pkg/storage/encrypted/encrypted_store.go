@@ -0,0 +1,277 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package encrypted provides a storage.Provider encrypting the values of the stores of another provider.
+//
+// Values are encrypted with a Tink AEAD primitive, ie obtained from a keyset handle with aead.New or backed by a KMS,
+// using their key as associated data so that a value can't be moved to another key. Keys are stored in plaintext:
+// iterators and counts still operate on key ranges and prefixes, which means that keys must not hold sensitive data.
+package encrypted
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/google/tink/go/tink"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// Provider is a storage.Provider encrypting the values of the stores of the provider it wraps.
+type Provider struct {
+	storage.Provider
+	aead tink.AEAD
+}
+
+// NewProvider instantiates a Provider encrypting the values of the stores of p with aead.
+func NewProvider(p storage.Provider, aead tink.AEAD) (*Provider, error) {
+	if p == nil || aead == nil {
+		return nil, errors.New("provider and AEAD primitive are mandatory")
+	}
+
+	return &Provider{Provider: p, aead: aead}, nil
+}
+
+// OpenStore opens the store of the wrapped provider with the given name, encrypting its values.
+func (p *Provider) OpenStore(name string) (storage.Store, error) {
+	store, err := p.Provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptedStore{store: store, aead: p.aead}, nil
+}
+
+type encryptedStore struct {
+	store storage.Store
+	aead  tink.AEAD
+}
+
+func (s *encryptedStore) encrypt(k string, v []byte) ([]byte, error) {
+	encrypted, err := s.aead.Encrypt(v, []byte(k))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt value of key %s: %w", k, err)
+	}
+
+	return encrypted, nil
+}
+
+func (s *encryptedStore) decrypt(k string, v []byte) ([]byte, error) {
+	decrypted, err := s.aead.Decrypt(v, []byte(k))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value of key %s: %w", k, err)
+	}
+
+	return decrypted, nil
+}
+
+// Put encrypts the value and stores it with the key
+func (s *encryptedStore) Put(k string, v []byte) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	encrypted, err := s.encrypt(k, v)
+	if err != nil {
+		return err
+	}
+
+	return s.store.Put(k, encrypted)
+}
+
+// PutBatch encrypts the values of all the given key/value pairs and stores them
+func (s *encryptedStore) PutBatch(kvs []storage.KeyValue) error {
+	encryptedKVs := make([]storage.KeyValue, len(kvs))
+
+	for i, kv := range kvs {
+		if kv.Key == "" {
+			return storage.ErrKeyRequired
+		}
+
+		encrypted, err := s.encrypt(kv.Key, kv.Value)
+		if err != nil {
+			return err
+		}
+
+		encryptedKVs[i] = storage.KeyValue{Key: kv.Key, Value: encrypted}
+	}
+
+	return s.store.PutBatch(encryptedKVs)
+}
+
+// PutIfMatch stores the encrypted value only if the current decrypted value equals expected. Encryption isn't
+// deterministic so the current encrypted value is the one matched by the wrapped store.
+func (s *encryptedStore) PutIfMatch(k string, expected, newValue []byte) (bool, error) {
+	if k == "" {
+		return false, storage.ErrKeyRequired
+	}
+
+	var current []byte
+
+	if expected != nil {
+		var err error
+
+		current, err = s.store.Get(k)
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return false, nil
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		decrypted, err := s.decrypt(k, current)
+		if err != nil {
+			return false, err
+		}
+
+		if !bytes.Equal(decrypted, expected) {
+			return false, nil
+		}
+	}
+
+	encrypted, err := s.encrypt(k, newValue)
+	if err != nil {
+		return false, err
+	}
+
+	return s.store.PutIfMatch(k, current, encrypted)
+}
+
+// Get fetches the value of the key and decrypts it
+func (s *encryptedStore) Get(k string) ([]byte, error) {
+	encrypted, err := s.store.Get(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.decrypt(k, encrypted)
+}
+
+// GetBulk fetches the values of the given keys and decrypts them, with a nil entry for every key not found
+func (s *encryptedStore) GetBulk(keys ...string) ([][]byte, error) {
+	values, err := s.store.GetBulk(keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+
+		values[i], err = s.decrypt(keys[i], v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// Has checks whether a record with key k exists
+func (s *encryptedStore) Has(k string) (bool, error) {
+	return s.store.Has(k)
+}
+
+// Iterator returns an iterator over the plaintext keys of the range, decrypting their values.
+func (s *encryptedStore) Iterator(startKey, endKey string, opts ...storage.IteratorOption) storage.StoreIterator {
+	return &encryptedIterator{StoreIterator: s.store.Iterator(startKey, endKey, opts...), store: s}
+}
+
+// Count returns the number of records within the plaintext key range
+func (s *encryptedStore) Count(startKey, endKey string) (int, error) {
+	return s.store.Count(startKey, endKey)
+}
+
+// ForEach calls fn with every plaintext key and decrypted value within the key range
+func (s *encryptedStore) ForEach(startKey, endKey string, fn func(key, value []byte) (bool, error)) error {
+	return storage.ForEach(s.Iterator(startKey, endKey), fn)
+}
+
+// Delete will delete record with k key
+func (s *encryptedStore) Delete(k string) error {
+	return s.store.Delete(k)
+}
+
+// Begin starts a new transaction encrypting the values it stores, stores wrapping a store that can't execute
+// atomic transactions return storage.ErrTransactionsNotSupported.
+func (s *encryptedStore) Begin(opts ...storage.TxOption) (storage.Transaction, error) {
+	txStore, ok := s.store.(storage.Transactional)
+	if !ok {
+		return nil, storage.ErrTransactionsNotSupported
+	}
+
+	tx, err := txStore.Begin(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encryptedTransaction{Transaction: tx, store: s}, nil
+}
+
+type encryptedTransaction struct {
+	storage.Transaction
+	store *encryptedStore
+}
+
+// Put encrypts the value and stores it with the key within the transaction
+func (t *encryptedTransaction) Put(k string, v []byte) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	encrypted, err := t.store.encrypt(k, v)
+	if err != nil {
+		return err
+	}
+
+	return t.Transaction.Put(k, encrypted)
+}
+
+// Get fetches the value of the key within the transaction and decrypts it
+func (t *encryptedTransaction) Get(k string) ([]byte, error) {
+	encrypted, err := t.Transaction.Get(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.store.decrypt(k, encrypted)
+}
+
+type encryptedIterator struct {
+	storage.StoreIterator
+	store *encryptedStore
+	err   error
+}
+
+// Value returns the decrypted value of the current key-value pair, or nil when it can't be decrypted in which case
+// Error returns the reason.
+func (i *encryptedIterator) Value() []byte {
+	encrypted := i.StoreIterator.Value()
+	if encrypted == nil {
+		return nil
+	}
+
+	decrypted, err := i.store.decrypt(string(i.StoreIterator.Key()), encrypted)
+	if err != nil {
+		i.err = err
+
+		return nil
+	}
+
+	return decrypted
+}
+
+// Error returns the error of the wrapped iterator or the last decryption error.
+func (i *encryptedIterator) Error() error {
+	if err := i.StoreIterator.Error(); err != nil {
+		return err
+	}
+
+	return i.err
+}
@@ -0,0 +1,162 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package encrypted
+
+import (
+	"testing"
+
+	"github.com/google/tink/go/aead"
+	"github.com/google/tink/go/keyset"
+	"github.com/google/tink/go/tink"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func newAEAD(t *testing.T) tink.AEAD {
+	kh, err := keyset.NewHandle(aead.AES256GCMKeyTemplate())
+	require.NoError(t, err)
+
+	a, err := aead.New(kh)
+	require.NoError(t, err)
+
+	return a
+}
+
+func TestEncryptedStore(t *testing.T) {
+	memProvider := mem.NewProvider()
+
+	prov, err := NewProvider(memProvider, newAEAD(t))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	rawStore, err := memProvider.OpenStore("test")
+	require.NoError(t, err)
+
+	t.Run("test values are encrypted", func(t *testing.T) {
+		require.NoError(t, store.Put("abc_1", []byte("value1")))
+		require.NoError(t, store.PutBatch([]storage.KeyValue{
+			{Key: "abc_2", Value: []byte("value2")},
+			{Key: "abc_3", Value: []byte("value3")},
+		}))
+
+		raw, err := rawStore.Get("abc_1")
+		require.NoError(t, err)
+		require.NotContains(t, string(raw), "value1")
+
+		v, err := store.Get("abc_1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value1"), v)
+
+		values, err := store.GetBulk("abc_2", "abc_4", "abc_3")
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("value2"), nil, []byte("value3")}, values)
+
+		found, err := store.Has("abc_2")
+		require.NoError(t, err)
+		require.True(t, found)
+
+		count, err := store.Count("abc_", "abc_"+storage.EndKeySuffix)
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+
+		itr := store.Iterator("abc_", "abc_"+storage.EndKeySuffix)
+
+		var iterated []string
+
+		for itr.Next() {
+			iterated = append(iterated, string(itr.Key())+"="+string(itr.Value()))
+		}
+
+		require.NoError(t, itr.Error())
+		require.Equal(t, []string{"abc_1=value1", "abc_2=value2", "abc_3=value3"}, iterated)
+		itr.Release()
+
+		require.NoError(t, store.Delete("abc_3"))
+
+		_, err = store.Get("abc_3")
+		require.Equal(t, storage.ErrDataNotFound, err)
+	})
+
+	t.Run("test put if match compares decrypted values", func(t *testing.T) {
+		stored, err := store.PutIfMatch("key1", nil, []byte("value1"))
+		require.NoError(t, err)
+		require.True(t, stored)
+
+		stored, err = store.PutIfMatch("key1", nil, []byte("value2"))
+		require.NoError(t, err)
+		require.False(t, stored)
+
+		stored, err = store.PutIfMatch("key1", []byte("other"), []byte("value2"))
+		require.NoError(t, err)
+		require.False(t, stored)
+
+		stored, err = store.PutIfMatch("key1", []byte("value1"), []byte("value2"))
+		require.NoError(t, err)
+		require.True(t, stored)
+
+		v, err := store.Get("key1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value2"), v)
+
+		stored, err = store.PutIfMatch("key2", []byte("value1"), []byte("value2"))
+		require.NoError(t, err)
+		require.False(t, stored)
+	})
+
+	t.Run("test values moved to another key can't be decrypted", func(t *testing.T) {
+		raw, err := rawStore.Get("abc_1")
+		require.NoError(t, err)
+
+		require.NoError(t, rawStore.Put("abc_5", raw))
+
+		_, err = store.Get("abc_5")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decrypt value of key abc_5")
+
+		_, err = store.GetBulk("abc_5")
+		require.Error(t, err)
+
+		itr := store.Iterator("abc_5", "abc_"+storage.EndKeySuffix)
+		require.True(t, itr.Next())
+		require.Nil(t, itr.Value())
+		require.Error(t, itr.Error())
+		itr.Release()
+
+		_, err = store.PutIfMatch("abc_5", raw, []byte("value"))
+		require.Error(t, err)
+
+		require.NoError(t, rawStore.Delete("abc_5"))
+	})
+
+	t.Run("test transactions", func(t *testing.T) {
+		txStore, ok := store.(storage.Transactional)
+		require.True(t, ok)
+
+		_, err := txStore.Begin()
+		require.Equal(t, storage.ErrTransactionsNotSupported, err)
+	})
+
+	t.Run("test key required", func(t *testing.T) {
+		require.Equal(t, storage.ErrKeyRequired, store.Put("", []byte("value")))
+		require.Equal(t, storage.ErrKeyRequired, store.PutBatch([]storage.KeyValue{{Value: []byte("value")}}))
+
+		_, err := store.PutIfMatch("", nil, []byte("value"))
+		require.Equal(t, storage.ErrKeyRequired, err)
+	})
+}
+
+func TestNewProvider(t *testing.T) {
+	_, err := NewProvider(nil, newAEAD(t))
+	require.EqualError(t, err, "provider and AEAD primitive are mandatory")
+
+	_, err = NewProvider(mem.NewProvider(), nil)
+	require.EqualError(t, err, "provider and AEAD primitive are mandatory")
+}
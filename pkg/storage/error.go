@@ -0,0 +1,27 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage
+
+// Error is a failure of the backend of a store, carrying the error code of the backend (ie the MySQL error number) so
+// that callers can tell failures apart, ie to retry deadlocks but not constraint violations. Callers extract it with
+// errors.As, the stores wrapping it with the message prefixes of their operations.
+type Error struct {
+	// Code is the error code of the backend, zero when the failure has none (ie a lost connection)
+	Code int
+	// Cause is the error returned by the driver of the backend
+	Cause error
+}
+
+// Error returns the message of the cause.
+func (e *Error) Error() string {
+	return e.Cause.Error()
+}
+
+// Unwrap returns the cause, errors.Is and errors.As matching it too.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
@@ -0,0 +1,29 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestError(t *testing.T) {
+	err := fmt.Errorf("failed to get row %w", &storage.Error{Code: 1213, Cause: context.Canceled})
+	require.EqualError(t, err, "failed to get row context canceled")
+	require.True(t, errors.Is(err, context.Canceled))
+
+	var storageErr *storage.Error
+
+	require.True(t, errors.As(err, &storageErr))
+	require.Equal(t, 1213, storageErr.Code)
+}
@@ -0,0 +1,197 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// An export starts with exportMagic followed by the key/value pairs, each pair being a 4 bytes big endian key length,
+// the key, a 4 bytes big endian value length and the value.
+const (
+	exportMagic        = "ARIESKV1"
+	exportLenPrefixLen = 4
+	// importBatchSize is the number of pairs stored by every PutBatch of the stores importing without a transaction
+	importBatchSize = 1000
+)
+
+// ErrInvalidExport is returned by Import when the stream isn't an export or is truncated
+var ErrInvalidExport = errors.New("invalid store export")
+
+// Export writes the key/value pairs of the store within the key range, with the same range semantics as Iterator, to
+// w in ascending key order. The export is read back by Import, ie to back up a store or to copy it to a store of
+// another provider. The pairs are read with an iterator, they aren't a point-in-time snapshot on stores written
+// while they are exported.
+func Export(store Store, startKey, endKey string, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	if _, err := bw.WriteString(exportMagic); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	err := store.ForEach(startKey, endKey, func(key, value []byte) (bool, error) {
+		if err := writeExportField(bw, key); err != nil {
+			return true, err
+		}
+
+		return false, writeExportField(bw, value)
+	})
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	if err = bw.Flush(); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	return nil
+}
+
+func writeExportField(w io.Writer, field []byte) error {
+	lenPrefix := make([]byte, exportLenPrefixLen)
+	binary.BigEndian.PutUint32(lenPrefix, uint32(len(field)))
+
+	if _, err := w.Write(lenPrefix); err != nil {
+		return err
+	}
+
+	_, err := w.Write(field)
+
+	return err
+}
+
+// Import stores the key/value pairs of the export written by Export read from r, existing keys are overwritten.
+// Stores implementing Transactional import all the pairs within a single transaction, nothing is stored when the
+// import fails. Other stores import the pairs by batches with PutBatch, the batches stored before a failure are kept.
+// The errors of streams that aren't exports or are truncated wrap ErrInvalidExport.
+func Import(store Store, r io.Reader) error {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(exportMagic))
+
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != exportMagic {
+		return fmt.Errorf("import: %w: missing export header", ErrInvalidExport)
+	}
+
+	txStore, ok := store.(Transactional)
+	if ok {
+		tx, err := txStore.Begin()
+		if err == nil {
+			return importInTransaction(tx, br)
+		}
+
+		if !errors.Is(err, ErrTransactionsNotSupported) {
+			return fmt.Errorf("import: %w", err)
+		}
+	}
+
+	return importByBatches(store, br)
+}
+
+func importInTransaction(tx Transaction, r io.Reader) error {
+	err := readExportedPairs(r, func(kv KeyValue) error {
+		return tx.Put(kv.Key, kv.Value)
+	})
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("import: %s: failed to rollback: %w", err.Error(), rbErr)
+		}
+
+		return fmt.Errorf("import: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	return nil
+}
+
+func importByBatches(store Store, r io.Reader) error {
+	batch := make([]KeyValue, 0, importBatchSize)
+
+	err := readExportedPairs(r, func(kv KeyValue) error {
+		batch = append(batch, kv)
+
+		if len(batch) < importBatchSize {
+			return nil
+		}
+
+		err := store.PutBatch(batch)
+		batch = batch[:0]
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	if len(batch) > 0 {
+		if err = store.PutBatch(batch); err != nil {
+			return fmt.Errorf("import: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// readExportedPairs calls fn with every key/value pair read from r until the end of the export.
+func readExportedPairs(r io.Reader, fn func(kv KeyValue) error) error {
+	for {
+		key, err := readExportField(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		value, err := readExportField(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return fmt.Errorf("%w: missing value of the last key", ErrInvalidExport)
+			}
+
+			return err
+		}
+
+		if err = fn(KeyValue{Key: string(key), Value: value}); err != nil {
+			return err
+		}
+	}
+}
+
+// readExportField reads a length prefixed field from r. It returns io.EOF when r is at its end, the field is read as
+// it arrives so that the memory allocated for a corrupted length is bounded by the size of the stream.
+func readExportField(r io.Reader) ([]byte, error) {
+	lenPrefix := make([]byte, exportLenPrefixLen)
+
+	n, err := io.ReadFull(r, lenPrefix)
+	if err != nil {
+		if n == 0 && errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+
+		return nil, fmt.Errorf("%w: %s", ErrInvalidExport, err.Error())
+	}
+
+	fieldLen := int64(binary.BigEndian.Uint32(lenPrefix))
+
+	field := new(bytes.Buffer)
+
+	if _, err = io.CopyN(field, r, fieldLen); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidExport, err.Error())
+	}
+
+	return field.Bytes(), nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+// txStore is a mem store whose transactions buffer their puts until they are committed.
+type txStore struct {
+	storage.Store
+	failCommit bool
+	rolledBack bool
+}
+
+func (s *txStore) Begin(...storage.TxOption) (storage.Transaction, error) {
+	return &bufferedTransaction{store: s}, nil
+}
+
+type bufferedTransaction struct {
+	storage.Transaction
+	store *txStore
+	puts  []storage.KeyValue
+}
+
+func (tx *bufferedTransaction) Put(k string, v []byte) error {
+	tx.puts = append(tx.puts, storage.KeyValue{Key: k, Value: v})
+
+	return nil
+}
+
+func (tx *bufferedTransaction) Commit() error {
+	if tx.store.failCommit {
+		return errors.New("commit error")
+	}
+
+	return tx.store.PutBatch(tx.puts)
+}
+
+func (tx *bufferedTransaction) Rollback() error {
+	tx.store.rolledBack = true
+
+	return nil
+}
+
+func TestExportImport(t *testing.T) {
+	prov := mem.NewProvider()
+
+	src, err := prov.OpenStore("src")
+	require.NoError(t, err)
+
+	// more pairs than a single import batch, with an empty value
+	const pairs = 1500
+
+	for i := 0; i < pairs; i++ {
+		require.NoError(t, src.Put(fmt.Sprintf("key_%04d", i), []byte(fmt.Sprintf("value-%d", i))))
+	}
+
+	require.NoError(t, src.Put("key_empty", []byte{}))
+	require.NoError(t, src.Put("other_key", []byte("other")))
+
+	export := new(bytes.Buffer)
+	require.NoError(t, storage.Export(src, "key_", "key_"+storage.EndKeySuffix, export))
+
+	t.Run("import by batches", func(t *testing.T) {
+		dst, err := prov.OpenStore("dst-batches")
+		require.NoError(t, err)
+
+		require.NoError(t, dst.Put("key_0000", []byte("overwritten")))
+
+		require.NoError(t, storage.Import(dst, bytes.NewReader(export.Bytes())))
+
+		count, err := dst.Count("", "")
+		require.NoError(t, err)
+		require.Equal(t, pairs+1, count)
+
+		v, err := dst.Get("key_0000")
+		require.NoError(t, err)
+		require.Equal(t, "value-0", string(v))
+
+		v, err = dst.Get("key_empty")
+		require.NoError(t, err)
+		require.Empty(t, v)
+
+		_, err = dst.Get("other_key")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("import in a transaction", func(t *testing.T) {
+		dst, err := prov.OpenStore("dst-tx")
+		require.NoError(t, err)
+
+		require.NoError(t, storage.Import(&txStore{Store: dst}, bytes.NewReader(export.Bytes())))
+
+		count, err := dst.Count("", "")
+		require.NoError(t, err)
+		require.Equal(t, pairs+1, count)
+
+		// a truncated export is rolled back
+		dst, err = prov.OpenStore("dst-tx-truncated")
+		require.NoError(t, err)
+
+		txDst := &txStore{Store: dst}
+
+		err = storage.Import(txDst, bytes.NewReader(export.Bytes()[:export.Len()-1]))
+		require.True(t, errors.Is(err, storage.ErrInvalidExport))
+		require.True(t, txDst.rolledBack)
+
+		count, err = dst.Count("", "")
+		require.NoError(t, err)
+		require.Zero(t, count)
+
+		err = storage.Import(&txStore{Store: dst, failCommit: true}, bytes.NewReader(export.Bytes()))
+		require.EqualError(t, err, "import: commit error")
+	})
+
+	t.Run("invalid exports", func(t *testing.T) {
+		dst, err := prov.OpenStore("dst-invalid")
+		require.NoError(t, err)
+
+		err = storage.Import(dst, bytes.NewReader([]byte("not an export")))
+		require.True(t, errors.Is(err, storage.ErrInvalidExport))
+
+		err = storage.Import(dst, bytes.NewReader(nil))
+		require.True(t, errors.Is(err, storage.ErrInvalidExport))
+
+		// a key without its value
+		empty := new(bytes.Buffer)
+		require.NoError(t, storage.Export(dst, "", "", empty))
+
+		err = storage.Import(dst, bytes.NewReader(append(empty.Bytes(), 0, 0, 0, 1, 'k')))
+		require.True(t, errors.Is(err, storage.ErrInvalidExport))
+		require.Contains(t, err.Error(), "missing value")
+
+		// a length longer than the stream
+		err = storage.Import(dst, bytes.NewReader(append(empty.Bytes(), 0xff, 0xff, 0xff, 0xff, 'k')))
+		require.True(t, errors.Is(err, storage.ErrInvalidExport))
+
+		count, err := dst.Count("", "")
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+}
@@ -0,0 +1,266 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// Names of the store operations reported to observers
+const (
+	OpPut        = "put"
+	OpPutBatch   = "putBatch"
+	OpPutIfMatch = "putIfMatch"
+	OpPutExpiry  = "putWithExpiry"
+	OpGet        = "get"
+	OpGetBulk    = "getBulk"
+	OpHas        = "has"
+	OpIterator   = "iterator"
+	OpCount      = "count"
+	OpForEach    = "forEach"
+	OpDelete     = "delete"
+)
+
+// Observer is notified of the operations executed by the stores of an instrumented provider, ie to export them as
+// metrics. It's called synchronously so it must not block.
+type Observer interface {
+	// OnOperation reports the operation op executed by the store with the given name, how long it took and the
+	// error it returned if any. Iterator operations only measure the creation of the iterator.
+	OnOperation(op, store string, dur time.Duration, err error)
+}
+
+// NewInstrumentedProvider returns a provider reporting every operation of the stores it opens to the observer, the
+// stores being opened by p. The provider p is returned as is when obs is nil.
+// The instrumented stores are Transactional, returning ErrTransactionsNotSupported when the stores of p aren't, and
+// ContextStore, falling back to the operations without context. They are ExpiringStore only when the stores of p are,
+// and the provider is a Pinger only when p is.
+func NewInstrumentedProvider(p Provider, obs Observer) Provider {
+	if obs == nil {
+		return p
+	}
+
+	provider := &instrumentedProvider{Provider: p, obs: obs}
+
+	if pinger, ok := p.(Pinger); ok {
+		return &instrumentedPinger{instrumentedProvider: provider, pinger: pinger}
+	}
+
+	return provider
+}
+
+type instrumentedProvider struct {
+	Provider
+	obs Observer
+}
+
+// OpenStore opens the store of p and instruments it.
+func (p *instrumentedProvider) OpenStore(name string) (Store, error) {
+	s, err := p.Provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &instrumentedStore{Store: s, name: name, obs: p.obs}
+
+	if expiringStore, ok := s.(ExpiringStore); ok {
+		return &instrumentedExpiringStore{instrumentedStore: store, expiringStore: expiringStore}, nil
+	}
+
+	return store, nil
+}
+
+type instrumentedPinger struct {
+	*instrumentedProvider
+	pinger Pinger
+}
+
+// Ping pings the backend of the instrumented provider.
+func (p *instrumentedPinger) Ping(ctx context.Context) error {
+	return p.pinger.Ping(ctx)
+}
+
+type instrumentedStore struct {
+	Store
+	name string
+	obs  Observer
+}
+
+func (s *instrumentedStore) report(op string, start time.Time, err error) {
+	s.obs.OnOperation(op, s.name, time.Since(start), err)
+}
+
+// Put stores the key and the record
+func (s *instrumentedStore) Put(k string, v []byte) error {
+	start := time.Now()
+	err := s.Store.Put(k, v)
+	s.report(OpPut, start, err)
+
+	return err
+}
+
+// PutBatch stores all the given key/value pairs
+func (s *instrumentedStore) PutBatch(kvs []KeyValue) error {
+	start := time.Now()
+	err := s.Store.PutBatch(kvs)
+	s.report(OpPutBatch, start, err)
+
+	return err
+}
+
+// PutIfMatch stores the record only if the current record of key k equals expected
+func (s *instrumentedStore) PutIfMatch(k string, expected, newValue []byte) (bool, error) {
+	start := time.Now()
+	stored, err := s.Store.PutIfMatch(k, expected, newValue)
+	s.report(OpPutIfMatch, start, err)
+
+	return stored, err
+}
+
+// Get fetches the record based on key
+func (s *instrumentedStore) Get(k string) ([]byte, error) {
+	start := time.Now()
+	v, err := s.Store.Get(k)
+	s.report(OpGet, start, err)
+
+	return v, err
+}
+
+// GetBulk fetches the records of all the given keys
+func (s *instrumentedStore) GetBulk(keys ...string) ([][]byte, error) {
+	start := time.Now()
+	values, err := s.Store.GetBulk(keys...)
+	s.report(OpGetBulk, start, err)
+
+	return values, err
+}
+
+// Has checks whether a record with key k exists
+func (s *instrumentedStore) Has(k string) (bool, error) {
+	start := time.Now()
+	found, err := s.Store.Has(k)
+	s.report(OpHas, start, err)
+
+	return found, err
+}
+
+// Iterator returns an iterator for the latest snapshot of the underlying store
+func (s *instrumentedStore) Iterator(startKey, endKey string, opts ...IteratorOption) StoreIterator {
+	start := time.Now()
+	itr := s.Store.Iterator(startKey, endKey, opts...)
+	s.report(OpIterator, start, itr.Error())
+
+	return itr
+}
+
+// Count returns the number of records within the key range
+func (s *instrumentedStore) Count(startKey, endKey string) (int, error) {
+	start := time.Now()
+	count, err := s.Store.Count(startKey, endKey)
+	s.report(OpCount, start, err)
+
+	return count, err
+}
+
+// ForEach calls fn with every key/value pair within the key range, the time spent in fn being part of the operation
+func (s *instrumentedStore) ForEach(startKey, endKey string, fn func(key, value []byte) (bool, error)) error {
+	start := time.Now()
+	err := s.Store.ForEach(startKey, endKey, fn)
+	s.report(OpForEach, start, err)
+
+	return err
+}
+
+// Delete will delete a record with k key
+func (s *instrumentedStore) Delete(k string) error {
+	start := time.Now()
+	err := s.Store.Delete(k)
+	s.report(OpDelete, start, err)
+
+	return err
+}
+
+// Begin starts a new transaction on the instrumented store, the operations of the transaction aren't reported.
+func (s *instrumentedStore) Begin(opts ...TxOption) (Transaction, error) {
+	txStore, ok := s.Store.(Transactional)
+	if !ok {
+		return nil, ErrTransactionsNotSupported
+	}
+
+	return txStore.Begin(opts...)
+}
+
+// PutContext stores the key and the record, the operation isn't started when ctx is already done if the
+// instrumented store isn't a ContextStore.
+func (s *instrumentedStore) PutContext(ctx context.Context, k string, v []byte) error {
+	ctxStore, ok := s.Store.(ContextStore)
+	if !ok {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		return s.Put(k, v)
+	}
+
+	start := time.Now()
+	err := ctxStore.PutContext(ctx, k, v)
+	s.report(OpPut, start, err)
+
+	return err
+}
+
+// GetContext fetches the record based on key, the operation isn't started when ctx is already done if the
+// instrumented store isn't a ContextStore.
+func (s *instrumentedStore) GetContext(ctx context.Context, k string) ([]byte, error) {
+	ctxStore, ok := s.Store.(ContextStore)
+	if !ok {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		return s.Get(k)
+	}
+
+	start := time.Now()
+	v, err := ctxStore.GetContext(ctx, k)
+	s.report(OpGet, start, err)
+
+	return v, err
+}
+
+// DeleteContext deletes the record of key k, the operation isn't started when ctx is already done if the
+// instrumented store isn't a ContextStore.
+func (s *instrumentedStore) DeleteContext(ctx context.Context, k string) error {
+	ctxStore, ok := s.Store.(ContextStore)
+	if !ok {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		return s.Delete(k)
+	}
+
+	start := time.Now()
+	err := ctxStore.DeleteContext(ctx, k)
+	s.report(OpDelete, start, err)
+
+	return err
+}
+
+type instrumentedExpiringStore struct {
+	*instrumentedStore
+	expiringStore ExpiringStore
+}
+
+// PutWithExpiry stores the key and the record until ttl elapses
+func (s *instrumentedExpiringStore) PutWithExpiry(k string, v []byte, ttl time.Duration) error {
+	start := time.Now()
+	err := s.expiringStore.PutWithExpiry(k, v, ttl)
+	s.report(OpPutExpiry, start, err)
+
+	return err
+}
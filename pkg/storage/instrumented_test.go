@@ -0,0 +1,131 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+type operation struct {
+	op    string
+	store string
+	err   error
+}
+
+type recordingObserver struct {
+	operations []operation
+}
+
+func (o *recordingObserver) OnOperation(op, store string, dur time.Duration, err error) {
+	o.operations = append(o.operations, operation{op: op, store: store, err: err})
+}
+
+func TestNewInstrumentedProvider(t *testing.T) {
+	t.Run("test store operations are reported", func(t *testing.T) {
+		obs := &recordingObserver{}
+		prov := storage.NewInstrumentedProvider(mem.NewProvider(), obs)
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("k1", []byte("v1")))
+		require.NoError(t, store.PutBatch([]storage.KeyValue{{Key: "k2", Value: []byte("v2")}}))
+
+		_, err = store.PutIfMatch("k1", []byte("v1"), []byte("v3"))
+		require.NoError(t, err)
+
+		_, err = store.Get("k1")
+		require.NoError(t, err)
+
+		_, err = store.Get("k4")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		_, err = store.GetBulk("k1", "k2")
+		require.NoError(t, err)
+
+		_, err = store.Has("k1")
+		require.NoError(t, err)
+
+		itr := store.Iterator("k", "k"+storage.EndKeySuffix)
+		itr.Release()
+
+		_, err = store.Count("", "")
+		require.NoError(t, err)
+
+		require.NoError(t, store.ForEach("k", "k"+storage.EndKeySuffix, func(key, value []byte) (bool, error) {
+			return false, nil
+		}))
+
+		require.NoError(t, store.Delete("k1"))
+
+		require.Equal(t, []operation{
+			{op: storage.OpPut, store: "test"},
+			{op: storage.OpPutBatch, store: "test"},
+			{op: storage.OpPutIfMatch, store: "test"},
+			{op: storage.OpGet, store: "test"},
+			{op: storage.OpGet, store: "test", err: storage.ErrDataNotFound},
+			{op: storage.OpGetBulk, store: "test"},
+			{op: storage.OpHas, store: "test"},
+			{op: storage.OpIterator, store: "test"},
+			{op: storage.OpCount, store: "test"},
+			{op: storage.OpForEach, store: "test"},
+			{op: storage.OpDelete, store: "test"},
+		}, obs.operations)
+	})
+
+	t.Run("test optional store capabilities", func(t *testing.T) {
+		obs := &recordingObserver{}
+		prov := storage.NewInstrumentedProvider(mem.NewProvider(), obs)
+
+		_, ok := prov.(storage.Pinger)
+		require.False(t, ok)
+		require.Equal(t, storage.HealthStatusUnknown, storage.Health(context.Background(), prov).Status)
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		expiringStore, ok := store.(storage.ExpiringStore)
+		require.True(t, ok)
+		require.NoError(t, expiringStore.PutWithExpiry("k1", []byte("v1"), time.Hour))
+
+		txStore, ok := store.(storage.Transactional)
+		require.True(t, ok)
+
+		_, err = txStore.Begin()
+		require.Equal(t, storage.ErrTransactionsNotSupported, err)
+
+		ctxStore, ok := store.(storage.ContextStore)
+		require.True(t, ok)
+
+		v, err := ctxStore.GetContext(context.Background(), "k1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), v)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.Equal(t, context.Canceled, ctxStore.PutContext(ctx, "k2", []byte("v2")))
+		require.Equal(t, context.Canceled, ctxStore.DeleteContext(ctx, "k1"))
+
+		require.Equal(t, []operation{
+			{op: storage.OpPutExpiry, store: "test"},
+			{op: storage.OpGet, store: "test"},
+		}, obs.operations)
+	})
+
+	t.Run("test nil observer", func(t *testing.T) {
+		prov := mem.NewProvider()
+		require.Equal(t, storage.Provider(prov), storage.NewInstrumentedProvider(prov, nil))
+	})
+}
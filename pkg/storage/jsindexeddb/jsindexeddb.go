@@ -9,6 +9,7 @@ SPDX-License-Identifier: Apache-2.0
 package jsindexeddb
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"sync"
@@ -40,6 +41,8 @@ var dbVersion = 1 //nolint:gochecknoglobals
 type Provider struct {
 	sync.RWMutex
 	stores map[string]*js.Value
+	// putIfMatchLock serializes the compare-and-swap operations of all the stores
+	putIfMatchLock sync.Mutex
 }
 
 // NewProvider instantiates Provider
@@ -72,7 +75,7 @@ func (p *Provider) OpenStore(name string) (storage.Store, error) {
 	p.RUnlock()
 
 	if ok {
-		return &store{name: name, db: db}, nil
+		return &store{name: name, db: db, putIfMatchLock: &p.putIfMatchLock}, nil
 	}
 
 	p.Lock()
@@ -84,7 +87,7 @@ func (p *Provider) OpenStore(name string) (storage.Store, error) {
 		return nil, err
 	}
 
-	return &store{name: name, db: p.stores[name]}, nil
+	return &store{name: name, db: p.stores[name], putIfMatchLock: &p.putIfMatchLock}, nil
 }
 
 func (p *Provider) openDB(db string, names ...string) error {
@@ -117,13 +120,18 @@ func (p *Provider) CloseStore(name string) error {
 }
 
 type store struct {
-	name string
-	db   *js.Value
+	name           string
+	db             *js.Value
+	putIfMatchLock *sync.Mutex
 }
 
 // Put stores the key and the record
 func (s *store) Put(k string, v []byte) error {
-	if k == "" || v == nil {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	if v == nil {
 		return errors.New("key and value are mandatory")
 	}
 
@@ -141,10 +149,62 @@ func (s *store) Put(k string, v []byte) error {
 	return nil
 }
 
+// PutIfMatch stores the key and the record only if the current record equals expected.
+// IndexedDB transactions can't span the asynchronous calls made from Go, so the comparison and the write are
+// serialized with the other PutIfMatch calls of the provider instead, but not with plain Put calls.
+func (s *store) PutIfMatch(k string, expected, newValue []byte) (bool, error) {
+	if k == "" {
+		return false, storage.ErrKeyRequired
+	}
+
+	if newValue == nil {
+		return false, errors.New("key and value are mandatory")
+	}
+
+	s.putIfMatchLock.Lock()
+	defer s.putIfMatchLock.Unlock()
+
+	current, err := s.Get(k)
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return false, err
+	}
+
+	if (err == nil) != (expected != nil) || !bytes.Equal(current, expected) {
+		return false, nil
+	}
+
+	if err = s.Put(k, newValue); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// PutBatch stores all the given key/value pairs
+func (s *store) PutBatch(kvs []storage.KeyValue) error {
+	for _, kv := range kvs {
+		if kv.Key == "" {
+			return storage.ErrKeyRequired
+		}
+
+		if kv.Value == nil {
+			return errors.New("key and value are mandatory")
+		}
+	}
+
+	for _, kv := range kvs {
+		if err := s.Put(kv.Key, kv.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Get fetches the record based on key
 func (s *store) Get(k string) ([]byte, error) {
 	if k == "" {
-		return nil, errors.New("key is mandatory")
+		return nil, storage.ErrKeyRequired
 	}
 
 	req := s.db.Call("transaction", s.name).Call("objectStore", s.name).Call("get", k)
@@ -161,24 +221,77 @@ func (s *store) Get(k string) ([]byte, error) {
 	return []byte(data.Get("value").String()), nil
 }
 
+// GetBulk fetches the records of the given keys, with a nil entry for every key not found
+func (s *store) GetBulk(keys ...string) ([][]byte, error) {
+	return storage.GetBulk(s.Get, keys...)
+}
+
+// Has checks whether a record with key k exists
+func (s *store) Has(k string) (bool, error) {
+	if k == "" {
+		return false, storage.ErrKeyRequired
+	}
+
+	req := s.db.Call("transaction", s.name).Call("objectStore", s.name).Call("count", k)
+
+	count, err := getResult(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to count data: %w", err)
+	}
+
+	return count.Int() > 0, nil
+}
+
 // Iterator returns iterator for the latest snapshot of the underlying db.
-func (s *store) Iterator(start, limit string) storage.StoreIterator {
+func (s *store) Iterator(start, limit string, opts ...storage.IteratorOption) storage.StoreIterator {
 	// TODO Change Store Iterator https://github.com/hyperledger/aries-framework-go/issues/852
 	if start == "" {
 		return newIterator(nil, fmt.Errorf("start key is mandatory"))
 	}
 
+	options := storage.GetIteratorOptions(opts...)
 	keyRange := js.Global().Get("IDBKeyRange").Call("bound", start, start+"\uffff")
+
+	// getAll can only cap the records from the start of the range while seeking may skip some of them, so the limit
+	// is applied by the iterator
 	openCursor := s.db.Call("transaction", s.name).Call("objectStore", s.name).Call("getAll", keyRange)
 	batch, err := getResult(openCursor)
 
-	return newIterator(batch, err)
+	itr := newIterator(batch, err)
+	itr.reverse = options.Reverse
+	itr.limit = options.Limit
+
+	return itr
+}
+
+// Count returns the number of records whose key starts with start, like the iterator of the store.
+// The whole object store is counted when both keys are empty.
+func (s *store) Count(start, limit string) (int, error) {
+	var args []interface{}
+
+	if start != "" || limit != "" {
+		args = append(args, js.Global().Get("IDBKeyRange").Call("bound", start, start+"\uffff"))
+	}
+
+	req := s.db.Call("transaction", s.name).Call("objectStore", s.name).Call("count", args...)
+
+	count, err := getResult(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count data: %w", err)
+	}
+
+	return count.Int(), nil
+}
+
+// ForEach calls fn with every key/value pair within the key range until fn returns stop or an error
+func (s *store) ForEach(start, limit string, fn func(key, value []byte) (bool, error)) error {
+	return storage.ForEach(s.Iterator(start, limit), fn)
 }
 
 // Delete will delete record with k key
 func (s *store) Delete(k string) error {
 	if k == "" {
-		return errors.New("key is mandatory")
+		return storage.ErrKeyRequired
 	}
 
 	req := s.db.Call("transaction", s.name, "readwrite").Call("objectStore", s.name).Call("delete", k)
@@ -192,9 +305,12 @@ func (s *store) Delete(k string) error {
 }
 
 type iterator struct {
-	batch *js.Value
-	err   error
-	index int
+	batch    *js.Value
+	err      error
+	index    int
+	reverse  bool
+	limit    int
+	returned int
 }
 
 // newIterator returns new iterator for given batch
@@ -205,19 +321,67 @@ func newIterator(batch *js.Value, err error) *iterator {
 // Next moves pointer to next value of iterator.
 // It returns false if the iterator is exhausted.
 func (s *iterator) Next() bool {
+	if s.limit > 0 && s.returned >= s.limit {
+		return false
+	}
+
 	s.index++
 
-	if s.batch != nil && s.batch.Index(s.index).Truthy() {
+	if s.batch != nil && s.current().Truthy() {
+		s.returned++
+
 		return true
 	}
 
 	return false
 }
 
+// Seek moves pointer to the first value whose key is at or after key in the iteration order.
+// It returns false if no such key exists.
+func (s *iterator) Seek(key string) bool {
+	if s.batch == nil || (s.limit > 0 && s.returned >= s.limit) {
+		return false
+	}
+
+	for s.index = 0; s.index < s.batch.Length(); s.index++ {
+		k := s.current().Get("key").String()
+
+		if (s.reverse && k <= key) || (!s.reverse && k >= key) {
+			s.returned++
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// current returns the item at the current position, walking the batch from its end for reverse iterators.
+func (s *iterator) current() js.Value {
+	if s.reverse {
+		return s.batch.Index(s.batch.Length() - 1 - s.index)
+	}
+
+	return s.batch.Index(s.index)
+}
+
 // Release releases associated resources.
 func (s *iterator) Release() {
 }
 
+// TotalCount returns the number of records fetched by the iterator, regardless of its limit.
+func (s *iterator) TotalCount() (int, error) {
+	if s.err != nil {
+		return -1, s.err
+	}
+
+	if s.batch == nil {
+		return 0, nil
+	}
+
+	return s.batch.Length(), nil
+}
+
 // Error returns error in iterator.
 func (s *iterator) Error() error {
 	return s.err
@@ -225,8 +389,8 @@ func (s *iterator) Error() error {
 
 // Key returns the key of the current key/value pair.
 func (s *iterator) Key() []byte {
-	if s.batch != nil && s.batch.Index(s.index).Truthy() {
-		return []byte(s.batch.Index(s.index).Get("key").String())
+	if s.batch != nil && s.current().Truthy() {
+		return []byte(s.current().Get("key").String())
 	}
 
 	return nil
@@ -234,8 +398,8 @@ func (s *iterator) Key() []byte {
 
 // Value returns the value of the current key/value pair.
 func (s *iterator) Value() []byte {
-	if s.batch != nil && s.batch.Index(s.index).Truthy() {
-		return []byte(s.batch.Index(s.index).Get("value").String())
+	if s.batch != nil && s.current().Truthy() {
+		return []byte(s.current().Get("value").String())
 	}
 
 	return nil
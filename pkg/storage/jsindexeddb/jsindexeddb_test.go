@@ -68,8 +68,7 @@ func TestStore(t *testing.T) {
 
 		// nil key
 		err = store.Put("", data)
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "key and value are mandatory")
+		require.Equal(t, storage.ErrKeyRequired, err)
 
 		err = prov.Close()
 		require.NoError(t, err)
@@ -9,8 +9,12 @@ SPDX-License-Identifier: Apache-2.0
 package leveldb
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 
@@ -25,14 +29,32 @@ const pathPattern = "%s-%s"
 
 // Provider leveldb implementation of storage.Provider interface
 type Provider struct {
-	dbPath string
-	dbs    map[string]*leveldbStore
-	lock   sync.RWMutex
+	dbPath       string
+	dbs          map[string]*leveldbStore
+	strictDelete bool
+	lock         sync.RWMutex
+}
+
+// Option configures the leveldb provider
+type Option func(opts *Provider)
+
+// WithStrictDelete option makes the Delete of the stores return storage.ErrDataNotFound when the key doesn't exist,
+// deletes are idempotent by default.
+func WithStrictDelete() Option {
+	return func(opts *Provider) {
+		opts.strictDelete = true
+	}
 }
 
 // NewProvider instantiates Provider
-func NewProvider(dbPath string) *Provider {
-	return &Provider{dbs: make(map[string]*leveldbStore), dbPath: dbPath}
+func NewProvider(dbPath string, opts ...Option) *Provider {
+	p := &Provider{dbs: make(map[string]*leveldbStore), dbPath: dbPath}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 // OpenStore opens and returns a store for given name space.
@@ -45,6 +67,35 @@ func (p *Provider) OpenStore(name string) (storage.Store, error) {
 	return store, nil
 }
 
+// StoreNames returns the names of the stores found in the directory of the DB path, including the stores that
+// aren't open.
+func (p *Provider) StoreNames() ([]string, error) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	// the store directories are named following pathPattern
+	prefix := fmt.Sprintf(pathPattern, filepath.Base(p.dbPath), "")
+
+	files, err := ioutil.ReadDir(filepath.Dir(p.dbPath))
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stores: %w", err)
+	}
+
+	names := []string{}
+
+	for _, f := range files {
+		if f.IsDir() && strings.HasPrefix(f.Name(), prefix) && len(f.Name()) > len(prefix) {
+			names = append(names, strings.TrimPrefix(f.Name(), prefix))
+		}
+	}
+
+	return names, nil
+}
+
 // getLeveldbStore finds level db store with given name
 // returns nil if not found
 func (p *Provider) getLeveldbStore(name string) *leveldbStore {
@@ -65,28 +116,29 @@ func (p *Provider) newLeveldbStore(name string) (*leveldbStore, error) {
 		return nil, err
 	}
 
-	store := &leveldbStore{db}
+	store := &leveldbStore{db: db, strictDelete: p.strictDelete}
 	p.dbs[strings.ToLower(name)] = store
 
 	return store, nil
 }
 
-// Close closes all stores created under this store provider
+// Close closes all stores created under this store provider. Every store is closed even if closing another one fails,
+// the errors are wrapped in a storage.MultiError. The stores are kept on failure so that Close can be retried.
 func (p *Provider) Close() error {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
-	var errs []error
+	var errs storage.MultiError
 
-	for _, v := range p.dbs {
+	for name, v := range p.dbs {
 		e := v.db.Close()
 		if e != nil && e != leveldb.ErrClosed {
-			errs = append(errs, e)
+			errs = append(errs, fmt.Errorf("failed to close store %s: %w", name, e))
 		}
 	}
 
 	if len(errs) > 0 {
-		return fmt.Errorf("failed to close stores, %v", errs)
+		return fmt.Errorf("failed to close stores: %w", errs)
 	}
 
 	p.dbs = make(map[string]*leveldbStore)
@@ -111,22 +163,90 @@ func (p *Provider) CloseStore(name string) error {
 }
 
 type leveldbStore struct {
-	db *leveldb.DB
+	db           *leveldb.DB
+	strictDelete bool
 }
 
 // Put stores the key and the record
 func (s *leveldbStore) Put(k string, v []byte) error {
-	if k == "" || v == nil {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	if v == nil {
 		return errors.New("key and value are mandatory")
 	}
 
 	return s.db.Put([]byte(k), v, nil)
 }
 
+// PutIfMatch stores the key and the record only if the current record equals expected. The comparison and the
+// write happen within a leveldb transaction, which blocks the other writes in the meantime.
+func (s *leveldbStore) PutIfMatch(k string, expected, newValue []byte) (bool, error) {
+	if k == "" {
+		return false, storage.ErrKeyRequired
+	}
+
+	if newValue == nil {
+		return false, errors.New("key and value are mandatory")
+	}
+
+	tr, err := s.db.OpenTransaction()
+	if err != nil {
+		return false, fmt.Errorf("failed to open transaction: %w", err)
+	}
+
+	current, err := tr.Get([]byte(k), nil)
+	if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+		tr.Discard()
+
+		return false, err
+	}
+
+	if (err == nil) != (expected != nil) || !bytes.Equal(current, expected) {
+		tr.Discard()
+
+		return false, nil
+	}
+
+	if err = tr.Put([]byte(k), newValue, nil); err != nil {
+		tr.Discard()
+
+		return false, err
+	}
+
+	if err = tr.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// PutBatch stores all the given key/value pairs
+func (s *leveldbStore) PutBatch(kvs []storage.KeyValue) error {
+	for _, kv := range kvs {
+		if kv.Key == "" {
+			return storage.ErrKeyRequired
+		}
+
+		if kv.Value == nil {
+			return errors.New("key and value are mandatory")
+		}
+	}
+
+	for _, kv := range kvs {
+		if err := s.db.Put([]byte(kv.Key), kv.Value, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Get fetches the record based on key
 func (s *leveldbStore) Get(k string) ([]byte, error) {
 	if k == "" {
-		return nil, errors.New("key is mandatory")
+		return nil, storage.ErrKeyRequired
 	}
 
 	data, err := s.db.Get([]byte(k), nil)
@@ -141,21 +261,245 @@ func (s *leveldbStore) Get(k string) ([]byte, error) {
 	return data, nil
 }
 
-// Iterator returns iterator for the latest snapshot of the underlying db.
-func (s *leveldbStore) Iterator(start, limit string) storage.StoreIterator {
-	if start == "" || limit == "" {
-		iterator.NewEmptyIterator(errors.New("start or limit key is mandatory"))
+// GetBulk fetches the records of the given keys, with a nil entry for every key not found
+func (s *leveldbStore) GetBulk(keys ...string) ([][]byte, error) {
+	return storage.GetBulk(s.Get, keys...)
+}
+
+// Has checks whether a record with key k exists
+func (s *leveldbStore) Has(k string) (bool, error) {
+	if k == "" {
+		return false, storage.ErrKeyRequired
 	}
 
-	return s.db.NewIterator(&util.Range{Start: []byte(start),
-		Limit: []byte(strings.ReplaceAll(limit, storage.EndKeySuffix, "~"))}, nil)
+	return s.db.Has([]byte(k), nil)
+}
+
+// Iterator returns iterator for the latest snapshot of the underlying db, an empty limit iterating up to the end of
+// the db.
+func (s *leveldbStore) Iterator(start, limit string, opts ...storage.IteratorOption) storage.StoreIterator {
+	keyRange := newKeyRange(start, limit)
+
+	itr := s.db.NewIterator(keyRange, nil)
+	counter := rangeCounter{db: s.db, keyRange: keyRange}
+
+	options := storage.GetIteratorOptions(opts...)
+
+	var result storage.StoreIterator = &forwardIterator{Iterator: itr, rangeCounter: counter}
+
+	if options.Reverse {
+		result = &reverseIterator{Iterator: itr, rangeCounter: counter}
+	}
+
+	if options.Limit > 0 {
+		result = &limitIterator{StoreIterator: result, remaining: options.Limit}
+	}
+
+	return result
+}
+
+// rangeCounter counts the records of the key range of an iterator
+type rangeCounter struct {
+	db       *leveldb.DB
+	keyRange *util.Range
+}
+
+// TotalCount returns the number of records within the key range of the iterator, regardless of its limit. Since
+// leveldb doesn't keep track of the number of records, the keys of the range are iterated.
+func (c rangeCounter) TotalCount() (int, error) {
+	return countRange(c.db, c.keyRange)
+}
+
+// forwardIterator adapts a leveldb iterator to the storage.StoreIterator seek method
+type forwardIterator struct {
+	iterator.Iterator
+	rangeCounter
+}
+
+// Seek moves the iterator to the first key/value pair of the range whose key is >= key.
+// It returns false if no such key exists.
+func (i *forwardIterator) Seek(key string) bool {
+	return i.Iterator.Seek([]byte(key))
+}
+
+// reverseIterator walks a leveldb iterator backwards, starting from the last key of its range
+type reverseIterator struct {
+	iterator.Iterator
+	rangeCounter
+	started bool
+}
+
+// Next moves the iterator to the previous key/value pair of the range.
+// It returns false if the iterator is exhausted.
+func (i *reverseIterator) Next() bool {
+	if !i.started {
+		i.started = true
+
+		return i.Last()
+	}
+
+	return i.Prev()
+}
+
+// Seek moves the iterator to the last key/value pair of the range whose key is <= key.
+// It returns false if no such key exists.
+func (i *reverseIterator) Seek(key string) bool {
+	i.started = true
+
+	if !i.Iterator.Seek([]byte(key)) {
+		// all the keys of the range are before key
+		return i.Last()
+	}
+
+	if string(i.Iterator.Key()) == key {
+		return true
+	}
+
+	return i.Prev()
+}
+
+// Count returns the number of records within the [start, limit) range, the whole db being counted when both keys
+// are empty. Since leveldb doesn't keep track of the number of records, the keys of the range are iterated.
+func (s *leveldbStore) Count(start, limit string) (int, error) {
+	var keyRange *util.Range
+
+	if start != "" || limit != "" {
+		keyRange = newKeyRange(start, limit)
+	}
+
+	return countRange(s.db, keyRange)
+}
+
+// newKeyRange returns the [start, limit) range. An empty limit leaves the range unbounded unless start is empty too,
+// the range of two empty keys matching no records.
+func newKeyRange(start, limit string) *util.Range {
+	if limit == "" && start != "" {
+		return &util.Range{Start: []byte(start)}
+	}
+
+	return &util.Range{Start: []byte(start), Limit: []byte(strings.ReplaceAll(limit, storage.EndKeySuffix, "~"))}
+}
+
+// countRange iterates the keys of keyRange to count them, the whole db being counted when keyRange is nil.
+func countRange(db *leveldb.DB, keyRange *util.Range) (int, error) {
+	itr := db.NewIterator(keyRange, nil)
+	defer itr.Release()
+
+	count := 0
+
+	for itr.Next() {
+		count++
+	}
+
+	if err := itr.Error(); err != nil {
+		return 0, fmt.Errorf("failed to count records: %w", err)
+	}
+
+	return count, nil
+}
+
+// ForEach calls fn with every key/value pair within the key range until fn returns stop or an error
+func (s *leveldbStore) ForEach(start, limit string, fn func(key, value []byte) (bool, error)) error {
+	return storage.ForEach(s.Iterator(start, limit), fn)
 }
 
 // Delete will delete record with k key
 func (s *leveldbStore) Delete(k string) error {
 	if k == "" {
-		return errors.New("key is mandatory")
+		return storage.ErrKeyRequired
+	}
+
+	if s.strictDelete {
+		return s.deleteExisting(k)
 	}
 
 	return s.db.Delete([]byte(k), nil)
 }
+
+// deleteExisting deletes the record of key k or returns storage.ErrDataNotFound if it doesn't exist, the check and
+// the deletion happen within a leveldb transaction.
+func (s *leveldbStore) deleteExisting(k string) error {
+	tr, err := s.db.OpenTransaction()
+	if err != nil {
+		return fmt.Errorf("failed to open transaction: %w", err)
+	}
+
+	found, err := tr.Has([]byte(k), nil)
+	if err != nil {
+		tr.Discard()
+
+		return err
+	}
+
+	if !found {
+		tr.Discard()
+
+		return storage.ErrDataNotFound
+	}
+
+	if err = tr.Delete([]byte(k), nil); err != nil {
+		tr.Discard()
+
+		return err
+	}
+
+	if err = tr.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// limitIterator stops the underlying iterator once the given number of key/value pairs has been returned
+type limitIterator struct {
+	storage.StoreIterator
+	remaining int
+	done      bool
+}
+
+// Next moves the iterator to the next key/value pair.
+// It returns false if the iterator is exhausted or the limit is reached.
+func (i *limitIterator) Next() bool {
+	if i.remaining == 0 || !i.StoreIterator.Next() {
+		i.done = true
+
+		return false
+	}
+
+	i.remaining--
+
+	return true
+}
+
+// Seek moves the iterator to the first key/value pair at or after key in the iteration order.
+// It returns false if no such key exists or the limit is reached.
+func (i *limitIterator) Seek(key string) bool {
+	if i.remaining == 0 || !i.StoreIterator.Seek(key) {
+		i.done = true
+
+		return false
+	}
+
+	i.done = false
+	i.remaining--
+
+	return true
+}
+
+// Key returns the key of the current key/value pair, or nil if done.
+func (i *limitIterator) Key() []byte {
+	if i.done {
+		return nil
+	}
+
+	return i.StoreIterator.Key()
+}
+
+// Value returns the value of the current key/value pair, or nil if done.
+func (i *limitIterator) Value() []byte {
+	if i.done {
+		return nil
+	}
+
+	return i.StoreIterator.Value()
+}
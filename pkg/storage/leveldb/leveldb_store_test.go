@@ -9,15 +9,18 @@ SPDX-License-Identifier: Apache-2.0
 package leveldb
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/storagetest"
 )
 
 func setupLevelDB(t testing.TB) (string, func()) {
@@ -226,11 +229,88 @@ func TestLevelDBStore(t *testing.T) {
 		itr = store.Iterator("", "")
 		verifyItr(t, itr, 0, "")
 
+		// an empty limit leaves the range unbounded
+		itr = store.Iterator("jkl_", "")
+		verifyItrKeys(t, itr, "jkl_123", "mno_123")
+
 		itr = store.Iterator("abc_", "mno_"+storage.EndKeySuffix)
 		verifyItr(t, itr, 6, "")
 
 		itr = store.Iterator("abc_", "mno_123")
 		verifyItr(t, itr, 5, "")
+
+		itr = store.Iterator("abc_", "abc_"+storage.EndKeySuffix, storage.WithReverse())
+		verifyItrKeys(t, itr, "abc_126", "abc_125", "abc_124", "abc_123")
+
+		// the end key remains excluded from the range when iterating in reverse
+		itr = store.Iterator("abc_", "mno_123", storage.WithReverse())
+		verifyItrKeys(t, itr, "jkl_123", "abc_126", "abc_125", "abc_124", "abc_123")
+
+		itr = store.Iterator("abc_", "abc_"+storage.EndKeySuffix, storage.WithLimit(2))
+		verifyItrKeys(t, itr, "abc_123", "abc_124")
+
+		itr = store.Iterator("abc_", "mno_123", storage.WithReverse(), storage.WithLimit(2))
+		verifyItrKeys(t, itr, "jkl_123", "abc_126")
+
+		// a zero limit means unlimited
+		itr = store.Iterator("abc_", "abc_"+storage.EndKeySuffix, storage.WithLimit(0))
+		verifyItr(t, itr, 4, "abc_")
+
+		itr = store.Iterator("abc_", "mno_123")
+		require.True(t, itr.Seek("abc_125"))
+		require.Equal(t, "abc_125", string(itr.Key()))
+		require.Equal(t, "val-for-abc_125", string(itr.Value()))
+		verifyItrKeys(t, itr, "abc_126", "jkl_123")
+
+		itr = store.Iterator("abc_", "mno_123")
+		require.True(t, itr.Seek("abc_1255"))
+		require.Equal(t, "abc_126", string(itr.Key()))
+		require.True(t, itr.Seek("a"))
+		require.Equal(t, "abc_123", string(itr.Key()))
+		require.False(t, itr.Seek("mno_123"))
+		itr.Release()
+
+		itr = store.Iterator("abc_", "mno_123", storage.WithReverse())
+		require.True(t, itr.Seek("abc_1255"))
+		require.Equal(t, "abc_125", string(itr.Key()))
+		verifyItrKeys(t, itr, "abc_124", "abc_123")
+
+		itr = store.Iterator("abc_", "mno_123", storage.WithReverse())
+		require.True(t, itr.Seek("z"))
+		require.Equal(t, "jkl_123", string(itr.Key()))
+		require.False(t, itr.Seek("abc_"))
+		itr.Release()
+
+		itr = store.Iterator("abc_", "mno_123", storage.WithLimit(2))
+		require.True(t, itr.Seek("abc_125"))
+		verifyItrKeys(t, itr, "abc_126")
+
+		count, err := store.Count("abc_", "abc_"+storage.EndKeySuffix)
+		require.NoError(t, err)
+		require.Equal(t, 4, count)
+
+		count, err = store.Count("abc_", "mno_123")
+		require.NoError(t, err)
+		require.Equal(t, 5, count)
+
+		count, err = store.Count("", "")
+		require.NoError(t, err)
+		require.Equal(t, len(keys), count)
+
+		// the total count ignores the limit and the pairs already reached
+		itr = store.Iterator("abc_", "mno_123", storage.WithReverse(), storage.WithLimit(2))
+		total, err := itr.TotalCount()
+		require.NoError(t, err)
+		require.Equal(t, 5, total)
+
+		verifyItrKeys(t, itr, "jkl_123", "abc_126")
+		total, err = itr.TotalCount()
+		require.NoError(t, err)
+		require.Equal(t, 5, total)
+
+		total, err = store.Iterator("abc_", "abc_"+storage.EndKeySuffix).TotalCount()
+		require.NoError(t, err)
+		require.Equal(t, 4, total)
 	})
 }
 
@@ -293,4 +373,210 @@ func TestLevelDBStoreDelete(t *testing.T) {
 	doc, err = store1.Get(commonKey)
 	require.EqualError(t, err, storage.ErrDataNotFound.Error())
 	require.Empty(t, doc)
+
+	// deleting a missing key succeeds by default
+	err = store1.Delete(commonKey)
+	require.NoError(t, err)
+}
+
+func TestLevelDBStoreStrictDelete(t *testing.T) {
+	path, cleanup := setupLevelDB(t)
+	defer cleanup()
+
+	prov := NewProvider(path, WithStrictDelete())
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key1", []byte("value1")))
+	require.NoError(t, store.Delete("key1"))
+
+	err = store.Delete("key1")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+	require.NoError(t, prov.Close())
+
+	err = store.Delete("key1")
+	require.Error(t, err)
+	require.False(t, errors.Is(err, storage.ErrDataNotFound))
+}
+
+func TestLevelDBStorePutBatch(t *testing.T) {
+	path, cleanup := setupLevelDB(t)
+	defer cleanup()
+
+	prov := NewProvider(path)
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	err = store.Put("key1", []byte("old-value1"))
+	require.NoError(t, err)
+
+	err = store.PutBatch([]storage.KeyValue{
+		{Key: "key1", Value: []byte("value1")},
+		{Key: "key2", Value: []byte("value2")},
+	})
+	require.NoError(t, err)
+
+	for k, v := range map[string]string{"key1": "value1", "key2": "value2"} {
+		doc, e := store.Get(k)
+		require.NoError(t, e)
+		require.Equal(t, []byte(v), doc)
+	}
+
+	// an empty key fails the whole batch before anything is written
+	err = store.PutBatch([]storage.KeyValue{
+		{Key: "key3", Value: []byte("value3")},
+		{Key: "", Value: []byte("value4")},
+	})
+	require.Equal(t, storage.ErrKeyRequired, err)
+
+	_, err = store.Get("key3")
+	require.Equal(t, storage.ErrDataNotFound, err)
+
+	require.NoError(t, prov.Close())
+}
+
+func TestLevelDBStoreGetBulk(t *testing.T) {
+	path, cleanup := setupLevelDB(t)
+	defer cleanup()
+
+	prov := NewProvider(path)
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key1", []byte("value1")))
+	require.NoError(t, store.Put("key3", []byte("value3")))
+
+	values, err := store.GetBulk("key3", "key2", "key1")
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("value3"), nil, []byte("value1")}, values)
+
+	values, err = store.GetBulk()
+	require.NoError(t, err)
+	require.Empty(t, values)
+
+	_, err = store.GetBulk("key1", "")
+	require.EqualError(t, err, "key is mandatory")
+
+	require.NoError(t, prov.Close())
+}
+
+func TestLevelDBStorePutIfMatch(t *testing.T) {
+	path, cleanup := setupLevelDB(t)
+	defer cleanup()
+
+	prov := NewProvider(path)
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	ok, err := store.PutIfMatch("key1", nil, []byte("value1"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// create-only fails once the key exists
+	ok, err = store.PutIfMatch("key1", nil, []byte("value2"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = store.PutIfMatch("key1", []byte("other"), []byte("value2"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = store.PutIfMatch("key1", []byte("value1"), []byte("value2"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	v, err := store.Get("key1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), v)
+
+	ok, err = store.PutIfMatch("key2", []byte("value1"), []byte("value2"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = store.PutIfMatch("", nil, []byte("value1"))
+	require.Error(t, err)
+}
+
+func TestLevelDBStoreHas(t *testing.T) {
+	path, cleanup := setupLevelDB(t)
+	defer cleanup()
+
+	prov := NewProvider(path)
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key1", []byte("value1")))
+
+	ok, err := store.Has("key1")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = store.Has("key2")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = store.Has("")
+	require.EqualError(t, err, "key is mandatory")
+
+	require.NoError(t, prov.Close())
+
+	_, err = store.Has("key1")
+	require.Error(t, err)
+}
+
+func verifyItrKeys(t *testing.T, itr storage.StoreIterator, keys ...string) {
+	var actual []string
+
+	for itr.Next() {
+		actual = append(actual, string(itr.Key()))
+	}
+
+	require.Equal(t, keys, actual)
+
+	itr.Release()
+}
+
+func TestLevelDBProviderStoreNames(t *testing.T) {
+	path, cleanup := setupLevelDB(t)
+	defer cleanup()
+
+	prov := NewProvider(filepath.Join(path, "db"))
+
+	names, err := prov.StoreNames()
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	for _, name := range []string{"store2", "store1", "store3"} {
+		_, err = prov.OpenStore(name)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, prov.CloseStore("store3"))
+
+	// unrelated files of the directory are ignored
+	require.NoError(t, ioutil.WriteFile(filepath.Join(path, "db-file"), []byte("data"), 0600))
+
+	var lister storage.StoreLister = prov
+
+	names, err = lister.StoreNames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"store1", "store2", "store3"}, names)
+
+	require.NoError(t, prov.Close())
+
+	prov = NewProvider(filepath.Join(path, "missing", "db"))
+
+	names, err = prov.StoreNames()
+	require.NoError(t, err)
+	require.Empty(t, names)
+}
+
+func TestLevelDBStoreConformance(t *testing.T) {
+	path, cleanup := setupLevelDB(t)
+	defer cleanup()
+
+	storagetest.RunSuite(t, func() (storage.Provider, error) {
+		return NewProvider(path), nil
+	})
 }
@@ -4,28 +4,65 @@ Copyright SecureKey Technologies Inc. All Rights Reserved.
 SPDX-License-Identifier: Apache-2.0
 */
 
+// Package mem provides an in-memory storage.Provider, ie to test code using the stores without a database.
+//
+// Its stores follow the semantics of the MySQL stores: iterators, counts and range deletes operate on the
+// [startKey, endKey) range, storage.EndKeySuffix matching all the keys starting with the rest of endKey. An empty
+// endKey leaves the range unbounded, like for the leveldb stores. The provider and its stores are safe for concurrent
+// use.
 package mem
 
 import (
+	"bytes"
 	"errors"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
 
-// TODO https://github.com/hyperledger/aries-framework-go/issues/750 - we will need to consider
-//  automatic eviction based on TTL.
+// endKeySuffixReplacement replaces storage.EndKeySuffix in the end key of ranges, it sorts after the other printable
+// ASCII characters like for the leveldb stores.
+const endKeySuffixReplacement = "~"
 
-// Provider leveldb implementation of storage.Provider interface
+// Provider in-memory implementation of storage.Provider interface
 type Provider struct {
-	dbs  map[string]*memStore
-	lock sync.RWMutex
+	dbs          map[string]*memStore
+	dbPrefix     string
+	strictDelete bool
+	lock         sync.RWMutex
+}
+
+// Option configures the mem provider
+type Option func(opts *Provider)
+
+// WithDBPrefix option is for adding prefix to store names, it lets tests share the configuration of the providers
+// backed by a database. The prefix is trimmed with storage.TrimDBPrefix.
+func WithDBPrefix(dbPrefix string) Option {
+	return func(opts *Provider) {
+		opts.dbPrefix = storage.TrimDBPrefix(dbPrefix)
+	}
+}
+
+// WithStrictDelete option makes the Delete of the stores return storage.ErrDataNotFound when the key doesn't exist,
+// deletes are idempotent by default.
+func WithStrictDelete() Option {
+	return func(opts *Provider) {
+		opts.strictDelete = true
+	}
 }
 
 // NewProvider instantiates Provider
-func NewProvider() *Provider {
-	return &Provider{dbs: make(map[string]*memStore)}
+func NewProvider(opts ...Option) *Provider {
+	p := &Provider{dbs: make(map[string]*memStore)}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
 }
 
 // OpenStore opens and returns a store for given name space.
@@ -38,34 +75,79 @@ func (p *Provider) OpenStore(name string) (storage.Store, error) {
 	return store, nil
 }
 
+// OpenExistingStore returns the store for given name space, or storage.ErrStoreNotFound if it isn't open since the
+// stores of the provider only exist in memory.
+func (p *Provider) OpenExistingStore(name string) (storage.Store, error) {
+	store := p.getMemStore(name)
+	if store == nil {
+		return nil, storage.ErrStoreNotFound
+	}
+
+	return store, nil
+}
+
+// dbName returns the name of the store with given name in the map of the provider
+func (p *Provider) dbName(name string) string {
+	if p.dbPrefix != "" {
+		name = p.dbPrefix + "_" + name
+	}
+
+	return strings.ToLower(name)
+}
+
 // getMemStore finds mem store with given name
 // returns nil if not found
 func (p *Provider) getMemStore(name string) *memStore {
 	p.lock.RLock()
 	defer p.lock.RUnlock()
 
-	return p.dbs[strings.ToLower(name)]
+	return p.dbs[p.dbName(name)]
 }
 
-// newMemStore creates mem store for given name space
-// returns nil if not found
+// newMemStore creates mem store for given name space, unless it has been created concurrently
 func (p *Provider) newMemStore(name string) *memStore {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
-	store := &memStore{db: make(map[string][]byte)}
-	p.dbs[strings.ToLower(name)] = store
+	if store, ok := p.dbs[p.dbName(name)]; ok {
+		return store
+	}
+
+	store := &memStore{
+		db:           make(map[string][]byte),
+		expiry:       make(map[string]time.Time),
+		tags:         make(map[string]map[string]string),
+		strictDelete: p.strictDelete,
+	}
+	p.dbs[p.dbName(name)] = store
 
 	return store
 }
 
+// StoreNames returns the names of the open stores, lower cased, since the stores of the provider only exist in memory
+func (p *Provider) StoreNames() ([]string, error) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	names := make([]string, 0, len(p.dbs))
+	prefix := p.dbName("")
+
+	for name := range p.dbs {
+		names = append(names, strings.TrimPrefix(name, prefix))
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
 // Close closes all stores created under this store provider
 func (p *Provider) Close() error {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
 	for _, memStore := range p.dbs {
-		memStore.db = make(map[string][]byte)
+		memStore.reset()
 	}
 
 	p.dbs = make(map[string]*memStore)
@@ -78,31 +160,216 @@ func (p *Provider) CloseStore(name string) error {
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
-	k := strings.ToLower(name)
+	k := p.dbName(name)
 
 	memStore, ok := p.dbs[k]
 	if ok {
 		delete(p.dbs, k)
 
-		memStore.db = make(map[string][]byte)
+		memStore.reset()
+	}
+
+	return nil
+}
+
+// Truncate deletes all the records of the store with given name, along with their expiry and tags. Truncating a store
+// which isn't open is a no-op, unless the provider has the WithStrictDelete option in which case
+// storage.ErrStoreNotFound is returned.
+func (p *Provider) Truncate(name string) error {
+	store := p.getMemStore(name)
+	if store == nil {
+		if p.strictDelete {
+			return storage.ErrStoreNotFound
+		}
+
+		return nil
 	}
 
+	store.reset()
+
 	return nil
 }
 
 type memStore struct {
 	db map[string][]byte
+	// expiry holds the expiration time of the keys stored with a TTL
+	expiry map[string]time.Time
+	// tags holds the tags of the keys stored with PutWithTags
+	tags         map[string]map[string]string
+	strictDelete bool
 	sync.RWMutex
 }
 
+func (s *memStore) reset() {
+	s.Lock()
+	s.db = make(map[string][]byte)
+	s.expiry = make(map[string]time.Time)
+	s.tags = make(map[string]map[string]string)
+	s.Unlock()
+}
+
+// lookup returns the record of key k unless it doesn't exist or has expired, the store must be locked.
+func (s *memStore) lookup(k string, now time.Time) ([]byte, bool) {
+	v, ok := s.db[k]
+	if !ok {
+		return nil, false
+	}
+
+	if expiresAt, ok := s.expiry[k]; ok && !now.Before(expiresAt) {
+		return nil, false
+	}
+
+	return v, true
+}
+
 // Put stores the key and the record
 func (s *memStore) Put(k string, v []byte) error {
-	if k == "" || v == nil {
-		return errors.New("key and value are mandatory")
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	if v == nil {
+		return storage.ErrNilValue
 	}
 
 	s.Lock()
-	s.db[k] = v
+	s.db[k] = clone(v)
+	delete(s.expiry, k)
+	s.Unlock()
+
+	return nil
+}
+
+// PutWithExpiry stores the key and the record until ttl elapses
+func (s *memStore) PutWithExpiry(k string, v []byte, ttl time.Duration) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	if v == nil {
+		return storage.ErrNilValue
+	}
+
+	if ttl <= 0 {
+		return storage.ErrInvalidTTL
+	}
+
+	now := time.Now()
+
+	s.Lock()
+	defer s.Unlock()
+
+	// expired records are only purged here so that stores not using TTLs don't pay for it
+	for key, expiresAt := range s.expiry {
+		if !now.Before(expiresAt) {
+			delete(s.db, key)
+			delete(s.expiry, key)
+			delete(s.tags, key)
+		}
+	}
+
+	s.db[k] = clone(v)
+	s.expiry[k] = now.Add(ttl)
+
+	return nil
+}
+
+// PutWithTags stores the key and the record along with the given tags, replacing the tags of the key
+func (s *memStore) PutWithTags(k string, v []byte, tags map[string]string) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	if v == nil {
+		return storage.ErrNilValue
+	}
+
+	keyTags := make(map[string]string, len(tags))
+
+	for name, value := range tags {
+		keyTags[name] = value
+	}
+
+	s.Lock()
+	s.db[k] = clone(v)
+	delete(s.expiry, k)
+	s.tags[k] = keyTags
+	s.Unlock()
+
+	return nil
+}
+
+// QueryByTag returns an iterator over the records tagged with the given name and value, in ascending key order
+func (s *memStore) QueryByTag(name, value string) (storage.StoreIterator, error) {
+	if name == "" {
+		return nil, errors.New("tag name is mandatory")
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+
+	var batch [][]string
+
+	now := time.Now()
+
+	for k, keyTags := range s.tags {
+		if tagValue, ok := keyTags[name]; !ok || tagValue != value {
+			continue
+		}
+
+		if v, ok := s.lookup(k, now); ok {
+			batch = append(batch, []string{k, string(v)})
+		}
+	}
+
+	sort.Slice(batch, func(i, j int) bool {
+		return batch[i][0] < batch[j][0]
+	})
+
+	return newMemIterator(batch), nil
+}
+
+// PutIfMatch stores the key and the record only if the current record equals expected
+func (s *memStore) PutIfMatch(k string, expected, newValue []byte) (bool, error) {
+	if k == "" {
+		return false, storage.ErrKeyRequired
+	}
+
+	if newValue == nil {
+		return false, storage.ErrNilValue
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	current, exists := s.lookup(k, time.Now())
+	if exists != (expected != nil) || !bytes.Equal(current, expected) {
+		return false, nil
+	}
+
+	s.db[k] = clone(newValue)
+	delete(s.expiry, k)
+
+	return true, nil
+}
+
+// PutBatch stores all the given key/value pairs
+func (s *memStore) PutBatch(kvs []storage.KeyValue) error {
+	for _, kv := range kvs {
+		if kv.Key == "" {
+			return storage.ErrKeyRequired
+		}
+
+		if kv.Value == nil {
+			return storage.ErrNilValue
+		}
+	}
+
+	s.Lock()
+	for _, kv := range kvs {
+		s.db[kv.Key] = clone(kv.Value)
+		delete(s.expiry, kv.Key)
+	}
 	s.Unlock()
 
 	return nil
@@ -111,56 +378,199 @@ func (s *memStore) Put(k string, v []byte) error {
 // Get fetches the record based on key
 func (s *memStore) Get(k string) ([]byte, error) {
 	if k == "" {
-		return nil, errors.New("key is mandatory")
+		return nil, storage.ErrKeyRequired
 	}
 
 	s.RLock()
-	data, ok := s.db[k]
+	data, ok := s.lookup(k, time.Now())
 	s.RUnlock()
 
 	if !ok {
 		return nil, storage.ErrDataNotFound
 	}
 
-	return data, nil
+	return clone(data), nil
+}
+
+// GetBulk fetches the records of the given keys, with a nil entry for every key not found
+func (s *memStore) GetBulk(keys ...string) ([][]byte, error) {
+	return storage.GetBulk(s.Get, keys...)
 }
 
-// Iterator returns iterator for the latest snapshot of the underlying db.
-func (s *memStore) Iterator(start, limit string) storage.StoreIterator {
-	// TODO Change Store Iterator https://github.com/hyperledger/aries-framework-go/issues/852
+// Has checks whether a record with key k exists
+func (s *memStore) Has(k string) (bool, error) {
+	if k == "" {
+		return false, storage.ErrKeyRequired
+	}
+
+	s.RLock()
+	_, ok := s.lookup(k, time.Now())
+	s.RUnlock()
+
+	return ok, nil
+}
+
+// Iterator returns iterator for the latest snapshot of the underlying db, over the [start, limit) range.
+func (s *memStore) Iterator(start, limit string, opts ...storage.IteratorOption) storage.StoreIterator {
 	s.RLock()
-	data := s.db
 	defer s.RUnlock()
 
 	var batch [][]string
 
-	for k, v := range data {
-		if strings.HasPrefix(k, start) {
+	now := time.Now()
+	end := rangeEnd(limit)
+
+	for k := range s.db {
+		if v, ok := s.lookup(k, now); ok && inRange(k, start, end) {
 			batch = append(batch, []string{k, string(v)})
 		}
 	}
 
-	return newMemIterator(batch)
+	options := storage.GetIteratorOptions(opts...)
+
+	sort.Slice(batch, func(i, j int) bool {
+		if options.Reverse {
+			return batch[i][0] > batch[j][0]
+		}
+
+		return batch[i][0] < batch[j][0]
+	})
+
+	itr := newMemIterator(batch)
+	itr.reverse = options.Reverse
+	itr.limit = options.Limit
+
+	return itr
+}
+
+// IteratorAll returns an iterator over all the records of the store, ie the range of two empty keys.
+func (s *memStore) IteratorAll(opts ...storage.IteratorOption) storage.StoreIterator {
+	return s.Iterator("", "", opts...)
+}
+
+// Count returns the number of records within the [start, limit) range, the whole store being counted when both keys
+// are empty.
+func (s *memStore) Count(start, limit string) (int, error) {
+	end := rangeEnd(limit)
+
+	s.RLock()
+	defer s.RUnlock()
+
+	count := 0
+	now := time.Now()
+
+	for k := range s.db {
+		if _, ok := s.lookup(k, now); ok && inRange(k, start, end) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// ApproxSizeBytes returns the sum of the lengths of the keys and values of the records which haven't expired
+func (s *memStore) ApproxSizeBytes() (int64, error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	var size int64
+
+	now := time.Now()
+
+	for k := range s.db {
+		if v, ok := s.lookup(k, now); ok {
+			size += int64(len(k) + len(v))
+		}
+	}
+
+	return size, nil
+}
+
+// ForEach calls fn with every key/value pair within the key range until fn returns stop or an error
+func (s *memStore) ForEach(start, limit string, fn func(key, value []byte) (bool, error)) error {
+	return storage.ForEach(s.Iterator(start, limit), fn)
 }
 
 // Delete will delete record with k key
 func (s *memStore) Delete(k string) error {
 	if k == "" {
-		return errors.New("key is mandatory")
+		return storage.ErrKeyRequired
 	}
 
 	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := s.lookup(k, time.Now()); !ok && s.strictDelete {
+		return storage.ErrDataNotFound
+	}
+
 	delete(s.db, k)
-	s.Unlock()
+	delete(s.expiry, k)
+	delete(s.tags, k)
 
 	return nil
 }
 
+// DeleteRange deletes the records within the [start, limit) range and returns the number of records deleted.
+func (s *memStore) DeleteRange(start, limit string) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	count := 0
+	now := time.Now()
+	end := rangeEnd(limit)
+
+	for k := range s.db {
+		if !inRange(k, start, end) {
+			continue
+		}
+
+		if _, ok := s.lookup(k, now); ok {
+			count++
+		}
+
+		delete(s.db, k)
+		delete(s.expiry, k)
+		delete(s.tags, k)
+	}
+
+	return count, nil
+}
+
+// rangeEnd returns the end key of the range to compare keys with, the storage.EndKeySuffix of limit being replaced.
+func rangeEnd(limit string) string {
+	return strings.ReplaceAll(limit, storage.EndKeySuffix, endKeySuffixReplacement)
+}
+
+// inRange checks whether key k is within the [start, end) range, an empty end leaving the range unbounded.
+func inRange(k, start, end string) bool {
+	return k >= start && (end == "" || k < end)
+}
+
+// clone copies the value so that the records can't be modified by the callers.
+func clone(v []byte) []byte {
+	c := make([]byte, len(v))
+	copy(c, v)
+
+	return c
+}
+
+// Begin is not supported by the mem store since it can't execute atomic transactions
+func (s *memStore) Begin(...storage.TxOption) (storage.Transaction, error) {
+	return nil, storage.ErrTransactionsNotSupported
+}
+
 type memIterator struct {
 	currentIndex int
 	currentItem  []string
 	items        [][]string
-	err          error
+	reverse      bool
+	// limit caps the number of items returned by Next and Seek, zero when unlimited
+	limit    int
+	returned int
+	err      error
+	// total is the number of items of the batch, kept after Release
+	total int
 }
 
 // NewMemIterator returns new mem iterator for given batch
@@ -169,11 +579,11 @@ func newMemIterator(batch [][]string) *memIterator {
 		return &memIterator{}
 	}
 
-	return &memIterator{items: batch}
+	return &memIterator{items: batch, total: len(batch)}
 }
 
 func (s *memIterator) isExhausted() bool {
-	return len(s.items) == 0 || len(s.items) == s.currentIndex
+	return len(s.items) == 0 || len(s.items) == s.currentIndex || (s.limit > 0 && s.returned == s.limit)
 }
 
 // Next moves pointer to next value of iterator.
@@ -185,10 +595,33 @@ func (s *memIterator) Next() bool {
 
 	s.currentItem = s.items[s.currentIndex]
 	s.currentIndex++
+	s.returned++
 
 	return true
 }
 
+// Seek moves pointer to the first value whose key is at or after key in the iteration order.
+// It returns false if no such key exists.
+func (s *memIterator) Seek(key string) bool {
+	if s.limit > 0 && s.returned == s.limit {
+		return false
+	}
+
+	// items are sorted in the iteration order
+	i := sort.Search(len(s.items), func(j int) bool {
+		if s.reverse {
+			return s.items[j][0] <= key
+		}
+
+		return s.items[j][0] >= key
+	})
+
+	s.currentIndex = i
+	s.currentItem = nil
+
+	return s.Next()
+}
+
 // Release releases associated resources.
 func (s *memIterator) Release() {
 	s.currentIndex = 0
@@ -196,6 +629,11 @@ func (s *memIterator) Release() {
 	s.currentItem = make([]string, 0)
 }
 
+// TotalCount returns the number of records of the iterator snapshot, regardless of its limit.
+func (s *memIterator) TotalCount() (int, error) {
+	return s.total, nil
+}
+
 // Error returns error in iterator.
 func (s *memIterator) Error() error {
 	return s.err
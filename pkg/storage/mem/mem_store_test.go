@@ -7,11 +7,16 @@ SPDX-License-Identifier: Apache-2.0
 package mem
 
 import (
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/storagetest"
 )
 
 func TestMemStore(t *testing.T) {
@@ -198,6 +203,73 @@ func TestMemStoreIterator(t *testing.T) {
 		require.Equal(t, len(rawData), count)
 	})
 
+	t.Run("Test mem store iterator - key order", func(t *testing.T) {
+		prov := NewProvider()
+		store, err := prov.OpenStore("test-order")
+		require.NoError(t, err)
+
+		for _, k := range []string{"abc_125", "abc_123", "abc_126", "abc_124"} {
+			require.NoError(t, store.Put(k, []byte("value-"+k)))
+		}
+
+		verifyItrKeys(t, store.Iterator("abc_", "abc_"+storage.EndKeySuffix),
+			"abc_123", "abc_124", "abc_125", "abc_126")
+
+		verifyItrKeys(t, store.Iterator("abc_", "abc_"+storage.EndKeySuffix, storage.WithReverse()),
+			"abc_126", "abc_125", "abc_124", "abc_123")
+
+		verifyItrKeys(t, store.Iterator("abc_", "abc_"+storage.EndKeySuffix, storage.WithLimit(2)),
+			"abc_123", "abc_124")
+
+		verifyItrKeys(t, store.Iterator("abc_", "abc_"+storage.EndKeySuffix, storage.WithReverse(), storage.WithLimit(2)),
+			"abc_126", "abc_125")
+
+		itr := store.Iterator("abc_", "abc_"+storage.EndKeySuffix)
+		require.True(t, itr.Seek("abc_125"))
+		require.Equal(t, "abc_125", string(itr.Key()))
+		verifyItrKeys(t, itr, "abc_126")
+
+		itr = store.Iterator("abc_", "abc_"+storage.EndKeySuffix)
+		require.True(t, itr.Seek("abc_1245"))
+		require.Equal(t, "abc_125", string(itr.Key()))
+		require.False(t, itr.Seek("abc_127"))
+		require.Nil(t, itr.Key())
+
+		itr = store.Iterator("abc_", "abc_"+storage.EndKeySuffix, storage.WithReverse())
+		require.True(t, itr.Seek("abc_1245"))
+		require.Equal(t, "abc_124", string(itr.Key()))
+		verifyItrKeys(t, itr, "abc_123")
+
+		itr = store.Iterator("abc_", "abc_"+storage.EndKeySuffix, storage.WithLimit(2))
+		require.True(t, itr.Seek("abc_125"))
+		verifyItrKeys(t, itr, "abc_126")
+	})
+
+	t.Run("Test mem store iterator - total count", func(t *testing.T) {
+		prov := NewProvider()
+		store, err := prov.OpenStore("test-total-count")
+		require.NoError(t, err)
+
+		for _, k := range []string{"abc_123", "abc_124", "abc_125", "xyz_123"} {
+			require.NoError(t, store.Put(k, []byte("value-"+k)))
+		}
+
+		itr := store.Iterator("abc_", "abc_"+storage.EndKeySuffix, storage.WithLimit(2))
+		total, err := itr.TotalCount()
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+
+		// the total count doesn't depend on the pairs already reached
+		verifyItrKeys(t, itr, "abc_123", "abc_124")
+		total, err = itr.TotalCount()
+		require.NoError(t, err)
+		require.Equal(t, 3, total)
+
+		total, err = store.Iterator("mno_", "mno_"+storage.EndKeySuffix).TotalCount()
+		require.NoError(t, err)
+		require.Zero(t, total)
+	})
+
 	t.Run("Test mem store iterator - no data in iterator", func(t *testing.T) {
 		// no data from iterator
 		prov := NewProvider()
@@ -244,4 +316,660 @@ func TestMemStoreDelete(t *testing.T) {
 	doc, err = store1.Get(commonKey)
 	require.EqualError(t, err, storage.ErrDataNotFound.Error())
 	require.Empty(t, doc)
+
+	// deleting a missing key succeeds by default
+	err = store1.Delete(commonKey)
+	require.NoError(t, err)
+}
+
+func TestMemStoreStrictDelete(t *testing.T) {
+	prov := NewProvider(WithStrictDelete())
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key1", []byte("value1")))
+	require.NoError(t, store.Delete("key1"))
+	require.True(t, errors.Is(store.Delete("key1"), storage.ErrDataNotFound))
+
+	expiringStore, ok := store.(storage.ExpiringStore)
+	require.True(t, ok)
+	require.NoError(t, expiringStore.PutWithExpiry("key2", []byte("value2"), time.Nanosecond))
+
+	time.Sleep(time.Millisecond)
+
+	require.True(t, errors.Is(store.Delete("key2"), storage.ErrDataNotFound))
+}
+
+func TestMemStoreDeleteRange(t *testing.T) {
+	prov := NewProvider()
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	rangeDeleter, ok := store.(storage.RangeDeleter)
+	require.True(t, ok)
+
+	for _, k := range []string{"abc_123", "abc_124", "jkl_123"} {
+		require.NoError(t, store.Put(k, []byte("value-"+k)))
+	}
+
+	expiringStore, ok := store.(storage.ExpiringStore)
+	require.True(t, ok)
+	require.NoError(t, expiringStore.PutWithExpiry("abc_125", []byte("value"), time.Nanosecond))
+
+	time.Sleep(time.Millisecond)
+
+	deleted, err := rangeDeleter.DeleteRange("abc_", "abc_"+storage.EndKeySuffix)
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+
+	itr := store.Iterator("", "")
+	verifyItrKeys(t, itr, "jkl_123")
+}
+
+func TestMemStorePutBatch(t *testing.T) {
+	prov := NewProvider()
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	err = store.Put("key1", []byte("old-value1"))
+	require.NoError(t, err)
+
+	err = store.PutBatch([]storage.KeyValue{
+		{Key: "key1", Value: []byte("value1")},
+		{Key: "key2", Value: []byte("value2")},
+	})
+	require.NoError(t, err)
+
+	for k, v := range map[string]string{"key1": "value1", "key2": "value2"} {
+		doc, e := store.Get(k)
+		require.NoError(t, e)
+		require.Equal(t, []byte(v), doc)
+	}
+
+	// an empty key fails the whole batch before anything is written
+	err = store.PutBatch([]storage.KeyValue{
+		{Key: "key3", Value: []byte("value3")},
+		{Key: "", Value: []byte("value4")},
+	})
+	require.Equal(t, storage.ErrKeyRequired, err)
+
+	_, err = store.Get("key3")
+	require.Equal(t, storage.ErrDataNotFound, err)
+
+	// empty batch
+	err = store.PutBatch(nil)
+	require.NoError(t, err)
+}
+
+func TestMemStoreGetBulk(t *testing.T) {
+	prov := NewProvider()
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key1", []byte("value1")))
+	require.NoError(t, store.Put("key3", []byte("value3")))
+
+	values, err := store.GetBulk("key3", "key2", "key1")
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("value3"), nil, []byte("value1")}, values)
+
+	values, err = store.GetBulk()
+	require.NoError(t, err)
+	require.Empty(t, values)
+
+	_, err = store.GetBulk("key1", "")
+	require.EqualError(t, err, "key is mandatory")
+}
+
+func TestMemStoreNilAndEmptyValues(t *testing.T) {
+	store, err := NewProvider().OpenStore("test")
+	require.NoError(t, err)
+
+	require.Equal(t, storage.ErrNilValue, store.Put("key", nil))
+	require.Equal(t, storage.ErrNilValue, store.PutBatch([]storage.KeyValue{{Key: "key"}}))
+
+	_, err = store.PutIfMatch("key", nil, nil)
+	require.Equal(t, storage.ErrNilValue, err)
+
+	require.NoError(t, store.Put("key", []byte{}))
+
+	v, err := store.Get("key")
+	require.NoError(t, err)
+	require.NotNil(t, v)
+	require.Empty(t, v)
+
+	values, err := store.GetBulk("key", "missing")
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{{}, nil}, values)
+}
+
+func TestMemStoreHas(t *testing.T) {
+	prov := NewProvider()
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key1", []byte("value1")))
+
+	ok, err := store.Has("key1")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = store.Has("key2")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = store.Has("")
+	require.EqualError(t, err, "key is mandatory")
+}
+
+func TestMemStoreCount(t *testing.T) {
+	prov := NewProvider()
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	for _, k := range []string{"abc_123", "abc_124", "jkl_123"} {
+		require.NoError(t, store.Put(k, []byte("value-"+k)))
+	}
+
+	count, err := store.Count("abc_", "abc_"+storage.EndKeySuffix)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	count, err = store.Count("", "")
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+}
+
+func TestMemStorePutIfMatch(t *testing.T) {
+	prov := NewProvider()
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	ok, err := store.PutIfMatch("key1", nil, []byte("value1"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// create-only fails once the key exists
+	ok, err = store.PutIfMatch("key1", nil, []byte("value2"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = store.PutIfMatch("key1", []byte("other"), []byte("value2"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = store.PutIfMatch("key1", []byte("value1"), []byte("value2"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	v, err := store.Get("key1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), v)
+
+	ok, err = store.PutIfMatch("key2", []byte("value1"), []byte("value2"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = store.PutIfMatch("", nil, []byte("value1"))
+	require.Error(t, err)
+}
+
+func TestMemStoreBegin(t *testing.T) {
+	prov := NewProvider()
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	txStore, ok := store.(storage.Transactional)
+	require.True(t, ok)
+
+	tx, err := txStore.Begin()
+	require.Equal(t, storage.ErrTransactionsNotSupported, err)
+	require.Nil(t, tx)
+}
+
+func verifyItrKeys(t *testing.T, itr storage.StoreIterator, keys ...string) {
+	var actual []string
+
+	for itr.Next() {
+		actual = append(actual, string(itr.Key()))
+	}
+
+	require.Equal(t, keys, actual)
+
+	itr.Release()
+}
+
+func TestMemStorePutWithExpiry(t *testing.T) {
+	prov := NewProvider()
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	expiringStore, ok := store.(storage.ExpiringStore)
+	require.True(t, ok)
+
+	t.Run("expired records are not found", func(t *testing.T) {
+		require.NoError(t, expiringStore.PutWithExpiry("expiring1", []byte("value1"), time.Millisecond))
+		require.NoError(t, expiringStore.PutWithExpiry("expiring2", []byte("value2"), time.Hour))
+
+		v, err := store.Get("expiring2")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value2"), v)
+
+		time.Sleep(10 * time.Millisecond)
+
+		_, err = store.Get("expiring1")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		found, err := store.Has("expiring1")
+		require.NoError(t, err)
+		require.False(t, found)
+
+		values, err := store.GetBulk("expiring1", "expiring2")
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{nil, []byte("value2")}, values)
+
+		count, err := store.Count("expiring", "")
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+
+		itr := store.Iterator("expiring", "")
+		require.True(t, itr.Next())
+		require.Equal(t, []byte("expiring2"), itr.Key())
+		require.False(t, itr.Next())
+
+		stored, err := store.PutIfMatch("expiring1", nil, []byte("value3"))
+		require.NoError(t, err)
+		require.True(t, stored)
+	})
+
+	t.Run("put removes the expiry", func(t *testing.T) {
+		require.NoError(t, expiringStore.PutWithExpiry("expiring3", []byte("value"), time.Millisecond))
+		require.NoError(t, store.Put("expiring3", []byte("value")))
+
+		time.Sleep(10 * time.Millisecond)
+
+		v, err := store.Get("expiring3")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value"), v)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		err := expiringStore.PutWithExpiry("expiring4", []byte("value"), 0)
+		require.True(t, errors.Is(err, storage.ErrInvalidTTL))
+
+		require.Error(t, expiringStore.PutWithExpiry("", []byte("value"), time.Hour))
+	})
+}
+
+func TestMemStoreQueryByTag(t *testing.T) {
+	prov := NewProvider()
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	taggedStore, ok := store.(storage.TaggedStore)
+	require.True(t, ok)
+
+	require.NoError(t, taggedStore.PutWithTags("vc2", []byte("value2"), map[string]string{"issuer": "did:example:1"}))
+	require.NoError(t, taggedStore.PutWithTags("vc1", []byte("value1"),
+		map[string]string{"issuer": "did:example:1", "type": "UniversityDegreeCredential"}))
+	require.NoError(t, taggedStore.PutWithTags("vc3", []byte("value3"), map[string]string{"issuer": "did:example:2"}))
+	require.NoError(t, store.Put("vc4", []byte("value4")))
+
+	itr, err := taggedStore.QueryByTag("issuer", "did:example:1")
+	require.NoError(t, err)
+	verifyItrKeys(t, itr, "vc1", "vc2")
+
+	t.Run("put keeps the tags", func(t *testing.T) {
+		require.NoError(t, store.Put("vc3", []byte("updated")))
+
+		itr, err := taggedStore.QueryByTag("issuer", "did:example:2")
+		require.NoError(t, err)
+		require.True(t, itr.Next())
+		require.Equal(t, []byte("updated"), itr.Value())
+		require.False(t, itr.Next())
+	})
+
+	t.Run("put with tags replaces the tags", func(t *testing.T) {
+		require.NoError(t, taggedStore.PutWithTags("vc2", []byte("value2"), map[string]string{"issuer": "did:example:2"}))
+
+		itr, err := taggedStore.QueryByTag("issuer", "did:example:1")
+		require.NoError(t, err)
+		verifyItrKeys(t, itr, "vc1")
+	})
+
+	t.Run("delete removes the tags", func(t *testing.T) {
+		require.NoError(t, store.Delete("vc1"))
+		require.NoError(t, store.Put("vc1", []byte("value1")))
+
+		itr, err := taggedStore.QueryByTag("type", "UniversityDegreeCredential")
+		require.NoError(t, err)
+		require.False(t, itr.Next())
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := taggedStore.QueryByTag("", "value")
+		require.Error(t, err)
+
+		require.Error(t, taggedStore.PutWithTags("", []byte("value"), nil))
+	})
+}
+
+func TestMemStoreForEach(t *testing.T) {
+	prov := NewProvider()
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	for _, k := range []string{"abc_124", "abc_123", "abc_125", "jkl_123"} {
+		require.NoError(t, store.Put(k, []byte("value-"+k)))
+	}
+
+	t.Run("all the records of the range", func(t *testing.T) {
+		var keys []string
+
+		err := store.ForEach("abc_", "abc_"+storage.EndKeySuffix, func(key, value []byte) (bool, error) {
+			require.Equal(t, "value-"+string(key), string(value))
+
+			keys = append(keys, string(key))
+
+			return false, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"abc_123", "abc_124", "abc_125"}, keys)
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		var keys []string
+
+		err := store.ForEach("abc_", "abc_"+storage.EndKeySuffix, func(key, value []byte) (bool, error) {
+			keys = append(keys, string(key))
+
+			return len(keys) == 2, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"abc_123", "abc_124"}, keys)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		fnErr := errors.New("fn error")
+
+		err := store.ForEach("abc_", "abc_"+storage.EndKeySuffix, func(key, value []byte) (bool, error) {
+			return false, fnErr
+		})
+		require.Equal(t, fnErr, err)
+	})
+}
+
+func TestMemProviderStoreNames(t *testing.T) {
+	prov := NewProvider()
+
+	names, err := prov.StoreNames()
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	for _, name := range []string{"store2", "Store1", "store3"} {
+		_, err = prov.OpenStore(name)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, prov.CloseStore("store3"))
+
+	var lister storage.StoreLister = prov
+
+	names, err = lister.StoreNames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"store1", "store2"}, names)
+}
+
+func TestMemProviderOpenExistingStore(t *testing.T) {
+	prov := NewProvider()
+
+	var opener storage.ExistingStoreOpener = prov
+
+	_, err := opener.OpenExistingStore("store1")
+	require.True(t, errors.Is(err, storage.ErrStoreNotFound))
+
+	store, err := prov.OpenStore("store1")
+	require.NoError(t, err)
+
+	existingStore, err := opener.OpenExistingStore("Store1")
+	require.NoError(t, err)
+	require.Same(t, store, existingStore)
+
+	require.NoError(t, prov.CloseStore("store1"))
+
+	_, err = opener.OpenExistingStore("store1")
+	require.True(t, errors.Is(err, storage.ErrStoreNotFound))
+}
+
+func TestMemProviderTruncate(t *testing.T) {
+	prov := NewProvider()
+
+	var truncater storage.Truncater = prov
+
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("k1", []byte("v1")))
+	require.NoError(t, store.(storage.TaggedStore).PutWithTags("k2", []byte("v2"), map[string]string{"tag": "v"}))
+	require.NoError(t, store.(storage.ExpiringStore).PutWithExpiry("k3", []byte("v3"), time.Hour))
+
+	otherStore, err := prov.OpenStore("other")
+	require.NoError(t, err)
+	require.NoError(t, otherStore.Put("k1", []byte("v1")))
+
+	require.NoError(t, truncater.Truncate("Test"))
+
+	count, err := store.Count("", "~")
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	itr, err := store.(storage.TaggedStore).QueryByTag("tag", "v")
+	require.NoError(t, err)
+	require.False(t, itr.Next())
+	itr.Release()
+
+	// the store stays open and the other stores are kept
+	require.NoError(t, store.Put("k1", []byte("v1")))
+
+	sameStore, err := prov.OpenExistingStore("test")
+	require.NoError(t, err)
+	require.Same(t, store, sameStore)
+
+	v, err := otherStore.Get("k1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("v1"), v)
+
+	t.Run("test missing store", func(t *testing.T) {
+		require.NoError(t, prov.Truncate("missing"))
+
+		names, err := prov.StoreNames()
+		require.NoError(t, err)
+		require.NotContains(t, names, "missing")
+
+		err = NewProvider(WithStrictDelete()).Truncate("missing")
+		require.True(t, errors.Is(err, storage.ErrStoreNotFound))
+	})
+}
+
+func TestMemStoreApproxSizeBytes(t *testing.T) {
+	prov := NewProvider()
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	sizeEstimator, ok := store.(storage.SizeEstimator)
+	require.True(t, ok)
+
+	size, err := sizeEstimator.ApproxSizeBytes()
+	require.NoError(t, err)
+	require.Zero(t, size)
+
+	require.NoError(t, store.Put("key1", []byte("value1")))
+	require.NoError(t, store.Put("k2", []byte("v2")))
+
+	expiringStore, ok := store.(storage.ExpiringStore)
+	require.True(t, ok)
+	require.NoError(t, expiringStore.PutWithExpiry("key3", []byte("value3"), time.Nanosecond))
+
+	time.Sleep(time.Millisecond)
+
+	// the expired record isn't counted
+	size, err = sizeEstimator.ApproxSizeBytes()
+	require.NoError(t, err)
+	require.Equal(t, int64(len("key1value1k2v2")), size)
+
+	require.NoError(t, store.Delete("key1"))
+
+	size, err = sizeEstimator.ApproxSizeBytes()
+	require.NoError(t, err)
+	require.Equal(t, int64(len("k2v2")), size)
+}
+
+func TestMemStoreRange(t *testing.T) {
+	prov := NewProvider()
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	for _, k := range []string{"abc_123", "abc_124", "abc_125", "abd_123", "jkl_123"} {
+		require.NoError(t, store.Put(k, []byte("value-"+k)))
+	}
+
+	// the end key is excluded from the range
+	verifyItrKeys(t, store.Iterator("abc_124", "abd_123"), "abc_124", "abc_125")
+	verifyItrKeys(t, store.Iterator("abc_", "abd"+storage.EndKeySuffix), "abc_123", "abc_124", "abc_125", "abd_123")
+	verifyItrKeys(t, store.Iterator("abd_", ""), "abd_123", "jkl_123")
+
+	count, err := store.Count("abc_124", "abd_123")
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	count, err = store.Count("", "")
+	require.NoError(t, err)
+	require.Equal(t, 5, count)
+
+	deleted, err := store.(storage.RangeDeleter).DeleteRange("abc_124", "jkl_123")
+	require.NoError(t, err)
+	require.Equal(t, 3, deleted)
+
+	verifyItrKeys(t, store.Iterator("", ""), "abc_123", "jkl_123")
+	verifyItrKeys(t, store.(storage.FullIterator).IteratorAll(storage.WithReverse()), "jkl_123", "abc_123")
+
+	t.Run("released iterator", func(t *testing.T) {
+		itr := store.Iterator("", "")
+		require.True(t, itr.Next())
+
+		itr.Release()
+
+		require.False(t, itr.Next())
+		require.Nil(t, itr.Key())
+		require.Nil(t, itr.Value())
+		require.NoError(t, itr.Error())
+	})
+}
+
+func TestMemStoreValuesAreCopied(t *testing.T) {
+	store, err := NewProvider().OpenStore("test")
+	require.NoError(t, err)
+
+	v := []byte("value")
+	require.NoError(t, store.Put("key", v))
+
+	v[0] = 'V'
+
+	stored, err := store.Get("key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), stored)
+
+	stored[0] = 'V'
+
+	stored, err = store.Get("key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), stored)
+}
+
+func TestMemProviderDBPrefix(t *testing.T) {
+	prov := NewProvider(WithDBPrefix("prefixdb"))
+
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+	require.NoError(t, store.Put("key", []byte("value")))
+
+	sameStore, err := prov.OpenStore("Test")
+	require.NoError(t, err)
+	require.Same(t, store, sameStore)
+
+	names, err := prov.StoreNames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"test"}, names)
+
+	require.NoError(t, prov.CloseStore("test"))
+
+	names, err = prov.StoreNames()
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	t.Run("test empty and trimmed prefixes", func(t *testing.T) {
+		require.Equal(t, NewProvider().dbName("test"), NewProvider(WithDBPrefix("")).dbName("test"))
+		require.Equal(t, "test", NewProvider(WithDBPrefix("_")).dbName("test"))
+		require.Equal(t, prov.dbName("test"), NewProvider(WithDBPrefix("prefixdb_")).dbName("test"))
+	})
+}
+
+func TestMemStoreConcurrency(t *testing.T) {
+	prov := NewProvider()
+
+	const routines = 10
+
+	var wg sync.WaitGroup
+
+	stores := make([]storage.Store, routines)
+	errs := make(chan error, routines)
+
+	for i := 0; i < routines; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			store, err := prov.OpenStore("test")
+			if err != nil {
+				errs <- err
+
+				return
+			}
+
+			stores[i] = store
+
+			if err = store.Put(fmt.Sprintf("key_%d", i), []byte("value")); err != nil {
+				errs <- err
+
+				return
+			}
+
+			errs <- store.ForEach("key_", "key_"+storage.EndKeySuffix, func(key, value []byte) (bool, error) {
+				return false, nil
+			})
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	// all the routines opened the same store
+	for _, store := range stores {
+		require.Same(t, stores[0], store)
+	}
+
+	count, err := stores[0].Count("key_", "key_"+storage.EndKeySuffix)
+	require.NoError(t, err)
+	require.Equal(t, routines, count)
+}
+
+func TestMemStoreConformance(t *testing.T) {
+	storagetest.RunSuite(t, func() (storage.Provider, error) {
+		return NewProvider(), nil
+	})
 }
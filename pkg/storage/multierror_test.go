@@ -0,0 +1,43 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestMultiError(t *testing.T) {
+	errClose := errors.New("close error")
+	pathErr := &os.PathError{Op: "close", Path: "store", Err: errors.New("path error")}
+
+	errs := storage.MultiError{fmt.Errorf("store1: %w", errClose), pathErr}
+
+	err := fmt.Errorf("failed to close provider: %w", errs)
+	require.EqualError(t, err, "failed to close provider: store1: close error; close store: path error")
+
+	require.True(t, errors.Is(err, errClose))
+	require.False(t, errors.Is(err, storage.ErrDataNotFound))
+
+	var target *os.PathError
+	require.True(t, errors.As(err, &target))
+	require.Equal(t, pathErr, target)
+
+	var multiErr storage.MultiError
+	require.True(t, errors.As(err, &multiErr))
+	require.Len(t, multiErr, 2)
+
+	require.Equal(t, errs, errs.ErrorOrNil())
+	require.NoError(t, storage.MultiError{}.ErrorOrNil())
+	require.NoError(t, storage.MultiError(nil).ErrorOrNil())
+}
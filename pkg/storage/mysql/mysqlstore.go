@@ -6,30 +6,145 @@ SPDX-License-Identifier: Apache-2.0
 package mysql
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
 	"database/sql"
 	"errors"
 	"fmt"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"sort"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
-	// Add as per the documentation - https://github.com/go-sql-driver/mysql
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
 )
 
+var logger = log.New("aries-framework/storage/mysql")
+
+// charsetNamePattern matches the names of the MySQL character sets and collations
+var charsetNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// dbPrefixPattern matches the DB prefixes accepted by WithDBPrefix: a letter followed by letters, digits or underscores
+var dbPrefixPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+var (
+	_ storage.StoreLister  = (*Provider)(nil)
+	_ storage.ContextStore = (*sqlDBStore)(nil)
+	_ storage.RangeDeleter = (*sqlDBStore)(nil)
+	_ storage.FullIterator = (*sqlDBStore)(nil)
+)
+
 // Provider represents a MySQL DB implementation of the storage.Provider interface
 type Provider struct {
 	dbURL    string
 	db       *sql.DB
 	dbs      map[string]*sqlDBStore
 	dbPrefix string
+	// poolSettings are applied to the connection pool of the provider and to the pool of its read replica
+	poolSettings []func(db *sql.DB)
+	// tlsConfigName and tlsConfig are registered with the driver and referenced by the DB URL
+	tlsConfigName string
+	tlsConfig     *tls.Config
+	// readReplicaURL is the DB URL of the read replica of the stores, if any
+	readReplicaURL string
+	retry          retryPolicy
+	// ownsDB is false when the connection pool is managed by the caller, in which case it's never closed by the
+	// provider
+	ownsDB bool
+	// readDB is the connection pool of the read replica shared by all the stores, or db without read replica
+	readDB *sql.DB
+	// valueColumnType and keyColumnSize set the schema of the tables created by OpenStore
+	valueColumnType string
+	keyColumnSize   int
+	// charset and collation are the character set and the collation of the tables created by OpenStore
+	charset   string
+	collation string
+	// tableNameFunc maps store names to table names, table names default to the t_ prefixed DB name of the store
+	tableNameFunc func(storeName string) string
+	// strictDelete makes the stores return storage.ErrDataNotFound when deleting a missing key
+	strictDelete bool
+	// softDelete makes the stores leave tombstones in place of the deleted records
+	softDelete bool
+	// iteratorLeakWarning makes the iterators of the stores log where they were created when their finalizer closes
+	// their rows
+	iteratorLeakWarning bool
+	// iteratorPageSize is the number of rows queried at once by the iterators of the stores, they query all their rows
+	// at once when it isn't positive
+	iteratorPageSize int
+	// operationTimeout bounds the duration of the operations of the stores, they are unbounded when it isn't positive
+	operationTimeout time.Duration
+	// limits are the maximum sizes of the keys and the values written to the stores
+	limits sizeLimits
+	// statementLogger logs the statements of the connection pools opened by the provider, slowQueryThreshold is the
+	// duration above which they are logged as slow
+	statementLogger    log.Logger
+	slowQueryThreshold time.Duration
+	// expiryCleanupInterval is the period of the deletion of the expired records of the stores, the cleanup is
+	// disabled when it isn't positive
+	expiryCleanupInterval time.Duration
+	stopCleanup           chan struct{}
+	cleanupDone           chan struct{}
+	stopCleanupOnce       sync.Once
+	// closed is set by Close, closing the provider again is a no-op
+	closed bool
 	sync.RWMutex
 }
 
 type sqlDBStore struct {
-	db        *sql.DB
+	db *sql.DB
+	// readDB is the connection pool of the reads tolerating a replication lag, the pool of the read replica when the
+	// provider has one or db otherwise
+	readDB    *sql.DB
 	tableName string
+	// tagsTableName is the companion table holding the tags of the records stored with PutWithTags
+	tagsTableName string
+	// dbName and unquotedTableName identify the tables of the store in information_schema
+	dbName            string
+	unquotedTableName string
+	// endKeySuffixReplacement replaces storage.EndKeySuffix in the end key of ranges, depending on the collation of
+	// the key column
+	endKeySuffixReplacement string
+	retry                   retryPolicy
+	strictDelete            bool
+	// softDelete is set by the provider option WithSoftDelete
+	softDelete bool
+	// iteratorLeakWarning is set by the provider option WithIteratorLeakWarning
+	iteratorLeakWarning bool
+	// iteratorPageSize is set by the provider option WithIteratorPageSize
+	iteratorPageSize int
+	// operationTimeout is set by the provider option WithOperationTimeout
+	operationTimeout time.Duration
+	// jsonColumn is set when the value column has the JSON type, ie when the table was created with WithJSONColumn
+	jsonColumn bool
+	// limits are set by the provider options WithMaxKeySize and WithMaxValueSize
+	limits sizeLimits
+}
+
+// sizeLimits are the maximum sizes in bytes of the keys and the values written to a store, a zero limit isn't enforced
+type sizeLimits struct {
+	maxKeySize   int
+	maxValueSize int
+}
+
+// check returns storage.ErrKeyTooLarge or storage.ErrValueTooLarge when the key or the value exceeds its limit.
+func (l sizeLimits) check(k string, v []byte) error {
+	if l.maxKeySize > 0 && len(k) > l.maxKeySize {
+		return storage.ErrKeyTooLarge
+	}
+
+	if l.maxValueSize > 0 && len(v) > l.maxValueSize {
+		return storage.ErrValueTooLarge
+	}
+
+	return nil
 }
 
 type result struct {
@@ -43,16 +158,223 @@ const (
 	tablePrefix               = "t_"
 	sqlDBNotFound             = "no rows"
 	createDBQuery             = "CREATE DATABASE IF NOT EXISTS "
-	useDBQuery                = "USE "
+	tagsTableSuffix           = "_tags"
+	// errDuplicateEntry is the MySQL error number of ER_DUP_ENTRY
+	errDuplicateEntry = 1062
+	// maxBatchRows caps the number of rows sent in a single multi-row statement to stay well under the
+	// placeholders limit of prepared statements
+	maxBatchRows = 1000
+	// unexpiredRowCondition filters out the expired records, which are only deleted periodically
+	unexpiredRowCondition = "(`expires_at` IS NULL OR `expires_at` > NOW(6))"
+	// liveRowCondition filters out the expired records and the tombstones of the deleted records
+	liveRowCondition = "(" + unexpiredRowCondition + " AND `deleted_at` IS NULL)"
+)
+
+const (
+	defaultValueColumnType = "BLOB"
+	// jsonValueColumnType is the value column type of the stores of the providers created with WithJSONColumn
+	jsonValueColumnType  = "JSON"
+	defaultKeyColumnSize = 255
+	// defaultCharset and defaultCollation compare the keys by their UTF-8 bytes, like the other storage backends
+	defaultCharset   = "utf8mb4"
+	defaultCollation = "utf8mb4_bin"
+	// binaryEndKeySuffixReplacement replaces storage.EndKeySuffix in the end key of ranges when keys are compared by
+	// their bytes, it sorts after the other printable ASCII characters like for the leveldb stores
+	binaryEndKeySuffixReplacement = "~"
+	// collatedEndKeySuffixReplacement replaces storage.EndKeySuffix in the end key of ranges when keys are compared
+	// with a non binary collation, as for the tables created with the default collation of the server
+	// reference : https://dev.mysql.com/doc/refman/8.0/en/fulltext-boolean.html
+	collatedEndKeySuffixReplacement = "*"
+	// maxKeyColumnSize keeps the key primary index under the 3072 bytes InnoDB limit with 4 bytes utf8mb4 characters
+	maxKeyColumnSize = 768
+	// defaultExpiryCleanupInterval is the period of the deletion of the expired records
+	defaultExpiryCleanupInterval = time.Minute
+	// maxIdentifierLength is the maximum length in characters of the MySQL database and table names
+	maxIdentifierLength = 64
+	// maxDBPrefixLength leaves room in the tags table names for the t_ prefix, the separator of the DB prefix, a one
+	// character store name and the tags table suffix
+	maxDBPrefixLength = maxIdentifierLength - len(tablePrefix) - len("_") - 1 - len(tagsTableSuffix)
+	// defaultMaxKeySize is the 3072 bytes InnoDB limit of the key primary index
+	defaultMaxKeySize = 3072
+	// defaultMaxValueSize keeps the insert statements under the 64MB default max_allowed_packet of MySQL 8, leaving
+	// room for the statement and the key
+	defaultMaxValueSize = 64<<20 - 64<<10
 )
 
 // Option configures the couchdb provider
 type Option func(opts *Provider)
 
-// WithDBPrefix option is for adding prefix to db name
+// WithDBPrefix option is for adding prefix to db name. The prefix only applies to the names of the databases and of
+// the tables of the stores, keys are stored and returned as given so keys already embedding a namespace are read as is.
+// The prefix must start with a letter followed by letters, digits or underscores and be at most 55 characters long so
+// that the table names of the stores fit in the 64 characters of MySQL identifiers once trimmed with
+// storage.TrimDBPrefix.
 func WithDBPrefix(dbPrefix string) Option {
 	return func(opts *Provider) {
-		opts.dbPrefix = dbPrefix
+		opts.dbPrefix = storage.TrimDBPrefix(dbPrefix)
+	}
+}
+
+// WithMaxOpenConns option sets the maximum number of open connections of every connection pool of the provider
+func WithMaxOpenConns(n int) Option {
+	return func(opts *Provider) {
+		opts.poolSettings = append(opts.poolSettings, func(db *sql.DB) {
+			db.SetMaxOpenConns(n)
+		})
+	}
+}
+
+// WithMaxIdleConns option sets the maximum number of idle connections of every connection pool of the provider
+func WithMaxIdleConns(n int) Option {
+	return func(opts *Provider) {
+		opts.poolSettings = append(opts.poolSettings, func(db *sql.DB) {
+			db.SetMaxIdleConns(n)
+		})
+	}
+}
+
+// WithConnMaxLifetime option sets the maximum amount of time a connection of any connection pool of the provider
+// may be reused
+func WithConnMaxLifetime(d time.Duration) Option {
+	return func(opts *Provider) {
+		opts.poolSettings = append(opts.poolSettings, func(db *sql.DB) {
+			db.SetConnMaxLifetime(d)
+		})
+	}
+}
+
+// WithValueColumnType option sets the SQL type of the value column of the tables created by OpenStore. It must be one
+// of BLOB (default), MEDIUMBLOB, LONGBLOB or JSON, see WithJSONColumn. Tables of existing stores are not altered.
+func WithValueColumnType(sqlType string) Option {
+	return func(opts *Provider) {
+		opts.valueColumnType = strings.ToUpper(sqlType)
+	}
+}
+
+// WithJSONColumn option makes OpenStore create tables with a JSON value column, so that the stores implement
+// storage.JSONStore by reading and updating the fields of their records with JSON_EXTRACT and JSON_SET. The values
+// stored must then be valid JSON documents, and are returned as normalized by MySQL rather than byte for byte.
+// Tables of existing stores are not altered.
+func WithJSONColumn() Option {
+	return func(opts *Provider) {
+		opts.valueColumnType = jsonValueColumnType
+	}
+}
+
+// WithKeyColumnSize option sets the maximum number of characters of the key column of the tables created by
+// OpenStore. It must be between 1 and 768, default is 255. Tables of existing stores are not altered.
+func WithKeyColumnSize(n int) Option {
+	return func(opts *Provider) {
+		opts.keyColumnSize = n
+	}
+}
+
+// WithCharset option sets the character set and the collation of the tables created by OpenStore, default is utf8mb4
+// with the utf8mb4_bin collation so that key ranges follow the byte order of the keys like for the other storage
+// backends. The collation may be empty to use the default collation of charset. Tables of existing stores are not
+// altered, the ranges of their stores following the collation of their key column.
+func WithCharset(charset, collation string) Option {
+	return func(opts *Provider) {
+		opts.charset = charset
+		opts.collation = collation
+	}
+}
+
+// WithTableNameFunc option sets the function mapping the names of the stores opened by the provider to the names of
+// their tables, ie to follow naming conventions of a shared MySQL instance. The store name is passed without the DB
+// prefix. Tables are named after the t_ prefixed DB name of their store by default.
+func WithTableNameFunc(tableNameFunc func(storeName string) string) Option {
+	return func(opts *Provider) {
+		opts.tableNameFunc = tableNameFunc
+	}
+}
+
+// WithExpiryCleanupInterval option sets how often the records stored with PutWithExpiry are deleted from the tables
+// once expired, default is every minute. The background cleanup is disabled when d isn't positive, expired records
+// are still never returned by the stores.
+func WithExpiryCleanupInterval(d time.Duration) Option {
+	return func(opts *Provider) {
+		opts.expiryCleanupInterval = d
+	}
+}
+
+// WithStrictDelete option makes the Delete of the stores return storage.ErrDataNotFound when no record of the key was
+// deleted, including when its record has expired. Deletes are idempotent by default.
+func WithStrictDelete() Option {
+	return func(opts *Provider) {
+		opts.strictDelete = true
+	}
+}
+
+// WithSoftDelete option makes the Delete and DeleteRange of the stores leave a tombstone in place of the deleted
+// records rather than removing them, ie so that a later sync with a remote agent can propagate the deletions. The
+// tombstones are handled as missing records by the stores, which implement storage.SoftDeleteStore to iterate over
+// the tombstones along with the records and to purge the old tombstones. The tags of the records are deleted along
+// with them. Storing a deleted key again replaces its tombstone.
+func WithSoftDelete() Option {
+	return func(opts *Provider) {
+		opts.softDelete = true
+	}
+}
+
+// WithIteratorLeakWarning option makes the stores log a warning with the stack trace of the creation of the iterators
+// that were neither released nor consumed to the end when they are garbage collected. The rows of such iterators are
+// always closed by a finalizer, returning their connection to the pool, the warning is meant to find the callers
+// missing a Release, ie in tests. It captures a stack trace for every iterator.
+func WithIteratorLeakWarning() Option {
+	return func(opts *Provider) {
+		opts.iteratorLeakWarning = true
+	}
+}
+
+// WithIteratorPageSize option makes the iterators of the stores query their rows by pages of n rows in the iteration
+// order, the next page being queried from the last key returned once a page is scanned (keyset pagination). The rows
+// of a page are read from the connection as the rows of an unpaged iterator, the connection is returned to the pool
+// between the pages so that long scans don't hold it. The pages are transparent to the callers of the iterators. The
+// iterators query all their rows at once by default, or when n isn't positive.
+func WithIteratorPageSize(n int) Option {
+	return func(opts *Provider) {
+		opts.iteratorPageSize = n
+	}
+}
+
+// WithOperationTimeout option bounds the duration of every operation of the stores, including its retries: the
+// statements of an operation are cancelled once d elapses and the operation fails with an error wrapping
+// context.DeadlineExceeded. The operations with a context are bounded by both d and their context. The timeout of an
+// iterator bounds its whole scan, from its creation to its release. Transactions aren't bounded as their operations
+// are made by the caller. Operations are unbounded by default, or when d isn't positive.
+func WithOperationTimeout(d time.Duration) Option {
+	return func(opts *Provider) {
+		opts.operationTimeout = d
+	}
+}
+
+// WithMaxKeySize option sets the maximum size in bytes of the keys written to the stores, default is 3072 bytes. Writes
+// of larger keys fail with storage.ErrKeyTooLarge before any statement is sent to the DB. The key column size bounds
+// the keys in characters regardless of this limit.
+func WithMaxKeySize(n int) Option {
+	return func(opts *Provider) {
+		opts.limits.maxKeySize = n
+	}
+}
+
+// WithMaxValueSize option sets the maximum size in bytes of the values written to the stores, default is 64MB less
+// 64KB so that the statements fit in the default max_allowed_packet of MySQL 8. Writes of larger values fail with
+// storage.ErrValueTooLarge before any statement is sent to the DB. The limit should be lowered along with the
+// max_allowed_packet of the server and the type of the value column, a BLOB column holding at most 64KB.
+func WithMaxValueSize(n int) Option {
+	return func(opts *Provider) {
+		opts.limits.maxValueSize = n
+	}
+}
+
+// WithTLSConfig option registers the given TLS config with the MySQL driver under the given name and adds the
+// matching tls parameter to the DB URL, keeping the TLS settings out of the connection string.
+// It only applies to providers created with NewProvider.
+func WithTLSConfig(name string, cfg *tls.Config) Option {
+	return func(opts *Provider) {
+		opts.tlsConfigName = name
+		opts.tlsConfig = cfg
 	}
 }
 
@@ -62,233 +384,1486 @@ func NewProvider(dbPath string, opts ...Option) (*Provider, error) {
 		return nil, errors.New(blankDBPathErrMsg)
 	}
 
-	// Example DB Path root:my-secret-pw@tcp(127.0.0.1:3306)/
-	db, err := sql.Open("mysql", dbPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open connection: %w", err)
-	}
-
 	p := &Provider{
-		dbURL: dbPath,
-		db:    db,
-		dbs:   map[string]*sqlDBStore{}}
+		dbURL:                 dbPath,
+		dbs:                   map[string]*sqlDBStore{},
+		ownsDB:                true,
+		valueColumnType:       defaultValueColumnType,
+		keyColumnSize:         defaultKeyColumnSize,
+		charset:               defaultCharset,
+		collation:             defaultCollation,
+		slowQueryThreshold:    defaultSlowQueryThreshold,
+		limits:                sizeLimits{maxKeySize: defaultMaxKeySize, maxValueSize: defaultMaxValueSize},
+		expiryCleanupInterval: defaultExpiryCleanupInterval}
 
 	for _, opt := range opts {
 		opt(p)
 	}
 
-	return p, nil
-}
+	if err := p.validateDBPrefix(); err != nil {
+		return nil, err
+	}
 
-// OpenStore opens and returns new db for given name space.
-func (p *Provider) OpenStore(name string) (storage.Store, error) {
-	p.Lock()
-	defer p.Unlock()
+	if err := p.validateColumnSettings(); err != nil {
+		return nil, err
+	}
 
-	if name == "" {
-		return nil, errors.New("store name is required")
+	if p.tlsConfig != nil {
+		if err := p.useTLSConfig(); err != nil {
+			return nil, err
+		}
 	}
 
-	if p.dbPrefix != "" {
-		name = p.dbPrefix + "_" + name
+	if p.readReplicaURL != "" {
+		if _, err := p.parseReadReplicaURL(); err != nil {
+			return nil, err
+		}
 	}
-	// creating the database
-	_, err := p.db.Exec(createDBQuery + name)
+
+	// Example DB Path root:my-secret-pw@tcp(127.0.0.1:3306)/
+	db, err := p.openDB(p.dbURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create db %s: %w", name, err)
+		return nil, fmt.Errorf("failed to open connection: %w", storage.RedactDSNError(err, p.dbURL))
 	}
 
-	// Opening new db connection
-	newDBConn, err := sql.Open("mysql", p.dbURL)
+	p.db = db
+	p.applyPoolSettings(db)
+
+	p.readDB, err = p.openReadDB()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create new connection %s: %w", p.dbURL, err)
+		if closeErr := db.Close(); closeErr != nil {
+			logger.Warnf("failed to close connection: %s", closeErr)
+		}
+
+		return nil, err
 	}
 
-	// Use query is used to select the created database without this DDL operations are not permitted
-	_, err = newDBConn.Exec(useDBQuery + name)
+	p.startExpiryCleanup()
+
+	return p, nil
+}
+
+// useTLSConfig registers the TLS config of the provider and references it in the DB URL.
+func (p *Provider) useTLSConfig() error {
+	dsnConfig, err := mysql.ParseDSN(p.dbURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to use db %s: %w", name, err)
+		return fmt.Errorf("failed to parse DB URL: %w", storage.RedactDSNError(err, p.dbURL))
 	}
 
-	tableName := tablePrefix + name
-	// TODO: Issue-1940 Store the hashed key to control the width of the key varchar column
-	createTableStmt := "CREATE Table IF NOT EXISTS " + tableName +
-		"(`key` varchar(255) NOT NULL ,`value` BLOB, PRIMARY KEY (`key`));"
+	if dsnConfig.TLSConfig != "" && dsnConfig.TLSConfig != p.tlsConfigName {
+		return fmt.Errorf("DB URL tls parameter %s conflicts with TLS config %s", dsnConfig.TLSConfig,
+			p.tlsConfigName)
+	}
 
-	// creating key-value table inside the database
-	_, err = newDBConn.Exec(createTableStmt)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create table %s: %w", tableName, err)
+	if err = mysql.RegisterTLSConfig(p.tlsConfigName, p.tlsConfig); err != nil {
+		return fmt.Errorf("failed to register TLS config %s: %w", p.tlsConfigName, err)
 	}
 
-	store := &sqlDBStore{
-		db:        newDBConn,
-		tableName: tableName}
+	if dsnConfig.TLSConfig == "" {
+		separator := "?"
+		if strings.Contains(p.dbURL, "?") {
+			separator = "&"
+		}
 
-	p.dbs[name] = store
+		p.dbURL += separator + "tls=" + p.tlsConfigName
+	}
 
-	return store, nil
+	return nil
 }
 
-// Close closes the provider.
-func (p *Provider) Close() error {
-	p.Lock()
-	defer p.Unlock()
+// NewProviderWithDB instantiates Provider on top of a connection pool managed by the caller. The pool is shared by
+// all the stores like the pool opened by NewProvider, but it isn't closed when the provider is closed. The read
+// replica option is ignored, the reads use the pool too.
+func NewProviderWithDB(db *sql.DB, opts ...Option) (*Provider, error) {
+	if db == nil {
+		return nil, errors.New("DB for new mySQL DB provider can't be nil")
+	}
 
-	for _, store := range p.dbs {
-		err := store.db.Close()
-		if err != nil {
-			return fmt.Errorf(failToCloseProviderErrMsg+": %w", err)
-		}
+	p := &Provider{
+		db:                    db,
+		readDB:                db,
+		dbs:                   map[string]*sqlDBStore{},
+		valueColumnType:       defaultValueColumnType,
+		keyColumnSize:         defaultKeyColumnSize,
+		charset:               defaultCharset,
+		collation:             defaultCollation,
+		limits:                sizeLimits{maxKeySize: defaultMaxKeySize, maxValueSize: defaultMaxValueSize},
+		expiryCleanupInterval: defaultExpiryCleanupInterval}
+
+	for _, opt := range opts {
+		opt(p)
 	}
 
-	if err := p.db.Close(); err != nil {
-		return err
+	if err := p.validateDBPrefix(); err != nil {
+		return nil, err
 	}
 
-	p.dbs = make(map[string]*sqlDBStore)
+	if err := p.validateColumnSettings(); err != nil {
+		return nil, err
+	}
 
-	return nil
-}
+	p.applyPoolSettings(db)
+	p.startExpiryCleanup()
 
-// CloseStore closes a previously opened store
-func (p *Provider) CloseStore(name string) error {
-	p.Lock()
-	defer p.Unlock()
+	return p, nil
+}
 
-	if p.dbPrefix != "" {
-		name = p.dbPrefix + "_" + name
+// validateDBPrefix validates the DB prefix of the provider before it is used in database and table names.
+func (p *Provider) validateDBPrefix() error {
+	if p.dbPrefix == "" {
+		return nil
 	}
 
-	store, exists := p.dbs[name]
-	if !exists {
-		return nil
+	if !dbPrefixPattern.MatchString(p.dbPrefix) {
+		return fmt.Errorf("invalid DB prefix %q: it must start with a letter followed by letters, digits or "+
+			"underscores", p.dbPrefix)
 	}
 
-	delete(p.dbs, name)
+	if len(p.dbPrefix) > maxDBPrefixLength {
+		return fmt.Errorf("invalid DB prefix %q: it must be at most %d characters long", p.dbPrefix,
+			maxDBPrefixLength)
+	}
 
-	return store.db.Close()
+	return nil
 }
 
-// Put stores the key and the value
-func (s *sqlDBStore) Put(k string, v []byte) error {
-	if k == "" {
-		return storage.ErrKeyRequired
+// validateColumnSettings validates the column settings of the provider before they are used in CREATE TABLE statements
+func (p *Provider) validateColumnSettings() error {
+	// the column type is part of the CREATE TABLE statement and can't be a query parameter, only allowlisted types are
+	// accepted
+	switch p.valueColumnType {
+	case "BLOB", "MEDIUMBLOB", "LONGBLOB", jsonValueColumnType:
+	default:
+		return fmt.Errorf("unsupported value column type %s", p.valueColumnType)
 	}
 
-	//nolint: gosec
-	// create upsert query to insert the record, checking whether the key is already mapped to a value in the store.
-	createStmt := "INSERT INTO " + s.tableName + " VALUES (?, ?) ON DUPLICATE KEY UPDATE value=?"
-	// executing the prepared insert statement
-	_, err := s.db.Exec(createStmt, k, v, v)
-	if err != nil {
-		return fmt.Errorf("failed to insert key and value record into %s %w ", s.tableName, err)
+	if p.keyColumnSize < 1 || p.keyColumnSize > maxKeyColumnSize {
+		return fmt.Errorf("key column size %d must be between 1 and %d", p.keyColumnSize, maxKeyColumnSize)
+	}
+
+	// the charset and the collation are part of the CREATE TABLE statement too, only names are accepted
+	if !charsetNamePattern.MatchString(p.charset) {
+		return fmt.Errorf("invalid charset %q", p.charset)
+	}
+
+	if p.collation != "" && !charsetNamePattern.MatchString(p.collation) {
+		return fmt.Errorf("invalid collation %q", p.collation)
+	}
+
+	if p.limits.maxKeySize < 1 || p.limits.maxValueSize < 1 {
+		return fmt.Errorf("maximum key size %d and maximum value size %d must be positive", p.limits.maxKeySize,
+			p.limits.maxValueSize)
 	}
 
 	return nil
 }
 
-// Get fetches the value based on key
-func (s *sqlDBStore) Get(k string) ([]byte, error) {
-	if k == "" {
-		return nil, storage.ErrKeyRequired
+// tableOptions returns the options of the CREATE TABLE statements setting the charset and the collation of the tables.
+func (p *Provider) tableOptions() string {
+	options := " CHARACTER SET " + p.charset
+
+	if p.collation != "" {
+		options += " COLLATE " + p.collation
 	}
 
-	var value []byte
-	//nolint: gosec
-	// select query to fetch the record by key
-	err := s.db.QueryRow("SELECT `value` FROM "+s.tableName+" "+
-		" WHERE `key` = ?", k).Scan(&value)
+	return options
+}
+
+func (p *Provider) applyPoolSettings(db *sql.DB) {
+	for _, setting := range p.poolSettings {
+		setting(db)
+	}
+}
+
+// OpenStore opens and returns new db for given name space, or the store already opened for the name space. It's safe
+// for concurrent use along with CloseStore and Close. The password of the DB URL is masked in the errors, the driver
+// errors may embed the DB URL.
+func (p *Provider) OpenStore(name string) (storage.Store, error) {
+	store, err := p.openStore(name, true)
 	if err != nil {
-		if strings.Contains(err.Error(), sqlDBNotFound) {
-			return nil, storage.ErrDataNotFound
-		}
+		return nil, storage.RedactDSNError(err, p.dbURL)
+	}
+
+	return store, nil
+}
 
-		return nil, fmt.Errorf("failed to get row %w", err)
+// OpenExistingStore opens and returns the store for given name space like OpenStore, but it returns
+// storage.ErrStoreNotFound instead of creating the store when its table doesn't exist.
+func (p *Provider) OpenExistingStore(name string) (storage.Store, error) {
+	store, err := p.openStore(name, false)
+	if err != nil {
+		return nil, storage.RedactDSNError(err, p.dbURL)
 	}
 
-	return value, nil
+	return store, nil
 }
 
-// Delete will delete record with k key
-func (s *sqlDBStore) Delete(k string) error {
-	if k == "" {
-		return storage.ErrKeyRequired
+// openStore opens the store for given name space, creating its database and tables unless they must exist already.
+func (p *Provider) openStore(name string, create bool) (storage.Store, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if name == "" {
+		return nil, errors.New("store name is required")
 	}
-	//nolint: gosec
-	// delete query to delete the record by key
-	_, err := s.db.Exec("DELETE FROM "+s.tableName+" WHERE `key`= ?", k)
 
+	unquotedTableName := p.TableName(name)
+	storeName := name
+
+	if p.dbPrefix != "" {
+		name = p.dbPrefix + "_" + name
+	}
+
+	err := validateIdentifierLengths(storeName, name, unquotedTableName)
 	if err != nil {
-		return fmt.Errorf("failed to delete row %w", err)
+		return nil, err
 	}
 
-	return nil
-}
+	// the store is opened once, opening it again returns it without creating its database and tables again
+	if store, ok := p.dbs[name]; ok {
+		return store, nil
+	}
 
-type sqlDBResultsIterator struct {
-	resultRows *sql.Rows
-	result     result
-	err        error
-}
+	if !create {
+		if err = p.checkTableExists(name, unquotedTableName); err != nil {
+			return nil, err
+		}
+	}
 
-func (s *sqlDBStore) Iterator(startKey, endKey string) storage.StoreIterator {
-	// reference : https://dev.mysql.com/doc/refman/8.0/en/fulltext-boolean.html
-	if strings.Contains(endKey, storage.EndKeySuffix) {
-		endKey = strings.ReplaceAll(endKey, storage.EndKeySuffix, "*")
+	// creating the database
+	_, err = p.db.Exec(createDBQuery + quoteIdentifier(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db %s: %w", name, dbError(err))
 	}
-	//nolint:gosec
-	// sub query to fetch the all the keys that have start and end key reference, simulating range behavior.
-	queryStmt := "SELECT * FROM " + s.tableName + " WHERE `key` >= ? AND `key` < ? order by `key`"
 
-	resultRows, err := s.db.Query(queryStmt, startKey, endKey)
+	// the stores share the connection pool of the provider, their tables are qualified with their database instead
+	newDBConn := p.db
+
+	tableName := p.quoteTableName(name, unquotedTableName)
+
+	// TODO: Issue-1940 Store the hashed key to control the width of the key varchar column
+	createTableStmt := fmt.Sprintf("CREATE Table IF NOT EXISTS %s(`key` varchar(%d) NOT NULL ,`value` %s, "+
+		"`expires_at` DATETIME(6) NULL, `deleted_at` DATETIME(6) NULL, PRIMARY KEY (`key`))%s;", tableName,
+		p.keyColumnSize, p.valueColumnType, p.tableOptions())
+
+	// creating key-value table inside the database
+	_, err = newDBConn.Exec(createTableStmt)
 	if err != nil {
-		return &sqlDBResultsIterator{
-			err: fmt.Errorf("failed to query rows %w", err)}
+		return nil, fmt.Errorf("failed to create table %s: %w", tableName, dbError(err))
 	}
 
-	if err = resultRows.Err(); err != nil {
-		return &sqlDBResultsIterator{
-			err: fmt.Errorf("failed to get resulted rows %w", err)}
+	// the tables of the stores created by former versions lack the columns added since
+	for _, column := range []string{"expires_at", "deleted_at"} {
+		err = addColumn(newDBConn, name, unquotedTableName, tableName, column, "DATETIME(6) NULL")
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return &sqlDBResultsIterator{resultRows: resultRows}
-}
+	tagsTableName := p.quoteTableName(name, unquotedTableName+tagsTableSuffix)
 
-func (i *sqlDBResultsIterator) Next() bool {
-	return i.resultRows.Next()
-}
+	err = createTagsTable(newDBConn, tagsTableName, p.keyColumnSize, p.tableOptions())
+	if err != nil {
+		return nil, err
+	}
 
-func (i *sqlDBResultsIterator) Release() {
-	if err := i.resultRows.Close(); err != nil {
-		i.err = err
+	endKeySuffixReplacement, err := endKeySuffixReplacementFor(newDBConn, name, unquotedTableName, tableName)
+	if err != nil {
+		return nil, err
 	}
-}
 
-func (i *sqlDBResultsIterator) Error() error {
-	if i.err != nil {
-		return i.err
+	jsonColumn, err := hasJSONColumn(newDBConn, name, unquotedTableName, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &sqlDBStore{
+		db:                      newDBConn,
+		readDB:                  p.readDB,
+		tableName:               tableName,
+		tagsTableName:           tagsTableName,
+		dbName:                  name,
+		unquotedTableName:       unquotedTableName,
+		endKeySuffixReplacement: endKeySuffixReplacement,
+		retry:                   p.retry,
+		strictDelete:            p.strictDelete,
+		softDelete:              p.softDelete,
+		iteratorLeakWarning:     p.iteratorLeakWarning,
+		iteratorPageSize:        p.iteratorPageSize,
+		operationTimeout:        p.operationTimeout,
+		jsonColumn:              jsonColumn,
+		limits:                  p.limits,
 	}
 
-	return i.resultRows.Err()
+	p.dbs[name] = store
+
+	return store, nil
 }
 
-// Key returns the key of the current key-value pair.
-func (i *sqlDBResultsIterator) Key() []byte {
-	err := i.resultRows.Scan(&i.result.key, &i.result.value)
+// checkTableExists returns storage.ErrStoreNotFound if the table of the store with the given DB name doesn't exist.
+func (p *Provider) checkTableExists(name, tableName string) error {
+	var found int
+
+	err := p.db.QueryRow("SELECT COUNT(*) FROM information_schema.TABLES WHERE `TABLE_SCHEMA` = ? AND "+
+		"`TABLE_NAME` = ?", name, tableName).Scan(&found)
 	if err != nil {
-		i.err = err
-		return nil
+		return fmt.Errorf("failed to check table of store %s: %w", name, dbError(err))
 	}
 
-	return []byte(i.result.key)
-}
+	if found == 0 {
+		return storage.ErrStoreNotFound
+	}
+
+	return nil
+}
+
+// TableName returns the name of the table of the store with the given name.
+func (p *Provider) TableName(storeName string) string {
+	if p.tableNameFunc != nil {
+		return p.tableNameFunc(storeName)
+	}
+
+	if p.dbPrefix != "" {
+		storeName = p.dbPrefix + "_" + storeName
+	}
+
+	return tablePrefix + storeName
+}
+
+// validateIdentifierLengths validates the database name and the table names of the store with the given name fit in
+// MySQL identifiers, the table names of long store names otherwise fail to be created with a cryptic error.
+func validateIdentifierLengths(storeName, dbName, tableName string) error {
+	for _, identifier := range []string{dbName, tableName + tagsTableSuffix} {
+		if utf8.RuneCountInString(identifier) > maxIdentifierLength {
+			return fmt.Errorf("store name %s is too long: %s exceeds the %d characters of MySQL identifiers",
+				storeName, identifier, maxIdentifierLength)
+		}
+	}
+
+	return nil
+}
+
+// quoteTableName returns the name of a table of the store with the given DB name qualified with the DB name, to
+// interpolate it in the statements of the store.
+func (p *Provider) quoteTableName(name, tableName string) string {
+	return quoteIdentifier(name) + "." + quoteIdentifier(tableName)
+}
+
+// quoteIdentifier quotes the DB or table name with backticks to interpolate it in statements, backticks of the name
+// are escaped.
+func quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// Close closes the provider: the expiry cleanup is stopped and the connection pools of the provider and of its read
+// replica are closed. Every pool is closed even if closing another one fails, the errors are wrapped in a
+// storage.MultiError.
+// The provider is closed even when an error is returned, closing it again is a no-op returning nil.
+func (p *Provider) Close() error {
+	p.stopExpiryCleanup()
+
+	p.Lock()
+	defer p.Unlock()
+
+	if p.closed {
+		return nil
+	}
+
+	p.closed = true
+
+	var errs storage.MultiError
+
+	if p.ownsDB {
+		if p.readDB != p.db {
+			if err := p.readDB.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close read replica connection: %w", err))
+			}
+		}
+
+		if err := p.db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close connection: %w", err))
+		}
+	}
+
+	p.dbs = make(map[string]*sqlDBStore)
+
+	if len(errs) > 0 {
+		return fmt.Errorf(failToCloseProviderErrMsg+": %w", errs)
+	}
+
+	return nil
+}
+
+// Ping checks the MySQL server of the provider is reachable. It lets callers fail fast instead of erroring lazily on
+// the first OpenStore.
+func (p *Provider) Ping(ctx context.Context) error {
+	p.RLock()
+	defer p.RUnlock()
+
+	if err := p.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping MySQL: %w", dbError(err))
+	}
+
+	return nil
+}
+
+// StoreNames returns the names of the stores found on the MySQL server, including the stores that aren't open. Stores
+// are found by their databases, filtered by the DB prefix of the provider which is stripped from the names, holding
+// the table of the store.
+func (p *Provider) StoreNames() ([]string, error) {
+	p.RLock()
+	defer p.RUnlock()
+
+	schemaPrefix := ""
+	if p.dbPrefix != "" {
+		schemaPrefix = p.dbPrefix + "_"
+	}
+
+	// the LIKE pattern only narrows the query down, the store tables are matched below
+	rows, err := p.db.Query("SELECT `TABLE_SCHEMA`, `TABLE_NAME` FROM information_schema.TABLES "+
+		"WHERE `TABLE_SCHEMA` LIKE ?", escapeLike(schemaPrefix)+"%")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query store tables %w", dbError(err))
+	}
+
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			logger.Warnf("failed to close rows: %s", closeErr)
+		}
+	}()
+
+	var names []string
+
+	for rows.Next() {
+		var schema, table string
+
+		if err = rows.Scan(&schema, &table); err != nil {
+			return nil, fmt.Errorf("failed to scan store table %w", dbError(err))
+		}
+
+		name := strings.TrimPrefix(schema, schemaPrefix)
+
+		if name != "" && strings.HasPrefix(schema, schemaPrefix) && table == p.TableName(name) {
+			names = append(names, name)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query store tables %w", dbError(err))
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// escapeLike escapes the wildcards of s to match it literally in a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// CloseStore closes a previously opened store, opening it again returns a new store
+func (p *Provider) CloseStore(name string) error {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.dbPrefix != "" {
+		name = p.dbPrefix + "_" + name
+	}
+
+	// the connection pools are shared by all the stores, they are closed along with the provider
+	delete(p.dbs, name)
+
+	return nil
+}
+
+// Truncate deletes all the records of the store with given name, along with their tags and tombstones, with TRUNCATE
+// TABLE statements which are much faster than deleting the records. The store doesn't need to be open, an open store
+// stays open. Truncating a store whose table doesn't exist is a no-op, unless the provider has the WithStrictDelete
+// option in which case storage.ErrStoreNotFound is returned.
+func (p *Provider) Truncate(name string) error {
+	if name == "" {
+		return errors.New("store name is required")
+	}
+
+	unquotedTableName := p.TableName(name)
+
+	if p.dbPrefix != "" {
+		name = p.dbPrefix + "_" + name
+	}
+
+	err := p.checkTableExists(name, unquotedTableName)
+	if errors.Is(err, storage.ErrStoreNotFound) && !p.strictDelete {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	tableNames := []string{unquotedTableName}
+
+	// the tags table is created when the store is opened, the stores created by former versions may lack it
+	err = p.checkTableExists(name, unquotedTableName+tagsTableSuffix)
+	if err == nil {
+		tableNames = append(tableNames, unquotedTableName+tagsTableSuffix)
+	} else if !errors.Is(err, storage.ErrStoreNotFound) {
+		return err
+	}
+
+	for _, tableName := range tableNames {
+		_, err = p.db.Exec("TRUNCATE TABLE " + p.quoteTableName(name, tableName))
+		if err != nil {
+			return fmt.Errorf("failed to truncate table %s: %w", tableName, dbError(err))
+		}
+	}
+
+	return nil
+}
+
+// sqlExecutor executes statements either directly on the DB or within a transaction
+type sqlExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Put stores the key and the value
+func (s *sqlDBStore) Put(k string, v []byte) error {
+	return s.PutContext(context.Background(), k, v)
+}
+
+// PutContext stores the key and the value, the statement is cancelled along with ctx
+func (s *sqlDBStore) PutContext(ctx context.Context, k string, v []byte) error {
+	ctx, cancel := s.withOperationTimeout(ctx)
+	defer cancel()
+
+	return s.retry.doContext(ctx, func() error {
+		return put(ctx, s.db, s.tableName, s.limits, k, v)
+	})
+}
+
+func put(ctx context.Context, e sqlExecutor, tableName string, limits sizeLimits, k string, v []byte) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	if v == nil {
+		return storage.ErrNilValue
+	}
+
+	if err := limits.check(k, v); err != nil {
+		return err
+	}
+
+	//nolint: gosec
+	// create upsert query to insert the record, checking whether the key is already mapped to a value in the store.
+	createStmt := "INSERT INTO " + tableName + " (`key`, `value`) VALUES (?, ?) " +
+		"ON DUPLICATE KEY UPDATE `value`=VALUES(`value`), `expires_at`=NULL, `deleted_at`=NULL"
+	// executing the prepared insert statement
+	_, err := e.ExecContext(ctx, createStmt, k, v)
+	if err != nil {
+		return fmt.Errorf("failed to insert key and value record into %s %w ", tableName, dbError(err))
+	}
+
+	return nil
+}
+
+// PutIfMatch updates the value of the key only if it currently equals expected, or inserts it only if the key
+// doesn't exist yet when expected is nil. It isn't retried since it's not idempotent.
+func (s *sqlDBStore) PutIfMatch(k string, expected, newValue []byte) (bool, error) {
+	if k == "" {
+		return false, storage.ErrKeyRequired
+	}
+
+	if newValue == nil {
+		return false, storage.ErrNilValue
+	}
+
+	if err := s.limits.check(k, newValue); err != nil {
+		return false, err
+	}
+
+	ctx, cancel := s.withOperationTimeout(context.Background())
+	defer cancel()
+
+	if expected == nil {
+		return s.insertIfAbsent(ctx, k, newValue)
+	}
+
+	// MySQL only reports the rows actually changed by an update, so matching rows left unchanged aren't counted
+	if bytes.Equal(expected, newValue) {
+		return s.holdsValue(ctx, k, expected)
+	}
+
+	//nolint: gosec
+	// update query only changing the record if it still holds the expected value
+	res, err := s.db.ExecContext(ctx, "UPDATE "+s.tableName+" SET `value` = ?, `expires_at` = NULL "+
+		"WHERE `key` = ? AND `value` = "+s.valuePlaceholder()+" AND "+liveRowCondition, newValue, k, expected)
+	if err != nil {
+		return false, fmt.Errorf("failed to update key and value record in %s %w ", s.tableName, dbError(err))
+	}
+
+	updated, err := rowsAffected(res)
+	if err != nil || updated || !s.jsonColumn {
+		return updated, err
+	}
+
+	// JSON documents only differing by their formatting are equal, the update leaves the record unchanged
+	return s.holdsValue(ctx, k, expected)
+}
+
+// holdsValue checks whether the record of the key holds the value, JSON values being compared as JSON documents.
+func (s *sqlDBStore) holdsValue(ctx context.Context, k string, v []byte) (bool, error) {
+	var found int
+	//nolint: gosec
+	// select query comparing the value on the primary without fetching it
+	err := s.db.QueryRowContext(ctx, "SELECT 1 FROM "+s.tableName+" WHERE `key` = ? AND `value` = "+
+		s.valuePlaceholder()+" AND "+liveRowCondition, k, v).Scan(&found)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to get row %w", dbError(err))
+	}
+
+	return true, nil
+}
+
+func (s *sqlDBStore) insertIfAbsent(ctx context.Context, k string, v []byte) (bool, error) {
+	//nolint: gosec
+	// an expired record or a tombstone of the key must not make the insert fail
+	res, err := s.db.ExecContext(ctx, "DELETE FROM "+s.tableName+" WHERE `key` = ? AND NOT "+liveRowCondition, k)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete expired row %w", dbError(err))
+	}
+
+	expired, err := rowsAffected(res)
+	if err != nil {
+		return false, err
+	}
+
+	// nor must its tags be attached to the new record
+	if expired {
+		if err = deleteTags(ctx, s.db, s.tagsTableName, k); err != nil {
+			return false, err
+		}
+	}
+
+	//nolint: gosec
+	// insert query failing with a duplicate entry error if the key is already mapped to a value in the store.
+	_, err = s.db.ExecContext(ctx, "INSERT INTO "+s.tableName+" (`key`, `value`) VALUES (?, ?)", k, v)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to insert key and value record into %s %w ", s.tableName, dbError(err))
+	}
+
+	return true, nil
+}
+
+func rowsAffected(res sql.Result) (bool, error) {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows %w", dbError(err))
+	}
+
+	return n > 0, nil
+}
+
+// dbError wraps an error of the driver in a storage.Error carrying its MySQL error number, if any.
+func dbError(err error) error {
+	storageErr := &storage.Error{Cause: err}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		storageErr.Code = int(mysqlErr.Number)
+	}
+
+	return storageErr
+}
+
+// withOperationTimeout derives a context from ctx bounded by the operation timeout of the store, ctx is returned as is
+// when the store has none.
+func (s *sqlDBStore) withOperationTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.operationTimeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, s.operationTimeout)
+}
+
+// PutBatch stores the given key/value pairs using multi-row upserts executed within a single transaction, so either
+// all the pairs are stored or none of them are.
+func (s *sqlDBStore) PutBatch(kvs []storage.KeyValue) error {
+	for _, kv := range kvs {
+		if kv.Key == "" {
+			return storage.ErrKeyRequired
+		}
+
+		if kv.Value == nil {
+			return storage.ErrNilValue
+		}
+
+		if err := s.limits.check(kv.Key, kv.Value); err != nil {
+			return err
+		}
+	}
+
+	if len(kvs) == 0 {
+		return nil
+	}
+
+	ctx, cancel := s.withOperationTimeout(context.Background())
+	defer cancel()
+
+	// the whole transaction is retried
+	return s.retry.doContext(ctx, func() error {
+		return s.putBatch(ctx, kvs)
+	})
+}
+
+func (s *sqlDBStore) putBatch(ctx context.Context, kvs []storage.KeyValue) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for batch insert into %s %w ", s.tableName, dbError(err))
+	}
+
+	for start := 0; start < len(kvs); start += maxBatchRows {
+		end := start + maxBatchRows
+		if end > len(kvs) {
+			end = len(kvs)
+		}
+
+		err = s.putBatchRows(ctx, tx, kvs[start:end])
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return fmt.Errorf("failed to rollback batch insert: %s: %w", rollbackErr.Error(), err)
+			}
+
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit batch insert into %s %w ", s.tableName, dbError(err))
+	}
+
+	return nil
+}
+
+func (s *sqlDBStore) putBatchRows(ctx context.Context, tx *sql.Tx, kvs []storage.KeyValue) error {
+	placeholders := make([]string, len(kvs))
+	args := make([]interface{}, 0, 2*len(kvs))
+
+	for i, kv := range kvs {
+		placeholders[i] = "(?, ?)"
+		args = append(args, kv.Key, kv.Value)
+	}
+
+	//nolint: gosec
+	// create multi-row upsert query, updating the value of the keys already mapped in the store.
+	createStmt := "INSERT INTO " + s.tableName + " (`key`, `value`) VALUES " + strings.Join(placeholders, ", ") +
+		" ON DUPLICATE KEY UPDATE `value`=VALUES(`value`), `expires_at`=NULL, `deleted_at`=NULL"
+
+	_, err := tx.ExecContext(ctx, createStmt, args...)
+	if err != nil {
+		return fmt.Errorf("failed to insert batch of key and value records into %s %w ", s.tableName, dbError(err))
+	}
+
+	return nil
+}
+
+// Get fetches the value based on key
+func (s *sqlDBStore) Get(k string) ([]byte, error) {
+	return s.GetContext(context.Background(), k)
+}
+
+// GetContext fetches the value based on key, the query is cancelled along with ctx
+func (s *sqlDBStore) GetContext(ctx context.Context, k string) ([]byte, error) {
+	ctx, cancel := s.withOperationTimeout(ctx)
+	defer cancel()
+
+	var value []byte
+
+	err := s.retry.doContext(ctx, func() error {
+		var err error
+
+		value, err = get(ctx, s.readDB, s.tableName, k)
+
+		return err
+	})
+
+	return value, err
+}
+
+func get(ctx context.Context, e sqlExecutor, tableName, k string) ([]byte, error) {
+	if k == "" {
+		return nil, storage.ErrKeyRequired
+	}
+
+	var value []byte
+	//nolint: gosec
+	// select query to fetch the record by key
+	err := e.QueryRowContext(ctx, "SELECT `value` FROM "+tableName+" "+
+		" WHERE `key` = ? AND "+liveRowCondition, k).Scan(&value)
+	if err != nil {
+		if strings.Contains(err.Error(), sqlDBNotFound) {
+			return nil, storage.ErrDataNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get row %w", dbError(err))
+	}
+
+	return value, nil
+}
+
+// GetBulk fetches the values of the given keys, querying the store once per batch of keys
+func (s *sqlDBStore) GetBulk(keys ...string) ([][]byte, error) {
+	for _, k := range keys {
+		if k == "" {
+			return nil, storage.ErrKeyRequired
+		}
+	}
+
+	ctx, cancel := s.withOperationTimeout(context.Background())
+	defer cancel()
+
+	found := make(map[string][]byte, len(keys))
+
+	for start := 0; start < len(keys); start += maxBatchRows {
+		end := start + maxBatchRows
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		batch := keys[start:end]
+
+		err := s.retry.doContext(ctx, func() error {
+			return s.getBulkRows(ctx, batch, found)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	values := make([][]byte, len(keys))
+
+	for i, k := range keys {
+		values[i] = found[k]
+	}
+
+	return values, nil
+}
+
+func (s *sqlDBStore) getBulkRows(ctx context.Context, keys []string, found map[string][]byte) error {
+	placeholders := make([]string, len(keys))
+	args := make([]interface{}, len(keys))
+
+	for i, k := range keys {
+		placeholders[i] = "?"
+		args[i] = k
+	}
+
+	//nolint: gosec
+	// select query to fetch the records of all the keys at once
+	rows, err := s.readDB.QueryContext(ctx, "SELECT `key`, `value` FROM "+s.tableName+
+		" WHERE `key` IN ("+strings.Join(placeholders, ", ")+") AND "+liveRowCondition, args...)
+	if err != nil {
+		return fmt.Errorf("failed to get rows %w", dbError(err))
+	}
+
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			logger.Warnf("failed to close rows: %s", closeErr)
+		}
+	}()
+
+	for rows.Next() {
+		var r result
+
+		if err = rows.Scan(&r.key, &r.value); err != nil {
+			return fmt.Errorf("failed to scan row %w", dbError(err))
+		}
+
+		found[r.key] = r.value
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to get rows %w", dbError(err))
+	}
+
+	return nil
+}
+
+// Has checks whether a record with key k exists, without transferring its value
+func (s *sqlDBStore) Has(k string) (bool, error) {
+	if k == "" {
+		return false, storage.ErrKeyRequired
+	}
+
+	ctx, cancel := s.withOperationTimeout(context.Background())
+	defer cancel()
+
+	var found bool
+
+	err := s.retry.doContext(ctx, func() error {
+		var err error
+
+		found, err = s.has(ctx, k)
+
+		return err
+	})
+
+	return found, err
+}
+
+func (s *sqlDBStore) has(ctx context.Context, k string) (bool, error) {
+	var found int
+	//nolint: gosec
+	// select query to check the key presence without fetching the value
+	err := s.readDB.QueryRowContext(ctx, "SELECT 1 FROM "+s.tableName+" WHERE `key` = ? AND "+liveRowCondition+
+		" LIMIT 1", k).Scan(&found)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to check row %w", dbError(err))
+	}
+
+	return true, nil
+}
+
+// Delete will delete record with k key
+func (s *sqlDBStore) Delete(k string) error {
+	return s.DeleteContext(context.Background(), k)
+}
+
+// DeleteContext will delete record with k key, the statement is cancelled along with ctx
+func (s *sqlDBStore) DeleteContext(ctx context.Context, k string) error {
+	ctx, cancel := s.withOperationTimeout(ctx)
+	defer cancel()
+
+	op := func() error {
+		return remove(ctx, s.db, s.tableName, s.tagsTableName, k, s.strictDelete, s.softDelete)
+	}
+
+	// a strict delete isn't idempotent, a retry following a delete that succeeded would return storage.ErrDataNotFound
+	if s.strictDelete {
+		return op()
+	}
+
+	return s.retry.doContext(ctx, op)
+}
+
+// remove deletes the record of key k along with its tags, when strict it returns storage.ErrDataNotFound if there's
+// no record of the key. When soft the record is replaced with a tombstone.
+func remove(ctx context.Context, e sqlExecutor, tableName, tagsTableName, k string, strict, soft bool) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+	//nolint: gosec
+	// delete query to delete the record by key
+	deleteStmt := "DELETE FROM " + tableName + " WHERE `key`= ?"
+
+	if soft {
+		// only the live record becomes a tombstone, an expired record is left to the expiry cleanup
+		deleteStmt = "UPDATE " + tableName + " SET " + tombstoneAssignments + " WHERE `key`= ? AND " + liveRowCondition
+	}
+
+	if strict && !soft {
+		// an expired record is left to the expiry cleanup, it doesn't exist anymore
+		deleteStmt += " AND " + liveRowCondition
+	}
+
+	res, err := e.ExecContext(ctx, deleteStmt, k)
+	if err != nil {
+		return fmt.Errorf("failed to delete row %w", dbError(err))
+	}
+
+	if strict {
+		deleted, err := rowsAffected(res)
+		if err != nil {
+			return err
+		}
+
+		if !deleted {
+			return storage.ErrDataNotFound
+		}
+	}
+
+	return deleteTags(ctx, e, tagsTableName, k)
+}
+
+// Count returns the number of records within the [startKey, endKey) range, the whole table being counted when both
+// keys are empty. The range is unbounded when only endKey is empty.
+func (s *sqlDBStore) Count(startKey, endKey string) (int, error) {
+	//nolint:gosec
+	// query to count all the records of the table
+	queryStmt := "SELECT COUNT(*) FROM " + s.tableName + " WHERE " + liveRowCondition
+
+	var args []interface{}
+
+	if startKey != "" || endKey != "" {
+		var condition string
+
+		condition, args = s.rangeCondition(startKey, endKey)
+		queryStmt += " AND " + condition
+	}
+
+	ctx, cancel := s.withOperationTimeout(context.Background())
+	defer cancel()
+
+	var count int
+
+	err := s.retry.doContext(ctx, func() error {
+		return s.readDB.QueryRowContext(ctx, queryStmt, args...).Scan(&count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rows %w", dbError(err))
+	}
+
+	return count, nil
+}
+
+// ApproxSizeBytes returns the data and index lengths of the table of the store and of its tags table as reported by
+// information_schema. MySQL refreshes these statistics periodically (see information_schema_stats_expiry), the size
+// is an estimate for capacity planning which may lag behind the latest writes.
+func (s *sqlDBStore) ApproxSizeBytes() (int64, error) {
+	ctx, cancel := s.withOperationTimeout(context.Background())
+	defer cancel()
+
+	var size int64
+
+	err := s.retry.doContext(ctx, func() error {
+		return s.db.QueryRowContext(ctx, "SELECT COALESCE(SUM(`DATA_LENGTH` + `INDEX_LENGTH`), 0) FROM "+
+			"information_schema.TABLES WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` IN (?, ?)", s.dbName,
+			s.unquotedTableName, s.unquotedTableName+tagsTableSuffix).Scan(&size)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get size of table %s: %w", s.tableName, dbError(err))
+	}
+
+	return size, nil
+}
+
+// ForEach calls fn with every key/value pair within the key range until fn returns stop or an error
+func (s *sqlDBStore) ForEach(startKey, endKey string, fn func(key, value []byte) (bool, error)) error {
+	return storage.ForEach(s.Iterator(startKey, endKey), fn)
+}
+
+// DeleteRange deletes the records within the [startKey, endKey) range with a single statement and returns the number
+// of records deleted, with the same range semantics as Iterator: nothing is deleted when both keys are empty. Expired
+// records are left to the expiry cleanup. The deleted records are replaced with tombstones with WithSoftDelete.
+// The statement isn't retried, a retry following a delete that succeeded would report no deleted records.
+func (s *sqlDBStore) DeleteRange(startKey, endKey string) (int, error) {
+	condition, args := s.rangeCondition(startKey, endKey)
+
+	ctx, cancel := s.withOperationTimeout(context.Background())
+	defer cancel()
+
+	//nolint:gosec
+	// delete query removing all the records of the range at once
+	deleteStmt := "DELETE FROM " + s.tableName
+
+	if s.softDelete {
+		deleteStmt = "UPDATE " + s.tableName + " SET " + tombstoneAssignments
+	}
+
+	res, err := s.db.ExecContext(ctx, deleteStmt+" WHERE "+condition+" AND "+liveRowCondition, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete range of rows %w", dbError(err))
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete range of rows %w", dbError(err))
+	}
+
+	//nolint:gosec
+	// the tags of the expired records of the range are deleted too, they can't be queried anymore
+	_, err = s.db.ExecContext(ctx, "DELETE FROM "+s.tagsTableName+" WHERE "+condition, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete range of rows %w", dbError(err))
+	}
+
+	return int(deleted), nil
+}
+
+// rangeCondition returns the condition selecting the keys of the [startKey, endKey) range and its arguments. An empty
+// endKey leaves the range unbounded, unless startKey is empty too in which case the range matches nothing.
+func (s *sqlDBStore) rangeCondition(startKey, endKey string) (string, []interface{}) {
+	if endKey == "" && startKey != "" {
+		return "`key` >= ?", []interface{}{startKey}
+	}
+
+	return "`key` >= ? AND `key` < ?", []interface{}{startKey, s.rangeEndKey(endKey)}
+}
+
+// rangeEndKey returns the end key of the range to use in queries, the storage.EndKeySuffix of endKey being
+// replaced with a character sorting after the other printable characters in the collation of the key column.
+func (s *sqlDBStore) rangeEndKey(endKey string) string {
+	return strings.ReplaceAll(endKey, storage.EndKeySuffix, s.endKeySuffixReplacement)
+}
+
+// endKeySuffixReplacementFor returns the replacement of storage.EndKeySuffix in the end key of the ranges of a table,
+// depending on the collation of its key column: tables of stores created with a non binary collation, ie before
+// WithCharset, keep comparing keys with the collation.
+func endKeySuffixReplacementFor(db *sql.DB, dbName, tableName, quotedTableName string) (string, error) {
+	var collation sql.NullString
+
+	err := db.QueryRow("SELECT `COLLATION_NAME` FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = ? "+
+		"AND TABLE_NAME = ? AND COLUMN_NAME = 'key'", dbName, tableName).Scan(&collation)
+	if err != nil {
+		return "", fmt.Errorf("failed to check key column collation of table %s: %w", quotedTableName, dbError(err))
+	}
+
+	if collation.String == "binary" || strings.HasSuffix(collation.String, "_bin") {
+		return binaryEndKeySuffixReplacement, nil
+	}
+
+	return collatedEndKeySuffixReplacement, nil
+}
+
+// sqlDBResultsIterator streams the rows of its query: the driver reads each row from the connection when Next is
+// called, the rows are never loaded at once. The connection is held until the end of the scan or Release, a finalizer
+// closes the rows of the iterators dropped before either of them. With a page size, the rows are queried by pages
+// following the last key returned, the connection being held until the end of each page.
+type sqlDBResultsIterator struct {
+	resultRows *sql.Rows
+	result     result
+	err        error
+	// the condition selecting the rows and the options of the iterator are kept to query the rows again when
+	// seeking a key
+	store     *sqlDBStore
+	condition string
+	args      []interface{}
+	options   storage.IteratorOptions
+	returned  int
+	// pageSize is the number of rows of the queries of a paged iterator, 0 without pages. pageRows is the number of
+	// rows returned from the current page and pageLimit its LIMIT, a page with fewer rows than its limit is the last.
+	pageSize  int
+	pageRows  int
+	pageLimit int
+	// totalCount caches the result of TotalCount, -1 until it's counted
+	totalCount int
+	// rowsClosed is set when the current rows are closed by Release or by the end of the scan
+	rowsClosed bool
+	// creationStack is the stack trace of the creation of the iterator, only captured with WithIteratorLeakWarning
+	creationStack []byte
+	// ctx bounds the scan by the operation timeout of the store until cancel is called by Release
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Iterator returns an iterator over the [startKey, endKey) range, in descending key order when storage.WithReverse
+// is given. The number of records is capped by storage.WithLimit. An empty endKey leaves the range unbounded, ie
+// Iterator(startKey, "") iterates from startKey to the end of the store. The range of two empty keys matches nothing
+// though, IteratorAll iterates over the whole store.
+func (s *sqlDBStore) Iterator(startKey, endKey string, opts ...storage.IteratorOption) storage.StoreIterator {
+	// sub query to fetch the all the keys that have start and end key reference, simulating range behavior.
+	condition, args := s.rangeCondition(startKey, endKey)
+
+	return newIterator(s, condition, args, storage.GetIteratorOptions(opts...))
+}
+
+// IteratorAll returns an iterator over all the records of the store, with the same options as Iterator.
+func (s *sqlDBStore) IteratorAll(opts ...storage.IteratorOption) storage.StoreIterator {
+	// every key is greater than or equal to the empty key
+	return newIterator(s, "`key` >= ?", []interface{}{""}, storage.GetIteratorOptions(opts...))
+}
+
+// newIterator returns an iterator over the live rows of the store matching the condition.
+func newIterator(s *sqlDBStore, condition string, args []interface{},
+	options storage.IteratorOptions) *sqlDBResultsIterator {
+	return newRowsIterator(s, condition+" AND "+liveRowCondition, args, options)
+}
+
+// newRowsIterator returns an iterator over the rows of the store matching the condition, which must filter out the
+// rows not to iterate over.
+func newRowsIterator(s *sqlDBStore, condition string, args []interface{},
+	options storage.IteratorOptions) *sqlDBResultsIterator {
+	itr := &sqlDBResultsIterator{
+		store:      s,
+		condition:  condition,
+		args:       args,
+		options:    options,
+		totalCount: -1,
+	}
+
+	if s.iteratorPageSize > 0 {
+		itr.pageSize = s.iteratorPageSize
+	}
+
+	itr.ctx, itr.cancel = s.withOperationTimeout(context.Background())
+
+	if s.iteratorLeakWarning {
+		itr.creationStack = debug.Stack()
+	}
+
+	itr.query(nil, false)
+
+	// database/sql doesn't close the rows it lost track of, the connection would never be returned to the pool
+	runtime.SetFinalizer(itr, (*sqlDBResultsIterator).finalize)
+
+	return itr
+}
+
+// finalize closes the rows of an iterator garbage collected before the end of its scan and without being released.
+func (i *sqlDBResultsIterator) finalize() {
+	i.cancel()
+
+	if i.resultRows == nil || i.rowsClosed {
+		return
+	}
+
+	if err := i.resultRows.Close(); err != nil {
+		logger.Warnf("failed to close rows of unreleased iterator: %s", err)
+	}
+
+	if i.creationStack != nil {
+		logger.Warnf("iterator over %s was not released, its rows were closed when it was garbage collected. "+
+			"It was created at:\n%s", i.store.tableName, i.creationStack)
+	}
+}
+
+// query queries the rows matching the condition of the iterator, only the rows at or after fromKey in the iteration
+// order when it's given, or strictly after fromKey when afterKey is set.
+func (i *sqlDBResultsIterator) query(fromKey *string, afterKey bool) {
+	//nolint:gosec
+	queryStmt := "SELECT `key`, `value` FROM " + i.store.tableName + " WHERE " + i.condition
+
+	args := append([]interface{}{}, i.args...)
+
+	if fromKey != nil {
+		queryStmt += " AND `key` " + i.fromKeyOperator(afterKey) + " ?"
+
+		args = append(args, *fromKey)
+	}
+
+	queryStmt += " order by `key`"
+
+	if i.options.Reverse {
+		queryStmt += " DESC"
+	}
+
+	limit := 0
+	if i.options.Limit > 0 {
+		limit = i.options.Limit - i.returned
+	}
+
+	if i.pageSize > 0 && (limit == 0 || i.pageSize < limit) {
+		limit = i.pageSize
+	}
+
+	if limit > 0 {
+		queryStmt += " LIMIT ?"
+
+		args = append(args, limit)
+	}
+
+	i.pageRows = 0
+	i.pageLimit = limit
+
+	var resultRows *sql.Rows
+
+	err := i.store.retry.doContext(i.ctx, func() error {
+		var err error
+
+		resultRows, err = i.store.readDB.QueryContext(i.ctx, queryStmt, args...)
+
+		return err
+	})
+	if err != nil {
+		i.err = fmt.Errorf("failed to query rows %w", dbError(err))
+
+		return
+	}
+
+	if err = resultRows.Err(); err != nil {
+		i.err = fmt.Errorf("failed to get resulted rows %w", dbError(err))
+
+		return
+	}
+
+	i.resultRows = resultRows
+	i.rowsClosed = false
+}
+
+// fromKeyOperator returns the comparison operator of the keys at or after a key in the iteration order, strictly after
+// it when afterKey is set.
+func (i *sqlDBResultsIterator) fromKeyOperator(afterKey bool) string {
+	operator := ">"
+	if i.options.Reverse {
+		operator = "<"
+	}
+
+	if afterKey {
+		return operator
+	}
+
+	return operator + "="
+}
+
+func (i *sqlDBResultsIterator) Next() bool {
+	for i.resultRows != nil {
+		if i.resultRows.Next() {
+			i.returned++
+			i.pageRows++
+
+			if i.pageSize > 0 {
+				// the key of the row is kept to query the next page from it
+				if err := i.resultRows.Scan(&i.result.key, &i.result.value); err != nil {
+					i.err = dbError(err)
+
+					return false
+				}
+			}
+
+			return true
+		}
+
+		// database/sql closes the rows at the end of the scan
+		i.rowsClosed = true
+
+		if !i.nextPage() {
+			return false
+		}
+	}
+
+	return false
+}
+
+// nextPage queries the page following the last key returned by a paged iterator whose current page is scanned. It
+// returns false at the end of the scan: the last page has fewer rows than its limit, the limit of the iterator is
+// reached or the scan failed.
+func (i *sqlDBResultsIterator) nextPage() bool {
+	if i.pageSize == 0 || i.pageRows < i.pageLimit || i.err != nil {
+		return false
+	}
+
+	if i.options.Limit > 0 && i.returned == i.options.Limit {
+		return false
+	}
+
+	if err := i.resultRows.Err(); err != nil {
+		i.err = dbError(err)
+
+		return false
+	}
+
+	lastKey := i.result.key
+	i.resultRows = nil
+
+	i.query(&lastKey, true)
+
+	return i.resultRows != nil
+}
+
+// Seek moves the iterator to the first key-value pair of the range at or after key in the iteration order, the rows
+// being queried again from key.
+func (i *sqlDBResultsIterator) Seek(key string) bool {
+	if i.resultRows == nil || (i.options.Limit > 0 && i.returned == i.options.Limit) {
+		return false
+	}
+
+	i.closeRows()
+	i.resultRows = nil
+
+	if i.err != nil {
+		return false
+	}
+
+	i.query(&key, false)
+
+	return i.Next()
+}
+
+// TotalCount returns the number of live rows matching the condition of the iterator, regardless of its limit. The rows
+// are counted with a COUNT query the first time it's called, the count is then cached.
+func (i *sqlDBResultsIterator) TotalCount() (int, error) {
+	if i.totalCount >= 0 {
+		return i.totalCount, nil
+	}
+
+	//nolint:gosec
+	queryStmt := "SELECT COUNT(*) FROM " + i.store.tableName + " WHERE " + i.condition
+
+	ctx, cancel := i.store.withOperationTimeout(context.Background())
+	defer cancel()
+
+	var count int
+
+	err := i.store.retry.doContext(ctx, func() error {
+		return i.store.readDB.QueryRowContext(ctx, queryStmt, i.args...).Scan(&count)
+	})
+	if err != nil {
+		return -1, fmt.Errorf("failed to count rows %w", dbError(err))
+	}
+
+	i.totalCount = count
+
+	return count, nil
+}
+
+// Release closes the rows of the iterator and cancels its operation timeout.
+func (i *sqlDBResultsIterator) Release() {
+	i.cancel()
+	i.closeRows()
+}
+
+func (i *sqlDBResultsIterator) closeRows() {
+	if i.resultRows == nil {
+		return
+	}
+
+	if err := i.resultRows.Close(); err != nil {
+		i.err = dbError(err)
+	}
+
+	i.rowsClosed = true
+}
+
+func (i *sqlDBResultsIterator) Error() error {
+	if i.err != nil || i.resultRows == nil {
+		return i.err
+	}
+
+	if err := i.resultRows.Err(); err != nil {
+		return dbError(err)
+	}
+
+	return nil
+}
+
+// Key returns the key of the current key-value pair.
+func (i *sqlDBResultsIterator) Key() []byte {
+	if i.resultRows == nil {
+		return nil
+	}
+
+	err := i.resultRows.Scan(&i.result.key, &i.result.value)
+	if err != nil {
+		i.err = dbError(err)
+		return nil
+	}
+
+	return []byte(i.result.key)
+}
 
 // Value returns the value of the current key-value pair.
 func (i *sqlDBResultsIterator) Value() []byte {
+	if i.resultRows == nil {
+		return nil
+	}
+
 	err := i.resultRows.Scan(&i.result.key, &i.result.value)
 	if err != nil {
-		i.err = err
+		i.err = dbError(err)
 		return nil
 	}
 
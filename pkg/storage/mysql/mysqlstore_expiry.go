@@ -0,0 +1,163 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+var _ storage.ExpiringStore = (*sqlDBStore)(nil)
+
+// PutWithExpiry stores the key and the value until ttl elapses. Expired records are filtered out by every query of the
+// store until they get deleted by the cleanup of the provider.
+func (s *sqlDBStore) PutWithExpiry(k string, v []byte, ttl time.Duration) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	if ttl <= 0 {
+		return storage.ErrInvalidTTL
+	}
+
+	if v == nil {
+		return storage.ErrNilValue
+	}
+
+	if err := s.limits.check(k, v); err != nil {
+		return err
+	}
+
+	//nolint: gosec
+	// create upsert query setting the expiration time relatively to the clock of the MySQL server
+	createStmt := "INSERT INTO " + s.tableName + " (`key`, `value`, `expires_at`) " +
+		"VALUES (?, ?, DATE_ADD(NOW(6), INTERVAL ? MICROSECOND)) " +
+		"ON DUPLICATE KEY UPDATE `value`=VALUES(`value`), `expires_at`=VALUES(`expires_at`), `deleted_at`=NULL"
+
+	ctx, cancel := s.withOperationTimeout(context.Background())
+	defer cancel()
+
+	return s.retry.doContext(ctx, func() error {
+		_, err := s.db.ExecContext(ctx, createStmt, k, v, ttl.Microseconds())
+		if err != nil {
+			return fmt.Errorf("failed to insert expiring key and value record into %s %w ", s.tableName, dbError(err))
+		}
+
+		return nil
+	})
+}
+
+// deleteExpired deletes the expired records of the store along with their tags.
+func (s *sqlDBStore) deleteExpired() error {
+	// the tags are deleted first since they are selected by the expiration time of their record, the same time of the
+	// MySQL server being used by both statements. It's kept as returned by the driver, which depends on the DB URL.
+	var now interface{}
+
+	err := s.db.QueryRow("SELECT NOW(6)").Scan(&now)
+	if err != nil {
+		return fmt.Errorf("failed to get time of expired rows from %s %w", s.tableName, dbError(err))
+	}
+
+	//nolint: gosec
+	// delete query to delete the tags of all the expired records at once
+	_, err = s.db.Exec("DELETE FROM "+s.tagsTableName+" WHERE `key` IN "+
+		"(SELECT `key` FROM "+s.tableName+" WHERE `expires_at` <= ?)", now)
+	if err != nil {
+		return fmt.Errorf("failed to delete tags of expired rows from %s %w", s.tagsTableName, dbError(err))
+	}
+
+	//nolint: gosec
+	// delete query to delete all the expired records at once
+	_, err = s.db.Exec("DELETE FROM "+s.tableName+" WHERE `expires_at` <= ?", now)
+	if err != nil {
+		return fmt.Errorf("failed to delete expired rows from %s %w", s.tableName, dbError(err))
+	}
+
+	return nil
+}
+
+// addColumn adds the column to the table of a store created before the column was introduced.
+func addColumn(db *sql.DB, dbName, tableName, quotedTableName, column, columnType string) error {
+	var found int
+
+	err := db.QueryRow("SELECT COUNT(*) FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? "+
+		"AND COLUMN_NAME = ?", dbName, tableName, column).Scan(&found)
+	if err != nil {
+		return fmt.Errorf("failed to check columns of table %s: %w", quotedTableName, dbError(err))
+	}
+
+	if found > 0 {
+		return nil
+	}
+
+	_, err = db.Exec("ALTER TABLE " + quotedTableName + " ADD COLUMN `" + column + "` " + columnType)
+	if err != nil {
+		return fmt.Errorf("failed to add %s column to table %s: %w", column, quotedTableName, dbError(err))
+	}
+
+	return nil
+}
+
+// startExpiryCleanup starts the goroutine periodically deleting the expired records of the open stores, Close stops it.
+func (p *Provider) startExpiryCleanup() {
+	if p.expiryCleanupInterval <= 0 {
+		return
+	}
+
+	p.stopCleanup = make(chan struct{})
+	p.cleanupDone = make(chan struct{})
+
+	go func() {
+		defer close(p.cleanupDone)
+
+		ticker := time.NewTicker(p.expiryCleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				p.deleteExpired()
+			case <-p.stopCleanup:
+				return
+			}
+		}
+	}()
+}
+
+func (p *Provider) deleteExpired() {
+	p.RLock()
+
+	stores := make([]*sqlDBStore, 0, len(p.dbs))
+
+	for _, store := range p.dbs {
+		stores = append(stores, store)
+	}
+
+	p.RUnlock()
+
+	for _, store := range stores {
+		if err := store.deleteExpired(); err != nil {
+			logger.Warnf("expiry cleanup: %s", err)
+		}
+	}
+}
+
+// stopExpiryCleanup stops the cleanup goroutine and waits for it to return, it must not be called with the provider
+// locked since the cleanup locks it.
+func (p *Provider) stopExpiryCleanup() {
+	p.stopCleanupOnce.Do(func() {
+		if p.stopCleanup == nil {
+			return
+		}
+
+		close(p.stopCleanup)
+		<-p.cleanupDone
+	})
+}
@@ -0,0 +1,166 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mysql
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestSQLDBStorePutWithExpiry(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"), WithExpiryCleanupInterval(0))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testExpiry")
+	require.NoError(t, err)
+
+	expiringStore, ok := store.(storage.ExpiringStore)
+	require.True(t, ok)
+
+	t.Run("Test expired records are not found", func(t *testing.T) {
+		require.NoError(t, expiringStore.PutWithExpiry("expiring1", []byte("value1"), 50*time.Millisecond))
+		require.NoError(t, expiringStore.PutWithExpiry("expiring2", []byte("value2"), time.Hour))
+
+		v, err := store.Get("expiring1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value1"), v)
+
+		time.Sleep(100 * time.Millisecond)
+
+		_, err = store.Get("expiring1")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		found, err := store.Has("expiring1")
+		require.NoError(t, err)
+		require.False(t, found)
+
+		values, err := store.GetBulk("expiring1", "expiring2")
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{nil, []byte("value2")}, values)
+
+		count, err := store.Count("expiring", "expiringz")
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+
+		itr := store.Iterator("expiring", "expiringz")
+		require.True(t, itr.Next())
+		require.Equal(t, []byte("expiring2"), itr.Key())
+		require.False(t, itr.Next())
+		itr.Release()
+
+		// the expired record doesn't prevent creating the key again
+		stored, err := store.PutIfMatch("expiring1", nil, []byte("value3"))
+		require.NoError(t, err)
+		require.True(t, stored)
+
+		require.NoError(t, store.Delete("expiring1"))
+		require.NoError(t, store.Delete("expiring2"))
+	})
+
+	t.Run("Test put removes the expiry", func(t *testing.T) {
+		require.NoError(t, expiringStore.PutWithExpiry("expiring3", []byte("value"), 50*time.Millisecond))
+		require.NoError(t, store.Put("expiring3", []byte("value")))
+
+		time.Sleep(100 * time.Millisecond)
+
+		v, err := store.Get("expiring3")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value"), v)
+
+		require.NoError(t, store.Delete("expiring3"))
+	})
+
+	t.Run("Test invalid arguments", func(t *testing.T) {
+		require.Equal(t, storage.ErrInvalidTTL, expiringStore.PutWithExpiry("expiring4", []byte("value"), 0))
+		require.Equal(t, storage.ErrKeyRequired, expiringStore.PutWithExpiry("", []byte("value"), time.Hour))
+	})
+
+	require.NoError(t, prov.Close())
+}
+
+func TestSQLDBProviderExpiryCleanup(t *testing.T) {
+	t.Run("Test expired records are deleted until the provider is closed", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithExpiryCleanupInterval(20*time.Millisecond))
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testExpiryCleanup")
+		require.NoError(t, err)
+
+		expiringStore, ok := store.(storage.ExpiringStore)
+		require.True(t, ok)
+
+		require.NoError(t, expiringStore.PutWithExpiry("expiring", []byte("value"), time.Millisecond))
+
+		db, err := sql.Open("mysql", sqlStoreDBURL)
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, db.Close())
+		}()
+
+		require.Eventually(t, func() bool {
+			var count int
+
+			err := db.QueryRow("SELECT COUNT(*) FROM `testExpiryCleanup`.`t_testExpiryCleanup`").Scan(&count)
+
+			return err == nil && count == 0
+		}, time.Second, 10*time.Millisecond)
+
+		require.NoError(t, prov.Close())
+
+		// the cleanup goroutine has returned
+		select {
+		case <-prov.cleanupDone:
+		default:
+			require.Fail(t, "expiry cleanup is still running")
+		}
+	})
+
+	t.Run("Test expiry cleanup disabled", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithExpiryCleanupInterval(0))
+		require.NoError(t, err)
+		require.Nil(t, prov.stopCleanup)
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test expires_at column added to existing tables", func(t *testing.T) {
+		db, err := sql.Open("mysql", sqlStoreDBURL)
+		require.NoError(t, err)
+
+		_, err = db.Exec("CREATE DATABASE IF NOT EXISTS `testExpiryMigration`")
+		require.NoError(t, err)
+
+		_, err = db.Exec("DROP TABLE IF EXISTS `testExpiryMigration`.`t_testExpiryMigration`")
+		require.NoError(t, err)
+
+		_, err = db.Exec("CREATE TABLE `testExpiryMigration`.`t_testExpiryMigration`(`key` varchar(255) NOT NULL, " +
+			"`value` BLOB, PRIMARY KEY (`key`))")
+		require.NoError(t, err)
+
+		prov, err := NewProviderWithDB(db, WithExpiryCleanupInterval(0))
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testExpiryMigration")
+		require.NoError(t, err)
+
+		expiringStore, ok := store.(storage.ExpiringStore)
+		require.True(t, ok)
+
+		require.NoError(t, expiringStore.PutWithExpiry("key1", []byte("value1"), time.Hour))
+
+		v, err := store.Get("key1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value1"), v)
+
+		require.NoError(t, prov.Close())
+		require.NoError(t, db.Close())
+	})
+}
@@ -0,0 +1,148 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+var _ storage.JSONStore = (*sqlDBStore)(nil)
+
+// hasJSONColumn checks whether the value column of the table of a store has the JSON type, the type of the tables of
+// existing stores being kept by OpenStore.
+func hasJSONColumn(db *sql.DB, dbName, tableName, quotedTableName string) (bool, error) {
+	var dataType string
+
+	err := db.QueryRow("SELECT `DATA_TYPE` FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = ? "+
+		"AND TABLE_NAME = ? AND COLUMN_NAME = 'value'", dbName, tableName).Scan(&dataType)
+	if err != nil {
+		return false, fmt.Errorf("failed to check value column type of table %s: %w", quotedTableName, dbError(err))
+	}
+
+	return strings.EqualFold(dataType, jsonValueColumnType), nil
+}
+
+// valuePlaceholder returns the placeholder of the values compared with the value column in statements, JSON values
+// are only equal to other JSON values.
+func (s *sqlDBStore) valuePlaceholder() string {
+	if s.jsonColumn {
+		return "CAST(? AS JSON)"
+	}
+
+	return "?"
+}
+
+func (s *sqlDBStore) checkJSONField(k, jsonPath string) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	if jsonPath == "" {
+		return errors.New("JSON path is mandatory")
+	}
+
+	if !s.jsonColumn {
+		return fmt.Errorf("value column of table %s isn't a JSON column", s.tableName)
+	}
+
+	return nil
+}
+
+// GetField returns the JSON value found at jsonPath in the record of the key, extracted by MySQL with JSON_EXTRACT.
+// The value column of the store must have the JSON type, see WithJSONColumn.
+func (s *sqlDBStore) GetField(k, jsonPath string) ([]byte, error) {
+	if err := s.checkJSONField(k, jsonPath); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.withOperationTimeout(context.Background())
+	defer cancel()
+
+	var value []byte
+
+	err := s.retry.doContext(ctx, func() error {
+		//nolint: gosec
+		// select query to extract the field from the record by key, NULL when the record has no such field
+		err := s.readDB.QueryRowContext(ctx, "SELECT JSON_EXTRACT(`value`, ?) FROM "+s.tableName+
+			" WHERE `key` = ? AND "+liveRowCondition, jsonPath, k).Scan(&value)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return storage.ErrDataNotFound
+			}
+
+			return fmt.Errorf("failed to get field %s %w", jsonPath, dbError(err))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		return nil, storage.ErrDataNotFound
+	}
+
+	return value, nil
+}
+
+// SetField sets the field at jsonPath of the record of the key to the given JSON value with a single UPDATE using
+// JSON_SET, leaving the other fields, the expiry and the tags of the record unchanged. The value column of the store
+// must have the JSON type, see WithJSONColumn.
+func (s *sqlDBStore) SetField(k, jsonPath string, value []byte) error {
+	if err := s.checkJSONField(k, jsonPath); err != nil {
+		return err
+	}
+
+	if err := s.limits.check(k, value); err != nil {
+		return err
+	}
+
+	ctx, cancel := s.withOperationTimeout(context.Background())
+	defer cancel()
+
+	// setting the field again to the same value is a no-op, the update is retried
+	return s.retry.doContext(ctx, func() error {
+		return s.setField(ctx, k, jsonPath, value)
+	})
+}
+
+func (s *sqlDBStore) setField(ctx context.Context, k, jsonPath string, value []byte) error {
+	//nolint: gosec
+	// update query setting the field in place, the value being parsed as a JSON document rather than a JSON string
+	res, err := s.db.ExecContext(ctx, "UPDATE "+s.tableName+" SET `value` = JSON_SET(`value`, ?, CAST(? AS JSON)) "+
+		"WHERE `key` = ? AND "+liveRowCondition, jsonPath, value, k)
+	if err != nil {
+		return fmt.Errorf("failed to set field %s in %s %w ", jsonPath, s.tableName, dbError(err))
+	}
+
+	updated, err := rowsAffected(res)
+	if err != nil || updated {
+		return err
+	}
+
+	// MySQL only reports the rows actually changed by an update, the field may already hold the value
+	var found int
+	//nolint: gosec
+	// select query checking the key presence on the primary, the replica may lag behind the update
+	err = s.db.QueryRowContext(ctx, "SELECT 1 FROM "+s.tableName+" WHERE `key` = ? AND "+liveRowCondition, k).
+		Scan(&found)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return storage.ErrDataNotFound
+		}
+
+		return fmt.Errorf("failed to check key presence %w", dbError(err))
+	}
+
+	return nil
+}
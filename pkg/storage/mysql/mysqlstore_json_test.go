@@ -0,0 +1,180 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mysql
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestSQLDBStoreJSONFields(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("jsondb"), WithJSONColumn(), WithExpiryCleanupInterval(0))
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, prov.Close())
+	}()
+
+	store, err := prov.OpenStore("testJSONFields")
+	require.NoError(t, err)
+
+	jsonStore, ok := store.(storage.JSONStore)
+	require.True(t, ok)
+
+	require.NoError(t, store.Put("doc1", []byte(`{"name":"alice","address":{"city":"Paris"},"tags":["a"]}`)))
+
+	t.Run("Test get field", func(t *testing.T) {
+		field, err := jsonStore.GetField("doc1", "$.name")
+		require.NoError(t, err)
+		require.Equal(t, `"alice"`, string(field))
+
+		field, err = jsonStore.GetField("doc1", "$.address")
+		require.NoError(t, err)
+		requireJSONEqual(t, `{"city":"Paris"}`, field)
+
+		_, err = jsonStore.GetField("doc1", "$.age")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		_, err = jsonStore.GetField("missing", "$.name")
+		require.Equal(t, storage.ErrDataNotFound, err)
+	})
+
+	t.Run("Test set field", func(t *testing.T) {
+		require.NoError(t, jsonStore.SetField("doc1", "$.address.city", []byte(`"Berlin"`)))
+		require.NoError(t, jsonStore.SetField("doc1", "$.age", []byte(`42`)))
+		require.NoError(t, jsonStore.SetField("doc1", "$.tags", []byte(`["a", "b"]`)))
+
+		// setting a field to its current value isn't reported as a missing record
+		require.NoError(t, jsonStore.SetField("doc1", "$.age", []byte(`42`)))
+
+		doc, err := store.Get("doc1")
+		require.NoError(t, err)
+		requireJSONEqual(t, `{"name":"alice","address":{"city":"Berlin"},"tags":["a","b"],"age":42}`, doc)
+
+		field, err := jsonStore.GetField("doc1", "$.tags[1]")
+		require.NoError(t, err)
+		require.Equal(t, `"b"`, string(field))
+
+		err = jsonStore.SetField("missing", "$.name", []byte(`"bob"`))
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		_, err = store.Get("missing")
+		require.Equal(t, storage.ErrDataNotFound, err)
+	})
+
+	t.Run("Test set field keeps the expiry", func(t *testing.T) {
+		expiringStore, ok := store.(storage.ExpiringStore)
+		require.True(t, ok)
+
+		require.NoError(t, expiringStore.PutWithExpiry("doc2", []byte(`{"name":"bob"}`), 100*time.Millisecond))
+		require.NoError(t, jsonStore.SetField("doc2", "$.name", []byte(`"carol"`)))
+
+		time.Sleep(200 * time.Millisecond)
+
+		_, err := jsonStore.GetField("doc2", "$.name")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		err = jsonStore.SetField("doc2", "$.name", []byte(`"dave"`))
+		require.Equal(t, storage.ErrDataNotFound, err)
+	})
+
+	t.Run("Test put if match compares JSON documents", func(t *testing.T) {
+		require.NoError(t, store.Put("doc3", []byte(`{"n":1}`)))
+
+		stored, err := store.PutIfMatch("doc3", []byte(`{ "n" : 1 }`), []byte(`{"n":2}`))
+		require.NoError(t, err)
+		require.True(t, stored)
+
+		stored, err = store.PutIfMatch("doc3", []byte(`{"n":1}`), []byte(`{"n":3}`))
+		require.NoError(t, err)
+		require.False(t, stored)
+
+		stored, err = store.PutIfMatch("doc3", []byte(`{ "n" : 2 }`), []byte(`{"n":2}`))
+		require.NoError(t, err)
+		require.True(t, stored)
+	})
+
+	t.Run("Test invalid arguments", func(t *testing.T) {
+		_, err := jsonStore.GetField("", "$.name")
+		require.Equal(t, storage.ErrKeyRequired, err)
+
+		_, err = jsonStore.GetField("doc1", "")
+		require.EqualError(t, err, "JSON path is mandatory")
+
+		err = jsonStore.SetField("", "$.name", []byte(`"bob"`))
+		require.Equal(t, storage.ErrKeyRequired, err)
+
+		err = jsonStore.SetField("doc1", "", []byte(`"bob"`))
+		require.EqualError(t, err, "JSON path is mandatory")
+
+		_, err = jsonStore.GetField("doc1", "name")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get field name")
+
+		var dbErr *storage.Error
+		require.True(t, errors.As(err, &dbErr))
+
+		err = jsonStore.SetField("doc1", "$.name", []byte(`bob`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to set field $.name")
+
+		field, err := jsonStore.GetField("doc1", "$.name")
+		require.NoError(t, err)
+		require.Equal(t, `"alice"`, string(field))
+
+		err = store.Put("doc4", []byte("not JSON"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to insert key and value record")
+	})
+
+	t.Run("Test stores without JSON column", func(t *testing.T) {
+		blobProv, err := NewProvider(sqlStoreDBURL, WithDBPrefix("jsondb"), WithExpiryCleanupInterval(0))
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, blobProv.Close())
+		}()
+
+		blobStore, err := blobProv.OpenStore("testBlobFields")
+		require.NoError(t, err)
+
+		require.NoError(t, blobStore.Put("doc1", []byte(`{"name":"alice"}`)))
+
+		_, err = blobStore.(storage.JSONStore).GetField("doc1", "$.name")
+		require.EqualError(t, err,
+			"value column of table `jsondb_testBlobFields`.`t_jsondb_testBlobFields` isn't a JSON column")
+
+		err = blobStore.(storage.JSONStore).SetField("doc1", "$.name", []byte(`"bob"`))
+		require.EqualError(t, err,
+			"value column of table `jsondb_testBlobFields`.`t_jsondb_testBlobFields` isn't a JSON column")
+
+		// the JSON column of existing tables is found without the option
+		reopened, err := blobProv.OpenStore("testJSONFields")
+		require.NoError(t, err)
+
+		field, err := reopened.(storage.JSONStore).GetField("doc1", "$.name")
+		require.NoError(t, err)
+		require.Equal(t, `"alice"`, string(field))
+	})
+}
+
+func requireJSONEqual(t *testing.T, expected string, actual []byte) {
+	t.Helper()
+
+	var actualDoc interface{}
+	require.NoError(t, json.Unmarshal(actual, &actualDoc))
+
+	var expectedDoc interface{}
+	require.NoError(t, json.Unmarshal([]byte(expected), &expectedDoc))
+
+	require.Equal(t, expectedDoc, actualDoc)
+}
@@ -0,0 +1,213 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+)
+
+// defaultSlowQueryThreshold is the duration above which the statements logged by WithLogger are logged as slow
+const defaultSlowQueryThreshold = time.Second
+
+// WithLogger option makes the stores log every statement they execute with l: at debug level with its duration, or
+// at warning level when it's slower than the threshold set by WithSlowQueryThreshold. Only the statements are logged,
+// their arguments (keys, values, tags) are redacted. The duration of a query doesn't include reading its rows.
+// Statements aren't logged by default. It only applies to providers created with NewProvider.
+func WithLogger(l log.Logger) Option {
+	return func(opts *Provider) {
+		opts.statementLogger = l
+	}
+}
+
+// WithSlowQueryThreshold option sets the duration above which the statements logged by WithLogger are logged at
+// warning level, default is one second. Slow statements are logged at debug level like the others when d isn't
+// positive.
+func WithSlowQueryThreshold(d time.Duration) Option {
+	return func(opts *Provider) {
+		opts.slowQueryThreshold = d
+	}
+}
+
+// openDB opens a connection pool with the DSN, logging its statements when the provider has a statement logger.
+func (p *Provider) openDB(dsn string) (*sql.DB, error) {
+	if p.statementLogger == nil {
+		return sql.Open("mysql", dsn)
+	}
+
+	dsnConfig, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	connector, err := mysql.NewConnector(dsnConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return sql.OpenDB(&loggingConnector{
+		Connector: connector,
+		log:       &statementLog{logger: p.statementLogger, slowThreshold: p.slowQueryThreshold},
+	}), nil
+}
+
+// statementLog logs the statements executed by the connections of a pool.
+type statementLog struct {
+	logger        log.Logger
+	slowThreshold time.Duration
+}
+
+// record logs the statement started at start with argsCount arguments, the arguments themselves are never logged.
+// The driver.ErrSkip errors aren't logged, database/sql executes the statement again as a prepared statement.
+func (l *statementLog) record(query string, argsCount int, start time.Time, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	elapsed := time.Since(start)
+
+	status := "executed"
+	if err != nil {
+		// the error isn't logged as it may quote the arguments, it's returned to the caller anyway
+		status = "failed"
+	}
+
+	msg := fmt.Sprintf("statement %s in %s", status, elapsed)
+	if argsCount > 0 {
+		msg += fmt.Sprintf(" (%d redacted args)", argsCount)
+	}
+
+	msg += ": " + query
+
+	if l.slowThreshold > 0 && elapsed > l.slowThreshold {
+		l.logger.Warnf("slow %s", msg)
+
+		return
+	}
+
+	l.logger.Debugf("%s", msg)
+}
+
+// loggingConnector wraps the connections of the MySQL driver connector to log their statements.
+type loggingConnector struct {
+	driver.Connector
+	log *statementLog
+}
+
+// driverConn is the set of interfaces implemented by the connections of the MySQL driver.
+type driverConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.NamedValueChecker
+}
+
+// Connect opens a connection with the MySQL driver, its statements are logged.
+func (c *loggingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dc, ok := conn.(driverConn)
+	if !ok {
+		// wrapping the connection would hide the interfaces it implements from database/sql
+		return conn, nil
+	}
+
+	return &loggingConn{driverConn: dc, log: c.log}, nil
+}
+
+type loggingConn struct {
+	driverConn
+	log *statementLog
+}
+
+func (c *loggingConn) ExecContext(ctx context.Context, query string,
+	args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+
+	res, err := c.driverConn.ExecContext(ctx, query, args)
+
+	c.log.record(query, len(args), start, err)
+
+	return res, err
+}
+
+func (c *loggingConn) QueryContext(ctx context.Context, query string,
+	args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+
+	rows, err := c.driverConn.QueryContext(ctx, query, args)
+
+	c.log.record(query, len(args), start, err)
+
+	return rows, err
+}
+
+func (c *loggingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	start := time.Now()
+
+	stmt, err := c.driverConn.PrepareContext(ctx, query)
+	if err != nil {
+		// the statement is only logged here when it can't be prepared, ie it refers to a missing table
+		c.log.record(query, 0, start, err)
+
+		return nil, err
+	}
+
+	ds, ok := stmt.(driverStmt)
+	if !ok {
+		return stmt, nil
+	}
+
+	return &loggingStmt{driverStmt: ds, query: query, log: c.log}, nil
+}
+
+// driverStmt is the set of interfaces implemented by the prepared statements of the MySQL driver.
+type driverStmt interface {
+	driver.Stmt
+	driver.StmtExecContext
+	driver.StmtQueryContext
+}
+
+type loggingStmt struct {
+	driverStmt
+	query string
+	log   *statementLog
+}
+
+func (s *loggingStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := time.Now()
+
+	res, err := s.driverStmt.ExecContext(ctx, args)
+
+	s.log.record(s.query, len(args), start, err)
+
+	return res, err
+}
+
+func (s *loggingStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := time.Now()
+
+	rows, err := s.driverStmt.QueryContext(ctx, args)
+
+	s.log.record(s.query, len(args), start, err)
+
+	return rows, err
+}
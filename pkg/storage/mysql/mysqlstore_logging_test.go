@@ -0,0 +1,157 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestSQLDBStoreWithLogger(t *testing.T) {
+	t.Run("statements are logged at debug level without their arguments", func(t *testing.T) {
+		l := &recordingLogger{}
+
+		prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"), WithLogger(l),
+			WithSlowQueryThreshold(time.Hour))
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testLogger")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("did:example:secret-key", []byte("secret-value")))
+
+		v, err := store.Get("did:example:secret-key")
+		require.NoError(t, err)
+		require.Equal(t, "secret-value", string(v))
+
+		itr := store.Iterator("did:", "did"+storage.EndKeySuffix)
+		require.True(t, itr.Next())
+		itr.Release()
+
+		// a failed statement is logged without its error
+		_, err = store.(*sqlDBStore).db.Exec("SELECT `secret` FROM `missing_table` WHERE `key` = ?", "secret-key")
+		require.Error(t, err)
+
+		require.NoError(t, prov.Close())
+
+		debug, warn := l.messages()
+		require.Empty(t, warn)
+
+		const table = "`prefixdb_testLogger`.`t_prefixdb_testLogger`"
+
+		requireLogged(t, debug, "statement executed in", "INSERT INTO "+table+" (`key`, `value`)", "(2 redacted args)")
+		requireLogged(t, debug, "statement executed in", "SELECT `value` FROM "+table, "(1 redacted args)")
+		requireLogged(t, debug, "statement executed in", "SELECT `key`, `value` FROM "+table)
+		requireLogged(t, debug, "statement failed in", "FROM `missing_table`")
+
+		for _, msg := range debug {
+			require.NotContains(t, msg, "secret-key")
+			require.NotContains(t, msg, "secret-value")
+		}
+	})
+
+	t.Run("slow statements are logged at warning level", func(t *testing.T) {
+		l := &recordingLogger{}
+
+		prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"), WithLogger(l),
+			WithSlowQueryThreshold(time.Nanosecond))
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testLogger")
+		require.NoError(t, err)
+
+		_, err = store.Get("did:example:missing")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		require.NoError(t, prov.Close())
+
+		_, warn := l.messages()
+		requireLogged(t, warn, "slow statement executed in",
+			"SELECT `value` FROM `prefixdb_testLogger`.`t_prefixdb_testLogger`")
+	})
+
+	t.Run("statements aren't logged by default", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL)
+		require.NoError(t, err)
+		require.Nil(t, prov.statementLogger)
+		require.Equal(t, defaultSlowQueryThreshold, prov.slowQueryThreshold)
+
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("invalid DB URL", func(t *testing.T) {
+		_, err := NewProvider("root:@tcp(127.0.0.1:45454)", WithLogger(&recordingLogger{}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to open connection")
+	})
+}
+
+// requireLogged checks one of the messages contains all the parts.
+func requireLogged(t *testing.T, messages []string, parts ...string) {
+	t.Helper()
+
+	for _, msg := range messages {
+		found := true
+
+		for _, part := range parts {
+			if !strings.Contains(msg, part) {
+				found = false
+
+				break
+			}
+		}
+
+		if found {
+			return
+		}
+	}
+
+	require.Failf(t, "message not logged", "no message contains %q in %q", parts, messages)
+}
+
+// recordingLogger records the debug and warning messages it logs.
+type recordingLogger struct {
+	mutex sync.Mutex
+	debug []string
+	warn  []string
+}
+
+func (l *recordingLogger) messages() ([]string, []string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.debug, l.warn
+}
+
+func (l *recordingLogger) Debugf(msg string, args ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.debug = append(l.debug, fmt.Sprintf(msg, args...))
+}
+
+func (l *recordingLogger) Warnf(msg string, args ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.warn = append(l.warn, fmt.Sprintf(msg, args...))
+}
+
+func (l *recordingLogger) Fatalf(string, ...interface{}) {}
+
+func (l *recordingLogger) Panicf(string, ...interface{}) {}
+
+func (l *recordingLogger) Infof(string, ...interface{}) {}
+
+func (l *recordingLogger) Errorf(string, ...interface{}) {}
@@ -0,0 +1,84 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mysql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestSQLDBStoreIteratorPages(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"), WithIteratorPageSize(3))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testIteratorPages")
+	require.NoError(t, err)
+
+	var keys []string
+
+	for i := 0; i < 7; i++ {
+		key := fmt.Sprintf("page_%d", i)
+		keys = append(keys, key)
+
+		require.NoError(t, store.Put(key, []byte("value_"+key)))
+	}
+
+	t.Run("Test iterating over several pages", func(t *testing.T) {
+		require.Equal(t, keys, iteratedKeys(t, store.Iterator("page_", "page_"+storage.EndKeySuffix)))
+	})
+
+	t.Run("Test iterating over pages in reverse order", func(t *testing.T) {
+		reversed := make([]string, 0, len(keys))
+		for i := len(keys) - 1; i >= 0; i-- {
+			reversed = append(reversed, keys[i])
+		}
+
+		require.Equal(t, reversed, iteratedKeys(t, store.Iterator("page_", "page_"+storage.EndKeySuffix,
+			storage.WithReverse())))
+	})
+
+	t.Run("Test the limit spans the pages", func(t *testing.T) {
+		require.Equal(t, keys[:5], iteratedKeys(t, store.Iterator("page_", "page_"+storage.EndKeySuffix,
+			storage.WithLimit(5))))
+	})
+
+	t.Run("Test a range ending on a page boundary", func(t *testing.T) {
+		require.Equal(t, keys[:6], iteratedKeys(t, store.Iterator("page_", "page_6")))
+	})
+
+	t.Run("Test seeking resets the pages", func(t *testing.T) {
+		itr := store.Iterator("page_", "page_"+storage.EndKeySuffix)
+		defer itr.Release()
+
+		require.True(t, itr.Next())
+		require.True(t, itr.Seek("page_4"))
+		require.Equal(t, []byte("page_4"), itr.Key())
+		require.Equal(t, []byte("value_page_4"), itr.Value())
+
+		require.Equal(t, keys[5:], iteratedKeys(t, itr))
+	})
+}
+
+// iteratedKeys returns the keys returned by the remaining calls to Next of itr and releases it.
+func iteratedKeys(t *testing.T, itr storage.StoreIterator) []string {
+	t.Helper()
+
+	defer itr.Release()
+
+	var keys []string
+
+	for itr.Next() {
+		keys = append(keys, string(itr.Key()))
+	}
+
+	require.NoError(t, itr.Error())
+
+	return keys
+}
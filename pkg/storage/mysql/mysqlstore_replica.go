@@ -0,0 +1,90 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+var _ storage.PrimaryReader = (*sqlDBStore)(nil)
+
+// WithReadReplica option makes the stores read their records from the MySQL read replica at the given DB URL, the
+// stores sharing a second connection pool to the replica. Get, GetBulk, Has, Count, the iterators and
+// QueryByTag read from the replica, the writes, PutIfMatch and the transactions run on the primary. The replica may
+// lag behind the primary: a record may not be found or be stale right after it's written, GetPrimary reads it from the
+// primary instead. The databases and the tables of the stores are only created on the primary, they must be
+// replicated before the stores can read from the replica. The TLS config and the pool settings of the provider apply
+// to the replica too. It only applies to providers created with NewProvider.
+func WithReadReplica(dbURL string) Option {
+	return func(opts *Provider) {
+		opts.readReplicaURL = dbURL
+	}
+}
+
+// parseReadReplicaURL parses the DB URL of the read replica, using the TLS config of the provider unless it has its
+// own tls parameter.
+func (p *Provider) parseReadReplicaURL() (*mysql.Config, error) {
+	dsnConfig, err := mysql.ParseDSN(p.readReplicaURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse read replica DB URL: %w",
+			storage.RedactDSNError(err, p.readReplicaURL))
+	}
+
+	if p.tlsConfig != nil && dsnConfig.TLSConfig == "" {
+		dsnConfig.TLSConfig = p.tlsConfigName
+	}
+
+	return dsnConfig, nil
+}
+
+// openReadDB returns the connection pool of the reads of the stores, the pool of the read replica or the primary pool
+// when the provider has no read replica.
+func (p *Provider) openReadDB() (*sql.DB, error) {
+	if p.readReplicaURL == "" {
+		return p.db, nil
+	}
+
+	dsnConfig, err := p.parseReadReplicaURL()
+	if err != nil {
+		return nil, err
+	}
+
+	// unlike the primary pool, no connection is opened at once as the databases may not be replicated yet
+	readDB, err := p.openDB(dsnConfig.FormatDSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new read replica connection: %w",
+			storage.RedactDSNError(err, p.readReplicaURL))
+	}
+
+	p.applyPoolSettings(readDB)
+
+	return readDB, nil
+}
+
+// GetPrimary fetches the value based on key from the primary, ie to read a record right after writing it when the
+// store reads from a replica. It's the same as Get otherwise.
+func (s *sqlDBStore) GetPrimary(k string) ([]byte, error) {
+	ctx, cancel := s.withOperationTimeout(context.Background())
+	defer cancel()
+
+	var value []byte
+
+	err := s.retry.doContext(ctx, func() error {
+		var err error
+
+		value, err = get(ctx, s.db, s.tableName, k)
+
+		return err
+	})
+
+	return value, err
+}
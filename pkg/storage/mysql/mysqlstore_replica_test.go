@@ -0,0 +1,101 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mysql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestSQLDBStoreWithReadReplica(t *testing.T) {
+	t.Run("reads are routed to the read replica", func(t *testing.T) {
+		// the replica is the primary server itself, the routing is checked by closing the pool of the replica
+		prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"), WithReadReplica(sqlStoreDBURL))
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testReadReplica")
+		require.NoError(t, err)
+
+		sqlStore := store.(*sqlDBStore)
+		require.NotEqual(t, sqlStore.db, sqlStore.readDB)
+
+		require.NoError(t, store.Put("key_1", []byte("value1")))
+
+		v, err := store.Get("key_1")
+		require.NoError(t, err)
+		require.Equal(t, "value1", string(v))
+
+		itr := store.Iterator("key", "key"+storage.EndKeySuffix)
+		verifyItrKeys(t, itr, "key_1")
+
+		require.NoError(t, sqlStore.readDB.Close())
+
+		_, err = store.Get("key_1")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "database is closed")
+
+		_, err = sqlStore.Has("key_1")
+		require.Error(t, err)
+
+		_, err = store.GetBulk("key_1")
+		require.Error(t, err)
+
+		_, err = store.Count("", "")
+		require.Error(t, err)
+
+		itr = store.Iterator("key", "key"+storage.EndKeySuffix)
+		require.Error(t, itr.Error())
+
+		// the writes and GetPrimary use the primary
+		require.NoError(t, store.Put("key_2", []byte("value2")))
+
+		primaryReader, ok := store.(storage.PrimaryReader)
+		require.True(t, ok)
+
+		v, err = primaryReader.GetPrimary("key_2")
+		require.NoError(t, err)
+		require.Equal(t, "value2", string(v))
+
+		require.NoError(t, store.Delete("key_1"))
+		require.NoError(t, store.Delete("key_2"))
+
+		_, err = primaryReader.GetPrimary("key_2")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		// closing a closed pool is a no-op
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("reads use the primary without read replica", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testReadReplica")
+		require.NoError(t, err)
+
+		sqlStore := store.(*sqlDBStore)
+		require.Equal(t, sqlStore.db, sqlStore.readDB)
+
+		require.NoError(t, store.Put("key_1", []byte("value1")))
+
+		v, err := sqlStore.GetPrimary("key_1")
+		require.NoError(t, err)
+		require.Equal(t, "value1", string(v))
+
+		require.NoError(t, prov.CloseStore("testReadReplica"))
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("invalid read replica DB URL", func(t *testing.T) {
+		_, err := NewProvider(sqlStoreDBURL, WithReadReplica("root:@tcp(127.0.0.1:3306)"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to parse read replica DB URL")
+	})
+}
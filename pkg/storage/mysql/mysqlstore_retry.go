@@ -0,0 +1,85 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	// errLockWaitTimeout is the MySQL error number of ER_LOCK_WAIT_TIMEOUT
+	errLockWaitTimeout = 1205
+	// errLockDeadlock is the MySQL error number of ER_LOCK_DEADLOCK
+	errLockDeadlock = 1213
+)
+
+// retryPolicy retries the store operations failing with a transient error, doubling the backoff between attempts.
+// The zero value doesn't retry.
+type retryPolicy struct {
+	maxRetries int
+	backoff    time.Duration
+}
+
+// WithRetry option makes the stores retry their idempotent operations, and the transactions they run as a whole,
+// up to maxRetries times when they fail with a transient error: a lock wait timeout, a deadlock or a bad connection.
+// The backoff is doubled after every attempt.
+func WithRetry(maxRetries int, backoff time.Duration) Option {
+	return func(opts *Provider) {
+		opts.retry = retryPolicy{maxRetries: maxRetries, backoff: backoff}
+	}
+}
+
+// doContext executes op, retrying it as long as it fails with a transient error and retries are left. It gives up on
+// the retries as soon as ctx is done.
+func (r retryPolicy) doContext(ctx context.Context, op func() error) error {
+	err := op()
+
+	backoff := r.backoff
+
+	for attempt := 1; attempt <= r.maxRetries && isTransientErr(err); attempt++ {
+		logger.Debugf("retrying mysql operation after transient error (attempt %d): %s", attempt, err)
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return fmt.Errorf("retry interrupted: %s: %w", err.Error(), ctx.Err())
+		}
+
+		backoff *= 2
+
+		err = op()
+		if err != nil && attempt == r.maxRetries && isTransientErr(err) {
+			return fmt.Errorf("failed after %d retries: %w", r.maxRetries, err)
+		}
+	}
+
+	return err
+}
+
+// isTransientErr checks whether the error may not happen again when retrying the operation.
+func isTransientErr(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+
+	var mysqlErr *mysql.MySQLError
+
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == errLockWaitTimeout || mysqlErr.Number == errLockDeadlock
+	}
+
+	return false
+}
@@ -0,0 +1,238 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const flakyDriverName = "flaky-mysql"
+
+var registerFlakyDriver sync.Once //nolint:gochecknoglobals
+
+// flakyDriver wraps the MySQL driver, failing the next statements with a deadlock error
+type flakyDriver struct {
+	mutex    sync.Mutex
+	failures int
+}
+
+var flaky = &flakyDriver{} //nolint:gochecknoglobals
+
+func (d *flakyDriver) failNext(n int) {
+	d.mutex.Lock()
+	d.failures = n
+	d.mutex.Unlock()
+}
+
+func (d *flakyDriver) fail() error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if d.failures == 0 {
+		return nil
+	}
+
+	d.failures--
+
+	return &mysql.MySQLError{Number: errLockDeadlock, Message: "Deadlock found when trying to get lock"}
+}
+
+func (d *flakyDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := mysql.MySQLDriver{}.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &flakyConn{Conn: conn, driver: d}, nil
+}
+
+type flakyConn struct {
+	driver.Conn
+	driver *flakyDriver
+}
+
+func (c *flakyConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.driver.fail(); err != nil {
+		return nil, err
+	}
+
+	return c.Conn.(driver.ExecerContext).ExecContext(ctx, query, args)
+}
+
+func (c *flakyConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.driver.fail(); err != nil {
+		return nil, err
+	}
+
+	return c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+}
+
+func openFlakyDB(t *testing.T) *sql.DB {
+	registerFlakyDriver.Do(func() {
+		sql.Register(flakyDriverName, flaky)
+	})
+
+	db, err := sql.Open(flakyDriverName, sqlStoreDBURL)
+	require.NoError(t, err)
+
+	return db
+}
+
+func TestSQLDBStoreRetry(t *testing.T) {
+	db := openFlakyDB(t)
+
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+
+	t.Run("Test transient errors are retried", func(t *testing.T) {
+		prov, err := NewProviderWithDB(db, WithRetry(3, time.Millisecond))
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testRetry")
+		require.NoError(t, err)
+
+		flaky.failNext(2)
+		require.NoError(t, store.Put("key1", []byte("value1")))
+
+		flaky.failNext(2)
+		v, err := store.Get("key1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value1"), v)
+
+		flaky.failNext(2)
+		require.NoError(t, store.PutBatch([]storage.KeyValue{{Key: "key2", Value: []byte("value2")}}))
+
+		flaky.failNext(2)
+		values, err := store.GetBulk("key1", "key2")
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("value1"), []byte("value2")}, values)
+
+		flaky.failNext(2)
+		ok, err := store.Has("key2")
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		flaky.failNext(2)
+		count, err := store.Count("", "")
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+
+		flaky.failNext(2)
+		itr := store.Iterator("key", "key"+storage.EndKeySuffix)
+		require.NoError(t, itr.Error())
+		itr.Release()
+
+		flaky.failNext(2)
+		require.NoError(t, store.Delete("key1"))
+
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test retries exhausted", func(t *testing.T) {
+		prov, err := NewProviderWithDB(db, WithRetry(2, time.Millisecond))
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testRetry")
+		require.NoError(t, err)
+
+		flaky.failNext(3)
+
+		_, err = store.Get("key2")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed after 2 retries")
+
+		var mysqlErr *mysql.MySQLError
+		require.True(t, errors.As(err, &mysqlErr))
+		require.EqualValues(t, errLockDeadlock, mysqlErr.Number)
+
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test retries interrupted by the context", func(t *testing.T) {
+		prov, err := NewProviderWithDB(db, WithRetry(2, time.Hour))
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testRetry")
+		require.NoError(t, err)
+
+		ctxStore, ok := store.(storage.ContextStore)
+		require.True(t, ok)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		flaky.failNext(1)
+
+		_, err = ctxStore.GetContext(ctx, "key2")
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		require.Contains(t, err.Error(), "Deadlock found")
+
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test no retry by default", func(t *testing.T) {
+		prov, err := NewProviderWithDB(db)
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testRetry")
+		require.NoError(t, err)
+
+		flaky.failNext(1)
+
+		_, err = store.Get("key2")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Deadlock found")
+
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test deletes that aren't idempotent aren't retried", func(t *testing.T) {
+		prov, err := NewProviderWithDB(db, WithRetry(3, time.Millisecond), WithStrictDelete())
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testRetry")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("key3", []byte("value3")))
+
+		flaky.failNext(1)
+
+		err = store.Delete("key3")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Deadlock found")
+
+		flaky.failNext(1)
+
+		_, err = store.(storage.RangeDeleter).DeleteRange("key3", "key3"+storage.EndKeySuffix)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Deadlock found")
+
+		require.NoError(t, store.Delete("key3"))
+		require.NoError(t, prov.Close())
+	})
+}
+
+func TestIsTransientErr(t *testing.T) {
+	require.False(t, isTransientErr(nil))
+	require.False(t, isTransientErr(storage.ErrDataNotFound))
+	require.False(t, isTransientErr(&mysql.MySQLError{Number: 1062}))
+	require.True(t, isTransientErr(fmt.Errorf("failed to get row %w", driver.ErrBadConn)))
+	require.True(t, isTransientErr(fmt.Errorf("failed to get row %w", &mysql.MySQLError{Number: errLockWaitTimeout})))
+	require.True(t, isTransientErr(&mysql.MySQLError{Number: errLockDeadlock}))
+}
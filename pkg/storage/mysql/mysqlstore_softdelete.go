@@ -0,0 +1,50 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+var _ storage.SoftDeleteStore = (*sqlDBStore)(nil)
+
+// tombstoneAssignments replaces a record with its tombstone, which keeps the key along with its deletion time
+const tombstoneAssignments = "`value`=NULL, `expires_at`=NULL, `deleted_at`=NOW(6)"
+
+// IteratorIncludingDeleted returns an iterator over the records within the [startKey, endKey) range along with the
+// tombstones of the deleted records, whose values are nil. It has the same range semantics and options as Iterator.
+func (s *sqlDBStore) IteratorIncludingDeleted(startKey, endKey string,
+	opts ...storage.IteratorOption) storage.StoreIterator {
+	condition, args := s.rangeCondition(startKey, endKey)
+
+	return newRowsIterator(s, condition+" AND "+unexpiredRowCondition, args, storage.GetIteratorOptions(opts...))
+}
+
+// Purge deletes the tombstones of the records deleted before the given time. The deletion times are compared using
+// the clock of the MySQL server, the same clock setting them.
+func (s *sqlDBStore) Purge(before time.Time) error {
+	ctx, cancel := s.withOperationTimeout(context.Background())
+	defer cancel()
+
+	//nolint: gosec
+	// delete query to delete all the old tombstones at once
+	deleteStmt := "DELETE FROM " + s.tableName + " WHERE `deleted_at` < DATE_SUB(NOW(6), INTERVAL ? MICROSECOND)"
+
+	err := s.retry.doContext(ctx, func() error {
+		_, err := s.db.ExecContext(ctx, deleteStmt, time.Since(before).Microseconds())
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to purge tombstones from %s %w", s.tableName, dbError(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mysql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestSQLDBStoreSoftDelete(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"), WithSoftDelete())
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testSoftDelete")
+	require.NoError(t, err)
+
+	softDeleteStore, ok := store.(storage.SoftDeleteStore)
+	require.True(t, ok)
+
+	// the tombstones of former runs are removed
+	require.NoError(t, softDeleteStore.Purge(time.Now().Add(time.Hour)))
+
+	t.Run("Test deleted records are tombstones", func(t *testing.T) {
+		require.NoError(t, store.Put("soft1", []byte("value1")))
+		require.NoError(t, store.Put("soft2", []byte("value2")))
+		require.NoError(t, store.Delete("soft1"))
+
+		_, err := store.Get("soft1")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		found, err := store.Has("soft1")
+		require.NoError(t, err)
+		require.False(t, found)
+
+		count, err := store.Count("soft", "softz")
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+
+		itr := store.Iterator("soft", "softz")
+		require.True(t, itr.Next())
+		require.Equal(t, []byte("soft2"), itr.Key())
+		require.False(t, itr.Next())
+		itr.Release()
+
+		itr = softDeleteStore.IteratorIncludingDeleted("soft", "softz")
+		require.True(t, itr.Next())
+		require.Equal(t, []byte("soft1"), itr.Key())
+		require.Nil(t, itr.Value())
+		require.True(t, itr.Next())
+		require.Equal(t, []byte("soft2"), itr.Key())
+		require.Equal(t, []byte("value2"), itr.Value())
+		require.False(t, itr.Next())
+		require.NoError(t, itr.Error())
+		itr.Release()
+
+		rangeDeleter, ok := store.(storage.RangeDeleter)
+		require.True(t, ok)
+
+		deleted, err := rangeDeleter.DeleteRange("soft", "softz")
+		require.NoError(t, err)
+		require.Equal(t, 1, deleted)
+
+		_, err = store.Get("soft2")
+		require.Equal(t, storage.ErrDataNotFound, err)
+	})
+
+	t.Run("Test put replaces the tombstone", func(t *testing.T) {
+		require.NoError(t, store.Put("soft3", []byte("value")))
+		require.NoError(t, store.Delete("soft3"))
+		require.NoError(t, store.Put("soft3", []byte("value3")))
+
+		v, err := store.Get("soft3")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value3"), v)
+
+		require.NoError(t, store.Delete("soft3"))
+
+		stored, err := store.PutIfMatch("soft3", nil, []byte("value4"))
+		require.NoError(t, err)
+		require.True(t, stored)
+
+		v, err = store.Get("soft3")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value4"), v)
+
+		require.NoError(t, store.Delete("soft3"))
+	})
+
+	t.Run("Test purge removes the old tombstones", func(t *testing.T) {
+		require.NoError(t, store.Put("soft4", []byte("value")))
+		require.NoError(t, store.Delete("soft4"))
+
+		require.NoError(t, softDeleteStore.Purge(time.Now().Add(-time.Hour)))
+		require.True(t, hasKey(t, softDeleteStore, "soft4"))
+
+		require.NoError(t, softDeleteStore.Purge(time.Now().Add(time.Second)))
+		require.False(t, hasKey(t, softDeleteStore, "soft4"))
+	})
+
+	t.Run("Test strict delete of a tombstone", func(t *testing.T) {
+		strictProv, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"), WithSoftDelete(), WithStrictDelete())
+		require.NoError(t, err)
+
+		strictStore, err := strictProv.OpenStore("testSoftDelete")
+		require.NoError(t, err)
+
+		require.NoError(t, strictStore.Put("soft5", []byte("value")))
+		require.NoError(t, strictStore.Delete("soft5"))
+		require.Equal(t, storage.ErrDataNotFound, strictStore.Delete("soft5"))
+
+		require.NoError(t, strictProv.Close())
+	})
+
+	require.NoError(t, prov.Close())
+}
+
+func hasKey(t *testing.T, store storage.SoftDeleteStore, k string) bool {
+	t.Helper()
+
+	itr := store.IteratorIncludingDeleted(k, k+"\x00")
+	defer itr.Release()
+
+	found := itr.Next()
+	require.NoError(t, itr.Error())
+
+	return found
+}
@@ -0,0 +1,152 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+var _ storage.TaggedStore = (*sqlDBStore)(nil)
+
+// maxTagSize is the maximum number of characters of the tag names and values
+const maxTagSize = 255
+
+// createTagsTable creates the companion table holding the tags of the records of a store, indexed by key to be
+// replaced and deleted along with the records and by tag to be queried.
+func createTagsTable(db *sql.DB, tagsTableName string, keyColumnSize int, tableOptions string) error {
+	// the primary key would be too wide with the largest key columns, rows are replaced by key instead
+	createTableStmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s(`key` varchar(%d) NOT NULL, "+
+		"`name` varchar(%d) NOT NULL, `value` varchar(%d) NOT NULL, INDEX `key_idx` (`key`), "+
+		"INDEX `tag_idx` (`name`, `value`))%s;", tagsTableName, keyColumnSize, maxTagSize, maxTagSize, tableOptions)
+
+	if _, err := db.Exec(createTableStmt); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", tagsTableName, dbError(err))
+	}
+
+	return nil
+}
+
+// PutWithTags stores the key and the value and replaces the tags of the key within a single transaction. Tag names
+// and values are limited to 255 characters.
+func (s *sqlDBStore) PutWithTags(k string, v []byte, tags map[string]string) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	if v == nil {
+		return storage.ErrNilValue
+	}
+
+	if err := s.limits.check(k, v); err != nil {
+		return err
+	}
+
+	for name, value := range tags {
+		if name == "" {
+			return errors.New("tag name is mandatory")
+		}
+
+		if len([]rune(name)) > maxTagSize || len([]rune(value)) > maxTagSize {
+			return fmt.Errorf("tag %s exceeds %d characters", name, maxTagSize)
+		}
+	}
+
+	ctx, cancel := s.withOperationTimeout(context.Background())
+	defer cancel()
+
+	// the whole transaction is retried
+	return s.retry.doContext(ctx, func() error {
+		return s.putWithTags(ctx, k, v, tags)
+	})
+}
+
+func (s *sqlDBStore) putWithTags(ctx context.Context, k string, v []byte, tags map[string]string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for tagged insert into %s %w ", s.tableName, dbError(err))
+	}
+
+	err = s.putTags(ctx, tx, k, v, tags)
+	if err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return fmt.Errorf("failed to rollback tagged insert: %s: %w", rollbackErr.Error(), err)
+		}
+
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit tagged insert into %s %w ", s.tableName, dbError(err))
+	}
+
+	return nil
+}
+
+func (s *sqlDBStore) putTags(ctx context.Context, tx *sql.Tx, k string, v []byte, tags map[string]string) error {
+	if err := put(ctx, tx, s.tableName, s.limits, k, v); err != nil {
+		return err
+	}
+
+	if err := deleteTags(ctx, tx, s.tagsTableName, k); err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, 0, len(tags))
+	args := make([]interface{}, 0, 3*len(tags))
+
+	for name, value := range tags {
+		placeholders = append(placeholders, "(?, ?, ?)")
+		args = append(args, k, name, value)
+	}
+
+	//nolint: gosec
+	// create multi-row insert query for all the tags of the key
+	_, err := tx.ExecContext(ctx, "INSERT INTO "+s.tagsTableName+" (`key`, `name`, `value`) VALUES "+
+		strings.Join(placeholders, ", "), args...)
+	if err != nil {
+		return fmt.Errorf("failed to insert tags into %s %w ", s.tagsTableName, dbError(err))
+	}
+
+	return nil
+}
+
+func deleteTags(ctx context.Context, e sqlExecutor, tagsTableName, k string) error {
+	//nolint: gosec
+	// delete query to delete the tags of the record by key
+	_, err := e.ExecContext(ctx, "DELETE FROM "+tagsTableName+" WHERE `key` = ?", k)
+	if err != nil {
+		return fmt.Errorf("failed to delete tags %w", dbError(err))
+	}
+
+	return nil
+}
+
+// QueryByTag returns an iterator over the records tagged with the given name and value, in ascending key order. The
+// records are selected with a semi-join of the tags table on key.
+func (s *sqlDBStore) QueryByTag(name, value string) (storage.StoreIterator, error) {
+	if name == "" {
+		return nil, errors.New("tag name is mandatory")
+	}
+
+	//nolint: gosec
+	itr := newIterator(s, "`key` IN (SELECT `key` FROM "+s.tagsTableName+" WHERE `name` = ? AND `value` = ?)",
+		[]interface{}{name, value}, storage.IteratorOptions{})
+	if itr.err != nil {
+		return nil, itr.err
+	}
+
+	return itr, nil
+}
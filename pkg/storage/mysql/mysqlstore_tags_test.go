@@ -0,0 +1,201 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mysql
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+func TestSQLDBStoreQueryByTag(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"), WithExpiryCleanupInterval(0))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testQueryByTag")
+	require.NoError(t, err)
+
+	taggedStore, ok := store.(storage.TaggedStore)
+	require.True(t, ok)
+
+	require.NoError(t, taggedStore.PutWithTags("vc2", []byte("value2"), map[string]string{"issuer": "did:example:1"}))
+	require.NoError(t, taggedStore.PutWithTags("vc1", []byte("value1"),
+		map[string]string{"issuer": "did:example:1", "type": "UniversityDegreeCredential"}))
+	require.NoError(t, taggedStore.PutWithTags("vc3", []byte("value3"), map[string]string{"issuer": "did:example:2"}))
+	require.NoError(t, store.Put("vc4", []byte("value4")))
+
+	t.Run("Test query by tag", func(t *testing.T) {
+		itr, err := taggedStore.QueryByTag("issuer", "did:example:1")
+		require.NoError(t, err)
+
+		require.True(t, itr.Next())
+		require.Equal(t, []byte("vc1"), itr.Key())
+		require.Equal(t, []byte("value1"), itr.Value())
+		require.True(t, itr.Next())
+		require.Equal(t, []byte("vc2"), itr.Key())
+		require.False(t, itr.Next())
+		require.NoError(t, itr.Error())
+		itr.Release()
+
+		itr, err = taggedStore.QueryByTag("issuer", "did:example:1")
+		require.NoError(t, err)
+		require.True(t, itr.Seek("vc2"))
+		require.Equal(t, []byte("vc2"), itr.Key())
+		require.False(t, itr.Next())
+		itr.Release()
+
+		itr, err = taggedStore.QueryByTag("type", "unknown")
+		require.NoError(t, err)
+		verifyItrKeys(t, itr)
+	})
+
+	t.Run("Test put keeps the tags", func(t *testing.T) {
+		require.NoError(t, store.PutBatch([]storage.KeyValue{{Key: "vc3", Value: []byte("updated")}}))
+
+		itr, err := taggedStore.QueryByTag("issuer", "did:example:2")
+		require.NoError(t, err)
+		require.True(t, itr.Next())
+		require.Equal(t, []byte("updated"), itr.Value())
+		require.False(t, itr.Next())
+		itr.Release()
+	})
+
+	t.Run("Test put with tags replaces the tags", func(t *testing.T) {
+		require.NoError(t, taggedStore.PutWithTags("vc2", []byte("value2"), map[string]string{"issuer": "did:example:2"}))
+
+		itr, err := taggedStore.QueryByTag("issuer", "did:example:1")
+		require.NoError(t, err)
+		verifyItrKeys(t, itr, "vc1")
+
+		require.NoError(t, taggedStore.PutWithTags("vc2", []byte("value2"), nil))
+
+		itr, err = taggedStore.QueryByTag("issuer", "did:example:2")
+		require.NoError(t, err)
+		verifyItrKeys(t, itr, "vc3")
+	})
+
+	t.Run("Test deleting records deletes their tags", func(t *testing.T) {
+		require.NoError(t, store.Delete("vc1"))
+		require.NoError(t, store.Put("vc1", []byte("value1")))
+
+		itr, err := taggedStore.QueryByTag("type", "UniversityDegreeCredential")
+		require.NoError(t, err)
+		verifyItrKeys(t, itr)
+
+		rangeDeleter, ok := store.(storage.RangeDeleter)
+		require.True(t, ok)
+
+		_, err = rangeDeleter.DeleteRange("vc3", "vc3"+storage.EndKeySuffix)
+		require.NoError(t, err)
+		require.NoError(t, store.Put("vc3", []byte("value3")))
+
+		itr, err = taggedStore.QueryByTag("issuer", "did:example:2")
+		require.NoError(t, err)
+		verifyItrKeys(t, itr)
+	})
+
+	t.Run("Test tags of expired records", func(t *testing.T) {
+		require.NoError(t, taggedStore.PutWithTags("vc5", []byte("value5"), map[string]string{"issuer": "did:example:3"}))
+
+		expiringStore, ok := store.(storage.ExpiringStore)
+		require.True(t, ok)
+
+		require.NoError(t, expiringStore.PutWithExpiry("vc5", []byte("value5"), time.Millisecond))
+
+		time.Sleep(10 * time.Millisecond)
+
+		itr, err := taggedStore.QueryByTag("issuer", "did:example:3")
+		require.NoError(t, err)
+		verifyItrKeys(t, itr)
+
+		stored, err := store.PutIfMatch("vc5", nil, []byte("value5"))
+		require.NoError(t, err)
+		require.True(t, stored)
+
+		itr, err = taggedStore.QueryByTag("issuer", "did:example:3")
+		require.NoError(t, err)
+		verifyItrKeys(t, itr)
+
+		sqlStore, ok := store.(*sqlDBStore)
+		require.True(t, ok)
+
+		require.NoError(t, taggedStore.PutWithTags("vc6", []byte("value6"), map[string]string{"issuer": "did:example:3"}))
+		require.NoError(t, expiringStore.PutWithExpiry("vc6", []byte("value6"), time.Millisecond))
+
+		time.Sleep(10 * time.Millisecond)
+
+		require.NoError(t, sqlStore.deleteExpired())
+
+		var count int
+
+		err = sqlStore.db.QueryRow("SELECT COUNT(*) FROM " + sqlStore.tagsTableName + " WHERE `key` = 'vc6'").Scan(&count)
+		require.NoError(t, err)
+		require.Equal(t, 0, count)
+	})
+
+	t.Run("Test invalid arguments", func(t *testing.T) {
+		_, err := taggedStore.QueryByTag("", "value")
+		require.EqualError(t, err, "tag name is mandatory")
+
+		err = taggedStore.PutWithTags("", []byte("value"), nil)
+		require.Equal(t, storage.ErrKeyRequired, err)
+
+		err = taggedStore.PutWithTags("vc7", []byte("value"), map[string]string{"": "value"})
+		require.EqualError(t, err, "tag name is mandatory")
+
+		err = taggedStore.PutWithTags("vc7", []byte("value"), map[string]string{"type": strings.Repeat("a", 256)})
+		require.EqualError(t, err, "tag type exceeds 255 characters")
+	})
+
+	require.NoError(t, prov.Close())
+
+	t.Run("Test query failure", func(t *testing.T) {
+		_, err := taggedStore.QueryByTag("issuer", "did:example:1")
+		require.Error(t, err)
+
+		err = taggedStore.PutWithTags("vc7", []byte("value"), nil)
+		require.Error(t, err)
+	})
+}
+
+func TestSQLDBStoreTagsWithDB(t *testing.T) {
+	db, err := sql.Open("mysql", sqlStoreDBURL)
+	require.NoError(t, err)
+
+	prov, err := NewProviderWithDB(db, WithExpiryCleanupInterval(0))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testTagsWithDB")
+	require.NoError(t, err)
+
+	taggedStore, ok := store.(storage.TaggedStore)
+	require.True(t, ok)
+
+	require.NoError(t, taggedStore.PutWithTags("vc1", []byte("value1"), map[string]string{"issuer": "did:example:1"}))
+
+	itr, err := taggedStore.QueryByTag("issuer", "did:example:1")
+	require.NoError(t, err)
+	verifyItrKeys(t, itr, "vc1")
+
+	tx, err := store.(storage.Transactional).Begin()
+	require.NoError(t, err)
+	require.NoError(t, tx.Delete("vc1"))
+	require.NoError(t, tx.Commit())
+
+	var count int
+
+	err = db.QueryRow("SELECT COUNT(*) FROM `testTagsWithDB`.`t_testTagsWithDB_tags`").Scan(&count)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+
+	require.NoError(t, prov.Close())
+	require.NoError(t, db.Close())
+}
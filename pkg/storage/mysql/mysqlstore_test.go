@@ -6,18 +6,28 @@ SPDX-License-Identifier: Apache-2.0
 package mysql
 
 import (
+	"context"
+	"crypto/tls"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"math"
 	"os"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/storagetest"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
 const (
@@ -166,7 +176,8 @@ func TestSQLDBStore(t *testing.T) {
 		require.NoError(t, err)
 
 		storeErr := &sqlDBStore{
-			db: prov.db,
+			db:     prov.db,
+			readDB: prov.db,
 		}
 		const commonKey = "did:example:1"
 		data := []byte("value1")
@@ -185,9 +196,17 @@ func TestSQLDBStore(t *testing.T) {
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "failed to delete row")
 
+		_, err = storeErr.Count("", "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to count rows")
+
 		itr := storeErr.Iterator(commonKey, "test")
 		require.Error(t, itr.Error())
 		require.Contains(t, itr.Error().Error(), "failed to query rows")
+		require.False(t, itr.Next())
+		require.False(t, itr.Seek(commonKey))
+		require.Nil(t, itr.Key())
+		require.Nil(t, itr.Value())
 	})
 	t.Run("Test sql db store failures", func(t *testing.T) {
 		prov, err := NewProvider("")
@@ -209,23 +228,32 @@ func TestSQLDBStore(t *testing.T) {
 		require.Nil(t, store)
 	})
 
-	t.Run("Test the open new connection error", func(t *testing.T) {
-		prov, err := NewProvider(sqlStoreDBURL)
+	t.Run("Test the stores share the connection pool of the provider", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
 		require.NoError(t, err)
 
-		// invalid db url
-		prov.dbURL = "fake-url"
+		store1, err := prov.OpenStore("testSharedPool1")
+		require.NoError(t, err)
 
-		_, err = prov.OpenStore("testErr")
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "failed to create new connection fake-url")
+		store2, err := prov.OpenStore("testSharedPool2")
+		require.NoError(t, err)
 
-		//  valid but not available db url
-		prov.dbURL = "root:my-secret-pw@tcp(127.0.0.1:3307)/"
+		require.Equal(t, prov.db, store1.(*sqlDBStore).db)
+		require.Equal(t, prov.db, store2.(*sqlDBStore).db)
 
-		_, err = prov.OpenStore("testErr")
-		require.Error(t, err)
-		require.Contains(t, err.Error(), "failed to use db testErr")
+		// the tables of the stores are still isolated
+		require.NoError(t, store1.Put("key", []byte("value")))
+		require.NoError(t, store2.Delete("key"))
+
+		_, err = store2.Get("key")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+		v, err := store1.Get("key")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value"), v)
+
+		require.NoError(t, store1.Delete("key"))
+		require.NoError(t, prov.Close())
 	})
 
 	t.Run("Test sqlDB multi store close by name", func(t *testing.T) {
@@ -308,14 +336,262 @@ func TestSQLDBStore(t *testing.T) {
 		itr = store.Iterator("", "")
 		verifyItr(t, itr, 0, "")
 
+		// an empty end key leaves the range unbounded
+		itr = store.Iterator("abc_125", "")
+		verifyItrKeys(t, itr, "abc_125", "abc_126", "jkl_123", "mno_123")
+
+		itr = store.Iterator("jkl_", "", storage.WithReverse())
+		verifyItrKeys(t, itr, "mno_123", "jkl_123")
+
+		count, err := store.Count("jkl_", "")
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+
+		itr = store.(storage.FullIterator).IteratorAll()
+		verifyItrKeys(t, itr, keys...)
+
+		itr = store.(storage.FullIterator).IteratorAll(storage.WithLimit(1))
+		verifyItrKeys(t, itr, "abc_123")
+
 		itr = store.Iterator("abc_", "mno"+storage.EndKeySuffix)
 		verifyItr(t, itr, 6, "")
 
 		itr = store.Iterator("abc_", "mno_123")
 		verifyItr(t, itr, 5, "")
+
+		itr = store.Iterator("abc_", "abc"+storage.EndKeySuffix, storage.WithReverse())
+		verifyItrKeys(t, itr, "abc_126", "abc_125", "abc_124", "abc_123")
+
+		// the end key remains excluded from the range when iterating in reverse
+		itr = store.Iterator("abc_", "mno_123", storage.WithReverse())
+		verifyItrKeys(t, itr, "jkl_123", "abc_126", "abc_125", "abc_124", "abc_123")
+
+		itr = store.Iterator("abc_", "abc"+storage.EndKeySuffix, storage.WithLimit(2))
+		verifyItrKeys(t, itr, "abc_123", "abc_124")
+
+		itr = store.Iterator("abc_", "mno_123", storage.WithReverse(), storage.WithLimit(2))
+		verifyItrKeys(t, itr, "jkl_123", "abc_126")
+
+		// a zero limit means unlimited
+		itr = store.Iterator("abc_", "abc"+storage.EndKeySuffix, storage.WithLimit(0))
+		verifyItr(t, itr, 4, "abc_")
+
+		itr = store.Iterator("abc_", "mno_123")
+		require.True(t, itr.Seek("abc_125"))
+		require.Equal(t, "abc_125", string(itr.Key()))
+		require.Equal(t, "val-for-abc_125", string(itr.Value()))
+		verifyItrKeys(t, itr, "abc_126", "jkl_123")
+
+		itr = store.Iterator("abc_", "mno_123")
+		require.True(t, itr.Seek("abc_1255"))
+		require.Equal(t, "abc_126", string(itr.Key()))
+		require.True(t, itr.Seek("a"))
+		require.Equal(t, "abc_123", string(itr.Key()))
+		require.False(t, itr.Seek("mno_123"))
+		require.NoError(t, itr.Error())
+		itr.Release()
+
+		itr = store.Iterator("abc_", "mno_123", storage.WithReverse())
+		require.True(t, itr.Seek("abc_1255"))
+		require.Equal(t, "abc_125", string(itr.Key()))
+		verifyItrKeys(t, itr, "abc_124", "abc_123")
+
+		itr = store.Iterator("abc_", "mno_123", storage.WithReverse())
+		require.True(t, itr.Seek("z"))
+		require.Equal(t, "jkl_123", string(itr.Key()))
+		require.False(t, itr.Seek("abc_"))
+		itr.Release()
+
+		itr = store.Iterator("abc_", "mno_123", storage.WithLimit(2))
+		require.True(t, itr.Seek("abc_125"))
+		verifyItrKeys(t, itr, "abc_126")
+
+		count, err = store.Count("abc_", "abc"+storage.EndKeySuffix)
+		require.NoError(t, err)
+		require.Equal(t, 4, count)
+
+		count, err = store.Count("abc_", "mno_123")
+		require.NoError(t, err)
+		require.Equal(t, 5, count)
+
+		count, err = store.Count("", "")
+		require.NoError(t, err)
+		require.Equal(t, len(keys), count)
+
+		// the total count ignores the limit and the pairs already reached
+		itr = store.Iterator("abc_", "mno_123", storage.WithReverse(), storage.WithLimit(2))
+		total, err := itr.TotalCount()
+		require.NoError(t, err)
+		require.Equal(t, 5, total)
+
+		verifyItrKeys(t, itr, "jkl_123", "abc_126")
+		total, err = itr.TotalCount()
+		require.NoError(t, err)
+		require.Equal(t, 5, total)
 	})
 }
 
+func TestSQLDBProviderConcurrentOpenStore(t *testing.T) {
+	l := &recordingLogger{}
+
+	prov, err := NewProvider(sqlStoreDBURL, WithLogger(l))
+	require.NoError(t, err)
+
+	const openers = 50
+
+	stores := make(chan storage.Store, openers)
+	errs := make(chan error, openers)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < openers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			store, e := prov.OpenStore("testConcurrentOpen")
+			if e != nil {
+				errs <- e
+
+				return
+			}
+
+			stores <- store
+		}()
+	}
+
+	wg.Wait()
+	close(stores)
+	close(errs)
+
+	for e := range errs {
+		require.NoError(t, e)
+	}
+
+	// every caller gets the same store, its table being created once
+	first := <-stores
+
+	for store := range stores {
+		require.True(t, first == store)
+	}
+
+	require.Len(t, prov.dbs, 1)
+
+	debug, _ := l.messages()
+
+	var creations int
+
+	for _, msg := range debug {
+		if strings.Contains(msg, "CREATE Table IF NOT EXISTS") {
+			creations++
+		}
+	}
+
+	require.Equal(t, 1, creations)
+
+	// the store can be closed and opened again concurrently
+	for i := 0; i < openers; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			if i%2 == 0 {
+				assert.NoError(t, prov.CloseStore("testConcurrentOpen"))
+
+				return
+			}
+
+			_, e := prov.OpenStore("testConcurrentOpen")
+			assert.NoError(t, e)
+		}(i)
+	}
+
+	wg.Wait()
+
+	require.NoError(t, prov.Close())
+}
+
+func TestSQLDBStoreErrors(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL)
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testErrors")
+	require.NoError(t, err)
+
+	missingTableStore := *store.(*sqlDBStore)
+	missingTableStore.tableName = "`testErrors`.`t_missing`"
+
+	var storageErr *storage.Error
+
+	// the MySQL error number is carried by the errors of the statements, the message prefixes being kept
+	_, err = missingTableStore.Get("did:example:1")
+	require.True(t, errors.As(err, &storageErr))
+	require.Equal(t, 1146, storageErr.Code)
+	require.Contains(t, err.Error(), "failed to get row")
+
+	var mysqlErr *mysql.MySQLError
+
+	require.True(t, errors.As(err, &mysqlErr))
+	require.Equal(t, uint16(1146), mysqlErr.Number)
+
+	err = missingTableStore.Put("did:example:1", []byte("value"))
+	require.True(t, errors.As(err, &storageErr))
+	require.Equal(t, 1146, storageErr.Code)
+	require.Contains(t, err.Error(), "failed to insert key and value record")
+
+	itr := missingTableStore.Iterator("did:", "did"+storage.EndKeySuffix)
+	require.True(t, errors.As(itr.Error(), &storageErr))
+	require.Equal(t, 1146, storageErr.Code)
+
+	require.NoError(t, prov.Close())
+
+	// errors without a MySQL error number, ie connection failures, have no code
+	_, err = store.Get("did:example:1")
+	require.True(t, errors.As(err, &storageErr))
+	require.Zero(t, storageErr.Code)
+}
+
+func TestSQLDBStoreKeysWithDBPrefix(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testKeysWithDBPrefix")
+	require.NoError(t, err)
+
+	// keys of legacy data already embedding the prefix aren't prefixed again
+	keys := []string{"prefixdb_key1", "prefixdb_testKeysWithDBPrefix_key2", "key3"}
+
+	for _, k := range keys {
+		require.NoError(t, store.Put(k, []byte("value-"+k)))
+
+		v, err := store.Get(k)
+		require.NoError(t, err)
+		require.Equal(t, []byte("value-"+k), v)
+	}
+
+	itr := store.Iterator("prefixdb_", "prefixdb"+storage.EndKeySuffix)
+	verifyItrKeys(t, itr, "prefixdb_key1", "prefixdb_testKeysWithDBPrefix_key2")
+
+	db, err := sql.Open("mysql", sqlStoreDBURL)
+	require.NoError(t, err)
+
+	var count int
+
+	err = db.QueryRow("SELECT COUNT(*) FROM `prefixdb_testKeysWithDBPrefix`.`t_prefixdb_testKeysWithDBPrefix` "+
+		"WHERE `key` IN (?, ?, ?)", keys[0], keys[1], keys[2]).Scan(&count)
+	require.NoError(t, err)
+	require.Equal(t, len(keys), count)
+
+	for _, k := range keys {
+		require.NoError(t, store.Delete(k))
+	}
+
+	require.NoError(t, db.Close())
+	require.NoError(t, prov.Close())
+}
+
 func TestSQLDBStoreDelete(t *testing.T) {
 	const commonKey = "did:example:1234"
 
@@ -349,25 +625,1420 @@ func TestSQLDBStoreDelete(t *testing.T) {
 	doc, err = store1.Get(commonKey)
 	require.EqualError(t, err, storage.ErrDataNotFound.Error())
 	require.Empty(t, doc)
+
+	// deleting a missing key succeeds by default
+	err = store1.Delete(commonKey)
+	require.NoError(t, err)
 }
 
-func verifyItr(t *testing.T, itr storage.StoreIterator, count int, prefix string) {
-	var vals []string
+func TestSQLDBStoreStrictDelete(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithStrictDelete(), WithExpiryCleanupInterval(0))
+	require.NoError(t, err)
 
-	for itr.Next() {
-		if prefix != "" {
-			require.True(t, strings.HasPrefix(string(itr.Key()), prefix))
-		}
+	store, err := prov.OpenStore("testStrictDelete")
+	require.NoError(t, err)
 
-		vals = append(vals, string(itr.Value()))
+	t.Run("Test delete of a missing key", func(t *testing.T) {
+		require.NoError(t, store.Put("key1", []byte("value1")))
+		require.NoError(t, store.Delete("key1"))
+
+		err := store.Delete("key1")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("Test delete of an expired key", func(t *testing.T) {
+		expiringStore, ok := store.(storage.ExpiringStore)
+		require.True(t, ok)
+
+		require.NoError(t, expiringStore.PutWithExpiry("key2", []byte("value2"), time.Millisecond))
+
+		time.Sleep(10 * time.Millisecond)
+
+		err := store.Delete("key2")
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	})
+
+	t.Run("Test delete within a transaction", func(t *testing.T) {
+		require.NoError(t, store.Put("key3", []byte("value3")))
+
+		tx, err := store.(storage.Transactional).Begin()
+		require.NoError(t, err)
+
+		require.NoError(t, tx.Delete("key3"))
+		require.True(t, errors.Is(tx.Delete("key3"), storage.ErrDataNotFound))
+		require.NoError(t, tx.Commit())
+	})
+
+	require.NoError(t, prov.Close())
+}
+
+func TestSQLDBStoreDeleteRange(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL)
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testDeleteRange")
+	require.NoError(t, err)
+
+	rangeDeleter, ok := store.(storage.RangeDeleter)
+	require.True(t, ok)
+
+	for _, key := range []string{"abc_123", "abc_124", "abc_125", "jkl_123", "mno_123"} {
+		require.NoError(t, store.Put(key, []byte("value-"+key)))
 	}
 
-	require.Len(t, vals, count)
+	// the deleted records are the ones returned by the iterator of the range
+	itr := store.Iterator("abc_", "abc"+storage.EndKeySuffix)
+	verifyItrKeys(t, itr, "abc_123", "abc_124", "abc_125")
 
-	itr.Release()
+	deleted, err := rangeDeleter.DeleteRange("abc_", "abc"+storage.EndKeySuffix)
+	require.NoError(t, err)
+	require.Equal(t, 3, deleted)
+
+	count, err := store.Count("", "")
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	deleted, err = rangeDeleter.DeleteRange("abc_", "abc"+storage.EndKeySuffix)
+	require.NoError(t, err)
+	require.Equal(t, 0, deleted)
+
+	// the end key is excluded from the range
+	deleted, err = rangeDeleter.DeleteRange("jkl_", "mno_123")
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+
+	found, err := store.Has("mno_123")
+	require.NoError(t, err)
+	require.True(t, found)
+
+	// the range of two empty keys matches nothing while an empty end key leaves the range unbounded
+	deleted, err = rangeDeleter.DeleteRange("", "")
+	require.NoError(t, err)
+	require.Equal(t, 0, deleted)
+
+	deleted, err = rangeDeleter.DeleteRange("mno_", "")
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+
+	require.NoError(t, prov.Close())
+}
+
+func TestSQLDBStorePoolSettings(t *testing.T) {
+	t.Run("Test sql db pool settings", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithMaxOpenConns(5), WithMaxIdleConns(1),
+			WithConnMaxLifetime(time.Minute))
+		require.NoError(t, err)
+
+		require.Equal(t, 5, prov.db.Stats().MaxOpenConnections)
+
+		store, err := prov.OpenStore("testPool")
+		require.NoError(t, err)
+
+		sqlStore, ok := store.(*sqlDBStore)
+		require.True(t, ok)
+		require.Equal(t, 5, sqlStore.db.Stats().MaxOpenConnections)
+
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test sql db default pool settings", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL)
+		require.NoError(t, err)
+
+		// zero means unlimited, as for the driver defaults
+		require.Equal(t, 0, prov.db.Stats().MaxOpenConnections)
+
+		require.NoError(t, prov.Close())
+	})
+}
+
+func TestSQLDBProviderTruncate(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"), WithExpiryCleanupInterval(0))
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, prov.Close())
+	}()
+
+	var truncater storage.Truncater = prov
+
+	store, err := prov.OpenStore("testTruncate")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("k1", []byte("v1")))
+	require.NoError(t, store.(storage.TaggedStore).PutWithTags("k2", []byte("v2"), map[string]string{"tag": "v"}))
+
+	require.NoError(t, truncater.Truncate("testTruncate"))
+
+	count, err := store.Count("", "~")
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	itr, err := store.(storage.TaggedStore).QueryByTag("tag", "v")
+	require.NoError(t, err)
 	require.False(t, itr.Next())
-	require.Empty(t, itr.Key())
-	require.Empty(t, itr.Value())
-	require.Error(t, itr.Error())
-	require.Contains(t, itr.Error().Error(), "sql: Rows are closed")
+	itr.Release()
+
+	// the store stays open
+	require.NoError(t, store.Put("k1", []byte("v1")))
+
+	v, err := store.Get("k1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("v1"), v)
+
+	t.Run("test missing store", func(t *testing.T) {
+		require.NoError(t, prov.Truncate("testTruncateMissing"))
+
+		_, err := prov.OpenExistingStore("testTruncateMissing")
+		require.True(t, errors.Is(err, storage.ErrStoreNotFound))
+
+		strictProv, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"), WithStrictDelete())
+		require.NoError(t, err)
+
+		err = strictProv.Truncate("testTruncateMissing")
+		require.True(t, errors.Is(err, storage.ErrStoreNotFound))
+
+		require.NoError(t, strictProv.Close())
+	})
+
+	t.Run("test blank store name", func(t *testing.T) {
+		require.EqualError(t, prov.Truncate(""), "store name is required")
+	})
+}
+
+func TestSQLDBProviderOpenExistingStore(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+	require.NoError(t, err)
+
+	var opener storage.ExistingStoreOpener = prov
+
+	// the misspelled store isn't created
+	_, err = opener.OpenExistingStore("testExistingStoreTypo")
+	require.True(t, errors.Is(err, storage.ErrStoreNotFound))
+
+	names, err := prov.StoreNames()
+	require.NoError(t, err)
+	require.NotContains(t, names, "testExistingStoreTypo")
+
+	store, err := prov.OpenStore("testExistingStore")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key", []byte("value")))
+	require.NoError(t, prov.Close())
+
+	// the store is opened by a new provider
+	prov, err = NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+	require.NoError(t, err)
+
+	store, err = prov.OpenExistingStore("testExistingStore")
+	require.NoError(t, err)
+
+	v, err := store.Get("key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), v)
+
+	sameStore, err := prov.OpenExistingStore("testExistingStore")
+	require.NoError(t, err)
+	require.Same(t, store, sameStore)
+
+	require.NoError(t, prov.Close())
+
+	_, err = prov.OpenExistingStore("testExistingStoreClosed")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to check table of store prefixdb_testExistingStoreClosed")
+}
+
+func TestSQLDBStoreApproxSizeBytes(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("sizedb"))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testApproxSize")
+	require.NoError(t, err)
+
+	sizeEstimator, ok := store.(storage.SizeEstimator)
+	require.True(t, ok)
+
+	// InnoDB tables have allocated pages even when they are empty
+	size, err := sizeEstimator.ApproxSizeBytes()
+	require.NoError(t, err)
+	require.True(t, size > 0)
+
+	require.NoError(t, prov.Close())
+
+	_, err = sizeEstimator.ApproxSizeBytes()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to get size of table `sizedb_testApproxSize`.`t_sizedb_testApproxSize`")
+}
+
+func TestSQLDBProviderStoreNames(t *testing.T) {
+	t.Run("Test store names with DB prefix", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("storenames"))
+		require.NoError(t, err)
+
+		for _, name := range []string{"store_2", "store_1"} {
+			_, err = prov.OpenStore(name)
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, prov.CloseStore("store_2"))
+
+		var lister storage.StoreLister = prov
+
+		names, err := lister.StoreNames()
+		require.NoError(t, err)
+		require.Equal(t, []string{"store_1", "store_2"}, names)
+
+		require.NoError(t, prov.Close())
+
+		// the stores of another prefix aren't listed
+		prov, err = NewProvider(sqlStoreDBURL, WithDBPrefix("storenames2"))
+		require.NoError(t, err)
+
+		names, err = prov.StoreNames()
+		require.NoError(t, err)
+		require.Empty(t, names)
+
+		require.NoError(t, prov.Close())
+
+		_, err = prov.StoreNames()
+		require.Error(t, err)
+	})
+
+	t.Run("Test store names with table name func", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("storenames3"), WithTableNameFunc(func(name string) string {
+			return "kv_" + name
+		}))
+		require.NoError(t, err)
+
+		_, err = prov.OpenStore("store")
+		require.NoError(t, err)
+
+		names, err := prov.StoreNames()
+		require.NoError(t, err)
+		require.Equal(t, []string{"store"}, names)
+
+		require.NoError(t, prov.Close())
+	})
+}
+
+func TestSQLDBProviderPing(t *testing.T) {
+	t.Run("Test sql db provider ping and health", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL)
+		require.NoError(t, err)
+
+		require.NoError(t, prov.Ping(context.Background()))
+
+		health := storage.Health(context.Background(), prov)
+		require.Equal(t, storage.HealthStatusUp, health.Status)
+		require.NoError(t, health.Err)
+
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test sql db provider ping with an unreachable DB", func(t *testing.T) {
+		prov, err := NewProvider("root:@tcp(127.0.0.1:45454)/")
+		require.NoError(t, err)
+
+		err = prov.Ping(context.Background())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to ping MySQL")
+
+		health := storage.Health(context.Background(), prov)
+		require.Equal(t, storage.HealthStatusDown, health.Status)
+		require.Error(t, health.Err)
+	})
+
+	t.Run("Test health of a provider which can't ping", func(t *testing.T) {
+		health := storage.Health(context.Background(), mem.NewProvider())
+		require.Equal(t, storage.HealthStatusUnknown, health.Status)
+		require.NoError(t, health.Err)
+	})
+}
+
+func TestSQLDBStoreWithDB(t *testing.T) {
+	t.Run("Test sql db store with a DB owned by the caller", func(t *testing.T) {
+		db, err := sql.Open("mysql", sqlStoreDBURL)
+		require.NoError(t, err)
+
+		prov, err := NewProviderWithDB(db, WithDBPrefix("callerdb"))
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("key_1", []byte("value1")))
+		require.NoError(t, store.PutBatch([]storage.KeyValue{{Key: "key_2", Value: []byte("value2")}}))
+
+		v, err := store.Get("key_1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value1"), v)
+
+		itr := store.Iterator("key_", "key"+storage.EndKeySuffix)
+		verifyItr(t, itr, 2, "key_")
+
+		require.NoError(t, prov.CloseStore("test"))
+		require.NoError(t, prov.Close())
+
+		// the DB remains usable by the caller
+		require.NoError(t, db.Ping())
+		require.NoError(t, db.Close())
+	})
+
+	t.Run("Test sql db store with a nil DB", func(t *testing.T) {
+		prov, err := NewProviderWithDB(nil)
+		require.EqualError(t, err, "DB for new mySQL DB provider can't be nil")
+		require.Nil(t, prov)
+	})
+}
+
+func TestSQLDBStoreContext(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testContext")
+	require.NoError(t, err)
+
+	ctxStore, ok := store.(storage.ContextStore)
+	require.True(t, ok)
+
+	t.Run("Test sql db store operations with a context", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		require.NoError(t, ctxStore.PutContext(ctx, "key1", []byte("value1")))
+
+		doc, err := ctxStore.GetContext(ctx, "key1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value1"), doc)
+
+		require.NoError(t, ctxStore.DeleteContext(ctx, "key1"))
+
+		_, err = ctxStore.GetContext(ctx, "key1")
+		require.Equal(t, storage.ErrDataNotFound, err)
+	})
+
+	t.Run("Test sql db store operations with a cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := ctxStore.PutContext(ctx, "key1", []byte("value1"))
+		require.True(t, errors.Is(err, context.Canceled))
+		require.Contains(t, err.Error(), "failed to insert key and value record")
+
+		_, err = ctxStore.GetContext(ctx, "key1")
+		require.True(t, errors.Is(err, context.Canceled))
+		require.Contains(t, err.Error(), "failed to get row")
+
+		err = ctxStore.DeleteContext(ctx, "key1")
+		require.True(t, errors.Is(err, context.Canceled))
+		require.Contains(t, err.Error(), "failed to delete row")
+	})
+
+	require.NoError(t, prov.Close())
+}
+
+func TestSQLDBStoreOperationTimeout(t *testing.T) {
+	t.Run("Test sql db store operations within the timeout", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithOperationTimeout(time.Minute))
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testOperationTimeout")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("key1", []byte("value1")))
+
+		doc, err := store.Get("key1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value1"), doc)
+
+		verifyItrKeys(t, store.Iterator("key", "key"+storage.EndKeySuffix), "key1")
+
+		require.NoError(t, store.Delete("key1"))
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test sql db store operations exceeding the timeout", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithOperationTimeout(time.Nanosecond))
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testOperationTimeout")
+		require.NoError(t, err)
+
+		err = store.Put("key1", []byte("value1"))
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		require.Contains(t, err.Error(), "failed to insert key and value record")
+
+		_, err = store.Get("key1")
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		_, err = store.Has("key1")
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		_, err = store.Count("", "")
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		err = store.Delete("key1")
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+
+		itr := store.Iterator("key", "key"+storage.EndKeySuffix)
+		require.False(t, itr.Next())
+		require.True(t, errors.Is(itr.Error(), context.DeadlineExceeded))
+		itr.Release()
+
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test sql db store iterator scan exceeding the timeout", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithOperationTimeout(200*time.Millisecond))
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testOperationTimeout")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("key1", []byte("value1")))
+
+		itr := store.Iterator("key", "key"+storage.EndKeySuffix)
+		require.NoError(t, itr.Error())
+
+		time.Sleep(300 * time.Millisecond)
+
+		require.False(t, itr.Next())
+		require.True(t, errors.Is(itr.Error(), context.DeadlineExceeded))
+		itr.Release()
+
+		require.NoError(t, store.Delete("key1"))
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test sql db store operations are unbounded by default", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL)
+		require.NoError(t, err)
+		require.Zero(t, prov.operationTimeout)
+
+		require.NoError(t, prov.Close())
+	})
+}
+
+func TestSQLDBStoreColumnSettings(t *testing.T) {
+	t.Run("Test sql db store with a larger value column type", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithValueColumnType("mediumblob"), WithKeyColumnSize(512))
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testColumns")
+		require.NoError(t, err)
+
+		// larger than the 64KB of a BLOB column
+		value := []byte(strings.Repeat("v", 100*1024))
+		key := strings.Repeat("k", 300)
+
+		require.NoError(t, store.Put(key, value))
+
+		doc, err := store.Get(key)
+		require.NoError(t, err)
+		require.Equal(t, value, doc)
+
+		require.NoError(t, prov.Close())
+
+		// the existing store keeps working with the default settings
+		prov, err = NewProvider(sqlStoreDBURL)
+		require.NoError(t, err)
+
+		store, err = prov.OpenStore("testColumns")
+		require.NoError(t, err)
+
+		doc, err = store.Get(key)
+		require.NoError(t, err)
+		require.Equal(t, value, doc)
+
+		require.NoError(t, store.Delete(key))
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test sql db store with invalid column settings", func(t *testing.T) {
+		_, err := NewProvider(sqlStoreDBURL, WithValueColumnType("BLOB); DROP TABLE t_testColumns; --"))
+		require.EqualError(t, err, "unsupported value column type BLOB); DROP TABLE T_TESTCOLUMNS; --")
+
+		_, err = NewProvider(sqlStoreDBURL, WithKeyColumnSize(0))
+		require.EqualError(t, err, "key column size 0 must be between 1 and 768")
+
+		db, err := sql.Open("mysql", sqlStoreDBURL)
+		require.NoError(t, err)
+
+		_, err = NewProviderWithDB(db, WithKeyColumnSize(1000))
+		require.EqualError(t, err, "key column size 1000 must be between 1 and 768")
+
+		_, err = NewProviderWithDB(db, WithCharset("utf8mb4; DROP TABLE t_testColumns; --", ""))
+		require.EqualError(t, err, `invalid charset "utf8mb4; DROP TABLE t_testColumns; --"`)
+
+		_, err = NewProvider(sqlStoreDBURL, WithCharset("utf8mb4", "utf8mb4 bin"))
+		require.EqualError(t, err, `invalid collation "utf8mb4 bin"`)
+
+		require.NoError(t, db.Close())
+	})
+}
+
+func TestSQLDBStoreCharset(t *testing.T) {
+	// multibyte keys differing by case, accents or scripts, which non binary collations compare as equal or reorder
+	keys := []string{
+		"did:例:abc", "did:例:ABC", "did:例:ábc", "did:例:z", "did:例:_1", "did:例:中文", "did:例:😀",
+		"did:例子:abc", "did:e:abc", "did:ü:abc",
+	}
+
+	t.Run("Test sql db store ranges follow the byte order of the keys by default", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL)
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testCharset")
+		require.NoError(t, err)
+
+		memStore, err := mem.NewProvider().OpenStore("testCharset")
+		require.NoError(t, err)
+
+		for _, k := range keys {
+			require.NoError(t, store.Put(k, []byte("value-for-"+k)))
+			require.NoError(t, memStore.Put(k, []byte("value-for-"+k)))
+		}
+
+		for _, k := range keys {
+			v, e := store.Get(k)
+			require.NoError(t, e)
+			require.Equal(t, "value-for-"+k, string(v))
+		}
+
+		for _, r := range [][2]string{
+			{"did:例:", "did:例:" + storage.EndKeySuffix},
+			{"did:例", "did:例" + storage.EndKeySuffix},
+			{"did:", ""},
+		} {
+			expected := collectKeys(t, memStore.Iterator(r[0], r[1]))
+			require.NotEmpty(t, expected)
+			require.Equal(t, expected, collectKeys(t, store.Iterator(r[0], r[1])))
+
+			expected = collectKeys(t, memStore.Iterator(r[0], r[1], storage.WithReverse()))
+			require.Equal(t, expected, collectKeys(t, store.Iterator(r[0], r[1], storage.WithReverse())))
+		}
+
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test sql db store with a non binary collation", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithCharset("utf8mb4", "utf8mb4_0900_ai_ci"))
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("testCollation")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("abc_123", []byte("value")))
+		require.NoError(t, store.Put("ABC_124", []byte("value")))
+
+		// the keys are compared with the collation
+		v, err := store.Get("abc_124")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value"), v)
+
+		verifyItrKeys(t, store.Iterator("abc_", "abc"+storage.EndKeySuffix), "abc_123", "ABC_124")
+
+		require.NoError(t, prov.Close())
+	})
+}
+
+func collectKeys(t *testing.T, itr storage.StoreIterator) []string {
+	t.Helper()
+
+	var keys []string
+
+	for itr.Next() {
+		keys = append(keys, string(itr.Key()))
+	}
+
+	require.NoError(t, itr.Error())
+	itr.Release()
+
+	return keys
+}
+
+func TestSQLDBStoreTableNames(t *testing.T) {
+	t.Run("Test sql db store named after a reserved word", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL)
+		require.NoError(t, err)
+
+		require.Equal(t, "t_order", prov.TableName("order"))
+
+		store, err := prov.OpenStore("order")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("k1", []byte("v1")))
+
+		doc, err := store.Get("k1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), doc)
+
+		require.NoError(t, store.Delete("k1"))
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test sql db store with a custom table name func", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"), WithTableNameFunc(func(storeName string) string {
+			return "app_" + storeName + "_kv"
+		}))
+		require.NoError(t, err)
+
+		require.Equal(t, "app_order_kv", prov.TableName("order"))
+
+		store, err := prov.OpenStore("order")
+		require.NoError(t, err)
+
+		sqlStore, ok := store.(*sqlDBStore)
+		require.True(t, ok)
+		require.Equal(t, "`prefixdb_order`.`app_order_kv`", sqlStore.tableName)
+
+		require.NoError(t, store.Put("k1", []byte("v1")))
+
+		doc, err := store.Get("k1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), doc)
+
+		require.NoError(t, store.Delete("k1"))
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test sql db store default table name with a DB prefix", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+		require.NoError(t, err)
+
+		require.Equal(t, "t_prefixdb_order", prov.TableName("order"))
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test identifiers quoting", func(t *testing.T) {
+		require.Equal(t, "`order`", quoteIdentifier("order"))
+		require.Equal(t, "`t_x``; DROP TABLE t_order; --`", quoteIdentifier("t_x`; DROP TABLE t_order; --"))
+	})
+}
+
+func TestSQLDBProviderDBPrefix(t *testing.T) {
+	t.Run("Test invalid DB prefixes", func(t *testing.T) {
+		_, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefix-db"))
+		require.EqualError(t, err, `invalid DB prefix "prefix-db": it must start with a letter followed by letters, `+
+			"digits or underscores")
+
+		_, err = NewProvider(sqlStoreDBURL, WithDBPrefix("1prefixdb"))
+		require.EqualError(t, err, `invalid DB prefix "1prefixdb": it must start with a letter followed by letters, `+
+			"digits or underscores")
+
+		db, err := sql.Open("mysql", sqlStoreDBURL)
+		require.NoError(t, err)
+
+		longPrefix := strings.Repeat("p", maxDBPrefixLength+1)
+
+		_, err = NewProviderWithDB(db, WithDBPrefix(longPrefix))
+		require.EqualError(t, err, fmt.Sprintf("invalid DB prefix %q: it must be at most 55 characters long",
+			longPrefix))
+
+		require.NoError(t, db.Close())
+	})
+
+	t.Run("Test empty and trimmed DB prefixes", func(t *testing.T) {
+		for prefix, tableName := range map[string]string{
+			"":          "t_order",
+			"_":         "t_order",
+			"prefixdb_": "t_prefixdb_order",
+		} {
+			prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix(prefix))
+			require.NoError(t, err)
+			require.Equal(t, tableName, prov.TableName("order"))
+			require.NoError(t, prov.Close())
+		}
+	})
+
+	t.Run("Test too long store name with a DB prefix", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, prov.Close())
+		}()
+
+		// t_prefixdb_<name>_tags is the longest identifier of the store
+		name := strings.Repeat("s", maxIdentifierLength-len("t_prefixdb__tags"))
+
+		store, err := prov.OpenStore(name)
+		require.NoError(t, err)
+		require.NoError(t, store.Put("k1", []byte("v1")))
+
+		_, err = prov.OpenStore(name + "s")
+		require.EqualError(t, err, fmt.Sprintf("store name %ss is too long: t_prefixdb_%ss_tags exceeds the 64 "+
+			"characters of MySQL identifiers", name, name))
+
+		_, err = prov.OpenStore(strings.Repeat("s", maxIdentifierLength))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "exceeds the 64 characters of MySQL identifiers")
+	})
+
+	t.Run("Test too long store name with a custom table name func", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithTableNameFunc(func(storeName string) string {
+			return "kv"
+		}))
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, prov.Close())
+		}()
+
+		name := strings.Repeat("s", maxIdentifierLength+1)
+
+		_, err = prov.OpenStore(name)
+		require.EqualError(t, err, fmt.Sprintf("store name %s is too long: %s exceeds the 64 characters of MySQL "+
+			"identifiers", name, name))
+	})
+}
+
+func TestSQLDBStoreSizeLimits(t *testing.T) {
+	t.Run("Test invalid size limits", func(t *testing.T) {
+		_, err := NewProvider(sqlStoreDBURL, WithMaxKeySize(0))
+		require.EqualError(t, err, "maximum key size 0 and maximum value size 67043328 must be positive")
+
+		_, err = NewProvider(sqlStoreDBURL, WithMaxValueSize(-1))
+		require.EqualError(t, err, "maximum key size 3072 and maximum value size -1 must be positive")
+	})
+
+	t.Run("Test keys and values exceeding the limits are rejected", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithMaxKeySize(8), WithMaxValueSize(16))
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, prov.Close())
+		}()
+
+		store, err := prov.OpenStore("sizelimits")
+		require.NoError(t, err)
+		require.NoError(t, store.Delete("key"))
+
+		largeKey, largeValue := "key_12345", []byte("value_0123456789a")
+
+		require.NoError(t, store.Put("key_1234", largeValue[:16]))
+		require.Equal(t, storage.ErrKeyTooLarge, store.Put(largeKey, []byte("value")))
+		require.Equal(t, storage.ErrValueTooLarge, store.Put("key", largeValue))
+
+		err = store.PutBatch([]storage.KeyValue{{Key: "key", Value: []byte("value")}, {Key: "key2", Value: largeValue}})
+		require.Equal(t, storage.ErrValueTooLarge, err)
+
+		_, err = store.PutIfMatch(largeKey, nil, []byte("value"))
+		require.Equal(t, storage.ErrKeyTooLarge, err)
+
+		err = store.(storage.ExpiringStore).PutWithExpiry("key", largeValue, time.Minute)
+		require.Equal(t, storage.ErrValueTooLarge, err)
+
+		tx, err := store.(storage.Transactional).Begin()
+		require.NoError(t, err)
+		require.Equal(t, storage.ErrKeyTooLarge, tx.Put(largeKey, []byte("value")))
+		require.NoError(t, tx.Rollback())
+
+		// nothing was written by the rejected batch
+		found, err := store.Has("key")
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+}
+
+func TestSQLDBStoreTLSConfig(t *testing.T) {
+	t.Run("Test tls parameter added to the DB URL", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL, WithTLSConfig("custom", &tls.Config{ServerName: "127.0.0.1"}))
+		require.NoError(t, err)
+		require.Equal(t, sqlStoreDBURL+"?tls=custom", prov.dbURL)
+		require.NoError(t, prov.Close())
+
+		prov, err = NewProvider(sqlStoreDBURL+"?parseTime=true", WithTLSConfig("custom", &tls.Config{}))
+		require.NoError(t, err)
+		require.Equal(t, sqlStoreDBURL+"?parseTime=true&tls=custom", prov.dbURL)
+		require.NoError(t, prov.Close())
+
+		prov, err = NewProvider(sqlStoreDBURL+"?tls=custom", WithTLSConfig("custom", &tls.Config{}))
+		require.NoError(t, err)
+		require.Equal(t, sqlStoreDBURL+"?tls=custom", prov.dbURL)
+		require.NoError(t, prov.Close())
+	})
+
+	t.Run("Test conflicting tls parameter", func(t *testing.T) {
+		prov, err := NewProvider(sqlStoreDBURL+"?tls=skip-verify", WithTLSConfig("custom", &tls.Config{}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "DB URL tls parameter skip-verify conflicts with TLS config custom")
+		require.Nil(t, prov)
+	})
+
+	t.Run("Test TLS config failures", func(t *testing.T) {
+		// reserved name
+		prov, err := NewProvider(sqlStoreDBURL, WithTLSConfig("true", &tls.Config{}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to register TLS config true")
+		require.Nil(t, prov)
+
+		prov, err = NewProvider("invalid-db-url", WithTLSConfig("custom", &tls.Config{}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to parse DB URL")
+		require.Nil(t, prov)
+	})
+}
+
+func TestSQLDBProviderPasswordRedacted(t *testing.T) {
+	const invalidDBURL = "root:my-secret-pw@tcp(127.0.0.1:3306)/?invalid"
+
+	_, err := NewProvider(invalidDBURL, WithTLSConfig("custom", &tls.Config{}))
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "my-secret-pw")
+
+	_, err = NewProvider(sqlStoreDBURL, WithReadReplica(invalidDBURL))
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "my-secret-pw")
+
+	prov, err := NewProvider("root:my-secret-pw@tcp(127.0.0.1:45454)/")
+	require.NoError(t, err)
+
+	_, err = prov.OpenStore("testPasswordRedacted")
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "my-secret-pw")
+
+	_, err = prov.OpenExistingStore("testPasswordRedacted")
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "my-secret-pw")
+
+	require.NoError(t, prov.Close())
+}
+
+func TestSQLDBStorePutBatch(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testBatch")
+	require.NoError(t, err)
+
+	err = store.Put("key1", []byte("old-value1"))
+	require.NoError(t, err)
+
+	err = store.PutBatch([]storage.KeyValue{
+		{Key: "key1", Value: []byte("value1")},
+		{Key: "key2", Value: []byte("value2")},
+		{Key: "key3", Value: []byte("value3")},
+	})
+	require.NoError(t, err)
+
+	for k, v := range map[string]string{"key1": "value1", "key2": "value2", "key3": "value3"} {
+		doc, e := store.Get(k)
+		require.NoError(t, e)
+		require.Equal(t, []byte(v), doc)
+	}
+
+	// an empty key fails the whole batch before anything is written
+	err = store.PutBatch([]storage.KeyValue{
+		{Key: "key4", Value: []byte("value4")},
+		{Key: "", Value: []byte("value5")},
+	})
+	require.EqualError(t, err, "key is mandatory")
+
+	_, err = store.Get("key4")
+	require.EqualError(t, err, storage.ErrDataNotFound.Error())
+
+	// batch larger than the multi-row statement limit
+	kvs := make([]storage.KeyValue, maxBatchRows+10)
+	for i := range kvs {
+		kvs[i] = storage.KeyValue{Key: fmt.Sprintf("batch_%05d", i), Value: []byte(fmt.Sprintf("value-%d", i))}
+	}
+
+	err = store.PutBatch(kvs)
+	require.NoError(t, err)
+
+	doc, err := store.Get(fmt.Sprintf("batch_%05d", maxBatchRows+5))
+	require.NoError(t, err)
+	require.Equal(t, []byte(fmt.Sprintf("value-%d", maxBatchRows+5)), doc)
+
+	// empty batch
+	err = store.PutBatch(nil)
+	require.NoError(t, err)
+
+	require.NoError(t, prov.Close())
+
+	// closed db fails the batch
+	err = store.PutBatch([]storage.KeyValue{{Key: "key6", Value: []byte("value6")}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to begin transaction")
+}
+
+func TestSQLDBStoreGetBulk(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testGetBulk")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key1", []byte("value1")))
+	require.NoError(t, store.Put("key3", []byte("value3")))
+
+	values, err := store.GetBulk("key3", "key2", "key1", "key3")
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("value3"), nil, []byte("value1"), []byte("value3")}, values)
+
+	values, err = store.GetBulk()
+	require.NoError(t, err)
+	require.Empty(t, values)
+
+	_, err = store.GetBulk("key1", "")
+	require.EqualError(t, err, "key is mandatory")
+
+	require.NoError(t, prov.Close())
+
+	_, err = store.GetBulk("key1")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to get rows")
+}
+
+func TestSQLDBStoreNilAndEmptyValues(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, prov.Close())
+	}()
+
+	store, err := prov.OpenStore("testEmptyValues")
+	require.NoError(t, err)
+
+	require.Equal(t, storage.ErrNilValue, store.Put("key", nil))
+	require.Equal(t, storage.ErrNilValue, store.PutBatch([]storage.KeyValue{{Key: "key"}}))
+	require.Equal(t, storage.ErrNilValue, store.(storage.ExpiringStore).PutWithExpiry("key", nil, time.Minute))
+
+	_, err = store.PutIfMatch("key", nil, nil)
+	require.Equal(t, storage.ErrNilValue, err)
+
+	require.NoError(t, store.Put("key", []byte{}))
+
+	v, err := store.Get("key")
+	require.NoError(t, err)
+	require.NotNil(t, v)
+	require.Empty(t, v)
+
+	values, err := store.GetBulk("key", "missing")
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{{}, nil}, values)
+}
+
+func TestSQLDBStoreHas(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testHas")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key1", []byte("value1")))
+
+	ok, err := store.Has("key1")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = store.Has("key2")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = store.Has("")
+	require.EqualError(t, err, "key is mandatory")
+
+	require.NoError(t, prov.Close())
+
+	_, err = store.Has("key1")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to check row")
+}
+
+func TestSQLDBStorePutIfMatch(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL)
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testCAS")
+	require.NoError(t, err)
+
+	// a fresh key for every run since the records persist in the DB
+	key := fmt.Sprintf("cas-%d", time.Now().UnixNano())
+
+	ok, err := store.PutIfMatch(key, nil, []byte("value1"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// create-only fails once the key exists
+	ok, err = store.PutIfMatch(key, nil, []byte("value2"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = store.PutIfMatch(key, []byte("other"), []byte("value2"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	v, err := store.Get(key)
+	require.NoError(t, err)
+	require.Equal(t, []byte("value1"), v)
+
+	ok, err = store.PutIfMatch(key, []byte("value1"), []byte("value2"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	v, err = store.Get(key)
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), v)
+
+	// unchanged value
+	ok, err = store.PutIfMatch(key, []byte("value2"), []byte("value2"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = store.PutIfMatch(key+"-missing", []byte("value1"), []byte("value2"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = store.PutIfMatch("", nil, []byte("value1"))
+	require.Error(t, err)
+
+	require.NoError(t, prov.Close())
+}
+
+func TestSQLDBProviderCloseErrors(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"), WithReadReplica(sqlStoreDBURL))
+	require.NoError(t, err)
+
+	// the pools of the provider are replaced with pools whose connections fail to close
+	require.NoError(t, prov.db.Close())
+	require.NoError(t, prov.readDB.Close())
+
+	prov.db = openCloseErrDB(t)
+	prov.readDB = openCloseErrDB(t)
+
+	err = prov.Close()
+	require.Error(t, err)
+	require.True(t, errors.Is(err, errConnClose))
+	require.Contains(t, err.Error(), failToCloseProviderErrMsg)
+	require.Contains(t, err.Error(), "failed to close read replica connection")
+	require.Contains(t, err.Error(), "failed to close connection")
+
+	var multiErr storage.MultiError
+	require.True(t, errors.As(err, &multiErr))
+	require.Len(t, multiErr, 2)
+
+	// every pool is closed despite the errors
+	require.EqualError(t, prov.readDB.Ping(), "sql: database is closed")
+	require.EqualError(t, prov.db.Ping(), "sql: database is closed")
+
+	// closing the provider again is a no-op
+	require.NoError(t, prov.Close())
+}
+
+var errConnClose = errors.New("connection close error")
+
+// openCloseErrDB opens a connection pool with an idle connection failing to close.
+func openCloseErrDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsnConfig, err := mysql.ParseDSN(sqlStoreDBURL)
+	require.NoError(t, err)
+
+	connector, err := mysql.NewConnector(dsnConfig)
+	require.NoError(t, err)
+
+	db := sql.OpenDB(&closeErrConnector{Connector: connector})
+	require.NoError(t, db.Ping())
+
+	return db
+}
+
+type closeErrConnector struct {
+	driver.Connector
+}
+
+func (c *closeErrConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &closeErrConn{Conn: conn}, nil
+}
+
+type closeErrConn struct {
+	driver.Conn
+}
+
+func (c *closeErrConn) Close() error {
+	if err := c.Conn.Close(); err != nil {
+		return err
+	}
+
+	return errConnClose
+}
+
+func TestSQLDBStoreSpecialCharacterKeys(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testSpecialCharacterKeys")
+	require.NoError(t, err)
+
+	// keys and values are bound as statement parameters, they are never interpolated in the statements
+	keys := []string{
+		`did:key:'quoted'`,
+		`did:key:"double-quoted"`,
+		"did:key:`backticked`",
+		`did:key:\back\slashed`,
+		"did:key:'; DROP TABLE `t_prefixdb_testSpecialCharacterKeys`; --",
+	}
+
+	for _, k := range keys {
+		require.NoError(t, store.Put(k, []byte("value-for-"+k)))
+	}
+
+	for _, k := range keys {
+		v, e := store.Get(k)
+		require.NoError(t, e)
+		require.Equal(t, "value-for-"+k, string(v))
+
+		ok, e := store.(*sqlDBStore).Has(k)
+		require.NoError(t, e)
+		require.True(t, ok)
+	}
+
+	// the order of the keys depends on the collation of the table
+	itr := store.Iterator("did:key:", "did:key"+storage.EndKeySuffix)
+
+	var actual []string
+
+	for itr.Next() {
+		actual = append(actual, string(itr.Key()))
+	}
+
+	itr.Release()
+	require.ElementsMatch(t, keys, actual)
+
+	for _, k := range keys {
+		itr = store.Iterator("did:key:", "did:key"+storage.EndKeySuffix)
+		require.True(t, itr.Seek(k))
+		require.Equal(t, k, string(itr.Key()))
+		itr.Release()
+	}
+
+	for _, k := range keys {
+		require.NoError(t, store.Delete(k))
+
+		_, err = store.Get(k)
+		require.True(t, errors.Is(err, storage.ErrDataNotFound))
+	}
+
+	count, err := store.Count("", "")
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	require.NoError(t, prov.Close())
+}
+
+func TestSQLDBStoreIteratorStreaming(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testIteratorStreaming")
+	require.NoError(t, err)
+
+	db := store.(*sqlDBStore).db
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, store.Put(fmt.Sprintf("key_%02d", i), []byte("value")))
+	}
+
+	// the rows are read from the connection as the iterator moves, the connection is held until the iterator
+	// is released
+	itr := store.Iterator("key_", "key"+storage.EndKeySuffix)
+	require.Equal(t, 1, db.Stats().InUse)
+
+	require.True(t, itr.Next())
+	require.Equal(t, "key_00", string(itr.Key()))
+	require.Equal(t, 1, db.Stats().InUse)
+
+	itr.Release()
+	require.Zero(t, db.Stats().InUse)
+	require.False(t, itr.Next())
+
+	// the connection is also released at the end of the scan
+	itr = store.Iterator("key_", "key"+storage.EndKeySuffix)
+	verifyItrKeys(t, itr, "key_00", "key_01", "key_02", "key_03", "key_04", "key_05", "key_06", "key_07",
+		"key_08", "key_09")
+	require.NoError(t, itr.Error())
+	require.Zero(t, db.Stats().InUse)
+
+	require.NoError(t, prov.Close())
+}
+
+func TestSQLDBStoreIteratorFinalizer(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"), WithIteratorLeakWarning())
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testIteratorFinalizer")
+	require.NoError(t, err)
+
+	db := store.(*sqlDBStore).db
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, store.Put(fmt.Sprintf("key_%02d", i), []byte("value")))
+	}
+
+	// the rows of an iterator dropped without Release are closed once it's garbage collected
+	consumeFirstKey(t, store)
+	require.Equal(t, 1, db.Stats().InUse)
+
+	require.Eventually(t, func() bool {
+		runtime.GC()
+
+		return db.Stats().InUse == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the creation stack of the iterators is only captured with the leak warning
+	itr := store.Iterator("key_", "key"+storage.EndKeySuffix).(*sqlDBResultsIterator)
+	require.Contains(t, string(itr.creationStack), "TestSQLDBStoreIteratorFinalizer")
+
+	// the rows of a released iterator aren't closed again
+	itr.Release()
+	require.True(t, itr.rowsClosed)
+	itr.finalize()
+
+	require.NoError(t, prov.Close())
+
+	prov, err = NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+	require.NoError(t, err)
+
+	store, err = prov.OpenStore("testIteratorFinalizer")
+	require.NoError(t, err)
+
+	itr = store.Iterator("key_", "key"+storage.EndKeySuffix).(*sqlDBResultsIterator)
+	require.Nil(t, itr.creationStack)
+
+	verifyItrKeys(t, itr, "key_00", "key_01", "key_02", "key_03", "key_04", "key_05", "key_06", "key_07",
+		"key_08", "key_09")
+	require.True(t, itr.rowsClosed)
+
+	require.NoError(t, prov.Close())
+}
+
+func TestSQLDBAppendStore(t *testing.T) {
+	prov1, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+	require.NoError(t, err)
+
+	prov2, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+	require.NoError(t, err)
+
+	store1, err := prov1.OpenStore("testAppendStore")
+	require.NoError(t, err)
+
+	_, err = store1.(storage.RangeDeleter).DeleteRange(storage.SeqKey(0), storage.SeqKey(math.MaxUint64))
+	require.NoError(t, err)
+
+	store2, err := prov2.OpenStore("testAppendStore")
+	require.NoError(t, err)
+
+	log1, err := storage.NewAppendStore(store1)
+	require.NoError(t, err)
+
+	log2, err := storage.NewAppendStore(store2)
+	require.NoError(t, err)
+
+	// the appenders of both providers share the sequence of the log
+	for i, log := range []storage.AppendStore{log1, log2, log2, log1} {
+		seq, err := log.Append([]byte(fmt.Sprintf("event%d", i+1)))
+		require.NoError(t, err)
+		require.Equal(t, uint64(i+1), seq)
+	}
+
+	require.Equal(t, storage.ErrReadOnly, log2.Put(storage.SeqKey(1), []byte("forged")))
+
+	verifyItrKeys(t, log1.Scan(3), storage.SeqKey(3), storage.SeqKey(4))
+
+	require.NoError(t, prov1.Close())
+	require.NoError(t, prov2.Close())
+}
+
+// consumeFirstKey reads the first key of an iterator over the store and drops it without releasing it.
+func consumeFirstKey(t *testing.T, store storage.Store) {
+	t.Helper()
+
+	itr := store.Iterator("key_", "key"+storage.EndKeySuffix)
+	require.True(t, itr.Next())
+	require.Equal(t, "key_00", string(itr.Key()))
+}
+
+func verifyItr(t *testing.T, itr storage.StoreIterator, count int, prefix string) {
+	var vals []string
+
+	for itr.Next() {
+		if prefix != "" {
+			require.True(t, strings.HasPrefix(string(itr.Key()), prefix))
+		}
+
+		vals = append(vals, string(itr.Value()))
+	}
+
+	require.Len(t, vals, count)
+
+	itr.Release()
+	require.False(t, itr.Next())
+	require.Empty(t, itr.Key())
+	require.Empty(t, itr.Value())
+	require.Error(t, itr.Error())
+	require.Contains(t, itr.Error().Error(), "sql: Rows are closed")
+}
+
+func verifyItrKeys(t *testing.T, itr storage.StoreIterator, keys ...string) {
+	var actual []string
+
+	for itr.Next() {
+		actual = append(actual, string(itr.Key()))
+	}
+
+	require.Equal(t, keys, actual)
+
+	itr.Release()
+}
+
+func TestSQLDBStoreConformance(t *testing.T) {
+	storagetest.RunSuite(t, func() (storage.Provider, error) {
+		return NewProvider(sqlStoreDBURL, WithDBPrefix("conformance"))
+	})
+}
+
+func BenchmarkSQLDBProviderOpenStores(b *testing.B) {
+	const storeCount = 100
+
+	for i := 0; i < b.N; i++ {
+		prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("benchstores"))
+		require.NoError(b, err)
+
+		for j := 0; j < storeCount; j++ {
+			store, err := prov.OpenStore(fmt.Sprintf("store_%d", j))
+			require.NoError(b, err)
+
+			require.NoError(b, store.Put("key", []byte("value")))
+		}
+
+		b.ReportMetric(float64(openConnections(prov)), "open-conns")
+
+		require.NoError(b, prov.Close())
+	}
+}
+
+// openConnections returns the number of connections opened by the connection pools of the provider and its stores.
+func openConnections(prov *Provider) int {
+	pools := map[*sql.DB]struct{}{prov.db: {}}
+
+	for _, store := range prov.dbs {
+		pools[store.db] = struct{}{}
+		pools[store.readDB] = struct{}{}
+	}
+
+	var count int
+
+	for db := range pools {
+		count += db.Stats().OpenConnections
+	}
+
+	return count
 }
@@ -0,0 +1,179 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mysql
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestSQLDBStoreTransaction(t *testing.T) {
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testTx")
+	require.NoError(t, err)
+
+	txStore, ok := store.(storage.Transactional)
+	require.True(t, ok)
+
+	require.NoError(t, store.Put("key1", []byte("value1")))
+
+	t.Run("Test transaction commit", func(t *testing.T) {
+		tx, err := txStore.Begin()
+		require.NoError(t, err)
+
+		require.NoError(t, tx.Put("key2", []byte("value2")))
+		require.NoError(t, tx.Delete("key1"))
+
+		v, err := tx.Get("key2")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value2"), v)
+
+		_, err = tx.Get("key1")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		require.NoError(t, tx.Commit())
+
+		v, err = store.Get("key2")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value2"), v)
+
+		_, err = store.Get("key1")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		// transaction is done
+		err = tx.Rollback()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to rollback transaction")
+	})
+
+	t.Run("Test transaction rollback", func(t *testing.T) {
+		tx, err := txStore.Begin()
+		require.NoError(t, err)
+
+		require.NoError(t, tx.Put("key3", []byte("value3")))
+		require.NoError(t, tx.Delete("key2"))
+
+		require.NoError(t, tx.Rollback())
+
+		_, err = store.Get("key3")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		v, err := store.Get("key2")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value2"), v)
+
+		// transaction is done
+		err = tx.Commit()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to commit transaction")
+	})
+
+	t.Run("Test transaction empty key", func(t *testing.T) {
+		tx, err := txStore.Begin()
+		require.NoError(t, err)
+
+		require.Equal(t, storage.ErrKeyRequired, tx.Put("", []byte("value")))
+		_, err = tx.Get("")
+		require.Equal(t, storage.ErrKeyRequired, err)
+		require.Equal(t, storage.ErrKeyRequired, tx.Delete(""))
+
+		require.NoError(t, tx.Rollback())
+	})
+
+	t.Run("Test transaction isolation levels", func(t *testing.T) {
+		readCommittedTx, err := txStore.Begin(storage.WithIsolationLevel(storage.LevelReadCommitted))
+		require.NoError(t, err)
+
+		_, err = readCommittedTx.Get("key4")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		serializableTx, err := txStore.Begin(storage.WithIsolationLevel(storage.LevelSerializable))
+		require.NoError(t, err)
+
+		require.NoError(t, serializableTx.Put("key4", []byte("value4")))
+		require.NoError(t, serializableTx.Commit())
+
+		// a read committed transaction reads the records committed since it started
+		v, err := readCommittedTx.Get("key4")
+		require.NoError(t, err)
+		require.Equal(t, []byte("value4"), v)
+
+		require.NoError(t, readCommittedTx.Rollback())
+
+		_, err = txStore.Begin(storage.WithIsolationLevel(storage.IsolationLevel(42)))
+		require.True(t, errors.Is(err, storage.ErrIsolationLevelNotSupported))
+		require.Contains(t, err.Error(), "failed to begin transaction")
+	})
+
+	t.Run("Test begin transaction error", func(t *testing.T) {
+		require.NoError(t, prov.Close())
+
+		_, err := txStore.Begin()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to begin transaction")
+	})
+}
+
+func TestSQLDBStoreExportImport(t *testing.T) {
+	memStore, err := mem.NewProvider().OpenStore("test")
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, memStore.Put(fmt.Sprintf("key_%02d", i), []byte(fmt.Sprintf("value-%d", i))))
+	}
+
+	export := new(bytes.Buffer)
+	require.NoError(t, storage.Export(memStore, "key_", "key_"+storage.EndKeySuffix, export))
+
+	prov, err := NewProvider(sqlStoreDBURL, WithDBPrefix("prefixdb"))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testExportImport")
+	require.NoError(t, err)
+
+	// the records of previous runs are deleted
+	_, err = store.(storage.RangeDeleter).DeleteRange("key_", "key"+storage.EndKeySuffix)
+	require.NoError(t, err)
+
+	// the import runs within a transaction, a truncated export stores nothing
+	err = storage.Import(store, bytes.NewReader(export.Bytes()[:export.Len()-1]))
+	require.True(t, errors.Is(err, storage.ErrInvalidExport))
+
+	count, err := store.Count("", "")
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	require.NoError(t, storage.Import(store, bytes.NewReader(export.Bytes())))
+
+	count, err = store.Count("", "")
+	require.NoError(t, err)
+	require.Equal(t, 10, count)
+
+	// and back to a mem store
+	sqlExport := new(bytes.Buffer)
+	require.NoError(t, storage.Export(store, "key_", "key"+storage.EndKeySuffix, sqlExport))
+	require.Equal(t, export.Bytes(), sqlExport.Bytes())
+
+	memStore, err = mem.NewProvider().OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, storage.Import(memStore, sqlExport))
+
+	v, err := memStore.Get("key_09")
+	require.NoError(t, err)
+	require.Equal(t, "value-9", string(v))
+
+	require.NoError(t, prov.Close())
+}
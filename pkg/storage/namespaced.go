@@ -0,0 +1,300 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// namespaceSeparator separates the namespace from the record key in the keys of the wrapped stores
+const namespaceSeparator = ":"
+
+// NewNamespacedProvider returns a provider isolating the records of its stores within a namespace of the stores of p,
+// ie to share the stores of a single backend between tenants. The keys of the records are stored by the stores of p
+// prefixed with the namespace and a colon, the prefix being stripped from the keys returned by the iterators. The
+// ranges of the iterators, counts and range deletes never go beyond the namespace: an empty startKey or endKey leaves
+// the range bounded by the namespace only. The namespace is mandatory and can't contain a colon so that it can't
+// overlap another namespace.
+// The namespaced stores are Transactional, returning ErrTransactionsNotSupported when the stores of p aren't,
+// RangeDeleter only when the stores of p are, ContextStore, falling back to the operations without context, and
+// ExpiringStore only when the stores of p are. They aren't TaggedStore as tag queries span the whole store of p.
+// Closing the provider closes p, along with the stores of the other namespaces of p.
+func NewNamespacedProvider(p Provider, namespace string) (Provider, error) {
+	if p == nil {
+		return nil, errors.New("provider is mandatory")
+	}
+
+	if namespace == "" {
+		return nil, errors.New("namespace is mandatory")
+	}
+
+	if strings.Contains(namespace, namespaceSeparator) {
+		return nil, fmt.Errorf("namespace %q can't contain %q", namespace, namespaceSeparator)
+	}
+
+	return &namespacedProvider{Provider: p, prefix: namespace + namespaceSeparator}, nil
+}
+
+type namespacedProvider struct {
+	Provider
+	prefix string
+}
+
+// OpenStore opens the store of p and namespaces its records.
+func (p *namespacedProvider) OpenStore(name string) (Store, error) {
+	s, err := p.Provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &namespacedStore{store: s, prefix: p.prefix}
+
+	rangeDeleter, isRangeDeleter := s.(RangeDeleter)
+	expiringStore, isExpiringStore := s.(ExpiringStore)
+
+	switch {
+	case isRangeDeleter && isExpiringStore:
+		return &namespacedExpiringRangeDeleter{
+			namespacedStore:         store,
+			namespacedRangeDeleter:  &namespacedRangeDeleter{namespacedStore: store, rangeDeleter: rangeDeleter},
+			namespacedExpiringStore: &namespacedExpiringStore{namespacedStore: store, expiringStore: expiringStore},
+		}, nil
+	case isRangeDeleter:
+		return &namespacedRangeDeleter{namespacedStore: store, rangeDeleter: rangeDeleter}, nil
+	case isExpiringStore:
+		return &namespacedExpiringStore{namespacedStore: store, expiringStore: expiringStore}, nil
+	default:
+		return store, nil
+	}
+}
+
+type namespacedStore struct {
+	store  Store
+	prefix string
+}
+
+// key returns the key of the record in the wrapped store. Empty keys aren't prefixed so that the wrapped store
+// rejects them.
+func (s *namespacedStore) key(k string) string {
+	if k == "" {
+		return ""
+	}
+
+	return s.prefix + k
+}
+
+// keyRange returns the range of the wrapped store matching the range of the namespace.
+func (s *namespacedStore) keyRange(startKey, endKey string) (string, string) {
+	if endKey == "" {
+		endKey = EndKeySuffix
+	}
+
+	return s.prefix + startKey, s.prefix + endKey
+}
+
+// Put stores the key and the record
+func (s *namespacedStore) Put(k string, v []byte) error {
+	return s.store.Put(s.key(k), v)
+}
+
+// PutBatch stores all the given key/value pairs
+func (s *namespacedStore) PutBatch(kvs []KeyValue) error {
+	namespacedKVs := make([]KeyValue, len(kvs))
+
+	for i, kv := range kvs {
+		namespacedKVs[i] = KeyValue{Key: s.key(kv.Key), Value: kv.Value}
+	}
+
+	return s.store.PutBatch(namespacedKVs)
+}
+
+// PutIfMatch stores the record only if the current record of key k equals expected
+func (s *namespacedStore) PutIfMatch(k string, expected, newValue []byte) (bool, error) {
+	return s.store.PutIfMatch(s.key(k), expected, newValue)
+}
+
+// Get fetches the record based on key
+func (s *namespacedStore) Get(k string) ([]byte, error) {
+	return s.store.Get(s.key(k))
+}
+
+// GetBulk fetches the records of all the given keys
+func (s *namespacedStore) GetBulk(keys ...string) ([][]byte, error) {
+	namespacedKeys := make([]string, len(keys))
+
+	for i, k := range keys {
+		namespacedKeys[i] = s.key(k)
+	}
+
+	return s.store.GetBulk(namespacedKeys...)
+}
+
+// Has checks whether a record with key k exists
+func (s *namespacedStore) Has(k string) (bool, error) {
+	return s.store.Has(s.key(k))
+}
+
+// Iterator returns an iterator over the range within the namespace, the keys it returns are stripped of the namespace.
+func (s *namespacedStore) Iterator(startKey, endKey string, opts ...IteratorOption) StoreIterator {
+	startKey, endKey = s.keyRange(startKey, endKey)
+
+	return &namespacedIterator{StoreIterator: s.store.Iterator(startKey, endKey, opts...), prefix: s.prefix}
+}
+
+// Count returns the number of records within the key range of the namespace
+func (s *namespacedStore) Count(startKey, endKey string) (int, error) {
+	startKey, endKey = s.keyRange(startKey, endKey)
+
+	return s.store.Count(startKey, endKey)
+}
+
+// ForEach calls fn with every key/value pair within the key range of the namespace
+func (s *namespacedStore) ForEach(startKey, endKey string, fn func(key, value []byte) (bool, error)) error {
+	return ForEach(s.Iterator(startKey, endKey), fn)
+}
+
+// Delete will delete a record with k key
+func (s *namespacedStore) Delete(k string) error {
+	return s.store.Delete(s.key(k))
+}
+
+// Begin starts a new transaction namespacing the keys of its records, stores wrapping a store that can't execute
+// atomic transactions return ErrTransactionsNotSupported.
+func (s *namespacedStore) Begin(opts ...TxOption) (Transaction, error) {
+	txStore, ok := s.store.(Transactional)
+	if !ok {
+		return nil, ErrTransactionsNotSupported
+	}
+
+	tx, err := txStore.Begin(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &namespacedTransaction{Transaction: tx, store: s}, nil
+}
+
+// PutContext stores the key and the record, the operation isn't started when ctx is already done if the wrapped
+// store isn't a ContextStore.
+func (s *namespacedStore) PutContext(ctx context.Context, k string, v []byte) error {
+	ctxStore, ok := s.store.(ContextStore)
+	if !ok {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		return s.Put(k, v)
+	}
+
+	return ctxStore.PutContext(ctx, s.key(k), v)
+}
+
+// GetContext fetches the record based on key, the operation isn't started when ctx is already done if the wrapped
+// store isn't a ContextStore.
+func (s *namespacedStore) GetContext(ctx context.Context, k string) ([]byte, error) {
+	ctxStore, ok := s.store.(ContextStore)
+	if !ok {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		return s.Get(k)
+	}
+
+	return ctxStore.GetContext(ctx, s.key(k))
+}
+
+// DeleteContext deletes the record of key k, the operation isn't started when ctx is already done if the wrapped
+// store isn't a ContextStore.
+func (s *namespacedStore) DeleteContext(ctx context.Context, k string) error {
+	ctxStore, ok := s.store.(ContextStore)
+	if !ok {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		return s.Delete(k)
+	}
+
+	return ctxStore.DeleteContext(ctx, s.key(k))
+}
+
+type namespacedRangeDeleter struct {
+	*namespacedStore
+	rangeDeleter RangeDeleter
+}
+
+// DeleteRange deletes the records within the key range of the namespace
+func (s *namespacedRangeDeleter) DeleteRange(startKey, endKey string) (int, error) {
+	startKey, endKey = s.keyRange(startKey, endKey)
+
+	return s.rangeDeleter.DeleteRange(startKey, endKey)
+}
+
+type namespacedExpiringStore struct {
+	*namespacedStore
+	expiringStore ExpiringStore
+}
+
+// PutWithExpiry stores the key and the record until ttl elapses
+func (s *namespacedExpiringStore) PutWithExpiry(k string, v []byte, ttl time.Duration) error {
+	return s.expiringStore.PutWithExpiry(s.key(k), v, ttl)
+}
+
+// namespacedExpiringRangeDeleter is both a namespacedRangeDeleter and a namespacedExpiringStore, the methods of
+// the store being promoted from its own namespacedStore.
+type namespacedExpiringRangeDeleter struct {
+	*namespacedStore
+	*namespacedRangeDeleter
+	*namespacedExpiringStore
+}
+
+type namespacedTransaction struct {
+	Transaction
+	store *namespacedStore
+}
+
+// Put stores the key and the record within the transaction
+func (t *namespacedTransaction) Put(k string, v []byte) error {
+	return t.Transaction.Put(t.store.key(k), v)
+}
+
+// Get fetches the record based on key within the transaction
+func (t *namespacedTransaction) Get(k string) ([]byte, error) {
+	return t.Transaction.Get(t.store.key(k))
+}
+
+// Delete deletes the record of key k within the transaction
+func (t *namespacedTransaction) Delete(k string) error {
+	return t.Transaction.Delete(t.store.key(k))
+}
+
+type namespacedIterator struct {
+	StoreIterator
+	prefix string
+}
+
+// Key returns the key of the current key/value pair stripped of the namespace, or nil if done.
+func (i *namespacedIterator) Key() []byte {
+	k := i.StoreIterator.Key()
+	if k == nil {
+		return nil
+	}
+
+	return bytes.TrimPrefix(k, []byte(i.prefix))
+}
+
+// Seek moves the iterator to the first key/value pair of its range whose key is at or after key in the iteration
+// order.
+func (i *namespacedIterator) Seek(key string) bool {
+	return i.StoreIterator.Seek(i.prefix + key)
+}
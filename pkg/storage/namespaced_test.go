@@ -0,0 +1,187 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestNewNamespacedProvider(t *testing.T) {
+	t.Run("test invalid namespace", func(t *testing.T) {
+		_, err := storage.NewNamespacedProvider(mem.NewProvider(), "")
+		require.EqualError(t, err, "namespace is mandatory")
+
+		_, err = storage.NewNamespacedProvider(mem.NewProvider(), "tenant:1")
+		require.EqualError(t, err, `namespace "tenant:1" can't contain ":"`)
+
+		_, err = storage.NewNamespacedProvider(nil, "tenant1")
+		require.EqualError(t, err, "provider is mandatory")
+	})
+
+	t.Run("test records are isolated between namespaces", func(t *testing.T) {
+		memProvider := mem.NewProvider()
+		store1, store2 := openNamespacedStores(t, memProvider)
+
+		require.NoError(t, store1.Put("k1", []byte("v1")))
+		require.NoError(t, store2.Put("k1", []byte("other")))
+		require.NoError(t, store1.PutBatch([]storage.KeyValue{{Key: "k2", Value: []byte("v2")}}))
+
+		v, err := store1.Get("k1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), v)
+
+		v, err = store2.Get("k1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("other"), v)
+
+		_, err = store2.Get("k2")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		found, err := store2.Has("k2")
+		require.NoError(t, err)
+		require.False(t, found)
+
+		values, err := store1.GetBulk("k1", "k2", "k3")
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("v1"), []byte("v2"), nil}, values)
+
+		stored, err := store1.PutIfMatch("k1", []byte("v1"), []byte("v3"))
+		require.NoError(t, err)
+		require.True(t, stored)
+
+		// the records are stored with the namespace in the shared store
+		rawStore, err := memProvider.OpenStore("test")
+		require.NoError(t, err)
+
+		v, err = rawStore.Get("tenant1:k1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v3"), v)
+
+		require.NoError(t, store2.Delete("k1"))
+
+		v, err = store1.Get("k1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v3"), v)
+
+		_, err = store2.Get("k1")
+		require.Equal(t, storage.ErrDataNotFound, err)
+	})
+
+	t.Run("test ranges are bounded by the namespace", func(t *testing.T) {
+		memProvider := mem.NewProvider()
+		store1, store2 := openNamespacedStores(t, memProvider)
+
+		rawStore, err := memProvider.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, rawStore.Put("a", []byte("raw")))
+		require.NoError(t, rawStore.Put("z", []byte("raw")))
+
+		for _, k := range []string{"key1", "key2", "key3"} {
+			require.NoError(t, store1.Put(k, []byte("v")))
+			require.NoError(t, store2.Put(k, []byte("v")))
+		}
+
+		count, err := store1.Count("", "")
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+
+		count, err = store1.Count("key2", "key"+storage.EndKeySuffix)
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+
+		var keys []string
+
+		err = store1.ForEach("", "", func(key, value []byte) (bool, error) {
+			keys = append(keys, string(key))
+
+			return false, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"key1", "key2", "key3"}, keys)
+
+		itr := store1.Iterator("key", "key"+storage.EndKeySuffix, storage.WithReverse())
+		require.True(t, itr.Seek("key2"))
+		require.Equal(t, []byte("key2"), itr.Key())
+		require.True(t, itr.Next())
+		require.Equal(t, []byte("key1"), itr.Key())
+		require.False(t, itr.Next())
+		itr.Release()
+
+		deleted, err := store2.(storage.RangeDeleter).DeleteRange("", "")
+		require.NoError(t, err)
+		require.Equal(t, 3, deleted)
+
+		count, err = store1.Count("", "")
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+
+		count, err = rawStore.Count("", "")
+		require.NoError(t, err)
+		require.Equal(t, 5, count)
+	})
+
+	t.Run("test empty keys are rejected", func(t *testing.T) {
+		store, _ := openNamespacedStores(t, mem.NewProvider())
+
+		require.Error(t, store.Put("", []byte("v")))
+
+		_, err := store.Get("")
+		require.Error(t, err)
+
+		require.Error(t, store.PutBatch([]storage.KeyValue{{Key: "", Value: []byte("v")}}))
+	})
+
+	t.Run("test optional capabilities", func(t *testing.T) {
+		store, _ := openNamespacedStores(t, mem.NewProvider())
+
+		_, err := store.(storage.Transactional).Begin()
+		require.Equal(t, storage.ErrTransactionsNotSupported, err)
+
+		require.NoError(t, store.(storage.ExpiringStore).PutWithExpiry("k1", []byte("v1"), time.Hour))
+
+		ctxStore := store.(storage.ContextStore)
+
+		v, err := ctxStore.GetContext(context.Background(), "k1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), v)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.Equal(t, context.Canceled, ctxStore.PutContext(ctx, "k2", []byte("v2")))
+		require.NoError(t, ctxStore.DeleteContext(context.Background(), "k1"))
+
+		_, isTaggedStore := store.(storage.TaggedStore)
+		require.False(t, isTaggedStore)
+	})
+}
+
+func openNamespacedStores(t *testing.T, p storage.Provider) (storage.Store, storage.Store) {
+	t.Helper()
+
+	var stores []storage.Store
+
+	for _, namespace := range []string{"tenant1", "tenant2"} {
+		prov, err := storage.NewNamespacedProvider(p, namespace)
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		stores = append(stores, store)
+	}
+
+	return stores[0], stores[1]
+}
@@ -0,0 +1,74 @@
+// +build !js,!wasm
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+var _ storage.Transactional = (*sqlDBStore)(nil)
+
+// sqlDBTransaction is a storage.Transaction backed by a sql.Tx
+type sqlDBTransaction struct {
+	tx        *sql.Tx
+	tableName string
+}
+
+// Begin starts a new transaction on the store, with the isolation level of the session (READ COMMITTED by default)
+// unless storage.WithIsolationLevel is given. All the levels are supported, PostgreSQL running the READ UNCOMMITTED
+// transactions as READ COMMITTED.
+func (s *sqlDBStore) Begin(opts ...storage.TxOption) (storage.Transaction, error) {
+	txOpts, err := storage.SQLTxOptions(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction on %s %w", s.tableName, err)
+	}
+
+	tx, err := s.db.BeginTx(context.Background(), txOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction on %s %w", s.tableName, err)
+	}
+
+	return &sqlDBTransaction{tx: tx, tableName: s.tableName}, nil
+}
+
+// Put stores the key and the value within the transaction
+func (t *sqlDBTransaction) Put(k string, v []byte) error {
+	return put(t.tx, t.tableName, k, v)
+}
+
+// Get fetches the value based on key within the transaction
+func (t *sqlDBTransaction) Get(k string) ([]byte, error) {
+	return get(t.tx, t.tableName, k)
+}
+
+// Delete will delete record with k key within the transaction
+func (t *sqlDBTransaction) Delete(k string) error {
+	return remove(t.tx, t.tableName, k)
+}
+
+// Commit commits the transaction
+func (t *sqlDBTransaction) Commit() error {
+	if err := t.tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction on %s %w", t.tableName, err)
+	}
+
+	return nil
+}
+
+// Rollback aborts the transaction
+func (t *sqlDBTransaction) Rollback() error {
+	if err := t.tx.Rollback(); err != nil {
+		return fmt.Errorf("failed to rollback transaction on %s %w", t.tableName, err)
+	}
+
+	return nil
+}
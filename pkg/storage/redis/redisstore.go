@@ -0,0 +1,528 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package redis
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	blankAddrErrMsg           = "address for new Redis provider can't be blank"
+	failToCloseProviderErrMsg = "failed to close provider"
+	separatorInNameErrMsg     = "name %q can't contain the namespace separator " + namespaceSeparator
+	// namespaceSeparator separates the store name from the record key in the Redis key space
+	namespaceSeparator = ":"
+	// scanCount is the hint given to Redis for the number of keys returned by every SCAN call
+	scanCount = 100
+)
+
+// Provider represents a Redis implementation of the storage.Provider interface
+type Provider struct {
+	client   *redis.Client
+	dbs      map[string]*redisStore
+	dbPrefix string
+	ttl      time.Duration
+	sync.RWMutex
+}
+
+type redisStore struct {
+	client    *redis.Client
+	namespace string
+	ttl       time.Duration
+}
+
+// Option configures the redis provider
+type Option func(opts *Provider)
+
+// WithDBPrefix option is for adding prefix to store names, it is trimmed with storage.TrimDBPrefix
+func WithDBPrefix(dbPrefix string) Option {
+	return func(opts *Provider) {
+		opts.dbPrefix = storage.TrimDBPrefix(dbPrefix)
+	}
+}
+
+// WithTTL option sets the time to live applied to every record stored by the provider's stores.
+// Records don't expire when the TTL is zero, which is the default.
+func WithTTL(ttl time.Duration) Option {
+	return func(opts *Provider) {
+		opts.ttl = ttl
+	}
+}
+
+// NewProvider instantiates Provider
+func NewProvider(addr string, opts ...Option) (*Provider, error) {
+	if addr == "" {
+		return nil, errors.New(blankAddrErrMsg)
+	}
+
+	// Example address 127.0.0.1:6379
+	p := &Provider{
+		client: redis.NewClient(&redis.Options{Addr: addr}),
+		dbs:    map[string]*redisStore{}}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	// the namespaces of the stores wouldn't be distinct anymore
+	if strings.Contains(p.dbPrefix, namespaceSeparator) {
+		return nil, fmt.Errorf("invalid DB prefix: "+separatorInNameErrMsg, p.dbPrefix)
+	}
+
+	return p, nil
+}
+
+// OpenStore opens and returns the store for given name space. All the stores share the client of the provider,
+// the records of each store being prefixed with its name in the Redis key space, so the name can't contain the
+// namespace separator.
+func (p *Provider) OpenStore(name string) (storage.Store, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if name == "" {
+		return nil, errors.New("store name is required")
+	}
+
+	if strings.Contains(name, namespaceSeparator) {
+		return nil, fmt.Errorf("invalid store name: "+separatorInNameErrMsg, name)
+	}
+
+	if p.dbPrefix != "" {
+		name = p.dbPrefix + "_" + name
+	}
+
+	if store, exists := p.dbs[name]; exists {
+		return store, nil
+	}
+
+	store := &redisStore{
+		client:    p.client,
+		namespace: name + namespaceSeparator,
+		ttl:       p.ttl}
+
+	p.dbs[name] = store
+
+	return store, nil
+}
+
+// Close closes the provider.
+func (p *Provider) Close() error {
+	p.Lock()
+	defer p.Unlock()
+
+	if err := p.client.Close(); err != nil {
+		return fmt.Errorf(failToCloseProviderErrMsg+": %w", err)
+	}
+
+	p.dbs = make(map[string]*redisStore)
+
+	return nil
+}
+
+// CloseStore closes a previously opened store. The client remains open since it is shared by all the
+// stores of the provider.
+func (p *Provider) CloseStore(name string) error {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.dbPrefix != "" {
+		name = p.dbPrefix + "_" + name
+	}
+
+	delete(p.dbs, name)
+
+	return nil
+}
+
+// Put stores the key and the value, applying the TTL of the provider
+func (s *redisStore) Put(k string, v []byte) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	err := s.client.Set(s.namespace+k, v, s.ttl).Err()
+	if err != nil {
+		return fmt.Errorf("failed to set key %s: %w", k, err)
+	}
+
+	return nil
+}
+
+// PutIfMatch stores the key and the value only if the current value equals expected, applying the TTL of the
+// provider. The key is watched while comparing, the update being discarded if it changes concurrently.
+// A nil expected value is handled with SETNX.
+func (s *redisStore) PutIfMatch(k string, expected, newValue []byte) (bool, error) {
+	if k == "" {
+		return false, storage.ErrKeyRequired
+	}
+
+	key := s.namespace + k
+
+	if expected == nil {
+		stored, err := s.client.SetNX(key, newValue, s.ttl).Result()
+		if err != nil {
+			return false, fmt.Errorf("failed to set key %s: %w", k, err)
+		}
+
+		return stored, nil
+	}
+
+	stored := false
+
+	err := s.client.Watch(func(tx *redis.Tx) error {
+		current, err := tx.Get(key).Bytes()
+		if errors.Is(err, redis.Nil) || (err == nil && !bytes.Equal(current, expected)) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(func(pipe redis.Pipeliner) error {
+			pipe.Set(key, newValue, s.ttl)
+
+			return nil
+		})
+		stored = err == nil
+
+		return err
+	}, key)
+	if err != nil && !errors.Is(err, redis.TxFailedErr) {
+		return false, fmt.Errorf("failed to set key %s: %w", k, err)
+	}
+
+	return stored, nil
+}
+
+// PutBatch stores the given key/value pairs within a single MULTI/EXEC transaction.
+func (s *redisStore) PutBatch(kvs []storage.KeyValue) error {
+	for _, kv := range kvs {
+		if kv.Key == "" {
+			return storage.ErrKeyRequired
+		}
+	}
+
+	if len(kvs) == 0 {
+		return nil
+	}
+
+	_, err := s.client.TxPipelined(func(pipe redis.Pipeliner) error {
+		for _, kv := range kvs {
+			pipe.Set(s.namespace+kv.Key, kv.Value, s.ttl)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set batch of keys: %w", err)
+	}
+
+	return nil
+}
+
+// Get fetches the value based on key
+func (s *redisStore) Get(k string) ([]byte, error) {
+	if k == "" {
+		return nil, storage.ErrKeyRequired
+	}
+
+	v, err := s.client.Get(s.namespace + k).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, storage.ErrDataNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get key %s: %w", k, err)
+	}
+
+	return v, nil
+}
+
+// GetBulk fetches the values of the given keys with a single MGET command
+func (s *redisStore) GetBulk(keys ...string) ([][]byte, error) {
+	for _, k := range keys {
+		if k == "" {
+			return nil, storage.ErrKeyRequired
+		}
+	}
+
+	values := make([][]byte, len(keys))
+
+	if len(keys) == 0 {
+		return values, nil
+	}
+
+	namespacedKeys := make([]string, len(keys))
+	for i, k := range keys {
+		namespacedKeys[i] = s.namespace + k
+	}
+
+	results, err := s.client.MGet(namespacedKeys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get keys: %w", err)
+	}
+
+	for i, r := range results {
+		if v, ok := r.(string); ok {
+			values[i] = []byte(v)
+		}
+	}
+
+	return values, nil
+}
+
+// Has checks whether a record with key k exists
+func (s *redisStore) Has(k string) (bool, error) {
+	if k == "" {
+		return false, storage.ErrKeyRequired
+	}
+
+	n, err := s.client.Exists(s.namespace + k).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check key %s: %w", k, err)
+	}
+
+	return n > 0, nil
+}
+
+// Delete will delete record with k key
+func (s *redisStore) Delete(k string) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	err := s.client.Del(s.namespace + k).Err()
+	if err != nil {
+		return fmt.Errorf("failed to delete key %s: %w", k, err)
+	}
+
+	return nil
+}
+
+// Iterator returns an iterator over the keys of the store starting with startKey.
+//
+// Redis has no native ordered range, so the range is approximated with a SCAN whose MATCH pattern is built from
+// startKey used as a prefix. The endKey is only honored as a prefix as well: once storage.EndKeySuffix is trimmed,
+// keys not starting with it are skipped. Keys are returned in no particular order, and a key may be returned more
+// than once if the key space is modified during the iteration. For that reason storage.WithReverse has no effect,
+// while storage.WithLimit caps the number of records returned.
+func (s *redisStore) Iterator(startKey, endKey string, opts ...storage.IteratorOption) storage.StoreIterator {
+	return &redisIterator{
+		client:    s.client,
+		namespace: s.namespace,
+		match:     escapeGlob(s.namespace+startKey) + "*",
+		endPrefix: s.namespace + strings.TrimSuffix(endKey, storage.EndKeySuffix),
+		limit:     storage.GetIteratorOptions(opts...).Limit,
+	}
+}
+
+// Count returns the number of records whose key starts with startKey and, once storage.EndKeySuffix is trimmed,
+// with endKey, following the same prefix semantics as Iterator. The whole store is counted when both keys are empty.
+// Keys are scanned without fetching their values.
+func (s *redisStore) Count(startKey, endKey string) (int, error) {
+	match := escapeGlob(s.namespace+startKey) + "*"
+	endPrefix := s.namespace + strings.TrimSuffix(endKey, storage.EndKeySuffix)
+
+	var cursor uint64
+
+	count := 0
+
+	for {
+		keys, next, err := s.client.Scan(cursor, match, scanCount).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to scan keys: %w", err)
+		}
+
+		for _, k := range keys {
+			if strings.HasPrefix(k, endPrefix) {
+				count++
+			}
+		}
+
+		if next == 0 {
+			return count, nil
+		}
+
+		cursor = next
+	}
+}
+
+// ForEach calls fn with every key/value pair within the key range until fn returns stop or an error
+func (s *redisStore) ForEach(startKey, endKey string, fn func(key, value []byte) (bool, error)) error {
+	return storage.ForEach(s.Iterator(startKey, endKey), fn)
+}
+
+// escapeGlob escapes the characters having a special meaning in the glob-style patterns of MATCH
+func escapeGlob(s string) string {
+	var sb strings.Builder
+
+	for _, c := range s {
+		switch c {
+		case '*', '?', '[', ']', '\\':
+			sb.WriteRune('\\')
+		}
+
+		sb.WriteRune(c)
+	}
+
+	return sb.String()
+}
+
+type redisIterator struct {
+	client    *redis.Client
+	namespace string
+	match     string
+	endPrefix string
+	cursor    uint64
+	done      bool
+	keys      []string
+	values    [][]byte
+	index     int
+	// limit caps the number of records returned by the iterator, zero when unlimited
+	limit int
+	count int
+	// minKey filters out the keys before it once the iterator has been seeked
+	minKey string
+	// released prevents Seek from restarting the scan once the iterator has been released
+	released bool
+	err      error
+}
+
+// Next moves to the next key/value pair, scanning a new page of keys when the current one is exhausted.
+func (i *redisIterator) Next() bool {
+	if i.limit > 0 && i.count == i.limit {
+		i.stop()
+
+		return false
+	}
+
+	i.index++
+
+	for i.index >= len(i.keys) {
+		if i.done {
+			i.stop()
+
+			return false
+		}
+
+		if err := i.scan(); err != nil {
+			i.err = err
+			i.stop()
+
+			return false
+		}
+	}
+
+	i.count++
+
+	return true
+}
+
+func (i *redisIterator) scan() error {
+	keys, cursor, err := i.client.Scan(i.cursor, i.match, scanCount).Result()
+	if err != nil {
+		return fmt.Errorf("failed to scan keys: %w", err)
+	}
+
+	i.cursor = cursor
+	i.done = cursor == 0
+
+	matching := make([]string, 0, len(keys))
+
+	for _, k := range keys {
+		if strings.HasPrefix(k, i.endPrefix) && k >= i.minKey {
+			matching = append(matching, k)
+		}
+	}
+
+	i.keys, i.values, i.index = nil, nil, 0
+
+	if len(matching) == 0 {
+		return nil
+	}
+
+	results, err := i.client.MGet(matching...).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get scanned keys: %w", err)
+	}
+
+	for j, r := range results {
+		// the key may have expired or been deleted since it was scanned
+		if v, ok := r.(string); ok {
+			i.keys = append(i.keys, matching[j])
+			i.values = append(i.values, []byte(v))
+		}
+	}
+
+	return nil
+}
+
+// Seek restarts the scan, moving to the first scanned key >= key. Since keys aren't ordered, the keys before key are
+// filtered out of the scan instead of being skipped, so keys already returned may be returned again.
+func (i *redisIterator) Seek(key string) bool {
+	if i.released || i.limit > 0 && i.count == i.limit {
+		return false
+	}
+
+	i.minKey = i.namespace + key
+	i.cursor = 0
+	i.done = false
+	i.keys, i.values, i.index = nil, nil, 0
+
+	return i.Next()
+}
+
+// Release stops the scan, the iterator is exhausted afterwards and can't be seeked anymore.
+func (i *redisIterator) Release() {
+	i.released = true
+	i.stop()
+}
+
+// stop ends the scan once the iterator is exhausted, it can still be seeked.
+func (i *redisIterator) stop() {
+	i.done = true
+	i.keys = nil
+	i.values = nil
+	i.index = 0
+}
+
+// TotalCount is not supported since counting the keys of the iterator requires to scan the whole key space, it
+// returns -1 and storage.ErrTotalCountNotSupported. Count scans the key space to count the records of a range.
+func (i *redisIterator) TotalCount() (int, error) {
+	return -1, storage.ErrTotalCountNotSupported
+}
+
+func (i *redisIterator) Error() error {
+	return i.err
+}
+
+// Key returns the key of the current key-value pair.
+func (i *redisIterator) Key() []byte {
+	if i.index >= len(i.keys) {
+		return nil
+	}
+
+	return []byte(strings.TrimPrefix(i.keys[i.index], i.namespace))
+}
+
+// Value returns the value of the current key-value pair.
+func (i *redisIterator) Value() []byte {
+	if i.index >= len(i.keys) {
+		return nil
+	}
+
+	return i.values[i.index]
+}
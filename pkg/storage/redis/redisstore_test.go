@@ -0,0 +1,457 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v7"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+const (
+	redisStoreAddr = "127.0.0.1:6379"
+)
+
+// For these unit tests to run, you must ensure you have a Redis instance running at the address specified in
+// redisStoreAddr.
+// To run the tests manually, start an instance by running the following command in the terminal
+// docker run -p 6379:6379 --name RedisStoreTest -d redis:6.0.6
+
+func TestMain(m *testing.M) {
+	err := waitForRedisToStart()
+	if err != nil {
+		fmt.Printf(err.Error() +
+			". Make sure you start a Redis instance using" +
+			" 'docker run -p 6379:6379 redis:6.0.6' before running the unit tests")
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+func waitForRedisToStart() error {
+	client := redis.NewClient(&redis.Options{Addr: redisStoreAddr})
+
+	defer func() {
+		if err := client.Close(); err != nil {
+			fmt.Printf("failed to close client: %s", err)
+		}
+	}()
+
+	timeout := time.After(10 * time.Second)
+
+	for {
+		select {
+		case <-timeout:
+			return fmt.Errorf("timeout: couldn't reach redis server")
+		default:
+			return client.Ping().Err()
+		}
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	t.Run("Test redis store put and get", func(t *testing.T) {
+		prov, err := NewProvider(redisStoreAddr, WithDBPrefix("prefixdb"))
+		require.NoError(t, err)
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		const key = "did:example:124"
+		data := []byte("value")
+
+		err = store.Put(key, data)
+		require.NoError(t, err)
+
+		doc, err := store.Get(key)
+		require.NoError(t, err)
+		require.Equal(t, data, doc)
+
+		// test update
+		data = []byte(`{"key1":"value1"}`)
+		err = store.Put(key, data)
+		require.NoError(t, err)
+
+		doc, err = store.Get(key)
+		require.NoError(t, err)
+		require.Equal(t, data, doc)
+
+		ok, err := store.Has(key)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		_, err = store.Get("did:example:789")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		ok, err = store.Has("did:example:789")
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		// nil key
+		_, err = store.Get("")
+		require.EqualError(t, err, "key is mandatory")
+
+		err = store.Put("", data)
+		require.EqualError(t, err, "key is mandatory")
+
+		_, err = store.Has("")
+		require.EqualError(t, err, "key is mandatory")
+
+		err = prov.Close()
+		require.NoError(t, err)
+	})
+
+	t.Run("Test redis multi store put and get", func(t *testing.T) {
+		prov, err := NewProvider(redisStoreAddr, WithDBPrefix("prefixdb"))
+		require.NoError(t, err)
+
+		const commonKey = "did:example:1"
+		data := []byte("value1")
+
+		_, err = prov.OpenStore("")
+		require.EqualError(t, err, "store name is required")
+
+		store1, err := prov.OpenStore("store1")
+		require.NoError(t, err)
+
+		store2, err := prov.OpenStore("store2")
+		require.NoError(t, err)
+
+		err = store1.Put(commonKey, data)
+		require.NoError(t, err)
+
+		_, err = store2.Get(commonKey)
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		// open store 1 again
+		store3, err := prov.OpenStore("store1")
+		require.NoError(t, err)
+
+		doc, err := store3.Get(commonKey)
+		require.NoError(t, err)
+		require.Equal(t, data, doc)
+
+		require.Len(t, prov.dbs, 2)
+
+		require.NoError(t, prov.CloseStore("store1"))
+		require.NoError(t, prov.CloseStore("store_x"))
+		require.Len(t, prov.dbs, 1)
+
+		require.NoError(t, prov.Close())
+		require.Empty(t, prov.dbs)
+	})
+
+	t.Run("Test redis store names with the namespace separator", func(t *testing.T) {
+		prov, err := NewProvider(redisStoreAddr)
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("a")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("b:c", []byte("value")))
+
+		defer func() {
+			require.NoError(t, store.Delete("b:c"))
+		}()
+
+		// store a:b would otherwise share the key space of store a, its key c being the key b:c of store a
+		_, err = prov.OpenStore("a:b")
+		require.EqualError(t, err, `invalid store name: name "a:b" can't contain the namespace separator :`)
+
+		count, err := store.Count("", "")
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+	})
+
+	t.Run("Test redis store failures", func(t *testing.T) {
+		prov, err := NewProvider("")
+		require.EqualError(t, err, blankAddrErrMsg)
+		require.Nil(t, prov)
+
+		prov, err = NewProvider(redisStoreAddr, WithDBPrefix("prefix:db"))
+		require.EqualError(t, err, `invalid DB prefix: name "prefix:db" can't contain the namespace separator :`)
+		require.Nil(t, prov)
+
+		prov, err = NewProvider("127.0.0.1:45454")
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("sample")
+		require.NoError(t, err)
+
+		err = store.Put("key", []byte("value"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to set key")
+
+		_, err = store.Get("key")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get key")
+
+		_, err = store.GetBulk("key")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get keys")
+
+		_, err = store.Has("key")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to check key")
+
+		err = store.Delete("key")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to delete key")
+
+		err = store.PutBatch([]storage.KeyValue{{Key: "key", Value: []byte("value")}})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to set batch of keys")
+
+		_, err = store.Count("key", "key"+storage.EndKeySuffix)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to scan keys")
+
+		itr := store.Iterator("key", "key"+storage.EndKeySuffix)
+		require.False(t, itr.Next())
+		require.Nil(t, itr.Key())
+		require.Nil(t, itr.Value())
+		itr.Release()
+		require.Error(t, itr.Error())
+		require.Contains(t, itr.Error().Error(), "failed to scan keys")
+	})
+
+	t.Run("Test redis store iterator", func(t *testing.T) {
+		prov, err := NewProvider(redisStoreAddr)
+		require.NoError(t, err)
+		store, err := prov.OpenStore("testIterator")
+		require.NoError(t, err)
+
+		const valPrefix = "val-for-%s"
+		keys := []string{"abc_123", "abc_124", "abc_125", "abc_126", "jkl_123", "mno_123", "abc*_123"}
+
+		for _, key := range keys {
+			err = store.Put(key, []byte(fmt.Sprintf(valPrefix, key)))
+			require.NoError(t, err)
+		}
+
+		itr := store.Iterator("abc_", "abc_"+storage.EndKeySuffix)
+		verifyItr(t, itr, 4, "abc_")
+
+		itr = store.Iterator("abc*", "abc*"+storage.EndKeySuffix)
+		verifyItr(t, itr, 1, "abc*_")
+
+		// the end key is only honored as a prefix
+		itr = store.Iterator("abc_", "abc_124")
+		verifyItr(t, itr, 1, "abc_124")
+
+		itr = store.Iterator("", "")
+		verifyItr(t, itr, len(keys), "")
+
+		itr = store.Iterator("abc_", "abc_"+storage.EndKeySuffix, storage.WithLimit(2))
+		verifyItr(t, itr, 2, "abc_")
+
+		total, err := store.Iterator("abc_", "abc_"+storage.EndKeySuffix).TotalCount()
+		require.True(t, errors.Is(err, storage.ErrTotalCountNotSupported))
+		require.Equal(t, -1, total)
+
+		// keys aren't ordered, the keys before the seeked key are filtered out
+		itr = store.Iterator("abc_", "abc_"+storage.EndKeySuffix)
+		require.True(t, itr.Seek("abc_125"))
+
+		seeked := []string{string(itr.Key())}
+
+		for itr.Next() {
+			seeked = append(seeked, string(itr.Key()))
+		}
+
+		require.ElementsMatch(t, []string{"abc_125", "abc_126"}, seeked)
+		require.False(t, itr.Seek("abc_127"))
+
+		// the exhausted iterator can still be seeked
+		require.True(t, itr.Seek("abc_126"))
+		require.Equal(t, "abc_126", string(itr.Key()))
+
+		// the scan isn't restarted once the iterator is released
+		itr.Release()
+		require.False(t, itr.Seek("abc_125"))
+		require.False(t, itr.Next())
+
+		count, err := store.Count("abc_", "abc_"+storage.EndKeySuffix)
+		require.NoError(t, err)
+		require.Equal(t, 4, count)
+
+		count, err = store.Count("", "")
+		require.NoError(t, err)
+		require.Equal(t, len(keys), count)
+
+		require.NoError(t, prov.Close())
+	})
+}
+
+func TestRedisStoreBulk(t *testing.T) {
+	prov, err := NewProvider(redisStoreAddr, WithDBPrefix("prefixdb"))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testBulk")
+	require.NoError(t, err)
+
+	err = store.PutBatch([]storage.KeyValue{
+		{Key: "key1", Value: []byte("old-value1")},
+		{Key: "key1", Value: []byte("value1")},
+		{Key: "key3", Value: []byte("value3")},
+	})
+	require.NoError(t, err)
+
+	values, err := store.GetBulk("key3", "key2", "key1")
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("value3"), nil, []byte("value1")}, values)
+
+	values, err = store.GetBulk()
+	require.NoError(t, err)
+	require.Empty(t, values)
+
+	_, err = store.GetBulk("key1", "")
+	require.EqualError(t, err, "key is mandatory")
+
+	// an empty key fails the whole batch before anything is written
+	err = store.PutBatch([]storage.KeyValue{
+		{Key: "key4", Value: []byte("value4")},
+		{Key: "", Value: []byte("value5")},
+	})
+	require.EqualError(t, err, "key is mandatory")
+
+	_, err = store.Get("key4")
+	require.Equal(t, storage.ErrDataNotFound, err)
+
+	require.NoError(t, prov.Close())
+}
+
+func TestRedisStoreDelete(t *testing.T) {
+	const commonKey = "did:example:1234"
+
+	prov, err := NewProvider(redisStoreAddr)
+	require.NoError(t, err)
+
+	data := []byte("value1")
+
+	store1, err := prov.OpenStore("store1")
+	require.NoError(t, err)
+
+	err = store1.Put(commonKey, data)
+	require.NoError(t, err)
+
+	doc, err := store1.Get(commonKey)
+	require.NoError(t, err)
+	require.Equal(t, data, doc)
+
+	// now try Delete with an empty key - should fail
+	err = store1.Delete("")
+	require.EqualError(t, err, "key is mandatory")
+
+	err = store1.Delete(commonKey)
+	require.NoError(t, err)
+
+	doc, err = store1.Get(commonKey)
+	require.EqualError(t, err, storage.ErrDataNotFound.Error())
+	require.Empty(t, doc)
+}
+
+func TestRedisStoreTTL(t *testing.T) {
+	prov, err := NewProvider(redisStoreAddr, WithTTL(time.Minute))
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testTTL")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("key1", []byte("value1")))
+	require.NoError(t, store.PutBatch([]storage.KeyValue{{Key: "key2", Value: []byte("value2")}}))
+
+	rs, ok := store.(*redisStore)
+	require.True(t, ok)
+
+	for _, k := range []string{"key1", "key2"} {
+		ttl, err := rs.client.TTL(rs.namespace + k).Result()
+		require.NoError(t, err)
+		require.True(t, ttl > 0 && ttl <= time.Minute)
+	}
+
+	require.NoError(t, prov.Close())
+}
+
+func TestRedisStorePutIfMatch(t *testing.T) {
+	prov, err := NewProvider(redisStoreAddr)
+	require.NoError(t, err)
+
+	store, err := prov.OpenStore("testCAS")
+	require.NoError(t, err)
+
+	// a fresh key for every run since the records persist in the DB
+	key := fmt.Sprintf("cas-%d", time.Now().UnixNano())
+
+	ok, err := store.PutIfMatch(key, nil, []byte("value1"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// create-only fails once the key exists
+	ok, err = store.PutIfMatch(key, nil, []byte("value2"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = store.PutIfMatch(key, []byte("other"), []byte("value2"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	v, err := store.Get(key)
+	require.NoError(t, err)
+	require.Equal(t, []byte("value1"), v)
+
+	ok, err = store.PutIfMatch(key, []byte("value1"), []byte("value2"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	v, err = store.Get(key)
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), v)
+
+	// unchanged value
+	ok, err = store.PutIfMatch(key, []byte("value2"), []byte("value2"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = store.PutIfMatch(key+"-missing", []byte("value1"), []byte("value2"))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = store.PutIfMatch("", nil, []byte("value1"))
+	require.Error(t, err)
+
+	require.NoError(t, prov.Close())
+}
+
+func verifyItr(t *testing.T, itr storage.StoreIterator, count int, prefix string) {
+	var vals []string
+
+	for itr.Next() {
+		if prefix != "" {
+			require.True(t, strings.HasPrefix(string(itr.Key()), prefix))
+		}
+
+		require.Equal(t, fmt.Sprintf("val-for-%s", itr.Key()), string(itr.Value()))
+
+		vals = append(vals, string(itr.Value()))
+	}
+
+	require.Len(t, vals, count)
+
+	itr.Release()
+	require.False(t, itr.Next())
+	require.Empty(t, itr.Key())
+	require.Empty(t, itr.Value())
+	require.NoError(t, itr.Error())
+}
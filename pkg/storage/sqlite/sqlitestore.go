@@ -0,0 +1,734 @@
+// +build !js,!wasm
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package sqlite provides a SQLite implementation of storage.Provider, using the pure Go driver of modernc.org/sqlite
+// so that it doesn't require cgo nor a database server, ie to run the tests of the code using the stores in-process.
+//
+// Its stores follow the semantics of the MySQL stores: iterators, counts and range deletes operate on the
+// [startKey, endKey) range, storage.EndKeySuffix matching all the keys starting with the rest of endKey, and an empty
+// endKey leaving the range unbounded. Keys are compared by their UTF-8 bytes.
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	// registers the sqlite driver with database/sql
+	_ "modernc.org/sqlite"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+var logger = log.New("aries-framework/storage/sqlite")
+
+// Provider represents a SQLite DB implementation of the storage.Provider interface
+type Provider struct {
+	dsn      string
+	db       *sql.DB
+	dbs      map[string]*sqlDBStore
+	dbPrefix string
+	// strictDelete makes the stores return storage.ErrDataNotFound when deleting a missing key
+	strictDelete bool
+	sync.RWMutex
+}
+
+type sqlDBStore struct {
+	db           *sql.DB
+	tableName    string
+	strictDelete bool
+}
+
+type result struct {
+	key   string
+	value []byte
+}
+
+var (
+	_ storage.RangeDeleter = (*sqlDBStore)(nil)
+	_ storage.FullIterator = (*sqlDBStore)(nil)
+)
+
+const (
+	blankDSNErrMsg            = "DSN for new SQLite DB provider can't be blank"
+	failToCloseProviderErrMsg = "failed to close provider"
+	tablePrefix               = "t_"
+	// endKeySuffix replaces storage.EndKeySuffix in the end key of ranges, it sorts after the other printable ASCII
+	// characters like for the leveldb stores
+	endKeySuffix = "~"
+	// maxBatchRows caps the number of rows sent in a single multi-row statement to stay well under the
+	// variables limit of SQLite statements
+	maxBatchRows = 1000
+	// iteratorPageSize is the number of rows fetched at once by the iterators
+	iteratorPageSize = 100
+)
+
+// Option configures the sqlite provider
+type Option func(opts *Provider)
+
+// WithDBPrefix option is for adding prefix to table names, it is trimmed with storage.TrimDBPrefix
+func WithDBPrefix(dbPrefix string) Option {
+	return func(opts *Provider) {
+		opts.dbPrefix = storage.TrimDBPrefix(dbPrefix)
+	}
+}
+
+// WithStrictDelete option makes the Delete of the stores return storage.ErrDataNotFound when the key doesn't exist,
+// deletes are idempotent by default.
+func WithStrictDelete() Option {
+	return func(opts *Provider) {
+		opts.strictDelete = true
+	}
+}
+
+// NewProvider instantiates Provider. dsn is either the path of the database file, created if it doesn't exist, or
+// :memory: for a database held in memory until the provider is closed.
+//
+// The provider uses a single connection: SQLite serializes the writes anyway, and the in-memory databases only live
+// within their connection. Iterators fetch their rows by pages instead of holding the connection, the stores may
+// then be used while iterating.
+func NewProvider(dsn string, opts ...Option) (*Provider, error) {
+	if dsn == "" {
+		return nil, errors.New(blankDSNErrMsg)
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection: %w", err)
+	}
+
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	p := &Provider{
+		dsn: dsn,
+		db:  db,
+		dbs: map[string]*sqlDBStore{},
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p, nil
+}
+
+// OpenStore opens and returns the store for given name space, creating its table if it doesn't exist.
+// All the stores share the connection of the provider, each store being mapped to its own table.
+func (p *Provider) OpenStore(name string) (storage.Store, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if name == "" {
+		return nil, errors.New("store name is required")
+	}
+
+	if p.dbPrefix != "" {
+		name = p.dbPrefix + "_" + name
+	}
+
+	if store, exists := p.dbs[name]; exists {
+		return store, nil
+	}
+
+	tableName := quoteIdentifier(tablePrefix + name)
+	// the default BINARY collation compares keys byte by byte to keep the same range semantics as the other stores
+	createTableStmt := "CREATE TABLE IF NOT EXISTS " + tableName +
+		" (key TEXT NOT NULL, value BLOB, PRIMARY KEY (key)) WITHOUT ROWID"
+
+	// creating key-value table
+	_, err := p.db.Exec(createTableStmt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create table %s: %w", tableName, err)
+	}
+
+	store := &sqlDBStore{
+		db:           p.db,
+		tableName:    tableName,
+		strictDelete: p.strictDelete,
+	}
+
+	p.dbs[name] = store
+
+	return store, nil
+}
+
+// quoteIdentifier quotes the table name with double quotes to interpolate it in statements, double quotes of the
+// name are escaped.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Close closes the provider, in-memory databases are discarded.
+func (p *Provider) Close() error {
+	p.Lock()
+	defer p.Unlock()
+
+	if err := p.db.Close(); err != nil {
+		return fmt.Errorf(failToCloseProviderErrMsg+": %w", err)
+	}
+
+	p.dbs = make(map[string]*sqlDBStore)
+
+	return nil
+}
+
+// CloseStore closes a previously opened store. The connection remains open since it is shared by all the stores of
+// the provider.
+func (p *Provider) CloseStore(name string) error {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.dbPrefix != "" {
+		name = p.dbPrefix + "_" + name
+	}
+
+	delete(p.dbs, name)
+
+	return nil
+}
+
+// Put stores the key and the value
+func (s *sqlDBStore) Put(k string, v []byte) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	//nolint: gosec
+	// create upsert query to insert the record, checking whether the key is already mapped to a value in the store.
+	createStmt := "INSERT INTO " + s.tableName + " (key, value) VALUES (?, ?)" +
+		" ON CONFLICT (key) DO UPDATE SET value = excluded.value"
+
+	_, err := s.db.Exec(createStmt, k, v)
+	if err != nil {
+		return fmt.Errorf("failed to insert key and value record into %s %w ", s.tableName, err)
+	}
+
+	return nil
+}
+
+// PutIfMatch updates the value of the key only if it currently equals expected, or inserts it only if the key
+// doesn't exist yet when expected is nil.
+func (s *sqlDBStore) PutIfMatch(k string, expected, newValue []byte) (bool, error) {
+	if k == "" {
+		return false, storage.ErrKeyRequired
+	}
+
+	//nolint: gosec
+	// insert query leaving the record untouched if the key is already mapped to a value in the store.
+	stmt := "INSERT INTO " + s.tableName + " (key, value) VALUES (?, ?) ON CONFLICT (key) DO NOTHING"
+	args := []interface{}{k, newValue}
+
+	if expected != nil {
+		//nolint: gosec
+		// update query only changing the record if it still holds the expected value, unlike MySQL SQLite counts
+		// the rows matched by an update even when their value is unchanged
+		stmt = "UPDATE " + s.tableName + " SET value = ? WHERE key = ? AND value = ?"
+		args = []interface{}{newValue, k, expected}
+	}
+
+	res, err := s.db.Exec(stmt, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to put key and value record into %s %w ", s.tableName, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows %w", err)
+	}
+
+	return n > 0, nil
+}
+
+// PutBatch stores the given key/value pairs using multi-row upserts executed within a single transaction, so either
+// all the pairs are stored or none of them are.
+func (s *sqlDBStore) PutBatch(kvs []storage.KeyValue) error {
+	for _, kv := range kvs {
+		if kv.Key == "" {
+			return storage.ErrKeyRequired
+		}
+	}
+
+	if len(kvs) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for batch insert into %s %w ", s.tableName, err)
+	}
+
+	for start := 0; start < len(kvs); start += maxBatchRows {
+		end := start + maxBatchRows
+		if end > len(kvs) {
+			end = len(kvs)
+		}
+
+		err = s.putBatchRows(tx, kvs[start:end])
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return fmt.Errorf("failed to rollback batch insert: %s: %w", rollbackErr.Error(), err)
+			}
+
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit batch insert into %s %w ", s.tableName, err)
+	}
+
+	return nil
+}
+
+func (s *sqlDBStore) putBatchRows(tx *sql.Tx, kvs []storage.KeyValue) error {
+	placeholders := make([]string, len(kvs))
+	args := make([]interface{}, 0, 2*len(kvs))
+
+	for i, kv := range kvs {
+		placeholders[i] = "(?, ?)"
+		args = append(args, kv.Key, kv.Value)
+	}
+
+	//nolint: gosec
+	// create multi-row upsert query, the rows being upserted in order the last value of a repeated key is stored.
+	createStmt := "INSERT INTO " + s.tableName + " (key, value) VALUES " + strings.Join(placeholders, ", ") +
+		" ON CONFLICT (key) DO UPDATE SET value = excluded.value"
+
+	_, err := tx.Exec(createStmt, args...)
+	if err != nil {
+		return fmt.Errorf("failed to insert batch of key and value records into %s %w ", s.tableName, err)
+	}
+
+	return nil
+}
+
+// Get fetches the value based on key
+func (s *sqlDBStore) Get(k string) ([]byte, error) {
+	if k == "" {
+		return nil, storage.ErrKeyRequired
+	}
+
+	var value []byte
+	//nolint: gosec
+	// select query to fetch the record by key
+	err := s.db.QueryRow("SELECT value FROM "+s.tableName+" WHERE key = ?", k).Scan(&value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrDataNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get row %w", err)
+	}
+
+	return value, nil
+}
+
+// GetBulk fetches the values of the given keys, querying the store once per batch of keys
+func (s *sqlDBStore) GetBulk(keys ...string) ([][]byte, error) {
+	for _, k := range keys {
+		if k == "" {
+			return nil, storage.ErrKeyRequired
+		}
+	}
+
+	found := make(map[string][]byte, len(keys))
+
+	for start := 0; start < len(keys); start += maxBatchRows {
+		end := start + maxBatchRows
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		if err := s.getBulkRows(keys[start:end], found); err != nil {
+			return nil, err
+		}
+	}
+
+	values := make([][]byte, len(keys))
+
+	for i, k := range keys {
+		values[i] = found[k]
+	}
+
+	return values, nil
+}
+
+func (s *sqlDBStore) getBulkRows(keys []string, found map[string][]byte) error {
+	placeholders := make([]string, len(keys))
+	args := make([]interface{}, len(keys))
+
+	for i, k := range keys {
+		placeholders[i] = "?"
+		args[i] = k
+	}
+
+	//nolint: gosec
+	// select query to fetch the records of the batch of keys at once
+	rows, err := s.db.Query("SELECT key, value FROM "+s.tableName+" WHERE key IN ("+
+		strings.Join(placeholders, ", ")+")", args...)
+	if err != nil {
+		return fmt.Errorf("failed to get rows %w", err)
+	}
+
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			logger.Warnf("failed to close rows: %s", closeErr)
+		}
+	}()
+
+	for rows.Next() {
+		var r result
+
+		if err = rows.Scan(&r.key, &r.value); err != nil {
+			return fmt.Errorf("failed to scan row %w", err)
+		}
+
+		found[r.key] = r.value
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to get rows %w", err)
+	}
+
+	return nil
+}
+
+// Has checks whether a record with key k exists, without transferring its value
+func (s *sqlDBStore) Has(k string) (bool, error) {
+	if k == "" {
+		return false, storage.ErrKeyRequired
+	}
+
+	var found int
+	//nolint: gosec
+	// select query to check the key presence without fetching the value
+	err := s.db.QueryRow("SELECT 1 FROM "+s.tableName+" WHERE key = ? LIMIT 1", k).Scan(&found)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to check row %w", err)
+	}
+
+	return true, nil
+}
+
+// Delete will delete record with k key, when the provider has the WithStrictDelete option it returns
+// storage.ErrDataNotFound if there's no record of the key.
+func (s *sqlDBStore) Delete(k string) error {
+	if k == "" {
+		return storage.ErrKeyRequired
+	}
+
+	//nolint: gosec
+	// delete query to delete the record by key
+	res, err := s.db.Exec("DELETE FROM "+s.tableName+" WHERE key = ?", k)
+	if err != nil {
+		return fmt.Errorf("failed to delete row %w", err)
+	}
+
+	if !s.strictDelete {
+		return nil
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows %w", err)
+	}
+
+	if n == 0 {
+		return storage.ErrDataNotFound
+	}
+
+	return nil
+}
+
+// DeleteRange deletes the records within the [startKey, endKey) range with a single statement and returns the number
+// of records deleted.
+func (s *sqlDBStore) DeleteRange(startKey, endKey string) (int, error) {
+	condition, args := rangeCondition(startKey, endKey)
+
+	//nolint: gosec
+	// delete query to delete all the records of the range
+	res, err := s.db.Exec("DELETE FROM "+s.tableName+" WHERE "+condition, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete range %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows %w", err)
+	}
+
+	return int(n), nil
+}
+
+// rangeCondition returns the condition selecting the keys of the [startKey, endKey) range and its arguments. An empty
+// endKey leaves the range unbounded unless startKey is empty too, the range of two empty keys matching no records.
+func rangeCondition(startKey, endKey string) (string, []interface{}) {
+	if endKey == "" && startKey != "" {
+		return "key >= ?", []interface{}{startKey}
+	}
+
+	return "key >= ? AND key < ?", []interface{}{startKey, strings.ReplaceAll(endKey, storage.EndKeySuffix,
+		endKeySuffix)}
+}
+
+// Count returns the number of records within the [startKey, endKey) range, the whole table being counted when both
+// keys are empty.
+func (s *sqlDBStore) Count(startKey, endKey string) (int, error) {
+	//nolint:gosec
+	// query to count all the records of the table
+	queryStmt := "SELECT COUNT(*) FROM " + s.tableName
+
+	var args []interface{}
+
+	if startKey != "" || endKey != "" {
+		var condition string
+
+		condition, args = rangeCondition(startKey, endKey)
+		queryStmt += " WHERE " + condition
+	}
+
+	var count int
+
+	err := s.db.QueryRow(queryStmt, args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rows %w", err)
+	}
+
+	return count, nil
+}
+
+// ForEach calls fn with every key/value pair within the key range until fn returns stop or an error
+func (s *sqlDBStore) ForEach(startKey, endKey string, fn func(key, value []byte) (bool, error)) error {
+	return storage.ForEach(s.Iterator(startKey, endKey), fn)
+}
+
+// Iterator returns an iterator over the [startKey, endKey) range, storage.EndKeySuffix is supported in endKey to
+// build prefix ranges. Records are returned in descending key order when storage.WithReverse is given, and their
+// number is capped by storage.WithLimit.
+func (s *sqlDBStore) Iterator(startKey, endKey string, opts ...storage.IteratorOption) storage.StoreIterator {
+	condition, args := rangeCondition(startKey, endKey)
+
+	return newIterator(s, condition, args, storage.GetIteratorOptions(opts...))
+}
+
+// IteratorAll returns an iterator over all the records of the store, with the same options as Iterator.
+func (s *sqlDBStore) IteratorAll(opts ...storage.IteratorOption) storage.StoreIterator {
+	return newIterator(s, "key >= ?", []interface{}{""}, storage.GetIteratorOptions(opts...))
+}
+
+// sqlDBResultsIterator fetches the rows of its condition by pages of iteratorPageSize rows, each page being queried
+// from the last key of the previous one. The connection of the store is only held while a page is fetched.
+type sqlDBResultsIterator struct {
+	store     *sqlDBStore
+	condition string
+	args      []interface{}
+	options   storage.IteratorOptions
+	// page holds the fetched rows, current is the index of the current row in page
+	page    []result
+	current int
+	// fromKey bounds the next page: it starts after fromKey, or at it when fromInclusive is set by Seek
+	fromKey       *string
+	fromInclusive bool
+	// exhausted is set once the last page of the range is fetched
+	exhausted bool
+	returned  int
+	err       error
+	// totalCount caches the result of TotalCount, -1 until it's counted
+	totalCount int
+}
+
+func newIterator(s *sqlDBStore, condition string, args []interface{},
+	options storage.IteratorOptions) *sqlDBResultsIterator {
+	return &sqlDBResultsIterator{
+		store:      s,
+		condition:  condition,
+		args:       args,
+		options:    options,
+		current:    -1,
+		totalCount: -1,
+	}
+}
+
+// fetchPage queries the next page of rows in the iteration order.
+func (i *sqlDBResultsIterator) fetchPage() {
+	queryStmt := "SELECT key, value FROM " + i.store.tableName + " WHERE " + i.condition
+	args := append([]interface{}{}, i.args...)
+
+	if i.fromKey != nil {
+		op := ">"
+		if i.options.Reverse {
+			op = "<"
+		}
+
+		if i.fromInclusive {
+			op += "="
+		}
+
+		queryStmt += " AND key " + op + " ?"
+		args = append(args, *i.fromKey)
+	}
+
+	queryStmt += " ORDER BY key"
+
+	if i.options.Reverse {
+		queryStmt += " DESC"
+	}
+
+	pageSize := iteratorPageSize
+	if i.options.Limit > 0 && i.options.Limit-i.returned < pageSize {
+		pageSize = i.options.Limit - i.returned
+	}
+
+	queryStmt += " LIMIT ?"
+	args = append(args, pageSize)
+
+	i.page = i.page[:0]
+	i.current = -1
+
+	//nolint:gosec
+	rows, err := i.store.db.Query(queryStmt, args...)
+	if err != nil {
+		i.err = fmt.Errorf("failed to query rows %w", err)
+
+		return
+	}
+
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			logger.Warnf("failed to close rows: %s", closeErr)
+		}
+	}()
+
+	for rows.Next() {
+		var r result
+
+		if err = rows.Scan(&r.key, &r.value); err != nil {
+			i.err = fmt.Errorf("failed to scan row %w", err)
+
+			return
+		}
+
+		i.page = append(i.page, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		i.err = fmt.Errorf("failed to get resulted rows %w", err)
+
+		return
+	}
+
+	i.exhausted = len(i.page) < pageSize
+
+	if len(i.page) > 0 {
+		lastKey := i.page[len(i.page)-1].key
+		i.fromKey = &lastKey
+		i.fromInclusive = false
+	}
+}
+
+// TotalCount returns the number of rows matching the iterator, regardless of its limit. The rows are counted with a
+// COUNT query the first time it's called, the count is then cached.
+func (i *sqlDBResultsIterator) TotalCount() (int, error) {
+	if i.totalCount >= 0 {
+		return i.totalCount, nil
+	}
+
+	var count int
+
+	//nolint:gosec
+	err := i.store.db.QueryRow("SELECT COUNT(*) FROM "+i.store.tableName+" WHERE "+i.condition, i.args...).
+		Scan(&count)
+	if err != nil {
+		return -1, fmt.Errorf("failed to count rows %w", err)
+	}
+
+	i.totalCount = count
+
+	return count, nil
+}
+
+func (i *sqlDBResultsIterator) Next() bool {
+	if i.err != nil || (i.options.Limit > 0 && i.returned >= i.options.Limit) {
+		i.page = nil
+
+		return false
+	}
+
+	if i.current+1 >= len(i.page) {
+		if i.exhausted {
+			i.page = nil
+
+			return false
+		}
+
+		i.fetchPage()
+
+		if i.err != nil || len(i.page) == 0 {
+			i.page = nil
+
+			return false
+		}
+	}
+
+	i.current++
+	i.returned++
+
+	return true
+}
+
+// Seek moves the iterator to the first key-value pair of the range at or after key in the iteration order, the rows
+// being fetched again from key.
+func (i *sqlDBResultsIterator) Seek(key string) bool {
+	if i.err != nil || (i.options.Limit > 0 && i.returned >= i.options.Limit) {
+		return false
+	}
+
+	i.page = nil
+	i.current = -1
+	i.exhausted = false
+	i.fromKey = &key
+	i.fromInclusive = true
+
+	return i.Next()
+}
+
+// Release drops the fetched rows, the iterator is then exhausted.
+func (i *sqlDBResultsIterator) Release() {
+	i.page = nil
+	i.current = -1
+	i.exhausted = true
+}
+
+func (i *sqlDBResultsIterator) Error() error {
+	return i.err
+}
+
+// Key returns the key of the current key-value pair.
+func (i *sqlDBResultsIterator) Key() []byte {
+	if i.current < 0 || i.current >= len(i.page) {
+		return nil
+	}
+
+	return []byte(i.page[i.current].key)
+}
+
+// Value returns the value of the current key-value pair.
+func (i *sqlDBResultsIterator) Value() []byte {
+	if i.current < 0 || i.current >= len(i.page) {
+		return nil
+	}
+
+	return i.page[i.current].value
+}
@@ -0,0 +1,417 @@
+// +build !js,!wasm
+
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sqlite
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/storagetest"
+)
+
+const memoryDSN = ":memory:"
+
+func TestSQLiteStore(t *testing.T) {
+	prov, err := NewProvider(memoryDSN)
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, prov.Close())
+	}()
+
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	t.Run("Test put, get, has and delete", func(t *testing.T) {
+		require.NoError(t, store.Put("k1", []byte("v1")))
+		require.NoError(t, store.Put("k1", []byte("v2")))
+
+		value, err := store.Get("k1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v2"), value)
+
+		found, err := store.Has("k1")
+		require.NoError(t, err)
+		require.True(t, found)
+
+		require.NoError(t, store.Delete("k1"))
+		require.NoError(t, store.Delete("k1"))
+
+		_, err = store.Get("k1")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		found, err = store.Has("k1")
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+
+	t.Run("Test put if match", func(t *testing.T) {
+		stored, err := store.PutIfMatch("match", nil, []byte("v1"))
+		require.NoError(t, err)
+		require.True(t, stored)
+
+		stored, err = store.PutIfMatch("match", nil, []byte("v2"))
+		require.NoError(t, err)
+		require.False(t, stored)
+
+		stored, err = store.PutIfMatch("match", []byte("v2"), []byte("v3"))
+		require.NoError(t, err)
+		require.False(t, stored)
+
+		stored, err = store.PutIfMatch("match", []byte("v1"), []byte("v1"))
+		require.NoError(t, err)
+		require.True(t, stored)
+
+		stored, err = store.PutIfMatch("match", []byte("v1"), []byte("v3"))
+		require.NoError(t, err)
+		require.True(t, stored)
+
+		value, err := store.Get("match")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v3"), value)
+	})
+
+	t.Run("Test put batch and get bulk", func(t *testing.T) {
+		kvs := make([]storage.KeyValue, 0, maxBatchRows+10)
+		for i := 0; i < maxBatchRows+10; i++ {
+			kvs = append(kvs, storage.KeyValue{Key: fmt.Sprintf("batch_%04d", i), Value: []byte(fmt.Sprint(i))})
+		}
+
+		kvs = append(kvs, storage.KeyValue{Key: "batch_0000", Value: []byte("last")})
+
+		require.NoError(t, store.PutBatch(kvs))
+
+		values, err := store.GetBulk("batch_0000", "batch_missing", "batch_1009")
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("last"), nil, []byte("1009")}, values)
+
+		count, err := store.Count("batch_", "batch_"+storage.EndKeySuffix)
+		require.NoError(t, err)
+		require.Equal(t, maxBatchRows+10, count)
+
+		err = store.PutBatch([]storage.KeyValue{{Key: "batch_x", Value: []byte("x")}, {Value: []byte("y")}})
+		require.Equal(t, storage.ErrKeyRequired, err)
+
+		found, err := store.Has("batch_x")
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+
+	t.Run("Test missing key", func(t *testing.T) {
+		require.Equal(t, storage.ErrKeyRequired, store.Put("", []byte("v")))
+		require.Equal(t, storage.ErrKeyRequired, store.Delete(""))
+
+		_, err := store.Get("")
+		require.Equal(t, storage.ErrKeyRequired, err)
+
+		_, err = store.Has("")
+		require.Equal(t, storage.ErrKeyRequired, err)
+
+		_, err = store.GetBulk("k", "")
+		require.Equal(t, storage.ErrKeyRequired, err)
+
+		_, err = store.PutIfMatch("", nil, []byte("v"))
+		require.Equal(t, storage.ErrKeyRequired, err)
+	})
+}
+
+func TestSQLiteStoreIterator(t *testing.T) {
+	prov, err := NewProvider(memoryDSN, WithDBPrefix("itr"))
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, prov.Close())
+	}()
+
+	store, err := prov.OpenStore("test")
+	require.NoError(t, err)
+
+	const n = 2*iteratorPageSize + 5
+
+	for i := 0; i < n; i++ {
+		require.NoError(t, store.Put(fmt.Sprintf("abc_%03d", i), []byte(fmt.Sprint(i))))
+	}
+
+	require.NoError(t, store.Put("abd_1", []byte("other")))
+	require.NoError(t, store.Put("ab", []byte("before")))
+
+	t.Run("Test range with end key suffix across pages", func(t *testing.T) {
+		itr := store.Iterator("abc_", "abc_"+storage.EndKeySuffix)
+		defer itr.Release()
+
+		count := 0
+
+		for itr.Next() {
+			require.Equal(t, fmt.Sprintf("abc_%03d", count), string(itr.Key()))
+			require.Equal(t, fmt.Sprint(count), string(itr.Value()))
+
+			// the store can be used while iterating
+			_, err := store.Get(string(itr.Key()))
+			require.NoError(t, err)
+
+			count++
+		}
+
+		require.NoError(t, itr.Error())
+		require.Equal(t, n, count)
+		require.Nil(t, itr.Key())
+		require.Nil(t, itr.Value())
+
+		total, err := itr.TotalCount()
+		require.NoError(t, err)
+		require.Equal(t, n, total)
+	})
+
+	t.Run("Test range bounds", func(t *testing.T) {
+		keys := iterate(t, store.Iterator("abc_003", "abc_006"))
+		require.Equal(t, []string{"abc_003", "abc_004", "abc_005"}, keys)
+
+		keys = iterate(t, store.Iterator("abc_204", ""))
+		require.Equal(t, []string{"abc_204", "abd_1"}, keys)
+
+		require.Empty(t, iterate(t, store.Iterator("", "")))
+
+		keys = iterate(t, store.(storage.FullIterator).IteratorAll(storage.WithLimit(2)))
+		require.Equal(t, []string{"ab", "abc_000"}, keys)
+
+		count, err := store.Count("", "")
+		require.NoError(t, err)
+		require.Equal(t, n+2, count)
+
+		count, err = store.Count("abc_200", "")
+		require.NoError(t, err)
+		require.Equal(t, 6, count)
+	})
+
+	t.Run("Test reverse, limit and seek", func(t *testing.T) {
+		keys := iterate(t, store.Iterator("abc_", "abc_"+storage.EndKeySuffix, storage.WithReverse(),
+			storage.WithLimit(3)))
+		require.Equal(t, []string{"abc_204", "abc_203", "abc_202"}, keys)
+
+		itr := store.Iterator("abc_", "abc_"+storage.EndKeySuffix, storage.WithLimit(iteratorPageSize+2))
+		defer itr.Release()
+
+		require.True(t, itr.Seek("abc_150"))
+		require.Equal(t, "abc_150", string(itr.Key()))
+		require.True(t, itr.Next())
+		require.Equal(t, "abc_151", string(itr.Key()))
+
+		require.True(t, itr.Seek("abc_0995"))
+		require.Equal(t, "abc_100", string(itr.Key()))
+
+		count := 3
+		for itr.Next() {
+			count++
+		}
+
+		require.Equal(t, iteratorPageSize+2, count)
+		require.False(t, itr.Seek("abc_000"))
+
+		itr = store.Iterator("abc_", "abc_"+storage.EndKeySuffix, storage.WithReverse())
+		defer itr.Release()
+
+		require.True(t, itr.Seek("abc_0995"))
+		require.Equal(t, "abc_099", string(itr.Key()))
+		require.True(t, itr.Next())
+		require.Equal(t, "abc_098", string(itr.Key()))
+		require.False(t, itr.Seek("abc"))
+	})
+
+	t.Run("Test release", func(t *testing.T) {
+		itr := store.Iterator("abc_", "abc_"+storage.EndKeySuffix)
+		require.True(t, itr.Next())
+
+		itr.Release()
+		itr.Release()
+
+		require.False(t, itr.Next())
+		require.Nil(t, itr.Key())
+	})
+
+	t.Run("Test for each and delete range", func(t *testing.T) {
+		var keys []string
+
+		err := store.ForEach("abc_", "abc_"+storage.EndKeySuffix, func(key, _ []byte) (bool, error) {
+			keys = append(keys, string(key))
+
+			return len(keys) == 2, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"abc_000", "abc_001"}, keys)
+
+		deleted, err := store.(storage.RangeDeleter).DeleteRange("abc_100", "abc_"+storage.EndKeySuffix)
+		require.NoError(t, err)
+		require.Equal(t, n-100, deleted)
+
+		count, err := store.Count("abc_", "abc_"+storage.EndKeySuffix)
+		require.NoError(t, err)
+		require.Equal(t, 100, count)
+	})
+}
+
+func TestSQLiteProvider(t *testing.T) {
+	t.Run("Test blank DSN", func(t *testing.T) {
+		_, err := NewProvider("")
+		require.EqualError(t, err, blankDSNErrMsg)
+	})
+
+	t.Run("Test file database", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "sqlitestore")
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, os.RemoveAll(dir))
+		}()
+
+		dsn := filepath.Join(dir, "test.db")
+
+		prov, err := NewProvider(dsn, WithStrictDelete())
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		same, err := prov.OpenStore("test")
+		require.NoError(t, err)
+		require.Equal(t, store, same)
+
+		_, err = prov.OpenStore("")
+		require.EqualError(t, err, "store name is required")
+
+		require.NoError(t, store.Put("k1", []byte("v1")))
+		require.NoError(t, store.Put("k2", []byte("v2")))
+		require.NoError(t, store.Delete("k2"))
+		require.Equal(t, storage.ErrDataNotFound, store.Delete("k2"))
+
+		require.NoError(t, prov.CloseStore("test"))
+		require.NoError(t, prov.Close())
+
+		// the records are persisted in the file
+		prov, err = NewProvider(dsn)
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, prov.Close())
+		}()
+
+		store, err = prov.OpenStore("test")
+		require.NoError(t, err)
+
+		value, err := store.Get("k1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), value)
+	})
+
+	t.Run("Test empty and trimmed DB prefixes", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "sqlitestore")
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, os.RemoveAll(dir))
+		}()
+
+		dsn := filepath.Join(dir, "test.db")
+
+		for _, opts := range [][]Option{nil, {WithDBPrefix("prefixdb")}} {
+			prov, err := NewProvider(dsn, opts...)
+			require.NoError(t, err)
+
+			store, err := prov.OpenStore("test")
+			require.NoError(t, err)
+			require.NoError(t, store.Put("k1", []byte(fmt.Sprintf("%d", len(opts)))))
+			require.NoError(t, prov.Close())
+		}
+
+		for prefix, expected := range map[string]string{
+			"":           "0",
+			"_":          "0",
+			"prefixdb_":  "1",
+			" prefixdb ": "1",
+		} {
+			prov, err := NewProvider(dsn, WithDBPrefix(prefix))
+			require.NoError(t, err)
+
+			store, err := prov.OpenStore("test")
+			require.NoError(t, err)
+
+			value, err := store.Get("k1")
+			require.NoError(t, err)
+			require.Equal(t, expected, string(value), "prefix %q", prefix)
+			require.NoError(t, prov.Close())
+		}
+	})
+
+	t.Run("Test stores are isolated", func(t *testing.T) {
+		prov, err := NewProvider(memoryDSN)
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, prov.Close())
+		}()
+
+		store1, err := prov.OpenStore("store1")
+		require.NoError(t, err)
+
+		store2, err := prov.OpenStore(`store"2`)
+		require.NoError(t, err)
+
+		require.NoError(t, store1.Put("k", []byte("v")))
+
+		_, err = store2.Get("k")
+		require.Equal(t, storage.ErrDataNotFound, err)
+	})
+
+	t.Run("Test closed provider", func(t *testing.T) {
+		prov, err := NewProvider(memoryDSN)
+		require.NoError(t, err)
+
+		store, err := prov.OpenStore("test")
+		require.NoError(t, err)
+
+		require.NoError(t, prov.Close())
+
+		err = store.Put("k", []byte("v"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to insert key and value record")
+
+		itr := store.Iterator("a", "b")
+		require.False(t, itr.Next())
+		require.Error(t, itr.Error())
+
+		_, err = itr.TotalCount()
+		require.Error(t, err)
+	})
+}
+
+func iterate(t *testing.T, itr storage.StoreIterator) []string {
+	t.Helper()
+
+	defer itr.Release()
+
+	var keys []string
+
+	for itr.Next() {
+		keys = append(keys, string(itr.Key()))
+	}
+
+	require.NoError(t, itr.Error())
+
+	return keys
+}
+
+func TestSQLiteStoreConformance(t *testing.T) {
+	storagetest.RunSuite(t, func() (storage.Provider, error) {
+		return NewProvider(memoryDSN)
+	})
+}
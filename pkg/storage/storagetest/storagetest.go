@@ -0,0 +1,397 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package storagetest provides the conformance tests of storage.Provider implementations. Backends prove their
+// compliance with the contract of storage.Provider, storage.Store and storage.StoreIterator by calling RunSuite from
+// their own tests:
+//
+//	func TestStoreConformance(t *testing.T) {
+//		storagetest.RunSuite(t, func() (storage.Provider, error) {
+//			return NewProvider(dsn)
+//		})
+//	}
+package storagetest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+)
+
+// ProviderFactory returns a new provider of the tested backend, it's called once per test of the suite and the
+// returned provider is closed by the test. Providers of persistent backends may share their database: the tests use
+// stores with unique names.
+type ProviderFactory func() (storage.Provider, error)
+
+// RunSuite runs the conformance tests of the storage contract against the providers returned by newProvider.
+//
+// The iterators are expected to return the keys of their range in byte order, the stores approximating ranges with
+// prefix scans such as the redis ones can't run the suite. Otherwise the suite only checks the behaviors shared by all
+// the backends, ie it accepts both the empty and the whole store results of the range of two empty keys, and doesn't
+// expect the records of a store to be kept or discarded after CloseStore.
+func RunSuite(t *testing.T, newProvider ProviderFactory) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		run  func(t *testing.T, prov storage.Provider)
+	}{
+		{name: "put and get", run: testPutGet},
+		{name: "delete", run: testDelete},
+		{name: "empty keys", run: testEmptyKeys},
+		{name: "put batch and get bulk", run: testPutBatchGetBulk},
+		{name: "put if match", run: testPutIfMatch},
+		{name: "iterator ranges", run: testIteratorRanges},
+		{name: "iterator byte order", run: testIteratorByteOrder},
+		{name: "iterator options", run: testIteratorOptions},
+		{name: "count and for each", run: testCountForEach},
+		{name: "stores are isolated", run: testStoresIsolation},
+		{name: "close store", run: testCloseStore},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			prov, err := newProvider()
+			require.NoError(t, err)
+
+			defer func() {
+				require.NoError(t, prov.Close())
+			}()
+
+			tc.run(t, prov)
+		})
+	}
+}
+
+// storeName returns a unique store name, so that the tests don't see the records of the previous runs when the
+// providers share a persistent database.
+func storeName(prefix string) string {
+	return prefix + "_" + strings.ReplaceAll(uuid.New().String(), "-", "")[:12]
+}
+
+func openStore(t *testing.T, prov storage.Provider, prefix string) storage.Store {
+	t.Helper()
+
+	store, err := prov.OpenStore(storeName(prefix))
+	require.NoError(t, err)
+
+	return store
+}
+
+func testPutGet(t *testing.T, prov storage.Provider) {
+	store := openStore(t, prov, "putget")
+
+	require.NoError(t, store.Put("did:example:1", []byte("value1")))
+
+	value, err := store.Get("did:example:1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value1"), value)
+
+	// update
+	require.NoError(t, store.Put("did:example:1", []byte("value2")))
+
+	value, err = store.Get("did:example:1")
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), value)
+
+	found, err := store.Has("did:example:1")
+	require.NoError(t, err)
+	require.True(t, found)
+
+	_, err = store.Get("did:example:2")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound), "unexpected error: %v", err)
+
+	found, err = store.Has("did:example:2")
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+func testDelete(t *testing.T, prov storage.Provider) {
+	store := openStore(t, prov, "delete")
+
+	require.NoError(t, store.Put("k1", []byte("v1")))
+	require.NoError(t, store.Put("k2", []byte("v2")))
+
+	require.NoError(t, store.Delete("k1"))
+
+	_, err := store.Get("k1")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound), "unexpected error: %v", err)
+
+	value, err := store.Get("k2")
+	require.NoError(t, err)
+	require.Equal(t, []byte("v2"), value)
+
+	// deletes are idempotent by default
+	require.NoError(t, store.Delete("k1"))
+	require.NoError(t, store.Delete("missing"))
+}
+
+func testEmptyKeys(t *testing.T, prov storage.Provider) {
+	store := openStore(t, prov, "emptykeys")
+
+	requireKeyRequired := func(err error) {
+		t.Helper()
+		require.True(t, errors.Is(err, storage.ErrKeyRequired), "unexpected error: %v", err)
+	}
+
+	requireKeyRequired(store.Put("", []byte("value")))
+	requireKeyRequired(store.Delete(""))
+	requireKeyRequired(store.PutBatch([]storage.KeyValue{{Key: "k", Value: []byte("v")}, {Value: []byte("v")}}))
+
+	_, err := store.Get("")
+	requireKeyRequired(err)
+
+	_, err = store.Has("")
+	requireKeyRequired(err)
+
+	_, err = store.GetBulk("k", "")
+	requireKeyRequired(err)
+
+	_, err = store.PutIfMatch("", nil, []byte("value"))
+	requireKeyRequired(err)
+
+	// the batch is rejected before anything is written
+	found, err := store.Has("k")
+	require.NoError(t, err)
+	require.False(t, found)
+}
+
+func testPutBatchGetBulk(t *testing.T, prov storage.Provider) {
+	store := openStore(t, prov, "batch")
+
+	require.NoError(t, store.Put("k1", []byte("old")))
+	require.NoError(t, store.PutBatch(nil))
+	require.NoError(t, store.PutBatch([]storage.KeyValue{
+		{Key: "k1", Value: []byte("v1")},
+		{Key: "k2", Value: []byte("v2")},
+		{Key: "k3", Value: []byte("v3")},
+	}))
+
+	values, err := store.GetBulk("k3", "missing", "k1")
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("v3"), nil, []byte("v1")}, values)
+
+	values, err = store.GetBulk()
+	require.NoError(t, err)
+	require.Empty(t, values)
+}
+
+func testPutIfMatch(t *testing.T, prov storage.Provider) {
+	store := openStore(t, prov, "putifmatch")
+
+	stored, err := store.PutIfMatch("k", nil, []byte("v1"))
+	require.NoError(t, err)
+	require.True(t, stored)
+
+	stored, err = store.PutIfMatch("k", nil, []byte("v2"))
+	require.NoError(t, err)
+	require.False(t, stored)
+
+	stored, err = store.PutIfMatch("k", []byte("other"), []byte("v2"))
+	require.NoError(t, err)
+	require.False(t, stored)
+
+	stored, err = store.PutIfMatch("k", []byte("v1"), []byte("v2"))
+	require.NoError(t, err)
+	require.True(t, stored)
+
+	value, err := store.Get("k")
+	require.NoError(t, err)
+	require.Equal(t, []byte("v2"), value)
+}
+
+func putAll(t *testing.T, store storage.Store, keys ...string) {
+	t.Helper()
+
+	for _, k := range keys {
+		require.NoError(t, store.Put(k, []byte("value-of-"+k)))
+	}
+}
+
+func iteratorKeys(t *testing.T, itr storage.StoreIterator) []string {
+	t.Helper()
+
+	defer itr.Release()
+
+	var keys []string
+
+	for itr.Next() {
+		keys = append(keys, string(itr.Key()))
+		require.Equal(t, "value-of-"+string(itr.Key()), string(itr.Value()))
+	}
+
+	require.NoError(t, itr.Error())
+
+	return keys
+}
+
+func testIteratorRanges(t *testing.T, prov storage.Provider) {
+	store := openStore(t, prov, "ranges")
+
+	putAll(t, store, "abc_1", "abc_2", "abc_3", "abd_1", "ab", "abc")
+
+	keys := iteratorKeys(t, store.Iterator("abc_", "abc_"+storage.EndKeySuffix))
+	require.Equal(t, []string{"abc_1", "abc_2", "abc_3"}, keys)
+
+	// the start key is included, the end key excluded
+	keys = iteratorKeys(t, store.Iterator("abc_1", "abc_3"))
+	require.Equal(t, []string{"abc_1", "abc_2"}, keys)
+
+	keys = iteratorKeys(t, store.Iterator("abc", "abd"))
+	require.Equal(t, []string{"abc", "abc_1", "abc_2", "abc_3"}, keys)
+
+	keys = iteratorKeys(t, store.Iterator("abd_", "abd_"+storage.EndKeySuffix))
+	require.Equal(t, []string{"abd_1"}, keys)
+
+	require.Empty(t, iteratorKeys(t, store.Iterator("abe_", "abe_"+storage.EndKeySuffix)))
+	require.Empty(t, iteratorKeys(t, store.Iterator("abc_3", "abc_1")))
+
+	// an empty end key iterates up to the end of the store
+	keys = iteratorKeys(t, store.Iterator("abc_2", ""))
+	require.Equal(t, []string{"abc_2", "abc_3", "abd_1"}, keys)
+
+	// the range of two empty keys matches either no records or all of them, depending on the backend
+	keys = iteratorKeys(t, store.Iterator("", ""))
+	if len(keys) > 0 {
+		require.Equal(t, []string{"ab", "abc", "abc_1", "abc_2", "abc_3", "abd_1"}, keys)
+	}
+}
+
+// testIteratorByteOrder checks that the keys are ordered by their bytes, regardless of the case or the charset
+// collation of the backend.
+func testIteratorByteOrder(t *testing.T, prov storage.Provider) {
+	store := openStore(t, prov, "byteorder")
+
+	putAll(t, store, "key_b", "key_B", "key_a", "key_A", "key_é", "key_z", "key_Z", "key_1")
+
+	keys := iteratorKeys(t, store.Iterator("key_", "key_"+storage.EndKeySuffix))
+	require.Equal(t, []string{"key_1", "key_A", "key_B", "key_Z", "key_a", "key_b", "key_z"}, keys)
+
+	keys = iteratorKeys(t, store.Iterator("key_B", "key_b"))
+	require.Equal(t, []string{"key_B", "key_Z", "key_a"}, keys)
+}
+
+func testIteratorOptions(t *testing.T, prov storage.Provider) {
+	store := openStore(t, prov, "itropts")
+
+	putAll(t, store, "k_1", "k_2", "k_3", "k_4", "k_5")
+
+	keys := iteratorKeys(t, store.Iterator("k_", "k_"+storage.EndKeySuffix, storage.WithReverse()))
+	require.Equal(t, []string{"k_5", "k_4", "k_3", "k_2", "k_1"}, keys)
+
+	keys = iteratorKeys(t, store.Iterator("k_", "k_"+storage.EndKeySuffix, storage.WithLimit(2)))
+	require.Equal(t, []string{"k_1", "k_2"}, keys)
+
+	keys = iteratorKeys(t, store.Iterator("k_", "k_"+storage.EndKeySuffix, storage.WithReverse(),
+		storage.WithLimit(2)))
+	require.Equal(t, []string{"k_5", "k_4"}, keys)
+
+	itr := store.Iterator("k_", "k_"+storage.EndKeySuffix)
+	defer itr.Release()
+
+	require.True(t, itr.Seek("k_3"))
+	require.Equal(t, "k_3", string(itr.Key()))
+	require.True(t, itr.Next())
+	require.Equal(t, "k_4", string(itr.Key()))
+	require.False(t, itr.Seek("k_6"))
+
+	total, err := itr.TotalCount()
+	if !errors.Is(err, storage.ErrTotalCountNotSupported) {
+		require.NoError(t, err)
+		require.Equal(t, 5, total)
+	}
+
+	itr.Release()
+	itr.Release()
+	require.False(t, itr.Next())
+}
+
+func testCountForEach(t *testing.T, prov storage.Provider) {
+	store := openStore(t, prov, "count")
+
+	putAll(t, store, "c_1", "c_2", "c_3", "d_1")
+
+	count, err := store.Count("c_", "c_"+storage.EndKeySuffix)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+
+	count, err = store.Count("", "")
+	require.NoError(t, err)
+	require.Equal(t, 4, count)
+
+	var keys []string
+
+	err = store.ForEach("c_", "c_"+storage.EndKeySuffix, func(key, value []byte) (bool, error) {
+		keys = append(keys, string(key))
+		require.Equal(t, "value-of-"+string(key), string(value))
+
+		return len(keys) == 2, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"c_1", "c_2"}, keys)
+
+	errStop := errors.New("stop")
+
+	err = store.ForEach("c_", "c_"+storage.EndKeySuffix, func(key, value []byte) (bool, error) {
+		return false, errStop
+	})
+	require.Equal(t, errStop, err)
+}
+
+func testStoresIsolation(t *testing.T, prov storage.Provider) {
+	store1 := openStore(t, prov, "isolation")
+	store2 := openStore(t, prov, "isolation")
+
+	require.NoError(t, store1.Put("k", []byte("v1")))
+
+	_, err := store2.Get("k")
+	require.True(t, errors.Is(err, storage.ErrDataNotFound), "unexpected error: %v", err)
+
+	require.Empty(t, iteratorKeys(t, store2.Iterator("k", "k"+storage.EndKeySuffix)))
+
+	require.NoError(t, store2.Put("k", []byte("v2")))
+
+	value, err := store1.Get("k")
+	require.NoError(t, err)
+	require.Equal(t, []byte("v1"), value)
+}
+
+func testCloseStore(t *testing.T, prov storage.Provider) {
+	name := storeName("closestore")
+
+	store, err := prov.OpenStore(name)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("k", []byte("v")))
+
+	// opening an opened store returns a usable store
+	store, err = prov.OpenStore(name)
+	require.NoError(t, err)
+
+	value, err := store.Get("k")
+	require.NoError(t, err)
+	require.Equal(t, []byte("v"), value)
+
+	require.NoError(t, prov.CloseStore(name))
+	require.NoError(t, prov.CloseStore(name))
+	require.NoError(t, prov.CloseStore(fmt.Sprintf("%s_never_opened", name)))
+
+	// a closed store can be opened again
+	store, err = prov.OpenStore(name)
+	require.NoError(t, err)
+	require.NoError(t, store.Put("k", []byte("v2")))
+
+	value, err = store.Get("k")
+	require.NoError(t, err)
+	require.Equal(t, []byte("v2"), value)
+}
@@ -6,7 +6,14 @@ SPDX-License-Identifier: Apache-2.0
 
 package storage
 
-import "errors"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
 // EndKeySuffix end key suffix
 const EndKeySuffix = "!!"
@@ -14,9 +21,41 @@ const EndKeySuffix = "!!"
 // ErrDataNotFound is returned when data not found
 var ErrDataNotFound = errors.New("data not found")
 
+// ErrStoreNotFound is returned by OpenExistingStore when the store doesn't exist
+var ErrStoreNotFound = errors.New("store not found")
+
 // ErrKeyRequired is returned when key is mandatory
 var ErrKeyRequired = errors.New("key is mandatory")
 
+// ErrNilValue is returned by the stores rejecting nil values, ie the mem and MySQL stores, which store empty values
+// and return them as empty, non nil, slices so that they can be told apart from missing records
+var ErrNilValue = errors.New("value is mandatory")
+
+// ErrKeyTooLarge is returned when a key exceeds the maximum key size of the store
+var ErrKeyTooLarge = errors.New("key is too large")
+
+// ErrValueTooLarge is returned when a value exceeds the maximum value size of the store
+var ErrValueTooLarge = errors.New("value is too large")
+
+// ErrTransactionsNotSupported is returned by stores that can't execute atomic transactions
+var ErrTransactionsNotSupported = errors.New("transactions are not supported")
+
+// ErrIsolationLevelNotSupported is returned when beginning a transaction with an isolation level the store can't
+// provide
+var ErrIsolationLevelNotSupported = errors.New("isolation level is not supported")
+
+// ErrInvalidTTL is returned when the time to live of a record isn't positive
+var ErrInvalidTTL = errors.New("ttl must be positive")
+
+// ErrTotalCountNotSupported is returned by the TotalCount method of iterators that can't count their records cheaply
+var ErrTotalCountNotSupported = errors.New("total count is not supported")
+
+// KeyValue is a key/value pair used by batch operations
+type KeyValue struct {
+	Key   string
+	Value []byte
+}
+
 // Provider storage provider interface
 type Provider interface {
 	// OpenStore opens a store with given name space and returns the handle
@@ -34,9 +73,24 @@ type Store interface {
 	// Put stores the key and the record
 	Put(k string, v []byte) error
 
+	// PutBatch stores all the given key/value pairs. If any of the keys is empty
+	// the whole batch is rejected with ErrKeyRequired before anything is written.
+	PutBatch(kvs []KeyValue) error
+
+	// PutIfMatch stores the record only if the current record of key k equals expected, as returned by Get,
+	// and reports whether it was stored. A nil expected record means that the key must not exist yet.
+	PutIfMatch(k string, expected, newValue []byte) (bool, error)
+
 	// Get fetches the record based on key
 	Get(k string) ([]byte, error)
 
+	// GetBulk fetches the records of all the given keys. Values are returned in the same order as the keys,
+	// with a nil entry for every key that isn't found.
+	GetBulk(keys ...string) ([][]byte, error)
+
+	// Has checks whether a record with key k exists, without fetching its value
+	Has(k string) (bool, error)
+
 	// Iterator returns an iterator for the latest snapshot of the
 	// underlying store
 	//
@@ -48,10 +102,379 @@ type Store interface {
 	// Returns:
 	//
 	// StoreIterator: iterator for result range
-	Iterator(startKey, endKey string) StoreIterator
+	Iterator(startKey, endKey string, opts ...IteratorOption) StoreIterator
+
+	// Count returns the number of records within the key range, with the same range semantics as Iterator.
+	// The whole store is counted when both startKey and endKey are empty.
+	Count(startKey, endKey string) (int, error)
+
+	// ForEach calls fn with every key/value pair within the key range, with the same range semantics as Iterator,
+	// until fn returns stop or an error. The iterator is released even if fn panics, the error returned by fn is
+	// returned as is.
+	ForEach(startKey, endKey string, fn func(key, value []byte) (stop bool, err error)) error
+
+	// Delete will delete a record with k key. Deleting a key that doesn't exist succeeds by default so that deletes
+	// are idempotent, stores of providers configured with a strict delete option (ie WithStrictDelete of the mysql,
+	// mem and leveldb providers) return ErrDataNotFound instead.
+	Delete(k string) error
+}
+
+// Transactional is implemented by stores able to execute several operations atomically.
+// Stores returned by Provider.OpenStore can be checked for this capability with a type assertion.
+type Transactional interface {
+	// Begin starts a new transaction, with the isolation level of the DB unless WithIsolationLevel is given. Stores
+	// that can't execute atomic transactions return ErrTransactionsNotSupported, the errors of stores that can't
+	// provide the isolation level wrap ErrIsolationLevelNotSupported.
+	Begin(opts ...TxOption) (Transaction, error)
+}
+
+// RangeDeleter is implemented by stores able to delete a range of records at once.
+// Stores returned by Provider.OpenStore can be checked for this capability with a type assertion.
+type RangeDeleter interface {
+	// DeleteRange deletes the records within the key range, with the same range semantics as Iterator, and returns
+	// the number of records deleted.
+	DeleteRange(startKey, endKey string) (int, error)
+}
+
+// FullIterator is implemented by stores able to iterate over all their records, the range of two empty keys of
+// Iterator matching no records in some stores. Stores returned by Provider.OpenStore can be checked for this
+// capability with a type assertion.
+type FullIterator interface {
+	// IteratorAll returns an iterator over all the records of the store, with the same options as Iterator.
+	IteratorAll(opts ...IteratorOption) StoreIterator
+}
+
+// JSONStore is implemented by stores holding JSON documents and able to read and update a single field of a record in
+// place, without transferring the whole document nor racing with concurrent updates of its other fields. Fields are
+// addressed by JSON path expressions such as $.name or $.credentials[0].id. Stores returned by Provider.OpenStore can
+// be checked for this capability with a type assertion.
+type JSONStore interface {
+	// GetField returns the JSON value found at jsonPath in the record of the key, ErrDataNotFound is returned when
+	// the key or the field doesn't exist.
+	GetField(key, jsonPath string) ([]byte, error)
+	// SetField sets the field at jsonPath of the record of the key to the given JSON value, creating the field when
+	// its parent exists. ErrDataNotFound is returned when the key doesn't exist.
+	SetField(key, jsonPath string, value []byte) error
+}
+
+// ContextStore is implemented by stores able to cancel their operations along with a context, ie when the deadline
+// of a request is exceeded. Stores returned by Provider.OpenStore can be checked for this capability with a type
+// assertion. The errors of cancelled operations wrap the error of the context.
+type ContextStore interface {
+	// PutContext stores the key and the record
+	PutContext(ctx context.Context, k string, v []byte) error
+
+	// GetContext fetches the record based on key
+	GetContext(ctx context.Context, k string) ([]byte, error)
+
+	// DeleteContext deletes the record of key k
+	DeleteContext(ctx context.Context, k string) error
+}
+
+// PrimaryReader is implemented by stores able to read from replicas of their backend, which may lag behind their
+// primary. Stores returned by Provider.OpenStore can be checked for this capability with a type assertion.
+type PrimaryReader interface {
+	// GetPrimary fetches the record based on key from the primary, ie to read a record right after writing it.
+	GetPrimary(k string) ([]byte, error)
+}
+
+// ExpiringStore is implemented by stores able to expire records after a time to live.
+// Stores returned by Provider.OpenStore can be checked for this capability with a type assertion.
+type ExpiringStore interface {
+	// PutWithExpiry stores the key and the record until ttl elapses, the record is then handled as if it was
+	// deleted. Storing the key again with Put or PutBatch removes the expiry.
+	PutWithExpiry(k string, v []byte, ttl time.Duration) error
+}
+
+// SoftDeleteStore is implemented by stores whose Delete can leave a tombstone in place of the deleted record, ie so
+// that a later sync with a remote agent can propagate the deletion. Tombstones are handled as missing records by the
+// methods of Store. Stores returned by Provider.OpenStore can be checked for this capability with a type assertion.
+type SoftDeleteStore interface {
+	// IteratorIncludingDeleted returns an iterator over the [startKey, endKey) range like Store.Iterator, the
+	// tombstones of the range being returned along with the records with a nil value.
+	IteratorIncludingDeleted(startKey, endKey string, opts ...IteratorOption) StoreIterator
+
+	// Purge physically removes the tombstones of the records deleted before the given time.
+	Purge(before time.Time) error
+}
+
+// TaggedStore is implemented by stores able to query records by tags, the tags being name/value pairs stored along
+// with the records like a minimal secondary index. Stores returned by Provider.OpenStore can be checked for this
+// capability with a type assertion.
+type TaggedStore interface {
+	// PutWithTags stores the key and the record along with the given tags, replacing the tags of the key. Storing the
+	// key again with Put or PutBatch leaves its tags unchanged, deleting the record deletes its tags.
+	PutWithTags(k string, v []byte, tags map[string]string) error
+
+	// QueryByTag returns an iterator over the records tagged with the given name and value, in ascending key order.
+	QueryByTag(name, value string) (StoreIterator, error)
+}
+
+// SizeEstimator is implemented by stores able to estimate the storage they consume, ie for capacity planning. Stores
+// returned by Provider.OpenStore can be checked for this capability with a type assertion.
+type SizeEstimator interface {
+	// ApproxSizeBytes returns the approximate number of bytes consumed by the store
+	ApproxSizeBytes() (int64, error)
+}
+
+// Pinger is implemented by providers able to check that their backend is reachable.
+// Providers can be checked for this capability with a type assertion, Health does it for its callers.
+type Pinger interface {
+	// Ping checks the backend of the provider is reachable
+	Ping(ctx context.Context) error
+}
+
+// StoreLister is implemented by providers able to list their stores, ie for diagnostic tools.
+// Providers can be checked for this capability with a type assertion.
+type StoreLister interface {
+	// StoreNames returns the names of the stores of the provider in ascending order, which open the stores when
+	// given to OpenStore
+	StoreNames() ([]string, error)
+}
+
+// ExistingStoreOpener is implemented by providers able to open a store without creating it, so that a misspelled
+// store name fails instead of opening a new empty store.
+// Providers can be checked for this capability with a type assertion.
+type ExistingStoreOpener interface {
+	// OpenExistingStore opens the store with the given name like Provider.OpenStore, it returns ErrStoreNotFound
+	// if the store doesn't exist
+	OpenExistingStore(name string) (Store, error)
+}
+
+// Truncater is implemented by providers able to delete all the records of a store at once, much faster than deleting
+// them one by one, ie to reset test fixtures.
+// Providers can be checked for this capability with a type assertion.
+type Truncater interface {
+	// Truncate deletes all the records of the store with the given name, the store stays open. Truncating a store
+	// which doesn't exist is a no-op, unless the provider is strict about deletes in which case ErrStoreNotFound is
+	// returned
+	Truncate(name string) error
+}
+
+// HealthStatus is the status of the backend of a storage provider
+type HealthStatus string
+
+const (
+	// HealthStatusUp is the status of a provider with a reachable backend
+	HealthStatusUp HealthStatus = "up"
+	// HealthStatusDown is the status of a provider with an unreachable backend
+	HealthStatusDown HealthStatus = "down"
+	// HealthStatusUnknown is the status of a provider that can't check its backend (ie not a Pinger)
+	HealthStatusUnknown HealthStatus = "unknown"
+)
+
+// HealthResult is the result of a storage provider health check
+type HealthResult struct {
+	Status HealthStatus
+	// Err is the reason of a HealthStatusDown status
+	Err error
+}
 
-	// Delete will delete a record with k key
+// Health checks the backend of the provider p. Providers implementing Pinger are reported up or down depending on the
+// result of their Ping, other providers are reported with an unknown status.
+func Health(ctx context.Context, p Provider) *HealthResult {
+	pinger, ok := p.(Pinger)
+	if !ok {
+		return &HealthResult{Status: HealthStatusUnknown}
+	}
+
+	if err := pinger.Ping(ctx); err != nil {
+		return &HealthResult{Status: HealthStatusDown, Err: err}
+	}
+
+	return &HealthResult{Status: HealthStatusUp}
+}
+
+// dbPrefixSeparator separates the DB prefix of the providers from the store names
+const dbPrefixSeparator = "_"
+
+// TrimDBPrefix returns the DB prefix given to the WithDBPrefix option of a provider without its surrounding spaces and
+// trailing separators, the providers adding the separator themselves. An empty prefix, or a prefix made of separators
+// only, is trimmed to no prefix: the provider then names its stores as without the option.
+func TrimDBPrefix(prefix string) string {
+	return strings.TrimRight(strings.TrimSpace(prefix), dbPrefixSeparator)
+}
+
+// Transaction is a set of store operations that are either all committed or all rolled back
+type Transaction interface {
+	// Put stores the key and the record within the transaction
+	Put(k string, v []byte) error
+
+	// Get fetches the record based on key within the transaction
+	Get(k string) ([]byte, error)
+
+	// Delete will delete a record with k key within the transaction
 	Delete(k string) error
+
+	// Commit makes all the operations of the transaction permanent
+	Commit() error
+
+	// Rollback discards all the operations of the transaction
+	Rollback() error
+}
+
+// IteratorOptions holds the options of a store iterator
+type IteratorOptions struct {
+	// Reverse makes the iterator return the records in descending key order
+	Reverse bool
+	// Limit caps the number of records returned by the iterator, zero means unlimited
+	Limit int
+}
+
+// IteratorOption configures a store iterator
+type IteratorOption func(opts *IteratorOptions)
+
+// WithReverse option makes the iterator return the records of the range in descending key order
+func WithReverse() IteratorOption {
+	return func(opts *IteratorOptions) {
+		opts.Reverse = true
+	}
+}
+
+// WithLimit option caps the number of records returned by the iterator. A limit of zero means unlimited.
+func WithLimit(limit int) IteratorOption {
+	return func(opts *IteratorOptions) {
+		opts.Limit = limit
+	}
+}
+
+// GetIteratorOptions applies the given options and returns the resulting iterator options
+func GetIteratorOptions(opts ...IteratorOption) IteratorOptions {
+	options := IteratorOptions{}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return options
+}
+
+// IsolationLevel is the isolation level of a transaction, as defined by the SQL standard
+type IsolationLevel int
+
+const (
+	// LevelDefault is the default isolation level of the DB of the store, ie REPEATABLE READ for MySQL (InnoDB) and
+	// READ COMMITTED for PostgreSQL unless their server is configured with another level
+	LevelDefault IsolationLevel = iota
+	// LevelReadUncommitted lets transactions read the uncommitted writes of the other transactions
+	LevelReadUncommitted
+	// LevelReadCommitted lets transactions read the writes committed by the other transactions since they started
+	LevelReadCommitted
+	// LevelRepeatableRead makes transactions read the same records again until they end
+	LevelRepeatableRead
+	// LevelSerializable makes transactions behave as if they were executed one after the other
+	LevelSerializable
+)
+
+// String returns the SQL name of the isolation level
+func (l IsolationLevel) String() string {
+	switch l {
+	case LevelDefault:
+		return "Default"
+	case LevelReadUncommitted:
+		return "Read Uncommitted"
+	case LevelReadCommitted:
+		return "Read Committed"
+	case LevelRepeatableRead:
+		return "Repeatable Read"
+	case LevelSerializable:
+		return "Serializable"
+	default:
+		return fmt.Sprintf("IsolationLevel(%d)", int(l))
+	}
+}
+
+// TxOptions holds the options of a transaction
+type TxOptions struct {
+	// Isolation is the isolation level of the transaction, LevelDefault leaves the level of the DB
+	Isolation IsolationLevel
+}
+
+// TxOption configures a transaction
+type TxOption func(opts *TxOptions)
+
+// WithIsolationLevel option sets the isolation level of the transaction, ie LevelReadCommitted for read-heavy
+// workloads or LevelSerializable for the transactions which must not interleave. Default is LevelDefault.
+func WithIsolationLevel(level IsolationLevel) TxOption {
+	return func(opts *TxOptions) {
+		opts.Isolation = level
+	}
+}
+
+// GetTxOptions applies the given options and returns the resulting transaction options
+func GetTxOptions(opts ...TxOption) TxOptions {
+	options := TxOptions{}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return options
+}
+
+// SQLTxOptions applies the given options and returns the resulting options of a database/sql transaction, for the SQL
+// stores. It returns an error wrapping ErrIsolationLevelNotSupported for an unknown isolation level.
+func SQLTxOptions(opts ...TxOption) (*sql.TxOptions, error) {
+	options := GetTxOptions(opts...)
+
+	var level sql.IsolationLevel
+
+	switch options.Isolation {
+	case LevelDefault:
+		level = sql.LevelDefault
+	case LevelReadUncommitted:
+		level = sql.LevelReadUncommitted
+	case LevelReadCommitted:
+		level = sql.LevelReadCommitted
+	case LevelRepeatableRead:
+		level = sql.LevelRepeatableRead
+	case LevelSerializable:
+		level = sql.LevelSerializable
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrIsolationLevelNotSupported, options.Isolation)
+	}
+
+	return &sql.TxOptions{Isolation: level}, nil
+}
+
+// ForEach calls fn with every key/value pair of itr until fn returns stop or an error, and then releases itr even if
+// fn panics. It's meant for stores implementing Store.ForEach with their Iterator.
+func ForEach(itr StoreIterator, fn func(key, value []byte) (stop bool, err error)) error {
+	defer itr.Release()
+
+	for itr.Next() {
+		stop, err := fn(itr.Key(), itr.Value())
+		if err != nil {
+			return err
+		}
+
+		if stop {
+			return nil
+		}
+	}
+
+	return itr.Error()
+}
+
+// GetBulk fetches the records of the given keys one at a time with get, with a nil entry for every key not found.
+// It's meant for stores without a native way to fetch several records at once.
+func GetBulk(get func(k string) ([]byte, error), keys ...string) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+
+	for i, k := range keys {
+		v, err := get(k)
+		if err != nil {
+			if errors.Is(err, ErrDataNotFound) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		values[i] = v
+	}
+
+	return values, nil
 }
 
 // StoreIterator is the iterator for the latest snapshot of the underlying store.
@@ -77,4 +500,15 @@ type StoreIterator interface {
 	// The caller should not modify the contents of the returned slice, and
 	// its contents may change on the next call to any 'seeks method'.
 	Value() []byte
+
+	// Seek moves the iterator to the first key/value pair of its range whose key is at or after key in the
+	// iteration order, ie the first key >= key, or the last key <= key when iterating in reverse. It returns
+	// false if no such key exists. Pairs reached by Seek count in the limit of the iterator like the ones
+	// reached by Next, and Next moves to the pair following the one reached by Seek.
+	Seek(key string) bool
+
+	// TotalCount returns the number of records matching the iterator (ie within its key range), regardless of its
+	// limit and of the pairs already reached by Next or Seek, ie to show "20 of N" in a paginated list. Iterators
+	// that can't count their records cheaply return -1 and ErrTotalCountNotSupported.
+	TotalCount() (int, error)
 }
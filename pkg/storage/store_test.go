@@ -0,0 +1,137 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage_test
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+type releaseRecordingIterator struct {
+	storage.StoreIterator
+	released bool
+}
+
+func (i *releaseRecordingIterator) Release() {
+	i.released = true
+	i.StoreIterator.Release()
+}
+
+func TestForEach(t *testing.T) {
+	store, err := mem.NewProvider().OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("k1", []byte("v1")))
+	require.NoError(t, store.Put("k2", []byte("v2")))
+
+	t.Run("test iterator released", func(t *testing.T) {
+		itr := &releaseRecordingIterator{StoreIterator: store.Iterator("k", "k"+storage.EndKeySuffix)}
+
+		count := 0
+
+		require.NoError(t, storage.ForEach(itr, func(key, value []byte) (bool, error) {
+			count++
+
+			return false, nil
+		}))
+		require.Equal(t, 2, count)
+		require.True(t, itr.released)
+	})
+
+	t.Run("test iterator released when fn panics", func(t *testing.T) {
+		itr := &releaseRecordingIterator{StoreIterator: store.Iterator("k", "k"+storage.EndKeySuffix)}
+
+		require.Panics(t, func() {
+			_ = storage.ForEach(itr, func(key, value []byte) (bool, error) {
+				panic("fn panic")
+			})
+		})
+		require.True(t, itr.released)
+	})
+}
+
+func TestGetBulk(t *testing.T) {
+	store, err := mem.NewProvider().OpenStore("test")
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("k1", []byte("v1")))
+	require.NoError(t, store.Put("k3", []byte("v3")))
+
+	t.Run("test missing keys", func(t *testing.T) {
+		values, err := storage.GetBulk(store.Get, "k1", "k2", "k3")
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("v1"), nil, []byte("v3")}, values)
+	})
+
+	t.Run("test get error", func(t *testing.T) {
+		errGet := errors.New("get error")
+
+		values, err := storage.GetBulk(func(string) ([]byte, error) {
+			return nil, errGet
+		}, "k1")
+		require.Equal(t, errGet, err)
+		require.Nil(t, values)
+	})
+}
+
+func TestTrimDBPrefix(t *testing.T) {
+	for prefix, expected := range map[string]string{
+		"":            "",
+		"_":           "",
+		"  ":          "",
+		"prefixdb":    "prefixdb",
+		"prefixdb_":   "prefixdb",
+		"prefixdb__":  "prefixdb",
+		" prefixdb_ ": "prefixdb",
+		"prefix_db":   "prefix_db",
+		"_prefixdb":   "_prefixdb",
+	} {
+		require.Equal(t, expected, storage.TrimDBPrefix(prefix), "prefix %q", prefix)
+	}
+}
+
+func TestSQLTxOptions(t *testing.T) {
+	t.Run("default isolation level", func(t *testing.T) {
+		txOpts, err := storage.SQLTxOptions()
+		require.NoError(t, err)
+		require.Equal(t, &sql.TxOptions{Isolation: sql.LevelDefault}, txOpts)
+	})
+
+	t.Run("isolation levels", func(t *testing.T) {
+		for level, expected := range map[storage.IsolationLevel]sql.IsolationLevel{
+			storage.LevelDefault:         sql.LevelDefault,
+			storage.LevelReadUncommitted: sql.LevelReadUncommitted,
+			storage.LevelReadCommitted:   sql.LevelReadCommitted,
+			storage.LevelRepeatableRead:  sql.LevelRepeatableRead,
+			storage.LevelSerializable:    sql.LevelSerializable,
+		} {
+			txOpts, err := storage.SQLTxOptions(storage.WithIsolationLevel(level))
+			require.NoError(t, err)
+			require.Equal(t, expected, txOpts.Isolation)
+			require.Equal(t, expected.String(), level.String())
+		}
+	})
+
+	t.Run("unknown isolation level", func(t *testing.T) {
+		txOpts, err := storage.SQLTxOptions(storage.WithIsolationLevel(storage.IsolationLevel(42)))
+		require.True(t, errors.Is(err, storage.ErrIsolationLevelNotSupported))
+		require.EqualError(t, err, "isolation level is not supported: IsolationLevel(42)")
+		require.Nil(t, txOpts)
+	})
+
+	t.Run("last option wins", func(t *testing.T) {
+		options := storage.GetTxOptions(storage.WithIsolationLevel(storage.LevelSerializable),
+			storage.WithIsolationLevel(storage.LevelReadCommitted))
+		require.Equal(t, storage.LevelReadCommitted, options.Isolation)
+	})
+}
@@ -0,0 +1,362 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/hyperledger/aries-framework-go/pkg/common/log"
+)
+
+var logger = log.New("aries-framework/storage")
+
+// warmBatchSize is the number of records copied at once from the durable stores to the fast stores by Warm
+const warmBatchSize = 1000
+
+// Warmer is implemented by write-through providers to preload the records of their durable stores into their fast
+// stores, ie on startup. Providers can be checked for this capability with a type assertion.
+type Warmer interface {
+	// Warm copies the records of the durable stores into the fast stores
+	Warm() error
+}
+
+// NewWriteThroughProvider returns a provider keeping the records of its stores in the stores of both fast and
+// durable, ie to serve the records from memory while persisting them. Records are written to the durable store first
+// and then to the fast store, so that a record that failed to be persisted is never read from the fast store, and
+// deleted from both. Get, GetBulk and Has read the fast store first, the records missing from it being read from the
+// durable store and added to the fast store. Iterators, counts and ForEach read the durable store, which holds all the
+// records, the fast store only holding the records written or read through the provider and the records preloaded by
+// Warm. The provider is a Warmer: Warm preloads the stores listed by durable when it is a StoreLister, the stores
+// opened so far otherwise. The write-through stores only implement Store.
+// Closing the provider closes both fast and durable.
+func NewWriteThroughProvider(fast, durable Provider) (Provider, error) {
+	if fast == nil || durable == nil {
+		return nil, errors.New("fast and durable providers are mandatory")
+	}
+
+	return &writeThroughProvider{fast: fast, durable: durable, names: map[string]struct{}{}}, nil
+}
+
+type writeThroughProvider struct {
+	fast    Provider
+	durable Provider
+	// names are the names of the stores opened so far, warmed when durable isn't a StoreLister
+	names map[string]struct{}
+	lock  sync.Mutex
+}
+
+// OpenStore opens the stores of both fast and durable with the given name.
+func (p *writeThroughProvider) OpenStore(name string) (Store, error) {
+	durable, err := p.durable.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fast, err := p.fast.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	p.lock.Lock()
+	p.names[name] = struct{}{}
+	p.lock.Unlock()
+
+	return &writeThroughStore{fast: fast, durable: durable}, nil
+}
+
+// CloseStore closes the stores of both fast and durable with the given name, the durable store is closed even if
+// closing the fast store fails.
+func (p *writeThroughProvider) CloseStore(name string) error {
+	p.lock.Lock()
+	delete(p.names, name)
+	p.lock.Unlock()
+
+	var errs MultiError
+
+	if err := p.fast.CloseStore(name); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := p.durable.CloseStore(name); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs.ErrorOrNil()
+}
+
+// Close closes both fast and durable, durable is closed even if closing fast fails.
+func (p *writeThroughProvider) Close() error {
+	p.lock.Lock()
+	p.names = map[string]struct{}{}
+	p.lock.Unlock()
+
+	var errs MultiError
+
+	if err := p.fast.Close(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := p.durable.Close(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs.ErrorOrNil()
+}
+
+// Warm copies the records of the durable stores into the fast stores. The records of durable stores that aren't
+// FullIterator are read with the range of all the keys starting with printable ASCII characters.
+func (p *writeThroughProvider) Warm() error {
+	names, err := p.storeNames()
+	if err != nil {
+		return fmt.Errorf("failed to list the stores to warm: %w", err)
+	}
+
+	for _, name := range names {
+		s, err := p.OpenStore(name)
+		if err != nil {
+			return fmt.Errorf("failed to open store %s to warm: %w", name, err)
+		}
+
+		if err = s.(*writeThroughStore).warm(); err != nil {
+			return fmt.Errorf("failed to warm store %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// storeNames returns the names of the stores to warm.
+func (p *writeThroughProvider) storeNames() ([]string, error) {
+	if lister, ok := p.durable.(StoreLister); ok {
+		return lister.StoreNames()
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	names := make([]string, 0, len(p.names))
+
+	for name := range p.names {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+type writeThroughStore struct {
+	fast    Store
+	durable Store
+}
+
+// Put stores the key and the record in the durable store and then in the fast store. The record of the key is
+// removed from the fast store when it fails to be stored there.
+func (s *writeThroughStore) Put(k string, v []byte) error {
+	if err := s.durable.Put(k, v); err != nil {
+		return err
+	}
+
+	if err := s.fast.Put(k, v); err != nil {
+		s.evict(k)
+
+		return err
+	}
+
+	return nil
+}
+
+// PutBatch stores all the given key/value pairs in the durable store and then in the fast store.
+func (s *writeThroughStore) PutBatch(kvs []KeyValue) error {
+	if err := s.durable.PutBatch(kvs); err != nil {
+		return err
+	}
+
+	if err := s.fast.PutBatch(kvs); err != nil {
+		for _, kv := range kvs {
+			s.evict(kv.Key)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// PutIfMatch stores the record only if the current record of key k in the durable store equals expected, the record
+// of the fast store being updated when it is stored and removed otherwise as it may be stale.
+func (s *writeThroughStore) PutIfMatch(k string, expected, newValue []byte) (bool, error) {
+	stored, err := s.durable.PutIfMatch(k, expected, newValue)
+	if err != nil || !stored {
+		s.evict(k)
+
+		return stored, err
+	}
+
+	if err = s.fast.Put(k, newValue); err != nil {
+		s.evict(k)
+
+		return true, err
+	}
+
+	return true, nil
+}
+
+// Get fetches the record based on key from the fast store, records missing from it being fetched from the durable
+// store and added to the fast store.
+func (s *writeThroughStore) Get(k string) ([]byte, error) {
+	v, err := s.fast.Get(k)
+	if !errors.Is(err, ErrDataNotFound) {
+		return v, err
+	}
+
+	v, err = s.durable.Get(k)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.fast.Put(k, v); err != nil {
+		logger.Warnf("failed to add record %s to the fast store: %s", k, err)
+	}
+
+	return v, nil
+}
+
+// GetBulk fetches the records of all the given keys from the fast store, the records missing from it being fetched at
+// once from the durable store and added to the fast store.
+func (s *writeThroughStore) GetBulk(keys ...string) ([][]byte, error) {
+	values, err := s.fast.GetBulk(keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		missingKeys    []string
+		missingIndexes []int
+	)
+
+	for i, v := range values {
+		if v == nil {
+			missingKeys = append(missingKeys, keys[i])
+			missingIndexes = append(missingIndexes, i)
+		}
+	}
+
+	if len(missingKeys) == 0 {
+		return values, nil
+	}
+
+	missingValues, err := s.durable.GetBulk(missingKeys...)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []KeyValue
+
+	for i, v := range missingValues {
+		values[missingIndexes[i]] = v
+
+		if v != nil {
+			found = append(found, KeyValue{Key: missingKeys[i], Value: v})
+		}
+	}
+
+	if len(found) > 0 {
+		if err = s.fast.PutBatch(found); err != nil {
+			logger.Warnf("failed to add records to the fast store: %s", err)
+		}
+	}
+
+	return values, nil
+}
+
+// Has checks whether a record with key k exists in the fast store or else in the durable store.
+func (s *writeThroughStore) Has(k string) (bool, error) {
+	found, err := s.fast.Has(k)
+	if err != nil || found {
+		return found, err
+	}
+
+	return s.durable.Has(k)
+}
+
+// Iterator returns an iterator over the range of the durable store.
+func (s *writeThroughStore) Iterator(startKey, endKey string, opts ...IteratorOption) StoreIterator {
+	return s.durable.Iterator(startKey, endKey, opts...)
+}
+
+// Count returns the number of records within the key range of the durable store.
+func (s *writeThroughStore) Count(startKey, endKey string) (int, error) {
+	return s.durable.Count(startKey, endKey)
+}
+
+// ForEach calls fn with every key/value pair within the key range of the durable store.
+func (s *writeThroughStore) ForEach(startKey, endKey string, fn func(key, value []byte) (bool, error)) error {
+	return s.durable.ForEach(startKey, endKey, fn)
+}
+
+// Delete deletes the record of key k from the durable store and then from the fast store, which may not hold it.
+func (s *writeThroughStore) Delete(k string) error {
+	if err := s.durable.Delete(k); err != nil {
+		return err
+	}
+
+	if err := s.fast.Delete(k); err != nil && !errors.Is(err, ErrDataNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// evict removes the record of key k from the fast store after a failed write, so that it isn't read with a stale
+// value.
+func (s *writeThroughStore) evict(k string) {
+	if err := s.fast.Delete(k); err != nil && !errors.Is(err, ErrDataNotFound) {
+		logger.Warnf("failed to remove record %s from the fast store: %s", k, err)
+	}
+}
+
+// warm copies the records of the durable store into the fast store by batches.
+func (s *writeThroughStore) warm() error {
+	var itr StoreIterator
+
+	if fullIterator, ok := s.durable.(FullIterator); ok {
+		itr = fullIterator.IteratorAll()
+	} else {
+		itr = s.durable.Iterator("", EndKeySuffix)
+	}
+
+	defer itr.Release()
+
+	batch := make([]KeyValue, 0, warmBatchSize)
+
+	for itr.Next() {
+		// iterators may reuse their buffers, the records are copied until the batch is written
+		batch = append(batch, KeyValue{Key: string(itr.Key()), Value: copyBytes(itr.Value())})
+
+		if len(batch) == warmBatchSize {
+			if err := s.fast.PutBatch(batch); err != nil {
+				return err
+			}
+
+			batch = batch[:0]
+		}
+	}
+
+	if err := itr.Error(); err != nil {
+		return err
+	}
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	return s.fast.PutBatch(batch)
+}
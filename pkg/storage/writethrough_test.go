@@ -0,0 +1,174 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package storage_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/storage"
+	"github.com/hyperledger/aries-framework-go/pkg/storage/mem"
+)
+
+func TestNewWriteThroughProvider(t *testing.T) {
+	t.Run("test missing providers", func(t *testing.T) {
+		_, err := storage.NewWriteThroughProvider(nil, mem.NewProvider())
+		require.EqualError(t, err, "fast and durable providers are mandatory")
+
+		_, err = storage.NewWriteThroughProvider(mem.NewProvider(), nil)
+		require.EqualError(t, err, "fast and durable providers are mandatory")
+	})
+
+	t.Run("test records are written to both stores", func(t *testing.T) {
+		fast, durable := mem.NewProvider(), mem.NewProvider()
+
+		p, err := storage.NewWriteThroughProvider(fast, durable)
+		require.NoError(t, err)
+
+		store, err := p.OpenStore("state")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Put("k1", []byte("v1")))
+		require.NoError(t, store.PutBatch([]storage.KeyValue{{Key: "k2", Value: []byte("v2")}}))
+
+		stored, err := store.PutIfMatch("k3", nil, []byte("v3"))
+		require.NoError(t, err)
+		require.True(t, stored)
+
+		for _, prov := range []storage.Provider{fast, durable} {
+			values, err := openStore(t, prov, "state").GetBulk("k1", "k2", "k3")
+			require.NoError(t, err)
+			require.Equal(t, [][]byte{[]byte("v1"), []byte("v2"), []byte("v3")}, values)
+		}
+
+		require.NoError(t, store.Delete("k1"))
+
+		for _, prov := range []storage.Provider{fast, durable} {
+			_, err = openStore(t, prov, "state").Get("k1")
+			require.Equal(t, storage.ErrDataNotFound, err)
+		}
+
+		// a record that fails to be persisted isn't written to the fast store
+		require.Equal(t, storage.ErrKeyRequired, store.Put("", []byte("v")))
+
+		count, err := openStore(t, fast, "state").Count("k", "k"+storage.EndKeySuffix)
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+
+		require.NoError(t, p.CloseStore("state"))
+		require.NoError(t, p.Close())
+	})
+
+	t.Run("test records missing from the fast store are read from the durable store", func(t *testing.T) {
+		fast, durable := mem.NewProvider(), mem.NewProvider()
+
+		durableStore := openStore(t, durable, "state")
+		require.NoError(t, durableStore.Put("k1", []byte("v1")))
+		require.NoError(t, durableStore.Put("k2", []byte("v2")))
+		require.NoError(t, durableStore.Put("k3", []byte("v3")))
+
+		p, err := storage.NewWriteThroughProvider(fast, durable)
+		require.NoError(t, err)
+
+		store := openStore(t, p, "state")
+
+		v, err := store.Get("k1")
+		require.NoError(t, err)
+		require.Equal(t, []byte("v1"), v)
+
+		values, err := store.GetBulk("k1", "k2", "missing")
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("v1"), []byte("v2"), nil}, values)
+
+		found, err := store.Has("k3")
+		require.NoError(t, err)
+		require.True(t, found)
+
+		_, err = store.Get("missing")
+		require.Equal(t, storage.ErrDataNotFound, err)
+
+		// the records read are added to the fast store, iterators read the durable store
+		values, err = openStore(t, fast, "state").GetBulk("k1", "k2", "k3")
+		require.NoError(t, err)
+		require.Equal(t, [][]byte{[]byte("v1"), []byte("v2"), nil}, values)
+
+		count, err := store.Count("k", "k"+storage.EndKeySuffix)
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+
+		// a record of the durable store only can be deleted
+		require.NoError(t, store.Delete("k3"))
+
+		_, err = durableStore.Get("k3")
+		require.Equal(t, storage.ErrDataNotFound, err)
+	})
+
+	t.Run("test warm", func(t *testing.T) {
+		durable := mem.NewProvider()
+
+		for _, name := range []string{"state1", "state2"} {
+			durableStore := openStore(t, durable, name)
+
+			for i := 0; i < 1500; i++ {
+				require.NoError(t, durableStore.Put(fmt.Sprintf("key_%04d", i), []byte(name)))
+			}
+		}
+
+		// a restart with an empty fast store
+		fast := mem.NewProvider()
+
+		p, err := storage.NewWriteThroughProvider(fast, durable)
+		require.NoError(t, err)
+
+		warmer, ok := p.(storage.Warmer)
+		require.True(t, ok)
+		require.NoError(t, warmer.Warm())
+
+		for _, name := range []string{"state1", "state2"} {
+			count, err := openStore(t, fast, name).Count("key_", "key_"+storage.EndKeySuffix)
+			require.NoError(t, err)
+			require.Equal(t, 1500, count)
+		}
+	})
+
+	t.Run("test warm the opened stores of a durable provider that can't list its stores", func(t *testing.T) {
+		memProvider := mem.NewProvider()
+
+		durable, err := storage.NewNamespacedProvider(memProvider, "tenant")
+		require.NoError(t, err)
+
+		require.NoError(t, openStore(t, durable, "state1").Put("k1", []byte("v1")))
+		require.NoError(t, openStore(t, durable, "state2").Put("k1", []byte("v1")))
+
+		fast := mem.NewProvider()
+
+		p, err := storage.NewWriteThroughProvider(fast, durable)
+		require.NoError(t, err)
+
+		openStore(t, p, "state1")
+		require.NoError(t, p.(storage.Warmer).Warm())
+
+		found, err := openStore(t, fast, "state1").Has("k1")
+		require.NoError(t, err)
+		require.True(t, found)
+
+		found, err = openStore(t, fast, "state2").Has("k1")
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+}
+
+func openStore(t *testing.T, p storage.Provider, name string) storage.Store {
+	t.Helper()
+
+	store, err := p.OpenStore(name)
+	require.NoError(t, err)
+
+	return store
+}
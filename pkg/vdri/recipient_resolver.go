@@ -0,0 +1,86 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vdri
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+	compositepb "github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/proto/common_composite_go_proto"
+	diddoc "github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	vdriapi "github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdri"
+)
+
+const (
+	x25519KeyAgreementKeyType = "X25519KeyAgreementKey2019"
+	x25519Curve               = "X25519"
+	x25519KeySize             = 32
+)
+
+// RecipientResolver resolves the keyAgreement keys of recipient DIDs as composite public keys to encrypt messages for
+// them, it implements composite.RecipientResolver.
+type RecipientResolver struct {
+	vdri vdriapi.Registry
+}
+
+// NewRecipientResolver returns a recipient resolver resolving DIDs with the given vdri registry.
+func NewRecipientResolver(vdri vdriapi.Registry) *RecipientResolver {
+	return &RecipientResolver{vdri: vdri}
+}
+
+// ResolveRecipientKeys resolves did and returns its keyAgreement keys which are encryption keys: EC and X25519 JWKs
+// and X25519KeyAgreementKey2019 keys. The other verification methods are skipped, the keys are returned with their
+// verification method ID as KID.
+func (r *RecipientResolver) ResolveRecipientKeys(did string) ([]*composite.PublicKey, error) {
+	doc, err := r.vdri.Resolve(did)
+	if err != nil {
+		return nil, fmt.Errorf("resolve recipient keys: %w", err)
+	}
+
+	var keys []*composite.PublicKey
+
+	for i := range doc.KeyAgreement {
+		key, ok := encryptionKey(&doc.KeyAgreement[i].PublicKey)
+		if !ok {
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// encryptionKey converts pk to a composite public key, it returns false if pk isn't an encryption key.
+func encryptionKey(pk *diddoc.PublicKey) (*composite.PublicKey, bool) {
+	if jwk := pk.JSONWebKey(); jwk != nil {
+		jwkBytes, err := jwk.MarshalJSON()
+		if err != nil {
+			return nil, false
+		}
+
+		key, err := composite.PublicKeyFromJWK(jwkBytes)
+		if err != nil {
+			return nil, false
+		}
+
+		key.KID = pk.ID
+
+		return key, true
+	}
+
+	if pk.Type != x25519KeyAgreementKeyType || len(pk.Value) != x25519KeySize {
+		return nil, false
+	}
+
+	return &composite.PublicKey{
+		KID:   pk.ID,
+		X:     pk.Value,
+		Curve: x25519Curve,
+		Type:  compositepb.KeyType_OKP.String(),
+	}, true
+}
@@ -0,0 +1,100 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vdri
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto/primitive/composite"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/jose"
+	mockvdri "github.com/hyperledger/aries-framework-go/pkg/mock/vdri"
+)
+
+func TestRecipientResolver_ResolveRecipientKeys(t *testing.T) {
+	const didID = "did:example:123"
+
+	x25519Key := make([]byte, 32)
+	_, err := rand.Read(x25519Key)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	ecJWK, err := jose.JWKFromPublicKey(&ecKey.PublicKey)
+	require.NoError(t, err)
+
+	ecPubKey, err := did.NewPublicKeyFromJWK(didID+"#key-2", "JsonWebKey2020", didID, ecJWK)
+	require.NoError(t, err)
+
+	edPubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	edJWK, err := jose.JWKFromPublicKey(edPubKey)
+	require.NoError(t, err)
+
+	edJWKPubKey, err := did.NewPublicKeyFromJWK(didID+"#key-4", "JsonWebKey2020", didID, edJWK)
+	require.NoError(t, err)
+
+	doc := &did.Doc{
+		ID: didID,
+		KeyAgreement: []did.VerificationMethod{
+			*did.NewEmbeddedVerificationMethod(
+				did.NewPublicKeyFromBytes(didID+"#key-1", "X25519KeyAgreementKey2019", didID, x25519Key),
+				did.KeyAgreement),
+			*did.NewEmbeddedVerificationMethod(ecPubKey, did.KeyAgreement),
+			*did.NewEmbeddedVerificationMethod(
+				did.NewPublicKeyFromBytes(didID+"#key-3", "Ed25519VerificationKey2018", didID, edPubKey),
+				did.KeyAgreement),
+			*did.NewEmbeddedVerificationMethod(edJWKPubKey, did.KeyAgreement),
+		},
+	}
+
+	t.Run("test resolve encryption keys", func(t *testing.T) {
+		var resolver composite.RecipientResolver = NewRecipientResolver(&mockvdri.MockVDRIRegistry{ResolveValue: doc})
+
+		keys, err := resolver.ResolveRecipientKeys(didID)
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+
+		require.Equal(t, didID+"#key-1", keys[0].KID)
+		require.Equal(t, "OKP", keys[0].Type)
+		require.Equal(t, "X25519", keys[0].Curve)
+		require.Equal(t, x25519Key, keys[0].X)
+
+		require.Equal(t, didID+"#key-2", keys[1].KID)
+		require.Equal(t, "EC", keys[1].Type)
+		require.Equal(t, ecKey.X, new(big.Int).SetBytes(keys[1].X))
+		require.Equal(t, ecKey.Y, new(big.Int).SetBytes(keys[1].Y))
+	})
+
+	t.Run("test DID without encryption key", func(t *testing.T) {
+		resolver := NewRecipientResolver(&mockvdri.MockVDRIRegistry{ResolveValue: &did.Doc{
+			ID:           didID,
+			KeyAgreement: doc.KeyAgreement[2:],
+		}})
+
+		_, err := composite.ResolveRecipients(resolver, []string{didID})
+		require.EqualError(t, err, "resolveRecipients: recipient did:example:123 has no encryption key")
+	})
+
+	t.Run("test error from resolve did", func(t *testing.T) {
+		resolver := NewRecipientResolver(&mockvdri.MockVDRIRegistry{ResolveErr: errors.New("resolve error")})
+
+		keys, err := resolver.ResolveRecipientKeys(didID)
+		require.EqualError(t, err, "resolve recipient keys: resolve error")
+		require.Nil(t, keys)
+	})
+}